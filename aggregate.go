@@ -0,0 +1,151 @@
+package gorm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/clause"
+)
+
+// AggregateOption the option of Max, Min, SumOf and AvgOf, see AggregateRequired
+type AggregateOption func(*aggregateConfig)
+
+type aggregateConfig struct {
+	required bool
+}
+
+// AggregateRequired adds ErrNullAggregate if the aggregate is NULL, e.g: no rows matched, instead of leaving dest zero
+func AggregateRequired() AggregateOption {
+	return func(config *aggregateConfig) {
+		config.required = true
+	}
+}
+
+// Max queries the maximum of column, respecting the conditions, joins and soft delete of db, and scans it into dest,
+// which should be a pointer to a number, a string, a time.Time, a pointer of them or a sql.Scanner, e.g:
+//
+//	var version int
+//	db.Model(&Migration{}).Where("app = ?", app).Max("version", &version)
+//
+// If the aggregate is NULL, e.g: no rows matched, dest is set to zero or nil, and RowsAffected is 0, use
+// AggregateRequired to return ErrNullAggregate instead. It can't be grouped, select the aggregates and Scan them instead
+func (db *DB) Max(column string, dest interface{}, opts ...AggregateOption) (tx *DB) {
+	return db.aggregate("MAX", column, dest, opts)
+}
+
+// Min queries the minimum of column and scans it into dest, see Max
+func (db *DB) Min(column string, dest interface{}, opts ...AggregateOption) (tx *DB) {
+	return db.aggregate("MIN", column, dest, opts)
+}
+
+// SumOf queries the sum of column and scans it into dest, which is zero if no rows matched, see Max
+func (db *DB) SumOf(column string, dest interface{}, opts ...AggregateOption) (tx *DB) {
+	return db.aggregate("SUM", column, dest, opts)
+}
+
+// AvgOf queries the average of column and scans it into dest, see Max
+func (db *DB) AvgOf(column string, dest interface{}, opts ...AggregateOption) (tx *DB) {
+	return db.aggregate("AVG", column, dest, opts)
+}
+
+func (db *DB) aggregate(fn string, column string, dest interface{}, opts []AggregateOption) (tx *DB) {
+	var config aggregateConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	tx = db.getInstance()
+	if _, ok := tx.Statement.Clauses["GROUP BY"]; ok {
+		tx.AddError(fmt.Errorf("%w: %s of the grouped rows, select the aggregates and Scan them instead", ErrInvalidData, fn))
+		return
+	}
+
+	if rv := reflect.ValueOf(dest); rv.Kind() != reflect.Ptr || rv.IsNil() {
+		tx.AddError(fmt.Errorf("%w: %T, which should be a pointer", ErrInvalidData, dest))
+		return
+	}
+
+	if tx.Statement.Model != nil {
+		if err := tx.Statement.Parse(tx.Statement.Model); err != nil {
+			tx.AddError(err)
+			return
+		}
+	}
+
+	aggregateColumn, ok := tx.Statement.lookUpColumn(column)
+	if !ok {
+		return
+	}
+
+	if selectClause, ok := db.Statement.Clauses["SELECT"]; ok {
+		defer func() {
+			tx.Statement.Clauses["SELECT"] = selectClause
+		}()
+	} else {
+		defer delete(tx.Statement.Clauses, "SELECT")
+	}
+	tx.Statement.AddClause(clause.Select{Expression: clause.Expr{SQL: fn + "(?)", Vars: []interface{}{aggregateColumn}}})
+
+	if orderByClause, ok := db.Statement.Clauses["ORDER BY"]; ok {
+		delete(tx.Statement.Clauses, "ORDER BY")
+		defer func() {
+			tx.Statement.Clauses["ORDER BY"] = orderByClause
+		}()
+	}
+
+	// the orders of the default scope are skipped like Count
+	tx.Statement.Settings.Store(countingKey, true)
+	defer tx.Statement.Settings.Delete(countingKey)
+	tx = tx.Set("rows", false)
+	tx = tx.callbacks.Row().Execute(tx)
+
+	row, ok := tx.Statement.Dest.(*sql.Row)
+	if tx.Error != nil || !ok {
+		return
+	}
+
+	var value interface{}
+	if err := row.Scan(&value); err != nil {
+		tx.AddError(err)
+		return
+	}
+
+	if err := assignAggregate(dest, value); err != nil {
+		tx.AddError(fmt.Errorf("failed to scan %s(%s): %w", fn, column, err))
+		return
+	}
+
+	if value == nil {
+		tx.RowsAffected = 0
+		if config.required {
+			tx.AddError(fmt.Errorf("%w: %s(%s)", ErrNullAggregate, fn, column))
+		}
+	} else {
+		tx.RowsAffected = 1
+	}
+	return
+}
+
+// assignAggregate assigns the aggregate value to dest, NULL resets dest to zero or nil
+func assignAggregate(dest interface{}, value interface{}) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+
+	rv := reflect.ValueOf(dest).Elem()
+	if value == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	if rv.Kind() == reflect.Ptr {
+		elem := reflect.New(rv.Type().Elem())
+		if err := assignAggregate(elem.Interface(), value); err != nil {
+			return err
+		}
+		rv.Set(elem)
+		return nil
+	}
+	return assignValue(rv, value)
+}
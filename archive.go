@@ -0,0 +1,157 @@
+package gorm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ArchivedAt soft deletes the rows by moving them to the `<table>_archive` table, which has the same columns and
+// is created by AutoMigrate, the column records when the rows were archived and is always NULL in the table, e.g:
+//
+//	type Order struct {
+//	  ID         uint
+//	  ArchivedAt gorm.ArchivedAt
+//	}
+//
+//	db.Delete(&order)                                        // moved to `orders_archive`
+//	db.Unscoped().Clauses(gorm.WithArchive).Find(&orders)    // includes the archived orders
+//	db.Restore(&Order{}, order.ID)                           // moved back to `orders`
+type ArchivedAt sql.NullTime
+
+// Scan implements the Scanner interface.
+func (n *ArchivedAt) Scan(value interface{}) error {
+	return (*sql.NullTime)(n).Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (n ArchivedAt) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Time, nil
+}
+
+func (n ArchivedAt) MarshalJSON() ([]byte, error) {
+	if n.Valid {
+		return json.Marshal(n.Time)
+	}
+	return json.Marshal(nil)
+}
+
+func (n *ArchivedAt) UnmarshalJSON(b []byte) error {
+	return (*DeletedAt)(n).UnmarshalJSON(b)
+}
+
+// ArchiveTable implements schema.SoftDeleteStrategy
+func (ArchivedAt) ArchiveTable(table string) string {
+	return table + "_archive"
+}
+
+func (ArchivedAt) QueryClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{ArchiveQueryClause{Field: f}}
+}
+
+const withArchiveKey = "gorm:with_archive"
+
+type withArchive struct{}
+
+// WithArchive includes the archived rows in the queries of Unscoped, see ArchivedAt
+var WithArchive = withArchive{}
+
+// ModifyStatement implements StatementModifier
+func (withArchive) ModifyStatement(stmt *Statement) {
+	stmt.Settings.Store(withArchiveKey, true)
+}
+
+// Build implements clause.Expression
+func (withArchive) Build(clause.Builder) {}
+
+type ArchiveQueryClause struct {
+	Field *schema.Field
+}
+
+func (ac ArchiveQueryClause) Name() string {
+	return ""
+}
+
+func (ac ArchiveQueryClause) Build(clause.Builder) {
+}
+
+func (ac ArchiveQueryClause) MergeClause(*clause.Clause) {
+}
+
+// ModifyStatement queries the union of the table and the archive table with the name of the table
+func (ac ArchiveQueryClause) ModifyStatement(stmt *Statement) {
+	if _, ok := stmt.Settings.Load(withArchiveKey); !ok || !stmt.Unscoped || stmt.TableExpr != nil || stmt.Table == "" {
+		return
+	}
+
+	columns := make([]string, 0, len(ac.Field.Schema.DBNames))
+	for _, dbName := range ac.Field.Schema.DBNames {
+		columns = append(columns, stmt.Quote(dbName))
+	}
+
+	stmt.TableExpr = &clause.Expr{
+		SQL: "(SELECT " + strings.Join(columns, ",") + " FROM ? UNION ALL SELECT " + strings.Join(columns, ",") + " FROM ?) ?",
+		Vars: []interface{}{
+			clause.Table{Name: stmt.Table},
+			clause.Table{Name: ac.Field.Schema.SoftDelete.ArchiveTable(stmt.Table)},
+			clause.Table{Name: stmt.Table},
+		},
+	}
+}
+
+// archivedColumns returns the quoted columns of s separated by comma, archivedAt is appended as the value of the soft
+// delete column if not empty, otherwise the column is excluded
+func archivedColumns(stmt *Statement, s *schema.Schema, archivedAt string) string {
+	columns := make([]string, 0, len(s.DBNames))
+	for _, dbName := range s.DBNames {
+		if dbName != s.SoftDeleteField.DBName {
+			columns = append(columns, stmt.Quote(dbName))
+		}
+	}
+	if archivedAt != "" {
+		columns = append(columns, archivedAt)
+	}
+	return strings.Join(columns, ",")
+}
+
+// ArchiveTable returns the archive table of the statement if its model is soft deleted by archiving, e.g. ArchivedAt
+func (stmt *Statement) ArchiveTable() string {
+	if stmt.Schema == nil || stmt.Schema.SoftDelete == nil || stmt.Table == "" {
+		return ""
+	}
+	return stmt.Schema.SoftDelete.ArchiveTable(stmt.Table)
+}
+
+// Archive copies the rows of the statement matching its where conditions to the archive table, the delete callback
+// calls it before deleting the rows of models soft deleted by archiving in the same transaction, e.g. ArchivedAt, the
+// rows of a limited statement are selected by the primary keys subquery of BuildLimited like the deletion
+func (stmt *Statement) Archive() error {
+	archiveTable := stmt.ArchiveTable()
+	if archiveTable == "" {
+		return nil
+	}
+
+	vars := []interface{}{clause.Table{Name: archiveTable}, stmt.DB.NowFunc(), clause.Table{Name: stmt.Table}}
+	sql := "INSERT INTO ? (" + archivedColumns(stmt, stmt.Schema, stmt.Quote(stmt.Schema.SoftDeleteField.DBName)) +
+		") SELECT " + archivedColumns(stmt, stmt.Schema, "?") + " FROM ?"
+	if cond, limited := stmt.limitedCondition(); limited {
+		if cond == nil {
+			return nil // ErrPrimaryKeyRequired is added to the statement
+		}
+		sql += " ?"
+		vars = append(vars, clause.Where{Exprs: []clause.Expression{cond}})
+	} else if where, ok := stmt.Clauses["WHERE"]; ok {
+		sql += " ?"
+		vars = append(vars, where.Expression)
+	}
+
+	// the conditions of the current table are built against the table of the statement
+	return stmt.DB.Session(&Session{NewDB: true}).Table(stmt.Table).Exec(sql, vars...).Error
+}
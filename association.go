@@ -16,6 +16,8 @@ type Association struct {
 	Relationship *schema.Relationship
 	Unscope      bool
 	Error        error
+
+	scopes []func(*DB) *DB
 }
 
 func (db *DB) Association(column string) *Association {
@@ -47,6 +49,21 @@ func (association *Association) Unscoped() *Association {
 		Relationship: association.Relationship,
 		Error:        association.Error,
 		Unscope:      true,
+		scopes:       association.scopes,
+	}
+}
+
+// Scopes applies the scopes to the queries of the associated values by Find and Count, e.g: paginates the associations,
+// the conditions, orders, limits, offsets and selected columns of the originating chain are applied as well
+//
+//	db.Model(&user).Association("Orders").Scopes(Paginate(2, 20)).Find(&orders)
+func (association *Association) Scopes(funcs ...func(*DB) *DB) *Association {
+	return &Association{
+		DB:           association.DB,
+		Relationship: association.Relationship,
+		Error:        association.Error,
+		Unscope:      association.Unscope,
+		scopes:       append(append([]func(*DB) *DB{}, association.scopes...), funcs...),
 	}
 }
 
@@ -366,7 +383,11 @@ func (association *Association) Clear() error {
 
 func (association *Association) Count() (count int64) {
 	if association.Error == nil {
-		association.Error = association.buildCondition().Count(&count).Error
+		// the limit and the offset paginating the associations aren't counted
+		association.Error = association.buildCondition().Scopes(func(tx *DB) *DB {
+			delete(tx.Statement.Clauses, "LIMIT")
+			return tx
+		}).Count(&count).Error
 	}
 	return
 }
@@ -566,7 +587,7 @@ func (association *Association) buildCondition() *DB {
 	var (
 		queryConds = association.Relationship.ToQueryConditions(association.DB.Statement.Context, association.DB.Statement.ReflectValue)
 		modelValue = reflect.New(association.Relationship.FieldSchema.ModelType).Interface()
-		tx         = association.DB.Model(modelValue)
+		tx         = association.DB.Session(&Session{}).Model(modelValue)
 	)
 
 	if association.Relationship.JoinTable != nil {
@@ -585,9 +606,25 @@ func (association *Association) buildCondition() *DB {
 			Table: clause.Table{Name: association.Relationship.JoinTable.Table},
 			ON:    clause.Where{Exprs: queryConds},
 		}}})
+
+		// the selected fields are qualified with the table of the associated values, the join table may have the same
+		// columns
+		if selects := tx.Statement.Selects; len(selects) > 0 {
+			columns := make([]clause.Column, 0, len(selects))
+			for _, name := range selects {
+				if field := association.Relationship.FieldSchema.LookUpField(name); field != nil && field.DBName != "" {
+					columns = append(columns, clause.Column{Table: association.Relationship.FieldSchema.Table, Name: field.DBName})
+				}
+			}
+
+			if len(columns) == len(selects) {
+				tx.Statement.Selects = nil
+				tx.Statement.AddClause(clause.Select{Distinct: tx.Statement.Distinct, Columns: columns})
+			}
+		}
 	} else {
 		tx.Clauses(clause.Where{Exprs: queryConds})
 	}
 
-	return tx
+	return tx.Scopes(association.scopes...)
 }
@@ -0,0 +1,227 @@
+package gorm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// associationJSON the association selected as the JSON array column, see SelectAssociationJSON
+type associationJSON struct {
+	Name   string
+	Column string
+}
+
+// SelectAssociationJSON selects the rows of the association as the JSON array column aggregated by the database, which
+// is unmarshalled into the association field of the scanned structs, the parents and their children are loaded in one
+// query instead of the queries of Preload, e.g: the small result sets
+//
+//	// SELECT `orders`.*,(SELECT json_group_array(json_object('id',`items_json`.`id`,...)) FROM `items` `items_json`
+//	// WHERE `items_json`.`order_id` = `orders`.`id`) AS `items_json` FROM `orders`
+//	db.Model(&Order{}).SelectAssociationJSON("Items", "items_json").Find(&orders)
+//
+// The has one, has many, belongs to and many to many relationships are supported by postgres, mysql and sqlite, the
+// keys of the JSON objects are the column names of the association, the parents without children get the empty slices,
+// the query clauses of the association are not applied, e.g: the soft deleted children are selected as well
+func (db *DB) SelectAssociationJSON(name string, column string) (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.associationJSONs = append(tx.Statement.associationJSONs, associationJSON{Name: name, Column: column})
+	return
+}
+
+// AssociationJSONColumns returns the columns aggregating the associations of SelectAssociationJSON, the query callbacks
+// append them to the selected columns
+func (stmt *Statement) AssociationJSONColumns() []clause.Column {
+	if len(stmt.associationJSONs) == 0 {
+		return nil
+	}
+
+	if stmt.Schema == nil {
+		stmt.AddError(fmt.Errorf("%w when using SelectAssociationJSON", ErrModelValueRequired))
+		return nil
+	}
+
+	var aggregate func(object string) string
+	switch name := stmt.DB.Dialector.Name(); name {
+	case "postgres":
+		aggregate = func(object string) string { return "json_agg(json_build_object(" + object + "))" }
+	case "mysql":
+		aggregate = func(object string) string { return "JSON_ARRAYAGG(JSON_OBJECT(" + object + "))" }
+	case "sqlite":
+		aggregate = func(object string) string { return "json_group_array(json_object(" + object + "))" }
+	default:
+		stmt.AddError(fmt.Errorf("%w: SelectAssociationJSON of %s", ErrUnsupportedDriver, name))
+		return nil
+	}
+
+	columns := make([]clause.Column, 0, len(stmt.associationJSONs))
+	for _, association := range stmt.associationJSONs {
+		rel := stmt.Schema.Relationships.Relations[association.Name]
+		if rel == nil {
+			stmt.AddError(fmt.Errorf("%s: %w for schema %s", association.Name, ErrUnsupportedRelation, stmt.Schema.Name))
+			return nil
+		}
+
+		var (
+			sql   strings.Builder
+			alias = stmt.Quote(association.Column)
+		)
+
+		object := make([]string, 0, len(rel.FieldSchema.DBNames))
+		for _, dbName := range rel.FieldSchema.DBNames {
+			object = append(object, quoteString(dbName)+","+alias+"."+stmt.Quote(dbName))
+		}
+
+		sql.WriteString("(SELECT " + aggregate(strings.Join(object, ",")) + " FROM " + stmt.Quote(rel.FieldSchema.Table) + " " + alias)
+
+		conds := make([]string, 0, len(rel.References))
+		if rel.JoinTable != nil {
+			joinTable := stmt.Quote(rel.JoinTable.Table)
+			sql.WriteString(" JOIN " + joinTable + " ON ")
+			joinConds := make([]string, 0, len(rel.References))
+			for _, ref := range rel.References {
+				foreignKey := joinTable + "." + stmt.Quote(ref.ForeignKey.DBName)
+				if ref.OwnPrimaryKey {
+					conds = append(conds, foreignKey+" = "+stmt.Quote(stmt.Table)+"."+stmt.Quote(ref.PrimaryKey.DBName))
+				} else if ref.PrimaryValue != "" {
+					conds = append(conds, foreignKey+" = "+quoteString(ref.PrimaryValue))
+				} else {
+					joinConds = append(joinConds, foreignKey+" = "+alias+"."+stmt.Quote(ref.PrimaryKey.DBName))
+				}
+			}
+			sql.WriteString(strings.Join(joinConds, " AND "))
+		} else {
+			for _, ref := range rel.References {
+				if ref.OwnPrimaryKey {
+					conds = append(conds, alias+"."+stmt.Quote(ref.ForeignKey.DBName)+" = "+stmt.Quote(stmt.Table)+"."+stmt.Quote(ref.PrimaryKey.DBName))
+				} else if ref.PrimaryValue != "" {
+					conds = append(conds, alias+"."+stmt.Quote(ref.ForeignKey.DBName)+" = "+quoteString(ref.PrimaryValue))
+				} else {
+					conds = append(conds, alias+"."+stmt.Quote(ref.PrimaryKey.DBName)+" = "+stmt.Quote(stmt.Table)+"."+stmt.Quote(ref.ForeignKey.DBName))
+				}
+			}
+		}
+
+		sql.WriteString(" WHERE " + strings.Join(conds, " AND ") + ") AS " + alias)
+		columns = append(columns, clause.Column{Name: sql.String(), Raw: true})
+	}
+	return columns
+}
+
+// quoteString quotes str as the SQL string literal, which is the column names and the polymorphic values of the tags
+func quoteString(str string) string {
+	return "'" + strings.ReplaceAll(str, "'", "''") + "'"
+}
+
+// associationJSONFields returns the relationships of the JSON array columns of SelectAssociationJSON by the index of
+// the scanned columns, nil if no columns are the JSON array columns
+func (stmt *Statement) associationJSONFields(sch *schema.Schema, columns []string) []*schema.Relationship {
+	if len(stmt.associationJSONs) == 0 || sch == nil {
+		return nil
+	}
+
+	var rels []*schema.Relationship
+	for _, association := range stmt.associationJSONs {
+		rel := sch.Relationships.Relations[association.Name]
+		if rel == nil {
+			continue
+		}
+
+		for idx, column := range columns {
+			if column == association.Column {
+				if rels == nil {
+					rels = make([]*schema.Relationship, len(columns))
+				}
+				rels[idx] = rel
+			}
+		}
+	}
+	return rels
+}
+
+// scanAssociationJSON unmarshals the JSON array columns scanned into values into the association fields of
+// reflectValue, NULL is unmarshalled into the empty slice
+func (db *DB) scanAssociationJSON(reflectValue reflect.Value, values []interface{}, rels []*schema.Relationship) {
+	for idx, rel := range rels {
+		if rel == nil {
+			continue
+		}
+
+		var data []byte
+		if v, ok := values[idx].(*interface{}); ok {
+			switch v := (*v).(type) {
+			case []byte:
+				data = v
+			case string:
+				data = []byte(v)
+			}
+		}
+
+		var objects []map[string]interface{}
+		if len(data) > 0 {
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.UseNumber()
+			if err := decoder.Decode(&objects); err != nil {
+				db.AddError(fmt.Errorf("failed to unmarshal %s of %s: %w", rel.Name, rel.Schema.Name, err))
+				continue
+			}
+		}
+
+		results := reflect.MakeSlice(reflect.SliceOf(reflect.PointerTo(rel.FieldSchema.ModelType)), 0, len(objects))
+		for _, object := range objects {
+			elem := reflect.New(rel.FieldSchema.ModelType)
+			for column, value := range object {
+				field := rel.FieldSchema.LookUpField(column)
+				if field == nil {
+					continue
+				}
+
+				switch v := value.(type) {
+				case json.Number:
+					if i, err := v.Int64(); err == nil {
+						value = i
+					} else {
+						value, _ = v.Float64()
+					}
+				case string:
+					// the times are the text of the JSON values, e.g: 2006-01-02 15:04:05.999999999-07:00 of sqlite, which are
+					// scanned by the time fields and the scanners like DeletedAt
+					if field.IndirectFieldType.Kind() != reflect.String {
+						if t, err := parseTimeText(v); err == nil {
+							value = t
+						}
+					}
+				case map[string]interface{}, []interface{}:
+					value, _ = json.Marshal(v)
+				}
+				db.AddError(field.Set(db.Statement.Context, elem, value))
+			}
+			results = reflect.Append(results, elem)
+		}
+
+		switch rel.Type {
+		case schema.HasMany, schema.Many2Many:
+			slice := reflect.MakeSlice(rel.Field.IndirectFieldType, 0, results.Len())
+			isPtr := rel.Field.IndirectFieldType.Elem().Kind() == reflect.Ptr
+			for i := 0; i < results.Len(); i++ {
+				if isPtr {
+					slice = reflect.Append(slice, results.Index(i))
+				} else {
+					slice = reflect.Append(slice, results.Index(i).Elem())
+				}
+			}
+			db.AddError(rel.Field.Set(db.Statement.Context, reflectValue, slice.Interface()))
+		default:
+			if results.Len() > 0 {
+				db.AddError(rel.Field.Set(db.Statement.Context, reflectValue, results.Index(0).Interface()))
+			} else {
+				db.AddError(rel.Field.Set(db.Statement.Context, reflectValue, reflect.New(rel.Field.FieldType).Interface()))
+			}
+		}
+	}
+}
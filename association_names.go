@@ -0,0 +1,84 @@
+package gorm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// associationPathRegexp matches the names of Joins which are the paths of the relationships instead of the raw SQL,
+// e.g: Company and Manager.Company
+var associationPathRegexp = regexp.MustCompile(`^\w+(\.\w+)*$`)
+
+// CheckAssociations returns ErrUnsupportedRelation if the paths of Preload and Joins aren't the relationships of the
+// schema, see Config.StrictAssociations, or clause.Associations of Preload resolves to no relationships
+func (stmt *Statement) CheckAssociations() error {
+	if stmt.Schema == nil {
+		return nil
+	}
+
+	for name := range stmt.Preloads {
+		if err := checkAssociationPath(stmt.Schema, name, true); err != nil {
+			return err
+		}
+	}
+
+	for _, join := range stmt.Joins {
+		if associationPathRegexp.MatchString(join.Name) {
+			if err := checkAssociationPath(stmt.Schema, join.Name, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkAssociation returns the error of the path of Preload or Joins if the schema of the model is known when the
+// path is added, the paths are checked again by the statements if Config.StrictAssociations is true
+func (stmt *Statement) checkAssociation(path string) error {
+	if stmt.Model == nil {
+		return nil
+	}
+
+	// the model is parsed by the statement when it's executed, which returns the error of parsing
+	sch, err := schema.Parse(stmt.Model, stmt.DB.cacheStore, stmt.DB.NamingStrategy)
+	if err != nil {
+		return nil
+	}
+	return checkAssociationPath(sch, path, false)
+}
+
+// checkAssociationPath returns ErrUnsupportedRelation with the closest names if the path isn't the relationships or
+// the embedded structs of sch, e.g: Orders.Itemz, the paths after clause.Associations are checked by the preloading,
+// clause.Associations returns the error if it resolves to no relationships when strict is true
+func checkAssociationPath(sch *schema.Schema, path string, strict bool) error {
+	var (
+		relationships = &sch.Relationships
+		names         = strings.Split(path, ".")
+	)
+
+	for idx, name := range names {
+		if name == clause.Associations {
+			if strict && len(relationships.Relations) == 0 && len(relationships.EmbeddedRelations) == 0 {
+				return fmt.Errorf("%s: %w for schema %s, which has no relationships", path, ErrUnsupportedRelation, sch.Name)
+			}
+			return nil
+		}
+
+		if embedded := relationships.EmbeddedRelations[name]; embedded != nil {
+			relationships = embedded
+		} else if rel := relationships.Relations[name]; rel != nil {
+			relationships, sch = &rel.FieldSchema.Relationships, rel.FieldSchema
+		} else {
+			err := fmt.Errorf("%s: %w for schema %s", strings.Join(names[:idx+1], "."), ErrUnsupportedRelation, sch.Name)
+			if closest := relationships.ClosestNames(name); len(closest) > 0 {
+				err = fmt.Errorf("%w, did you mean %s?", err, strings.Join(closest, " or "))
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package gorm
+
+import (
+	"gorm.io/gorm/clause"
+)
+
+// ConditionsOption the option of BuildConditions passed with the conditions, see DialectPlaceholders and
+// IncludeQueryClauses
+type ConditionsOption func(*conditionsConfig)
+
+type conditionsConfig struct {
+	dialectPlaceholders bool
+	placeholderStart    int
+	queryClauses        bool
+}
+
+// DialectPlaceholders builds the placeholders of the dialector instead of ?, the numbered placeholders start at start,
+// e.g: $3 of postgres for the conditions following two vars of the raw SQL, which is 1 if it's less than 1
+func DialectPlaceholders(start int) ConditionsOption {
+	return func(config *conditionsConfig) {
+		config.dialectPlaceholders = true
+		config.placeholderStart = start
+	}
+}
+
+// IncludeQueryClauses includes the query clauses of the model in the conditions unless Unscoped, e.g: the soft delete
+// condition
+func IncludeQueryClauses() ConditionsOption {
+	return func(config *conditionsConfig) {
+		config.queryClauses = true
+	}
+}
+
+// questionPlaceholders the dialector binding the vars with ?, which builds the conditions of BuildConditions
+type questionPlaceholders struct {
+	Dialector
+}
+
+func (questionPlaceholders) BindVarTo(writer clause.Writer, stmt *Statement, v interface{}) {
+	writer.WriteByte('?')
+}
+
+// BuildConditions builds the conditions with the Where conditions of db into the SQL fragment and the vars without
+// executing it, the conditions are built against the schema of the model like Where, so the renamed columns and
+// the struct conditions work, the fragment could be used by the raw SQL, the placeholders are ? unless
+// DialectPlaceholders, the subqueries are built with their dialectors, e.g:
+//
+//	// sql: `users`.`name` = ? AND `users`.`age` = ? AND `users`.`deleted_at` IS NULL
+//	sql, vars, err := gorm.BuildConditions(db.Model(&User{}), &User{Name: "jinzhu", Age: 18}, gorm.IncludeQueryClauses())
+//	db.Raw("SELECT name, SUM(amount) FROM users JOIN orders ON orders.user_id = users.id WHERE "+sql+" GROUP BY name", vars...).Scan(&results)
+func BuildConditions(db *DB, conds ...interface{}) (sql string, vars []interface{}, err error) {
+	var (
+		config    conditionsConfig
+		condExprs = make([]interface{}, 0, len(conds))
+	)
+	for _, cond := range conds {
+		if opt, ok := cond.(ConditionsOption); ok {
+			opt(&config)
+		} else {
+			condExprs = append(condExprs, cond)
+		}
+	}
+
+	tx := db.Session(&Session{DryRun: true}).getInstance()
+	stmt := tx.Statement
+	if !config.dialectPlaceholders {
+		tx.Config.Dialector = questionPlaceholders{Dialector: tx.Dialector}
+	}
+
+	if model := stmt.Model; model != nil || stmt.Dest != nil {
+		if model == nil {
+			model = stmt.Dest
+		}
+
+		if err := stmt.Parse(model); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if len(condExprs) > 0 {
+		if exprs := stmt.BuildCondition(condExprs[0], condExprs[1:]...); len(exprs) > 0 {
+			stmt.AddClause(clause.Where{Exprs: exprs})
+		}
+	}
+
+	if config.queryClauses && stmt.Schema != nil {
+		for _, c := range stmt.Schema.QueryClauses {
+			stmt.AddClause(c)
+		}
+	}
+
+	if tx.Error != nil {
+		return "", nil, tx.Error
+	}
+
+	// the numbered placeholders of the dialector are numbered by the vars
+	offset := 0
+	if config.placeholderStart > 1 {
+		offset = config.placeholderStart - 1
+	}
+	stmt.SQL.Reset()
+	stmt.Vars = make([]interface{}, offset)
+
+	if where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where); ok {
+		where.Build(stmt)
+	}
+
+	if tx.Error != nil {
+		return "", nil, tx.Error
+	}
+	return stmt.SQL.String(), stmt.Vars[offset:], nil
+}
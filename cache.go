@@ -0,0 +1,302 @@
+package gorm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// CacheStore stores the cached results of reads, see Config.CacheStore, values set with zero ttl never expire
+type CacheStore interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
+	Delete(ctx context.Context, key string)
+}
+
+// CacheCodec serializes the cached results
+type CacheCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// GobCacheCodec serializes the cached results with encoding/gob, the concrete types stored in interface values, e.g.
+// the values of []map[string]interface{}, should be registered with gob.Register
+type GobCacheCodec struct{}
+
+func (GobCacheCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (GobCacheCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// MemoryCacheStore in-memory CacheStore of a single process, mostly for tests
+type MemoryCacheStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+}
+
+type memoryCacheEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryCacheStore returns an empty MemoryCacheStore
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{entries: map[string]memoryCacheEntry{}}
+}
+
+func (s *MemoryCacheStore) Get(ctx context.Context, key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if ok && !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		delete(s.entries, key)
+		return nil, false
+	}
+	return entry.value, ok
+}
+
+func (s *MemoryCacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := memoryCacheEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	s.entries[key] = entry
+}
+
+func (s *MemoryCacheStore) Delete(ctx context.Context, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+// Len returns the number of the cached entries, including the table versions
+func (s *MemoryCacheStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// cacheTablePrefix the prefix of the keys of the table versions, which are part of the keys of the cached results of the
+// reads of the tables, so changing the version invalidates them without tracking their keys
+const cacheTablePrefix = "gorm:cache:table:"
+
+// InvalidateCache deletes the cached results of the reads of tables, the writes of creates, updates and deletes
+// invalidate their tables automatically, which should be done manually for the writes of raw SQL, e.g:
+//
+//	db.Exec("UPDATE users SET age = age + 1")
+//	db.InvalidateCache("users")
+//
+// the invalidation is deferred to the commit in transactions, see DB.OnCommit
+func (db *DB) InvalidateCache(tables ...string) *DB {
+	tx := db.getInstance()
+	tx.Statement.invalidateCache(tables...)
+	return tx
+}
+
+// InvalidateCache deletes the cached results of the reads of the table of the statement, after the transaction of it
+// committed, so the rolled back writes don't invalidate them, nor the reads before the commit cache the stale results
+// after the invalidation
+func (stmt *Statement) InvalidateCache() {
+	if stmt.Table != "" {
+		stmt.invalidateCache(stmt.Table)
+	}
+}
+
+func (stmt *Statement) invalidateCache(tables ...string) {
+	store := stmt.DB.CacheStore
+	if store == nil || len(tables) == 0 {
+		return
+	}
+
+	// the statement could be reused before the commit
+	ctx := stmt.Context
+	stmt.DB.OnCommit(func() {
+		for _, table := range tables {
+			setCacheTableVersion(ctx, store, table)
+		}
+	})
+}
+
+// LoadCache loads the cached results of the query to the dest, returns false if caching is disabled or missed, the
+// reads in transactions are never cached
+func (stmt *Statement) LoadCache() bool {
+	if !stmt.caching() {
+		return false
+	}
+
+	stmt.cacheKeyResolved = ""
+	data, ok := stmt.DB.CacheStore.Get(stmt.Context, stmt.cacheKey())
+	if !ok {
+		return false
+	}
+
+	stmt.ReflectValue.Set(reflect.Zero(stmt.ReflectValue.Type()))
+	if err := stmt.cacheCodec().Unmarshal(data, stmt.ReflectValue.Addr().Interface()); err != nil {
+		stmt.DB.Logger.Warn(stmt.Context, "failed to load the cached results of %s: %v", stmt.SQL.String(), err)
+		return false
+	}
+
+	stmt.cacheHit = true
+	stmt.DB.RowsAffected = 1
+	if kind := stmt.ReflectValue.Kind(); kind == reflect.Slice || kind == reflect.Array {
+		stmt.DB.RowsAffected = int64(stmt.ReflectValue.Len())
+	}
+	return true
+}
+
+// CacheHit reports whether the results of the query were loaded from CacheStore
+func (stmt *Statement) CacheHit() bool {
+	return stmt.cacheHit
+}
+
+// SaveCache caches the found results of the query with CacheTTL, whose key contains the versions of the tables read by
+// the query and its joins and preloads, empty results are not cached
+func (stmt *Statement) SaveCache() {
+	if stmt.cacheHit || stmt.DB.Error != nil || stmt.DB.RowsAffected == 0 || !stmt.caching() {
+		return
+	}
+
+	data, err := stmt.cacheCodec().Marshal(stmt.ReflectValue.Addr().Interface())
+	if err != nil {
+		stmt.DB.Logger.Warn(stmt.Context, "failed to cache the results of %s: %v", stmt.SQL.String(), err)
+		return
+	}
+
+	stmt.DB.CacheStore.Set(stmt.Context, stmt.cacheKey(), data, stmt.DB.CacheTTL)
+}
+
+func (stmt *Statement) caching() bool {
+	// the redacted results aren't cached, nor loaded from the results cached without redaction
+	if stmt.DB.CacheStore == nil || stmt.DB.redact || stmt.DB.CacheTTL <= 0 || stmt.DB.DryRun || stmt.Dest == nil || !stmt.ReflectValue.CanAddr() {
+		return false
+	}
+
+	_, inTransaction := stmt.ConnPool.(TxCommitter)
+	return !inTransaction
+}
+
+func (stmt *Statement) cacheCodec() CacheCodec {
+	if stmt.DB.CacheCodec != nil {
+		return stmt.DB.CacheCodec
+	}
+	return GobCacheCodec{}
+}
+
+// cacheKey returns the key of the cached results derived from the SQL, vars, the type of the dest and the versions of
+// the read tables, prefixed with CacheKey, it's resolved once per query, so the results read before an invalidation are
+// never cached with the versions after it
+func (stmt *Statement) cacheKey() string {
+	if stmt.cacheKeyResolved != "" {
+		return stmt.cacheKeyResolved
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(stmt.DB.Dialector.Explain(stmt.SQL.String(), stmt.Vars...)))
+	hash.Write([]byte{0})
+	hash.Write([]byte(stmt.ReflectValue.Type().String()))
+	for _, table := range stmt.cacheTables() {
+		hash.Write([]byte{0})
+		hash.Write([]byte(cacheTableVersion(stmt.Context, stmt.DB.CacheStore, table)))
+	}
+
+	key := "gorm:cache:"
+	if stmt.CacheKey != "" {
+		key += stmt.CacheKey + ":"
+	}
+	stmt.cacheKeyResolved = key + hex.EncodeToString(hash.Sum(nil))
+	return stmt.cacheKeyResolved
+}
+
+// cacheTables returns the tables whose writes invalidate the cached results of the query
+func (stmt *Statement) cacheTables() []string {
+	tables := make([]string, 0, 1+len(stmt.Joins)+len(stmt.Preloads))
+	addTable := func(table string) {
+		for _, t := range tables {
+			if t == table {
+				return
+			}
+		}
+		if table != "" {
+			tables = append(tables, table)
+		}
+	}
+	addTable(stmt.Table)
+
+	if stmt.Schema == nil {
+		return tables
+	}
+
+	addRelation := func(rel *schema.Relationship) {
+		addTable(rel.FieldSchema.Table)
+		if rel.JoinTable != nil {
+			addTable(rel.JoinTable.Table)
+		}
+	}
+	addRelations := func(path string) {
+		s := stmt.Schema
+		for _, name := range strings.Split(path, ".") {
+			if name == clause.Associations {
+				for _, rel := range s.Relationships.Relations {
+					addRelation(rel)
+				}
+				return
+			}
+
+			rel, ok := s.Relationships.Relations[name]
+			if !ok {
+				return
+			}
+			addRelation(rel)
+			s = rel.FieldSchema
+		}
+	}
+
+	for _, join := range stmt.Joins {
+		addRelations(join.Name)
+	}
+	for name := range stmt.Preloads {
+		addRelations(name)
+	}
+	return tables
+}
+
+// cacheTableVersion returns the current version of table, a new version is set if it's missing, e.g: evicted by the
+// store, so the results cached with a previous version are never loaded again
+func cacheTableVersion(ctx context.Context, store CacheStore, table string) string {
+	if version, ok := store.Get(ctx, cacheTablePrefix+table); ok {
+		return string(version)
+	}
+	return setCacheTableVersion(ctx, store, table)
+}
+
+// setCacheTableVersion sets a new unique version of table, the versions never expire, so there is one per table
+func setCacheTableVersion(ctx context.Context, store CacheStore, table string) string {
+	id, err := NewUUIDv7()
+	if err != nil {
+		id = [16]byte{}
+		binary.BigEndian.PutUint64(id[:], uint64(time.Now().UnixNano()))
+	}
+
+	version := hex.EncodeToString(id[:])
+	store.Set(ctx, cacheTablePrefix+table, []byte(version), 0)
+	return version
+}
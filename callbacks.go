@@ -4,10 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path"
 	"reflect"
 	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
 	"gorm.io/gorm/utils"
 )
@@ -25,6 +29,40 @@ func initializeCallbacks(db *DB) *callbacks {
 	}
 }
 
+// ModelHook hooks called with every model row of the statement, e.g. for audit, without defining hook methods on
+// each model. Before hooks are called before the model's own hooks, After hooks are called after them, multiple
+// model hooks are called in the order registered.
+type ModelHook struct {
+	BeforeCreate func(tx *DB, model interface{}) error
+	AfterCreate  func(tx *DB, model interface{}) error
+	BeforeUpdate func(tx *DB, model interface{}) error
+	AfterUpdate  func(tx *DB, model interface{}) error
+	BeforeDelete func(tx *DB, model interface{}) error
+	AfterDelete  func(tx *DB, model interface{}) error
+	AfterFind    func(tx *DB, model interface{}) error
+
+	// Filter only calls the hook for the models it returns true, e.g. models implementing a marker interface
+	Filter func(model interface{}) bool
+	// Schemas only calls the hook for the models with the given schema names, empty means all models
+	Schemas []string
+}
+
+// Match returns whether the hook should be called for model of schema s
+func (hook ModelHook) Match(s *schema.Schema, model interface{}) bool {
+	if len(hook.Schemas) > 0 && (s == nil || !utils.Contains(hook.Schemas, s.Name)) {
+		return false
+	}
+	return hook.Filter == nil || hook.Filter(model)
+}
+
+// RegisterModelHook registers a hook called with every model row, it should be registered at initialization,
+// sessions created before won't call it
+func (db *DB) RegisterModelHook(hook ModelHook) {
+	// sessions share the backing array of the hooks, copy it so other sessions aren't changed
+	hooks := db.Config.ModelHooks
+	db.Config.ModelHooks = append(hooks[:len(hooks):len(hooks)], hook)
+}
+
 // callbacks gorm callbacks manager
 type callbacks struct {
 	processors map[string]*processor
@@ -34,18 +72,33 @@ type processor struct {
 	db        *DB
 	Clauses   []string
 	fns       []func(*DB)
+	names     []string
 	callbacks []*callback
 }
 
 type callback struct {
-	name      string
-	before    string
-	after     string
-	remove    bool
-	replace   bool
-	match     func(*DB) bool
-	handler   func(*DB)
-	processor *processor
+	name          string
+	before        string
+	after         string
+	beforePattern string
+	afterPattern  string
+	remove        bool
+	replace       bool
+	location      string
+	match         func(*DB) bool
+	handler       func(*DB)
+	processor     *processor
+}
+
+// CallbackInfo the information of a registered callback, returned by Inspect
+type CallbackInfo struct {
+	Name   string
+	Before string
+	After  string
+	// Location where the callback registered
+	Location string
+	// Replaced locations of the callbacks with the same name it replaced
+	Replaced []string
 }
 
 func (cs *callbacks) Create() *processor {
@@ -64,10 +117,25 @@ func (cs *callbacks) Delete() *processor {
 	return cs.processors["delete"]
 }
 
+// Row the callbacks of Row, Rows and Scan, the SQL is built by gorm:build_row_sql unless it is written by Raw, and
+// queried by gorm:row, the callbacks registered between them could inspect or modify Statement.SQL and Statement.Vars,
+// or add an error to stop the query, e.g:
+//
+//	db.Callback().Row().After("gorm:build_row_sql").Before("gorm:row").Register("audit:row", auditRow)
+//
+// The SQL should be modified before gorm:row, which executes it, the prepared statements are cached by the SQL
 func (cs *callbacks) Row() *processor {
 	return cs.processors["row"]
 }
 
+// Raw the callbacks of Exec, the SQL is built before the callbacks and executed by gorm:raw, the callbacks registered
+// before it could inspect or modify Statement.SQL and Statement.Vars, or add an error to stop the execution, e.g:
+//
+//	db.Callback().Raw().Before("gorm:raw").Register("audit:raw", func(db *gorm.DB) {
+//		db.Statement.SQL.WriteString(" /* audited */")
+//	})
+//
+// The SQL should be modified before gorm:raw, which executes it, the prepared statements are cached by the SQL
 func (cs *callbacks) Raw() *processor {
 	return cs.processors["raw"]
 }
@@ -89,6 +157,25 @@ func (p *processor) Execute(db *DB) *DB {
 		resetBuildClauses = true
 	}
 
+	if isConnReleased(stmt.ConnPool) {
+		db.AddError(ErrConnReleased)
+	}
+
+	// the remaining statements of the transaction aren't executed once its context is done, see DB.Transaction
+	if progress := stmt.transactionProgress(); progress != nil && db.Error == nil {
+		if err := stmt.Interrupted("transaction", int(atomic.LoadInt64(progress))); err != nil {
+			db.AddError(err)
+		} else {
+			atomic.AddInt64(progress, 1)
+		}
+	}
+
+	// record the statement once built, before the statements of preloads and associations run by later callbacks
+	recorded := !db.DryRun
+	if !recorded && stmt.sqlStatements == nil {
+		stmt.sqlStatements = &[]SQLStatement{}
+	}
+
 	if optimizer, ok := db.Statement.Dest.(StatementModifier); ok {
 		optimizer.ModifyStatement(stmt)
 	}
@@ -126,23 +213,76 @@ func (p *processor) Execute(db *DB) *DB {
 		}
 	}
 
-	for _, f := range p.fns {
-		f(db)
+	// use the shard table decided by the sharder, the statements executed on the shards by it are finished
+	if db.Sharder != nil && db.Error == nil && stmt.Schema != nil && stmt.TableExpr == nil && stmt.Table == stmt.Schema.Table && stmt.SQL.Len() == 0 {
+		if p.shard(db) {
+			if resetBuildClauses {
+				stmt.BuildClauses = nil
+			}
+			return db
+		}
+	}
+
+	var fetchWarnings func()
+	if fetcher, ok := db.Dialector.(WarningFetcher); ok && db.CaptureWarnings && !db.DryRun && db.Error == nil {
+		fetchWarnings = stmt.captureWarnings(fetcher)
+	}
+
+	if db.TraceCallbacks {
+		for idx, f := range p.fns {
+			beginAt := time.Now()
+			f(db)
+			db.Logger.Info(stmt.Context, "callback `%s` finished in %s", p.names[idx], time.Since(beginAt))
+			if !recorded && stmt.SQL.Len() > 0 && db.Error == nil {
+				stmt.recordSQLStatement()
+				recorded = true
+			}
+		}
+	} else {
+		for _, f := range p.fns {
+			f(db)
+			if !recorded && stmt.SQL.Len() > 0 && db.Error == nil {
+				stmt.recordSQLStatement()
+				recorded = true
+			}
+		}
+	}
+
+	if db.DryRun && db.CommentDryRun && stmt.SQL.Len() > 0 {
+		if comment := stmt.SQLComment(); comment != "" {
+			stmt.SQL.WriteString(" " + comment)
+		}
 	}
 
 	if stmt.SQL.Len() > 0 {
-		db.Logger.Trace(stmt.Context, curTime, func() (string, int64) {
+		traceLogger := stmt.traceLogger(stmt.SQL.String())
+		traceLogger.Trace(stmt.Context, curTime, func() (string, int64) {
 			sql, vars := stmt.SQL.String(), stmt.Vars
-			if filter, ok := db.Logger.(ParamsFilter); ok {
+			if filter, ok := traceLogger.(ParamsFilter); ok {
 				sql, vars = filter.ParamsFilter(stmt.Context, stmt.SQL.String(), stmt.Vars...)
 			}
-			return db.Dialector.Explain(sql, vars...), db.RowsAffected
+			// logs the comment executed with the SQL, which isn't explained with the vars
+			if sql = db.Dialector.Explain(sql, vars...); stmt.sqlComment != "" {
+				sql += " " + stmt.sqlComment
+			}
+			return sql, db.RowsAffected
 		}, db.Error)
+
+		if explainer, ok := db.Logger.(SlowQueryExplainer); ok && !db.DryRun {
+			if sql := stmt.SQL.String(); explainable(sql) && explainer.ShouldExplain(stmt.Context, sql, time.Since(curTime)) {
+				db.explainSlowQuery(sql, stmt.Vars)
+			}
+		}
+	}
+
+	if fetchWarnings != nil {
+		fetchWarnings()
 	}
 
 	if !stmt.DB.DryRun {
 		stmt.SQL.Reset()
-		stmt.Vars = nil
+		stmt.resetVars()
+		stmt.sqlComment = ""
 	}
 
 	if resetBuildClauses {
@@ -152,6 +292,43 @@ func (p *processor) Execute(db *DB) *DB {
 	return db
 }
 
+// traceLogger returns the logger tracing the sql of the statement, whose log level is decided by DebugFilter and
+// LogLevelByTable
+func (stmt *Statement) traceLogger(sql string) logger.Interface {
+	db := stmt.DB
+	if db.DebugFilter != nil && db.DebugFilter(stmt) {
+		return db.Logger.LogMode(logger.Info)
+	}
+
+	if len(db.LogLevelByTable) == 0 {
+		return db.Logger
+	}
+
+	tables := []string{stmt.Table}
+	if stmt.Table == "" {
+		tables = utils.SQLTables(sql)
+	}
+
+	var (
+		level   logger.LogLevel
+		matched bool
+	)
+	for _, table := range tables {
+		if l, ok := db.LogLevelByTable[table]; ok && (!matched || l > level) {
+			level, matched = l, true
+		}
+	}
+
+	if !matched {
+		level, matched = db.LogLevelByTable["*"]
+	}
+
+	if matched {
+		return db.Logger.LogMode(level)
+	}
+	return db.Logger
+}
+
 func (p *processor) Get(name string) func(*DB) {
 	for i := len(p.callbacks) - 1; i >= 0; i-- {
 		if v := p.callbacks[i]; v.name == name && !v.remove {
@@ -161,12 +338,16 @@ func (p *processor) Get(name string) func(*DB) {
 	return nil
 }
 
+// Before registers the callback before the callback name, name could be a glob pattern like `*:audit`, then it
+// will be registered before the first registered callback matching it
 func (p *processor) Before(name string) *callback {
-	return &callback{before: name, processor: p}
+	return (&callback{processor: p}).Before(name)
 }
 
+// After registers the callback after the callback name, name could be a glob pattern like `*:audit`, then it
+// will be registered after the last registered callback matching it
 func (p *processor) After(name string) *callback {
-	return &callback{after: name, processor: p}
+	return (&callback{processor: p}).After(name)
 }
 
 func (p *processor) Match(fc func(*DB) bool) *callback {
@@ -185,6 +366,30 @@ func (p *processor) Replace(name string, fn func(*DB)) error {
 	return (&callback{processor: p}).Replace(name, fn)
 }
 
+// Inspect returns the callbacks in execution order, with their constraints and where they were registered
+func (p *processor) Inspect() []CallbackInfo {
+	infos := make([]CallbackInfo, 0, len(p.names))
+	for _, name := range p.names {
+		info := CallbackInfo{Name: name}
+		for _, c := range p.callbacks {
+			if c.name == name && !c.remove {
+				if info.Location != "" {
+					info.Replaced = append(info.Replaced, info.Location)
+				}
+				info.Before, info.After, info.Location = c.before, c.after, c.location
+				if c.beforePattern != "" {
+					info.Before = c.beforePattern
+				}
+				if c.afterPattern != "" {
+					info.After = c.afterPattern
+				}
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
 func (p *processor) compile() (err error) {
 	var callbacks []*callback
 	removedMap := map[string]bool{}
@@ -202,25 +407,34 @@ func (p *processor) compile() (err error) {
 	}
 	p.callbacks = callbacks
 
-	if p.fns, err = sortCallbacks(p.callbacks); err != nil {
+	if p.fns, p.names, err = sortCallbacks(p.callbacks); err != nil {
 		p.db.Logger.Error(context.Background(), "Got error when compile callbacks, got %v", err)
 	}
 	return
 }
 
 func (c *callback) Before(name string) *callback {
-	c.before = name
+	if isCallbackPattern(name) {
+		c.beforePattern = name
+	} else {
+		c.before = name
+	}
 	return c
 }
 
 func (c *callback) After(name string) *callback {
-	c.after = name
+	if isCallbackPattern(name) {
+		c.afterPattern = name
+	} else {
+		c.after = name
+	}
 	return c
 }
 
 func (c *callback) Register(name string, fn func(*DB)) error {
 	c.name = name
 	c.handler = fn
+	c.location = utils.FileWithLineNum()
 	c.processor.callbacks = append(c.processor.callbacks, c)
 	return c.processor.compile()
 }
@@ -229,6 +443,7 @@ func (c *callback) Remove(name string) error {
 	c.processor.db.Logger.Warn(context.Background(), "removing callback `%s` from %s\n", name, utils.FileWithLineNum())
 	c.name = name
 	c.remove = true
+	c.location = utils.FileWithLineNum()
 	c.processor.callbacks = append(c.processor.callbacks, c)
 	return c.processor.compile()
 }
@@ -238,6 +453,7 @@ func (c *callback) Replace(name string, fn func(*DB)) error {
 	c.name = name
 	c.handler = fn
 	c.replace = true
+	c.location = utils.FileWithLineNum()
 	c.processor.callbacks = append(c.processor.callbacks, c)
 	return c.processor.compile()
 }
@@ -252,11 +468,42 @@ func getRIndex(strs []string, str string) int {
 	return -1
 }
 
-func sortCallbacks(cs []*callback) (fns []func(*DB), err error) {
+// isCallbackPattern returns whether name is a glob pattern, `*` alone means the first or last callback
+func isCallbackPattern(name string) bool {
+	return name != "*" && strings.ContainsAny(name, "*?[")
+}
+
+// resolveCallbackPatterns resolves the before/after patterns to the first/last matched callback name
+func resolveCallbackPatterns(cs []*callback) {
+	for _, c := range cs {
+		if c.beforePattern != "" {
+			c.before = ""
+			for _, m := range cs {
+				if matched, _ := path.Match(c.beforePattern, m.name); matched && m.name != c.name && !m.remove {
+					c.before = m.name
+					break
+				}
+			}
+		}
+
+		if c.afterPattern != "" {
+			c.after = ""
+			for i := len(cs) - 1; i >= 0; i-- {
+				if matched, _ := path.Match(c.afterPattern, cs[i].name); matched && cs[i].name != c.name && !cs[i].remove {
+					c.after = cs[i].name
+					break
+				}
+			}
+		}
+	}
+}
+
+func sortCallbacks(cs []*callback) (fns []func(*DB), sortedNames []string, err error) {
 	var (
 		names, sorted []string
 		sortCallback  func(*callback) error
 	)
+	resolveCallbackPatterns(cs)
 	sort.SliceStable(cs, func(i, j int) bool {
 		if cs[j].before == "*" && cs[i].before != "*" {
 			return true
@@ -342,6 +589,7 @@ func sortCallbacks(cs []*callback) (fns []func(*DB), err error) {
 	for _, name := range sorted {
 		if idx := getRIndex(names, name); !cs[idx].remove {
 			fns = append(fns, cs[idx].handler)
+			sortedNames = append(sortedNames, name)
 		}
 	}
 
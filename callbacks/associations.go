@@ -1,6 +1,7 @@
 package callbacks
 
 import (
+	"errors"
 	"reflect"
 	"strings"
 
@@ -346,10 +347,11 @@ func SaveAfterAssociations(create bool) func(db *gorm.DB) {
 					}
 				}
 
-				if joins.Len() > 0 {
+				if joins.Len() > 0 && !associationsInterrupted(db) {
+					disableNestedTransaction := true
 					db.AddError(db.Session(&gorm.Session{NewDB: true}).Clauses(clause.OnConflict{DoNothing: true}).Session(&gorm.Session{
 						SkipHooks:                db.Statement.SkipHooks,
-						DisableNestedTransaction: true,
+						DisableNestedTransaction: &disableNestedTransaction,
 					}).Create(joins.Interface()).Error)
 				}
 			}
@@ -377,7 +379,7 @@ func onConflictOption(stmt *gorm.Statement, s *schema.Schema, defaultUpdatingCol
 
 func saveAssociations(db *gorm.DB, rel *schema.Relationship, rValues reflect.Value, selectColumns map[string]bool, restricted bool, defaultUpdatingColumns []string) error {
 	// stop save association loop
-	if checkAssociationsSaved(db, rValues) {
+	if checkAssociationsSaved(db, rValues) || associationsInterrupted(db) {
 		return nil
 	}
 
@@ -403,10 +405,11 @@ func saveAssociations(db *gorm.DB, rel *schema.Relationship, rValues reflect.Val
 		}
 	}
 
+	disableNestedTransaction := true
 	tx := db.Session(&gorm.Session{NewDB: true}).Clauses(onConflict).Session(&gorm.Session{
 		FullSaveAssociations:     db.FullSaveAssociations,
 		SkipHooks:                db.Statement.SkipHooks,
-		DisableNestedTransaction: true,
+		DisableNestedTransaction: &disableNestedTransaction,
 	})
 
 	db.Statement.Settings.Range(func(k, v interface{}) bool {
@@ -431,6 +434,28 @@ func saveAssociations(db *gorm.DB, rel *schema.Relationship, rValues reflect.Val
 	return db.AddError(tx.Create(values).Error)
 }
 
+// savedAssociationsKey the count of the association saves of the statement
+const savedAssociationsKey = "gorm:saved_associations"
+
+// associationsInterrupted adds *gorm.InterruptedError and returns true if the context is done before saving the next
+// associations, the remaining associations aren't saved
+func associationsInterrupted(db *gorm.DB) bool {
+	var interrupted *gorm.InterruptedError
+	if errors.As(db.Error, &interrupted) {
+		return true
+	}
+
+	saved, _ := db.InstanceGet(savedAssociationsKey)
+	completed, _ := saved.(int)
+	if err := db.Statement.Interrupted("save associations", completed); err != nil {
+		db.AddError(err)
+		return true
+	}
+
+	db.InstanceSet(savedAssociationsKey, completed+1)
+	return false
+}
+
 // check association values has been saved
 // if values kind is Struct, check it has been saved
 // if values kind is Slice/Array, check all items have been saved
@@ -451,3 +476,10 @@ func checkAssociationsSaved(db *gorm.DB, values reflect.Value) bool {
 
 	return false
 }
+
+// CheckForeignKeys checks the parents referenced by the created or updated values exist, see Config.EmulateForeignKeys
+func CheckForeignKeys(db *gorm.DB) {
+	if db.Error == nil && db.Statement.Schema != nil && !db.DryRun {
+		db.AddError(db.Statement.CheckForeignKeys())
+	}
+}
@@ -37,14 +37,38 @@ func RegisterDefaultCallbacks(db *gorm.DB, config *Config) {
 		config.UpdateClauses = updateClauses
 	}
 
+	// the dialector declaring CapabilityReturning builds or skips RETURNING for creates, updates and deletes
+	if dialector, ok := db.Dialector.(gorm.CapabilityDialector); ok {
+		if supported, ok := dialector.Capabilities()[gorm.CapabilityReturning]; ok {
+			config.CreateClauses = withReturningClause(config.CreateClauses, supported)
+			config.UpdateClauses = withReturningClause(config.UpdateClauses, supported)
+			config.DeleteClauses = withReturningClause(config.DeleteClauses, supported)
+		}
+
+		// UPDATE ... SET ... FROM, the FROM clause is only built when it is added to the statement
+		if supported, ok := dialector.Capabilities()[gorm.CapabilityUpdateFrom]; ok {
+			config.UpdateClauses = withUpdateFromClause(config.UpdateClauses, supported)
+		}
+
+		// UPDATE/DELETE ... ORDER BY ... LIMIT, which are rewritten into primary key subqueries if unsupported
+		if supported, ok := dialector.Capabilities()[gorm.CapabilityLimitedWrite]; ok {
+			config.UpdateClauses = withLimitClauses(config.UpdateClauses, supported)
+			config.DeleteClauses = withLimitClauses(config.DeleteClauses, supported)
+		}
+	}
+
 	createCallback := db.Callback().Create()
 	createCallback.Match(enableTransaction).Register("gorm:begin_transaction", BeginTransaction)
 	createCallback.Register("gorm:before_create", BeforeCreate)
 	createCallback.Register("gorm:save_before_associations", SaveBeforeAssociations(true))
+	createCallback.Register("gorm:check_foreign_keys", CheckForeignKeys)
 	createCallback.Register("gorm:create", Create(config))
 	createCallback.Register("gorm:save_after_associations", SaveAfterAssociations(true))
 	createCallback.Register("gorm:after_create", AfterCreate)
+	createCallback.Register("gorm:register_transaction_hooks", RegisterTransactionHooks)
 	createCallback.Match(enableTransaction).Register("gorm:commit_or_rollback_transaction", CommitOrRollbackTransaction)
+	createCallback.Register("gorm:invalidate_cache", InvalidateCache)
+	createCallback.Register("gorm:partial_batch", PartialBatch)
 	createCallback.Clauses = config.CreateClauses
 
 	queryCallback := db.Callback().Query()
@@ -59,7 +83,9 @@ func RegisterDefaultCallbacks(db *gorm.DB, config *Config) {
 	deleteCallback.Register("gorm:delete_before_associations", DeleteBeforeAssociations)
 	deleteCallback.Register("gorm:delete", Delete(config))
 	deleteCallback.Register("gorm:after_delete", AfterDelete)
+	deleteCallback.Register("gorm:register_transaction_hooks", RegisterTransactionHooks)
 	deleteCallback.Match(enableTransaction).Register("gorm:commit_or_rollback_transaction", CommitOrRollbackTransaction)
+	deleteCallback.Register("gorm:invalidate_cache", InvalidateCache)
 	deleteCallback.Clauses = config.DeleteClauses
 
 	updateCallback := db.Callback().Update()
@@ -67,13 +93,18 @@ func RegisterDefaultCallbacks(db *gorm.DB, config *Config) {
 	updateCallback.Register("gorm:setup_reflect_value", SetupUpdateReflectValue)
 	updateCallback.Register("gorm:before_update", BeforeUpdate)
 	updateCallback.Register("gorm:save_before_associations", SaveBeforeAssociations(false))
+	updateCallback.Register("gorm:check_foreign_keys", CheckForeignKeys)
 	updateCallback.Register("gorm:update", Update(config))
 	updateCallback.Register("gorm:save_after_associations", SaveAfterAssociations(false))
 	updateCallback.Register("gorm:after_update", AfterUpdate)
+	updateCallback.Register("gorm:register_transaction_hooks", RegisterTransactionHooks)
 	updateCallback.Match(enableTransaction).Register("gorm:commit_or_rollback_transaction", CommitOrRollbackTransaction)
+	updateCallback.Register("gorm:invalidate_cache", InvalidateCache)
+	updateCallback.Register("gorm:partial_batch", PartialBatch)
 	updateCallback.Clauses = config.UpdateClauses
 
 	rowCallback := db.Callback().Row()
+	rowCallback.Register("gorm:build_row_sql", BuildRowSQL)
 	rowCallback.Register("gorm:row", RowQuery)
 	rowCallback.Clauses = config.QueryClauses
 
@@ -81,3 +112,50 @@ func RegisterDefaultCallbacks(db *gorm.DB, config *Config) {
 	rawCallback.Register("gorm:raw", RawExec)
 	rawCallback.Clauses = config.QueryClauses
 }
+
+// withReturningClause returns the copied clauses with or without RETURNING
+func withReturningClause(clauses []string, returning bool) []string {
+	result := make([]string, 0, len(clauses)+1)
+	for _, name := range clauses {
+		if name != "RETURNING" {
+			result = append(result, name)
+		}
+	}
+
+	if returning {
+		result = append(result, "RETURNING")
+	}
+	return result
+}
+
+// withUpdateFromClause returns the update clauses with FROM after SET if updateFrom, or without FROM
+func withUpdateFromClause(clauses []string, updateFrom bool) []string {
+	result := make([]string, 0, len(clauses)+1)
+	for _, name := range clauses {
+		if name == "FROM" {
+			continue
+		}
+
+		result = append(result, name)
+		if name == "SET" && updateFrom {
+			result = append(result, "FROM")
+		}
+	}
+	return result
+}
+
+// withLimitClauses returns the clauses with ORDER BY and LIMIT after WHERE if limited, or without them
+func withLimitClauses(clauses []string, limited bool) []string {
+	result := make([]string, 0, len(clauses)+2)
+	for _, name := range clauses {
+		if name == "ORDER BY" || name == "LIMIT" {
+			continue
+		}
+
+		result = append(result, name)
+		if name == "WHERE" && limited {
+			result = append(result, "ORDER BY", "LIMIT")
+		}
+	}
+	return result
+}
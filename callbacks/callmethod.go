@@ -12,8 +12,12 @@ func callMethod(db *gorm.DB, fc func(value interface{}, tx *gorm.DB) bool) {
 		switch db.Statement.ReflectValue.Kind() {
 		case reflect.Slice, reflect.Array:
 			db.Statement.CurDestIndex = 0
+			called := calledValues(db)
 			for i := 0; i < db.Statement.ReflectValue.Len(); i++ {
-				if value := reflect.Indirect(db.Statement.ReflectValue.Index(i)); value.CanAddr() {
+				if value := reflect.Indirect(db.Statement.ReflectValue.Index(i)); called(value) {
+					db.Statement.CurDestIndex++
+					continue
+				} else if value.CanAddr() {
 					fc(value.Addr().Interface(), tx)
 				} else {
 					db.AddError(gorm.ErrInvalidValue)
@@ -30,3 +34,129 @@ func callMethod(db *gorm.DB, fc func(value interface{}, tx *gorm.DB) bool) {
 		}
 	}
 }
+
+// calledValues returns the function reporting whether the hooks of the value have been called, the rows of the same
+// primary keys share the struct with Config.DeduplicateByPrimaryKey, whose hooks are called once
+func calledValues(db *gorm.DB) func(value reflect.Value) bool {
+	if !db.DeduplicateByPrimaryKey {
+		return func(reflect.Value) bool { return false }
+	}
+
+	called := map[uintptr]bool{}
+	return func(value reflect.Value) bool {
+		if !value.CanAddr() {
+			return false
+		}
+
+		ptr := value.Addr().Pointer()
+		if called[ptr] {
+			return true
+		}
+		called[ptr] = true
+		return false
+	}
+}
+
+// skipHooks reports whether the hooks should be skipped, they are skipped in DryRun mode unless DryRunWithHooks
+func skipHooks(db *gorm.DB) bool {
+	return db.Statement.SkipHooks || (db.DryRun && !db.DryRunWithHooks)
+}
+
+// callModelHooks calls the registered model hooks of phase with every model row
+func callModelHooks(db *gorm.DB, phase func(hook gorm.ModelHook) func(*gorm.DB, interface{}) error) {
+	if db.Error != nil || db.Statement.Schema == nil || skipHooks(db) || len(db.ModelHooks) == 0 {
+		return
+	}
+
+	tx := db.Session(&gorm.Session{NewDB: true})
+	for _, hook := range db.ModelHooks {
+		fc := phase(hook)
+		if fc == nil {
+			continue
+		}
+
+		call := func(value reflect.Value) bool {
+			if !value.CanAddr() {
+				db.AddError(gorm.ErrInvalidValue)
+				return false
+			}
+
+			if model := value.Addr().Interface(); hook.Match(db.Statement.Schema, model) {
+				db.AddError(fc(tx, model))
+			}
+			return true
+		}
+
+		switch db.Statement.ReflectValue.Kind() {
+		case reflect.Slice, reflect.Array:
+			db.Statement.CurDestIndex = 0
+			called := calledValues(db)
+			for i := 0; i < db.Statement.ReflectValue.Len(); i++ {
+				if value := reflect.Indirect(db.Statement.ReflectValue.Index(i)); !called(value) && !call(value) {
+					return
+				}
+				db.Statement.CurDestIndex++
+			}
+		case reflect.Struct:
+			call(db.Statement.ReflectValue)
+		}
+	}
+}
+
+type partialBatch struct {
+	reflectValue reflect.Value
+	errors       map[int]error
+}
+
+// callPartialBatchHooks calls hooks with every row of the batch separately in PartialBatch mode, the failed rows are
+// excluded from the statement and recorded with their index, returns false if not applicable
+func callPartialBatchHooks(db *gorm.DB, hooks func(*gorm.DB)) bool {
+	reflectValue := db.Statement.ReflectValue
+	if !db.PartialBatch || db.Error != nil || db.Statement.Schema == nil || skipHooks(db) ||
+		(reflectValue.Kind() != reflect.Slice && reflectValue.Kind() != reflect.Array) {
+		return false
+	}
+
+	var (
+		batch   = partialBatch{reflectValue: reflectValue, errors: map[int]error{}}
+		rows    = reflect.MakeSlice(reflect.SliceOf(reflect.PtrTo(db.Statement.Schema.ModelType)), 0, reflectValue.Len())
+		setRows = reflect.New(rows.Type()).Elem()
+	)
+
+	for i := 0; i < reflectValue.Len(); i++ {
+		row := reflect.Indirect(reflectValue.Index(i))
+		if !row.CanAddr() || row.Type() != db.Statement.Schema.ModelType {
+			batch.errors[i] = gorm.ErrInvalidValue
+			continue
+		}
+
+		db.Statement.ReflectValue, db.Statement.CurDestIndex = row, i
+		hooks(db)
+		if db.Error != nil {
+			batch.errors[i] = db.Error
+			db.Error = nil
+		} else {
+			rows = reflect.Append(rows, row.Addr())
+		}
+	}
+
+	setRows.Set(rows)
+	db.Statement.ReflectValue, db.Statement.CurDestIndex = setRows, 0
+	db.InstanceSet("gorm:partial_batch", batch)
+
+	if rows.Len() == 0 {
+		db.AddError(&gorm.BatchError{Errors: batch.errors})
+	}
+	return true
+}
+
+// PartialBatch restores the statement's rows and returns the errors of failed rows in PartialBatch mode
+func PartialBatch(db *gorm.DB) {
+	if v, ok := db.InstanceGet("gorm:partial_batch"); ok {
+		batch := v.(partialBatch)
+		db.Statement.ReflectValue = batch.reflectValue
+		if db.Error == nil && len(batch.errors) > 0 {
+			db.AddError(&gorm.BatchError{Errors: batch.errors, RowsAffected: db.RowsAffected})
+		}
+	}
+}
@@ -8,12 +8,19 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
-	"gorm.io/gorm/utils"
 )
 
 // BeforeCreate before create hooks
 func BeforeCreate(db *gorm.DB) {
-	if db.Error == nil && db.Statement.Schema != nil && !db.Statement.SkipHooks && (db.Statement.Schema.BeforeSave || db.Statement.Schema.BeforeCreate) {
+	if !callPartialBatchHooks(db, beforeCreate) {
+		beforeCreate(db)
+	}
+}
+
+func beforeCreate(db *gorm.DB) {
+	callModelHooks(db, func(hook gorm.ModelHook) func(*gorm.DB, interface{}) error { return hook.BeforeCreate })
+
+	if db.Error == nil && db.Statement.Schema != nil && !skipHooks(db) && (db.Statement.Schema.BeforeSave || db.Statement.Schema.BeforeCreate) {
 		callMethod(db, func(value interface{}, tx *gorm.DB) (called bool) {
 			if db.Statement.Schema.BeforeSave {
 				if i, ok := value.(BeforeSaveInterface); ok {
@@ -35,13 +42,16 @@ func BeforeCreate(db *gorm.DB) {
 
 // Create create hook
 func Create(config *Config) func(db *gorm.DB) {
-	supportReturning := utils.Contains(config.CreateClauses, "RETURNING")
-
 	return func(db *gorm.DB) {
 		if db.Error != nil {
 			return
 		}
 
+		var (
+			supportReturning = supportsReturning(db, config.CreateClauses)
+			returnInserted   bool
+		)
+
 		if db.Statement.Schema != nil {
 			if !db.Statement.Unscoped {
 				for _, c := range db.Statement.Schema.CreateClauses {
@@ -49,13 +59,13 @@ func Create(config *Config) func(db *gorm.DB) {
 				}
 			}
 
-			if supportReturning && len(db.Statement.Schema.FieldsWithDefaultDBValue) > 0 {
-				if _, ok := db.Statement.Clauses["RETURNING"]; !ok {
+			if supportReturning && len(db.Statement.Schema.FieldsWithDefaultDBValue) > 0 && !db.Statement.CreatingFromQuery() {
+				if _, ok := db.Statement.Returning(); !ok {
 					fromColumns := make([]clause.Column, 0, len(db.Statement.Schema.FieldsWithDefaultDBValue))
 					for _, field := range db.Statement.Schema.FieldsWithDefaultDBValue {
 						fromColumns = append(fromColumns, clause.Column{Name: field.DBName})
 					}
-					db.Statement.AddClause(clause.Returning{Columns: fromColumns})
+					db.Statement.AddReturning(fromColumns...)
 				}
 			}
 		}
@@ -63,8 +73,15 @@ func Create(config *Config) func(db *gorm.DB) {
 		if db.Statement.SQL.Len() == 0 {
 			db.Statement.SQL.Grow(180)
 			db.Statement.AddClauseIfNotExists(clause.Insert{})
-			db.Statement.AddClause(ConvertToCreateValues(db.Statement))
+			if !db.Statement.CreatingFromQuery() {
+				if sch := db.Statement.Schema; sch != nil && sch.OnConflict != nil && (sch.OnConflictImplemented || db.UseModelConflictClause) {
+					db.Statement.AddClauseIfNotExists(*sch.OnConflict)
+				}
+				db.Statement.AddClause(ConvertToCreateValues(db.Statement))
+			}
 
+			returnInserted = supportReturning && returnUpsertInserted(db.Statement)
+			db.Statement.BuildReturning()
 			db.Statement.Build(db.Statement.BuildClauses...)
 		}
 
@@ -81,29 +98,44 @@ func Create(config *Config) func(db *gorm.DB) {
 				}
 			}
 
-			rows, err := db.Statement.ConnPool.QueryContext(
-				db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...,
-			)
+			rows, err := db.Statement.QueryContext()
 			if db.AddError(err) == nil {
 				defer func() {
 					db.AddError(rows.Close())
 				}()
-				gorm.Scan(rows, db, mode)
+
+				if onConflict, values, ok := upsertConflict(db.Statement); ok {
+					upsertRows := newUpsertRows(rows, returnInserted)
+					gorm.Scan(upsertRows, db, mode)
+					if db.AddError(upsertRows.drain()) == nil {
+						inserted := int64(-1)
+						if returnInserted {
+							inserted = upsertRows.inserted
+						}
+						result := upsertResult(db.Statement, onConflict, values, upsertRows.returned, inserted)
+						db.Statement.Upsert = &result
+					}
+				} else {
+					gorm.Scan(rows, db, mode)
+				}
 			}
 
 			return
 		}
 
-		result, err := db.Statement.ConnPool.ExecContext(
-			db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...,
-		)
+		result, err := db.Statement.ExecContext()
 		if err != nil {
 			db.AddError(err)
 			return
 		}
 
 		db.RowsAffected, _ = result.RowsAffected()
-		if db.RowsAffected == 0 {
+		if onConflict, values, ok := upsertConflict(db.Statement); ok {
+			result := upsertResult(db.Statement, onConflict, values, db.RowsAffected, -1)
+			db.Statement.Upsert = &result
+		}
+
+		if db.RowsAffected == 0 || db.Statement.CreatingFromQuery() {
 			return
 		}
 
@@ -202,7 +234,7 @@ func Create(config *Config) func(db *gorm.DB) {
 
 // AfterCreate after create hooks
 func AfterCreate(db *gorm.DB) {
-	if db.Error == nil && db.Statement.Schema != nil && !db.Statement.SkipHooks && (db.Statement.Schema.AfterSave || db.Statement.Schema.AfterCreate) {
+	if db.Error == nil && db.Statement.Schema != nil && !skipHooks(db) && (db.Statement.Schema.AfterSave || db.Statement.Schema.AfterCreate) {
 		callMethod(db, func(value interface{}, tx *gorm.DB) (called bool) {
 			if db.Statement.Schema.AfterCreate {
 				if i, ok := value.(AfterCreateInterface); ok {
@@ -220,11 +252,14 @@ func AfterCreate(db *gorm.DB) {
 			return called
 		})
 	}
+
+	callModelHooks(db, func(hook gorm.ModelHook) func(*gorm.DB, interface{}) error { return hook.AfterCreate })
 }
 
 // ConvertToCreateValues convert to create values
 func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 	curTime := stmt.DB.NowFunc()
+	auditor := &auditUser{stmt: stmt}
 
 	switch value := stmt.Dest.(type) {
 	case map[string]interface{}:
@@ -239,15 +274,18 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 		var (
 			selectColumns, restricted = stmt.SelectAndOmitColumns(true, false)
 			_, updateTrackTime        = stmt.Get("gorm:update_track_time")
+			tenant, withTenant        = stmt.Tenant()
+			trackTime                 = !stmt.DB.SkipAutoTimeTracking
 			isZero                    bool
 		)
 		stmt.Settings.Delete("gorm:update_track_time")
+		updateTrackTime = updateTrackTime && trackTime
 
 		values = clause.Values{Columns: make([]clause.Column, 0, len(stmt.Schema.DBNames))}
 
 		for _, db := range stmt.Schema.DBNames {
 			if field := stmt.Schema.FieldsByDBName[db]; !field.HasDefaultValue || field.DefaultValueInterface != nil {
-				if v, ok := selectColumns[db]; (ok && v) || (!ok && (!restricted || field.AutoCreateTime > 0 || field.AutoUpdateTime > 0)) {
+				if v, ok := selectColumns[db]; (ok && v) || (!ok && (!restricted || (trackTime && (field.AutoCreateTime > 0 || field.AutoUpdateTime > 0)) || field.AutoCreatedBy || field.AutoUpdatedBy || (withTenant && field == stmt.Schema.TenantField))) {
 					values.Columns = append(values.Columns, clause.Column{Name: db})
 				}
 			}
@@ -273,6 +311,11 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 					return
 				}
 
+				// the tenant field is always set to the current tenant, rows of other tenants are never created
+				if withTenant {
+					stmt.AddError(stmt.Schema.TenantField.Set(stmt.Context, rv, tenant))
+				}
+
 				values.Values[i] = make([]interface{}, len(values.Columns))
 				for idx, column := range values.Columns {
 					field := stmt.Schema.FieldsByDBName[column.Name]
@@ -280,9 +323,17 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 						if field.DefaultValueInterface != nil {
 							values.Values[i][idx] = field.DefaultValueInterface
 							stmt.AddError(field.Set(stmt.Context, rv, field.DefaultValueInterface))
-						} else if field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 {
+						} else if field.AutoUUID {
+							stmt.AddError(setGeneratedUUID(stmt, field, rv))
+							values.Values[i][idx], _ = field.ValueOf(stmt.Context, rv)
+						} else if trackTime && (field.AutoCreateTime > 0 || field.AutoUpdateTime > 0) {
 							stmt.AddError(field.Set(stmt.Context, rv, curTime))
 							values.Values[i][idx], _ = field.ValueOf(stmt.Context, rv)
+						} else if field.AutoCreatedBy || field.AutoUpdatedBy {
+							if user, ok := auditor.get(); ok {
+								stmt.AddError(field.Set(stmt.Context, rv, user))
+								values.Values[i][idx], _ = field.ValueOf(stmt.Context, rv)
+							}
 						}
 					} else if field.AutoUpdateTime > 0 && updateTrackTime {
 						stmt.AddError(field.Set(stmt.Context, rv, curTime))
@@ -315,6 +366,10 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 				}
 			}
 		case reflect.Struct:
+			if withTenant {
+				stmt.AddError(stmt.Schema.TenantField.Set(stmt.Context, stmt.ReflectValue, tenant))
+			}
+
 			values.Values = [][]interface{}{make([]interface{}, len(values.Columns))}
 			for idx, column := range values.Columns {
 				field := stmt.Schema.FieldsByDBName[column.Name]
@@ -322,9 +377,17 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 					if field.DefaultValueInterface != nil {
 						values.Values[0][idx] = field.DefaultValueInterface
 						stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue, field.DefaultValueInterface))
-					} else if field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 {
+					} else if field.AutoUUID {
+						stmt.AddError(setGeneratedUUID(stmt, field, stmt.ReflectValue))
+						values.Values[0][idx], _ = field.ValueOf(stmt.Context, stmt.ReflectValue)
+					} else if trackTime && (field.AutoCreateTime > 0 || field.AutoUpdateTime > 0) {
 						stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue, curTime))
 						values.Values[0][idx], _ = field.ValueOf(stmt.Context, stmt.ReflectValue)
+					} else if field.AutoCreatedBy || field.AutoUpdatedBy {
+						if user, ok := auditor.get(); ok {
+							stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue, user))
+							values.Values[0][idx], _ = field.ValueOf(stmt.Context, stmt.ReflectValue)
+						}
 					}
 				} else if field.AutoUpdateTime > 0 && updateTrackTime {
 					stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue, curTime))
@@ -345,6 +408,14 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 		}
 	}
 
+	for _, rowValues := range values.Values {
+		for idx, value := range rowValues {
+			if idx < len(values.Columns) {
+				rowValues[idx] = writableDefault(stmt, values.Columns[idx].Name, value)
+			}
+		}
+	}
+
 	if c, ok := stmt.Clauses["ON CONFLICT"]; ok {
 		if onConflict, _ := c.Expression.(clause.OnConflict); onConflict.UpdateAll {
 			if stmt.Schema != nil && len(values.Columns) >= 1 {
@@ -357,6 +428,10 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 							if !field.PrimaryKey && (!field.HasDefaultValue || field.DefaultValueInterface != nil ||
 								strings.EqualFold(field.DefaultValue, "NULL")) && field.AutoCreateTime == 0 {
 								if field.AutoUpdateTime > 0 {
+									if stmt.DB.SkipAutoTimeTracking {
+										continue
+									}
+
 									assignment := clause.Assignment{Column: clause.Column{Name: field.DBName}, Value: curTime}
 									switch field.AutoUpdateTime {
 									case schema.UnixNanosecond:
@@ -394,3 +469,164 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 
 	return values
 }
+
+// setGeneratedUUID sets the UUID generated by Config.UUIDGenerator to the field, in the string form unless the field
+// is an array or slice of bytes
+func setGeneratedUUID(stmt *gorm.Statement, field *schema.Field, rv reflect.Value) error {
+	id, err := stmt.DB.UUIDGenerator()
+	if err != nil {
+		return err
+	}
+
+	var value interface{} = schema.FormatUUID(id)
+	switch field.IndirectFieldType.Kind() {
+	case reflect.Array:
+		if reflect.TypeOf(id).ConvertibleTo(field.IndirectFieldType) {
+			value = reflect.ValueOf(id).Convert(field.IndirectFieldType).Interface()
+		}
+	case reflect.Slice:
+		value = id[:]
+	}
+	return field.Set(stmt.Context, rv, value)
+}
+
+// upsertInsertedColumn the column returned by the upserts of Postgres, which reports whether the row is inserted
+const upsertInsertedColumn = "gorm_inserted"
+
+// upsertConflict returns the OnConflict clause of the create and the rows of its values, ok is false if the create
+// isn't an upsert
+func upsertConflict(stmt *gorm.Statement) (onConflict clause.OnConflict, values int64, ok bool) {
+	c, ok := stmt.Clauses["ON CONFLICT"]
+	if !ok || stmt.CreatingFromQuery() {
+		return onConflict, 0, false
+	}
+
+	onConflict, ok = c.Expression.(clause.OnConflict)
+	createValues, _ := stmt.Clauses["VALUES"].Expression.(clause.Values)
+	return onConflict, int64(len(createValues.Values)), ok && len(createValues.Values) > 0
+}
+
+// returnUpsertInserted returns (xmax = 0) of the rows upserted on Postgres, which is true for the inserted rows
+func returnUpsertInserted(stmt *gorm.Statement) bool {
+	if stmt.DB.Dialector.Name() != "postgres" {
+		return false
+	}
+
+	if _, _, ok := upsertConflict(stmt); !ok {
+		return false
+	}
+
+	switch stmt.Dest.(type) {
+	case map[string]interface{}, *map[string]interface{}, []map[string]interface{}, *[]map[string]interface{}:
+		// the returned columns are scanned into the maps
+		return false
+	}
+
+	returning, ok := stmt.Returning()
+	if ok && len(returning.Columns) == 0 {
+		// RETURNING *
+		return false
+	}
+
+	columns := make([]clause.Column, 0, len(returning.Columns)+1)
+	columns = append(columns, returning.Columns...)
+	columns = append(columns, clause.Column{Name: "(xmax = 0) AS " + upsertInsertedColumn, Raw: true})
+	delete(stmt.Clauses, "RETURNING")
+	stmt.AddReturning(columns...)
+	return true
+}
+
+// upsertResult determines the rows inserted and updated by the upsert of values rows, affected is the rows affected
+// or returned, inserted is the rows returned with upsertInsertedColumn, which is negative if it isn't returned
+func upsertResult(stmt *gorm.Statement, onConflict clause.OnConflict, values int64, affected int64, inserted int64) gorm.UpsertResult {
+	switch {
+	case inserted >= 0:
+		return gorm.UpsertResult{Known: true, Inserted: inserted, Updated: affected - inserted, Unchanged: values - affected}
+	case onConflict.DoNothing:
+		return gorm.UpsertResult{Known: true, Inserted: affected, Unchanged: values - affected}
+	case stmt.DB.Dialector.Name() == "mysql":
+		// the affected rows of MySQL are 1 per inserted row, 2 per updated row and 0 per unchanged row, which are
+		// known only if one count of the updated rows matches them
+		minUpdated, maxUpdated := affected-values, affected/2
+		if minUpdated < 0 {
+			minUpdated = 0
+		}
+		if minUpdated == maxUpdated {
+			return gorm.UpsertResult{
+				Known:     true,
+				Inserted:  affected - 2*minUpdated,
+				Updated:   minUpdated,
+				Unchanged: values - affected + minUpdated,
+			}
+		}
+	}
+	return gorm.UpsertResult{}
+}
+
+// upsertRows counts the rows returned by the upserts, and the inserted rows by upsertInsertedColumn
+type upsertRows struct {
+	gorm.Rows
+	index    int
+	returned int64
+	inserted int64
+}
+
+func newUpsertRows(rows gorm.Rows, returnInserted bool) *upsertRows {
+	upsertRows := &upsertRows{Rows: rows, index: -1}
+	if returnInserted {
+		columns, _ := rows.Columns()
+		for idx, column := range columns {
+			if column == upsertInsertedColumn {
+				upsertRows.index = idx
+			}
+		}
+	}
+	return upsertRows
+}
+
+func (rows *upsertRows) Scan(dest ...interface{}) error {
+	var inserted interface{}
+	if rows.index >= 0 && rows.index < len(dest) {
+		original := dest[rows.index]
+		dest[rows.index] = &inserted
+		defer func() {
+			dest[rows.index] = original
+			if v, ok := original.(*interface{}); ok {
+				*v = inserted
+			}
+		}()
+	}
+
+	if err := rows.Rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	rows.returned++
+	if v, ok := inserted.(bool); ok && v {
+		rows.inserted++
+	}
+	return nil
+}
+
+// drain counts the rows not scanned, e.g: the rows more than the created values
+func (rows *upsertRows) drain() error {
+	var dest []interface{}
+	for rows.Next() {
+		if dest == nil {
+			columns, err := rows.Columns()
+			if err != nil {
+				return err
+			}
+
+			dest = make([]interface{}, len(columns))
+			for idx := range dest {
+				dest[idx] = new(interface{})
+			}
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
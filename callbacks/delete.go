@@ -7,11 +7,12 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
-	"gorm.io/gorm/utils"
 )
 
 func BeforeDelete(db *gorm.DB) {
-	if db.Error == nil && db.Statement.Schema != nil && !db.Statement.SkipHooks && db.Statement.Schema.BeforeDelete {
+	callModelHooks(db, func(hook gorm.ModelHook) func(*gorm.DB, interface{}) error { return hook.BeforeDelete })
+
+	if db.Error == nil && db.Statement.Schema != nil && !skipHooks(db) && db.Statement.Schema.BeforeDelete {
 		callMethod(db, func(value interface{}, tx *gorm.DB) bool {
 			if i, ok := value.(BeforeDeleteInterface); ok {
 				db.AddError(i.BeforeDelete(tx))
@@ -111,17 +112,23 @@ func DeleteBeforeAssociations(db *gorm.DB) {
 }
 
 func Delete(config *Config) func(db *gorm.DB) {
-	supportReturning := utils.Contains(config.DeleteClauses, "RETURNING")
-
 	return func(db *gorm.DB) {
 		if db.Error != nil {
 			return
 		}
 
+		supportReturning := supportsReturning(db, config.DeleteClauses)
+
+		if db.Statement.SQL.Len() == 0 {
+			db.Statement.AddTenantCondition()
+			db.Statement.AddDefaultScope(true)
+		}
+
 		if db.Statement.Schema != nil {
 			for _, c := range db.Statement.Schema.DeleteClauses {
 				db.Statement.AddClause(c)
 			}
+			serializeUUIDPrimaryValues(db.Statement)
 		}
 
 		if db.Statement.SQL.Len() == 0 {
@@ -148,15 +155,28 @@ func Delete(config *Config) func(db *gorm.DB) {
 
 			db.Statement.AddClauseIfNotExists(clause.From{})
 
-			db.Statement.Build(db.Statement.BuildClauses...)
+			db.Statement.BuildReturning()
+			db.Statement.BuildLimited(db.Statement.BuildClauses...)
+			db.InstanceSet("gorm:conditions_built", true)
 		}
 
 		checkMissingWhereConditions(db)
 
+		if db.Error == nil && !db.DryRun {
+			db.AddError(db.Statement.CheckRestrictedChildren())
+		}
+
+		if !db.Statement.Unscoped && db.Error == nil && db.Statement.ArchiveTable() != "" {
+			if finish := beginArchiveTransaction(db); finish != nil {
+				defer finish()
+			}
+			db.AddError(db.Statement.Archive())
+		}
+
 		if !db.DryRun && db.Error == nil {
 			ok, mode := hasReturning(db, supportReturning)
 			if !ok {
-				result, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...)
+				result, err := db.Statement.ExecContext()
 				if db.AddError(err) == nil {
 					db.RowsAffected, _ = result.RowsAffected()
 				}
@@ -164,7 +184,7 @@ func Delete(config *Config) func(db *gorm.DB) {
 				return
 			}
 
-			if rows, err := db.Statement.ConnPool.QueryContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...); db.AddError(err) == nil {
+			if rows, err := db.Statement.QueryContext(); db.AddError(err) == nil {
 				gorm.Scan(rows, db, mode)
 				db.AddError(rows.Close())
 			}
@@ -173,7 +193,7 @@ func Delete(config *Config) func(db *gorm.DB) {
 }
 
 func AfterDelete(db *gorm.DB) {
-	if db.Error == nil && db.Statement.Schema != nil && !db.Statement.SkipHooks && db.Statement.Schema.AfterDelete {
+	if db.Error == nil && db.Statement.Schema != nil && !skipHooks(db) && db.Statement.Schema.AfterDelete {
 		callMethod(db, func(value interface{}, tx *gorm.DB) bool {
 			if i, ok := value.(AfterDeleteInterface); ok {
 				db.AddError(i.AfterDelete(tx))
@@ -182,4 +202,33 @@ func AfterDelete(db *gorm.DB) {
 			return false
 		})
 	}
+
+	callModelHooks(db, func(hook gorm.ModelHook) func(*gorm.DB, interface{}) error { return hook.AfterDelete })
+}
+
+// beginArchiveTransaction begins the transaction archiving and deleting the rows if the statement isn't in one, e.g:
+// SkipDefaultTransaction, so the rows aren't archived if failed to delete them, returns the func finishing it
+func beginArchiveTransaction(db *gorm.DB) func() {
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok || db.DryRun {
+		return nil
+	}
+
+	tx := db.Session(&gorm.Session{NewDB: true}).Begin()
+	if tx.Error != nil {
+		if tx.Error != gorm.ErrInvalidTransaction {
+			db.AddError(tx.Error)
+		}
+		return nil
+	}
+
+	connPool := db.Statement.ConnPool
+	db.Statement.ConnPool = tx.Statement.ConnPool
+	return func() {
+		if db.Error != nil {
+			tx.Rollback()
+		} else {
+			db.AddError(tx.Commit().Error)
+		}
+		db.Statement.ConnPool = connPool
+	}
 }
@@ -1,17 +1,23 @@
 package callbacks
 
 import (
+	"database/sql/driver"
 	"reflect"
 	"sort"
+	"strings"
+	"unicode"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils"
 )
 
-// ConvertMapToValuesForCreate convert map to values
+// ConvertMapToValuesForCreate convert map to values, the columns are sorted by the keys of the map
 func ConvertMapToValuesForCreate(stmt *gorm.Statement, mapValue map[string]interface{}) (values clause.Values) {
 	values.Columns = make([]clause.Column, 0, len(mapValue))
 	selectColumns, restricted := stmt.SelectAndOmitColumns(true, false)
+	setDynamicTimes(stmt, mapValue)
 
 	keys := make([]string, 0, len(mapValue))
 	for k := range mapValue {
@@ -23,7 +29,7 @@ func ConvertMapToValuesForCreate(stmt *gorm.Statement, mapValue map[string]inter
 		value := mapValue[k]
 		if stmt.Schema != nil {
 			if field := stmt.Schema.LookUpField(k); field != nil {
-				k = field.DBName
+				k, value = field.DBName, field.DurationValue(stmt.Context, value)
 			}
 		}
 
@@ -39,7 +45,7 @@ func ConvertMapToValuesForCreate(stmt *gorm.Statement, mapValue map[string]inter
 	return
 }
 
-// ConvertSliceOfMapToValuesForCreate convert slice of map to values
+// ConvertSliceOfMapToValuesForCreate convert slice of map to values, the columns are sorted by the keys of the maps
 func ConvertSliceOfMapToValuesForCreate(stmt *gorm.Statement, mapValues []map[string]interface{}) (values clause.Values) {
 	columns := make([]string, 0, len(mapValues))
 
@@ -55,17 +61,21 @@ func ConvertSliceOfMapToValuesForCreate(stmt *gorm.Statement, mapValues []map[st
 		selectColumns, restricted = stmt.SelectAndOmitColumns(true, false)
 	)
 
+	setDynamicTimes(stmt, mapValues...)
 	for idx, mapValue := range mapValues {
 		for k, v := range mapValue {
 			if stmt.Schema != nil {
 				if field := stmt.Schema.LookUpField(k); field != nil {
-					k = field.DBName
+					k, v = field.DBName, field.DurationValue(stmt.Context, v)
 				}
 			}
 
 			if _, ok := result[k]; !ok {
 				if v, ok := selectColumns[k]; (ok && v) || (!ok && !restricted) {
 					result[k] = make([]interface{}, len(mapValues))
+					for i := range result[k] {
+						result[k][i] = missingMapValue{}
+					}
 					columns = append(columns, k)
 				} else {
 					continue
@@ -87,16 +97,70 @@ func ConvertSliceOfMapToValuesForCreate(stmt *gorm.Statement, mapValues []map[st
 				values.Values[i] = make([]interface{}, len(columns))
 			}
 
+			if _, missing := v.(missingMapValue); missing {
+				v = columnDefaultValue(stmt, column)
+			}
 			values.Values[i][idx] = v
 		}
 	}
 	return
 }
 
+// setDynamicTimes sets the auto create and update time of the dynamic schema missing in the maps, see
+// schema.DynamicSchema
+func setDynamicTimes(stmt *gorm.Statement, mapValues ...map[string]interface{}) {
+	if stmt.Schema == nil || !stmt.Schema.Dynamic || stmt.DB.SkipAutoTimeTracking {
+		return
+	}
+
+	curTime := stmt.DB.NowFunc()
+	for _, mapValue := range mapValues {
+		if mapValue == nil {
+			continue
+		}
+
+		reflectValue := reflect.ValueOf(mapValue)
+		for _, field := range stmt.Schema.Fields {
+			if field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 {
+				if _, isZero := field.ValueOf(stmt.Context, reflectValue); isZero {
+					stmt.AddError(field.Set(stmt.Context, reflectValue, curTime))
+				}
+			}
+		}
+	}
+}
+
+// missingMapValue the value of the column missing in a map of the slice
+type missingMapValue struct{}
+
+// columnDefaultValue returns the default value of the column for the maps missing it, or nil for NULL
+func columnDefaultValue(stmt *gorm.Statement, column string) interface{} {
+	if stmt.Schema != nil {
+		if field := stmt.Schema.LookUpField(column); field != nil && field.HasDefaultValue {
+			if field.DefaultValueInterface != nil {
+				return field.DefaultValueInterface
+			}
+
+			if field.DefaultValue != "" && field.DefaultValue != "(-)" && !strings.EqualFold(field.DefaultValue, "null") {
+				return clause.Expr{SQL: field.DefaultValue}
+			}
+		}
+	}
+	return nil
+}
+
+// supportsReturning reports whether the statement could return rows, by RETURNING of the clauses or the
+// ReturningBuilder of the dialector
+func supportsReturning(tx *gorm.DB, clauses []string) bool {
+	if _, ok := tx.Dialector.(gorm.ReturningBuilder); ok {
+		return true
+	}
+	return utils.Contains(clauses, "RETURNING")
+}
+
 func hasReturning(tx *gorm.DB, supportReturning bool) (bool, gorm.ScanMode) {
 	if supportReturning {
-		if c, ok := tx.Statement.Clauses["RETURNING"]; ok {
-			returning, _ := c.Expression.(clause.Returning)
+		if returning, ok := tx.Statement.Returning(); ok {
 			if len(returning.Columns) == 0 || (len(returning.Columns) == 1 && returning.Columns[0].Name == "*") {
 				return true, 0
 			}
@@ -106,13 +170,64 @@ func hasReturning(tx *gorm.DB, supportReturning bool) (bool, gorm.ScanMode) {
 	return false, 0
 }
 
+// allowGlobalWrite reports whether the statement may update or delete without conditions
+func allowGlobalWrite(db *gorm.DB) bool {
+	if allowed, ok := db.Get("gorm:allow_global_write"); ok && allowed.(bool) {
+		return true
+	}
+	return db.AllowGlobalUpdate && !db.BlockGlobalWrite
+}
+
+// isGlobalWriteSQL reports whether the raw sql is an UPDATE or DELETE without a top level WHERE
+func isGlobalWriteSQL(sql string) bool {
+	sql = strings.TrimSpace(sql)
+	if idx := strings.IndexFunc(sql, unicode.IsSpace); idx < 0 || (!strings.EqualFold(sql[:idx], "UPDATE") && !strings.EqualFold(sql[:idx], "DELETE")) {
+		return false
+	}
+
+	var (
+		depth int
+		quote byte
+	)
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && (c == 'W' || c == 'w') && i+5 <= len(sql) && strings.EqualFold(sql[i:i+5], "WHERE") &&
+			(i == 0 || !isIdentifierChar(sql[i-1])) && (i+5 == len(sql) || !isIdentifierChar(sql[i+5])):
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '.' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
 func checkMissingWhereConditions(db *gorm.DB) {
-	if !db.AllowGlobalUpdate && db.Error == nil {
+	if !allowGlobalWrite(db) && db.Error == nil {
 		where, withCondition := db.Statement.Clauses["WHERE"]
 		if withCondition {
-			if _, withSoftDelete := db.Statement.Clauses["soft_delete_enabled"]; withSoftDelete {
+			// the soft delete and tenant conditions don't count
+			var implicitConditions int
+			for _, name := range []string{"soft_delete_enabled", "tenant_enabled"} {
+				if _, ok := db.Statement.Clauses[name]; ok {
+					implicitConditions++
+				}
+			}
+
+			if implicitConditions > 0 {
 				whereClause, _ := where.Expression.(clause.Where)
-				withCondition = len(whereClause.Exprs) > 1
+				withCondition = len(whereClause.Exprs) > implicitConditions
 			}
 		}
 		if !withCondition {
@@ -150,3 +265,108 @@ func loadOrStoreVisitMap(visitMap *visitMap, v reflect.Value) (loaded bool) {
 
 	return
 }
+
+// auditUser extracts the acting user of the audit fields once for a statement
+type auditUser struct {
+	stmt   *gorm.Statement
+	user   interface{}
+	ok     bool
+	loaded bool
+}
+
+func (u *auditUser) get() (interface{}, bool) {
+	if !u.loaded {
+		u.user, u.ok = u.stmt.AuditUser()
+		u.loaded = true
+	}
+	return u.user, u.ok
+}
+
+// noteRawTenancy notes the raw sql of the models having a tenant field isn't restricted to the tenant
+func noteRawTenancy(db *gorm.DB) {
+	if db.Statement.Schema != nil && db.Statement.Schema.TenantField != nil {
+		if _, disabled := db.Statement.Settings.Load("gorm:without_tenancy"); !disabled {
+			db.Logger.Info(db.Statement.Context, "raw sql of %s isn't restricted to the tenant", db.Statement.Schema.Name)
+		}
+	}
+}
+
+// serializeUUIDPrimaryValues converts the values of the primary key conditions, e.g: First(&user, "<uuid>"), to the
+// bytes of the binary UUID primary key, the conditions are copied on write as the clauses may be shared
+func serializeUUIDPrimaryValues(stmt *gorm.Statement) {
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil || field.UUIDStorage != schema.UUIDBinary {
+		return
+	}
+
+	where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where)
+	if !ok {
+		return
+	}
+
+	serialize := func(value interface{}) interface{} {
+		if _, ok := value.(driver.Valuer); ok {
+			return value
+		}
+		if v, err := (schema.UUIDBinarySerializer{}).Value(stmt.Context, field, stmt.ReflectValue, value); err == nil {
+			return v
+		}
+		return value
+	}
+
+	var exprs []clause.Expression
+	for idx, expr := range where.Exprs {
+		switch v := expr.(type) {
+		case clause.IN:
+			if v.Column != clause.PrimaryColumn {
+				continue
+			}
+			values := make([]interface{}, len(v.Values))
+			for i, value := range v.Values {
+				values[i] = serialize(value)
+			}
+			v.Values = values
+			expr = v
+		case clause.Eq:
+			if v.Column != clause.PrimaryColumn {
+				continue
+			}
+			v.Value = serialize(v.Value)
+			expr = v
+		default:
+			continue
+		}
+
+		if exprs == nil {
+			exprs = append([]clause.Expression(nil), where.Exprs...)
+		}
+		exprs[idx] = expr
+	}
+
+	if exprs != nil {
+		where.Exprs = exprs
+		c := stmt.Clauses["WHERE"]
+		c.Expression = where
+		stmt.Clauses["WHERE"] = c
+	}
+}
+
+// writableDefault returns the value written for gorm.Default of the column, SQLite doesn't support the DEFAULT keyword,
+// the default value declared by the `default` tag of the field is written instead
+func writableDefault(stmt *gorm.Statement, column string, value interface{}) interface{} {
+	if _, ok := value.(gorm.Default); !ok || stmt.DB.Dialector.Name() != "sqlite" || stmt.Schema == nil {
+		return value
+	}
+
+	if field := stmt.Schema.LookUpField(column); field != nil {
+		switch {
+		case field.DefaultValueInterface != nil:
+			return field.DefaultValueInterface
+		case field.HasDefaultValue && field.DefaultValue != "":
+			return clause.Expr{SQL: field.DefaultValue}
+		default:
+			return nil
+		}
+	}
+	return value
+}
@@ -3,7 +3,6 @@ package callbacks
 import (
 	"fmt"
 	"reflect"
-	"sort"
 	"strings"
 
 	"gorm.io/gorm"
@@ -12,91 +11,11 @@ import (
 	"gorm.io/gorm/utils"
 )
 
-// parsePreloadMap extracts nested preloads. e.g.
-//
-//	// schema has a "k0" relation and a "k7.k8" embedded relation
-//	parsePreloadMap(schema, map[string][]interface{}{
-//		clause.Associations: {"arg1"},
-//		"k1":                {"arg2"},
-//		"k2.k3":             {"arg3"},
-//		"k4.k5.k6":          {"arg4"},
-//	})
-//	// preloadMap is
-//	map[string]map[string][]interface{}{
-//		"k0": {},
-//		"k7": {
-//			"k8": {},
-//		},
-//		"k1": {},
-//		"k2": {
-//			"k3": {"arg3"},
-//		},
-//		"k4": {
-//			"k5.k6": {"arg4"},
-//		},
-//	}
-func parsePreloadMap(s *schema.Schema, preloads map[string][]interface{}) map[string]map[string][]interface{} {
-	preloadMap := map[string]map[string][]interface{}{}
-	setPreloadMap := func(name, value string, args []interface{}) {
-		if _, ok := preloadMap[name]; !ok {
-			preloadMap[name] = map[string][]interface{}{}
-		}
-		if value != "" {
-			preloadMap[name][value] = args
-		}
-	}
-
-	for name, args := range preloads {
-		preloadFields := strings.Split(name, ".")
-		value := strings.TrimPrefix(strings.TrimPrefix(name, preloadFields[0]), ".")
-		if preloadFields[0] == clause.Associations {
-			for _, relation := range s.Relationships.Relations {
-				if relation.Schema == s {
-					setPreloadMap(relation.Name, value, args)
-				}
-			}
-
-			for embedded, embeddedRelations := range s.Relationships.EmbeddedRelations {
-				for _, value := range embeddedValues(embeddedRelations) {
-					setPreloadMap(embedded, value, args)
-				}
-			}
-		} else {
-			setPreloadMap(preloadFields[0], value, args)
-		}
-	}
-	return preloadMap
-}
-
-func embeddedValues(embeddedRelations *schema.Relationships) []string {
-	if embeddedRelations == nil {
-		return nil
-	}
-	names := make([]string, 0, len(embeddedRelations.Relations)+len(embeddedRelations.EmbeddedRelations))
-	for _, relation := range embeddedRelations.Relations {
-		// skip first struct name
-		names = append(names, strings.Join(relation.Field.EmbeddedBindNames[1:], "."))
-	}
-	for _, relations := range embeddedRelations.EmbeddedRelations {
-		names = append(names, embeddedValues(relations)...)
-	}
-	return names
-}
-
 // preloadEntryPoint enters layer by layer. It will call real preload if it finds the right entry point.
 // If the current relationship is embedded or joined, current query will be ignored.
 //
 //nolint:cyclop
-func preloadEntryPoint(db *gorm.DB, joins []string, relationships *schema.Relationships, preloads map[string][]interface{}, associationsConds []interface{}) error {
-	preloadMap := parsePreloadMap(db.Statement.Schema, preloads)
-
-	// avoid random traversal of the map
-	preloadNames := make([]string, 0, len(preloadMap))
-	for key := range preloadMap {
-		preloadNames = append(preloadNames, key)
-	}
-	sort.Strings(preloadNames)
-
+func preloadEntryPoint(db *gorm.DB, joins []string, relationships *schema.Relationships, nodes []*gorm.PreloadNode) error {
 	isJoined := func(name string) (joined bool, nestedJoins []string) {
 		for _, join := range joins {
 			if _, ok := relationships.Relations[join]; ok && name == join {
@@ -114,9 +33,11 @@ func preloadEntryPoint(db *gorm.DB, joins []string, relationships *schema.Relati
 		return joined, nestedJoins
 	}
 
-	for _, name := range preloadNames {
+	var preloaded int
+	for _, node := range nodes {
+		name := node.Name
 		if relations := relationships.EmbeddedRelations[name]; relations != nil {
-			if err := preloadEntryPoint(db, joins, relations, preloadMap[name], associationsConds); err != nil {
+			if err := preloadEntryPoint(db, joins, relations, node.Children); err != nil {
 				return err
 			}
 		} else if rel := relationships.Relations[name]; rel != nil {
@@ -138,24 +59,31 @@ func preloadEntryPoint(db *gorm.DB, joins []string, relationships *schema.Relati
 						}
 
 						tx := preloadDB(db, reflectValue, reflectValue.Interface())
-						if err := preloadEntryPoint(tx, nestedJoins, &tx.Statement.Schema.Relationships, preloadMap[name], associationsConds); err != nil {
+						if err := preloadEntryPoint(tx, nestedJoins, &tx.Statement.Schema.Relationships, node.Children); err != nil {
 							return err
 						}
 					}
 				case reflect.Struct, reflect.Pointer:
 					reflectValue := rel.Field.ReflectValueOf(db.Statement.Context, rv)
 					tx := preloadDB(db, reflectValue, reflectValue.Interface())
-					if err := preloadEntryPoint(tx, nestedJoins, &tx.Statement.Schema.Relationships, preloadMap[name], associationsConds); err != nil {
+					if err := preloadEntryPoint(tx, nestedJoins, &tx.Statement.Schema.Relationships, node.Children); err != nil {
 						return err
 					}
 				default:
 					return gorm.ErrInvalidData
 				}
 			} else {
+				// the remaining preloads aren't queried once the context is done
+				if err := db.Statement.Interrupted("preload", preloaded); err != nil {
+					return err
+				}
+				preloaded++
+
 				tx := db.Table("").Session(&gorm.Session{Context: db.Statement.Context, SkipHooks: db.Statement.SkipHooks})
 				tx.Statement.ReflectValue = db.Statement.ReflectValue
 				tx.Statement.Unscoped = db.Statement.Unscoped
-				if err := preload(tx, rel, append(preloads[name], associationsConds...), preloadMap[name]); err != nil {
+				tx.Statement.UnscopedTables = db.Statement.UnscopedTables
+				if err := preload(tx, rel, node.Conds, node.Children); err != nil {
 					return err
 				}
 			}
@@ -166,6 +94,16 @@ func preloadEntryPoint(db *gorm.DB, joins []string, relationships *schema.Relati
 	return nil
 }
 
+// nestedPreloads returns the paths and the conditions of the nodes preloaded by the query of their parent
+// relationship, the conditions of the nodes are merged, so they are preloaded as planned again
+func nestedPreloads(nodes []*gorm.PreloadNode, prefix string, preloads map[string][]interface{}) map[string][]interface{} {
+	for _, node := range nodes {
+		preloads[prefix+node.Name] = node.Conds
+		nestedPreloads(node.Children, prefix+node.Name+".", preloads)
+	}
+	return preloads
+}
+
 func preloadDB(db *gorm.DB, reflectValue reflect.Value, dest interface{}) *gorm.DB {
 	tx := db.Session(&gorm.Session{Context: db.Statement.Context, NewDB: true, SkipHooks: db.Statement.SkipHooks, Initialized: true})
 	db.Statement.Settings.Range(func(k, v interface{}) bool {
@@ -179,10 +117,11 @@ func preloadDB(db *gorm.DB, reflectValue reflect.Value, dest interface{}) *gorm.
 	}
 	tx.Statement.ReflectValue = reflectValue
 	tx.Statement.Unscoped = db.Statement.Unscoped
+	tx.Statement.UnscopedTables = db.Statement.UnscopedTables
 	return tx
 }
 
-func preload(tx *gorm.DB, rel *schema.Relationship, conds []interface{}, preloads map[string][]interface{}) error {
+func preload(tx *gorm.DB, rel *schema.Relationship, conds []interface{}, nodes []*gorm.PreloadNode) error {
 	var (
 		reflectValue     = tx.Statement.ReflectValue
 		relForeignKeys   []string
@@ -267,7 +206,7 @@ func preload(tx *gorm.DB, rel *schema.Relationship, conds []interface{}, preload
 	}
 
 	// nested preload
-	for p, pvs := range preloads {
+	for p, pvs := range nestedPreloads(nodes, "", map[string][]interface{}{}) {
 		tx = tx.Preload(p, pvs...)
 	}
 
@@ -275,6 +214,13 @@ func preload(tx *gorm.DB, rel *schema.Relationship, conds []interface{}, preload
 	column, values := schema.ToQueryValues(clause.CurrentTable, relForeignKeys, foreignValues)
 
 	if len(values) != 0 {
+		// the keys to assign the preloaded rows are selected with DefaultSelect of the model
+		if !tx.IgnoreModelDefaults {
+			if columns := rel.FieldSchema.DefaultSelectColumns(relForeignKeys...); len(columns) > 0 {
+				tx = tx.Select(columns)
+			}
+		}
+
 		for _, cond := range conds {
 			if fc, ok := cond.(func(*gorm.DB) *gorm.DB); ok {
 				tx = fc(tx)
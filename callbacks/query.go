@@ -1,6 +1,7 @@
 package callbacks
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -12,24 +13,45 @@ import (
 )
 
 func Query(db *gorm.DB) {
+	if db.Error == nil && db.StrictAssociations {
+		db.AddError(db.Statement.CheckAssociations())
+	}
+
 	if db.Error == nil {
 		BuildQuerySQL(db)
 
-		if !db.DryRun && db.Error == nil {
-			rows, err := db.Statement.ConnPool.QueryContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...)
-			if err != nil {
-				db.AddError(err)
-				return
+		if !db.DryRun && db.Error == nil && !db.Statement.LoadCache() {
+			for attempt := 0; ; attempt++ {
+				query(db)
+				if !db.ShouldRetryTransient(attempt, db.Error) {
+					break
+				}
+				db.Error, db.RowsAffected = nil, 0
 			}
-			defer func() {
-				db.AddError(rows.Close())
-			}()
-			gorm.Scan(rows, db, 0)
 		}
 	}
 }
 
+func query(db *gorm.DB) {
+	rows, err := db.Statement.QueryContext()
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	defer func() {
+		db.AddError(rows.Close())
+	}()
+	gorm.Scan(rows, db, 0)
+}
+
 func BuildQuerySQL(db *gorm.DB) {
+	if db.Statement.SQL.Len() == 0 {
+		db.Statement.AddTenantCondition()
+		db.Statement.AddDefaultScope(false)
+	} else {
+		noteRawTenancy(db)
+	}
+
 	if db.Statement.Schema != nil {
 		for _, c := range db.Statement.Schema.QueryClauses {
 			db.Statement.AddClause(c)
@@ -37,7 +59,18 @@ func BuildQuerySQL(db *gorm.DB) {
 	}
 
 	if db.Statement.SQL.Len() == 0 {
+		db.Statement.AddModelDefaults()
 		db.Statement.SQL.Grow(100)
+		if groupBy, ok := db.Statement.Clauses["GROUP BY"].Expression.(clause.GroupBy); ok && len(groupBy.Having) > 0 &&
+			!db.Supports(gorm.CapabilityHavingAlias) {
+			db.Statement.ExpandHavingAliases()
+		}
+
+		if db.Statement.Schema != nil {
+			collateOrderColumns(db.Statement)
+			serializeUUIDPrimaryValues(db.Statement)
+		}
+
 		clauseSelect := clause.Select{Distinct: db.Statement.Distinct}
 
 		if db.Statement.ReflectValue.Kind() == reflect.Struct && db.Statement.ReflectValue.Type() == db.Statement.Schema.ModelType {
@@ -56,10 +89,15 @@ func BuildQuerySQL(db *gorm.DB) {
 		if len(db.Statement.Selects) > 0 {
 			clauseSelect.Columns = make([]clause.Column, len(db.Statement.Selects))
 			for idx, name := range db.Statement.Selects {
-				if db.Statement.Schema == nil {
-					clauseSelect.Columns[idx] = clause.Column{Name: name, Raw: true}
-				} else if f := db.Statement.Schema.LookUpField(name); f != nil {
-					clauseSelect.Columns[idx] = clause.Column{Name: f.DBName}
+				if db.Statement.Schema != nil {
+					if f := db.Statement.Schema.LookUpField(name); f != nil {
+						clauseSelect.Columns[idx] = clause.Column{Name: f.DBName}
+						continue
+					}
+				}
+
+				if column, ok := clause.ParseQuotingIdentifier(name); ok {
+					clauseSelect.Columns[idx] = column
 				} else {
 					clauseSelect.Columns[idx] = clause.Column{Name: name, Raw: true}
 				}
@@ -190,7 +228,7 @@ func BuildQuerySQL(db *gorm.DB) {
 							}
 
 							{
-								onStmt := gorm.Statement{Table: tableAliasName, DB: db, Clauses: map[string]clause.Clause{}}
+								onStmt := gorm.Statement{Table: tableAliasName, DB: db, Clauses: map[string]clause.Clause{}, Unscoped: join.Unscoped}
 								for _, c := range relation.FieldSchema.QueryClauses {
 									onStmt.AddClause(c)
 								}
@@ -257,6 +295,13 @@ func BuildQuerySQL(db *gorm.DB) {
 			db.Statement.AddClauseIfNotExists(clause.From{})
 		}
 
+		if columns := db.Statement.AssociationJSONColumns(); len(columns) > 0 {
+			if len(clauseSelect.Columns) == 0 {
+				clauseSelect.Columns = append(clauseSelect.Columns, clause.Column{Name: db.Statement.Quote(db.Statement.Table) + ".*", Raw: true})
+			}
+			clauseSelect.Columns = append(clauseSelect.Columns, columns...)
+		}
+
 		db.Statement.AddClauseIfNotExists(clauseSelect)
 
 		db.Statement.Build(db.Statement.BuildClauses...)
@@ -264,7 +309,7 @@ func BuildQuerySQL(db *gorm.DB) {
 }
 
 func Preload(db *gorm.DB) {
-	if db.Error == nil && len(db.Statement.Preloads) > 0 {
+	if db.Error == nil && len(db.Statement.Preloads) > 0 && !db.Statement.CacheHit() {
 		if db.Statement.Schema == nil {
 			db.AddError(fmt.Errorf("%w when using preload", gorm.ErrModelValueRequired))
 			return
@@ -275,13 +320,46 @@ func Preload(db *gorm.DB) {
 			joins = append(joins, join.Name)
 		}
 
+		nodes, err := db.Statement.PreloadPlan()
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+
 		tx := preloadDB(db, db.Statement.ReflectValue, db.Statement.Dest)
 		if tx.Error != nil {
 			return
 		}
 
-		db.AddError(preloadEntryPoint(tx, joins, &tx.Statement.Schema.Relationships, db.Statement.Preloads, db.Statement.Preloads[clause.Associations]))
+		// the nested preloads are queried with the context, whose paths are logged by the top query
+		_, nested := tx.Statement.Context.Value(preloadingKey{}).(bool)
+		if !nested {
+			tx.Statement.Context = context.WithValue(tx.Statement.Context, preloadingKey{}, true)
+		}
+
+		if err := preloadEntryPoint(tx, joins, &tx.Statement.Schema.Relationships, nodes); err != nil {
+			db.AddError(err)
+			return
+		}
+
+		if !nested {
+			db.Logger.Info(db.Statement.Context, "preloaded associations: %s", strings.Join(preloadedPaths(nodes, nil), ", "))
+		}
+	}
+}
+
+// preloadingKey the context key of the queries of the nested preloads
+type preloadingKey struct{}
+
+// preloadedPaths returns the paths of the preloaded relationships, the embedded structs are omitted
+func preloadedPaths(nodes []*gorm.PreloadNode, paths []string) []string {
+	for _, node := range nodes {
+		if node.Relationship != nil {
+			paths = append(paths, node.Path)
+		}
+		paths = preloadedPaths(node.Children, paths)
 	}
+	return paths
 }
 
 func AfterQuery(db *gorm.DB) {
@@ -291,7 +369,14 @@ func AfterQuery(db *gorm.DB) {
 		fromClause.Expression = clause.From{Tables: v.Tables, Joins: utils.RTrimSlice(v.Joins, len(db.Statement.Joins))} // keep the original From Joins
 		db.Statement.Clauses["FROM"] = fromClause
 	}
-	if db.Error == nil && db.Statement.Schema != nil && !db.Statement.SkipHooks && db.Statement.Schema.AfterFind && db.RowsAffected > 0 {
+
+	// cache the results before AfterFind, which is called for the cached results again
+	db.Statement.SaveCache()
+	if db.Statement.CacheHit() && db.CacheSkipAfterFind {
+		return
+	}
+
+	if db.Error == nil && db.Statement.Schema != nil && !skipHooks(db) && db.Statement.Schema.AfterFind && db.RowsAffected > 0 {
 		callMethod(db, func(value interface{}, tx *gorm.DB) bool {
 			if i, ok := value.(AfterFindInterface); ok {
 				db.AddError(i.AfterFind(tx))
@@ -300,4 +385,69 @@ func AfterQuery(db *gorm.DB) {
 			return false
 		})
 	}
+
+	if db.RowsAffected > 0 {
+		callModelHooks(db, func(hook gorm.ModelHook) func(*gorm.DB, interface{}) error { return hook.AfterFind })
+	}
+
+	// snapshot the loaded models after the hooks, which might change them
+	if db.Error == nil && db.RowsAffected > 0 {
+		db.Statement.SnapshotChanges()
+		db.Statement.RecordRedacted()
+	}
+}
+
+// collateOrderColumns orders the columns of the fields tagged with `queryCollate` with their collations
+func collateOrderColumns(stmt *gorm.Statement) {
+	c, ok := stmt.Clauses["ORDER BY"]
+	orderBy, isOrderBy := c.Expression.(clause.OrderBy)
+	if !ok || !isOrderBy {
+		return
+	}
+
+	var columns []clause.OrderByColumn
+	for idx, column := range orderBy.Columns {
+		if column.Collate != "" {
+			continue
+		} else if column.Column.Raw {
+			// the raw names of the fields, e.g: Order("email DESC"), are resolved to the columns to be collated
+			var isIdentifier bool
+			if column, isIdentifier = rawOrderColumn(column.Column.Name); !isIdentifier {
+				continue
+			}
+		}
+
+		if column.Column.Table != "" && column.Column.Table != clause.CurrentTable && column.Column.Table != stmt.Table {
+			continue
+		}
+
+		if field := stmt.Schema.LookUpField(column.Column.Name); field != nil && field.QueryCollate != "" {
+			if columns == nil {
+				// the columns may be shared with other statements
+				columns = append([]clause.OrderByColumn(nil), orderBy.Columns...)
+			}
+			columns[idx] = clause.OrderByColumn{
+				Column: clause.Column{Table: column.Column.Table, Name: field.DBName}, Desc: column.Desc, Collate: field.QueryCollate,
+			}
+		}
+	}
+
+	if columns != nil {
+		orderBy.Columns = columns
+		c.Expression = orderBy
+		stmt.Clauses["ORDER BY"] = c
+	}
+}
+
+// rawOrderColumn parses the raw order of a single identifier followed by ASC or DESC optionally, e.g: email DESC
+func rawOrderColumn(order string) (clause.OrderByColumn, bool) {
+	fields := strings.Fields(order)
+	if len(fields) == 2 && (strings.EqualFold(fields[1], "ASC") || strings.EqualFold(fields[1], "DESC")) {
+		column, ok := clause.ParseIdentifier(fields[0])
+		return clause.OrderByColumn{Column: column, Desc: strings.EqualFold(fields[1], "DESC")}, ok
+	} else if len(fields) == 1 {
+		column, ok := clause.ParseIdentifier(fields[0])
+		return clause.OrderByColumn{Column: column}, ok
+	}
+	return clause.OrderByColumn{}, false
 }
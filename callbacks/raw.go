@@ -5,13 +5,20 @@ import (
 )
 
 func RawExec(db *gorm.DB) {
+	noteRawTenancy(db)
+
+	if db.Error == nil && db.BlockGlobalWrite && !allowGlobalWrite(db) && isGlobalWriteSQL(db.Statement.SQL.String()) {
+		db.AddError(gorm.ErrMissingWhereClause)
+	}
+
 	if db.Error == nil && !db.DryRun {
-		result, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...)
+		result, err := db.Statement.ExecContext()
 		if err != nil {
 			db.AddError(err)
 			return
 		}
 
+		db.Statement.Result = result
 		db.RowsAffected, _ = result.RowsAffected()
 	}
 }
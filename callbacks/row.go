@@ -4,18 +4,40 @@ import (
 	"gorm.io/gorm"
 )
 
-func RowQuery(db *gorm.DB) {
+// BuildRowSQL builds the SQL of the statement queried by Row and Rows, which is called before gorm:row, so the
+// callbacks registered between them could inspect or modify it
+func BuildRowSQL(db *gorm.DB) {
 	if db.Error == nil {
 		BuildQuerySQL(db)
-		if db.DryRun || db.Error != nil {
+	}
+}
+
+func RowQuery(db *gorm.DB) {
+	if db.Error == nil {
+		if db.DryRun {
 			return
 		}
 
 		if isRows, ok := db.Get("rows"); ok && isRows.(bool) {
 			db.Statement.Settings.Delete("rows")
-			db.Statement.Dest, db.Error = db.Statement.ConnPool.QueryContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...)
+			for attempt := 0; ; attempt++ {
+				db.Statement.Dest, db.Error = db.Statement.QueryContext()
+				if !db.ShouldRetryTransient(attempt, db.Error) {
+					break
+				}
+			}
 		} else {
-			db.Statement.Dest = db.Statement.ConnPool.QueryRowContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...)
+			for attempt := 0; ; attempt++ {
+				row := db.Statement.QueryRowContext()
+				if row == nil {
+					// aborted by Config.SQLRewriter
+					break
+				}
+				db.Statement.Dest = row
+				if !db.ShouldRetryTransient(attempt, row.Err()) {
+					break
+				}
+			}
 		}
 
 		db.RowsAffected = -1
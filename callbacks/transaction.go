@@ -5,7 +5,7 @@ import (
 )
 
 func BeginTransaction(db *gorm.DB) {
-	if !db.Config.SkipDefaultTransaction && db.Error == nil {
+	if !db.Config.SkipDefaultTransaction && !db.DryRun && db.Error == nil {
 		if tx := db.Begin(); tx.Error == nil {
 			db.Statement.ConnPool = tx.Statement.ConnPool
 			db.InstanceSet("gorm:started_transaction", true)
@@ -30,3 +30,43 @@ func CommitOrRollbackTransaction(db *gorm.DB) {
 		}
 	}
 }
+
+// RegisterTransactionHooks registers models' AfterCommit and AfterRollback hooks to the current transaction
+func RegisterTransactionHooks(db *gorm.DB) {
+	if db.Statement.Schema != nil && !skipHooks(db) && (db.Statement.Schema.AfterCommit || db.Statement.Schema.AfterRollback) {
+		// hooks are called after the transaction finished, use a session without the transaction
+		hookTx := db.Session(&gorm.Session{NewDB: true, Context: db.Statement.Context})
+		hookTx.Statement.ConnPool = db.ConnPool
+
+		ctx := db.Statement.Context
+		logError := func(hook string, err error) {
+			if err != nil {
+				db.Logger.Error(ctx, "%s hook failed, got error %v", hook, err)
+			}
+		}
+
+		callMethod(db, func(value interface{}, tx *gorm.DB) (called bool) {
+			if db.Statement.Schema.AfterCommit && db.Error == nil {
+				if i, ok := value.(AfterCommitInterface); ok {
+					called = true
+					db.OnCommit(func() { logError("AfterCommit", i.AfterCommit(hookTx)) })
+				}
+			}
+
+			if db.Statement.Schema.AfterRollback {
+				if i, ok := value.(AfterRollbackInterface); ok {
+					called = true
+					db.OnRollback(func() { logError("AfterRollback", i.AfterRollback(hookTx)) })
+				}
+			}
+			return called
+		})
+	}
+}
+
+// InvalidateCache deletes the cached results of the reads of the written table, see gorm.Config.CacheStore
+func InvalidateCache(db *gorm.DB) {
+	if db.Error == nil && db.RowsAffected > 0 && !db.DryRun {
+		db.Statement.InvalidateCache()
+	}
+}
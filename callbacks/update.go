@@ -7,7 +7,6 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
-	"gorm.io/gorm/utils"
 )
 
 func SetupUpdateReflectValue(db *gorm.DB) {
@@ -26,12 +25,26 @@ func SetupUpdateReflectValue(db *gorm.DB) {
 				}
 			}
 		}
+
+		// update columns are resolved when hooks ask for them with Statement.UpdateColumns
+		if _, ok := db.Statement.Clauses["SET"]; !ok && db.Statement.SQL.Len() == 0 && db.Statement.Dest != nil {
+			stmt := db.Statement
+			db.InstanceSet("gorm:update_columns", func() clause.Set { return convertToAssignments(stmt, true) })
+		}
 	}
 }
 
 // BeforeUpdate before update hooks
 func BeforeUpdate(db *gorm.DB) {
-	if db.Error == nil && db.Statement.Schema != nil && !db.Statement.SkipHooks && (db.Statement.Schema.BeforeSave || db.Statement.Schema.BeforeUpdate) {
+	if !callPartialBatchHooks(db, beforeUpdate) {
+		beforeUpdate(db)
+	}
+}
+
+func beforeUpdate(db *gorm.DB) {
+	callModelHooks(db, func(hook gorm.ModelHook) func(*gorm.DB, interface{}) error { return hook.BeforeUpdate })
+
+	if db.Error == nil && db.Statement.Schema != nil && !skipHooks(db) && (db.Statement.Schema.BeforeSave || db.Statement.Schema.BeforeUpdate) {
 		callMethod(db, func(value interface{}, tx *gorm.DB) (called bool) {
 			if db.Statement.Schema.BeforeSave {
 				if i, ok := value.(BeforeSaveInterface); ok {
@@ -54,13 +67,18 @@ func BeforeUpdate(db *gorm.DB) {
 
 // Update update hook
 func Update(config *Config) func(db *gorm.DB) {
-	supportReturning := utils.Contains(config.UpdateClauses, "RETURNING")
-
 	return func(db *gorm.DB) {
 		if db.Error != nil {
 			return
 		}
 
+		supportReturning := supportsReturning(db, config.UpdateClauses)
+
+		if db.Statement.SQL.Len() == 0 {
+			db.Statement.AddTenantCondition()
+			db.Statement.AddDefaultScope(true)
+		}
+
 		if db.Statement.Schema != nil {
 			for _, c := range db.Statement.Schema.UpdateClauses {
 				db.Statement.AddClause(c)
@@ -71,33 +89,44 @@ func Update(config *Config) func(db *gorm.DB) {
 			db.Statement.SQL.Grow(180)
 			db.Statement.AddClauseIfNotExists(clause.Update{})
 			if _, ok := db.Statement.Clauses["SET"]; !ok {
-				if set := ConvertToAssignments(db.Statement); len(set) != 0 {
+				set := ConvertToAssignments(db.Statement)
+				db.InstanceSet("gorm:update_columns", set)
+				if len(set) != 0 {
 					defer delete(db.Statement.Clauses, "SET")
 					db.Statement.AddClause(set)
 				} else {
 					return
 				}
+			} else if set, ok := db.Statement.Clauses["SET"].Expression.(clause.Set); ok {
+				defer db.Statement.AddClause(set)
+				db.Statement.AddClause(subqueryAssignments(set))
 			}
 
-			db.Statement.Build(db.Statement.BuildClauses...)
+			db.Statement.BuildReturning()
+			db.Statement.BuildLimited(db.Statement.BuildClauses...)
+			db.InstanceSet("gorm:conditions_built", true)
 		}
 
 		checkMissingWhereConditions(db)
+		db.AddError(db.Statement.CheckRowsAffectedMode())
 
 		if !db.DryRun && db.Error == nil {
 			if ok, mode := hasReturning(db, supportReturning); ok {
-				if rows, err := db.Statement.ConnPool.QueryContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...); db.AddError(err) == nil {
+				if rows, err := db.Statement.QueryContext(); db.AddError(err) == nil {
 					dest := db.Statement.Dest
 					db.Statement.Dest = db.Statement.ReflectValue.Addr().Interface()
 					gorm.Scan(rows, db, mode)
 					db.Statement.Dest = dest
 					db.AddError(rows.Close())
 				}
-			} else {
-				result, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...)
+			} else if matched, countMatched := db.Statement.CountMatchedRows(); db.Error == nil {
+				result, err := db.Statement.ExecContext()
 
 				if db.AddError(err) == nil {
 					db.RowsAffected, _ = result.RowsAffected()
+					if countMatched && matched > db.RowsAffected {
+						db.RowsAffected = matched
+					}
 				}
 			}
 		}
@@ -106,7 +135,7 @@ func Update(config *Config) func(db *gorm.DB) {
 
 // AfterUpdate after update hooks
 func AfterUpdate(db *gorm.DB) {
-	if db.Error == nil && db.Statement.Schema != nil && !db.Statement.SkipHooks && (db.Statement.Schema.AfterSave || db.Statement.Schema.AfterUpdate) {
+	if db.Error == nil && db.Statement.Schema != nil && !skipHooks(db) && (db.Statement.Schema.AfterSave || db.Statement.Schema.AfterUpdate) {
 		callMethod(db, func(value interface{}, tx *gorm.DB) (called bool) {
 			if db.Statement.Schema.AfterUpdate {
 				if i, ok := value.(AfterUpdateInterface); ok {
@@ -125,13 +154,34 @@ func AfterUpdate(db *gorm.DB) {
 			return called
 		})
 	}
+
+	callModelHooks(db, func(hook gorm.ModelHook) func(*gorm.DB, interface{}) error { return hook.AfterUpdate })
+}
+
+// subqueryAssignments wraps the subqueries assigned by set in parentheses, e.g: SET balance = (SELECT ...)
+func subqueryAssignments(set clause.Set) clause.Set {
+	assignments := make(clause.Set, len(set))
+	for idx, assignment := range set {
+		if _, ok := assignment.Value.(*gorm.DB); ok {
+			assignment.Value = []interface{}{assignment.Value}
+		}
+		assignments[idx] = assignment
+	}
+	return assignments
 }
 
 // ConvertToAssignments convert to update assignments
 func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
+	return convertToAssignments(stmt, false)
+}
+
+// convertToAssignments convert to update assignments, the statement and model won't be changed when preview
+func convertToAssignments(stmt *gorm.Statement, preview bool) (set clause.Set) {
 	var (
 		selectColumns, restricted = stmt.SelectAndOmitColumns(false, true)
 		assignValue               func(field *schema.Field, value interface{})
+		auditor                   = &auditUser{stmt: stmt}
+		trackTime                 = !stmt.DB.SkipAutoTimeTracking
 	)
 
 	switch stmt.ReflectValue.Kind() {
@@ -154,12 +204,16 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 		}
 	}
 
+	if preview {
+		assignValue = func(field *schema.Field, value interface{}) {}
+	}
+
 	updatingValue := reflect.ValueOf(stmt.Dest)
 	for updatingValue.Kind() == reflect.Ptr {
 		updatingValue = updatingValue.Elem()
 	}
 
-	if !updatingValue.CanAddr() || stmt.Dest != stmt.Model {
+	if !preview && (!updatingValue.CanAddr() || stmt.Dest != stmt.Model) {
 		switch stmt.ReflectValue.Kind() {
 		case reflect.Slice, reflect.Array:
 			if size := stmt.ReflectValue.Len(); size > 0 {
@@ -208,7 +262,7 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 				if field := stmt.Schema.LookUpField(k); field != nil {
 					if field.DBName != "" {
 						if v, ok := selectColumns[field.DBName]; (ok && v) || (!ok && !restricted) {
-							set = append(set, clause.Assignment{Column: clause.Column{Name: field.DBName}, Value: kv})
+							set = append(set, clause.Assignment{Column: clause.Column{Name: field.DBName}, Value: field.DurationValue(stmt.Context, kv)})
 							assignValue(field, value[k])
 						}
 					} else if v, ok := selectColumns[field.Name]; (ok && v) || (!ok && !restricted) {
@@ -227,6 +281,10 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 			for _, dbName := range stmt.Schema.DBNames {
 				field := stmt.Schema.LookUpField(dbName)
 				if field.AutoUpdateTime > 0 && value[field.Name] == nil && value[field.DBName] == nil {
+					if stmt.DB.SkipAutoTimeTracking {
+						continue
+					}
+
 					if v, ok := selectColumns[field.DBName]; (ok && v) || !ok {
 						now := stmt.DB.NowFunc()
 						assignValue(field, now)
@@ -241,6 +299,13 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 							set = append(set, clause.Assignment{Column: clause.Column{Name: field.DBName}, Value: now})
 						}
 					}
+				} else if field.AutoUpdatedBy && value[field.Name] == nil && value[field.DBName] == nil {
+					if v, ok := selectColumns[field.DBName]; (ok && v) || !ok {
+						if user, ok := auditor.get(); ok {
+							assignValue(field, user)
+							set = append(set, clause.Assignment{Column: clause.Column{Name: field.DBName}, Value: user})
+						}
+					}
 				}
 			}
 		}
@@ -262,9 +327,9 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 			for _, dbName := range stmt.Schema.DBNames {
 				if field := updatingSchema.LookUpField(dbName); field != nil {
 					if !field.PrimaryKey || !updatingValue.CanAddr() || stmt.Dest != stmt.Model {
-						if v, ok := selectColumns[field.DBName]; (ok && v) || (!ok && (!restricted || (!stmt.SkipHooks && field.AutoUpdateTime > 0))) {
+						if v, ok := selectColumns[field.DBName]; (ok && v) || (!ok && (!restricted || (!stmt.SkipHooks && ((trackTime && field.AutoUpdateTime > 0) || field.AutoUpdatedBy)))) {
 							value, isZero := field.ValueOf(stmt.Context, updatingValue)
-							if !stmt.SkipHooks && field.AutoUpdateTime > 0 {
+							if !stmt.SkipHooks && trackTime && field.AutoUpdateTime > 0 {
 								if field.AutoUpdateTime == schema.UnixNanosecond {
 									value = stmt.DB.NowFunc().UnixNano()
 								} else if field.AutoUpdateTime == schema.UnixMillisecond {
@@ -275,6 +340,10 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 									value = stmt.DB.NowFunc()
 								}
 								isZero = false
+							} else if !stmt.SkipHooks && field.AutoUpdatedBy {
+								if user, ok := auditor.get(); ok {
+									value, isZero = user, false
+								}
 							}
 
 							if (ok || !isZero) && field.Updatable {
@@ -288,7 +357,7 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 								assignValue(assignField, value)
 							}
 						}
-					} else {
+					} else if !preview {
 						if value, isZero := field.ValueOf(stmt.Context, updatingValue); !isZero {
 							stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.Eq{Column: field.DBName, Value: value}}})
 						}
@@ -296,9 +365,14 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 				}
 			}
 		default:
-			stmt.AddError(gorm.ErrInvalidData)
+			if !preview {
+				stmt.AddError(gorm.ErrInvalidData)
+			}
 		}
 	}
 
+	for idx, assignment := range set {
+		set[idx].Value = writableDefault(stmt, assignment.Column.Name, assignment.Value)
+	}
 	return
 }
@@ -0,0 +1,151 @@
+package gorm
+
+import (
+	"context"
+
+	"gorm.io/gorm/utils"
+)
+
+// Capability a feature which is only supported by some databases, see DB.Supports
+type Capability string
+
+// the capabilities of databases, dialectors could define their own capabilities
+const (
+	CapabilityReturning       Capability = "returning"        // INSERT/UPDATE/DELETE ... RETURNING, or see ReturningBuilder
+	CapabilityOnConflict      Capability = "on_conflict"      // upserts with clause.OnConflict
+	CapabilityCTE             Capability = "cte"              // WITH common table expressions
+	CapabilityWindowFunctions Capability = "window_functions" // e.g: ROW_NUMBER() OVER (...)
+	CapabilityPartialIndex    Capability = "partial_index"    // CREATE INDEX ... WHERE
+	CapabilitySkipLocked      Capability = "skip_locked"      // SELECT ... FOR UPDATE SKIP LOCKED
+	CapabilityMultiStatement  Capability = "multi_statement"  // multiple statements executed at once
+	CapabilitySavePoint       Capability = "savepoint"        // SAVEPOINT for nested transactions
+	CapabilityTwoPhaseCommit  Capability = "two_phase_commit" // PREPARE TRANSACTION, see TxPreparer
+	CapabilityUpdateFrom      Capability = "update_from"      // UPDATE ... SET ... FROM with clause.From
+	CapabilityLimitedWrite    Capability = "limited_write"    // UPDATE/DELETE ... ORDER BY ... LIMIT
+	CapabilityTableFunctions  Capability = "table_functions"  // SELECT ... FROM generate_series(...), see clause.FunctionTable
+	CapabilityHavingAlias     Capability = "having_alias"     // HAVING referencing the aliases of the selected columns
+	CapabilityArrayTypes      Capability = "array_types"      // the array, composite and interval types, see schema.ArraySerializer
+	CapabilityDriverArrays    Capability = "driver_arrays"    // the driver binds the slices as arrays, see schema.WithDriverArrays
+	// RowsAffected of UPDATE counts the matched rows instead of the changed rows, see RowsAffectedMatched
+	CapabilityMatchedRowsAffected Capability = "matched_rows_affected"
+)
+
+// Capabilities the supported and unsupported capabilities of a dialector, the missing ones are unknown
+type Capabilities map[Capability]bool
+
+// CapabilityDialector the dialector declaring its capabilities, which are preferred to the capabilities inferred by
+// DB.Supports, so the dialectors wrapping other dialectors could keep or override them
+type CapabilityDialector interface {
+	Capabilities() Capabilities
+}
+
+// DefaultCapabilityProbes the read-only queries probing the capabilities supported by most databases, which could be
+// used as Config.CapabilityProbes
+var DefaultCapabilityProbes = map[Capability]string{
+	CapabilityCTE:             "WITH gorm_probe AS (SELECT 1 AS n) SELECT n FROM gorm_probe",
+	CapabilityWindowFunctions: "SELECT ROW_NUMBER() OVER (ORDER BY n) FROM (SELECT 1 AS n) gorm_probe",
+	CapabilityHavingAlias:     "SELECT count(*) AS gorm_total FROM (SELECT 1 AS n) gorm_probe GROUP BY n HAVING gorm_total > 0",
+}
+
+// Supports reports whether the database supports the capability, which is declared by the CapabilityDialector, or
+// inferred from the interfaces implemented by the dialector and the registered clauses, e.g:
+// SavePointerDialectorInterface for CapabilitySavePoint, ReturningBuilder or RETURNING of the create clauses for
+// CapabilityReturning, FROM of the update clauses for CapabilityUpdateFrom, LIMIT of the delete clauses for
+// CapabilityLimitedWrite, the postgres, sqlite and sqlserver dialectors for CapabilityTableFunctions, the mysql and
+// sqlite dialectors for CapabilityHavingAlias, the postgres dialector for CapabilityArrayTypes, the postgres, sqlite and
+// sqlserver dialectors for CapabilityMatchedRowsAffected, the mysql dialector reports the changed rows unless the DSN
+// sets clientFoundRows=true, which could be declared by the CapabilityDialector.
+// The unknown capabilities are probed by the queries of Config.CapabilityProbes once, or unsupported.
+func (db *DB) Supports(capability Capability) bool {
+	if dialector, ok := db.Dialector.(CapabilityDialector); ok {
+		if supported, ok := dialector.Capabilities()[capability]; ok {
+			return supported
+		}
+	}
+
+	switch capability {
+	case CapabilitySavePoint:
+		if _, ok := db.Dialector.(SavePointerDialectorInterface); ok {
+			return true
+		}
+	case CapabilityTwoPhaseCommit:
+		if _, ok := db.Dialector.(TxPreparer); ok {
+			return true
+		}
+	case CapabilityReturning:
+		if _, ok := db.Dialector.(ReturningBuilder); ok {
+			return true
+		}
+
+		if _, ok := db.ClauseBuilders["RETURNING"]; ok || utils.Contains(db.callbacks.Create().Clauses, "RETURNING") {
+			return true
+		}
+	case CapabilityUpdateFrom:
+		if utils.Contains(db.callbacks.Update().Clauses, "FROM") {
+			return true
+		}
+	case CapabilityLimitedWrite:
+		if utils.Contains(db.callbacks.Delete().Clauses, "LIMIT") {
+			return true
+		}
+	case CapabilityTableFunctions:
+		switch db.Dialector.Name() {
+		case "postgres", "sqlite", "sqlserver":
+			return true
+		}
+	case CapabilityHavingAlias:
+		switch db.Dialector.Name() {
+		case "mysql", "sqlite":
+			return true
+		}
+	case CapabilityMatchedRowsAffected:
+		switch db.Dialector.Name() {
+		case "postgres", "sqlite", "sqlserver":
+			return true
+		}
+	case CapabilityArrayTypes:
+		if db.Dialector.Name() == "postgres" {
+			return true
+		}
+	case CapabilityOnConflict:
+		if _, ok := db.ClauseBuilders["ON CONFLICT"]; ok || utils.Contains(db.callbacks.Create().Clauses, "ON CONFLICT") {
+			return true
+		}
+	}
+
+	if query, ok := db.CapabilityProbes[capability]; ok {
+		return db.probeCapability(capability, query)
+	}
+	return false
+}
+
+// probeCapability executes query with the *sql.DB, the capability is supported if it succeeds, the result is cached
+func (db *DB) probeCapability(capability Capability, query string) bool {
+	key := string(capability) + ":" + query
+	if db.capabilities != nil {
+		if supported, ok := db.capabilities.Load(key); ok {
+			return supported.(bool)
+		}
+	}
+
+	// probe outside transactions, as failed queries abort the transactions of some databases
+	sqlDB, err := db.DB()
+	if err != nil {
+		return false
+	}
+
+	ctx := context.Background()
+	if db.Statement != nil && db.Statement.Context != nil {
+		ctx = db.Statement.Context
+	}
+
+	supported := false
+	if rows, err := sqlDB.QueryContext(ctx, query); err == nil {
+		supported = rows.Close() == nil && rows.Err() == nil
+	}
+
+	if ctx.Err() == nil && db.capabilities != nil {
+		db.capabilities.Store(key, supported)
+	}
+	return supported
+}
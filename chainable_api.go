@@ -2,10 +2,14 @@ package gorm
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
+	"runtime"
 	"strings"
+	"sync"
 
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 	"gorm.io/gorm/utils"
 )
 
@@ -55,15 +59,60 @@ func (db *DB) Clauses(conds ...clause.Expression) (tx *DB) {
 	return
 }
 
+// WithClauseBuilder builds the clause of the name with builder for the statement instead of Config.ClauseBuilders
+// and the default building of the clause, e.g: the LIMIT of a legacy query
+//
+//	db.WithClauseBuilder("LIMIT", func(c clause.Clause, builder clause.Builder) {
+//		if limit, ok := c.Expression.(clause.Limit); ok && limit.Limit != nil {
+//			builder.WriteString("LIMIT " + strconv.Itoa(*limit.Limit))
+//		}
+//	}).Find(&users)
+func (db *DB) WithClauseBuilder(name string, builder clause.ClauseBuilder) (tx *DB) {
+	tx = db.getInstance()
+	// copied as the clause builders are shared by the cloned statements
+	clauseBuilders := make(map[string]clause.ClauseBuilder, len(tx.Statement.ClauseBuilders)+1)
+	for k, b := range tx.Statement.ClauseBuilders {
+		clauseBuilders[k] = b
+	}
+	clauseBuilders[name] = builder
+	tx.Statement.ClauseBuilders = clauseBuilders
+	return
+}
+
 var tableRegexp = regexp.MustCompile(`(?i)(?:.+? AS (\w+)\s*(?:$|,)|^\w+\s+(\w+)$)`)
 
-// Table specify the table you would like to run db operations
+// Table specify the table you would like to run db operations, or the table-valued function with clause.FunctionTable,
+// which requires CapabilityTableFunctions
 //
 //	// Get a user
 //	db.Table("users").Take(&result)
-func (db *DB) Table(name string, args ...interface{}) (tx *DB) {
+//	// Query the rows returned by the function
+//	db.Table(clause.FunctionTable{Name: "search_products", Args: []interface{}{query}, Alias: "p"}).Find(&products)
+func (db *DB) Table(table interface{}, args ...interface{}) (tx *DB) {
 	tx = db.getInstance()
-	if strings.Contains(name, " ") || strings.Contains(name, "`") || len(args) > 0 {
+
+	var name string
+	switch v := table.(type) {
+	case string:
+		name = v
+	case clause.FunctionTable:
+		return tx.functionTable(v)
+	case *clause.FunctionTable:
+		return tx.functionTable(*v)
+	default:
+		rv := reflect.ValueOf(table)
+		if rv.Kind() != reflect.String {
+			tx.AddError(fmt.Errorf("unsupported table %T", table))
+			return
+		}
+		name = rv.String()
+	}
+
+	if column, ok := clause.ParseIdentifier(name); ok && len(args) == 0 && strings.ContainsAny(name, "`\"[") {
+		// the quoted names, e.g: `legacy.orders`, whose dots aren't split, see clause.ParseIdentifier
+		tx.Statement.TableExpr = &clause.Expr{SQL: tx.Statement.Quote(column)}
+		tx.Statement.Table = column.Name
+	} else if strings.Contains(name, " ") || strings.Contains(name, "`") || len(args) > 0 {
 		tx.Statement.TableExpr = &clause.Expr{SQL: name, Vars: args}
 		if results := tableRegexp.FindStringSubmatch(name); len(results) == 3 {
 			if results[1] != "" {
@@ -85,6 +134,21 @@ func (db *DB) Table(name string, args ...interface{}) (tx *DB) {
 	return
 }
 
+// functionTable queries from the table-valued function
+func (db *DB) functionTable(table clause.FunctionTable) (tx *DB) {
+	tx = db
+	if !tx.Supports(CapabilityTableFunctions) {
+		tx.AddError(fmt.Errorf("%w: table-valued function %s isn't supported by %s", ErrUnsupportedDriver, table.Name, tx.Dialector.Name()))
+		return
+	}
+
+	tx.Statement.TableExpr = &clause.Expr{SQL: "?", Vars: []interface{}{table}}
+	if tx.Statement.Table = table.Alias; table.Alias == "" {
+		tx.Statement.Table = table.Name
+	}
+	return
+}
+
 // Distinct specify distinct fields that you want querying
 //
 //	// Select distinct names of users
@@ -166,6 +230,11 @@ func (db *DB) Select(query interface{}, args ...interface{}) (tx *DB) {
 				tx.Statement.Clauses["SELECT"] = clause
 			}
 		}
+	case clause.Expression:
+		tx.Statement.AddClause(clause.Select{
+			Distinct:   db.Statement.Distinct,
+			Expression: v,
+		})
 	default:
 		tx.AddError(fmt.Errorf("unsupported select args %v %v", query, args))
 	}
@@ -173,6 +242,69 @@ func (db *DB) Select(query interface{}, args ...interface{}) (tx *DB) {
 	return
 }
 
+// SelectForType selects the columns of the fields of dest, which is a narrower struct of the model, e.g: a DTO, the
+// fields are mapped to the columns of the model by their column names or field names, the columns qualified with a
+// table, e.g: `gorm:"column:orders.total"`, are selected from the joined tables, ErrInvalidField is added if a field
+// isn't found in the model or the joined relation
+//
+//	db.Model(&User{}).SelectForType(&UserSummary{}).Find(&summaries)
+func (db *DB) SelectForType(dest interface{}) (tx *DB) {
+	tx = db.getInstance()
+	if tx.Statement.Model == nil {
+		tx.AddError(ErrModelValueRequired)
+		return
+	}
+
+	modelSchema, err := schema.Parse(tx.Statement.Model, tx.cacheStore, tx.NamingStrategy)
+	if err != nil {
+		tx.AddError(err)
+		return
+	}
+
+	destSchema, err := schema.Parse(dest, tx.cacheStore, tx.NamingStrategy)
+	if err != nil {
+		tx.AddError(err)
+		return
+	}
+
+	columns := make([]clause.Column, 0, len(destSchema.DBNames))
+	for _, dbName := range destSchema.DBNames {
+		field := destSchema.FieldsByDBName[dbName]
+		if idx := strings.LastIndexByte(dbName, '.'); idx > 0 {
+			table, name := dbName[:idx], dbName[idx+1:]
+			for _, rel := range modelSchema.Relationships.Relations {
+				if (rel.Name == table || rel.FieldSchema.Table == table) && rel.FieldSchema.LookUpField(name) == nil {
+					tx.AddError(fmt.Errorf("%w: %s.%s isn't a field of %s", ErrInvalidField, destSchema.Name, field.Name, rel.FieldSchema.Name))
+					return
+				}
+			}
+
+			// the column name may not be a valid field name of dest, alias it with the field name
+			columns = append(columns, clause.Column{Table: table, Name: name, Alias: field.Name})
+			continue
+		}
+
+		modelField := modelSchema.LookUpField(dbName)
+		if modelField == nil {
+			modelField = modelSchema.LookUpField(field.Name)
+		}
+
+		if modelField == nil || modelField.DBName == "" {
+			tx.AddError(fmt.Errorf("%w: %s.%s isn't a field of %s", ErrInvalidField, destSchema.Name, field.Name, modelSchema.Name))
+			return
+		}
+
+		column := clause.Column{Table: clause.CurrentTable, Name: modelField.DBName}
+		if modelField.DBName != dbName {
+			column.Alias = dbName
+		}
+		columns = append(columns, column)
+	}
+
+	tx.Statement.AddClause(clause.Select{Distinct: tx.Statement.Distinct, Columns: columns})
+	return
+}
+
 // Omit specify fields that you want to ignore when creating, updating and querying
 func (db *DB) Omit(columns ...string) (tx *DB) {
 	tx = db.getInstance()
@@ -220,6 +352,11 @@ func (db *DB) Where(query interface{}, args ...interface{}) (tx *DB) {
 //	db.Not("name = ?", "jinzhu").First(&user)
 func (db *DB) Not(query interface{}, args ...interface{}) (tx *DB) {
 	tx = db.getInstance()
+	// the primary keys aren't in the empty slice, which matches all the rows
+	if rv := reflect.ValueOf(query); len(args) == 0 && (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Len() == 0 {
+		return
+	}
+
 	if conds := tx.Statement.BuildCondition(query, args...); len(conds) > 0 {
 		tx.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.Not(conds...)}})
 	}
@@ -240,7 +377,8 @@ func (db *DB) Or(query interface{}, args ...interface{}) (tx *DB) {
 	return
 }
 
-// Joins specify Joins conditions
+// Joins specify Joins conditions, the relationship paths are checked when they are added if the model is specified
+// by Model before, ErrUnsupportedRelation with the closest relationships is added for the unknown ones
 //
 //	db.Joins("Account").Find(&user)
 //	db.Joins("JOIN emails ON emails.user_id = users.id AND emails.email = ?", "jinzhu@example.org").Find(&user)
@@ -257,12 +395,19 @@ func (db *DB) InnerJoins(query string, args ...interface{}) (tx *DB) {
 
 func joins(db *DB, joinType clause.JoinType, query string, args ...interface{}) (tx *DB) {
 	tx = db.getInstance()
+	// the relationship paths are checked, not the raw SQL
+	if associationPathRegexp.MatchString(query) {
+		if err := tx.Statement.checkAssociation(query); err != nil {
+			tx.AddError(err)
+			return
+		}
+	}
 
 	if len(args) == 1 {
 		if db, ok := args[0].(*DB); ok {
 			j := join{
 				Name: query, Conds: args, Selects: db.Statement.Selects,
-				Omits: db.Statement.Omits, JoinType: joinType,
+				Omits: db.Statement.Omits, JoinType: joinType, Unscoped: db.Statement.Unscoped,
 			}
 			if where, ok := db.Statement.Clauses["WHERE"].Expression.(clause.Where); ok {
 				j.On = &where
@@ -280,20 +425,40 @@ func joins(db *DB, joinType clause.JoinType, query string, args ...interface{})
 //
 //	// Select the sum age of users with given names
 //	db.Model(&User{}).Select("name, sum(age) as total").Group("name").Find(&results)
-func (db *DB) Group(name string) (tx *DB) {
+//	// the columns and tables of args are quoted as identifiers
+//	db.Model(&User{}).Select("lower(name) AS name, sum(age) as total").Group("lower(?)", clause.Col("name")).Find(&results)
+func (db *DB) Group(name string, args ...interface{}) (tx *DB) {
 	tx = db.getInstance()
 
-	fields := strings.FieldsFunc(name, utils.IsValidDBNameChar)
-	tx.Statement.AddClause(clause.GroupBy{
-		Columns: []clause.Column{{Name: name, Raw: len(fields) != 1}},
-	})
+	if len(args) > 0 {
+		tx.Statement.AddClause(clause.GroupBy{
+			Columns: []clause.Column{{Name: tx.Statement.quoteIdentifierVars(name, args), Raw: true}},
+		})
+		return
+	}
+
+	if columns, ok := parseColumns(name); ok {
+		tx.Statement.AddClause(clause.GroupBy{Columns: columns})
+	} else {
+		fields := strings.FieldsFunc(name, utils.IsValidDBNameChar)
+		tx.Statement.AddClause(clause.GroupBy{
+			Columns: []clause.Column{{Name: name, Raw: len(fields) != 1}},
+		})
+	}
 	return
 }
 
-// Having specify HAVING conditions for GROUP BY
+// Having specify HAVING conditions for GROUP BY, the conditions of multiple calls are joined with AND, which accepts
+// the conditions like Where, e.g: clause expressions and maps. The aliases of the selected columns are replaced with
+// the selected expressions if the database doesn't support CapabilityHavingAlias
 //
 //	// Select the sum age of users with name jinzhu
 //	db.Model(&User{}).Select("name, sum(age) as total").Group("name").Having("name = ?", "jinzhu").Find(&result)
+//
+//	// HAVING count(*) > 1 AND total > 100
+//	db.Model(&User{}).Select("name, sum(age) as total").Group("name").
+//		Having(clause.Gt{Column: clause.Expr{SQL: "count(*)"}, Value: 1}).
+//		Having(clause.Gt{Column: "total", Value: 100}).Find(&result)
 func (db *DB) Having(query interface{}, args ...interface{}) (tx *DB) {
 	tx = db.getInstance()
 	tx.Statement.AddClause(clause.GroupBy{
@@ -310,6 +475,7 @@ func (db *DB) Having(query interface{}, args ...interface{}) (tx *DB) {
 //		{Column: clause.Column{Name: "name"}, Desc: true},
 //		{Column: clause.Column{Name: "age"}, Desc: true},
 //	}})
+//	db.Order(gorm.Expr("lower(?) DESC", clause.Col("name")))
 func (db *DB) Order(value interface{}) (tx *DB) {
 	tx = db.getInstance()
 
@@ -320,8 +486,12 @@ func (db *DB) Order(value interface{}) (tx *DB) {
 		tx.Statement.AddClause(clause.OrderBy{
 			Columns: []clause.OrderByColumn{v},
 		})
+	case clause.Expression:
+		tx.Statement.AddClause(clause.OrderBy{Expression: v})
 	case string:
-		if v != "" {
+		if columns, ok := parseOrderColumns(v); ok {
+			tx.Statement.AddClause(clause.OrderBy{Columns: columns})
+		} else if v != "" {
 			tx.Statement.AddClause(clause.OrderBy{
 				Columns: []clause.OrderByColumn{{
 					Column: clause.Column{Name: v, Raw: true},
@@ -375,20 +545,84 @@ func (db *DB) Offset(offset int) (tx *DB) {
 //	db.Scopes(AmountGreaterThan1000, OrderStatus([]string{"paid", "shipped"})).Find(&orders)
 func (db *DB) Scopes(funcs ...func(*DB) *DB) (tx *DB) {
 	tx = db.getInstance()
-	tx.Statement.scopes = append(tx.Statement.scopes, funcs...)
+	for _, fc := range funcs {
+		tx.Statement.scopes = append(tx.Statement.scopes, scope{name: scopeName(fc), fc: fc})
+	}
+	return tx
+}
+
+// scopeNames the names of the scope functions by their entry addresses
+var scopeNames sync.Map
+
+// scopeName returns the name of the scope function, which is resolved once per function
+func scopeName(fc func(*DB) *DB) string {
+	pc := reflect.ValueOf(fc).Pointer()
+	if name, ok := scopeNames.Load(pc); ok {
+		return name.(string)
+	}
+
+	name := runtime.FuncForPC(pc).Name()
+	scopeNames.Store(pc, name)
+	return name
+}
+
+// ScopeOption options of scopes registered with RegisterScope
+type ScopeOption struct {
+	// Unique applies the scope at most once per statement, even if it's requested repeatedly
+	Unique bool
+}
+
+type scope struct {
+	name   string
+	fc     func(*DB) *DB
+	unique bool
+}
+
+// RegisterScope registers the scope fc with name, which could be applied with NamedScopes, registering a name again
+// replaces the scope
+//
+//	db.RegisterScope("tenant", TenantScope, gorm.ScopeOption{Unique: true})
+//	db.NamedScopes("tenant", "notArchived").Find(&orders)
+func (db *DB) RegisterScope(name string, fc func(*DB) *DB, opts ...ScopeOption) {
+	s := scope{name: name, fc: fc}
+	for _, opt := range opts {
+		s.unique = s.unique || opt.Unique
+	}
+	db.namedScopes.Store(name, s)
+}
+
+// NamedScopes applies the scopes registered with RegisterScope by their names, scopes are applied in the call order
+// with the scopes passed to Scopes
+func (db *DB) NamedScopes(names ...string) (tx *DB) {
+	tx = db.getInstance()
+	for _, name := range names {
+		if v, ok := tx.namedScopes.Load(name); ok {
+			tx.Statement.scopes = append(tx.Statement.scopes, v.(scope))
+		} else {
+			tx.AddError(fmt.Errorf("%w: %s", ErrUnregisteredScope, name))
+		}
+	}
 	return tx
 }
 
 func (db *DB) executeScopes() (tx *DB) {
 	scopes := db.Statement.scopes
 	db.Statement.scopes = nil
-	for _, scope := range scopes {
-		db = scope(db)
+	for _, s := range scopes {
+		if s.unique && utils.Contains(db.Statement.AppliedScopes, s.name) {
+			continue
+		}
+
+		db.Statement.AppliedScopes = append(db.Statement.AppliedScopes, s.name)
+		db = s.fc(db)
 	}
 	return db
 }
 
-// Preload preload associations with given conditions
+// Preload preload associations with given conditions, the paths are merged by the rules of Statement.PreloadPlan,
+// preloading a path again with different conditions adds ErrPreloadConflict, the paths are checked when they are
+// added if the model is specified by Model before, ErrUnsupportedRelation with the closest relationships is added for
+// the unknown ones, e.g: "Orderz" of "Orders"
 //
 //	// get all users, and preload all non-cancelled orders
 //	db.Preload("Orders", "state NOT IN (?)", "cancelled").Find(&users)
@@ -397,6 +631,14 @@ func (db *DB) Preload(query string, args ...interface{}) (tx *DB) {
 	if tx.Statement.Preloads == nil {
 		tx.Statement.Preloads = map[string][]interface{}{}
 	}
+
+	if conds, ok := tx.Statement.Preloads[query]; ok && !sameConds(conds, args) {
+		tx.AddError(fmt.Errorf("%w: %s", ErrPreloadConflict, query))
+		return
+	}
+
+	// the unknown paths are added as well, which are returned by Statement.PreloadPlan again
+	tx.AddError(tx.Statement.checkAssociation(query))
 	tx.Statement.Preloads[query] = args
 	return
 }
@@ -457,10 +699,44 @@ func (db *DB) Unscoped() (tx *DB) {
 	return
 }
 
+// UnscopedTables disables the soft deletion scope of the tables only, which are matched with the table names or the
+// aliases of joined relations, the other tables are still scoped, e.g:
+//
+//	// includes the deleted orders of the users not deleted
+//	db.UnscopedTables("orders").Joins("Orders").Find(&users)
+//	db.UnscopedTables("orders").Preload("Orders").Find(&users)
+func (db *DB) UnscopedTables(tables ...string) (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.UnscopedTables = append(tx.Statement.UnscopedTables[:len(tx.Statement.UnscopedTables):len(tx.Statement.UnscopedTables)], tables...)
+	return
+}
+
+// AllowGlobal allows the statement to update or delete without conditions, even when BlockGlobalWrite is enabled
+//
+//	db.Unscoped().AllowGlobal().Exec("DELETE FROM users")
+func (db *DB) AllowGlobal() (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.Settings.Store("gorm:allow_global_write", true)
+	return
+}
+
+// ContinueOnError continues executing the statements of ExecMulti after a statement failed
+//
+//	db.ContinueOnError().ExecMulti(seeds)
+func (db *DB) ContinueOnError() (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.Settings.Store(continueOnErrorKey, true)
+	return
+}
+
 func (db *DB) Raw(sql string, values ...interface{}) (tx *DB) {
 	tx = db.getInstance()
 	tx.Statement.SQL = strings.Builder{}
 
+	if tx.NormalizePlaceholders {
+		sql, values = tx.Statement.normalizePlaceholders(sql, values)
+	}
+
 	if strings.Contains(sql, "@") {
 		clause.NamedExpr{SQL: sql, Vars: values}.Build(tx.Statement)
 	} else {
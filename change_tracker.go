@@ -0,0 +1,241 @@
+package gorm
+
+import (
+	"database/sql/driver"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils"
+)
+
+// ChangeTracker keeps the snapshot of the loaded model embedding it for ChangedFields, so the model is tracked
+// without Session.TrackChanges, and the snapshot lives as long as the model, e.g:
+//
+//	type User struct {
+//		gorm.ChangeTracker
+//		ID   uint
+//		Name string
+//	}
+type ChangeTracker struct {
+	snapshot map[string]interface{}
+}
+
+func (tracker *ChangeTracker) changeTracker() *ChangeTracker {
+	return tracker
+}
+
+// changeTrackable the models embedding ChangeTracker
+type changeTrackable interface {
+	changeTracker() *ChangeTracker
+}
+
+var changeTrackableType = reflect.TypeOf((*changeTrackable)(nil)).Elem()
+
+// SnapshotChanges keeps the snapshots of the loaded models for ChangedFields, if they embed ChangeTracker or the
+// session tracks changes, see Session.TrackChanges
+func (stmt *Statement) SnapshotChanges() {
+	if stmt.Schema == nil || (stmt.DB.changeSnapshots == nil && !reflect.PtrTo(stmt.Schema.ModelType).Implements(changeTrackableType)) {
+		return
+	}
+
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			stmt.snapshotChanges(stmt.ReflectValue.Index(i))
+		}
+	case reflect.Struct:
+		stmt.snapshotChanges(stmt.ReflectValue)
+	}
+}
+
+func (stmt *Statement) snapshotChanges(rv reflect.Value) {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Type() != stmt.Schema.ModelType || !rv.CanAddr() {
+		return
+	}
+
+	snapshot := make(map[string]interface{}, len(stmt.Schema.DBNames))
+	for _, field := range stmt.Schema.Fields {
+		// the fields never updated aren't compared, the fields tagged with `skipDiff` are always changed
+		if field.DBName != "" && field.Updatable && !field.SkipDiff {
+			value, _ := field.ValueOf(stmt.Context, rv)
+			snapshot[field.DBName] = snapshotValue(value)
+		}
+	}
+
+	model := rv.Addr().Interface()
+	if trackable, ok := model.(changeTrackable); ok {
+		trackable.changeTracker().snapshot = snapshot
+	} else {
+		stmt.DB.changeSnapshots.Store(model, snapshot)
+	}
+}
+
+// snapshotValue returns the value of the snapshot, the bytes are copied as they may be modified in place
+func snapshotValue(value interface{}) interface{} {
+	value = comparedValue(value)
+	if bytes, ok := value.([]byte); ok {
+		return append([]byte(nil), bytes...)
+	}
+	return value
+}
+
+// comparedValue returns the value to compare, the valuers, e.g: serializer fields, are compared by their driver values
+func comparedValue(value interface{}) interface{} {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+
+		if valuer, ok := rv.Interface().(driver.Valuer); ok {
+			if v, err := valuer.Value(); err == nil {
+				return comparedValue(v)
+			}
+		}
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() {
+		return nil
+	}
+
+	value = rv.Interface()
+	if valuer, ok := value.(driver.Valuer); ok {
+		if v, err := valuer.Value(); err == nil {
+			return comparedValue(v)
+		}
+	}
+	return value
+}
+
+// ChangedFields returns the names of the fields of model changed since it was loaded, the model should be loaded by
+// the session with Session.TrackChanges or embed ChangeTracker, returns nil if it isn't tracked or not changed, e.g:
+//
+//	tx := db.Session(&gorm.Session{TrackChanges: true})
+//	tx.First(&user)
+//	user.Name = "jinzhu"
+//	gorm.ChangedFields(tx, &user) // [Name]
+func ChangedFields(db *DB, model interface{}) []string {
+	fields, _ := db.changedFields(model)
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, field.Name)
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+	return names
+}
+
+// changedFields returns the fields of model changed since it was loaded, returns false if it isn't tracked
+func (db *DB) changedFields(model interface{}) (fields []*schema.Field, tracked bool) {
+	rv := reflect.ValueOf(model)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	var snapshot map[string]interface{}
+	trackable, ok := model.(changeTrackable)
+	if ok {
+		snapshot = trackable.changeTracker().snapshot
+	} else if db.changeSnapshots != nil {
+		if v, ok := db.changeSnapshots.Load(model); ok {
+			snapshot = v.(map[string]interface{})
+		}
+	}
+
+	if snapshot == nil {
+		return nil, false
+	}
+
+	modelSchema, err := schema.Parse(model, db.cacheStore, db.NamingStrategy)
+	if err != nil {
+		return nil, false
+	}
+
+	// the snapshot of another record is stale, e.g: the slice elements replaced by new models at the same addresses
+	ctx := db.Statement.Context
+	for _, field := range modelSchema.PrimaryFields {
+		value, _ := field.ValueOf(ctx, rv.Elem())
+		if old, ok := snapshot[field.DBName]; ok && !utils.AssertEqual(old, comparedValue(value)) {
+			if trackable == nil {
+				db.changeSnapshots.Delete(model)
+			}
+			return nil, false
+		}
+	}
+
+	for _, field := range modelSchema.Fields {
+		if field.DBName == "" || !field.Updatable {
+			continue
+		}
+
+		if field.SkipDiff {
+			fields = append(fields, field)
+			continue
+		}
+
+		value, _ := field.ValueOf(ctx, rv.Elem())
+		if old, ok := snapshot[field.DBName]; !ok || !utils.AssertEqual(old, comparedValue(value)) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, true
+}
+
+// ForgetChanges releases the snapshots of the models kept by the session with Session.TrackChanges, the snapshots are
+// also released after the models are saved or updated, the models embedding ChangeTracker keep their snapshots, e.g:
+//
+//	tx := db.Session(&gorm.Session{TrackChanges: true})
+//	tx.Find(&users)
+//	gorm.ForgetChanges(tx, &users)
+func ForgetChanges(db *DB, models ...interface{}) {
+	if db.changeSnapshots == nil {
+		return
+	}
+
+	for _, model := range models {
+		eachModel(reflect.ValueOf(model), func(rv reflect.Value) {
+			db.changeSnapshots.Delete(rv.Addr().Interface())
+		})
+	}
+}
+
+// releaseChanges releases the snapshots of the session of the saved or updated models, the models embedding
+// ChangeTracker are snapshotted again as their snapshots live as long as them
+func (stmt *Statement) releaseChanges() {
+	if stmt.Schema == nil || !stmt.ReflectValue.IsValid() {
+		return
+	}
+
+	if reflect.PtrTo(stmt.Schema.ModelType).Implements(changeTrackableType) {
+		stmt.SnapshotChanges()
+	} else if stmt.DB.changeSnapshots != nil {
+		eachModel(stmt.ReflectValue, func(rv reflect.Value) {
+			stmt.DB.changeSnapshots.Delete(rv.Addr().Interface())
+		})
+	}
+}
+
+// updateChanges updates the changed fields of the tracked model only
+func (db *DB) updateChanges(fields []*schema.Field) (tx *DB) {
+	tx = db.getInstance()
+	if len(fields) == 0 {
+		return tx
+	}
+
+	tx.Statement.Selects = make([]string, 0, len(fields))
+	for _, field := range fields {
+		tx.Statement.Selects = append(tx.Statement.Selects, field.Name)
+	}
+
+	return tx.callbacks.Update().Execute(tx)
+}
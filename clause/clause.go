@@ -1,5 +1,10 @@
 package clause
 
+import (
+	"strings"
+	"unicode"
+)
+
 // Interface clause interface
 type Interface interface {
 	Name() string
@@ -23,6 +28,47 @@ type Builder interface {
 	AddError(error) error
 }
 
+// ColumnVarAdder the builder tracking the columns of the added vars, e.g: gorm.Statement
+type ColumnVarAdder interface {
+	AddColumnVar(writer Writer, column interface{}, vars ...interface{})
+}
+
+// DialectNamer the builder knowing the name of its dialect, e.g: gorm.Statement, used by the expressions written
+// differently by the dialects, e.g: EqNullSafe
+type DialectNamer interface {
+	DialectName() string
+}
+
+// dialectName returns the name of the dialect of the builder, or empty if it's unknown
+func dialectName(builder Builder) string {
+	if namer, ok := builder.(DialectNamer); ok {
+		return namer.DialectName()
+	}
+	return ""
+}
+
+// EmptyINReporter the builder reporting the IN conditions without values, e.g: gorm.Statement, which logs them, or
+// returns gorm.ErrEmptyInCondition if gorm.Config.ErrorOnEmptyIN is enabled
+type EmptyINReporter interface {
+	ReportEmptyIN(column interface{})
+}
+
+// reportEmptyIN reports the IN condition of column without values if the builder is an EmptyINReporter
+func reportEmptyIN(builder Builder, column interface{}) {
+	if reporter, ok := builder.(EmptyINReporter); ok {
+		reporter.ReportEmptyIN(column)
+	}
+}
+
+// addColumnVar adds the vars of the column with the builder, tracks the column if the builder is a ColumnVarAdder
+func addColumnVar(builder Builder, column interface{}, vars ...interface{}) {
+	if adder, ok := builder.(ColumnVarAdder); ok {
+		adder.AddColumnVar(builder, column, vars...)
+	} else {
+		builder.AddVar(builder, vars...)
+	}
+}
+
 // Clause
 type Clause struct {
 	Name                string // WHERE
@@ -81,9 +127,176 @@ type Column struct {
 	Raw   bool
 }
 
+// Col returns the column of name, e.g: gorm.Expr("lower(?) = ?", clause.Col("email"), email)
+func Col(name string) Column {
+	return Column{Name: name}
+}
+
+// ColTable returns the column of name in table
+func ColTable(table, name string) Column {
+	return Column{Table: table, Name: name}
+}
+
+// RawColumn the column or expression written as it is, which isn't quoted even if it looks like an identifier, e.g:
+// the keywords like CURRENT_TIMESTAMP, see ParseQuotingIdentifier
+//
+//	db.Table("events").Select(clause.RawColumn("CURRENT_TIMESTAMP")).Scan(&now)
+type RawColumn string
+
+// Build writes the column as it is
+func (column RawColumn) Build(builder Builder) {
+	builder.WriteString(string(column))
+}
+
+// ParseIdentifier parses name into the column if it is an identifier, which is a plain name, e.g: order, or a name
+// qualified with its tables, e.g: users.name, which are quoted by the dialector, the parts could be quoted with `, "
+// or [], and the quoted parts containing dots are kept quoted, e.g: `legacy.orders`.`order`, so they aren't split,
+// returns false for the expressions, e.g: count(*), users.* or name desc, which should be written as they are
+func ParseIdentifier(name string) (Column, bool) {
+	var (
+		parts []string
+		rest  = strings.TrimSpace(name)
+	)
+
+	for {
+		part, remain, ok := parseIdentifierPart(rest)
+		if !ok {
+			return Column{}, false
+		}
+		parts = append(parts, part)
+
+		if remain == "" {
+			break
+		} else if remain[0] != '.' {
+			return Column{}, false
+		}
+		rest = remain[1:]
+	}
+
+	if len(parts) == 1 {
+		return Column{Name: parts[0]}, true
+	}
+	return Column{Table: strings.Join(parts[:len(parts)-1], "."), Name: parts[len(parts)-1]}, true
+}
+
+// reservedWords the keywords reserved by the databases, which must be quoted when they're used as names, the keywords
+// used as values, e.g: CURRENT_TIMESTAMP, NULL, aren't included as they aren't names
+var reservedWords = map[string]bool{
+	"ADD": true, "ALL": true, "ALTER": true, "AND": true, "ANY": true, "AS": true, "ASC": true, "BETWEEN": true,
+	"BY": true, "CASE": true, "CAST": true, "CHECK": true, "COLLATE": true, "COLUMN": true, "CONSTRAINT": true,
+	"CREATE": true, "CROSS": true, "DELETE": true, "DESC": true, "DISTINCT": true, "DROP": true, "ELSE": true,
+	"END": true, "EXCEPT": true, "EXISTS": true, "FETCH": true, "FOR": true, "FOREIGN": true, "FROM": true,
+	"FULL": true, "GRANT": true, "GROUP": true, "GROUPS": true, "HAVING": true, "IN": true, "INDEX": true,
+	"INNER": true, "INSERT": true, "INTERSECT": true, "INTERVAL": true, "INTO": true, "IS": true, "JOIN": true,
+	"KEY": true, "KEYS": true, "LEADING": true, "LEFT": true, "LIKE": true, "LIMIT": true, "NATURAL": true,
+	"NOT": true, "OFFSET": true, "ON": true, "OR": true, "ORDER": true, "OUTER": true, "OVER": true,
+	"PARTITION": true, "PRIMARY": true, "RANGE": true, "RANK": true, "REFERENCES": true, "RIGHT": true, "ROW": true,
+	"ROWS": true, "SELECT": true, "SET": true, "TABLE": true, "THEN": true, "TO": true, "TRAILING": true,
+	"UNION": true, "UNIQUE": true, "UPDATE": true, "USING": true, "VALUES": true, "WHEN": true, "WHERE": true,
+	"WINDOW": true, "WITH": true,
+}
+
+// ParseQuotingIdentifier parses name like ParseIdentifier, but only returns the identifiers which must be quoted, which
+// are reserved words or quoted by the users, e.g: order, `legacy.orders`.id, the other names should be written as they
+// are, so they're folded by the databases like the names of raw SQL, e.g: Name of postgres
+func ParseQuotingIdentifier(name string) (Column, bool) {
+	column, ok := ParseIdentifier(name)
+	if !ok {
+		return Column{}, false
+	} else if strings.ContainsAny(name, "`\"[") {
+		return column, true
+	}
+
+	if reservedWords[strings.ToUpper(column.Name)] {
+		return column, true
+	}
+	for _, part := range strings.Split(column.Table, ".") {
+		if reservedWords[strings.ToUpper(part)] {
+			return column, true
+		}
+	}
+	return Column{}, false
+}
+
+// parseIdentifierPart parses the leading part of the identifier, returns the part and the remaining string
+func parseIdentifierPart(str string) (part string, remain string, ok bool) {
+	if str == "" {
+		return "", "", false
+	}
+
+	switch open := str[0]; open {
+	case '`', '"', '[':
+		closing := open
+		if open == '[' {
+			closing = ']'
+		}
+
+		var builder strings.Builder
+		for idx := 1; idx < len(str); idx++ {
+			if str[idx] != closing {
+				builder.WriteByte(str[idx])
+			} else if idx+1 < len(str) && str[idx+1] == closing && closing != ']' {
+				// the escaped quote, e.g: ``
+				builder.WriteByte(closing)
+				idx++
+			} else {
+				if part = builder.String(); part == "" {
+					return "", "", false
+				} else if strings.Contains(part, ".") {
+					part = str[:idx+1]
+				}
+				return part, str[idx+1:], true
+			}
+		}
+		return "", "", false
+	}
+
+	end := strings.IndexFunc(str, func(r rune) bool {
+		return r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if end == -1 {
+		end = len(str)
+	}
+
+	// the names start with a letter or _, e.g: 1 and $1 aren't identifiers
+	if first := []rune(str)[0]; end == 0 || (first != '_' && !unicode.IsLetter(first)) {
+		return "", "", false
+	}
+	return str[:end], str[end:], true
+}
+
 // Table quote with name
 type Table struct {
 	Name  string
 	Alias string
 	Raw   bool
 }
+
+// FunctionTable the table-valued function used as a table, e.g: generate_series, the args are added as vars, and the
+// alias is quoted, e.g:
+//
+//	db.Table(clause.FunctionTable{Name: "search_products", Args: []interface{}{query}, Alias: "p"}).Find(&products)
+//	// SELECT * FROM search_products($1) AS "p"
+type FunctionTable struct {
+	Name  string
+	Args  []interface{}
+	Alias string
+}
+
+// Build build function table
+func (table FunctionTable) Build(builder Builder) {
+	builder.WriteString(table.Name)
+	builder.WriteByte('(')
+	for idx, arg := range table.Args {
+		if idx > 0 {
+			builder.WriteByte(',')
+		}
+		builder.AddVar(builder, arg)
+	}
+	builder.WriteByte(')')
+
+	if table.Alias != "" {
+		builder.WriteString(" AS ")
+		builder.WriteQuoted(table.Alias)
+	}
+}
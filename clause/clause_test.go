@@ -41,3 +41,66 @@ func checkBuildClauses(t *testing.T, clauses []clause.Interface, result string,
 		t.Errorf("Vars expects %+v got %v", stmt.Vars, vars)
 	}
 }
+
+func TestParseIdentifier(t *testing.T) {
+	results := []struct {
+		Name   string
+		Column clause.Column
+		OK     bool
+	}{
+		{"order", clause.Column{Name: "order"}, true},
+		{" users.name ", clause.Column{Table: "users", Name: "name"}, true},
+		{"public.users.name", clause.Column{Table: "public.users", Name: "name"}, true},
+		{"`order`", clause.Column{Name: "order"}, true},
+		{`"users"."group"`, clause.Column{Table: "users", Name: "group"}, true},
+		{"[users].[order]", clause.Column{Table: "users", Name: "order"}, true},
+		{"`legacy.orders`.`order`", clause.Column{Table: "`legacy.orders`", Name: "order"}, true},
+		{"`a``b`", clause.Column{Name: "a`b"}, true},
+		{"_id$", clause.Column{Name: "_id$"}, true},
+		{"", clause.Column{}, false},
+		{"*", clause.Column{}, false},
+		{"users.*", clause.Column{}, false},
+		{"1", clause.Column{}, false},
+		{"$1", clause.Column{}, false},
+		{"@name", clause.Column{}, false},
+		{"name desc", clause.Column{}, false},
+		{"count(*)", clause.Column{}, false},
+		{"users.", clause.Column{}, false},
+		{"``", clause.Column{}, false},
+		{"`order", clause.Column{}, false},
+	}
+
+	for _, result := range results {
+		column, ok := clause.ParseIdentifier(result.Name)
+		if ok != result.OK || !reflect.DeepEqual(column, result.Column) {
+			t.Errorf("%q should be parsed to %#v, %v, got %#v, %v", result.Name, result.Column, result.OK, column, ok)
+		}
+	}
+}
+
+func TestParseQuotingIdentifier(t *testing.T) {
+	results := []struct {
+		Name   string
+		Column clause.Column
+		OK     bool
+	}{
+		{"order", clause.Column{Name: "order"}, true},
+		{"GROUP", clause.Column{Name: "GROUP"}, true},
+		{"orders.key", clause.Column{Table: "orders", Name: "key"}, true},
+		{"order.id", clause.Column{Table: "order", Name: "id"}, true},
+		{"`name`", clause.Column{Name: "name"}, true},
+		{"`legacy.orders`.id", clause.Column{Table: "`legacy.orders`", Name: "id"}, true},
+		{"name", clause.Column{}, false},
+		{"Name", clause.Column{}, false},
+		{"users.name", clause.Column{}, false},
+		{"CURRENT_TIMESTAMP", clause.Column{}, false},
+		{"order desc", clause.Column{}, false},
+	}
+
+	for _, result := range results {
+		column, ok := clause.ParseQuotingIdentifier(result.Name)
+		if ok != result.OK || !reflect.DeepEqual(column, result.Column) {
+			t.Errorf("%q should be parsed to %#v, %v, got %#v, %v", result.Name, result.Column, result.OK, column, ok)
+		}
+	}
+}
@@ -5,8 +5,16 @@ import (
 	"database/sql/driver"
 	"go/ast"
 	"reflect"
+	"regexp"
+	"strings"
 )
 
+// nilNamedComparisonRegexp matches the comparisons of named vars, the character before = isn't a part of operators
+var nilNamedComparisonRegexp = regexp.MustCompile(`(^|[^<>!:=])\s*(=|<>|!=)\s*@(\w+)\b`)
+
+// emptyINRegexp matches the column and the IN operator before the placeholder, e.g: `id NOT IN (`
+var emptyINRegexp = regexp.MustCompile("(?i)([\\w.`\"\\[\\]]+)\\s+(NOT\\s+)?IN\\s*(\\()?\\s*$")
+
 // Expression expression interface
 type Expression interface {
 	Build(builder Builder)
@@ -24,16 +32,38 @@ type Expr struct {
 	WithoutParentheses bool
 }
 
-// Build build raw expression
+// Build build raw expression, the columns and tables of vars are written as quoted identifiers, and `??` is written as
+// a literal question mark when the SQL has more placeholders than vars, e.g: for the JSON operators of Postgres
 func (expr Expr) Build(builder Builder) {
 	var (
 		afterParenthesis bool
 		idx              int
+		escaped          = escapesQuestionMark(expr.SQL, len(expr.Vars))
+		emptyINs         = expr.emptyINs(escaped)
 	)
 
-	for _, v := range []byte(expr.SQL) {
-		if v == '?' && len(expr.Vars) > idx {
-			if afterParenthesis || expr.WithoutParentheses {
+	for i := 0; i < len(expr.SQL); i++ {
+		v := expr.SQL[i]
+		if in, ok := emptyINs[i]; ok {
+			// the IN conditions of the empty slices are written like IN without values, e.g: `1 = 0`
+			reportEmptyIN(builder, nil)
+			if in.not {
+				builder.WriteString(in.column)
+				builder.WriteString(" IS NOT NULL")
+			} else {
+				builder.WriteString("1 = 0")
+			}
+			afterParenthesis = false
+			i = in.end - 1
+			idx++
+		} else if escaped && v == '?' && i+1 < len(expr.SQL) && expr.SQL[i+1] == '?' {
+			afterParenthesis = false
+			builder.WriteByte('?')
+			i++
+		} else if v == '?' && len(expr.Vars) > idx {
+			if field, ok := identifierVar(expr.Vars[idx]); ok {
+				builder.WriteQuoted(field)
+			} else if afterParenthesis || expr.WithoutParentheses {
 				if _, ok := expr.Vars[idx].(driver.Valuer); ok {
 					builder.AddVar(builder, expr.Vars[idx])
 				} else {
@@ -75,62 +105,117 @@ func (expr Expr) Build(builder Builder) {
 	}
 }
 
+// emptyIN the IN condition of an empty slice in the SQL of Expr
+type emptyIN struct {
+	column string
+	not    bool
+	end    int
+}
+
+// emptyINs returns the IN conditions of the empty slices by their start positions in the SQL, the conditions without
+// a column before them, e.g: `LOWER(name) IN ?`, are kept
+func (expr Expr) emptyINs(escaped bool) map[int]emptyIN {
+	var emptyINs map[int]emptyIN
+	for i, idx := 0, 0; i < len(expr.SQL) && idx < len(expr.Vars); i++ {
+		if escaped && expr.SQL[i] == '?' && i+1 < len(expr.SQL) && expr.SQL[i+1] == '?' {
+			i++
+			continue
+		} else if expr.SQL[i] != '?' {
+			continue
+		}
+
+		v := expr.Vars[idx]
+		idx++
+		if _, ok := v.(driver.Valuer); ok {
+			continue
+		}
+
+		if rv := reflect.ValueOf(v); (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Len() > 0 ||
+			rv.Type().Elem().Kind() == reflect.Uint8 {
+			continue
+		}
+
+		matches := emptyINRegexp.FindStringSubmatchIndex(expr.SQL[:i])
+		if matches == nil {
+			continue
+		}
+
+		in := emptyIN{column: expr.SQL[matches[2]:matches[3]], not: matches[4] >= 0, end: i + 1}
+		if matches[6] >= 0 {
+			// skip the closing parenthesis of `IN (?)`
+			end := strings.IndexByte(expr.SQL[in.end:], ')')
+			if end < 0 || strings.TrimSpace(expr.SQL[in.end:in.end+end]) != "" {
+				continue
+			}
+			in.end += end + 1
+		}
+
+		if emptyINs == nil {
+			emptyINs = map[int]emptyIN{}
+		}
+		emptyINs[matches[2]] = in
+	}
+	return emptyINs
+}
+
+// escapesQuestionMark reports whether `??` of sql is an escaped question mark, which are two placeholders if the vars
+// fill all the placeholders, e.g: `REFERENCES ??` with the table and the columns
+func escapesQuestionMark(sql string, vars int) bool {
+	return strings.Contains(sql, "??") && strings.Count(sql, "?") > vars
+}
+
+// identifierVar returns the column or table of the var, which is written as a quoted identifier instead of a bind var
+func identifierVar(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case Column, Table:
+		return v, true
+	case *Column:
+		if v != nil {
+			return *v, true
+		}
+	case *Table:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return nil, false
+}
+
 // NamedExpr raw expression for named expr
 type NamedExpr struct {
 	SQL  string
 	Vars []interface{}
 }
 
-// Build build raw expression
+// Build build raw expression, see Expr.Build
 func (expr NamedExpr) Build(builder Builder) {
 	var (
 		idx              int
 		inName           bool
 		afterParenthesis bool
-		namedMap         = make(map[string]interface{}, len(expr.Vars))
+		escaped          = escapesQuestionMark(expr.SQL, len(expr.Vars))
+		namedMap         = namedVars(expr.Vars)
 	)
 
-	for _, v := range expr.Vars {
-		switch value := v.(type) {
-		case sql.NamedArg:
-			namedMap[value.Name] = value.Value
-		case map[string]interface{}:
-			for k, v := range value {
-				namedMap[k] = v
-			}
-		default:
-			var appendFieldsToMap func(reflect.Value)
-			appendFieldsToMap = func(reflectValue reflect.Value) {
-				reflectValue = reflect.Indirect(reflectValue)
-				switch reflectValue.Kind() {
-				case reflect.Struct:
-					modelType := reflectValue.Type()
-					for i := 0; i < modelType.NumField(); i++ {
-						if fieldStruct := modelType.Field(i); ast.IsExported(fieldStruct.Name) {
-							namedMap[fieldStruct.Name] = reflectValue.Field(i).Interface()
-
-							if fieldStruct.Anonymous {
-								appendFieldsToMap(reflectValue.Field(i))
-							}
-						}
-					}
-				}
-			}
-
-			appendFieldsToMap(reflect.ValueOf(value))
-		}
-	}
-
 	name := make([]byte, 0, 10)
 
-	for _, v := range []byte(expr.SQL) {
-		if v == '@' && !inName {
+	for i := 0; i < len(expr.SQL); i++ {
+		v := expr.SQL[i]
+		if escaped && v == '?' && !inName && i+1 < len(expr.SQL) && expr.SQL[i+1] == '?' {
+			afterParenthesis = false
+			builder.WriteByte('?')
+			i++
+		} else if v == '@' && !inName {
 			inName = true
 			name = name[:0]
 		} else if v == ' ' || v == ',' || v == ')' || v == '"' || v == '\'' || v == '`' || v == '\r' || v == '\n' || v == ';' {
 			if inName {
 				if nv, ok := namedMap[string(name)]; ok {
-					builder.AddVar(builder, nv)
+					if field, ok := identifierVar(nv); ok {
+						builder.WriteQuoted(field)
+					} else {
+						builder.AddVar(builder, nv)
+					}
 				} else {
 					builder.WriteByte('@')
 					builder.WriteString(string(name))
@@ -141,7 +226,9 @@ func (expr NamedExpr) Build(builder Builder) {
 			afterParenthesis = false
 			builder.WriteByte(v)
 		} else if v == '?' && len(expr.Vars) > idx {
-			if afterParenthesis {
+			if field, ok := identifierVar(expr.Vars[idx]); ok {
+				builder.WriteQuoted(field)
+			} else if afterParenthesis {
 				if _, ok := expr.Vars[idx].(driver.Valuer); ok {
 					builder.AddVar(builder, expr.Vars[idx])
 				} else {
@@ -180,7 +267,11 @@ func (expr NamedExpr) Build(builder Builder) {
 
 	if inName {
 		if nv, ok := namedMap[string(name)]; ok {
-			builder.AddVar(builder, nv)
+			if field, ok := identifierVar(nv); ok {
+				builder.WriteQuoted(field)
+			} else {
+				builder.AddVar(builder, nv)
+			}
 		} else {
 			builder.WriteByte('@')
 			builder.WriteString(string(name))
@@ -188,29 +279,78 @@ func (expr NamedExpr) Build(builder Builder) {
 	}
 }
 
+// namedVars returns the values of the named vars, the fields of the structs are named by their names
+func namedVars(vars []interface{}) map[string]interface{} {
+	namedMap := make(map[string]interface{}, len(vars))
+	for _, v := range vars {
+		switch value := v.(type) {
+		case sql.NamedArg:
+			namedMap[value.Name] = value.Value
+		case map[string]interface{}:
+			for k, v := range value {
+				namedMap[k] = v
+			}
+		default:
+			var appendFieldsToMap func(reflect.Value)
+			appendFieldsToMap = func(reflectValue reflect.Value) {
+				reflectValue = reflect.Indirect(reflectValue)
+				switch reflectValue.Kind() {
+				case reflect.Struct:
+					modelType := reflectValue.Type()
+					for i := 0; i < modelType.NumField(); i++ {
+						if fieldStruct := modelType.Field(i); ast.IsExported(fieldStruct.Name) {
+							namedMap[fieldStruct.Name] = reflectValue.Field(i).Interface()
+
+							if fieldStruct.Anonymous {
+								appendFieldsToMap(reflectValue.Field(i))
+							}
+						}
+					}
+				}
+			}
+
+			appendFieldsToMap(reflect.ValueOf(value))
+		}
+	}
+
+	return namedMap
+}
+
+// NilAsNull the named expression whose comparisons of the named vars with nil values, e.g: `name = @name`, are written
+// as IS NULL or IS NOT NULL, which never match with = or <>, see Config.NilAsNull
+type NilAsNull NamedExpr
+
+func (expr NilAsNull) Build(builder Builder) {
+	NamedExpr{SQL: nilNamedComparisons(expr.SQL, namedVars(expr.Vars)), Vars: expr.Vars}.Build(builder)
+}
+
 // IN Whether a value is within a set of values
 type IN struct {
 	Column interface{}
 	Values []interface{}
 }
 
+// Build writes the IN condition, which is written as `1 = 0` matching nothing without values
 func (in IN) Build(builder Builder) {
-	builder.WriteQuoted(in.Column)
+	if len(in.Values) == 0 {
+		reportEmptyIN(builder, in.Column)
+		builder.WriteString("1 = 0")
+		return
+	}
 
+	builder.WriteQuoted(in.Column)
 	switch len(in.Values) {
-	case 0:
-		builder.WriteString(" IN (NULL)")
 	case 1:
 		if _, ok := in.Values[0].([]interface{}); !ok {
 			builder.WriteString(" = ")
-			builder.AddVar(builder, in.Values[0])
+			addColumnVar(builder, in.Column, in.Values[0])
 			break
 		}
 
 		fallthrough
 	default:
 		builder.WriteString(" IN (")
-		builder.AddVar(builder, in.Values...)
+		addColumnVar(builder, in.Column, in.Values...)
 		builder.WriteByte(')')
 	}
 }
@@ -219,18 +359,19 @@ func (in IN) NegationBuild(builder Builder) {
 	builder.WriteQuoted(in.Column)
 	switch len(in.Values) {
 	case 0:
+		reportEmptyIN(builder, in.Column)
 		builder.WriteString(" IS NOT NULL")
 	case 1:
 		if _, ok := in.Values[0].([]interface{}); !ok {
 			builder.WriteString(" <> ")
-			builder.AddVar(builder, in.Values[0])
+			addColumnVar(builder, in.Column, in.Values[0])
 			break
 		}
 
 		fallthrough
 	default:
 		builder.WriteString(" NOT IN (")
-		builder.AddVar(builder, in.Values...)
+		addColumnVar(builder, in.Column, in.Values...)
 		builder.WriteByte(')')
 	}
 }
@@ -242,29 +383,31 @@ type Eq struct {
 }
 
 func (eq Eq) Build(builder Builder) {
-	builder.WriteQuoted(eq.Column)
-
 	switch eq.Value.(type) {
 	case []string, []int, []int32, []int64, []uint, []uint32, []uint64, []interface{}:
 		rv := reflect.ValueOf(eq.Value)
 		if rv.Len() == 0 {
-			builder.WriteString(" IN (NULL)")
-		} else {
-			builder.WriteString(" IN (")
-			for i := 0; i < rv.Len(); i++ {
-				if i > 0 {
-					builder.WriteByte(',')
-				}
-				builder.AddVar(builder, rv.Index(i).Interface())
+			reportEmptyIN(builder, eq.Column)
+			builder.WriteString("1 = 0")
+			return
+		}
+
+		builder.WriteQuoted(eq.Column)
+		builder.WriteString(" IN (")
+		for i := 0; i < rv.Len(); i++ {
+			if i > 0 {
+				builder.WriteByte(',')
 			}
-			builder.WriteByte(')')
+			addColumnVar(builder, eq.Column, rv.Index(i).Interface())
 		}
+		builder.WriteByte(')')
 	default:
+		builder.WriteQuoted(eq.Column)
 		if eqNil(eq.Value) {
 			builder.WriteString(" IS NULL")
 		} else {
 			builder.WriteString(" = ")
-			builder.AddVar(builder, eq.Value)
+			addColumnVar(builder, eq.Column, eq.Value)
 		}
 	}
 }
@@ -281,13 +424,19 @@ func (neq Neq) Build(builder Builder) {
 
 	switch neq.Value.(type) {
 	case []string, []int, []int32, []int64, []uint, []uint32, []uint64, []interface{}:
-		builder.WriteString(" NOT IN (")
 		rv := reflect.ValueOf(neq.Value)
+		if rv.Len() == 0 {
+			reportEmptyIN(builder, neq.Column)
+			builder.WriteString(" IS NOT NULL")
+			break
+		}
+
+		builder.WriteString(" NOT IN (")
 		for i := 0; i < rv.Len(); i++ {
 			if i > 0 {
 				builder.WriteByte(',')
 			}
-			builder.AddVar(builder, rv.Index(i).Interface())
+			addColumnVar(builder, neq.Column, rv.Index(i).Interface())
 		}
 		builder.WriteByte(')')
 	default:
@@ -295,7 +444,7 @@ func (neq Neq) Build(builder Builder) {
 			builder.WriteString(" IS NOT NULL")
 		} else {
 			builder.WriteString(" <> ")
-			builder.AddVar(builder, neq.Value)
+			addColumnVar(builder, neq.Column, neq.Value)
 		}
 	}
 }
@@ -304,13 +453,113 @@ func (neq Neq) NegationBuild(builder Builder) {
 	Eq(neq).Build(builder)
 }
 
+// EqNullSafe equal to for where, which matches when both sides are NULL, written as `<=>` on MySQL, `IS NOT DISTINCT
+// FROM` on Postgres, `IS` on SQLite and `(a = b OR (a IS NULL AND b IS NULL))` on the others, the dialect is known by
+// the builders implementing DialectNamer, e.g: gorm.Statement
+//
+//	db.Where(clause.EqNullSafe{Column: "manager_id", Value: clause.Column{Table: "teams", Name: "manager_id"}})
+type EqNullSafe Eq
+
+func (eq EqNullSafe) Build(builder Builder) {
+	if eqNil(eq.Value) {
+		builder.WriteQuoted(eq.Column)
+		builder.WriteString(" IS NULL")
+		return
+	}
+
+	switch dialectName(builder) {
+	case "mysql":
+		builder.WriteQuoted(eq.Column)
+		builder.WriteString(" <=> ")
+		addColumnVar(builder, eq.Column, eq.Value)
+	case "postgres":
+		builder.WriteQuoted(eq.Column)
+		builder.WriteString(" IS NOT DISTINCT FROM ")
+		addColumnVar(builder, eq.Column, eq.Value)
+	case "sqlite":
+		builder.WriteQuoted(eq.Column)
+		builder.WriteString(" IS ")
+		addColumnVar(builder, eq.Column, eq.Value)
+	default:
+		if !nullableValue(eq.Value) {
+			Eq(eq).Build(builder)
+			return
+		}
+
+		builder.WriteByte('(')
+		builder.WriteQuoted(eq.Column)
+		builder.WriteString(" = ")
+		addColumnVar(builder, eq.Column, eq.Value)
+		builder.WriteString(" OR (")
+		builder.WriteQuoted(eq.Column)
+		builder.WriteString(" IS NULL AND ")
+		addColumnVar(builder, eq.Column, eq.Value)
+		builder.WriteString(" IS NULL))")
+	}
+}
+
+func (eq EqNullSafe) NegationBuild(builder Builder) {
+	NeqNullSafe(eq).Build(builder)
+}
+
+// NeqNullSafe not equal to for where, which matches when only one side is NULL, see EqNullSafe
+type NeqNullSafe Eq
+
+func (neq NeqNullSafe) Build(builder Builder) {
+	if eqNil(neq.Value) {
+		builder.WriteQuoted(neq.Column)
+		builder.WriteString(" IS NOT NULL")
+		return
+	}
+
+	switch dialectName(builder) {
+	case "mysql":
+		builder.WriteString("NOT (")
+		EqNullSafe(neq).Build(builder)
+		builder.WriteByte(')')
+	case "postgres":
+		builder.WriteQuoted(neq.Column)
+		builder.WriteString(" IS DISTINCT FROM ")
+		addColumnVar(builder, neq.Column, neq.Value)
+	case "sqlite":
+		builder.WriteQuoted(neq.Column)
+		builder.WriteString(" IS NOT ")
+		addColumnVar(builder, neq.Column, neq.Value)
+	default:
+		builder.WriteByte('(')
+		builder.WriteQuoted(neq.Column)
+		builder.WriteString(" <> ")
+		addColumnVar(builder, neq.Column, neq.Value)
+		builder.WriteString(" OR ")
+		if !nullableValue(neq.Value) {
+			builder.WriteQuoted(neq.Column)
+			builder.WriteString(" IS NULL)")
+			return
+		}
+
+		builder.WriteString("(")
+		builder.WriteQuoted(neq.Column)
+		builder.WriteString(" IS NULL AND ")
+		addColumnVar(builder, neq.Column, neq.Value)
+		builder.WriteString(" IS NOT NULL) OR (")
+		builder.WriteQuoted(neq.Column)
+		builder.WriteString(" IS NOT NULL AND ")
+		addColumnVar(builder, neq.Column, neq.Value)
+		builder.WriteString(" IS NULL))")
+	}
+}
+
+func (neq NeqNullSafe) NegationBuild(builder Builder) {
+	EqNullSafe(neq).Build(builder)
+}
+
 // Gt greater than for where
 type Gt Eq
 
 func (gt Gt) Build(builder Builder) {
 	builder.WriteQuoted(gt.Column)
 	builder.WriteString(" > ")
-	builder.AddVar(builder, gt.Value)
+	addColumnVar(builder, gt.Column, gt.Value)
 }
 
 func (gt Gt) NegationBuild(builder Builder) {
@@ -323,7 +572,7 @@ type Gte Eq
 func (gte Gte) Build(builder Builder) {
 	builder.WriteQuoted(gte.Column)
 	builder.WriteString(" >= ")
-	builder.AddVar(builder, gte.Value)
+	addColumnVar(builder, gte.Column, gte.Value)
 }
 
 func (gte Gte) NegationBuild(builder Builder) {
@@ -336,7 +585,7 @@ type Lt Eq
 func (lt Lt) Build(builder Builder) {
 	builder.WriteQuoted(lt.Column)
 	builder.WriteString(" < ")
-	builder.AddVar(builder, lt.Value)
+	addColumnVar(builder, lt.Column, lt.Value)
 }
 
 func (lt Lt) NegationBuild(builder Builder) {
@@ -349,7 +598,7 @@ type Lte Eq
 func (lte Lte) Build(builder Builder) {
 	builder.WriteQuoted(lte.Column)
 	builder.WriteString(" <= ")
-	builder.AddVar(builder, lte.Value)
+	addColumnVar(builder, lte.Column, lte.Value)
 }
 
 func (lte Lte) NegationBuild(builder Builder) {
@@ -362,13 +611,57 @@ type Like Eq
 func (like Like) Build(builder Builder) {
 	builder.WriteQuoted(like.Column)
 	builder.WriteString(" LIKE ")
-	builder.AddVar(builder, like.Value)
+	addColumnVar(builder, like.Column, like.Value)
 }
 
 func (like Like) NegationBuild(builder Builder) {
 	builder.WriteQuoted(like.Column)
 	builder.WriteString(" NOT LIKE ")
-	builder.AddVar(builder, like.Value)
+	addColumnVar(builder, like.Column, like.Value)
+}
+
+// Collate the column compared or ordered with the collation, the collation is quoted like identifiers, e.g:
+//
+//	db.Where(clause.Eq{Column: clause.Collate{Column: "email", Collation: "und-x-icu"}, Value: email})
+//	db.Order(clause.Collate{Column: clause.Column{Name: "name"}, Collation: "NOCASE"})
+type Collate struct {
+	Column    interface{}
+	Collation string
+}
+
+func (collate Collate) Build(builder Builder) {
+	builder.WriteQuoted(collate.Column)
+	builder.WriteString(" COLLATE ")
+	builder.WriteQuoted(collate.Collation)
+}
+
+// nullableValue reports whether the value compared could be NULL when executed, e.g: the columns and expressions,
+// the other values are known when built
+func nullableValue(value interface{}) bool {
+	switch value.(type) {
+	case Column, Expression:
+		return true
+	}
+	return false
+}
+
+// nilNamedComparisons rewrites the comparisons of the named vars whose values are nil to IS NULL or IS NOT NULL
+func nilNamedComparisons(sql string, namedMap map[string]interface{}) string {
+	if !strings.Contains(sql, "@") {
+		return sql
+	}
+
+	return nilNamedComparisonRegexp.ReplaceAllStringFunc(sql, func(match string) string {
+		submatches := nilNamedComparisonRegexp.FindStringSubmatch(match)
+		if value, ok := namedMap[submatches[3]]; !ok || !eqNil(value) {
+			return match
+		}
+
+		if submatches[2] == "=" {
+			return submatches[1] + " IS NULL"
+		}
+		return submatches[1] + " IS NOT NULL"
+	})
 }
 
 func eqNil(value interface{}) bool {
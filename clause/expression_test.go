@@ -15,13 +15,41 @@ import (
 
 func TestExpr(t *testing.T) {
 	results := []struct {
-		SQL    string
-		Result string
-		Vars   []interface{}
+		SQL          string
+		Result       string
+		Vars         []interface{}
+		ExpectedVars []interface{}
 	}{{
 		SQL:    "create table ? (? ?, ? ?)",
 		Vars:   []interface{}{clause.Table{Name: "users"}, clause.Column{Name: "id"}, clause.Expr{SQL: "int"}, clause.Column{Name: "name"}, clause.Expr{SQL: "text"}},
 		Result: "create table `users` (`id` int, `name` text)",
+	}, {
+		SQL:          "lower(?) = ?",
+		Vars:         []interface{}{&clause.Column{Name: "email"}, "jinzhu@example.org"},
+		Result:       "lower(`email`) = ?",
+		ExpectedVars: []interface{}{"jinzhu@example.org"},
+	}, {
+		SQL:          "? IN (?)",
+		Vars:         []interface{}{clause.ColTable("users", "name"), clause.Col("role")},
+		Result:       "`users`.`name` IN (`role`)",
+		ExpectedVars: nil,
+	}, {
+		SQL:          "? ?? 'admin' AND ? = ?",
+		Vars:         []interface{}{clause.Col("roles"), clause.Col("name"), "jinzhu"},
+		Result:       "`roles` ? 'admin' AND `name` = ?",
+		ExpectedVars: []interface{}{"jinzhu"},
+	}, {
+		SQL:    "data ?| array['a']",
+		Result: "data ?| array['a']",
+	}, {
+		SQL:    "REFERENCES ??",
+		Vars:   []interface{}{clause.Table{Name: "users"}, clause.Column{Name: "id"}},
+		Result: "REFERENCES `users``id`",
+	}, {
+		SQL:          "SELECT * FROM ?",
+		Vars:         []interface{}{clause.FunctionTable{Name: "json_each", Args: []interface{}{"[1,2]", 1}, Alias: "j"}},
+		Result:       "SELECT * FROM json_each(?,?) AS `j`",
+		ExpectedVars: []interface{}{"[1,2]", 1},
 	}}
 
 	for idx, result := range results {
@@ -32,6 +60,10 @@ func TestExpr(t *testing.T) {
 			if stmt.SQL.String() != result.Result {
 				t.Errorf("generated SQL is not equal, expects %v, but got %v", result.Result, stmt.SQL.String())
 			}
+
+			if !reflect.DeepEqual(result.ExpectedVars, stmt.Vars) {
+				t.Errorf("generated vars is not equal, expects %v, but got %v", result.ExpectedVars, stmt.Vars)
+			}
 		})
 	}
 }
@@ -132,6 +164,11 @@ func TestNamedExpr(t *testing.T) {
 		SQL:    "?",
 		Vars:   []interface{}{clause.Table{Name: "table", Alias: "alias", Raw: true}},
 		Result: "table alias",
+	}, {
+		SQL:          "lower(@column) = @email AND data ?? 'email'",
+		Vars:         []interface{}{map[string]interface{}{"column": clause.Col("email"), "email": "jinzhu@example.org"}},
+		Result:       "lower(`email`) = ? AND data ? 'email'",
+		ExpectedVars: []interface{}{"jinzhu@example.org"},
 	}}
 
 	for idx, result := range results {
@@ -203,7 +240,39 @@ func TestExpression(t *testing.T) {
 		Expressions: []clause.Expression{
 			clause.Eq{Column: column, Value: []string{}},
 		},
-		Result: "`column-name` IN (NULL)",
+		Result: "1 = 0",
+	}, {
+		Expressions: []clause.Expression{
+			clause.Neq{Column: column, Value: []string{}},
+		},
+		Result: "`column-name` IS NOT NULL",
+	}, {
+		Expressions: []clause.Expression{
+			clause.IN{Column: column},
+		},
+		Result: "1 = 0",
+	}, {
+		Expressions: []clause.Expression{
+			clause.Not(clause.IN{Column: column}),
+		},
+		Result: "`column-name` IS NOT NULL",
+	}, {
+		Expressions: []clause.Expression{
+			clause.Expr{SQL: "age > ? AND `users`.`name` IN ?", Vars: []interface{}{18, []string{}}},
+		},
+		Result:       "age > ? AND 1 = 0",
+		ExpectedVars: []interface{}{18},
+	}, {
+		Expressions: []clause.Expression{
+			clause.Expr{SQL: "name not in (?) OR id IN ?", Vars: []interface{}{[]string{}, []int{1}}},
+		},
+		Result:       "name IS NOT NULL OR id IN (?)",
+		ExpectedVars: []interface{}{1},
+	}, {
+		Expressions: []clause.Expression{
+			clause.Expr{SQL: "LOWER(name) IN ?", Vars: []interface{}{[]string{}}},
+		},
+		Result: "LOWER(name) IN (NULL)",
 	}, {
 		Expressions: []clause.Expression{
 			clause.Eq{Column: clause.Expr{SQL: "SUM(?)", Vars: []interface{}{clause.Column{Name: "id"}}}, Value: 100},
@@ -216,6 +285,18 @@ func TestExpression(t *testing.T) {
 		},
 		ExpectedVars: []interface{}{100},
 		Result:       "SUM(`users`.`id`) >= ?",
+	}, {
+		Expressions: []clause.Expression{
+			clause.Eq{Column: clause.Collate{Column: column, Collation: "und-x-icu"}, Value: "column-value"},
+		},
+		ExpectedVars: []interface{}{"column-value"},
+		Result:       "`column-name` COLLATE `und-x-icu` = ?",
+	}, {
+		Expressions: []clause.Expression{
+			clause.Like{Column: clause.Collate{Column: clause.Column{Table: "users", Name: "name"}, Collation: "NOCASE"}, Value: "a%"},
+		},
+		ExpectedVars: []interface{}{"a%"},
+		Result:       "`users`.`name` COLLATE `NOCASE` LIKE ?",
 	}}
 
 	for idx, result := range results {
@@ -235,3 +316,89 @@ func TestExpression(t *testing.T) {
 		}
 	}
 }
+
+func TestNamedExprNilAsNull(t *testing.T) {
+	results := []struct {
+		SQL          string
+		Vars         []interface{}
+		Result       string
+		ExpectedVars []interface{}
+	}{{
+		SQL:          "name = @name AND age <> @age AND role != @role",
+		Vars:         []interface{}{map[string]interface{}{"name": nil, "age": (*int)(nil), "role": sql.NullString{}}},
+		Result:       "name IS NULL AND age IS NOT NULL AND role IS NOT NULL",
+		ExpectedVars: nil,
+	}, {
+		SQL:          "(@name IS NULL OR name = @name) AND age >= @age AND age <= @age",
+		Vars:         []interface{}{sql.Named("name", nil), sql.Named("age", nil)},
+		Result:       "(? IS NULL OR name IS NULL) AND age >= ? AND age <= ?",
+		ExpectedVars: []interface{}{nil, nil, nil},
+	}, {
+		SQL:          "name=@name AND age = @age",
+		Vars:         []interface{}{map[string]interface{}{"name": nil, "age": 18}},
+		Result:       "name IS NULL AND age = ?",
+		ExpectedVars: []interface{}{18},
+	}}
+
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			stmt := &gorm.Statement{DB: db, Clauses: map[string]clause.Clause{}}
+			clause.NilAsNull{SQL: result.SQL, Vars: result.Vars}.Build(stmt)
+			if stmt.SQL.String() != result.Result {
+				t.Errorf("generated SQL is not equal, expects %v, but got %v", result.Result, stmt.SQL.String())
+			}
+
+			if !reflect.DeepEqual(result.ExpectedVars, stmt.Vars) {
+				t.Errorf("generated vars is not equal, expects %v, but got %v", result.ExpectedVars, stmt.Vars)
+			}
+		})
+	}
+}
+
+// dialectStatement the statement of the dialect named name
+type dialectStatement struct {
+	*gorm.Statement
+	name string
+}
+
+func (stmt dialectStatement) DialectName() string {
+	return stmt.name
+}
+
+func TestEqNullSafe(t *testing.T) {
+	column := clause.Column{Table: "users", Name: "manager_id"}
+	other := clause.Column{Table: "teams", Name: "manager_id"}
+	results := []struct {
+		Dialect      string
+		Expression   clause.Expression
+		Result       string
+		ExpectedVars []interface{}
+	}{
+		{"mysql", clause.EqNullSafe{Column: column, Value: other}, "`users`.`manager_id` <=> `teams`.`manager_id`", nil},
+		{"mysql", clause.NeqNullSafe{Column: column, Value: 1}, "NOT (`users`.`manager_id` <=> ?)", []interface{}{1}},
+		{"postgres", clause.EqNullSafe{Column: column, Value: 1}, "`users`.`manager_id` IS NOT DISTINCT FROM ?", []interface{}{1}},
+		{"postgres", clause.NeqNullSafe{Column: column, Value: other}, "`users`.`manager_id` IS DISTINCT FROM `teams`.`manager_id`", nil},
+		{"sqlite", clause.EqNullSafe{Column: column, Value: other}, "`users`.`manager_id` IS `teams`.`manager_id`", nil},
+		{"sqlite", clause.Not(clause.EqNullSafe{Column: column, Value: 1}), "`users`.`manager_id` IS NOT ?", []interface{}{1}},
+		{"sqlserver", clause.EqNullSafe{Column: column, Value: 1}, "`users`.`manager_id` = ?", []interface{}{1}},
+		{"sqlserver", clause.EqNullSafe{Column: column, Value: other}, "(`users`.`manager_id` = `teams`.`manager_id` OR (`users`.`manager_id` IS NULL AND `teams`.`manager_id` IS NULL))", nil},
+		{"sqlserver", clause.NeqNullSafe{Column: column, Value: 1}, "(`users`.`manager_id` <> ? OR `users`.`manager_id` IS NULL)", []interface{}{1}},
+		{"sqlserver", clause.NeqNullSafe{Column: column, Value: other}, "(`users`.`manager_id` <> `teams`.`manager_id` OR (`users`.`manager_id` IS NULL AND `teams`.`manager_id` IS NOT NULL) OR (`users`.`manager_id` IS NOT NULL AND `teams`.`manager_id` IS NULL))", nil},
+		{"mysql", clause.EqNullSafe{Column: column, Value: (*int)(nil)}, "`users`.`manager_id` IS NULL", nil},
+		{"postgres", clause.NeqNullSafe{Column: column, Value: nil}, "`users`.`manager_id` IS NOT NULL", nil},
+	}
+
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			stmt := dialectStatement{Statement: &gorm.Statement{DB: db, Clauses: map[string]clause.Clause{}}, name: result.Dialect}
+			result.Expression.Build(stmt)
+			if stmt.SQL.String() != result.Result {
+				t.Errorf("generated SQL is not equal, expects %v, but got %v", result.Result, stmt.SQL.String())
+			}
+
+			if !reflect.DeepEqual(result.ExpectedVars, stmt.Vars) {
+				t.Errorf("generated vars is not equal, expects %v, but got %v", result.ExpectedVars, stmt.Vars)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ type OrderByColumn struct {
 	Column  Column
 	Desc    bool
 	Reorder bool
+	Collate string // ordered with the collation, e.g: ORDER BY name COLLATE NOCASE
 }
 
 type OrderBy struct {
@@ -27,6 +28,10 @@ func (orderBy OrderBy) Build(builder Builder) {
 			}
 
 			builder.WriteQuoted(column.Column)
+			if column.Collate != "" {
+				builder.WriteString(" COLLATE ")
+				builder.WriteQuoted(column.Collate)
+			}
 			if column.Desc {
 				builder.WriteString(" DESC")
 			}
@@ -48,6 +48,14 @@ func TestOrderBy(t *testing.T) {
 			"SELECT * FROM `users` ORDER BY FIELD(id, ?,?,?)",
 			[]interface{}{1, 2, 3},
 		},
+		{
+			[]clause.Interface{
+				clause.Select{}, clause.From{}, clause.OrderBy{
+					Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "name"}, Collate: "NOCASE", Desc: true}, {Column: clause.PrimaryColumn}},
+				},
+			},
+			"SELECT * FROM `users` ORDER BY `name` COLLATE `NOCASE` DESC,`users`.`id`", nil,
+		},
 	}
 
 	for idx, result := range results {
@@ -21,7 +21,7 @@ func (set Set) Build(builder Builder) {
 			}
 			builder.WriteQuoted(assignment.Column)
 			builder.WriteByte('=')
-			builder.AddVar(builder, assignment.Value)
+			addColumnVar(builder, assignment.Column, assignment.Value)
 		}
 	} else {
 		builder.WriteQuoted(Column{Name: PrimaryKey})
@@ -37,6 +37,7 @@ func (set Set) MergeClause(clause *Clause) {
 	clause.Expression = Set(copiedAssignments)
 }
 
+// Assignments returns the assignments of the columns to the values, which are sorted by the columns
 func Assignments(values map[string]interface{}) Set {
 	keys := make([]string, 0, len(values))
 	for key := range values {
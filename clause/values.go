@@ -30,7 +30,16 @@ func (values Values) Build(builder Builder) {
 			}
 
 			builder.WriteByte('(')
-			builder.AddVar(builder, value...)
+			for i, v := range value {
+				if i > 0 {
+					builder.WriteByte(',')
+				}
+				if i < len(values.Columns) {
+					addColumnVar(builder, values.Columns[i], v)
+				} else {
+					builder.AddVar(builder, v)
+				}
+			}
 			builder.WriteByte(')')
 		}
 	} else {
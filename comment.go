@@ -0,0 +1,92 @@
+package gorm
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SQLComment returns the comment of the statement appended to the executed SQL, which includes Config.QueryComment
+// and the tags of Config.CommentProvider serialized in the sqlcommenter format, e.g:
+//
+//	/*nightly report*/ /*route='%2Fusers',traceparent='00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01'*/
+func (stmt *Statement) SQLComment() string {
+	var builder strings.Builder
+	if comment := stmt.DB.QueryComment; comment != "" {
+		builder.WriteString("/*")
+		builder.WriteString(strings.ReplaceAll(comment, "*/", "* /"))
+		builder.WriteString("*/")
+	}
+
+	if stmt.DB.CommentProvider != nil {
+		if tags := stmt.DB.CommentProvider(stmt.Context, stmt); len(tags) > 0 {
+			keys := make([]string, 0, len(tags))
+			for key := range tags {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			if builder.Len() > 0 {
+				builder.WriteByte(' ')
+			}
+			builder.WriteString("/*")
+			for idx, key := range keys {
+				if idx > 0 {
+					builder.WriteByte(',')
+				}
+				builder.WriteString(commentEscape(key))
+				builder.WriteString("='")
+				builder.WriteString(strings.ReplaceAll(commentEscape(tags[key]), "'", "\\'"))
+				builder.WriteByte('\'')
+			}
+			builder.WriteString("*/")
+		}
+	}
+	return builder.String()
+}
+
+// ExecSQL returns the SQL executed for the statement, which is the generated SQL with SQLComment appended, the comment
+// is logged with the SQL as well
+func (stmt *Statement) ExecSQL() string {
+	if stmt.DB.QueryComment == "" && stmt.DB.CommentProvider == nil {
+		return stmt.SQL.String()
+	}
+
+	if stmt.sqlComment = stmt.SQLComment(); stmt.sqlComment != "" {
+		return stmt.SQL.String() + " " + stmt.sqlComment
+	}
+	return stmt.SQL.String()
+}
+
+// sqlCommentKey the context key of the comment of the SQL executed with prepared statements, which is trimmed from
+// the SQL to cache the statements, see Config.PreparedStmtCacheWithoutComment
+type sqlCommentKey struct{}
+
+// connContext returns the context executing the SQL of the statement with its ConnPool, which carries the comment of
+// the SQL to the prepared statements if Config.PreparedStmtCacheWithoutComment is enabled
+func (stmt *Statement) connContext(ctx context.Context) context.Context {
+	if stmt.sqlComment == "" || !stmt.DB.PreparedStmtCacheWithoutComment {
+		return ctx
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, sqlCommentKey{}, stmt.sqlComment)
+}
+
+// preparedStmtKey returns the key caching the prepared statement of query, which is query without the comment of ctx
+func preparedStmtKey(ctx context.Context, query string) string {
+	if ctx != nil {
+		if comment, ok := ctx.Value(sqlCommentKey{}).(string); ok {
+			return strings.TrimSuffix(query, " "+comment)
+		}
+	}
+	return query
+}
+
+// commentEscape url encodes the keys and values of the sqlcommenter tags
+func commentEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,122 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// pinnedConn a connection acquired by DB.AcquireConn, every statement of the derived DB runs on it until released
+type pinnedConn struct {
+	*sql.Conn
+	released     int32
+	once         sync.Once
+	mux          sync.Mutex
+	preparedStmt *PreparedStmtDB
+	logger       logger.Interface
+	location     string
+}
+
+func (c *pinnedConn) isReleased() bool {
+	return atomic.LoadInt32(&c.released) == 1
+}
+
+// release closes the prepared statements of the connection and returns it to the pool, it's safe to call it repeatedly
+func (c *pinnedConn) release() (err error) {
+	c.once.Do(func() {
+		atomic.StoreInt32(&c.released, 1)
+
+		c.mux.Lock()
+		preparedStmt := c.preparedStmt
+		c.preparedStmt = nil
+		c.mux.Unlock()
+		if preparedStmt != nil {
+			preparedStmt.Close()
+		}
+
+		err = c.Conn.Close()
+	})
+	return
+}
+
+// pinnedConnRef the reference of the pinned connection held by the Config of the DB derived by AcquireConn, the
+// connection and its prepared statements don't reference it, so the finalizer of it runs once the DB is unreachable
+type pinnedConnRef struct {
+	*pinnedConn
+}
+
+func (r *pinnedConnRef) release() error {
+	runtime.SetFinalizer(r, nil)
+	return r.pinnedConn.release()
+}
+
+// finalize is the safety net releasing connections dropped without calling release
+func (r *pinnedConnRef) finalize() {
+	r.logger.Error(context.Background(), "connection acquired by AcquireConn at %s was never released, releasing it now", r.location)
+	_ = r.pinnedConn.release()
+}
+
+// preparedStmtDB returns the PreparedStmtDB preparing statements on the connection with the cache settings of root,
+// it isn't registered to the partitions of root, which would keep the connection alive, it's closed on release
+func (c *pinnedConn) preparedStmtDB(root *PreparedStmtDB) *PreparedStmtDB {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.preparedStmt == nil {
+		var (
+			maxSize int
+			ttl     time.Duration
+		)
+		if root.cache != nil {
+			maxSize, ttl = root.cache.maxSize, root.cache.ttl
+		}
+		c.preparedStmt = NewPreparedStmtDBWithCache(c, maxSize, ttl)
+		c.preparedStmt.partitions = nil
+	}
+	return c.preparedStmt
+}
+
+func (c *pinnedConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	if c.isReleased() {
+		return nil, ErrConnReleased
+	}
+	return c.Conn.PrepareContext(ctx, query)
+}
+
+func (c *pinnedConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if c.isReleased() {
+		return nil, ErrConnReleased
+	}
+	return c.Conn.ExecContext(ctx, query, args...)
+}
+
+func (c *pinnedConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if c.isReleased() {
+		return nil, ErrConnReleased
+	}
+	return c.Conn.QueryContext(ctx, query, args...)
+}
+
+func (c *pinnedConn) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	if c.isReleased() {
+		return nil, ErrConnReleased
+	}
+	return c.Conn.BeginTx(ctx, opts)
+}
+
+// isConnReleased reports whether connPool is, or prepares statements on, a released pinned connection
+func isConnReleased(connPool ConnPool) bool {
+	switch pool := connPool.(type) {
+	case *pinnedConn:
+		return pool.isReleased()
+	case *PreparedStmtDB:
+		if pinned, ok := pool.ConnPool.(*pinnedConn); ok {
+			return pinned.isReleased()
+		}
+	}
+	return false
+}
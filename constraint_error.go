@@ -0,0 +1,202 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ConstraintError the details of a violated constraint, errors translated to ErrDuplicatedKey, ErrForeignKeyViolated,
+// ErrCheckConstraintViolated or ErrNotNullViolated wrap it, e.g:
+//
+//	var constraintErr *gorm.ConstraintError
+//	if errors.As(err, &constraintErr) {
+//	  fmt.Println(constraintErr.ConstraintName, constraintErr.Columns)
+//	}
+//
+// details not reported by the database are left empty
+type ConstraintError struct {
+	// Err the violation, ErrDuplicatedKey, ErrForeignKeyViolated, ErrCheckConstraintViolated or ErrNotNullViolated
+	Err            error
+	ConstraintName string
+	Table          string
+	Columns        []string
+	// Value the conflicting value of duplicated keys
+	Value string
+	// Cause the original error returned by the driver
+	Cause error
+}
+
+func (e *ConstraintError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%v: %v", e.Err, e.Cause)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the violation and the driver error, so both of them are matched by errors.Is and errors.As
+func (e *ConstraintError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Err, e.Cause}
+}
+
+// Is matches the violation and the driver error for the versions of errors.Is not unwrapping []error
+func (e *ConstraintError) Is(target error) bool {
+	return errors.Is(e.Err, target) || (e.Cause != nil && errors.Is(e.Cause, target))
+}
+
+// As matches the violation and the driver error for the versions of errors.As not unwrapping []error
+func (e *ConstraintError) As(target interface{}) bool {
+	return errors.As(e.Err, target) || (e.Cause != nil && errors.As(e.Cause, target))
+}
+
+type constraintPattern struct {
+	err     error
+	regexp  *regexp.Regexp
+	extract func(ce *ConstraintError, matches []string)
+}
+
+var (
+	postgresKeyDetailRegexp = regexp.MustCompile(`Key \((.+)\)=\((.*)\)`)
+
+	constraintPatterns = []constraintPattern{
+		// mysql
+		{ErrDuplicatedKey, regexp.MustCompile(`Duplicate entry '(.*)' for key '([^']+)'`), func(ce *ConstraintError, m []string) {
+			ce.Value = m[1]
+			ce.Table, ce.ConstraintName = splitQualifiedName(m[2])
+		}},
+		{ErrCheckConstraintViolated, regexp.MustCompile(`Check constraint '([^']+)' is violated`), func(ce *ConstraintError, m []string) {
+			ce.ConstraintName = m[1]
+		}},
+		{ErrNotNullViolated, regexp.MustCompile(`Column '([^']+)' cannot be null`), func(ce *ConstraintError, m []string) {
+			ce.Columns = []string{m[1]}
+		}},
+		{ErrForeignKeyViolated, regexp.MustCompile("foreign key constraint fails \\((?:`[^`]+`\\.)?`([^`]+)`, CONSTRAINT `([^`]+)` FOREIGN KEY \\(([^)]+)\\)"), func(ce *ConstraintError, m []string) {
+			ce.Table, ce.ConstraintName, ce.Columns = m[1], m[2], splitColumns(m[3])
+		}},
+		// postgres
+		{ErrDuplicatedKey, regexp.MustCompile(`duplicate key value violates unique constraint "([^"]+)"`), func(ce *ConstraintError, m []string) {
+			ce.ConstraintName = m[1]
+		}},
+		{ErrCheckConstraintViolated, regexp.MustCompile(`(?:new row for relation "([^"]+)" )?violates check constraint "([^"]+)"`), func(ce *ConstraintError, m []string) {
+			ce.Table, ce.ConstraintName = m[1], m[2]
+		}},
+		{ErrNotNullViolated, regexp.MustCompile(`null value in column "([^"]+)"(?: of relation "([^"]+)")? violates not-null constraint`), func(ce *ConstraintError, m []string) {
+			ce.Columns, ce.Table = []string{m[1]}, m[2]
+		}},
+		{ErrForeignKeyViolated, regexp.MustCompile(`on table "([^"]+)" violates foreign key constraint "([^"]+)"`), func(ce *ConstraintError, m []string) {
+			ce.Table, ce.ConstraintName = m[1], m[2]
+		}},
+		// sqlite
+		{ErrDuplicatedKey, regexp.MustCompile(`(?:UNIQUE|PRIMARY KEY) constraint failed: (.+)$`), func(ce *ConstraintError, m []string) {
+			for _, column := range splitColumns(m[1]) {
+				var name string
+				ce.Table, name = splitQualifiedName(column)
+				ce.Columns = append(ce.Columns, name)
+			}
+		}},
+		{ErrCheckConstraintViolated, regexp.MustCompile(`CHECK constraint failed: (.+)$`), func(ce *ConstraintError, m []string) {
+			ce.ConstraintName = m[1]
+		}},
+		{ErrNotNullViolated, regexp.MustCompile(`NOT NULL constraint failed: (.+)$`), func(ce *ConstraintError, m []string) {
+			var column string
+			ce.Table, column = splitQualifiedName(m[1])
+			ce.Columns = []string{column}
+		}},
+		{ErrForeignKeyViolated, regexp.MustCompile(`FOREIGN KEY constraint failed`), func(ce *ConstraintError, m []string) {}},
+		// sqlserver
+		{ErrDuplicatedKey, regexp.MustCompile(`Violation of (?:UNIQUE KEY|PRIMARY KEY) constraint '([^']+)'\. Cannot insert duplicate key in object '([^']+)'\. The duplicate key value is \((.*)\)`), func(ce *ConstraintError, m []string) {
+			_, ce.Table = splitQualifiedName(m[2])
+			ce.ConstraintName, ce.Value = m[1], m[3]
+		}},
+		{ErrDuplicatedKey, regexp.MustCompile(`Cannot insert duplicate key row in object '([^']+)' with unique index '([^']+)'\. The duplicate key value is \((.*)\)`), func(ce *ConstraintError, m []string) {
+			_, ce.Table = splitQualifiedName(m[1])
+			ce.ConstraintName, ce.Value = m[2], m[3]
+		}},
+		{ErrCheckConstraintViolated, regexp.MustCompile(`conflicted with the CHECK constraint "([^"]+)"\. The conflict occurred in database "[^"]+", table "([^"]+)"(?:, column '([^']+)')?`), func(ce *ConstraintError, m []string) {
+			_, ce.Table = splitQualifiedName(m[2])
+			ce.ConstraintName = m[1]
+			if m[3] != "" {
+				ce.Columns = []string{m[3]}
+			}
+		}},
+		{ErrNotNullViolated, regexp.MustCompile(`Cannot insert the value NULL into column '([^']+)', table '([^']+)'`), func(ce *ConstraintError, m []string) {
+			_, ce.Table = splitQualifiedName(m[2])
+			ce.Columns = []string{m[1]}
+		}},
+		{ErrForeignKeyViolated, regexp.MustCompile(`conflicted with the FOREIGN KEY constraint "([^"]+)"\. The conflict occurred in database "[^"]+", table "([^"]+)"(?:, column '([^']+)')?`), func(ce *ConstraintError, m []string) {
+			_, ce.Table = splitQualifiedName(m[2])
+			ce.ConstraintName = m[1]
+			if m[3] != "" {
+				ce.Columns = []string{m[3]}
+			}
+		}},
+	}
+)
+
+// ParseConstraintError parses the constraint violation from the message of err reported by mysql, postgres, sqlite
+// or sqlserver, returns nil if err isn't a constraint violation, dialectors implementing ErrorTranslator may use it
+// or fill the ConstraintError with the details of their driver errors
+func ParseConstraintError(err error) *ConstraintError {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	for _, pattern := range constraintPatterns {
+		if matches := pattern.regexp.FindStringSubmatch(msg); matches != nil {
+			ce := &ConstraintError{Err: pattern.err, Cause: err}
+			pattern.extract(ce, matches)
+			return ce
+		}
+	}
+	return nil
+}
+
+// ParsePostgresKeyDetail parses the columns and values from the detail of postgres errors, e.g:
+//
+//	Key (name, age)=(jinzhu, 18) already exists.
+func ParsePostgresKeyDetail(detail string) (columns []string, value string) {
+	if matches := postgresKeyDetailRegexp.FindStringSubmatch(detail); matches != nil {
+		return splitColumns(matches[1]), matches[2]
+	}
+	return nil, ""
+}
+
+// translateConstraintError attaches the details of the constraint violation err to the translated error
+func translateConstraintError(err, translated error) error {
+	var ce *ConstraintError
+	if errors.As(translated, &ce) {
+		return translated
+	}
+
+	if ce = ParseConstraintError(err); ce != nil && (translated == err || errors.Is(ce, translated)) {
+		return ce
+	}
+
+	for _, sentinel := range []error{ErrDuplicatedKey, ErrForeignKeyViolated, ErrCheckConstraintViolated, ErrNotNullViolated} {
+		if translated == sentinel {
+			return &ConstraintError{Err: translated, Cause: err}
+		}
+	}
+	return translated
+}
+
+// splitQualifiedName splits `table.name` into table and name
+func splitQualifiedName(name string) (string, string) {
+	if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+		return name[:idx], name[idx+1:]
+	}
+	return "", name
+}
+
+func splitColumns(columns string) []string {
+	names := strings.Split(columns, ",")
+	for idx, name := range names {
+		names[idx] = strings.Trim(strings.TrimSpace(name), "`\"[]")
+	}
+	return names
+}
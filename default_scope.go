@@ -0,0 +1,114 @@
+package gorm
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// DefaultScope the models implementing it are queried with the scope, including Find, First, Count, Pluck and the
+// preloads of the model, the clauses added by the scope, e.g: the conditions and the orders, are merged into the
+// queries, it's disabled by Unscoped or WithoutDefaultScope, e.g:
+//
+//	func (Article) DefaultScope(db *gorm.DB) *gorm.DB {
+//		return db.Where("visible = ?", true).Order("position")
+//	}
+//
+// The updates and deletes aren't scoped unless the model implements DefaultWriteScope
+type DefaultScope interface {
+	DefaultScope(db *DB) *DB
+}
+
+// DefaultWriteScope the models implementing it are updated and deleted with the scope like DefaultScope, e.g: to
+// protect the rows hidden by DefaultScope
+type DefaultWriteScope interface {
+	DefaultWriteScope(db *DB) *DB
+}
+
+const (
+	withoutDefaultScopeKey = "gorm:without_default_scope"
+	countingKey            = "gorm:counting"
+)
+
+// defaultScopeKey marks the context of the default scope of the schema, the queries of the scope, e.g: the subqueries
+// of the same model, aren't scoped again
+type defaultScopeKey struct {
+	schema *schema.Schema
+}
+
+// WithoutDefaultScope disables DefaultScope and DefaultWriteScope of the models, including the preloads, unlike
+// Unscoped, the soft deleted rows are still excluded
+func (db *DB) WithoutDefaultScope() (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.Settings.Store(withoutDefaultScopeKey, true)
+	return
+}
+
+// AddDefaultScope applies DefaultScope of the model to the query, or DefaultWriteScope to the update or delete if
+// write, the query, update and delete callbacks call it before building the statement
+func (stmt *Statement) AddDefaultScope(write bool) {
+	if stmt.Schema == nil || stmt.Unscoped {
+		return
+	}
+
+	if _, ok := stmt.Clauses["default_scope_enabled"]; ok {
+		return
+	}
+
+	if _, disabled := stmt.Settings.Load(withoutDefaultScopeKey); disabled {
+		return
+	}
+
+	ctx := stmt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	} else if ctx.Value(defaultScopeKey{schema: stmt.Schema}) != nil {
+		return
+	}
+
+	var fc func(*DB) *DB
+	model := reflect.New(stmt.Schema.ModelType).Interface()
+	if write {
+		if scope, ok := model.(DefaultWriteScope); ok {
+			fc = scope.DefaultWriteScope
+		}
+	} else if scope, ok := model.(DefaultScope); ok {
+		fc = scope.DefaultScope
+	}
+
+	if fc == nil {
+		return
+	}
+	stmt.Clauses["default_scope_enabled"] = clause.Clause{}
+
+	tx := stmt.DB.Session(&Session{NewDB: true, Context: context.WithValue(ctx, defaultScopeKey{schema: stmt.Schema}, true)}).Model(stmt.Model)
+	tx.Statement.Schema, tx.Statement.Table = stmt.Schema, stmt.Table
+	tx = fc(tx)
+	for len(tx.Statement.scopes) > 0 {
+		tx = tx.executeScopes()
+	}
+
+	if tx.Error != nil {
+		stmt.AddError(tx.Error)
+		return
+	}
+
+	_, counting := stmt.Settings.Load(countingKey)
+	for name, c := range tx.Statement.Clauses {
+		switch expr := c.Expression.(type) {
+		case clause.Where:
+			if len(expr.Exprs) > 0 {
+				groupOrConditions(stmt)
+				stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.And(expr.Exprs...)}})
+			}
+		case clause.Interface:
+			if name == "ORDER BY" && counting {
+				// the orders are removed by Count
+				continue
+			}
+			stmt.AddClause(expr)
+		}
+	}
+}
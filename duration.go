@@ -0,0 +1,46 @@
+package gorm
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// durationVar the time.Duration value of the map conditions, which is bound as the value stored by the duration field
+// of the column when the statement is built, e.g: the milliseconds of the field tagged with `durationUnit:ms`
+type durationVar struct {
+	column string
+	value  time.Duration
+}
+
+// durationVarOf wraps the time.Duration value of the column of the map conditions
+func durationVarOf(column string, value interface{}) interface{} {
+	if d, ok := value.(time.Duration); ok {
+		return durationVar{column: column, value: d}
+	}
+	return value
+}
+
+// Build binds the value stored by the duration field, or the nanoseconds if the column isn't a field of the model
+func (v durationVar) Build(builder clause.Builder) {
+	var value interface{} = v.value
+	if stmt, ok := builder.(*Statement); ok && stmt.Schema != nil {
+		column := v.column
+		if table, col := matchName(column); col != "" && (table == "" || table == stmt.Table) {
+			column = col
+		}
+
+		if field := stmt.Schema.LookUpField(column); field != nil {
+			value = field.DurationValue(stmt.Context, v.value)
+		}
+	}
+	builder.AddVar(builder, value)
+}
+
+// conditionValue returns the value of the condition built by BuildCondition
+func conditionValue(value interface{}) interface{} {
+	if v, ok := value.(durationVar); ok {
+		return v.value
+	}
+	return value
+}
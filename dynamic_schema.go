@@ -0,0 +1,65 @@
+package gorm
+
+import (
+	"gorm.io/gorm/schema"
+)
+
+type dynamicSchemaKey string
+
+// RegisterDynamicSchema registers the schema of the table defined at runtime, which is used by the statements of the
+// table with the map values like the models, e.g: the auto create and update time, the soft delete and the permissions
+// of the fields, registering a table again replaces its schema
+//
+//	db.RegisterDynamicSchema(schema.NewDynamic("custom_objects_42").
+//		Field("id", schema.Uint, schema.PrimaryKey).
+//		Field("created_at", schema.Time, schema.AutoCreateTime).
+//		Field("deleted_at", schema.Time, schema.SoftDelete))
+//
+//	db.Table("custom_objects_42").Create(map[string]interface{}{"name": "jinzhu"})
+//	db.Model("custom_objects_42").Where("id = ?", 1).Find(&results)
+//	db.Migrator().AutoMigrate(dynamic)
+func (db *DB) RegisterDynamicSchema(dynamic *schema.DynamicSchema) error {
+	s, err := dynamic.Parse(db.NamingStrategy)
+	if err != nil {
+		return err
+	}
+
+	db.cacheStore.Store(dynamicSchemaKey(s.Table), s)
+	return nil
+}
+
+// parseDynamicSchema parses the dynamic schema of value, which is the DynamicSchema, the name of the registered table or
+// the maps of the registered table, returns false if value isn't a dynamic schema
+func (stmt *Statement) parseDynamicSchema(value interface{}, specialTableName string) (bool, error) {
+	var table string
+	switch v := value.(type) {
+	case *schema.DynamicSchema:
+		s, err := v.Parse(stmt.DB.NamingStrategy)
+		if err != nil {
+			return true, err
+		}
+		stmt.Schema = s
+		if stmt.Table == "" {
+			stmt.Table = s.Table
+		}
+		return true, nil
+	case string:
+		table = v
+	case map[string]interface{}, *map[string]interface{}, []map[string]interface{}, *[]map[string]interface{}:
+		if table = specialTableName; table == "" {
+			table = stmt.Table
+		}
+	default:
+		return false, nil
+	}
+
+	v, ok := stmt.DB.cacheStore.Load(dynamicSchemaKey(table))
+	if !ok {
+		return false, nil
+	}
+
+	if stmt.Schema = v.(*schema.Schema); stmt.Table == "" {
+		stmt.Table = stmt.Schema.Table
+	}
+	return true, nil
+}
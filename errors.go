@@ -2,6 +2,9 @@ package gorm
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 
 	"gorm.io/gorm/logger"
 )
@@ -39,16 +42,115 @@ var (
 	ErrDryRunModeUnsupported = errors.New("dry run mode unsupported")
 	// ErrInvalidDB invalid db
 	ErrInvalidDB = errors.New("invalid db")
+	// ErrConnReleased the connection acquired by AcquireConn has been released
+	ErrConnReleased = errors.New("connection released")
+	// ErrUnregisteredScope the scope applied with NamedScopes isn't registered, see RegisterScope
+	ErrUnregisteredScope = errors.New("unregistered scope")
+	// ErrUnregisteredRedactor the redactor of the `redact` tag isn't registered, see RegisterRedactor
+	ErrUnregisteredRedactor = errors.New("unregistered redactor")
+	// ErrRedactedModel the model saved was loaded with the redacted fields, see Session.Redact
+	ErrRedactedModel = errors.New("model loaded with redacted fields")
+	// ErrUnregisteredQuery the query queried with Named isn't registered, see RegisterQuery
+	ErrUnregisteredQuery = errors.New("unregistered query")
+	// ErrNotSoftDeleted the model restored with Restore isn't soft deleted
+	ErrNotSoftDeleted = errors.New("model isn't soft deleted")
+	// ErrMissingAuditUser the acting user of the audit fields isn't found in the context, see Config.StrictAudit
+	ErrMissingAuditUser = errors.New("missing audit user")
+	// ErrMissingTenant the tenant of the model having a tenant field isn't resolved, see Config.TenantResolver
+	ErrMissingTenant = errors.New("missing tenant")
+	// ErrShardNotFound the shard of the sharded model can't be determined by the statement, see Config.Sharder
+	ErrShardNotFound = errors.New("shard not found")
+	// ErrLastInsertIDUnsupported the dialect doesn't return the last insert id of Exec, e.g: Postgres, use RETURNING
+	ErrLastInsertIDUnsupported = errors.New("last insert id unsupported")
+	// ErrTransient dialectors translate errors which may succeed when retried to it, see Config.RetryTransient
+	ErrTransient = errors.New("transient error")
+	// ErrEmptyInCondition the IN condition without values, which is returned if Config.ErrorOnEmptyIN is enabled
+	ErrEmptyInCondition = errors.New("empty IN condition")
+	// ErrNullAggregate the aggregate of Max, Min, SumOf or AvgOf is NULL, e.g: no rows matched, see AggregateRequired
+	ErrNullAggregate = errors.New("aggregate is null")
 	// ErrInvalidValue invalid value
 	ErrInvalidValue = errors.New("invalid value, should be pointer to struct or slice")
 	// ErrInvalidValueOfLength invalid values do not match length
 	ErrInvalidValueOfLength = errors.New("invalid association values, length doesn't match")
 	// ErrPreloadNotAllowed preload is not allowed when count is used
 	ErrPreloadNotAllowed = errors.New("preload is not allowed when count is used")
+	// ErrPreloadConflict the same preloaded relationship with different conditions
+	ErrPreloadConflict = errors.New("conflicting preload conditions")
 	// ErrDuplicatedKey occurs when there is a unique key constraint violation
 	ErrDuplicatedKey = errors.New("duplicated key not allowed")
 	// ErrForeignKeyViolated occurs when there is a foreign key constraint violation
 	ErrForeignKeyViolated = errors.New("violates foreign key constraint")
 	// ErrCheckConstraintViolated occurs when there is a check constraint violation
 	ErrCheckConstraintViolated = errors.New("violates check constraint")
+	// ErrNotNullViolated occurs when there is a not null constraint violation
+	ErrNotNullViolated = errors.New("violates not null constraint")
 )
+
+// BatchError returned when some rows of a batch failed in PartialBatch mode, the other rows are still saved
+type BatchError struct {
+	// Errors the errors of failed rows, keyed by their index in the batch
+	Errors map[int]error
+	// RowsAffected the rows affected by the saved rows
+	RowsAffected int64
+}
+
+func (e *BatchError) Error() string {
+	indexes := make([]int, 0, len(e.Errors))
+	for idx := range e.Errors {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+
+	msgs := make([]string, 0, len(indexes))
+	for _, idx := range indexes {
+		msgs = append(msgs, fmt.Sprintf("#%d: %v", idx, e.Errors[idx]))
+	}
+	return fmt.Sprintf("%d rows of batch failed: %s", len(indexes), strings.Join(msgs, "; "))
+}
+
+// DryRunError returned when executing a statement which needs a database connection in DryRun mode, e.g. Rows,
+// it unwraps to ErrDryRunModeUnsupported
+type DryRunError struct {
+	SQL  string
+	Vars []interface{}
+}
+
+func (e *DryRunError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrDryRunModeUnsupported, e.SQL)
+}
+
+func (e *DryRunError) Unwrap() error {
+	return ErrDryRunModeUnsupported
+}
+
+// InterruptedError returned when the context is done between the statements of an operation, whose remaining
+// statements aren't executed, e.g: the batches of CreateInBatches, it unwraps to the error of the context
+type InterruptedError struct {
+	// Operation the interrupted operation, e.g: create in batches
+	Operation string
+	// Completed the completed steps of the operation, e.g: the created batches
+	Completed int
+	Err       error
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("%s interrupted after %d completed: %v", e.Operation, e.Completed, e.Err)
+}
+
+func (e *InterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// UnsupportedConnPoolError returned by DB.Stats when the ConnPool isn't backed by *sql.DB
+type UnsupportedConnPoolError struct {
+	ConnPool ConnPool
+	Err      error
+}
+
+func (e *UnsupportedConnPoolError) Error() string {
+	return fmt.Sprintf("connection pool %T doesn't provide stats: %v", e.ConnPool, e.Err)
+}
+
+func (e *UnsupportedConnPoolError) Unwrap() error {
+	return e.Err
+}
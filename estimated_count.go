@@ -0,0 +1,175 @@
+package gorm
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+// CountStrategy is the strategy used by EstimatedCount to count the rows
+type CountStrategy string
+
+const (
+	// CountStatistics reads the row count from the table statistics of the database
+	CountStatistics CountStrategy = "statistics"
+	// CountPlanner parses the row estimate of the query planner from EXPLAIN, see Session.AllowPlannerEstimate
+	CountPlanner CountStrategy = "planner"
+	// CountExact executes SELECT count(*) like Count
+	CountExact CountStrategy = "exact"
+)
+
+// EstimatedCount counts the rows of the chained conditions approximately, it is meant for the large tables whose
+// exact count is too slow, e.g: the totals of pagination, and returns the strategy used to count them:
+//
+//	var total int64
+//	strategy, err := db.Model(&User{}).EstimatedCount(&total)
+//
+// The queries without conditions read the table statistics, reltuples of pg_class adjusted by the current relpages
+// on Postgres and TABLE_ROWS of information_schema on MySQL, the queries with conditions parse the row estimate of
+// EXPLAIN on Postgres and MySQL if AllowPlannerEstimate is enabled, the exact count is used otherwise, or when the
+// statistics aren't available, e.g: the tables never analyzed. The conditions of soft delete are conditions too, use
+// Unscoped to count the soft deleted rows by the statistics.
+//
+// The statistics are only updated by ANALYZE or the auto vacuum, so the estimated counts may be far from the exact
+// ones, e.g: after bulk inserts, don't use them for the logics relying on the exact counts
+func (db *DB) EstimatedCount(count *int64) (CountStrategy, error) {
+	queryTx := db.Session(&Session{DryRun: true, SkipDefaultTransaction: true})
+	queryTx.Statement = queryTx.Statement.clone()
+	queryTx.Statement.DB = queryTx
+	// the query is only built to find the table and the conditions, it isn't one of the statements of ToSQLs
+	queryTx.Statement.sqlStatements = nil
+	if queryTx.Statement.Model == nil {
+		queryTx.Statement.Model = queryTx.Statement.Dest
+	}
+	if queryTx = queryTx.Find(&[]map[string]interface{}{}); queryTx.Error != nil {
+		return CountExact, queryTx.Error
+	}
+
+	var (
+		stmt      = queryTx.Statement
+		estimated sql.NullFloat64
+		strategy  CountStrategy
+		err       error
+	)
+
+	switch dialect := db.Dialector.Name(); {
+	case dialect != "postgres" && dialect != "mysql":
+		// the other dialects have neither the statistics nor the row estimates
+	case db.Statement.SQL.Len() == 0 && stmt.estimatedByStatistics():
+		strategy = CountStatistics
+		estimated, err = db.statisticsRowCount(stmt)
+	case db.AllowPlannerEstimate:
+		strategy = CountPlanner
+		estimated, err = db.plannerRowCount(stmt.SQL.String(), stmt.Vars)
+	}
+
+	if strategy != "" && (db.DryRun || err != nil) {
+		return strategy, err
+	}
+
+	if estimated.Valid && estimated.Float64 >= 0 {
+		*count = int64(estimated.Float64)
+		return strategy, nil
+	}
+	return CountExact, db.Count(count).Error
+}
+
+// estimatedByStatistics reports whether the rows of the statement can be counted by the table statistics, which is
+// only true for the queries of all the rows of a table
+func (stmt *Statement) estimatedByStatistics() bool {
+	if stmt.Table == "" || stmt.TableExpr != nil || len(stmt.Joins) > 0 || stmt.Distinct {
+		return false
+	}
+
+	for _, name := range []string{"WHERE", "GROUP BY", "LIMIT"} {
+		if _, ok := stmt.Clauses[name]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// statisticsRowCount reads the row count of the table of stmt from the statistics of the database, it isn't valid
+// if the dialect has no statistics or the table isn't analyzed yet
+func (db *DB) statisticsRowCount(stmt *Statement) (estimated sql.NullFloat64, err error) {
+	var query string
+	vars := []interface{}{stmt.Table}
+
+	switch db.Dialector.Name() {
+	case "postgres":
+		// reltuples is scaled by the current pages, as the pages are updated by the writes before the next ANALYZE
+		query = "SELECT CASE WHEN c.reltuples < 0 OR c.relpages = 0 THEN NULL " +
+			"ELSE c.reltuples / c.relpages * (pg_relation_size(c.oid) / current_setting('block_size')::integer) END " +
+			"FROM pg_class c WHERE c.oid = to_regclass(?)"
+		vars = []interface{}{stmt.Quote(stmt.Table)}
+	case "mysql":
+		query = "SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?"
+	default:
+		return
+	}
+
+	err = db.Session(&Session{NewDB: true}).Raw(query, vars...).Scan(&estimated).Error
+	return
+}
+
+// plannerRowCount parses the row estimate of the query planner from the EXPLAIN of query, it isn't valid if the
+// dialect doesn't estimate rows
+func (db *DB) plannerRowCount(query string, vars []interface{}) (estimated sql.NullFloat64, err error) {
+	dialect := db.Dialector.Name()
+	if dialect != "postgres" && dialect != "mysql" {
+		return
+	}
+
+	explainTx := db.Session(&Session{NewDB: true}).getInstance()
+	explainTx.Statement.SQL.WriteString(explainSQL(dialect, query, false))
+	explainTx.Statement.Vars = vars
+	rows, err := explainTx.Rows()
+	if err != nil {
+		if db.DryRun && errors.Is(err, ErrDryRunModeUnsupported) {
+			// the EXPLAIN is generated, nothing to parse
+			err = nil
+		}
+		return
+	}
+	defer rows.Close()
+
+	var plan json.RawMessage
+	if err = scanQueryPlan(rows, &plan); err != nil {
+		return
+	}
+
+	switch dialect {
+	case "postgres":
+		var plans []struct {
+			Plan struct {
+				Rows float64 `json:"Plan Rows"`
+			}
+		}
+		if json.Unmarshal(plan, &plans) == nil && len(plans) > 0 {
+			estimated = sql.NullFloat64{Float64: plans[0].Plan.Rows, Valid: true}
+		}
+	case "mysql":
+		type table struct {
+			RowsProduced *float64 `json:"rows_produced_per_join"`
+		}
+		var explained struct {
+			QueryBlock struct {
+				Table      *table `json:"table"`
+				NestedLoop []struct {
+					Table *table `json:"table"`
+				} `json:"nested_loop"`
+			} `json:"query_block"`
+		}
+		if json.Unmarshal(plan, &explained) == nil {
+			// the rows produced by the last table of the nested loop are the rows of the joins
+			t := explained.QueryBlock.Table
+			if loop := explained.QueryBlock.NestedLoop; len(loop) > 0 {
+				t = loop[len(loop)-1].Table
+			}
+			if t != nil && t.RowsProduced != nil {
+				estimated = sql.NullFloat64{Float64: *t.RowsProduced, Valid: true}
+			}
+		}
+	}
+	return
+}
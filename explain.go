@@ -0,0 +1,199 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// selectIntoRegexp matches SELECT ... INTO, which creates a table with the results
+var selectIntoRegexp = regexp.MustCompile(`(?i)\bINTO\b`)
+
+// ExplainQuery explains the query of the chained conditions with the EXPLAIN of the dialect and scans the query plan to
+// dest, which should be *string or *json.RawMessage, analyze executes the query to get the actual costs and is only
+// allowed for plain SELECT queries, e.g:
+//
+//	var plan string
+//	db.Model(&User{}).Where("name = ?", "jinzhu").ExplainQuery(&plan, false)
+//
+//	var jsonPlan json.RawMessage
+//	db.Raw("SELECT * FROM users WHERE age > ?", 18).ExplainQuery(&jsonPlan, true)
+//
+// Postgres and MySQL return the plan in FORMAT JSON, SQLite returns the details of EXPLAIN QUERY PLAN
+func (db *DB) ExplainQuery(dest interface{}, analyze bool) (tx *DB) {
+	tx = db.getInstance()
+	switch dest.(type) {
+	case *string, *json.RawMessage:
+	default:
+		tx.AddError(fmt.Errorf("%w: unsupported query plan destination %T", ErrInvalidData, dest))
+		return
+	}
+
+	queryTx := db.Session(&Session{DryRun: true, SkipDefaultTransaction: true}).Find(&[]map[string]interface{}{})
+	if queryTx.Error != nil {
+		tx.AddError(queryTx.Error)
+		return
+	}
+
+	query := queryTx.Statement.SQL.String()
+	if analyze && !isSelectSQL(query) {
+		tx.AddError(fmt.Errorf("%w: ANALYZE only executes plain SELECT queries, got %s", ErrInvalidData, query))
+		return
+	}
+
+	explainTx := db.Session(&Session{NewDB: true}).getInstance()
+	explainTx.Statement.SQL.WriteString(explainSQL(db.Dialector.Name(), query, analyze))
+	explainTx.Statement.Vars = queryTx.Statement.Vars
+	rows, err := explainTx.Rows()
+	if err != nil {
+		tx.AddError(err)
+		return
+	}
+	defer rows.Close()
+
+	tx.AddError(scanQueryPlan(rows, dest))
+	return
+}
+
+// explainSlowQuery logs the query plan of the slow sql asynchronously with the connection pool it was executed on, so
+// queries in transactions are explained in them, see SlowQueryExplainer
+func (db *DB) explainSlowQuery(query string, vars []interface{}) {
+	connPool := db.Statement.ConnPool
+	switch pool := connPool.(type) {
+	case *PreparedStmtDB:
+		connPool = pool.ConnPool
+	case *PreparedStmtTX:
+		connPool = pool.Tx
+	}
+	if connPool == nil {
+		return
+	}
+
+	vars = append([]interface{}(nil), vars...)
+	go func() {
+		var (
+			plan    string
+			ctx     = context.Background()
+			rows    *sql.Rows
+			err     error
+			explain = explainSQL(db.Dialector.Name(), query, false)
+		)
+
+		if rows, err = connPool.QueryContext(ctx, explain, vars...); err == nil {
+			err = scanQueryPlan(rows, &plan)
+			rows.Close()
+		}
+
+		if err != nil {
+			db.Logger.Warn(ctx, "failed to explain slow SQL %s: %v", query, err)
+		} else {
+			db.Logger.Warn(ctx, "query plan of slow SQL %s\n%s", query, plan)
+		}
+	}()
+}
+
+// explainSQL prefixes sql with the EXPLAIN of dialect
+func explainSQL(dialect, sql string, analyze bool) string {
+	switch dialect {
+	case "sqlite", "sqlite3":
+		return "EXPLAIN QUERY PLAN " + sql
+	case "postgres":
+		if analyze {
+			return "EXPLAIN (ANALYZE, FORMAT JSON) " + sql
+		}
+		return "EXPLAIN (FORMAT JSON) " + sql
+	case "mysql":
+		// EXPLAIN ANALYZE of MySQL 8 only supports the TREE format
+		if analyze {
+			return "EXPLAIN ANALYZE " + sql
+		}
+		return "EXPLAIN FORMAT=JSON " + sql
+	}
+
+	if analyze {
+		return "EXPLAIN ANALYZE " + sql
+	}
+	return "EXPLAIN " + sql
+}
+
+// isSelectSQL reports whether sql is a plain SELECT, which is safe to be executed by ANALYZE, CTEs are excluded as they
+// could modify data, e.g: WITH deleted AS (DELETE FROM users RETURNING *) SELECT * FROM deleted
+func isSelectSQL(sql string) bool {
+	return hasSQLPrefix(sql, "SELECT") && !selectIntoRegexp.MatchString(sql)
+}
+
+// explainable reports whether sql can be explained, writes are explained without ANALYZE only
+func explainable(sql string) bool {
+	return hasSQLPrefix(sql, "SELECT", "WITH", "INSERT", "UPDATE", "DELETE")
+}
+
+func hasSQLPrefix(sql string, prefixes ...string) bool {
+	sql = strings.TrimLeft(sql, " \t\r\n(")
+	for _, prefix := range prefixes {
+		if len(sql) >= len(prefix) && strings.EqualFold(sql[:len(prefix)], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanQueryPlan scans the rows of EXPLAIN to dest, single column plans are returned as they are, the details of the
+// plans with many columns are joined by lines for *string and the rows are marshaled for *json.RawMessage
+func scanQueryPlan(rows *sql.Rows, dest interface{}) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	var (
+		lines   []string
+		records []map[string]interface{}
+	)
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+
+		record := make(map[string]interface{}, len(columns))
+		texts := make([]string, 0, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			record[column] = values[i]
+			texts = append(texts, fmt.Sprint(values[i]))
+		}
+		records = append(records, record)
+
+		if detail, ok := record["detail"]; ok && len(columns) > 1 {
+			lines = append(lines, fmt.Sprint(detail))
+		} else {
+			lines = append(lines, strings.Join(texts, "\t"))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	switch dest := dest.(type) {
+	case *string:
+		*dest = strings.Join(lines, "\n")
+	case *json.RawMessage:
+		if len(columns) == 1 && len(lines) == 1 && json.Valid([]byte(lines[0])) {
+			*dest = json.RawMessage(lines[0])
+		} else if *dest, err = json.Marshal(records); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: unsupported query plan destination %T", ErrInvalidData, dest)
+	}
+	return nil
+}
@@ -0,0 +1,449 @@
+package gorm
+
+import (
+	"bufio"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils"
+)
+
+// ExportFormat the format of the rows written by Export
+type ExportFormat string
+
+const (
+	// ExportCSV writes the rows as CSV, the first line is the header of the column names
+	ExportCSV ExportFormat = "csv"
+	// ExportNDJSON writes the rows as JSON objects per line, the keys are the JSON names of the fields
+	ExportNDJSON ExportFormat = "ndjson"
+)
+
+// ExportOption the option of Export, see ExportColumns, ExportNull, ExportBatchSize and ExportFlushEvery
+type ExportOption func(*exportConfig)
+
+type exportConfig struct {
+	columns    []string
+	null       string
+	batchSize  int
+	flushEvery int
+}
+
+// ExportColumns exports the columns in the order, the columns are the names or the column names of the fields
+func ExportColumns(columns ...string) ExportOption {
+	return func(config *exportConfig) {
+		config.columns = append(config.columns, columns...)
+	}
+}
+
+// ExportNull writes the NULL values of CSV as null, which are written as empty strings by default
+func ExportNull(null string) ExportOption {
+	return func(config *exportConfig) {
+		config.null = null
+	}
+}
+
+// ExportBatchSize queries the rows in batches of size ordered by the primary key like FindInBatches, instead of
+// reading all the rows by one long-running query
+func ExportBatchSize(size int) ExportOption {
+	return func(config *exportConfig) {
+		config.batchSize = size
+	}
+}
+
+// ExportFlushEvery flushes the written rows to the writer every rows, 1000 by default
+func ExportFlushEvery(rows int) ExportOption {
+	return func(config *exportConfig) {
+		config.flushEvery = rows
+	}
+}
+
+// ExportResult the rows and bytes written by Export
+type ExportResult struct {
+	Rows  int64
+	Bytes int64
+}
+
+// Export streams the rows of the query to w, the rows are scanned one by one with ScanRows, so the values of the
+// fields are scanned by their serializers and the memory doesn't grow with the rows, the columns not found in the
+// model are exported with the values returned by the driver, e.g:
+//
+//	result, err := db.Model(&Order{}).Where("created_at > ?", since).Export(w, gorm.ExportCSV, gorm.ExportColumns("ID", "Amount"))
+//
+// The written rows and bytes are returned with the error if the export fails or the context is done
+func (db *DB) Export(w io.Writer, format ExportFormat, opts ...ExportOption) (result ExportResult, err error) {
+	config := exportConfig{flushEvery: 1000}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	counter := &exportCounter{Writer: w}
+	exporter := &exporter{config: config, w: w, buf: bufio.NewWriter(counter), format: format}
+	switch format {
+	case ExportCSV:
+		exporter.csv = csv.NewWriter(exporter.buf)
+	case ExportNDJSON:
+	default:
+		return result, fmt.Errorf("%w: export format %q", ErrInvalidData, format)
+	}
+
+	tx := db.getInstance()
+	if tx.Statement.Model == nil {
+		tx.Statement.Model = tx.Statement.Dest
+	}
+	if tx.Statement.Model != nil {
+		if err = tx.Statement.Parse(tx.Statement.Model); err != nil {
+			return result, err
+		}
+	}
+	exporter.tx = tx
+
+	if config.batchSize > 0 {
+		err = exporter.exportInBatches()
+	} else {
+		err = exporter.exportRows()
+	}
+
+	if flushErr := exporter.flush(); err == nil {
+		err = flushErr
+	}
+	return ExportResult{Rows: exporter.rows, Bytes: counter.bytes}, err
+}
+
+type exportCounter struct {
+	io.Writer
+	bytes int64
+}
+
+func (w *exportCounter) Write(p []byte) (n int, err error) {
+	n, err = w.Writer.Write(p)
+	w.bytes += int64(n)
+	return
+}
+
+type exportColumn struct {
+	name  string
+	key   string
+	field *schema.Field
+}
+
+type exporter struct {
+	tx      *DB
+	config  exportConfig
+	format  ExportFormat
+	w       io.Writer
+	buf     *bufio.Writer
+	csv     *csv.Writer
+	columns []exportColumn
+	record  []string
+	rows    int64
+}
+
+// lookUpColumns returns the columns exported by the names or the column names of the fields
+func (e *exporter) lookUpColumns(names []string) []exportColumn {
+	columns := make([]exportColumn, 0, len(names))
+	for _, name := range names {
+		column := exportColumn{name: name, key: name}
+		if e.tx.Statement.Schema != nil {
+			if field := e.tx.Statement.Schema.LookUpField(name); field != nil && field.DBName != "" {
+				column = exportColumn{name: field.DBName, key: field.Name, field: field}
+				if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+					if e.format == ExportNDJSON {
+						continue
+					}
+				} else if tag != "" {
+					column.key = tag
+				}
+			}
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// writeHeader writes the header of CSV
+func (e *exporter) writeHeader() error {
+	if e.csv == nil {
+		return nil
+	}
+
+	header := make([]string, len(e.columns))
+	for idx, column := range e.columns {
+		header[idx] = column.name
+	}
+	return e.csv.Write(header)
+}
+
+// exportRows exports the rows of one query, scanning them to the model if all the columns are its fields
+func (e *exporter) exportRows() error {
+	tx := e.tx
+	if len(e.config.columns) > 0 && len(tx.Statement.Selects) == 0 {
+		tx = tx.Select(e.config.columns)
+	}
+
+	rows, err := tx.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	names, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if len(e.config.columns) > 0 {
+		e.columns = e.lookUpColumns(e.config.columns)
+		for _, column := range e.columns {
+			if !utils.Contains(names, column.name) {
+				return fmt.Errorf("%w: export column %v isn't selected", ErrInvalidField, column.name)
+			}
+		}
+	} else {
+		e.columns = e.lookUpColumns(names)
+	}
+
+	scanModel := tx.Statement.Schema != nil
+	for _, column := range e.columns {
+		if column.field == nil {
+			scanModel = false
+		}
+	}
+
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	scanDB := tx.Session(&Session{NewDB: true})
+	for rows.Next() {
+		if err := tx.Statement.Interrupted("export", int(e.rows)); err != nil {
+			return err
+		}
+
+		if scanModel {
+			elem := reflect.New(tx.Statement.Schema.ModelType)
+			if err := scanDB.ScanRows(rows, elem.Interface()); err != nil {
+				return err
+			}
+			err = e.writeRow(elem.Elem(), nil)
+		} else {
+			values := map[string]interface{}{}
+			if err := scanDB.ScanRows(rows, &values); err != nil {
+				return err
+			}
+			err = e.writeRow(reflect.Value{}, values)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		if interrupted := tx.Statement.Interrupted("export", int(e.rows)); interrupted != nil {
+			return interrupted
+		}
+		return err
+	}
+	return nil
+}
+
+// exportInBatches exports the rows in batches with FindInBatches
+func (e *exporter) exportInBatches() error {
+	tx := e.tx
+	if tx.Statement.Schema == nil {
+		return fmt.Errorf("%w: export in batches", ErrModelValueRequired)
+	}
+
+	switch {
+	case len(e.config.columns) > 0:
+		e.columns = e.lookUpColumns(e.config.columns)
+	case len(tx.Statement.Selects) > 0:
+		e.columns = e.lookUpColumns(tx.Statement.Selects)
+	default:
+		e.columns = e.lookUpColumns(tx.Statement.Schema.DBNames)
+	}
+	for _, column := range e.columns {
+		if column.field == nil {
+			return fmt.Errorf("%w: export column %v isn't a field of %v", ErrInvalidField, column.name, tx.Statement.Schema)
+		}
+	}
+
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	results := reflect.New(reflect.SliceOf(tx.Statement.Schema.ModelType))
+	return tx.FindInBatches(results.Interface(), e.config.batchSize, func(batchTx *DB, batch int) error {
+		values := results.Elem()
+		for i := 0; i < values.Len(); i++ {
+			if err := tx.Statement.Interrupted("export", int(e.rows)); err != nil {
+				return err
+			}
+			if err := e.writeRow(values.Index(i), nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// writeRow writes the row of the model value or the scanned values
+func (e *exporter) writeRow(rv reflect.Value, values map[string]interface{}) error {
+	ctx := e.tx.Statement.Context
+	valueOf := func(column exportColumn) interface{} {
+		if rv.IsValid() {
+			if e.format == ExportNDJSON {
+				return column.field.ReflectValueOf(ctx, rv).Interface()
+			}
+			value, _ := column.field.ValueOf(ctx, rv)
+			return value
+		}
+		return values[column.name]
+	}
+
+	if e.csv != nil {
+		if cap(e.record) < len(e.columns) {
+			e.record = make([]string, len(e.columns))
+		}
+		record := e.record[:len(e.columns)]
+		for idx, column := range e.columns {
+			s, err := e.formatCSV(valueOf(column))
+			if err != nil {
+				return fmt.Errorf("failed to export column %v, got error: %w", column.name, err)
+			}
+			record[idx] = s
+		}
+		if err := e.csv.Write(record); err != nil {
+			return err
+		}
+	} else {
+		e.buf.WriteByte('{')
+		for idx, column := range e.columns {
+			if idx > 0 {
+				e.buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(column.key)
+			e.buf.Write(key)
+			e.buf.WriteByte(':')
+
+			value := valueOf(column)
+			if b, ok := value.([]byte); ok && !rv.IsValid() {
+				// the text columns may be returned as bytes by the drivers
+				value = string(b)
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("failed to export column %v, got error: %w", column.name, err)
+			}
+			e.buf.Write(data)
+		}
+		if _, err := e.buf.WriteString("}\n"); err != nil {
+			return err
+		}
+	}
+
+	if e.rows++; e.config.flushEvery > 0 && e.rows%int64(e.config.flushEvery) == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *exporter) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := e.buf.Flush(); err != nil {
+		return err
+	}
+
+	// e.g: http.ResponseWriter
+	if flusher, ok := e.w.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// formatCSV formats the value of CSV, the values of driver.Valuer, e.g: the serializers, are formatted by their values
+func (e *exporter) formatCSV(value interface{}) (string, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return e.config.null, nil
+		}
+
+		var err error
+		if value, err = valuer.Value(); err != nil {
+			return "", err
+		}
+	}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return e.config.null, nil
+		}
+		rv = rv.Elem()
+		value = rv.Interface()
+		if valuer, ok := value.(driver.Valuer); ok {
+			return e.formatCSV(valuer)
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return e.config.null, nil
+	case string:
+		return v, nil
+	case []byte:
+		if isText(v) {
+			return string(v), nil
+		}
+		return base64.StdEncoding.EncodeToString(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		data, err := json.Marshal(value)
+		return string(data), err
+	}
+	return fmt.Sprint(value), nil
+}
+
+// isText reports whether the bytes are valid UTF-8 text without control characters except the whitespaces
+func isText(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, c := range b {
+		if c < 0x20 && c != '\t' && c != '\n' && c != '\r' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,170 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// ChannelOption the option of FindToChannel, see ChannelErrors
+type ChannelOption func(*channelConfig)
+
+type channelConfig struct {
+	errs chan<- error
+}
+
+// ChannelErrors sends the error of FindToChannel to errs before the channel is closed, the error is sent without
+// blocking, so errs should be buffered
+func ChannelErrors(errs chan<- error) ChannelOption {
+	return func(config *channelConfig) {
+		config.errs = errs
+	}
+}
+
+// FindToChannel finds the records and sends them to the channel ch one by one, whose element should be a struct or a
+// pointer to struct, every row is scanned into a new value and its AfterFind hooks are called before it is sent, the
+// channel is closed when all the rows are sent or the find fails, e.g:
+//
+//	events := make(chan *Event, 100)
+//	go func() {
+//		if err := db.Where("created_at > ?", since).FindToChannel(events).Error; err != nil {
+//			log.Println(err)
+//		}
+//	}()
+//
+//	for event := range events {
+//		process(event)
+//	}
+//
+// The rows are scanned while the channel is received, so the connection is busy until all the rows are received, the
+// find stops if the context is done. The error is returned, and could be received with ChannelErrors
+func (db *DB) FindToChannel(ch interface{}, opts ...ChannelOption) (tx *DB) {
+	var config channelConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	tx = db.getInstance()
+	chValue := reflect.ValueOf(ch)
+	if chValue.Kind() != reflect.Chan || chValue.Type().ChanDir()&reflect.SendDir == 0 {
+		tx.AddError(fmt.Errorf("%w: %T, which should be a channel to send", ErrInvalidData, ch))
+		sendChannelError(config, tx.Error)
+		return
+	}
+
+	defer func() {
+		sendChannelError(config, tx.Error)
+		chValue.Close()
+	}()
+
+	elemType := chValue.Type().Elem()
+	modelType := elemType
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	if modelType.Kind() != reflect.Struct {
+		tx.AddError(fmt.Errorf("%w: channel of %v, which should be a struct or a pointer to struct", ErrInvalidData, elemType))
+		return
+	}
+
+	sch, err := schema.Parse(reflect.New(modelType).Interface(), tx.cacheStore, tx.NamingStrategy)
+	if err != nil {
+		tx.AddError(err)
+		return
+	}
+
+	if tx.Statement.Model == nil {
+		tx.Statement.Model = reflect.New(modelType).Interface()
+	}
+
+	rows, err := tx.Rows()
+	if err != nil {
+		return
+	}
+	defer func() {
+		tx.AddError(rows.Close())
+	}()
+
+	var (
+		scanDB    = tx.Session(&Session{NewDB: true})
+		skipHooks = tx.Statement.SkipHooks || (tx.DryRun && !tx.DryRunWithHooks)
+		done      reflect.Value
+	)
+	if tx.Statement.Context != nil {
+		done = reflect.ValueOf(tx.Statement.Context.Done())
+	} else {
+		done = reflect.ValueOf((<-chan struct{})(nil))
+	}
+
+	tx.RowsAffected = 0
+	for rows.Next() {
+		elem := reflect.New(modelType)
+		if err := scanDB.ScanRows(rows, elem.Interface()); err != nil {
+			if interrupted := tx.Statement.Interrupted("find to channel", int(tx.RowsAffected)); interrupted != nil {
+				err = interrupted
+			}
+			tx.AddError(err)
+			return
+		}
+
+		if !skipHooks {
+			if err := callAfterFind(scanDB, sch, elem.Interface()); err != nil {
+				tx.AddError(err)
+				return
+			}
+		}
+
+		if elemType.Kind() != reflect.Ptr {
+			elem = elem.Elem()
+		}
+
+		// blocks until the row is received or the context is done
+		chosen, _, _ := reflect.Select([]reflect.SelectCase{
+			{Dir: reflect.SelectSend, Chan: chValue, Send: elem},
+			{Dir: reflect.SelectRecv, Chan: done},
+		})
+		if chosen == 1 {
+			tx.AddError(tx.Statement.Interrupted("find to channel", int(tx.RowsAffected)))
+			return
+		}
+		tx.RowsAffected++
+	}
+
+	if err := rows.Err(); err != nil {
+		if interrupted := tx.Statement.Interrupted("find to channel", int(tx.RowsAffected)); interrupted != nil {
+			err = interrupted
+		}
+		tx.AddError(err)
+	}
+	return
+}
+
+// callAfterFind calls the AfterFind hook method and the AfterFind of the model hooks with the found model of sch
+func callAfterFind(tx *DB, sch *schema.Schema, model interface{}) error {
+	if sch.AfterFind {
+		if i, ok := model.(interface{ AfterFind(*DB) error }); ok {
+			if err := i.AfterFind(tx); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, hook := range tx.ModelHooks {
+		if hook.AfterFind != nil && hook.Match(sch, model) {
+			if err := hook.AfterFind(tx, model); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func sendChannelError(config channelConfig, err error) {
+	if err != nil && config.errs != nil {
+		select {
+		case config.errs <- err:
+		default:
+		}
+	}
+}
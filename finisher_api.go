@@ -1,12 +1,17 @@
 package gorm
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"hash/maphash"
 	"reflect"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
@@ -16,13 +21,85 @@ import (
 
 // Create inserts value, returning the inserted data's primary key in value's id
 func (db *DB) Create(value interface{}) (tx *DB) {
-	if db.CreateBatchSize > 0 {
-		return db.CreateInBatches(value, db.CreateBatchSize)
+	if batchSize := db.createBatchSize(value); batchSize > 0 {
+		return db.CreateInBatches(value, batchSize)
+	}
+
+	if db.GroupMapsByKeys {
+		if groups := groupMapsByKeys(value); len(groups) > 1 {
+			return db.createMapGroups(groups)
+		}
 	}
 
 	tx = db.getInstance()
 	tx.Statement.Dest = value
-	return tx.callbacks.Create().Execute(tx)
+	return db.releaseStatement(tx.callbacks.Create().Execute(tx))
+}
+
+// groupMapsByKeys groups the slice of maps by their sorted keys in the order of their first maps
+func groupMapsByKeys(value interface{}) (groups [][]map[string]interface{}) {
+	var mapValues []map[string]interface{}
+	switch v := value.(type) {
+	case []map[string]interface{}:
+		mapValues = v
+	case *[]map[string]interface{}:
+		mapValues = *v
+	default:
+		return nil
+	}
+
+	indexes := map[string]int{}
+	for _, mapValue := range mapValues {
+		keys := make([]string, 0, len(mapValue))
+		for k := range mapValue {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		signature := strings.Join(keys, "\x00")
+		if idx, ok := indexes[signature]; ok {
+			groups[idx] = append(groups[idx], mapValue)
+		} else {
+			indexes[signature] = len(groups)
+			groups = append(groups, []map[string]interface{}{mapValue})
+		}
+	}
+	return groups
+}
+
+// createMapGroups creates the groups of maps with one INSERT for every group in a transaction
+func (db *DB) createMapGroups(groups [][]map[string]interface{}) (tx *DB) {
+	var rowsAffected int64
+	tx = db.getInstance()
+
+	createFc := func(tx *DB) error {
+		for idx := range groups {
+			result := tx.Session(&Session{}).Create(&groups[idx])
+			if result.Error != nil {
+				return result.Error
+			}
+			rowsAffected += result.RowsAffected
+		}
+		return nil
+	}
+
+	if tx.SkipDefaultTransaction {
+		tx.AddError(createFc(tx.Session(&Session{})))
+	} else {
+		tx.AddError(tx.Transaction(createFc))
+	}
+
+	tx.RowsAffected = rowsAffected
+	return tx
+}
+
+// releaseStatement puts the statement of the finished tx back to the pool if it is created by the finisher called from
+// a new DB, e.g: Session{NewDB: true}, WithContext, so it can't be referenced by other chains
+func (db *DB) releaseStatement(tx *DB) *DB {
+	if db.clone > 0 && tx.Statement.pooled && tx.Statement.DB == tx && !tx.DryRun {
+		tx.Statement = tx.Statement.release()
+	}
+	return tx
 }
 
 // CreateInBatches inserts value in batches of batchSize
@@ -31,7 +108,11 @@ func (db *DB) CreateInBatches(value interface{}, batchSize int) (tx *DB) {
 
 	switch reflectValue.Kind() {
 	case reflect.Slice, reflect.Array:
-		var rowsAffected int64
+		var (
+			rowsAffected int64
+			batchErr     *BatchError
+			upsert       *UpsertResult
+		)
 		tx = db.getInstance()
 
 		// the reflection length judgment of the optimized value
@@ -39,6 +120,12 @@ func (db *DB) CreateInBatches(value interface{}, batchSize int) (tx *DB) {
 
 		callFc := func(tx *DB) error {
 			for i := 0; i < reflectLen; i += batchSize {
+				if i > 0 {
+					if err := tx.Statement.Interrupted("create in batches", i/batchSize); err != nil {
+						return err
+					}
+				}
+
 				ends := i + batchSize
 				if ends > reflectLen {
 					ends = reflectLen
@@ -47,10 +134,24 @@ func (db *DB) CreateInBatches(value interface{}, batchSize int) (tx *DB) {
 				subtx := tx.getInstance()
 				subtx.Statement.Dest = reflectValue.Slice(i, ends).Interface()
 				subtx.callbacks.Create().Execute(subtx)
-				if subtx.Error != nil {
+				if err, ok := subtx.Error.(*BatchError); ok {
+					// failed rows are excluded in PartialBatch mode, continue with the next batch
+					if batchErr == nil {
+						batchErr = &BatchError{Errors: map[int]error{}}
+					}
+					for idx, e := range err.Errors {
+						batchErr.Errors[i+idx] = e
+					}
+				} else if subtx.Error != nil {
 					return subtx.Error
 				}
 				rowsAffected += subtx.RowsAffected
+				if result := subtx.Statement.Upsert; result != nil {
+					if upsert == nil {
+						upsert = &UpsertResult{Known: true}
+					}
+					*upsert = upsert.add(*result)
+				}
 			}
 			return nil
 		}
@@ -62,6 +163,11 @@ func (db *DB) CreateInBatches(value interface{}, batchSize int) (tx *DB) {
 		}
 
 		tx.RowsAffected = rowsAffected
+		tx.Statement.Upsert = upsert
+		if batchErr != nil && tx.Error == nil {
+			batchErr.RowsAffected = rowsAffected
+			tx.AddError(batchErr)
+		}
 	default:
 		tx = db.getInstance()
 		tx.Statement.Dest = value
@@ -70,11 +176,163 @@ func (db *DB) CreateInBatches(value interface{}, batchSize int) (tx *DB) {
 	return
 }
 
+// createFromQueryKey the setting of the statements created by CreateFromQuery
+const createFromQueryKey = "gorm:create_from_query"
+
+// CreateFromQuery inserts the rows selected by src into the columns of the model with INSERT ... SELECT, the columns
+// should match the select list of src, the rows are created by the database, so the create hooks of the model are
+// called once with the model instead of the rows, and could be checked with Statement.CreatingFromQuery, e.g:
+//
+//	db.Model(&ArchiveOrder{}).Clauses(clause.OnConflict{DoNothing: true}).CreateFromQuery(
+//		[]string{"id", "amount"}, db.Model(&Order{}).Select("id", "amount").Where("created_at < ?", archivedAt))
+func (db *DB) CreateFromQuery(columns []string, src *DB) (tx *DB) {
+	tx = db.getInstance()
+	if tx.Statement.Model == nil {
+		tx.AddError(ErrModelValueRequired)
+		return
+	}
+
+	if src == nil {
+		tx.AddError(ErrSubQueryRequired)
+		return
+	}
+
+	if len(columns) == 0 {
+		tx.AddError(fmt.Errorf("%w: columns of the created rows required", ErrInvalidData))
+		return
+	}
+
+	if err := tx.Statement.Parse(tx.Statement.Model); err != nil {
+		tx.AddError(err)
+		return
+	}
+
+	if selected, ok := selectListLen(src.Statement); ok && selected != len(columns) {
+		tx.AddError(fmt.Errorf("%w: %d columns, but %d columns selected", ErrInvalidData, len(columns), selected))
+		return
+	}
+
+	values := make([]interface{}, 0, len(columns))
+	for _, column := range columns {
+		field := tx.Statement.Schema.LookUpField(column)
+		if field == nil || field.DBName == "" {
+			tx.AddError(fmt.Errorf("%w: %s isn't a field of %s", ErrInvalidField, column, tx.Statement.Schema.Name))
+			return
+		}
+		values = append(values, clause.Column{Name: field.DBName})
+	}
+
+	// SQLite parses the ON of the upsert as the join constraint of a SELECT without WHERE
+	if _, ok := tx.Statement.Clauses["ON CONFLICT"]; ok {
+		if _, ok := src.Statement.Clauses["WHERE"]; !ok {
+			src = src.Session(&Session{}).Where("1 = 1")
+		}
+	}
+
+	tx.Statement.Clauses["VALUES"] = clause.Clause{Expression: clause.Expr{SQL: "? ?", Vars: []interface{}{values, src}}}
+	tx.Statement.Settings.Store(createFromQueryKey, true)
+	tx.Statement.Dest = tx.Statement.Model
+	return tx.callbacks.Create().Execute(tx)
+}
+
+// selectListLen returns the length of the select list of stmt, returns false if it is unknown, e.g: SELECT *
+func selectListLen(stmt *Statement) (int, bool) {
+	if c, ok := stmt.Clauses["SELECT"]; ok && c.Expression != nil {
+		switch v := c.Expression.(type) {
+		case clause.Select:
+			if v.Expression == nil {
+				return len(v.Columns), len(v.Columns) > 0
+			}
+			return selectListLen(&Statement{Clauses: map[string]clause.Clause{"SELECT": {Expression: v.Expression}}})
+		case clause.Expr:
+			return sqlListLen(v.SQL)
+		case clause.NamedExpr:
+			return sqlListLen(v.SQL)
+		}
+		return 0, false
+	}
+
+	var length int
+	for _, s := range stmt.Selects {
+		n, ok := sqlListLen(s)
+		if !ok {
+			return 0, false
+		}
+		length += n
+	}
+	return length, length > 0
+}
+
+// sqlListLen returns the number of the comma separated items of sql, returns false if any item is *
+func sqlListLen(sql string) (int, bool) {
+	var (
+		length, depth, start int
+		quote                byte
+	)
+
+	for idx := 0; idx <= len(sql); idx++ {
+		if idx < len(sql) {
+			switch c := sql[idx]; {
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+				continue
+			case c == '\'' || c == '"' || c == '`':
+				quote = c
+				continue
+			case c == '(':
+				depth++
+				continue
+			case c == ')':
+				depth--
+				continue
+			case c != ',' || depth > 0:
+				continue
+			}
+		}
+
+		item := strings.TrimSpace(sql[start:idx])
+		if item == "*" || strings.HasSuffix(item, ".*") {
+			return 0, false
+		}
+		length++
+		start = idx + 1
+	}
+	return length, true
+}
+
+// SaveMode how Save determines whether to update or create the record with primary keys
+type SaveMode string
+
+const (
+	// SaveByUpdate updates the record, and creates it if no rows are affected, which might misfire for the drivers
+	// reporting 0 affected rows when the updated values are not changed
+	SaveByUpdate SaveMode = ""
+	// SaveByQuery queries whether the record exists by its primary keys with the scopes of the model first, e.g: soft
+	// delete and tenant, then updates or creates it in the same transaction, the record not updated is upserted
+	SaveByQuery SaveMode = "query"
+	// SaveByUpsert creates the record with ON CONFLICT updating all columns if CapabilityOnConflict is supported, or
+	// saves it like SaveByQuery
+	SaveByUpsert SaveMode = "upsert"
+)
+
+// savePathKey the setting of the path executed by Save, which is "create", "update" or "upsert"
+const savePathKey = "gorm:save_path"
+
 // Save updates value in database. If value doesn't contain a matching primary key, value is inserted.
+// How it is determined depends on Config.SaveMode, the executed path is set to the "gorm:save_path" setting of the
+// returned db, only the hooks of the executed path are called. Only the changed fields of the tracked models are
+// updated, see ChangedFields. The models holding the redacted values return ErrRedactedModel, see RegisterRedactor
 func (db *DB) Save(value interface{}) (tx *DB) {
 	tx = db.getInstance()
 	tx.Statement.Dest = value
 
+	if err := tx.checkRedacted(value); err != nil {
+		tx.AddError(err)
+		return
+	}
+
 	reflectValue := reflect.Indirect(reflect.ValueOf(value))
 	for reflectValue.Kind() == reflect.Ptr || reflectValue.Kind() == reflect.Interface {
 		reflectValue = reflect.Indirect(reflectValue)
@@ -86,77 +344,243 @@ func (db *DB) Save(value interface{}) (tx *DB) {
 			tx = tx.Clauses(clause.OnConflict{UpdateAll: true})
 		}
 		tx = tx.callbacks.Create().Execute(tx.Set("gorm:update_track_time", true))
+		tx.Statement.Settings.Store(savePathKey, "upsert")
 	case reflect.Struct:
 		if err := tx.Statement.Parse(value); err == nil && tx.Statement.Schema != nil {
 			for _, pf := range tx.Statement.Schema.PrimaryFields {
 				if _, isZero := pf.ValueOf(tx.Statement.Context, reflectValue); isZero {
-					return tx.callbacks.Create().Execute(tx)
+					return savedBy(tx.callbacks.Create().Execute(tx), "create")
 				}
 			}
+
+			switch tx.SaveMode {
+			case SaveByUpsert:
+				if tx.Supports(CapabilityOnConflict) {
+					if _, ok := tx.Statement.Clauses["ON CONFLICT"]; !ok {
+						tx = tx.Clauses(clause.OnConflict{UpdateAll: true})
+					}
+					return savedBy(tx.callbacks.Create().Execute(tx.Set("gorm:update_track_time", true)), "upsert")
+				}
+				fallthrough
+			case SaveByQuery:
+				return tx.saveByQuery(value)
+			}
 		}
 
-		fallthrough
+		return tx.saveUpdate(value, true, tx.SaveMode == SaveByUpdate)
 	default:
-		selectedUpdate := len(tx.Statement.Selects) != 0
-		// when updating, use all fields including those zero-value fields
-		if !selectedUpdate {
-			tx.Statement.Selects = append(tx.Statement.Selects, "*")
+		return tx.saveUpdate(value, false, tx.SaveMode == SaveByUpdate)
+	}
+
+	return
+}
+
+// saveUpdate updates the record of value by Save, only the changed fields of the tracked struct are updated, the record
+// not updated is created if createMissing
+func (tx *DB) saveUpdate(value interface{}, isStruct bool, createMissing bool) *DB {
+	// update the changed fields of the tracked model only, see ChangedFields
+	if isStruct && len(tx.Statement.Selects) == 0 && len(tx.Statement.Omits) == 0 {
+		if fields, tracked := tx.changedFields(value); tracked {
+			return savedBy(tx.updateChanges(fields), "update")
 		}
+	}
+
+	selectedUpdate := len(tx.Statement.Selects) != 0
+	// when updating, use all fields including those zero-value fields
+	if !selectedUpdate {
+		tx.Statement.Selects = append(tx.Statement.Selects, "*")
+	}
+
+	updateTx := tx.callbacks.Update().Execute(tx.Session(&Session{Initialized: true}))
+
+	if updateTx.Error == nil && updateTx.RowsAffected == 0 && !updateTx.DryRun && !selectedUpdate && createMissing {
+		return savedBy(tx.Session(&Session{SkipHooks: true}).Clauses(clause.OnConflict{UpdateAll: true}).Create(value), "create")
+	}
 
-		updateTx := tx.callbacks.Update().Execute(tx.Session(&Session{Initialized: true}))
+	return savedBy(updateTx, "update")
+}
 
-		if updateTx.Error == nil && updateTx.RowsAffected == 0 && !updateTx.DryRun && !selectedUpdate {
-			return tx.Session(&Session{SkipHooks: true}).Clauses(clause.OnConflict{UpdateAll: true}).Create(value)
+// saveByQuery saves the struct value by SaveByQuery, the query of its primary keys and the write run in one transaction
+// if the statement isn't in one, the record not updated, e.g: deleted concurrently, is upserted
+func (tx *DB) saveByQuery(value interface{}) *DB {
+	var (
+		saveTx   *DB
+		connPool = tx.Statement.ConnPool
+	)
+
+	if _, ok := connPool.(TxCommitter); !ok && !tx.DryRun {
+		if saveTx = tx.Session(&Session{NewDB: true}).Begin(); saveTx.Error != nil {
+			if saveTx.Error != ErrInvalidTransaction {
+				tx.AddError(saveTx.Error)
+				return tx
+			}
+			saveTx = nil
+		} else {
+			tx.Statement.ConnPool = saveTx.Statement.ConnPool
 		}
+	}
 
-		return updateTx
+	result := tx
+	if exists, err := tx.primaryKeysExist(value); err != nil {
+		tx.AddError(err)
+	} else if !exists {
+		result = savedBy(tx.callbacks.Create().Execute(tx), "create")
+	} else {
+		result = tx.saveUpdate(value, true, true)
 	}
 
-	return
+	if saveTx != nil {
+		if result.Error != nil {
+			saveTx.Rollback()
+		} else {
+			result.AddError(saveTx.Commit().Error)
+		}
+		tx.Statement.ConnPool = connPool
+		result.Statement.ConnPool = connPool
+	}
+	return result
+}
+
+// savedBy sets the path executed by Save to the setting of tx, and releases the snapshot of the saved model
+func savedBy(tx *DB, path string) *DB {
+	tx.Statement.Settings.Store(savePathKey, path)
+	if tx.Error == nil && !tx.DryRun {
+		tx.Statement.releaseChanges()
+	}
+	return tx
+}
+
+// primaryKeysExist queries whether the record of the primary keys of value exists in the table of the statement, the
+// query is restricted by the scopes of the model like the update, e.g: soft delete and tenant
+func (db *DB) primaryKeysExist(value interface{}) (bool, error) {
+	reflectValue := reflect.Indirect(reflect.ValueOf(value))
+	conds := make([]clause.Expression, 0, len(db.Statement.Schema.PrimaryFields))
+	for _, pf := range db.Statement.Schema.PrimaryFields {
+		pv, _ := pf.ValueOf(db.Statement.Context, reflectValue)
+		conds = append(conds, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: pf.DBName}, Value: pv})
+	}
+
+	queryTx := db.Session(&Session{NewDB: true, SkipHooks: true}).Model(value)
+	queryTx.Statement.Table = db.Statement.Table
+	queryTx.Statement.TableExpr = db.Statement.TableExpr
+	queryTx.Statement.Unscoped = db.Statement.Unscoped
+
+	var count int64
+	err := queryTx.Where(clause.And(conds...)).Limit(1).Count(&count).Error
+	return count > 0, err
 }
 
 // First finds the first record ordered by primary key, matching given conditions conds
 func (db *DB) First(dest interface{}, conds ...interface{}) (tx *DB) {
-	tx = db.Limit(1).Order(clause.OrderByColumn{
-		Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey},
-	})
-	if len(conds) > 0 {
-		if exprs := tx.Statement.BuildCondition(conds[0], conds[1:]...); len(exprs) > 0 {
-			tx.Statement.AddClause(clause.Where{Exprs: exprs})
-		}
-	}
-	tx.Statement.RaiseErrorOnNotFound = true
-	tx.Statement.Dest = dest
-	return tx.callbacks.Query().Execute(tx)
+	return db.releaseStatement(db.first(dest, conds, true))
 }
 
 // Take finds the first record returned by the database in no specified order, matching given conditions conds
 func (db *DB) Take(dest interface{}, conds ...interface{}) (tx *DB) {
-	tx = db.Limit(1)
-	if len(conds) > 0 {
-		if exprs := tx.Statement.BuildCondition(conds[0], conds[1:]...); len(exprs) > 0 {
-			tx.Statement.AddClause(clause.Where{Exprs: exprs})
-		}
-	}
-	tx.Statement.RaiseErrorOnNotFound = true
-	tx.Statement.Dest = dest
-	return tx.callbacks.Query().Execute(tx)
+	return db.releaseStatement(db.take(dest, conds, true))
 }
 
 // Last finds the last record ordered by primary key, matching given conditions conds
 func (db *DB) Last(dest interface{}, conds ...interface{}) (tx *DB) {
+	return db.releaseStatement(db.last(dest, conds, true))
+}
+
+// FirstBy finds the first record ordered by column instead of primary key, matching given conditions conds, column
+// should be a field or a column of the model, or a qualified column of the joined tables, e.g:
+//
+//	db.Where("user_id = ?", userID).FirstBy("CreatedAt", &order)
+//
+// The records of the same column are ordered by primary key, the NULL values are ordered by the database, e.g: MySQL
+// orders them first, but Postgres orders them last
+func (db *DB) FirstBy(column string, dest interface{}, conds ...interface{}) (tx *DB) {
+	return db.releaseStatement(db.firstBy(column, false, dest, conds))
+}
+
+// LastBy finds the last record ordered by column instead of primary key, matching given conditions conds, see FirstBy
+func (db *DB) LastBy(column string, dest interface{}, conds ...interface{}) (tx *DB) {
+	return db.releaseStatement(db.firstBy(column, true, dest, conds))
+}
+
+func (db *DB) firstBy(column string, desc bool, dest interface{}, conds []interface{}) (tx *DB) {
+	tx = db.getInstance()
+	model := tx.Statement.Model
+	if model == nil {
+		model = dest
+	}
+	if err := tx.Statement.Parse(model); err != nil {
+		tx.AddError(err)
+		return
+	}
+
+	orderColumn, ok := tx.Statement.lookUpColumn(column)
+	if !ok {
+		return
+	}
+
+	tx = tx.Limit(1).Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: orderColumn, Desc: desc},
+		{Column: clause.PrimaryColumn, Desc: desc},
+	}})
+	return tx.findOne(dest, conds, true)
+}
+
+// TryFirst finds the first record ordered by primary key like First, but reports whether it is found instead of
+// returning ErrRecordNotFound, the error isn't added to db, so it can still be chained
+func (db *DB) TryFirst(dest interface{}, conds ...interface{}) (found bool, err error) {
+	return db.try(func(tx *DB) *DB { return tx.first(dest, conds, false) })
+}
+
+// TryTake finds the first record in no specified order like Take, but reports whether it is found instead of
+// returning ErrRecordNotFound, the error isn't added to db, so it can still be chained
+func (db *DB) TryTake(dest interface{}, conds ...interface{}) (found bool, err error) {
+	return db.try(func(tx *DB) *DB { return tx.take(dest, conds, false) })
+}
+
+// TryLast finds the last record ordered by primary key like Last, but reports whether it is found instead of
+// returning ErrRecordNotFound, the error isn't added to db, so it can still be chained
+func (db *DB) TryLast(dest interface{}, conds ...interface{}) (found bool, err error) {
+	return db.try(func(tx *DB) *DB { return tx.last(dest, conds, false) })
+}
+
+func (db *DB) try(fc func(tx *DB) *DB) (found bool, err error) {
+	session := db.Session(&Session{})
+	tx := fc(session)
+	found, err = tx.Error == nil && tx.RowsAffected > 0, tx.Error
+	if db.clone == 0 {
+		db.RowsAffected = tx.RowsAffected
+	}
+	session.releaseStatement(tx)
+	return
+}
+
+func (db *DB) first(dest interface{}, conds []interface{}, raiseErrorOnNotFound bool) (tx *DB) {
+	tx = db.Limit(1).Order(clause.OrderByColumn{
+		Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey},
+	})
+	return tx.findOne(dest, conds, raiseErrorOnNotFound)
+}
+
+func (db *DB) take(dest interface{}, conds []interface{}, raiseErrorOnNotFound bool) (tx *DB) {
+	return db.Limit(1).findOne(dest, conds, raiseErrorOnNotFound)
+}
+
+func (db *DB) last(dest interface{}, conds []interface{}, raiseErrorOnNotFound bool) (tx *DB) {
 	tx = db.Limit(1).Order(clause.OrderByColumn{
 		Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey},
 		Desc:   true,
 	})
+	return tx.findOne(dest, conds, raiseErrorOnNotFound)
+}
+
+func (db *DB) findOne(dest interface{}, conds []interface{}, raiseErrorOnNotFound bool) *DB {
 	if len(conds) > 0 {
-		if exprs := tx.Statement.BuildCondition(conds[0], conds[1:]...); len(exprs) > 0 {
-			tx.Statement.AddClause(clause.Where{Exprs: exprs})
+		if exprs := db.Statement.BuildCondition(conds[0], conds[1:]...); len(exprs) > 0 {
+			db.Statement.AddClause(clause.Where{Exprs: exprs})
 		}
 	}
-	tx.Statement.RaiseErrorOnNotFound = true
-	tx.Statement.Dest = dest
-	return tx.callbacks.Query().Execute(tx)
+	db.Statement.RaiseErrorOnNotFound = raiseErrorOnNotFound
+	db.Statement.Dest = dest
+	return db.callbacks.Query().Execute(db)
 }
 
 // Find finds all records matching given conditions conds
@@ -168,7 +592,51 @@ func (db *DB) Find(dest interface{}, conds ...interface{}) (tx *DB) {
 		}
 	}
 	tx.Statement.Dest = dest
-	return tx.callbacks.Query().Execute(tx)
+	return db.releaseStatement(tx.callbacks.Query().Execute(tx))
+}
+
+// LoadAssociations loads the associations of the values of Model, which have been loaded, like Preload without
+// querying the values again, e.g: loads the associations after inspecting the values. The nested associations like
+// "Orders.Items", clause.Associations and the conditions work like Preload, the associated values of all the values
+// are queried with one IN query of their keys, and assigned back to them by the keys
+//
+//	db.Model(&users).LoadAssociations("Orders", "state = ?", "paid")
+//	db.Model(&users).LoadAssociations("Orders.Items")
+func (db *DB) LoadAssociations(query string, args ...interface{}) (tx *DB) {
+	tx = db.getInstance()
+	preload := tx.callbacks.Query().Get("gorm:preload")
+	if preload == nil {
+		tx.AddError(fmt.Errorf("%w: callback gorm:preload isn't registered", ErrNotImplemented))
+		return
+	}
+
+	if tx.Statement.Model == nil {
+		tx.AddError(ErrModelValueRequired)
+		return
+	}
+
+	reflectValue := reflect.ValueOf(tx.Statement.Model)
+	if reflectValue.Kind() != reflect.Ptr || reflectValue.IsNil() {
+		tx.AddError(ErrInvalidValue)
+		return
+	}
+
+	for reflectValue.Kind() == reflect.Ptr {
+		reflectValue = reflectValue.Elem()
+	}
+
+	if err := tx.Statement.Parse(tx.Statement.Model); err != nil {
+		tx.AddError(err)
+		return
+	}
+
+	// only the associations of the query are loaded, the preloads of the statement are kept for its queries
+	preloads := tx.Statement.Preloads
+	tx.Statement.Preloads = map[string][]interface{}{query: args}
+	tx.Statement.Dest, tx.Statement.ReflectValue = tx.Statement.Model, reflectValue
+	preload(tx)
+	tx.Statement.Preloads = preloads
+	return
 }
 
 // FindInBatches finds all records in batches of batchSize
@@ -200,6 +668,13 @@ func (db *DB) FindInBatches(dest interface{}, batchSize int, fc func(tx *DB, bat
 	}
 
 	for {
+		if batch > 0 {
+			if err := tx.Statement.Interrupted("find in batches", batch); err != nil {
+				tx.AddError(err)
+				break
+			}
+		}
+
 		result := queryDB.Limit(batchSize).Find(dest)
 		rowsAffected += result.RowsAffected
 		batch++
@@ -244,20 +719,72 @@ func (db *DB) FindInBatches(dest interface{}, batchSize int, fc func(tx *DB, bat
 	return tx
 }
 
+// DeleteInBatches deletes the records matching the conditions in batches of batchSize, until a batch deletes less
+// records than batchSize, fc is called after each batch with the records affected by the batch if it isn't nil, e.g:
+//
+//	db.Where("created_at < ?", expiredAt).Order("created_at").DeleteInBatches(&Event{}, 10000, func(tx *gorm.DB, batch int) error {
+//		log.Printf("batch %d deleted %d events", batch, tx.RowsAffected)
+//		return nil
+//	})
+func (db *DB) DeleteInBatches(value interface{}, batchSize int, fc func(tx *DB, batch int) error) *DB {
+	var (
+		tx           = db.Session(&Session{})
+		rowsAffected int64
+		batch        int
+	)
+
+	if batchSize <= 0 {
+		tx.AddError(fmt.Errorf("%w: invalid batch size %d", ErrInvalidData, batchSize))
+		return tx
+	}
+
+	for {
+		if batch > 0 {
+			if err := tx.Statement.Interrupted("delete in batches", batch); err != nil {
+				tx.AddError(err)
+				break
+			}
+		}
+
+		result := tx.Limit(batchSize).Delete(value)
+		rowsAffected += result.RowsAffected
+		batch++
+
+		if result.Error != nil {
+			tx.AddError(result.Error)
+		} else if fc != nil && result.RowsAffected != 0 {
+			fcTx := result.Session(&Session{NewDB: true})
+			fcTx.RowsAffected = result.RowsAffected
+			tx.AddError(fc(fcTx, batch))
+		}
+
+		if tx.Error != nil || int(result.RowsAffected) < batchSize {
+			break
+		}
+	}
+
+	tx.RowsAffected = rowsAffected
+	return tx
+}
+
 func (db *DB) assignInterfacesToValue(values ...interface{}) {
 	for _, value := range values {
 		switch v := value.(type) {
 		case []clause.Expression:
 			for _, expr := range v {
 				if eq, ok := expr.(clause.Eq); ok {
+					if collate, ok := eq.Column.(clause.Collate); ok {
+						eq.Column = collate.Column
+					}
+
 					switch column := eq.Column.(type) {
 					case string:
 						if field := db.Statement.Schema.LookUpField(column); field != nil {
-							db.AddError(field.Set(db.Statement.Context, db.Statement.ReflectValue, eq.Value))
+							db.AddError(db.Statement.setField(field, db.Statement.ReflectValue, conditionValue(eq.Value)))
 						}
 					case clause.Column:
 						if field := db.Statement.Schema.LookUpField(column.Name); field != nil {
-							db.AddError(field.Set(db.Statement.Context, db.Statement.ReflectValue, eq.Value))
+							db.AddError(db.Statement.setField(field, db.Statement.ReflectValue, conditionValue(eq.Value)))
 						}
 					}
 				} else if andCond, ok := expr.(clause.AndConditions); ok {
@@ -277,7 +804,7 @@ func (db *DB) assignInterfacesToValue(values ...interface{}) {
 						if f.Readable {
 							if v, isZero := f.ValueOf(db.Statement.Context, reflectValue); !isZero {
 								if field := db.Statement.Schema.LookUpField(f.Name); field != nil {
-									db.AddError(field.Set(db.Statement.Context, db.Statement.ReflectValue, v))
+									db.AddError(db.Statement.setField(field, db.Statement.ReflectValue, v))
 								}
 							}
 						}
@@ -309,6 +836,9 @@ func (db *DB) FirstOrInit(dest interface{}, conds ...interface{}) (tx *DB) {
 	queryTx := db.Limit(1).Order(clause.OrderByColumn{
 		Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey},
 	})
+	if db.Statement.softDeleted != SoftDeletedExcluded {
+		queryTx = queryTx.unscopeSoftDeleted(dest)
+	}
 
 	if tx = queryTx.Find(dest, conds...); tx.RowsAffected == 0 {
 		if c, ok := tx.Statement.Clauses["WHERE"]; ok {
@@ -344,11 +874,33 @@ func (db *DB) FirstOrInit(dest interface{}, conds ...interface{}) (tx *DB) {
 //	result := db.Where(User{Name: "jinzhu"}).Assign(User{Email: "fake@fake.org"}).FirstOrCreate(&user)
 //	// user -> User{Name: "jinzhu", Age: 20, Email: "fake@fake.org"}
 //	// result.RowsAffected -> 1
+//
+// The soft deleted rows are looked up, restored or kept soft deleted by WithSoftDeleted
 func (db *DB) FirstOrCreate(dest interface{}, conds ...interface{}) (tx *DB) {
+	if db.Statement.softDeleted == SoftDeletedExcluded || db.SkipDefaultTransaction || db.DryRun {
+		return db.firstOrCreate(dest, conds...)
+	}
+
+	// the row is looked up and created or restored in a transaction
+	tx = db.getInstance()
+	tx.AddError(tx.Transaction(func(txDB *DB) error {
+		txDB.Statement.attrs, txDB.Statement.assigns = db.Statement.attrs, db.Statement.assigns
+		txDB.Statement.softDeleted = db.Statement.softDeleted
+		result := txDB.firstOrCreate(dest, conds...)
+		tx.RowsAffected = result.RowsAffected
+		return result.Error
+	}))
+	return tx
+}
+
+func (db *DB) firstOrCreate(dest interface{}, conds ...interface{}) (tx *DB) {
 	tx = db.getInstance()
 	queryTx := db.Session(&Session{}).Limit(1).Order(clause.OrderByColumn{
 		Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey},
 	})
+	if db.Statement.softDeleted != SoftDeletedExcluded {
+		queryTx = queryTx.unscopeSoftDeleted(dest)
+	}
 
 	result := queryTx.Find(dest, conds...)
 	if result.Error != nil {
@@ -374,7 +926,22 @@ func (db *DB) FirstOrCreate(dest interface{}, conds ...interface{}) (tx *DB) {
 		}
 
 		return tx.Create(dest)
-	} else if len(db.Statement.assigns) > 0 {
+	}
+
+	if result.Statement.isSoftDeleted(dest) {
+		if db.Statement.softDeleted == RestoreOnConflict {
+			restored := tx.Session(&Session{NewDB: true}).Restore(dest)
+			if restored.Error != nil || len(db.Statement.assigns) == 0 {
+				tx.Error, tx.RowsAffected = restored.Error, restored.RowsAffected
+				return tx
+			}
+		} else {
+			// the found row is updated with Assign, which is kept soft deleted
+			tx = tx.unscopeSoftDeleted(dest)
+		}
+	}
+
+	if len(db.Statement.assigns) > 0 {
 		exprs := tx.Statement.BuildCondition(db.Statement.assigns[0], db.Statement.assigns[1:]...)
 		assigns := map[string]interface{}{}
 		for i := 0; i < len(exprs); i++ {
@@ -383,11 +950,15 @@ func (db *DB) FirstOrCreate(dest interface{}, conds ...interface{}) (tx *DB) {
 			if eq, ok := expr.(clause.AndConditions); ok {
 				exprs = append(exprs, eq.Exprs...)
 			} else if eq, ok := expr.(clause.Eq); ok {
+				if collate, ok := eq.Column.(clause.Collate); ok {
+					eq.Column = collate.Column
+				}
+
 				switch column := eq.Column.(type) {
 				case string:
-					assigns[column] = eq.Value
+					assigns[column] = conditionValue(eq.Value)
 				case clause.Column:
-					assigns[column.Name] = eq.Value
+					assigns[column.Name] = conditionValue(eq.Value)
 				}
 			}
 		}
@@ -402,28 +973,55 @@ func (db *DB) FirstOrCreate(dest interface{}, conds ...interface{}) (tx *DB) {
 func (db *DB) Update(column string, value interface{}) (tx *DB) {
 	tx = db.getInstance()
 	tx.Statement.Dest = map[string]interface{}{column: value}
-	return tx.callbacks.Update().Execute(tx)
+	return db.releaseStatement(tx.callbacks.Update().Execute(tx))
 }
 
 // Updates updates attributes using callbacks. values must be a struct or map. Reference: https://gorm.io/docs/update.html#Update-Changed-Fields
+//
+// The columns of a map are assigned in the order of their sorted keys, and the columns of a struct in the order of its
+// fields, so the same values are always built into the same SQL
 func (db *DB) Updates(values interface{}) (tx *DB) {
 	tx = db.getInstance()
 	tx.Statement.Dest = values
-	return tx.callbacks.Update().Execute(tx)
+
+	if err := tx.checkRedactedModels(values); err != nil {
+		tx.AddError(err)
+		return
+	}
+
+	// update the changed fields of the tracked model only, including the fields changed to zero values
+	if (tx.Statement.Model == nil || tx.Statement.Model == values) && len(tx.Statement.Selects) == 0 && len(tx.Statement.Omits) == 0 {
+		if fields, tracked := tx.changedFields(values); tracked {
+			if tx = tx.updateChanges(fields); tx.Error == nil && !tx.DryRun {
+				tx.Statement.releaseChanges()
+			}
+			return db.releaseStatement(tx)
+		}
+	}
+
+	if tx = tx.callbacks.Update().Execute(tx); tx.Error == nil && !tx.DryRun {
+		tx.Statement.releaseChanges()
+	}
+	return db.releaseStatement(tx)
 }
 
 func (db *DB) UpdateColumn(column string, value interface{}) (tx *DB) {
 	tx = db.getInstance()
 	tx.Statement.Dest = map[string]interface{}{column: value}
 	tx.Statement.SkipHooks = true
-	return tx.callbacks.Update().Execute(tx)
+	return db.releaseStatement(tx.callbacks.Update().Execute(tx))
 }
 
 func (db *DB) UpdateColumns(values interface{}) (tx *DB) {
 	tx = db.getInstance()
 	tx.Statement.Dest = values
 	tx.Statement.SkipHooks = true
-	return tx.callbacks.Update().Execute(tx)
+
+	if err := tx.checkRedactedModels(values); err != nil {
+		tx.AddError(err)
+		return
+	}
+	return db.releaseStatement(tx.callbacks.Update().Execute(tx))
 }
 
 // Delete deletes value matching given conditions. If value contains primary key it is included in the conditions. If
@@ -437,7 +1035,7 @@ func (db *DB) Delete(value interface{}, conds ...interface{}) (tx *DB) {
 		}
 	}
 	tx.Statement.Dest = value
-	return tx.callbacks.Delete().Execute(tx)
+	return db.releaseStatement(tx.callbacks.Delete().Execute(tx))
 }
 
 func (db *DB) Count(count *int64) (tx *DB) {
@@ -457,6 +1055,14 @@ func (db *DB) Count(count *int64) (tx *DB) {
 		defer delete(tx.Statement.Clauses, "SELECT")
 	}
 
+	// the aliases referenced by HAVING are replaced with the selected columns
+	if groupByClause, ok := db.Statement.Clauses["GROUP BY"]; ok {
+		defer func() {
+			tx.Statement.Clauses["GROUP BY"] = groupByClause
+		}()
+		tx.Statement.ExpandHavingAliases()
+	}
+
 	if len(tx.Statement.Selects) == 0 {
 		tx.Statement.AddClause(clause.Select{Expression: clause.Expr{SQL: "count(*)"}})
 	} else if !strings.HasPrefix(strings.TrimSpace(strings.ToLower(tx.Statement.Selects[0])), "count(") {
@@ -493,6 +1099,8 @@ func (db *DB) Count(count *int64) (tx *DB) {
 	}
 
 	tx.Statement.Dest = count
+	tx.Statement.Settings.Store(countingKey, true)
+	defer tx.Statement.Settings.Delete(countingKey)
 	tx = tx.callbacks.Query().Execute(tx)
 
 	if _, ok := db.Statement.Clauses["GROUP BY"]; ok || tx.RowsAffected != 1 {
@@ -507,7 +1115,7 @@ func (db *DB) Row() *sql.Row {
 	tx = tx.callbacks.Row().Execute(tx)
 	row, ok := tx.Statement.Dest.(*sql.Row)
 	if !ok && tx.DryRun {
-		db.Logger.Error(tx.Statement.Context, ErrDryRunModeUnsupported.Error())
+		db.Logger.Error(tx.Statement.Context, tx.dryRunError().Error())
 	}
 	return row
 }
@@ -517,34 +1125,60 @@ func (db *DB) Rows() (*sql.Rows, error) {
 	tx = tx.callbacks.Row().Execute(tx)
 	rows, ok := tx.Statement.Dest.(*sql.Rows)
 	if !ok && tx.DryRun && tx.Error == nil {
-		tx.Error = ErrDryRunModeUnsupported
+		tx.Error = tx.dryRunError()
 	}
 	return rows, tx.Error
 }
 
+// dryRunError returns the DryRunError of the last statement generated in DryRun mode
+func (db *DB) dryRunError() *DryRunError {
+	if statements := db.Statement.SQLStatements(); len(statements) > 0 {
+		last := statements[len(statements)-1]
+		return &DryRunError{SQL: last.SQL, Vars: last.Vars}
+	}
+	return &DryRunError{}
+}
+
 // Scan scans selected value to the struct dest
 func (db *DB) Scan(dest interface{}) (tx *DB) {
 	config := *db.Config
 	currentLogger, newLogger := config.Logger, logger.Recorder.New()
+	newLogger.Interface = currentLogger
 	config.Logger = newLogger
 
 	tx = db.getInstance()
 	tx.Config = &config
 
-	if rows, err := tx.Rows(); err == nil {
+	statementDest := tx.Statement.Dest
+	for attempt := 0; ; attempt++ {
+		rows, err := tx.Rows()
+		if err != nil {
+			if tx.DryRun && errors.Is(err, ErrDryRunModeUnsupported) {
+				// the statement is generated, nothing to scan
+				tx.Error = nil
+			}
+			break
+		}
+
 		if rows.Next() {
 			tx.ScanRows(rows, dest)
+		} else if err := rows.Err(); tx.ShouldRetryTransient(attempt, err) {
+			// the query failed before returning any row
+			rows.Close()
+			tx.Statement.Dest = statementDest
+			continue
 		} else {
 			tx.RowsAffected = 0
-			tx.AddError(rows.Err())
+			tx.AddError(err)
 		}
 		tx.AddError(rows.Close())
+		break
 	}
 
-	currentLogger.Trace(tx.Statement.Context, newLogger.BeginAt, func() (string, int64) {
+	tx.Logger = currentLogger
+	tx.Statement.traceLogger(newLogger.SQL).Trace(tx.Statement.Context, newLogger.BeginAt, func() (string, int64) {
 		return newLogger.SQL, tx.RowsAffected
 	}, tx.Error)
-	tx.Logger = currentLogger
 	return
 }
 
@@ -563,10 +1197,14 @@ func (db *DB) Pluck(column string, dest interface{}) (tx *DB) {
 	}
 
 	if len(tx.Statement.Selects) != 1 {
-		fields := strings.FieldsFunc(column, utils.IsValidDBNameChar)
+		selectColumn, ok := clause.ParseQuotingIdentifier(column)
+		if !ok {
+			fields := strings.FieldsFunc(column, utils.IsValidDBNameChar)
+			selectColumn = clause.Column{Name: column, Raw: len(fields) != 1}
+		}
 		tx.Statement.AddClauseIfNotExists(clause.Select{
 			Distinct: tx.Statement.Distinct,
-			Columns:  []clause.Column{{Name: column, Raw: len(fields) != 1}},
+			Columns:  []clause.Column{selectColumn},
 		})
 	}
 	tx.Statement.Dest = dest
@@ -575,7 +1213,12 @@ func (db *DB) Pluck(column string, dest interface{}) (tx *DB) {
 
 func (db *DB) ScanRows(rows *sql.Rows, dest interface{}) error {
 	tx := db.getInstance()
-	if err := tx.Statement.Parse(dest); !errors.Is(err, schema.ErrUnsupportedDataType) {
+	// the model redacts the values scanned into the other destinations, see RegisterRedactor
+	value := dest
+	if tx.redact && tx.Statement.Model != nil {
+		value = tx.Statement.Model
+	}
+	if err := tx.Statement.Parse(value); !errors.Is(err, schema.ErrUnsupportedDataType) {
 		tx.AddError(err)
 	}
 	tx.Statement.Dest = dest
@@ -615,15 +1258,70 @@ func (db *DB) Connection(fc func(tx *DB) error) (err error) {
 	return fc(tx)
 }
 
+// AcquireConn takes a connection from the pool, statements of the returned db run on it until release is called, e.g:
+//
+//	tx, release, err := db.AcquireConn(ctx)
+//	if err != nil {
+//	  return err
+//	}
+//	defer release()
+//	tx.Exec("SET search_path TO tenant")
+//	tx.Find(&users)
+//
+// statements of the db after release return ErrConnReleased, connections never released are returned to the pool
+// when the db is garbage collected, with an error log
+func (db *DB) AcquireConn(ctx context.Context) (tx *DB, release func() error, err error) {
+	if db.Error != nil {
+		return nil, nil, db.Error
+	}
+
+	tx = db.Session(&Session{Context: ctx})
+	sqlDB, err := tx.DB()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pinned := &pinnedConn{Conn: conn, logger: db.Logger, location: utils.FileWithLineNum()}
+	ref := &pinnedConnRef{pinnedConn: pinned}
+	runtime.SetFinalizer(ref, (*pinnedConnRef).finalize)
+
+	tx.Config.ConnPool = ref
+	tx.Statement.ConnPool = pinned
+	if db.PrepareStmt {
+		if v, ok := db.cacheStore.Load(preparedStmtDBKey); ok {
+			tx.Statement.ConnPool = pinned.preparedStmtDB(v.(*PreparedStmtDB))
+		}
+	}
+	return tx, ref.release, nil
+}
+
+// TxJoin the option of Transaction and Begin, the nested transactions of the transaction, or the nested transaction
+// itself, join the outer transaction without savepoints, overriding Config.DisableNestedTransaction, e.g:
+//
+//	db.Transaction(func(tx *gorm.DB) error { ... }, gorm.TxJoin)
+var TxJoin = &sql.TxOptions{}
+
 // Transaction start a transaction as a block, return error will rollback, otherwise to commit. Transaction executes an
 // arbitrary number of commands in fc within a transaction. On success the changes are committed; if an error occurs
 // they are rolled back.
+//
+// The nested transactions create savepoints, the error of a nested transaction rolls back to its savepoint, and the
+// outer transaction could continue. The nested transactions joining the outer transaction without savepoints, see
+// TxJoin and DisableNestedTransaction, can't be rolled back alone, the error of a joined transaction is returned as
+// is, its changes are kept in the outer transaction, which is committed or rolled back as fc of the outer
+// transaction returns.
 func (db *DB) Transaction(fc func(tx *DB) error, opts ...*sql.TxOptions) (err error) {
 	panicked := true
 
 	if committer, ok := db.Statement.ConnPool.(TxCommitter); ok && committer != nil {
 		// nested transaction
-		if !db.DisableNestedTransaction {
+		joined, _ := txJoinOption(opts)
+		if !db.DisableNestedTransaction && !joined {
 			spID := new(maphash.Hash).Sum64()
 			err = db.SavePoint(fmt.Sprintf("sp%d", spID)).Error
 			if err != nil {
@@ -635,14 +1333,24 @@ func (db *DB) Transaction(fc func(tx *DB) error, opts ...*sql.TxOptions) (err er
 					db.RollbackTo(fmt.Sprintf("sp%d", spID))
 				}
 			}()
+			err = fc(db.Session(&Session{NewDB: db.clone == 1}))
+		} else {
+			// the nested transactions of fc join the outer transaction too, the SAVEPOINT statements are traced otherwise
+			db.Logger.Info(db.Statement.Context, "nested transaction joins the outer transaction without savepoint")
+			disableNestedTransaction := true
+			err = fc(db.Session(&Session{NewDB: db.clone == 1, DisableNestedTransaction: &disableNestedTransaction}))
 		}
-		err = fc(db.Session(&Session{NewDB: db.clone == 1}))
 	} else {
 		tx := db.Begin(opts...)
 		if tx.Error != nil {
 			return tx.Error
 		}
 
+		// the remaining statements of fc aren't executed once the context is done, see Statement.transactionProgress
+		if tx.Statement.Context != nil {
+			tx.Statement.Context = context.WithValue(tx.Statement.Context, transactionProgressKey{}, new(int64))
+		}
+
 		defer func() {
 			// Make sure to rollback when panic, Block error or Commit error
 			if panicked || err != nil {
@@ -660,31 +1368,73 @@ func (db *DB) Transaction(fc func(tx *DB) error, opts ...*sql.TxOptions) (err er
 	return
 }
 
-// Begin begins a transaction with any transaction options opts
+// txJoinOption reports whether opts includes TxJoin, returns the other options
+func txJoinOption(opts []*sql.TxOptions) (joined bool, others []*sql.TxOptions) {
+	for idx, opt := range opts {
+		if opt == TxJoin {
+			others = append(append(others, opts[:idx]...), opts[idx+1:]...)
+			return true, others
+		}
+	}
+	return false, opts
+}
+
+// transactionProgressKey the context key of the statements executed by the closure of Transaction
+type transactionProgressKey struct{}
+
+// transactionProgress returns the count of the statements executed by the closure of Transaction, nil if the
+// statement isn't executed in it
+func (stmt *Statement) transactionProgress() *int64 {
+	if _, ok := stmt.ConnPool.(TxCommitter); !ok || stmt.Context == nil {
+		return nil
+	}
+
+	progress, _ := stmt.Context.Value(transactionProgressKey{}).(*int64)
+	return progress
+}
+
+// Begin begins a transaction with any transaction options opts, the nested transactions of the transaction join it
+// without savepoints if opts includes TxJoin
 func (db *DB) Begin(opts ...*sql.TxOptions) *DB {
+	joined, opts := txJoinOption(opts)
 	var (
 		// clone statement
-		tx  = db.getInstance().Session(&Session{Context: db.Statement.Context, NewDB: db.clone == 1})
-		opt *sql.TxOptions
-		err error
+		tx    = db.getInstance().Session(&Session{Context: db.Statement.Context, NewDB: db.clone == 1})
+		opt   *sql.TxOptions
+		err   error
+		start = time.Now()
 	)
 
+	if joined {
+		tx.Config.DisableNestedTransaction = true
+	}
+
 	if len(opts) > 0 {
 		opt = opts[0]
 	}
 
+	ctx := tx.Statement.Context
+	if len(tx.QueryHooks) > 0 {
+		ctx = tx.Statement.beforeQueryHooks(OperationBegin)
+	}
+
 	switch beginner := tx.Statement.ConnPool.(type) {
 	case TxBeginner:
-		tx.Statement.ConnPool, err = beginner.BeginTx(tx.Statement.Context, opt)
+		tx.Statement.ConnPool, err = beginner.BeginTx(ctx, opt)
 	case ConnPoolBeginner:
-		tx.Statement.ConnPool, err = beginner.BeginTx(tx.Statement.Context, opt)
+		tx.Statement.ConnPool, err = beginner.BeginTx(ctx, opt)
 	default:
 		err = ErrInvalidTransaction
 	}
 
+	if len(tx.QueryHooks) > 0 {
+		tx.Statement.afterQueryHooks(ctx, err)
+	}
+
 	if err != nil {
 		tx.AddError(err)
 	}
+	tx.notifyPoolEvent(PoolTxBegin, start, err)
 
 	return tx
 }
@@ -692,7 +1442,17 @@ func (db *DB) Begin(opts ...*sql.TxOptions) *DB {
 // Commit commits the changes in a transaction
 func (db *DB) Commit() *DB {
 	if committer, ok := db.Statement.ConnPool.(TxCommitter); ok && committer != nil && !reflect.ValueOf(committer).IsNil() {
-		db.AddError(committer.Commit())
+		start := time.Now()
+		err := db.Statement.runTxQueryHooks(OperationCommit, committer.Commit)
+		db.AddError(err)
+		db.notifyPoolEvent(PoolTxCommit, start, err)
+		if hooks := db.takeTxHooks(committer); hooks != nil {
+			if err == nil {
+				hooks.runCommits(db)
+			} else {
+				hooks.runRollbacks(db, 0)
+			}
+		}
 	} else {
 		db.AddError(ErrInvalidTransaction)
 	}
@@ -703,7 +1463,13 @@ func (db *DB) Commit() *DB {
 func (db *DB) Rollback() *DB {
 	if committer, ok := db.Statement.ConnPool.(TxCommitter); ok && committer != nil {
 		if !reflect.ValueOf(committer).IsNil() {
-			db.AddError(committer.Rollback())
+			start := time.Now()
+			err := db.Statement.runTxQueryHooks(OperationRollback, committer.Rollback)
+			db.AddError(err)
+			db.notifyPoolEvent(PoolTxRollback, start, err)
+			if hooks := db.takeTxHooks(committer); hooks != nil {
+				hooks.runRollbacks(db, 0)
+			}
 		}
 	} else {
 		db.AddError(ErrInvalidTransaction)
@@ -711,6 +1477,126 @@ func (db *DB) Rollback() *DB {
 	return db
 }
 
+// OnCommit registers fc to be called after the current transaction has been committed, hooks registered in nested
+// transactions are only called when the outermost transaction commits. If db is not in a transaction, fc is called
+// immediately.
+func (db *DB) OnCommit(fc func()) *DB {
+	if committer, ok := db.Statement.ConnPool.(TxCommitter); ok && committer != nil && !reflect.ValueOf(committer).IsNil() {
+		hooks := db.loadTxHooks(committer, true)
+		hooks.mux.Lock()
+		hooks.commits = append(hooks.commits, fc)
+		hooks.mux.Unlock()
+	} else {
+		(&txHooks{commits: []func(){fc}}).runCommits(db)
+	}
+	return db
+}
+
+// OnRollback registers fc to be called after the current transaction, or the savepoint of the nested transaction
+// it registered in, has been rolled back. If db is not in a transaction, fc is never called.
+func (db *DB) OnRollback(fc func()) *DB {
+	if committer, ok := db.Statement.ConnPool.(TxCommitter); ok && committer != nil && !reflect.ValueOf(committer).IsNil() {
+		hooks := db.loadTxHooks(committer, true)
+		hooks.mux.Lock()
+		hooks.rollbacks = append(hooks.rollbacks, fc)
+		hooks.mux.Unlock()
+	}
+	return db
+}
+
+// txHooks the hooks registered to a transaction with OnCommit and OnRollback
+type txHooks struct {
+	mux        sync.Mutex
+	commits    []func()
+	rollbacks  []func()
+	savePoints map[string][2]int
+}
+
+// txOf returns the underlying transaction of committer, so hooks are shared whether prepared statement used or not
+func txOf(committer TxCommitter) TxCommitter {
+	if preparedStmtTx, ok := committer.(*PreparedStmtTX); ok {
+		return preparedStmtTx.Tx
+	}
+	return committer
+}
+
+func (db *DB) loadTxHooks(committer TxCommitter, create bool) *txHooks {
+	key := txOf(committer)
+	if v, ok := db.transactionHooks.Load(key); ok {
+		return v.(*txHooks)
+	} else if create {
+		v, _ := db.transactionHooks.LoadOrStore(key, &txHooks{})
+		return v.(*txHooks)
+	}
+	return nil
+}
+
+// takeTxHooks removes the hooks of the finished transaction of committer, and returns them to be run
+func (db *DB) takeTxHooks(committer TxCommitter) *txHooks {
+	if v, ok := db.transactionHooks.LoadAndDelete(txOf(committer)); ok {
+		return v.(*txHooks)
+	}
+	return nil
+}
+
+func (hooks *txHooks) savePoint(name string) {
+	hooks.mux.Lock()
+	defer hooks.mux.Unlock()
+	if hooks.savePoints == nil {
+		hooks.savePoints = map[string][2]int{}
+	}
+	hooks.savePoints[name] = [2]int{len(hooks.commits), len(hooks.rollbacks)}
+}
+
+// rollbackTo drops the commit hooks registered after the savepoint and calls the rollback hooks registered after it
+func (hooks *txHooks) rollbackTo(db *DB, name string) {
+	hooks.mux.Lock()
+	mark, ok := hooks.savePoints[name]
+	if ok {
+		hooks.commits = hooks.commits[:mark[0]]
+	}
+	hooks.mux.Unlock()
+
+	if ok {
+		hooks.runRollbacks(db, mark[1])
+	}
+}
+
+func (hooks *txHooks) runCommits(db *DB) {
+	hooks.mux.Lock()
+	fcs := hooks.commits
+	hooks.commits = nil
+	hooks.mux.Unlock()
+
+	for _, fc := range fcs {
+		runTxHook(db, "commit", fc)
+	}
+}
+
+func (hooks *txHooks) runRollbacks(db *DB, from int) {
+	hooks.mux.Lock()
+	var fcs []func()
+	if from < len(hooks.rollbacks) {
+		fcs = hooks.rollbacks[from:]
+		hooks.rollbacks = hooks.rollbacks[:from:from]
+	}
+	hooks.mux.Unlock()
+
+	for _, fc := range fcs {
+		runTxHook(db, "rollback", fc)
+	}
+}
+
+// runTxHook calls fc after the transaction finished, panics are recovered as the connection is already released
+func runTxHook(db *DB, event string, fc func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			db.Logger.Error(db.Statement.Context, "panic in after %s hook: %v\n%s", event, r, utils.FileWithLineNum())
+		}
+	}()
+	fc()
+}
+
 func (db *DB) SavePoint(name string) *DB {
 	if savePointer, ok := db.Dialector.(SavePointerDialectorInterface); ok {
 		// close prepared statement, because SavePoint not support prepared statement.
@@ -723,7 +1609,11 @@ func (db *DB) SavePoint(name string) *DB {
 		if preparedStmtTx, isPreparedStmtTx = db.Statement.ConnPool.(*PreparedStmtTX); isPreparedStmtTx {
 			db.Statement.ConnPool = preparedStmtTx.Tx
 		}
-		db.AddError(savePointer.SavePoint(db, name))
+		if err := savePointer.SavePoint(db, name); err != nil {
+			db.AddError(err)
+		} else if committer, ok := db.Statement.ConnPool.(TxCommitter); ok && committer != nil {
+			db.loadTxHooks(committer, true).savePoint(name)
+		}
 		// restore prepared statement
 		if isPreparedStmtTx {
 			db.Statement.ConnPool = preparedStmtTx
@@ -746,7 +1636,13 @@ func (db *DB) RollbackTo(name string) *DB {
 		if preparedStmtTx, isPreparedStmtTx = db.Statement.ConnPool.(*PreparedStmtTX); isPreparedStmtTx {
 			db.Statement.ConnPool = preparedStmtTx.Tx
 		}
-		db.AddError(savePointer.RollbackTo(db, name))
+		if err := savePointer.RollbackTo(db, name); err != nil {
+			db.AddError(err)
+		} else if committer, ok := db.Statement.ConnPool.(TxCommitter); ok && committer != nil {
+			if hooks := db.loadTxHooks(committer, false); hooks != nil {
+				hooks.rollbackTo(db, name)
+			}
+		}
 		// restore prepared statement
 		if isPreparedStmtTx {
 			db.Statement.ConnPool = preparedStmtTx
@@ -757,16 +1653,218 @@ func (db *DB) RollbackTo(name string) *DB {
 	return db
 }
 
+// PreparedTransaction an in-doubt transaction prepared for two-phase commit
+type PreparedTransaction struct {
+	ID       string
+	Prepared time.Time
+	Owner    string
+	Database string
+}
+
+// PrepareTransaction prepares the current transaction for two-phase commit with the given id, it needs to be
+// finished later with CommitPrepared or RollbackPrepared, probably from another connection.
+//
+// The transaction is detached from its connection after it has been prepared, so the connection is released
+// back to the pool and the returned db can't be used to commit or rollback it anymore.
+func (db *DB) PrepareTransaction(id string) *DB {
+	preparer, ok := db.Dialector.(TxPreparer)
+	if !ok {
+		db.AddError(ErrUnsupportedDriver)
+		return db
+	}
+
+	committer, ok := db.Statement.ConnPool.(TxCommitter)
+	if !ok || committer == nil || reflect.ValueOf(committer).IsNil() {
+		db.AddError(ErrInvalidTransaction)
+		return db
+	}
+
+	// PREPARE TRANSACTION can't be executed as a prepared statement
+	if preparedStmtTx, isPreparedStmtTx := committer.(*PreparedStmtTX); isPreparedStmtTx {
+		db.Statement.ConnPool = preparedStmtTx.Tx
+	}
+
+	if err := preparer.PrepareTransaction(db, id); err != nil {
+		db.Statement.ConnPool = committer.(ConnPool)
+		db.AddError(err)
+		return db
+	}
+
+	// the session is no longer in a transaction after PREPARE, ending the sql.Tx only releases its connection,
+	// the prepared transaction itself is not affected, so the error is ignored
+	_ = committer.Commit()
+	// the hooks belong to the session's transaction, which has ended, whether the prepared transaction is committed
+	// or rolled back later is unknown to it
+	db.takeTxHooks(committer)
+	db.Statement.ConnPool = db.ConnPool
+	return db
+}
+
+// CommitPrepared commits a transaction prepared with PrepareTransaction
+func (db *DB) CommitPrepared(id string) (tx *DB) {
+	tx = db.getInstance()
+	if preparer, ok := tx.Dialector.(TxPreparer); ok {
+		tx.AddError(preparer.CommitPrepared(tx, id))
+	} else {
+		tx.AddError(ErrUnsupportedDriver)
+	}
+	return tx
+}
+
+// RollbackPrepared rollbacks a transaction prepared with PrepareTransaction
+func (db *DB) RollbackPrepared(id string) (tx *DB) {
+	tx = db.getInstance()
+	if preparer, ok := tx.Dialector.(TxPreparer); ok {
+		tx.AddError(preparer.RollbackPrepared(tx, id))
+	} else {
+		tx.AddError(ErrUnsupportedDriver)
+	}
+	return tx
+}
+
+// PreparedTransactions returns the in-doubt transactions prepared with PrepareTransaction, dialectors which don't
+// implement PreparedTransactionLister are expected to expose them with the pg_prepared_xacts view
+func (db *DB) PreparedTransactions() ([]PreparedTransaction, error) {
+	tx := db.getInstance()
+	if lister, ok := tx.Dialector.(PreparedTransactionLister); ok {
+		return lister.PreparedTransactions(tx)
+	}
+
+	if _, ok := tx.Dialector.(TxPreparer); !ok {
+		return nil, ErrUnsupportedDriver
+	}
+
+	var transactions []PreparedTransaction
+	err := tx.Raw("SELECT gid AS id, prepared, owner, database FROM pg_prepared_xacts ORDER BY prepared").Scan(&transactions).Error
+	return transactions, err
+}
+
 // Exec executes raw sql
 func (db *DB) Exec(sql string, values ...interface{}) (tx *DB) {
 	tx = db.getInstance()
 	tx.Statement.SQL = strings.Builder{}
 
+	if tx.NormalizePlaceholders {
+		sql, values = tx.Statement.normalizePlaceholders(sql, values)
+	}
+
 	if strings.Contains(sql, "@") {
 		clause.NamedExpr{SQL: sql, Vars: values}.Build(tx.Statement)
 	} else {
 		clause.Expr{SQL: sql, Vars: values}.Build(tx.Statement)
 	}
 
-	return tx.callbacks.Raw().Execute(tx)
+	return db.releaseStatement(tx.callbacks.Raw().Execute(tx))
+}
+
+// LastInsertID returns the id generated by the database for the row inserted by Exec, e.g:
+//
+//	tx := db.Exec("INSERT INTO users (name) VALUES (?)", "jinzhu")
+//	id, err := tx.LastInsertID()
+//
+// the id of multi-row INSERT depends on the database, MySQL returns the id of the first inserted row and SQLite the
+// last one, the inserted rows are counted by RowsAffected. Postgres and SQL Server don't return the ids of Exec, which
+// return ErrLastInsertIDUnsupported, use RETURNING or OUTPUT with Raw and Scan instead
+func (db *DB) LastInsertID() (int64, error) {
+	if db.Error != nil {
+		return 0, db.Error
+	}
+
+	switch name := db.Dialector.Name(); name {
+	case "postgres", "sqlserver":
+		return 0, fmt.Errorf("%w: %s, use RETURNING or OUTPUT to return the inserted ids", ErrLastInsertIDUnsupported, name)
+	}
+
+	if db.Statement.Result == nil {
+		return 0, fmt.Errorf("%w: no statement executed by Exec", ErrInvalidDB)
+	}
+	return db.Statement.Result.LastInsertId()
+}
+
+const continueOnErrorKey = "gorm:continue_on_error"
+
+// ExecResult the result of a statement executed by ExecMulti
+type ExecResult struct {
+	SQL          string
+	RowsAffected int64
+	// LastInsertID the id of the inserted row if the dialect returns it, see DB.LastInsertID
+	LastInsertID int64
+	Error        error
+}
+
+// ExecMulti executes the statements of sql separated by semicolons one by one, see utils.SplitSQL, returns the results
+// of the executed statements and the error of the first failed one. The vars are consumed by the `?` placeholders of
+// the statements in order, or passed to every statement as named vars if sql doesn't have placeholders.
+//
+// The statements are executed in a transaction unless SkipDefaultTransaction is enabled, which stops and rolls back at
+// the first failed statement. With ContinueOnError, the failed statements are rolled back to savepoints if supported,
+// and all the other statements are executed and committed, e.g:
+//
+//	results, err := db.ContinueOnError().ExecMulti("INSERT INTO users (name) VALUES (?); UPDATE users SET age = ?", "jinzhu", 18)
+func (db *DB) ExecMulti(sql string, values ...interface{}) (results []ExecResult, err error) {
+	var (
+		statements      = utils.SplitSQL(sql)
+		placeholders    = utils.CountSQLPlaceholders(sql)
+		continueOnError bool
+	)
+
+	if placeholders > 0 && placeholders != len(values) {
+		return nil, fmt.Errorf("%w: %d vars for %d placeholders of %v", ErrInvalidData, len(values), placeholders, sql)
+	}
+
+	if v, ok := db.Statement.Settings.Load(continueOnErrorKey); ok {
+		continueOnError = v.(bool)
+	}
+
+	execStatements := func(tx *DB) error {
+		_, inTransaction := tx.Statement.ConnPool.(TxCommitter)
+		savePoint := continueOnError && inTransaction && tx.Supports(CapabilitySavePoint)
+
+		var offset int
+		for idx, statement := range statements {
+			vars := values
+			if placeholders > 0 {
+				count := utils.CountSQLPlaceholders(statement)
+				vars, offset = values[offset:offset+count], offset+count
+			}
+
+			result := ExecResult{SQL: statement}
+			exec := func(tx *DB) error {
+				execTx := tx.Exec(statement, vars...)
+				result.RowsAffected = execTx.RowsAffected
+				result.LastInsertID, _ = execTx.LastInsertID()
+				return execTx.Error
+			}
+
+			if savePoint {
+				result.Error = tx.Transaction(exec)
+			} else {
+				result.Error = exec(tx)
+			}
+			results = append(results, result)
+
+			if result.Error != nil {
+				if err == nil {
+					err = fmt.Errorf("statement #%d failed: %w", idx, result.Error)
+				}
+
+				if !continueOnError {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	var txErr error
+	if db.SkipDefaultTransaction {
+		txErr = execStatements(db)
+	} else {
+		txErr = db.Transaction(execStatements)
+	}
+
+	if err == nil {
+		err = txErr
+	}
+	return results, err
 }
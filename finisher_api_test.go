@@ -0,0 +1,61 @@
+package gorm
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+type hooksTx struct {
+	ConnPool
+}
+
+func (hooksTx) Commit() error   { return nil }
+func (hooksTx) Rollback() error { return nil }
+
+func TestTxHooksRemovedWhenTransactionEnds(t *testing.T) {
+	newTx := func() *DB {
+		db := &DB{Config: &Config{transactionHooks: &sync.Map{}}}
+		db.Statement = &Statement{DB: db, ConnPool: &hooksTx{}, Context: context.Background()}
+		return db
+	}
+
+	countHooks := func(db *DB) (count int) {
+		db.transactionHooks.Range(func(key, value interface{}) bool {
+			count++
+			return true
+		})
+		return count
+	}
+
+	var committed, rolledBack int
+	tx := newTx()
+	tx.OnCommit(func() { committed++ }).OnRollback(func() { rolledBack++ })
+	if countHooks(tx) != 1 {
+		t.Fatalf("expects hooks stored for the open transaction, got %v", countHooks(tx))
+	}
+	if tx.Commit(); countHooks(tx) != 0 || committed != 1 || rolledBack != 0 {
+		t.Errorf("expects hooks run once and removed after commit, got %v stored, %v/%v run", countHooks(tx), committed, rolledBack)
+	}
+
+	tx = newTx()
+	tx.OnCommit(func() { committed++ }).OnRollback(func() { rolledBack++ })
+	if tx.Rollback(); countHooks(tx) != 0 || committed != 1 || rolledBack != 1 {
+		t.Errorf("expects hooks run once and removed after rollback, got %v stored, %v/%v run", countHooks(tx), committed, rolledBack)
+	}
+
+	tx = newTx()
+	tx.OnCommit(func() { committed++ })
+	tx.Dialector = preparerDialector{}
+	if tx.PrepareTransaction("gorm"); countHooks(tx) != 0 || committed != 1 {
+		t.Errorf("expects hooks dropped after prepare transaction, got %v stored, %v commits", countHooks(tx), committed)
+	}
+}
+
+type preparerDialector struct {
+	Dialector
+}
+
+func (preparerDialector) PrepareTransaction(*DB, string) error { return nil }
+func (preparerDialector) CommitPrepared(*DB, string) error     { return nil }
+func (preparerDialector) RollbackPrepared(*DB, string) error   { return nil }
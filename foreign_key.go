@@ -0,0 +1,213 @@
+package gorm
+
+import (
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils"
+)
+
+const withoutForeignKeyChecksKey = "gorm:without_foreign_key_checks"
+
+// WithoutForeignKeyChecks skips the foreign keys emulated by Config.EmulateForeignKeys or the relationships tagged
+// with `emulateForeignKey` for the statement, e.g:
+//
+//	db.WithoutForeignKeyChecks().Create(&pets)
+func (db *DB) WithoutForeignKeyChecks() (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.Settings.Store(withoutForeignKeyChecksKey, true)
+	return
+}
+
+// emulatesForeignKey returns true if the foreign key of the relationship is checked by the statement
+func (stmt *Statement) emulatesForeignKey(rel *schema.Relationship) bool {
+	if _, skipped := stmt.Settings.Load(withoutForeignKeyChecksKey); skipped {
+		return false
+	}
+
+	if v, ok := rel.Field.TagSettings["EMULATEFOREIGNKEY"]; ok {
+		return utils.CheckTruth(v)
+	}
+	return stmt.DB.EmulateForeignKeys
+}
+
+// CheckForeignKeys checks the parents referenced by the belongs to relationships of the created or updated values
+// exist, the foreign keys of all the values are checked with one SELECT for every relationship, returns
+// *ConstraintError wrapping ErrForeignKeyViolated if any parent is missing
+func (stmt *Statement) CheckForeignKeys() error {
+	if stmt.Schema == nil || stmt.SQL.Len() > 0 {
+		return nil
+	}
+
+	selectColumns, restricted := stmt.SelectAndOmitColumns(false, false)
+	for _, rel := range stmt.Schema.Relationships.BelongsTo {
+		if !stmt.emulatesForeignKey(rel) {
+			continue
+		}
+
+		var (
+			foreignFields  = make([]*schema.Field, 0, len(rel.References))
+			primaryColumns = make([]string, 0, len(rel.References))
+			columns        = make([]string, 0, len(rel.References))
+			selected       = true
+		)
+		for _, ref := range rel.References {
+			if ref.PrimaryKey == nil || ref.OwnPrimaryKey {
+				continue
+			}
+
+			if v, ok := selectColumns[ref.ForeignKey.DBName]; (ok && !v) || (!ok && restricted) {
+				selected = false
+			}
+			foreignFields = append(foreignFields, ref.ForeignKey)
+			primaryColumns = append(primaryColumns, ref.PrimaryKey.DBName)
+			columns = append(columns, ref.ForeignKey.DBName)
+		}
+
+		if !selected || len(foreignFields) == 0 {
+			continue
+		}
+
+		values := stmt.foreignKeyValues(foreignFields)
+		if len(values) == 0 {
+			continue
+		}
+
+		var count int64
+		column, queryValues := schema.ToQueryValues(rel.FieldSchema.Table, primaryColumns, values)
+		if err := stmt.DB.Session(&Session{NewDB: true}).Table(rel.FieldSchema.Table).
+			Where(clause.IN{Column: column, Values: queryValues}).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count < int64(len(values)) {
+			constraintErr := &ConstraintError{Err: ErrForeignKeyViolated, Table: stmt.Table, Columns: columns}
+			if constraint := rel.ParseConstraint(); constraint != nil {
+				constraintErr.ConstraintName = constraint.Name
+			}
+			return constraintErr
+		}
+	}
+	return nil
+}
+
+// foreignKeyValues returns the distinct values of the foreign fields of the created or updated values, the values
+// without the foreign keys are skipped
+func (stmt *Statement) foreignKeyValues(fields []*schema.Field) (values [][]interface{}) {
+	fromMap := func(mapValue map[string]interface{}) {
+		value, notZero := make([]interface{}, len(fields)), false
+		for idx, field := range fields {
+			if v, ok := mapValue[field.DBName]; ok {
+				value[idx] = v
+			} else if v, ok := mapValue[field.Name]; ok {
+				value[idx] = v
+			}
+
+			if rv := reflect.ValueOf(value[idx]); rv.IsValid() && !(rv.Kind() == reflect.Ptr && rv.IsNil()) && !rv.IsZero() {
+				notZero = true
+			}
+		}
+
+		if notZero {
+			values = append(values, value)
+		}
+	}
+
+	switch dest := stmt.Dest.(type) {
+	case map[string]interface{}:
+		fromMap(dest)
+	case *map[string]interface{}:
+		fromMap(*dest)
+	case []map[string]interface{}:
+		for _, v := range dest {
+			fromMap(v)
+		}
+	case *[]map[string]interface{}:
+		for _, v := range *dest {
+			fromMap(v)
+		}
+	default:
+		reflectValue := stmt.ReflectValue
+		// the struct updated with Updates, e.g: db.Model(&pet).Updates(Pet{UserID: 1})
+		if destValue := reflect.Indirect(reflect.ValueOf(stmt.Dest)); destValue.Kind() == reflect.Struct &&
+			destValue.Type() == stmt.Schema.ModelType {
+			reflectValue = destValue
+		}
+		_, values = schema.GetIdentityFieldValuesMap(stmt.Context, reflectValue, fields)
+		return values
+	}
+
+	distinctValues := values[:0]
+	loaded := map[string]bool{}
+	for _, value := range values {
+		if key := utils.ToStringKey(value...); !loaded[key] {
+			loaded[key] = true
+			distinctValues = append(distinctValues, value)
+		}
+	}
+	return distinctValues
+}
+
+// CheckRestrictedChildren checks the deleted values aren't referenced by the children of the has one and has many
+// relationships deleted with restrict semantics, the relationships without OnDelete constraint or with RESTRICT and
+// NO ACTION, should be called after the conditions are built, returns *ConstraintError wrapping ErrForeignKeyViolated
+// if any child references the deleted values, the soft deletes aren't checked
+func (stmt *Statement) CheckRestrictedChildren() error {
+	if stmt.Schema == nil || (stmt.Schema.SoftDelete != nil && !stmt.Unscoped) {
+		return nil
+	}
+
+	for _, rel := range stmt.Schema.Relationships.Relations {
+		if rel.Schema != stmt.Schema || (rel.Type != schema.HasOne && rel.Type != schema.HasMany) || !stmt.emulatesForeignKey(rel) {
+			continue
+		}
+
+		constraint := rel.ParseConstraint()
+		if constraint == nil {
+			continue
+		}
+
+		if onDelete := strings.ToUpper(strings.TrimSpace(constraint.OnDelete)); onDelete != "" && onDelete != "RESTRICT" && onDelete != "NO ACTION" {
+			continue
+		}
+
+		var (
+			foreignColumns = make([]interface{}, 0, len(rel.References))
+			primaryColumns = make([]string, 0, len(rel.References))
+			conds          []clause.Expression
+			columns        []string
+		)
+		for _, ref := range rel.References {
+			if ref.OwnPrimaryKey {
+				foreignColumns = append(foreignColumns, clause.Column{Table: rel.FieldSchema.Table, Name: ref.ForeignKey.DBName})
+				primaryColumns = append(primaryColumns, stmt.Quote(clause.Column{Table: stmt.Table, Name: ref.PrimaryKey.DBName}))
+				columns = append(columns, ref.ForeignKey.DBName)
+			} else if ref.PrimaryValue != "" {
+				// the type of the polymorphic relationships
+				conds = append(conds, clause.Eq{Column: clause.Column{Table: rel.FieldSchema.Table, Name: ref.ForeignKey.DBName}, Value: ref.PrimaryValue})
+			}
+		}
+
+		// the children referencing the deleted values, e.g: (`pets`.`user_id`) IN (SELECT `users`.`id` FROM `users` WHERE ...)
+		sql := "? IN (SELECT " + strings.Join(primaryColumns, ",") + " FROM ?"
+		vars := []interface{}{foreignColumns, clause.Table{Name: stmt.Table}}
+		if where, ok := stmt.Clauses["WHERE"]; ok {
+			sql += " ?"
+			vars = append(vars, where.Expression)
+		}
+		conds = append(conds, clause.Expr{SQL: sql + ")", Vars: vars})
+
+		var count int64
+		tx := stmt.DB.Session(&Session{NewDB: true}).Table(rel.FieldSchema.Table).Where(clause.And(conds...))
+		if err := tx.Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return &ConstraintError{Err: ErrForeignKeyViolated, ConstraintName: constraint.Name, Table: rel.FieldSchema.Table, Columns: columns}
+		}
+	}
+	return nil
+}
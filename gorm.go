@@ -26,34 +26,189 @@ type Config struct {
 	NamingStrategy schema.Namer
 	// FullSaveAssociations full save associations
 	FullSaveAssociations bool
+	// StrictAssociations returns ErrUnsupportedRelation for the queries whose Joins or Preload paths resolve to no
+	// relationships, e.g: the relationship paths treated as the raw SQL by Joins, or clause.Associations of the
+	// schemas without relationships, see Statement.CheckAssociations
+	StrictAssociations bool
 	// Logger
 	Logger logger.Interface
+	// LogLevelByTable the log levels of the statements of the tables, the statements of raw SQL are matched by the
+	// tables parsed from the SQL, and "*" matches the statements of the other tables, see DB.DebugIf
+	LogLevelByTable map[string]logger.LogLevel
+	// DebugFilter logs the statements matching it at Info level, see DB.DebugIf
+	DebugFilter func(stmt *Statement) bool
 	// NowFunc the function to be used when creating a new timestamp
 	NowFunc func() time.Time
-	// DryRun generate sql without execute
+	// DryRun generate sql without execute, the generated statements are recorded in Statement.SQLStatements
 	DryRun bool
-	// PrepareStmt executes the given query in cached statement
+	// DryRunWithHooks calls the hooks in DryRun mode, which are skipped by default as they may have side effects
+	DryRunWithHooks bool
+	// QueryComment the comment appended to the executed SQL, see Statement.SQLComment
+	QueryComment string
+	// CommentProvider returns the tags appended to the executed SQL as a comment in the sqlcommenter format, e.g:
+	// /*route='%2Fusers',traceparent='00-...-01'*/
+	CommentProvider func(ctx context.Context, stmt *Statement) map[string]string
+	// CommentDryRun appends the SQL comment to the SQL of DryRun mode and ToSQL, which are generated without it
+	CommentDryRun bool
+	// PreparedStmtCacheWithoutComment caches the prepared statements by the SQL without the comment, otherwise the
+	// comments changing with the context make every statement prepared and cached separately, the comment is still
+	// sent when preparing, so the database sees the comment of the first execution of every cached statement
+	PreparedStmtCacheWithoutComment bool
+	// PrepareStmt executes the given query in cached statement, it can be overridden per session by
+	// Session.PrepareStmt, e.g: disabled for the one-off queries polluting the cache
 	PrepareStmt bool
+	// PreparedStmtMaxSize the max number of cached statements, the least recently used ones are closed when exceeded
+	PreparedStmtMaxSize int
+	// PreparedStmtTTL closes the cached statements not used within the duration
+	PreparedStmtTTL time.Duration
 	// DisableAutomaticPing
 	DisableAutomaticPing bool
 	// DisableForeignKeyConstraintWhenMigrating
 	DisableForeignKeyConstraintWhenMigrating bool
 	// IgnoreRelationshipsWhenMigrating
 	IgnoreRelationshipsWhenMigrating bool
-	// DisableNestedTransaction disable nested transaction
+	// DisableNestedTransaction disable nested transaction, the nested transactions join the outer transaction without
+	// savepoints, overridden by Session.DisableNestedTransaction if not nil, e.g: false to create the savepoints of
+	// the session, or by TxJoin of the transaction
 	DisableNestedTransaction bool
 	// AllowGlobalUpdate allow global update
 	AllowGlobalUpdate bool
+	// BlockGlobalWrite returns ErrMissingWhereClause for updates and deletes without conditions, including raw sql
+	// executed with Exec, even when AllowGlobalUpdate is enabled, use AllowGlobal to allow them per statement
+	BlockGlobalWrite bool
+	// SoftDeleteByContextKey the context key of the value set to the field tagged with `softDeleteBy` when soft deleted
+	SoftDeleteByContextKey interface{}
+	// AuditUserExtractor extracts the acting user from the context, which is set to the fields tagged with
+	// `autoCreatedBy`, `autoUpdatedBy` and `autoDeletedBy` when creating, updating and soft deleting
+	AuditUserExtractor func(ctx context.Context) (interface{}, bool)
+	// TenantResolver resolves the tenant from the context, which restricts the queries, updates and deletes of the
+	// models having a field tagged with `tenant` and is set to the field when creating, see DB.WithoutTenancy
+	TenantResolver func(ctx context.Context) (interface{}, error)
+	// NestedColumnSeparator separates the prefixes of the columns scanned into the relationships or the embedded structs
+	// of the model, e.g: `o.total AS order__total` is scanned into the field Total of Order, defaults to "__"
+	NestedColumnSeparator string
+	// EmulateForeignKeys checks the foreign keys of the relationships in application code, the parents referenced by
+	// the belongs to relationships of the created and updated values should exist, and the values deleted with restrict
+	// semantics shouldn't be referenced by the children, returns ErrForeignKeyViolated otherwise, the relationships
+	// could also be checked with the tag `emulateForeignKey`, see DB.WithoutForeignKeyChecks
+	EmulateForeignKeys bool
+	// StrictAudit returns ErrMissingAuditUser when the acting user of the audit fields isn't found, the fields are
+	// left untouched by default
+	StrictAudit bool
 	// QueryFields executes the SQL query with all fields of the table
 	QueryFields bool
-	// CreateBatchSize default create batch size
+	// CreateBatchSize default create batch size, the batch size of Session overrides DefaultCreateBatchSize of the
+	// models, which overrides the one of Config
 	CreateBatchSize int
+	// IgnoreModelDefaults ignores the statement defaults declared by the models, see schema.DefaultSelectInterface,
+	// schema.DefaultCreateBatchSizeInterface and schema.DefaultClausesInterface
+	IgnoreModelDefaults bool
+	// MaxPerPage the max rows of the pages found by Paginate, the larger pages are limited to it, unlimited if zero
+	MaxPerPage int
 	// TranslateError enabling error translation
 	TranslateError bool
 	// PropagateUnscoped propagate Unscoped to every other nested statement
 	PropagateUnscoped bool
-
-	// ClauseBuilders clause builder
+	// StatementPool reuses the statements of finished queries started from a new DB, e.g: db.WithContext(ctx).First(&user),
+	// the statement of the returned DB only keeps the model/dest values when enabled
+	StatementPool bool
+	// PartialBatch only excludes the rows whose hooks failed when creating/updating a batch, returns *BatchError for them
+	PartialBatch bool
+	// SaveMode how Save determines whether to update or create the record with primary keys, see SaveByUpdate
+	SaveMode SaveMode
+	// RowsAffectedMode whether RowsAffected of the updates counts the matched or the changed rows for all the databases,
+	// see RowsAffectedMatched, it's reported by the driver by default
+	RowsAffectedMode RowsAffectedMode
+	// GroupMapsByKeys creates the slice of maps with one INSERT for the maps of every key set, instead of filling the
+	// keys missing in some maps with the default values of the columns
+	GroupMapsByKeys bool
+	// DeduplicateByPrimaryKey scans the rows of the same primary keys into the same struct for the slices of pointers,
+	// e.g: the parents duplicated by joins, the struct is allocated for the first row and shared by all the positions
+	// of the rows, so mutating it affects all of them, and the hooks, e.g: AfterFind, are called once for it
+	DeduplicateByPrimaryKey bool
+	// ReuseDest scans the rows of Find and Scan into the elements of the backing array of the destination slice if it
+	// has the capacity, instead of allocating the new elements, e.g: the polling queries with the same slice, the
+	// elements are zeroed before scanning, and the non-nil pointers of the slices of pointers are reused as well, so
+	// the elements and the pointers of the previous results, e.g: the ones kept by the callers or the caches, are
+	// overwritten, and the elements beyond the new length are left as they were
+	ReuseDest bool
+	// AllowPlannerEstimate allows EstimatedCount to estimate the rows of the queries with conditions by the row
+	// estimate of the query planner, which may be far from the exact count, they are counted exactly by default
+	AllowPlannerEstimate bool
+	// NilAsNull writes the comparisons of the named arguments of the conditions whose values are nil, e.g: `name = @name`,
+	// as IS NULL or IS NOT NULL like the nil values of map conditions, instead of `= NULL` which never matches
+	NilAsNull bool
+	// NormalizePlaceholders accepts the SQL of Raw and Exec with `?` or the numbered placeholders like `$1` for all the
+	// dialects, which are rewritten to the bind vars of the dialector, e.g: the SQL shared by Postgres and MySQL
+	NormalizePlaceholders bool
+	// ErrorOnEmptyIN returns ErrEmptyInCondition before executing the statements having IN conditions without values,
+	// e.g: `Where("id IN ?", []int{})` or `Find(&users, []int{})`, which match nothing and are logged at Info by default
+	ErrorOnEmptyIN bool
+	// UseModelConflictClause creates the models with the OnConflict clause declared by the `onConflict` setting of their
+	// unique indexes if no OnConflict clause is specified, the models implementing OnConflictClause always use it
+	UseModelConflictClause bool
+	// UUIDGenerator generates the UUIDs of the fields tagged with `default:uuid`, e.g: the UUIDs of a UUID library,
+	// NewUUIDv7 by default
+	UUIDGenerator func() ([16]byte, error)
+	// Sharder decides the shard tables of the sharded models, see Sharder
+	Sharder Sharder
+	// ShardScatter scatters the queries whose shard can't be determined by Sharder to all the shards listed by
+	// ShardLister, instead of returning ErrShardNotFound
+	ShardScatter bool
+	// SkipAutoTimeTracking skips populating the fields tracking create/update time, e.g: CreatedAt, UpdatedAt, and the
+	// `autoCreateTime`, `autoUpdateTime` fields, the hooks and the other auto fields are still populated
+	SkipAutoTimeTracking bool
+	// StrictTags returns schema.ErrInvalidTag for the models with unknown gorm tag keys or malformed values, instead of
+	// only logging the warnings when they are parsed
+	StrictTags bool
+	// TraceCallbacks logs the name and duration of every executed callback at Info level
+	TraceCallbacks bool
+	// OnConnect the statements executed on every new connection of the pool before it's used, e.g: SET time_zone, the
+	// connections failing them are closed instead of pooled, see OnConnectError. The dialector must implement
+	// ConnectorDialector, the pool is opened with its connector after Initialize, so configure it with DB.DB() after Open
+	OnConnect []string
+	// PoolEventHandler is called with the connection pool events, the waits for connections are only reported when the
+	// ConnPool is *sql.DB, which are observed from its statistics for the whole pool, see PoolWait
+	PoolEventHandler func(ctx context.Context, event PoolEvent)
+	// QueryHooks are called around every execution of the ConnPool, Before in order and After in reverse order
+	QueryHooks []QueryHook
+	// SQLRewriter rewrites the SQL and vars right before every execution of the ConnPool, including the statements of
+	// preloads and associations, the returned error aborts the statement, see Statement.RewrittenSQL
+	SQLRewriter func(ctx context.Context, sql string, vars []interface{}, stmt *Statement) (string, []interface{}, error)
+	// RetryTransient the max times to execute reads outside transactions again when they fail with transient errors
+	RetryTransient int
+	// TransientErrors the errors retried by RetryTransient, DefaultTransientErrors by default
+	TransientErrors []error
+	// Replicas reads outside transactions are sent to the replicas, use Clauses(gorm.Write) to read from the primary
+	Replicas []Dialector
+	// ReplicaPolicy chooses the replica serving a read, RoundRobinPolicy by default
+	ReplicaPolicy ReplicaPolicy
+	// ReplicaQuarantine how long a replica returning connection errors isn't read from, it doubles with every
+	// consecutive failure up to a minute, or itself if it's longer, 1s by default
+	ReplicaQuarantine time.Duration
+	// CacheStore caches the results of the reads with CacheTTL, which are invalidated by the creates, updates and
+	// deletes of the read tables, see Session.CacheTTL
+	CacheStore CacheStore
+	// CacheCodec serializes the cached results, GobCacheCodec by default
+	CacheCodec CacheCodec
+	// CacheTTL caches the results of the reads for the duration when CacheStore is set, usually enabled with Session
+	CacheTTL time.Duration
+	// CacheSkipAfterFind skips the AfterFind hooks of the results loaded from CacheStore
+	CacheSkipAfterFind bool
+	// CapabilityProbes the queries probing the capabilities which aren't declared by the dialector, whose results are
+	// cached, see DB.Supports and DefaultCapabilityProbes
+	CapabilityProbes map[Capability]string
+	// TrackVarBindings tracks the columns and clauses of the vars added by the typed clauses, which could be inspected
+	// with Statement.VarBindings by the query hooks and loggers
+	TrackVarBindings bool
+	// CaptureWarnings fetches the warnings of every statement from the dialector implementing WarningFetcher, which are
+	// logged at Warn and kept in Statement.Warnings, it's off by default as it may take an extra round trip
+	CaptureWarnings bool
+
+	// ModelHooks hooks called for every model row, see RegisterModelHook
+	ModelHooks []ModelHook
+
+	// ClauseBuilders clause builder, overridden by Session.ClauseBuilders and DB.WithClauseBuilder
 	ClauseBuilders map[string]clause.ClauseBuilder
 	// ConnPool db conn pool
 	ConnPool ConnPool
@@ -62,8 +217,18 @@ type Config struct {
 	// Plugins registered plugins
 	Plugins map[string]Plugin
 
-	callbacks  *callbacks
-	cacheStore *sync.Map
+	callbacks       *callbacks
+	cacheStore      *sync.Map
+	capabilities    *sync.Map
+	changeSnapshots *sync.Map
+	// namedScopes the scopes registered with RegisterScope by their names
+	namedScopes *sync.Map
+	// transactionHooks the OnCommit and OnRollback hooks by their open transactions, removed when the transaction ends
+	transactionHooks *sync.Map
+	// redact is set by Session.Redact, see RegisterRedactor
+	redact bool
+	// CreateBatchSize is set by Session, which overrides DefaultCreateBatchSize of the models
+	sessionBatchSize bool
 }
 
 // Apply update config to new config
@@ -103,21 +268,51 @@ type DB struct {
 
 // Session session config when create session with Session() method
 type Session struct {
-	DryRun                   bool
-	PrepareStmt              bool
+	DryRun          bool
+	DryRunWithHooks bool
+	CommentDryRun   bool
+	// PrepareStmt overrides Config.PrepareStmt for the session if not nil, the sessions enabling it share the cached
+	// statements with each other and the DB, the transactions begun from the session inherit it. With Replicas, the
+	// reads of the session are prepared on the replicas or not as well
+	PrepareStmt              *bool
 	NewDB                    bool
 	Initialized              bool
 	SkipHooks                bool
 	SkipDefaultTransaction   bool
-	DisableNestedTransaction bool
+	DisableNestedTransaction *bool
 	AllowGlobalUpdate        bool
+	BlockGlobalWrite         bool
 	FullSaveAssociations     bool
+	StrictAssociations       bool
 	PropagateUnscoped        bool
 	QueryFields              bool
+	IgnoreModelDefaults      bool
+	TraceCallbacks           bool
+	PartialBatch             bool
+	GroupMapsByKeys          bool
+	DeduplicateByPrimaryKey  bool
+	ReuseDest                bool
+	AllowPlannerEstimate     bool
+	UseModelConflictClause   bool
+	NilAsNull                bool
+	NormalizePlaceholders    bool
+	CaptureWarnings          bool
+	SaveMode                 SaveMode
+	RowsAffectedMode         RowsAffectedMode
+	TrackChanges             bool
+	Redact                   bool
+	ShardScatter             bool
+	SkipAutoTimeTracking     bool
+	QueryComment             string
+	CacheKey                 string
+	CacheTTL                 time.Duration
 	Context                  context.Context
 	Logger                   logger.Interface
+	LogLevelByTable          map[string]logger.LogLevel
+	ClauseBuilders           map[string]clause.ClauseBuilder
 	NowFunc                  func() time.Time
 	CreateBatchSize          int
+	RetryTransient           int
 }
 
 // Open initialize db session based on dialector
@@ -161,6 +356,10 @@ func Open(dialector Dialector, opts ...Option) (db *DB, err error) {
 		config.NowFunc = func() time.Time { return time.Now().Local() }
 	}
 
+	if config.UUIDGenerator == nil {
+		config.UUIDGenerator = NewUUIDv7
+	}
+
 	if dialector != nil {
 		config.Dialector = dialector
 	}
@@ -173,6 +372,18 @@ func Open(dialector Dialector, opts ...Option) (db *DB, err error) {
 		config.cacheStore = &sync.Map{}
 	}
 
+	if config.capabilities == nil {
+		config.capabilities = &sync.Map{}
+	}
+
+	if config.namedScopes == nil {
+		config.namedScopes = &sync.Map{}
+	}
+
+	if config.transactionHooks == nil {
+		config.transactionHooks = &sync.Map{}
+	}
+
 	db = &DB{Config: config, clone: 1}
 
 	db.callbacks = initializeCallbacks(db)
@@ -196,8 +407,22 @@ func Open(dialector Dialector, opts ...Option) (db *DB, err error) {
 		}
 	}
 
+	if err == nil && len(config.OnConnect) > 0 {
+		err = db.openOnConnectPool()
+	}
+
+	if config.PoolEventHandler != nil {
+		if sqlDB, ok := db.ConnPool.(*sql.DB); ok {
+			db.ConnPool = newPoolEventConnPool(sqlDB, config.PoolEventHandler)
+		}
+	}
+
+	if err == nil && len(config.Replicas) > 0 {
+		err = db.initializeReplicas()
+	}
+
 	if config.PrepareStmt {
-		preparedStmt := NewPreparedStmtDB(db.ConnPool)
+		preparedStmt := NewPreparedStmtDBWithCache(db.ConnPool, config.PreparedStmtMaxSize, config.PreparedStmtTTL)
 		db.cacheStore.Store(preparedStmtDBKey, preparedStmt)
 		db.ConnPool = preparedStmt
 	}
@@ -235,6 +460,7 @@ func (db *DB) Session(config *Session) *DB {
 	)
 	if config.CreateBatchSize > 0 {
 		tx.Config.CreateBatchSize = config.CreateBatchSize
+		tx.Config.sessionBatchSize = true
 	}
 
 	if config.SkipDefaultTransaction {
@@ -245,15 +471,23 @@ func (db *DB) Session(config *Session) *DB {
 		txConfig.AllowGlobalUpdate = true
 	}
 
+	if config.BlockGlobalWrite {
+		txConfig.BlockGlobalWrite = true
+	}
+
 	if config.FullSaveAssociations {
 		txConfig.FullSaveAssociations = true
 	}
 
+	if config.StrictAssociations {
+		txConfig.StrictAssociations = true
+	}
+
 	if config.PropagateUnscoped {
 		txConfig.PropagateUnscoped = true
 	}
 
-	if config.Context != nil || config.PrepareStmt || config.SkipHooks {
+	if config.Context != nil || config.PrepareStmt != nil || config.SkipHooks || config.CacheKey != "" {
 		tx.Statement = tx.Statement.clone()
 		tx.Statement.DB = tx
 	}
@@ -262,13 +496,17 @@ func (db *DB) Session(config *Session) *DB {
 		tx.Statement.Context = config.Context
 	}
 
-	if config.PrepareStmt {
+	if config.PrepareStmt != nil && !*config.PrepareStmt {
+		tx.Statement.ConnPool = unpreparedConnPool(tx.Statement.ConnPool)
+		txConfig.ConnPool = unpreparedConnPool(txConfig.ConnPool)
+		txConfig.PrepareStmt = false
+	} else if config.PrepareStmt != nil && !isPreparedConnPool(tx.Statement.ConnPool) {
 		var preparedStmt *PreparedStmtDB
 
 		if v, ok := db.cacheStore.Load(preparedStmtDBKey); ok {
 			preparedStmt = v.(*PreparedStmtDB)
 		} else {
-			preparedStmt = NewPreparedStmtDB(db.ConnPool)
+			preparedStmt = NewPreparedStmtDBWithCache(db.ConnPool, db.PreparedStmtMaxSize, db.PreparedStmtTTL)
 			db.cacheStore.Store(preparedStmtDBKey, preparedStmt)
 		}
 
@@ -278,11 +516,16 @@ func (db *DB) Session(config *Session) *DB {
 				Tx:             t,
 				PreparedStmtDB: preparedStmt,
 			}
+		case *pinnedConn:
+			// statements prepared on the connection can't outlive it
+			tx.Statement.ConnPool = t.preparedStmtDB(preparedStmt)
 		default:
 			tx.Statement.ConnPool = &PreparedStmtDB{
-				ConnPool: db.Config.ConnPool,
-				Mux:      preparedStmt.Mux,
-				Stmts:    preparedStmt.Stmts,
+				ConnPool:   db.Config.ConnPool,
+				Mux:        preparedStmt.Mux,
+				Stmts:      preparedStmt.Stmts,
+				cache:      preparedStmt.cache,
+				partitions: preparedStmt.partitions,
 			}
 		}
 		txConfig.ConnPool = tx.Statement.ConnPool
@@ -293,8 +536,16 @@ func (db *DB) Session(config *Session) *DB {
 		tx.Statement.SkipHooks = true
 	}
 
-	if config.DisableNestedTransaction {
-		txConfig.DisableNestedTransaction = true
+	if config.CacheKey != "" {
+		tx.Statement.CacheKey = config.CacheKey
+	}
+
+	if config.CacheTTL > 0 {
+		txConfig.CacheTTL = config.CacheTTL
+	}
+
+	if config.DisableNestedTransaction != nil {
+		txConfig.DisableNestedTransaction = *config.DisableNestedTransaction
 	}
 
 	if !config.NewDB {
@@ -305,10 +556,111 @@ func (db *DB) Session(config *Session) *DB {
 		tx.Config.DryRun = true
 	}
 
+	if config.DryRunWithHooks {
+		tx.Config.DryRunWithHooks = true
+	}
+
+	if config.CommentDryRun {
+		tx.Config.CommentDryRun = true
+	}
+
+	if config.QueryComment != "" {
+		tx.Config.QueryComment = config.QueryComment
+	}
+
 	if config.QueryFields {
 		tx.Config.QueryFields = true
 	}
 
+	if config.IgnoreModelDefaults {
+		tx.Config.IgnoreModelDefaults = true
+	}
+
+	if config.TraceCallbacks {
+		tx.Config.TraceCallbacks = true
+	}
+
+	if config.PartialBatch {
+		tx.Config.PartialBatch = true
+	}
+
+	if config.GroupMapsByKeys {
+		tx.Config.GroupMapsByKeys = true
+	}
+
+	if config.DeduplicateByPrimaryKey {
+		tx.Config.DeduplicateByPrimaryKey = true
+	}
+
+	if config.ReuseDest {
+		tx.Config.ReuseDest = true
+	}
+
+	if config.AllowPlannerEstimate {
+		tx.Config.AllowPlannerEstimate = true
+	}
+
+	if config.UseModelConflictClause {
+		tx.Config.UseModelConflictClause = true
+	}
+
+	if config.NilAsNull {
+		tx.Config.NilAsNull = true
+	}
+
+	if config.NormalizePlaceholders {
+		tx.Config.NormalizePlaceholders = true
+	}
+
+	if config.CaptureWarnings {
+		tx.Config.CaptureWarnings = true
+	}
+
+	if config.SaveMode != "" {
+		tx.Config.SaveMode = config.SaveMode
+	}
+
+	if config.RowsAffectedMode != "" {
+		tx.Config.RowsAffectedMode = config.RowsAffectedMode
+	}
+
+	if config.ShardScatter {
+		tx.Config.ShardScatter = true
+	}
+
+	if config.SkipAutoTimeTracking {
+		tx.Config.SkipAutoTimeTracking = true
+	}
+
+	// the snapshots of the loaded models, see ChangedFields, which are released after saved or by ForgetChanges
+	if config.TrackChanges && tx.Config.changeSnapshots == nil {
+		tx.Config.changeSnapshots = &sync.Map{}
+	}
+
+	if config.Redact {
+		tx.Config.redact = true
+	}
+
+	if config.RetryTransient > 0 {
+		tx.Config.RetryTransient = config.RetryTransient
+	}
+
+	if config.LogLevelByTable != nil {
+		tx.Config.LogLevelByTable = config.LogLevelByTable
+	}
+
+	if len(config.ClauseBuilders) > 0 {
+		// merged over the clause builders of the db, which are shared by its sessions
+		clauseBuilders := make(map[string]clause.ClauseBuilder, len(tx.Config.ClauseBuilders)+len(config.ClauseBuilders))
+		for name, builder := range tx.Config.ClauseBuilders {
+			clauseBuilders[name] = builder
+		}
+		for name, builder := range config.ClauseBuilders {
+			clauseBuilders[name] = builder
+		}
+		tx.Config.ClauseBuilders = clauseBuilders
+	}
+
 	if config.Logger != nil {
 		tx.Config.Logger = config.Logger
 	}
@@ -337,6 +689,16 @@ func (db *DB) Debug() (tx *DB) {
 	})
 }
 
+// DebugIf logs the statements matching fc at Info level, which is checked after the SQL built and before formatting
+// the SQL, e.g:
+//
+//	db.DebugIf(func(stmt *gorm.Statement) bool { return stmt.Table == "users" }).Find(&users)
+func (db *DB) DebugIf(fc func(stmt *Statement) bool) (tx *DB) {
+	tx = db.Session(&Session{})
+	tx.Config.DebugFilter = fc
+	return tx
+}
+
 // Set store value with key into current db instance's context
 func (db *DB) Set(key string, value interface{}) *DB {
 	tx := db.getInstance()
@@ -371,7 +733,7 @@ func (db *DB) AddError(err error) error {
 	if err != nil {
 		if db.Config.TranslateError {
 			if errTranslator, ok := db.Dialector.(ErrorTranslator); ok {
-				err = errTranslator.Translate(err)
+				err = translateConstraintError(err, errTranslator.Translate(err))
 			}
 		}
 
@@ -413,20 +775,29 @@ func (db *DB) getInstance() *DB {
 
 		if db.clone == 1 {
 			// clone with new statement
-			tx.Statement = &Statement{
-				DB:        tx,
-				ConnPool:  db.Statement.ConnPool,
-				Context:   db.Statement.Context,
-				Clauses:   map[string]clause.Clause{},
-				Vars:      make([]interface{}, 0, 8),
-				SkipHooks: db.Statement.SkipHooks,
+			if db.StatementPool {
+				tx.Statement = acquireStatement()
+			} else {
+				tx.Statement = &Statement{
+					Clauses: map[string]clause.Clause{},
+					Vars:    make([]interface{}, 0, 8),
+				}
 			}
+			tx.Statement.DB = tx
+			tx.Statement.ConnPool = db.Statement.ConnPool
+			tx.Statement.Context = db.Statement.Context
+			tx.Statement.SkipHooks = db.Statement.SkipHooks
+			tx.Statement.sqlStatements = db.Statement.sqlStatements
 			if db.Config.PropagateUnscoped {
 				tx.Statement.Unscoped = db.Statement.Unscoped
 			}
 		} else {
 			// with clone statement
-			tx.Statement = db.Statement.clone()
+			if db.StatementPool {
+				tx.Statement = db.Statement.cloneTo(acquireStatement())
+			} else {
+				tx.Statement = db.Statement.clone()
+			}
 			tx.Statement.DB = tx
 		}
 
@@ -492,6 +863,63 @@ func (db *DB) SetupJoinTable(model interface{}, field string, joinTable interfac
 	return nil
 }
 
+// TableModel the model with a dynamic table name, used by PrewarmSchemas
+type TableModel struct {
+	Model interface{}
+	Table string
+}
+
+// PrewarmSchemas parses and caches the schemas of models and their relationships in advance, so the first queries
+// won't parse schemas, use TableModel for the models queried with dynamic table names
+//
+//	db.PrewarmSchemas(&User{}, &Pet{}, gorm.TableModel{Model: &Log{}, Table: "logs_2024"})
+func (db *DB) PrewarmSchemas(models ...interface{}) (err error) {
+	for _, model := range models {
+		var (
+			s        *schema.Schema
+			parseErr error
+		)
+		if tableModel, ok := model.(TableModel); ok {
+			s, parseErr = schema.ParseWithSpecialTableName(tableModel.Model, db.cacheStore, db.NamingStrategy, tableModel.Table)
+		} else {
+			s, parseErr = schema.Parse(model, db.cacheStore, db.NamingStrategy)
+		}
+
+		if parseErr == nil && db.StrictTags {
+			parseErr = s.TagError()
+		}
+
+		if parseErr != nil {
+			parseErr = fmt.Errorf("failed to parse schema of %T: %w", model, parseErr)
+			if err == nil {
+				err = parseErr
+			} else {
+				err = fmt.Errorf("%v; %w", err, parseErr)
+			}
+		}
+	}
+	return err
+}
+
+// CachedSchemas returns the schemas cached by db, ordered by table name
+func (db *DB) CachedSchemas() []*schema.Schema {
+	var schemas []*schema.Schema
+	db.cacheStore.Range(func(key, value interface{}) bool {
+		if s, ok := value.(*schema.Schema); ok {
+			schemas = append(schemas, s)
+		}
+		return true
+	})
+
+	sort.Slice(schemas, func(i, j int) bool {
+		if schemas[i].Table == schemas[j].Table {
+			return schemas[i].Name < schemas[j].Name
+		}
+		return schemas[i].Table < schemas[j].Table
+	})
+	return schemas
+}
+
 // Use use plugin
 func (db *DB) Use(plugin Plugin) error {
 	name := plugin.Name()
@@ -519,3 +947,30 @@ func (db *DB) ToSQL(queryFn func(tx *DB) *DB) string {
 
 	return db.Dialector.Explain(stmt.SQL.String(), stmt.Vars...)
 }
+
+// ToSQLs generates the SQL strings of all the statements run by queryFn, in the order they would have been executed,
+// including the statements of the associations, the join tables and the preloads, unlike ToSQL returning the last one
+//
+//	db.ToSQLs(func(tx *gorm.DB) *gorm.DB {
+//		return tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(&user)
+//	})
+func (db *DB) ToSQLs(queryFn func(tx *DB) *DB) []string {
+	statements := db.ToSQLStatements(queryFn)
+	sqls := make([]string, len(statements))
+	for idx, statement := range statements {
+		sqls[idx] = db.Dialector.Explain(statement.SQL, statement.Vars...)
+	}
+	return sqls
+}
+
+// ToSQLStatements returns the SQL and vars of all the statements run by queryFn like ToSQLs
+func (db *DB) ToSQLStatements(queryFn func(tx *DB) *DB) []SQLStatement {
+	tx := db.Session(&Session{DryRun: true, SkipDefaultTransaction: true})
+	tx.Statement = tx.Statement.clone()
+	tx.Statement.DB = tx
+	tx.Statement.sqlStatements = &[]SQLStatement{}
+
+	statements := tx.Statement.sqlStatements
+	queryFn(tx)
+	return *statements
+}
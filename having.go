@@ -0,0 +1,269 @@
+package gorm
+
+import (
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm/clause"
+)
+
+// selectAliases returns the expressions of the aliases of the selected columns, which are written as `expr AS alias`,
+// e.g: total => sum(age) for Select("name, sum(age) as total"), the aliases are lower cased
+func (stmt *Statement) selectAliases() map[string]string {
+	var selects []string
+	selects = append(selects, stmt.Selects...)
+	if c, ok := stmt.Clauses["SELECT"]; ok {
+		if expr, ok := c.Expression.(clause.Expr); ok && len(expr.Vars) == 0 {
+			selects = append(selects, expr.SQL)
+		}
+	}
+
+	aliases := map[string]string{}
+	for _, s := range selects {
+		for _, column := range splitTopLevel(s, ',') {
+			words := splitTopLevel(column, ' ')
+			for idx := len(words) - 2; idx > 0; idx-- {
+				if strings.EqualFold(words[idx], "AS") {
+					expr := strings.TrimSpace(strings.Join(words[:idx], " "))
+					alias := strings.ToLower(strings.Trim(strings.Join(words[idx+1:], " "), "`\"[]"))
+					if expr != "" && alias != "" && !strings.EqualFold(expr, alias) {
+						aliases[alias] = expr
+					}
+					break
+				}
+			}
+		}
+	}
+	return aliases
+}
+
+// ExpandHavingAliases rewrites the aliases of the selected columns referenced by HAVING to the selected expressions,
+// for the databases rejecting the aliases in HAVING, or the counts replacing the selected columns, e.g:
+//
+//	// HAVING total > 10 => HAVING sum(age) > 10
+//	db.Model(&User{}).Select("name, sum(age) as total").Group("name").Having("total > ?", 10)
+func (stmt *Statement) ExpandHavingAliases() {
+	c, ok := stmt.Clauses["GROUP BY"]
+	if !ok {
+		return
+	}
+
+	groupBy, ok := c.Expression.(clause.GroupBy)
+	if !ok || len(groupBy.Having) == 0 {
+		return
+	}
+
+	aliases := stmt.selectAliases()
+	if len(aliases) == 0 {
+		return
+	}
+
+	having := make([]clause.Expression, len(groupBy.Having))
+	for idx, expr := range groupBy.Having {
+		having[idx] = expandAliases(expr, aliases)
+	}
+	groupBy.Having = having
+	c.Expression = groupBy
+	stmt.Clauses["GROUP BY"] = c
+}
+
+// expandAliases rewrites the aliases referenced by the condition
+func expandAliases(expr clause.Expression, aliases map[string]string) clause.Expression {
+	switch v := expr.(type) {
+	case clause.Expr:
+		v.SQL = expandSQLAliases(v.SQL, aliases)
+		return v
+	case clause.NamedExpr:
+		v.SQL = expandSQLAliases(v.SQL, aliases)
+		return v
+	case clause.Eq:
+		v.Column = expandColumnAlias(v.Column, aliases)
+		return v
+	case clause.Neq:
+		v.Column = expandColumnAlias(v.Column, aliases)
+		return v
+	case clause.Gt:
+		v.Column = expandColumnAlias(v.Column, aliases)
+		return v
+	case clause.Gte:
+		v.Column = expandColumnAlias(v.Column, aliases)
+		return v
+	case clause.Lt:
+		v.Column = expandColumnAlias(v.Column, aliases)
+		return v
+	case clause.Lte:
+		v.Column = expandColumnAlias(v.Column, aliases)
+		return v
+	case clause.Like:
+		v.Column = expandColumnAlias(v.Column, aliases)
+		return v
+	case clause.IN:
+		v.Column = expandColumnAlias(v.Column, aliases)
+		return v
+	case clause.AndConditions:
+		v.Exprs = expandAllAliases(v.Exprs, aliases)
+		return v
+	case clause.OrConditions:
+		v.Exprs = expandAllAliases(v.Exprs, aliases)
+		return v
+	case clause.NotConditions:
+		v.Exprs = expandAllAliases(v.Exprs, aliases)
+		return v
+	}
+	return expr
+}
+
+func expandAllAliases(exprs []clause.Expression, aliases map[string]string) []clause.Expression {
+	results := make([]clause.Expression, len(exprs))
+	for idx, expr := range exprs {
+		results[idx] = expandAliases(expr, aliases)
+	}
+	return results
+}
+
+// expandColumnAlias returns the selected expression if the column is an alias
+func expandColumnAlias(column interface{}, aliases map[string]string) interface{} {
+	switch v := column.(type) {
+	case string:
+		if expr, ok := aliases[strings.ToLower(v)]; ok {
+			return clause.Expr{SQL: parenthesize(expr)}
+		}
+	case clause.Column:
+		if expr, ok := aliases[strings.ToLower(v.Name)]; ok && v.Table == "" && v.Alias == "" {
+			return clause.Expr{SQL: parenthesize(expr)}
+		}
+	case clause.Expr:
+		v.SQL = expandSQLAliases(v.SQL, aliases)
+		return v
+	}
+	return column
+}
+
+// expandSQLAliases replaces the identifiers of the SQL which are aliases, the strings, the qualified names, e.g:
+// users.total, the functions and the named parameters aren't replaced
+func expandSQLAliases(sql string, aliases map[string]string) string {
+	var (
+		builder strings.Builder
+		runes   = []rune(sql)
+	)
+
+	isIdentRune := func(r rune) bool {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+
+	for idx := 0; idx < len(runes); {
+		switch r := runes[idx]; {
+		case r == '\'':
+			end := idx + 1
+			for end < len(runes) && runes[end] != '\'' {
+				end++
+			}
+			if end < len(runes) {
+				end++
+			}
+			builder.WriteString(string(runes[idx:end]))
+			idx = end
+		case r == '`' || r == '"':
+			end := idx + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			ident := string(runes[idx+1 : end])
+			if end < len(runes) {
+				end++
+			}
+			if expr, ok := aliases[strings.ToLower(ident)]; ok && (idx == 0 || runes[idx-1] != '.') && (end == len(runes) || runes[end] != '.') {
+				builder.WriteString(parenthesize(expr))
+			} else {
+				builder.WriteString(string(runes[idx:end]))
+			}
+			idx = end
+		case isIdentRune(r):
+			end := idx + 1
+			for end < len(runes) && isIdentRune(runes[end]) {
+				end++
+			}
+
+			ident := string(runes[idx:end])
+			qualified := idx > 0 && (runes[idx-1] == '.' || runes[idx-1] == '@' || runes[idx-1] == ':')
+			if expr, ok := aliases[strings.ToLower(ident)]; ok && !qualified && (end == len(runes) || (runes[end] != '.' && runes[end] != '(')) {
+				builder.WriteString(parenthesize(expr))
+			} else {
+				builder.WriteString(ident)
+			}
+			idx = end
+		default:
+			builder.WriteRune(r)
+			idx++
+		}
+	}
+	return builder.String()
+}
+
+// parenthesize wraps the expression with parentheses unless it is a name, a function call, e.g: sum(age), or already
+// parenthesized
+func parenthesize(expr string) string {
+	open := strings.IndexByte(expr, '(')
+	if open == -1 {
+		if !strings.ContainsAny(expr, " +-*/%<>=|&") {
+			return expr
+		}
+		return "(" + expr + ")"
+	}
+
+	for _, r := range expr[:open] {
+		if r != '_' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return "(" + expr + ")"
+		}
+	}
+
+	depth := 0
+	for idx, r := range expr[open:] {
+		if r == '(' {
+			depth++
+		} else if r == ')' {
+			if depth--; depth == 0 {
+				if open+idx == len(expr)-1 {
+					return expr
+				}
+				break
+			}
+		}
+	}
+	return "(" + expr + ")"
+}
+
+// splitTopLevel splits the SQL by sep outside the parentheses and the quotes, the empty parts are skipped
+func splitTopLevel(sql string, sep rune) (parts []string) {
+	var (
+		depth int
+		quote rune
+		start int
+	)
+
+	appendPart := func(part string) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	for idx, r := range sql {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == sep && depth == 0:
+			appendPart(sql[start:idx])
+			start = idx + len(string(r))
+		}
+	}
+	appendPart(sql[start:])
+	return parts
+}
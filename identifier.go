@@ -0,0 +1,84 @@
+package gorm
+
+import (
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// parseColumns parses the columns separated by commas, e.g: users.name, `order`, returns false if any of them isn't an
+// identifier, or none of them must be quoted, see clause.ParseQuotingIdentifier, the others are written as they are
+func parseColumns(names string) ([]clause.Column, bool) {
+	var (
+		parts   = splitTopLevel(names, ',')
+		columns = make([]clause.Column, 0, len(parts))
+		quoting bool
+	)
+
+	for _, part := range parts {
+		column, ok := parseColumn(part)
+		if !ok {
+			return nil, false
+		}
+		quoting = quoting || !column.Raw
+		columns = append(columns, column)
+	}
+	return columns, quoting
+}
+
+// parseOrderColumns parses the columns of ORDER BY separated by commas, which are identifiers followed by ASC or DESC
+// optionally, e.g: `order` desc, name, returns false if any of them isn't, or none of them must be quoted, see
+// splitOrderColumns
+func parseOrderColumns(order string) ([]clause.OrderByColumn, bool) {
+	columns, ok := splitOrderColumns(order)
+	if !ok {
+		return nil, false
+	}
+
+	for _, column := range columns {
+		if !column.Column.Raw {
+			return columns, true
+		}
+	}
+	return nil, false
+}
+
+// splitOrderColumns splits the columns of ORDER BY like parseOrderColumns, whether they must be quoted or not, the
+// names which needn't be quoted are raw, returns false if any of them isn't an identifier
+func splitOrderColumns(order string) ([]clause.OrderByColumn, bool) {
+	parts := splitTopLevel(order, ',')
+	columns := make([]clause.OrderByColumn, 0, len(parts))
+
+	for _, part := range parts {
+		var (
+			name = part
+			desc bool
+		)
+
+		if idx := strings.LastIndexAny(part, " \t\n"); idx != -1 {
+			switch direction := part[idx+1:]; {
+			case strings.EqualFold(direction, "ASC"):
+				name = part[:idx]
+			case strings.EqualFold(direction, "DESC"):
+				name, desc = part[:idx], true
+			}
+		}
+
+		column, ok := parseColumn(name)
+		if !ok {
+			return nil, false
+		}
+		columns = append(columns, clause.OrderByColumn{Column: column, Desc: desc})
+	}
+	return columns, len(columns) > 0
+}
+
+// parseColumn parses the identifier name into the column, which is raw unless it must be quoted
+func parseColumn(name string) (clause.Column, bool) {
+	if column, ok := clause.ParseQuotingIdentifier(name); ok {
+		return column, true
+	} else if _, ok := clause.ParseIdentifier(name); ok {
+		return clause.Column{Name: strings.TrimSpace(name), Raw: true}, true
+	}
+	return clause.Column{}, false
+}
@@ -3,6 +3,8 @@ package gorm
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"time"
 
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
@@ -20,6 +22,13 @@ type Dialector interface {
 	Explain(sql string, vars ...interface{}) string
 }
 
+// ConnectorDialector is implemented by the dialectors opening their pool with a driver.Connector, which is required by
+// Config.OnConnect, Connector returns the connector of the pool opened by Initialize, or nil if the pool was given by
+// the caller, e.g: the Conn of the dialector
+type ConnectorDialector interface {
+	Connector() (driver.Connector, error)
+}
+
 // Plugin GORM plugin interface
 type Plugin interface {
 	Name() string
@@ -30,6 +39,12 @@ type ParamsFilter interface {
 	ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{})
 }
 
+// SlowQueryExplainer is implemented by the loggers logging the query plans of slow queries, the plan of sql is logged at
+// Warn if ShouldExplain returns true
+type SlowQueryExplainer interface {
+	ShouldExplain(ctx context.Context, sql string, elapsed time.Duration) bool
+}
+
 // ConnPool db conns pool interface
 type ConnPool interface {
 	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
@@ -44,6 +59,18 @@ type SavePointerDialectorInterface interface {
 	RollbackTo(tx *DB, name string) error
 }
 
+// TxPreparer two-phase commit dialector interface
+type TxPreparer interface {
+	PrepareTransaction(tx *DB, id string) error
+	CommitPrepared(db *DB, id string) error
+	RollbackPrepared(db *DB, id string) error
+}
+
+// PreparedTransactionLister list in-doubt prepared transactions
+type PreparedTransactionLister interface {
+	PreparedTransactions(db *DB) ([]PreparedTransaction, error)
+}
+
 // TxBeginner tx beginner
 type TxBeginner interface {
 	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
@@ -72,11 +99,23 @@ type Valuer interface {
 	GormValue(context.Context, *DB) clause.Expr
 }
 
+// ScannerWithContext gorm scanner interface, which is preferred to sql.Scanner when scanning the values of the fields
+// and setting the values not assignable to the fields, the ctx is the context of the statement
+type ScannerWithContext interface {
+	ScanContext(ctx context.Context, db *DB, src interface{}) error
+}
+
 // GetDBConnector SQL db connector
 type GetDBConnector interface {
 	GetDBConn() (*sql.DB, error)
 }
 
+// PreparedStmtPartitioner returns the partition key of the prepared statements cache for the ConnPool,
+// pools with the same key share prepared statements, pools are partitioned by themselves by default
+type PreparedStmtPartitioner interface {
+	PreparedStmtPartition() interface{}
+}
+
 // Rows rows interface
 type Rows interface {
 	Columns() ([]string, error)
@@ -87,6 +126,15 @@ type Rows interface {
 	Close() error
 }
 
+// WarningFetcher fetches the warnings raised by the last statement executed on conn when Config.CaptureWarnings is
+// enabled, e.g: SHOW WARNINGS of MySQL, or the notices of Postgres collected by the notice handler of the connection,
+// conn is pinned to a connection for the statement unless it's a transaction or a connection already
+type WarningFetcher interface {
+	FetchWarnings(ctx context.Context, conn ConnPool) []Warning
+}
+
+// ErrorTranslator translates driver errors to gorm errors, constraint violations may be translated to a
+// *ConstraintError with the details of the violated constraint, see ParseConstraintError
 type ErrorTranslator interface {
 	Translate(err error) error
 }
@@ -0,0 +1,146 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// KeyCascadeResult the rows updated by UpdateKeyCascade
+type KeyCascadeResult struct {
+	// RowsAffected the updated rows of the tables, including the table of the model and the join tables
+	RowsAffected map[string]int64
+}
+
+// keyChange the old and new values of a changed key field
+type keyChange struct {
+	old, new interface{}
+}
+
+// UpdateKeyCascade updates the key fields of the model to values, keyed by the field names or the columns, and the
+// foreign keys of the has one, has many and many2many relationships referencing them in a transaction, like ON
+// UPDATE CASCADE of the database, e.g: the natural keys changed occasionally
+//
+//	db.Model(&country).UpdateKeyCascade(map[string]interface{}{"Code": "CZ"})
+//
+// The polymorphic children of the model's type are included, and the children whose foreign keys are referenced by
+// their own relationships are cascaded recursively. The model is updated before the children, so the foreign key
+// constraints of the database should be deferrable or disabled. The rows are updated without hooks, soft delete and
+// default scopes, the values of the model are set if succeeded
+func (db *DB) UpdateKeyCascade(values map[string]interface{}) (result KeyCascadeResult, err error) {
+	tx := db.getInstance()
+	if tx.Error != nil {
+		return result, tx.Error
+	}
+
+	if tx.Statement.Model == nil {
+		return result, ErrModelValueRequired
+	}
+
+	if err = tx.Statement.Parse(tx.Statement.Model); err != nil {
+		return result, err
+	}
+
+	var (
+		ctx          = tx.Statement.Context
+		sch          = tx.Statement.Schema
+		reflectValue = reflect.Indirect(reflect.ValueOf(tx.Statement.Model))
+		changes      = make(map[*schema.Field]keyChange, len(values))
+		assignments  = make(map[string]interface{}, len(values))
+	)
+	if reflectValue.Kind() != reflect.Struct {
+		return result, fmt.Errorf("%w: %T, which should be a struct of the model", ErrInvalidData, tx.Statement.Model)
+	}
+
+	for name, value := range values {
+		field := sch.LookUpField(name)
+		if field == nil || field.DBName == "" {
+			return result, fmt.Errorf("%w: %s isn't a field of %s", ErrInvalidField, name, sch.Name)
+		}
+
+		old, zero := field.ValueOf(ctx, reflectValue)
+		if zero {
+			return result, fmt.Errorf("%w: %s of %s is zero", ErrInvalidData, field.Name, sch.Name)
+		}
+		changes[field] = keyChange{old: old, new: value}
+		assignments[field.DBName] = value
+	}
+
+	result.RowsAffected = map[string]int64{}
+	err = tx.Transaction(func(tx *DB) error {
+		updateTx := tx.Session(&Session{NewDB: true}).Model(tx.Statement.Model).Omit(clause.Associations).UpdateColumns(assignments)
+		if updateTx.Error != nil {
+			return updateTx.Error
+		} else if updateTx.RowsAffected == 0 {
+			return fmt.Errorf("%w: %s to update the keys", ErrRecordNotFound, sch.Name)
+		}
+		result.RowsAffected[sch.Table] += updateTx.RowsAffected
+
+		return cascadeKeyChanges(tx, sch, changes, result.RowsAffected, map[*schema.Relationship]bool{})
+	})
+
+	if err == nil {
+		for field, change := range changes {
+			if err = field.Set(ctx, reflectValue, change.new); err != nil {
+				break
+			}
+		}
+	}
+	return result, err
+}
+
+// cascadeKeyChanges updates the foreign keys of the relationships of sch referencing the changed fields, then cascades
+// the changed foreign keys of the children to their relationships, every relationship is updated once
+func cascadeKeyChanges(tx *DB, sch *schema.Schema, changes map[*schema.Field]keyChange, rowsAffected map[string]int64, visited map[*schema.Relationship]bool) error {
+	relationships := make([]*schema.Relationship, 0, len(sch.Relationships.HasOne)+len(sch.Relationships.HasMany)+len(sch.Relationships.Many2Many))
+	relationships = append(relationships, sch.Relationships.HasOne...)
+	relationships = append(relationships, sch.Relationships.HasMany...)
+	relationships = append(relationships, sch.Relationships.Many2Many...)
+
+	for _, rel := range relationships {
+		if visited[rel] {
+			continue
+		}
+
+		var (
+			table        = rel.FieldSchema.Table
+			conds        []clause.Expression
+			assignments  = map[string]interface{}{}
+			childChanges = map[*schema.Field]keyChange{}
+		)
+		if rel.JoinTable != nil {
+			table = rel.JoinTable.Table
+		}
+
+		for _, ref := range rel.References {
+			if ref.PrimaryKey == nil {
+				// the type of the polymorphic children
+				conds = append(conds, clause.Eq{Column: ref.ForeignKey.DBName, Value: ref.PrimaryValue})
+			} else if change, ok := changes[ref.PrimaryKey]; ok && ref.OwnPrimaryKey {
+				conds = append(conds, clause.Eq{Column: ref.ForeignKey.DBName, Value: change.old})
+				assignments[ref.ForeignKey.DBName] = change.new
+				childChanges[ref.ForeignKey] = change
+			}
+		}
+
+		if len(assignments) == 0 {
+			continue
+		}
+		visited[rel] = true
+
+		updateTx := tx.Session(&Session{NewDB: true}).Table(table).Where(clause.And(conds...)).UpdateColumns(assignments)
+		if updateTx.Error != nil {
+			return updateTx.Error
+		}
+		rowsAffected[table] += updateTx.RowsAffected
+
+		if rel.JoinTable == nil {
+			if err := cascadeKeyChanges(tx, rel.FieldSchema, childChanges, rowsAffected, visited); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"sync"
 	"time"
 
 	"gorm.io/gorm/utils"
@@ -57,6 +58,11 @@ type Config struct {
 	IgnoreRecordNotFoundError bool
 	ParameterizedQueries      bool
 	LogLevel                  LogLevel
+	// ExplainSlowQueries logs the query plans of the queries slower than SlowThreshold at Warn, which are explained
+	// asynchronously without ANALYZE, once per SQL fingerprint per ExplainInterval, see utils.SQLFingerprint
+	ExplainSlowQueries bool
+	// ExplainInterval the interval explaining the same SQL again, defaults to 1 hour
+	ExplainInterval time.Duration
 }
 
 // Interface logger interface
@@ -65,6 +71,8 @@ type Interface interface {
 	Info(context.Context, string, ...interface{})
 	Warn(context.Context, string, ...interface{})
 	Error(context.Context, string, ...interface{})
+	// Trace traces the executed SQL, the SQL returned by fc could be grouped with utils.SQLFingerprint, which equals the
+	// Statement.Fingerprint of the executed statement
 	Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error)
 }
 
@@ -116,6 +124,7 @@ func New(writer Writer, config Config) Interface {
 		traceStr:     traceStr,
 		traceWarnStr: traceWarnStr,
 		traceErrStr:  traceErrStr,
+		explained:    &explainedSQLs{at: map[string]time.Time{}},
 	}
 }
 
@@ -124,6 +133,7 @@ type logger struct {
 	Config
 	infoStr, warnStr, errStr            string
 	traceStr, traceErrStr, traceWarnStr string
+	explained                           *explainedSQLs
 }
 
 // LogMode log mode
@@ -189,6 +199,57 @@ func (l *logger) Trace(ctx context.Context, begin time.Time, fc func() (string,
 	}
 }
 
+// ShouldExplain reports whether the slow sql should be explained, see Config.ExplainSlowQueries
+func (l *logger) ShouldExplain(ctx context.Context, sql string, elapsed time.Duration) bool {
+	if !l.ExplainSlowQueries || l.SlowThreshold == 0 || elapsed <= l.SlowThreshold || l.LogLevel < Warn {
+		return false
+	}
+
+	interval := l.ExplainInterval
+	if interval == 0 {
+		interval = time.Hour
+	}
+	return l.explained.mark(utils.SQLFingerprint(sql), interval)
+}
+
+// maxExplainedSQLs the max number of fingerprints kept by explainedSQLs
+const maxExplainedSQLs = 1000
+
+// explainedSQLs the last time the fingerprints of the slow SQLs were explained
+type explainedSQLs struct {
+	mu sync.Mutex
+	at map[string]time.Time
+}
+
+// mark marks fingerprint explained, returns false if it was explained within interval
+func (e *explainedSQLs) mark(fingerprint string, interval time.Duration) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	if at, ok := e.at[fingerprint]; ok && now.Sub(at) < interval {
+		return false
+	}
+
+	if len(e.at) >= maxExplainedSQLs {
+		var oldest string
+		for s, at := range e.at {
+			if now.Sub(at) >= interval {
+				delete(e.at, s)
+			} else if oldest == "" || at.Before(e.at[oldest]) {
+				oldest = s
+			}
+		}
+
+		// all of them were explained within interval, drops the oldest to keep the map bounded
+		if len(e.at) >= maxExplainedSQLs {
+			delete(e.at, oldest)
+		}
+	}
+	e.at[fingerprint] = now
+	return true
+}
+
 // ParamsFilter filter params
 func (l *logger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
 	if l.Config.ParameterizedQueries {
@@ -210,6 +271,11 @@ func (l *traceRecorder) New() *traceRecorder {
 	return &traceRecorder{Interface: l.Interface, BeginAt: time.Now()}
 }
 
+// LogMode keeps recording the traces of any log level
+func (l *traceRecorder) LogMode(LogLevel) Interface {
+	return l
+}
+
 // Trace implement logger interface
 func (l *traceRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
 	l.BeginAt = begin
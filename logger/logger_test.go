@@ -0,0 +1,42 @@
+package logger_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+func TestShouldExplainBounded(t *testing.T) {
+	l := logger.New(log.New(&strings.Builder{}, "", 0), logger.Config{
+		SlowThreshold:      time.Nanosecond,
+		LogLevel:           logger.Warn,
+		ExplainSlowQueries: true,
+	}).(interface {
+		ShouldExplain(ctx context.Context, sql string, elapsed time.Duration) bool
+	})
+
+	ctx := context.Background()
+	table := func(i int) string {
+		return strings.NewReplacer("0", "a", "1", "b", "2", "c", "3", "d", "4", "e", "5", "f", "6", "g", "7", "h", "8", "i", "9", "j").Replace(fmt.Sprint(i))
+	}
+
+	if !l.ShouldExplain(ctx, "SELECT * FROM users_"+table(0), time.Second) {
+		t.Fatalf("slow query should be explained")
+	}
+	if l.ShouldExplain(ctx, "SELECT * FROM users_"+table(0), time.Second) {
+		t.Fatalf("slow query should be explained once within the interval")
+	}
+
+	for i := 1; i <= 1000; i++ {
+		l.ShouldExplain(ctx, "SELECT * FROM users_"+table(i), time.Second)
+	}
+
+	if !l.ShouldExplain(ctx, "SELECT * FROM users_"+table(0), time.Second) {
+		t.Errorf("the oldest explained query should be dropped when too many queries explained within the interval")
+	}
+}
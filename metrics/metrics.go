@@ -0,0 +1,173 @@
+// Package metrics collects the count, latency, rows affected and errors of the statements per table and operation,
+// enabled by registering the plugin with a Collector:
+//
+//	collector := metrics.NewMemoryCollector()
+//	db.Use(metrics.New(collector))
+//
+//	collector.Snapshot()[metrics.Key{Table: "users", Operation: metrics.OperationQuery}].MaxDuration
+//
+// Collectors of other monitoring systems could be adapted with CollectorFunc, e.g. Prometheus:
+//
+//	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "gorm_statement_seconds"}, []string{"table", "operation", "status"})
+//	db.Use(metrics.New(metrics.CollectorFunc(func(table, operation string, duration time.Duration, rowsAffected int64, err error) {
+//	  status := "ok"
+//	  if err != nil {
+//	    status = "error"
+//	  }
+//	  histogram.WithLabelValues(table, operation, status).Observe(duration.Seconds())
+//	})))
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// the operations observed by the plugin, which are the names of the callback processors
+const (
+	OperationCreate = "create"
+	OperationQuery  = "query"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+	OperationRow    = "row"
+	OperationRaw    = "raw"
+)
+
+const startedAtKey = "metrics:started_at"
+
+// Collector observes the executed statements, the table is empty for the raw SQL without model
+type Collector interface {
+	ObserveQuery(table, operation string, duration time.Duration, rowsAffected int64, err error)
+}
+
+// CollectorFunc adapts a function to Collector
+type CollectorFunc func(table, operation string, duration time.Duration, rowsAffected int64, err error)
+
+// ObserveQuery implements Collector
+func (fc CollectorFunc) ObserveQuery(table, operation string, duration time.Duration, rowsAffected int64, err error) {
+	fc(table, operation, duration, rowsAffected, err)
+}
+
+// Plugin observes the statements of all operations with Collector, the statements of DryRun mode aren't observed
+type Plugin struct {
+	Collector Collector
+}
+
+// New returns the plugin observing the statements with collector
+func New(collector Collector) *Plugin {
+	return &Plugin{Collector: collector}
+}
+
+// Name implements gorm.Plugin
+func (p *Plugin) Name() string {
+	return "gorm:metrics"
+}
+
+// Initialize implements gorm.Plugin, registers the callbacks before and after the callbacks of every operation
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	for _, processor := range []struct {
+		operation     string
+		before, after func(name string, fn func(*gorm.DB)) error
+	}{
+		{OperationCreate, callback.Create().Before("*").Register, callback.Create().After("*").Register},
+		{OperationQuery, callback.Query().Before("*").Register, callback.Query().After("*").Register},
+		{OperationUpdate, callback.Update().Before("*").Register, callback.Update().After("*").Register},
+		{OperationDelete, callback.Delete().Before("*").Register, callback.Delete().After("*").Register},
+		{OperationRow, callback.Row().Before("*").Register, callback.Row().After("*").Register},
+		{OperationRaw, callback.Raw().Before("*").Register, callback.Raw().After("*").Register},
+	} {
+		if err := processor.before("metrics:before_"+processor.operation, before); err != nil {
+			return err
+		}
+
+		if err := processor.after("metrics:after_"+processor.operation, p.after(processor.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func before(db *gorm.DB) {
+	if !db.DryRun {
+		db.InstanceSet(startedAtKey, time.Now())
+	}
+}
+
+func (p *Plugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.DryRun {
+			return
+		}
+
+		if startedAt, ok := db.InstanceGet(startedAtKey); ok {
+			p.Collector.ObserveQuery(db.Statement.Table, operation, time.Since(startedAt.(time.Time)), db.RowsAffected, db.Error)
+		}
+	}
+}
+
+// Key the table and operation of the observed statements
+type Key struct {
+	Table     string
+	Operation string
+}
+
+// Stats the statistics of the observed statements
+type Stats struct {
+	Count         int64
+	Errors        int64
+	RowsAffected  int64
+	TotalDuration time.Duration
+	MaxDuration   time.Duration
+}
+
+// MemoryCollector collects the statistics in memory, which could be used in tests
+type MemoryCollector struct {
+	mu    sync.Mutex
+	stats map[Key]Stats
+}
+
+// NewMemoryCollector returns an empty MemoryCollector
+func NewMemoryCollector() *MemoryCollector {
+	return &MemoryCollector{stats: map[Key]Stats{}}
+}
+
+// ObserveQuery implements Collector
+func (c *MemoryCollector) ObserveQuery(table, operation string, duration time.Duration, rowsAffected int64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := Key{Table: table, Operation: operation}
+	stats := c.stats[key]
+	stats.Count++
+	stats.RowsAffected += rowsAffected
+	stats.TotalDuration += duration
+	if duration > stats.MaxDuration {
+		stats.MaxDuration = duration
+	}
+	if err != nil {
+		stats.Errors++
+	}
+	c.stats[key] = stats
+}
+
+// Snapshot returns a copy of the collected statistics
+func (c *MemoryCollector) Snapshot() map[Key]Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := make(map[Key]Stats, len(c.stats))
+	for key, stats := range c.stats {
+		snapshot[key] = stats
+	}
+	return snapshot
+}
+
+// Reset clears the collected statistics
+func (c *MemoryCollector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = map[Key]Stats{}
+}
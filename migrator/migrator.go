@@ -83,9 +83,37 @@ func (m Migrator) DataTypeOf(field *schema.Field) string {
 		}
 	}
 
+	if field.UUIDStorage != "" && field.TagSettings["TYPE"] == "" {
+		if dataType := uuidDataTypeOf(m.Dialector.Name(), field.UUIDStorage); dataType != "" {
+			return dataType
+		}
+	}
+
 	return m.Dialector.DataTypeOf(field)
 }
 
+// uuidDataTypeOf returns the data type of the UUIDs stored with storage, the data type of the dialector is used if
+// it's empty
+func uuidDataTypeOf(dialect string, storage schema.UUIDStorage) string {
+	switch dialect {
+	case "mysql", "sqlserver":
+		if storage == schema.UUIDBinary {
+			return "binary(16)"
+		}
+		return "char(36)"
+	case "postgres":
+		if storage == schema.UUIDBinary {
+			return "bytea"
+		}
+		return "uuid"
+	case "sqlite":
+		if storage == schema.UUIDBinary {
+			return "blob"
+		}
+	}
+	return ""
+}
+
 // FullDataTypeOf returns field's db full data type
 func (m Migrator) FullDataTypeOf(field *schema.Field) (expr clause.Expr) {
 	expr.SQL = m.DataTypeOf(field)
@@ -199,11 +227,61 @@ func (m Migrator) AutoMigrate(values ...interface{}) error {
 				return err
 			}
 		}
+
+		if err := m.migrateArchiveTable(value); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// migrateArchiveTable creates the archive table of the models soft deleted by archiving, it has the same columns
+// without constraints or indexes, so rows could be archived repeatedly, the missing columns are added to it
+func (m Migrator) migrateArchiveTable(value interface{}) error {
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		if stmt.Schema == nil || stmt.Schema.SoftDelete == nil {
+			return nil
+		}
+
+		archiveTable := stmt.Schema.SoftDelete.ArchiveTable(stmt.Table)
+		if archiveTable == "" {
+			return nil
+		}
+
+		queryTx, execTx := m.GetQueryAndExecTx()
+		hasTable := queryTx.Migrator().HasTable(archiveTable)
+
+		var (
+			createTableSQL = "CREATE TABLE ? ("
+			values         = []interface{}{clause.Table{Name: archiveTable}}
+		)
+		for _, dbName := range stmt.Schema.DBNames {
+			field := *stmt.Schema.FieldsByDBName[dbName]
+			if field.IgnoreMigration {
+				continue
+			}
+
+			field.PrimaryKey, field.AutoIncrement = false, false
+			dataType := clause.Expr{SQL: m.DataTypeOf(&field)}
+			if !hasTable {
+				createTableSQL += "? ?,"
+				values = append(values, clause.Column{Name: dbName}, dataType)
+			} else if !queryTx.Migrator().HasColumn(archiveTable, dbName) {
+				if err := execTx.Exec("ALTER TABLE ? ADD ? ?", clause.Table{Name: archiveTable}, clause.Column{Name: dbName}, dataType).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if hasTable {
+			return nil
+		}
+		createTableSQL = strings.TrimSuffix(createTableSQL, ",") + ")"
+		return execTx.Exec(createTableSQL, values...).Error
+	})
+}
+
 // GetTables returns tables
 func (m Migrator) GetTables() (tableList []string, err error) {
 	err = m.DB.Raw("SELECT TABLE_NAME FROM information_schema.tables where TABLE_SCHEMA=?", m.CurrentDatabase()).
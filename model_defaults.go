@@ -0,0 +1,76 @@
+package gorm
+
+import (
+	"reflect"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// createBatchSize returns the batch size of Create, the batch size of Session overrides DefaultCreateBatchSize of the
+// model, which overrides the one of Config
+func (db *DB) createBatchSize(value interface{}) int {
+	if db.sessionBatchSize || db.IgnoreModelDefaults {
+		return db.CreateBatchSize
+	}
+
+	model := db.Statement.Model
+	if model == nil {
+		model = value
+	}
+
+	if s, err := schema.Parse(model, db.cacheStore, db.NamingStrategy); err == nil && s.DefaultCreateBatchSize > 0 {
+		return s.DefaultCreateBatchSize
+	}
+	return db.CreateBatchSize
+}
+
+// AddModelDefaults applies DefaultSelect and DefaultClauses of the model to the query unless they are specified by the
+// statement, the columns are selected if the model is scanned without Joins, the clauses of the same names aren't
+// added, the query callbacks call it before building the statement
+func (stmt *Statement) AddModelDefaults() {
+	if stmt.Schema == nil || stmt.DB.IgnoreModelDefaults {
+		return
+	}
+
+	if _, ok := stmt.Clauses["model_defaults_enabled"]; ok {
+		return
+	}
+	stmt.Clauses["model_defaults_enabled"] = clause.Clause{}
+
+	if len(stmt.Selects) == 0 && len(stmt.Omits) == 0 && len(stmt.Joins) == 0 && stmt.scansModel() {
+		stmt.Selects = stmt.Schema.DefaultSelectColumns()
+	}
+
+	var conds []interface{}
+	for _, expr := range stmt.Schema.DefaultClauses {
+		if where, ok := expr.(clause.Where); ok {
+			stmt.AddClause(where)
+		} else if c, ok := expr.(clause.Interface); ok {
+			if _, specified := stmt.Clauses[c.Name()]; !specified {
+				stmt.AddClause(c)
+			}
+		} else if modifier, ok := expr.(StatementModifier); ok {
+			modifier.ModifyStatement(stmt)
+		} else {
+			conds = append(conds, expr)
+		}
+	}
+
+	if len(conds) > 0 {
+		stmt.AddClause(clause.Where{Exprs: stmt.BuildCondition(conds[0], conds[1:]...)})
+	}
+}
+
+// scansModel reports whether the rows of the statement are scanned into the model, not the other types, e.g: Count
+func (stmt *Statement) scansModel() bool {
+	if !stmt.ReflectValue.IsValid() {
+		return false
+	}
+
+	reflectType := stmt.ReflectValue.Type()
+	for reflectType.Kind() == reflect.Slice || reflectType.Kind() == reflect.Array || reflectType.Kind() == reflect.Ptr {
+		reflectType = reflectType.Elem()
+	}
+	return reflectType == stmt.Schema.ModelType
+}
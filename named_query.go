@@ -0,0 +1,119 @@
+package gorm
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"unicode"
+
+	"gorm.io/gorm/clause"
+)
+
+// namedQueries the query templates registered with RegisterQuery
+var namedQueries sync.Map
+
+// bindVarFunc the template function the printed values of the query templates are piped to, which renders them as
+// named vars instead of splicing them into the SQL
+const bindVarFunc = "gormBindVar"
+
+// RegisterQuery registers the query template with name, which could be queried with DB.Named, the optional fragments
+// are rendered by text/template with the params, the values printed by the template, e.g: {{.status}}, are bound as
+// vars, and the values could be passed as named vars as well, registering a name again replaces the query, e.g:
+//
+//	gorm.RegisterQuery("monthly_sales", `SELECT product_id, SUM(amount) AS total FROM orders
+//		WHERE created_at >= @since {{if .HasStatus}}AND status = {{.status}}{{end}} GROUP BY product_id`)
+func RegisterQuery(name, query string) error {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{bindVarFunc: fmt.Sprint}).Parse(query)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			bindPrintedValues(t.Tree, t.Tree.Root)
+		}
+	}
+
+	namedQueries.Store(name, tmpl)
+	return nil
+}
+
+// bindPrintedValues pipes the values printed by the actions of node to bindVarFunc
+func bindPrintedValues(tree *parse.Tree, node parse.Node) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node != nil {
+			for _, n := range node.Nodes {
+				bindPrintedValues(tree, n)
+			}
+		}
+	case *parse.ActionNode:
+		// actions declaring variables print nothing
+		if len(node.Pipe.Decl) == 0 {
+			node.Pipe.Cmds = append(node.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      node.Pos,
+				Args:     []parse.Node{parse.NewIdentifier(bindVarFunc).SetTree(tree).SetPos(node.Pos)},
+			})
+		}
+	case *parse.IfNode:
+		bindPrintedValues(tree, node.List)
+		bindPrintedValues(tree, node.ElseList)
+	case *parse.RangeNode:
+		bindPrintedValues(tree, node.List)
+		bindPrintedValues(tree, node.ElseList)
+	case *parse.WithNode:
+		bindPrintedValues(tree, node.List)
+		bindPrintedValues(tree, node.ElseList)
+	}
+}
+
+// Named queries from the query registered with RegisterQuery, the query rendered with params is used as a subquery,
+// whose named vars are resolved from params like Raw, so it could be chained to filter, paginate, count and scan its
+// results, use Unscoped when scanning into soft deleted models whose deleted at column isn't selected, e.g:
+//
+//	db.Named("monthly_sales", map[string]interface{}{"since": since, "HasStatus": true, "status": "paid"}).
+//		Order("total DESC").Limit(10).Find(&sales)
+func (db *DB) Named(name string, params interface{}) (tx *DB) {
+	tx = db.getInstance()
+
+	v, ok := namedQueries.Load(name)
+	if !ok {
+		tx.AddError(fmt.Errorf("%w: %s", ErrUnregisteredQuery, name))
+		return
+	}
+
+	tmpl, err := v.(*template.Template).Clone()
+	if err != nil {
+		tx.AddError(err)
+		return
+	}
+
+	vars := []interface{}{params}
+	tmpl.Funcs(template.FuncMap{bindVarFunc: func(value interface{}) string {
+		name := "gorm_named_" + strconv.Itoa(len(vars))
+		vars = append(vars, sql.Named(name, value))
+		return "@" + name + " "
+	}})
+
+	var query strings.Builder
+	if err := tmpl.Execute(&query, params); err != nil {
+		tx.AddError(fmt.Errorf("failed to render query %s: %w", name, err))
+		return
+	}
+
+	alias := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+
+	tx = tx.Table("(?) AS ?", clause.NamedExpr{SQL: strings.TrimSpace(query.String()), Vars: vars}, clause.Table{Name: alias})
+	tx.Statement.Table = alias
+	return
+}
@@ -0,0 +1,113 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// OnConnectError returned when a statement of Config.OnConnect or OnConnectConnector failed on a new connection, which is closed instead of
+// being added to the pool
+type OnConnectError struct {
+	SQL string
+	Err error
+}
+
+func (e *OnConnectError) Error() string {
+	return fmt.Sprintf("failed to initialize new connection with %q: %v", e.SQL, e.Err)
+}
+
+func (e *OnConnectError) Unwrap() error {
+	return e.Err
+}
+
+// OnConnectConnector wraps connector to execute the statements on every new connection before database/sql pools it,
+// e.g: SET time_zone, the connections failing them are closed and OnConnectError is returned, it's used by
+// Config.OnConnect, or open the pool given to a dialector not implementing ConnectorDialector with it, e.g:
+//
+//	connector, _ := mysqldriver.NewConnector(cfg)
+//	sqlDB := sql.OpenDB(gorm.OnConnectConnector(connector, "SET time_zone = '+00:00'"))
+//	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB}), &gorm.Config{})
+func OnConnectConnector(connector driver.Connector, statements ...string) driver.Connector {
+	return &onConnectConnector{Connector: connector, statements: statements}
+}
+
+// openOnConnectPool replaces the pool opened by the dialector with a pool of the same connector executing
+// Config.OnConnect on the new connections, the pool isn't touched if the dialector doesn't expose its connector
+func (db *DB) openOnConnectPool() error {
+	dialector, ok := db.Dialector.(ConnectorDialector)
+	if !ok {
+		return fmt.Errorf("%w: OnConnect requires the dialector %s to implement ConnectorDialector", ErrUnsupportedDriver, db.Dialector.Name())
+	}
+
+	connector, err := dialector.Connector()
+	if err != nil {
+		return err
+	} else if connector == nil {
+		return fmt.Errorf("%w: OnConnect requires the pool to be opened by the dialector %s, wrap the connector of the given pool with OnConnectConnector", ErrUnsupportedDriver, db.Dialector.Name())
+	}
+
+	sqlDB, ok := db.ConnPool.(*sql.DB)
+	if !ok {
+		return fmt.Errorf("%w: OnConnect requires the ConnPool to be *sql.DB, got %T", ErrUnsupportedDriver, db.ConnPool)
+	}
+
+	// the connections of the pool opened by the dialector weren't initialized, it's owned by the dialector as the
+	// connector is only exposed for the pools it opened
+	onConnectDB := sql.OpenDB(&onConnectConnector{Connector: connector, statements: db.OnConnect})
+	onConnectDB.SetMaxOpenConns(sqlDB.Stats().MaxOpenConnections)
+	db.ConnPool = onConnectDB
+	return sqlDB.Close()
+}
+
+// onConnectConnector executes the statements on every connection it opens, before database/sql pools it
+type onConnectConnector struct {
+	driver.Connector
+	statements []string
+}
+
+func (c *onConnectConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, statement := range c.statements {
+		if err := execDriverConn(ctx, conn, statement); err != nil {
+			_ = conn.Close()
+			return nil, &OnConnectError{SQL: statement, Err: err}
+		}
+	}
+	return conn, nil
+}
+
+// execDriverConn executes query on the driver connection, with the optional interfaces of it like database/sql
+func execDriverConn(ctx context.Context, conn driver.Conn, query string) error {
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		if _, err := execer.ExecContext(ctx, query, nil); err != driver.ErrSkip {
+			return err
+		}
+	}
+
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if preparer, ok := conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = conn.Prepare(query)
+	}
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	if execer, ok := stmt.(driver.StmtExecContext); ok {
+		_, err = execer.ExecContext(ctx, nil)
+	} else {
+		_, err = stmt.Exec(nil) //nolint:staticcheck
+	}
+	return err
+}
@@ -0,0 +1,230 @@
+package gorm
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Default the value written as the DEFAULT keyword, which sets the column to its database default, e.g:
+//
+//	db.Model(&User{}).Create(map[string]interface{}{"name": "jinzhu", "role": gorm.Default{}})
+//
+// SQLite doesn't support it, the value of the `default` tag of the field is written instead
+type Default struct{}
+
+// Build writes the DEFAULT keyword
+func (Default) Build(builder clause.Builder) {
+	builder.WriteString("DEFAULT")
+}
+
+type optionalState uint8
+
+const (
+	optionalUnset optionalState = iota
+	optionalSet
+	optionalDefault
+)
+
+// Optional the field whose value is created and updated when it's set, even if it's zero, e.g: 0 into a column having
+// a database default, which is skipped like the other zero values unless the field is selected, the value not set is
+// treated as zero and written as NULL, e.g:
+//
+//	type Product struct {
+//	  ID    uint
+//	  Stock gorm.Optional[int] `gorm:"default:10"`
+//	}
+//
+//	db.Create(&Product{Stock: gorm.Set(0)})            // INSERT INTO `products` (`stock`) VALUES (0)
+//	db.Create(&Product{Stock: gorm.UseDefault[int]()}) // INSERT INTO `products` (`stock`) VALUES (DEFAULT)
+type Optional[T any] struct {
+	V     T
+	state optionalState
+}
+
+// Set returns the Optional of value, which is written even if it's zero
+func Set[T any](value T) Optional[T] {
+	return Optional[T]{V: value, state: optionalSet}
+}
+
+// UseDefault returns the Optional written as Default, which sets the column to its database default
+func UseDefault[T any]() Optional[T] {
+	return Optional[T]{state: optionalDefault}
+}
+
+// IsSet reports whether the value is set, the scanned values are set unless they are NULL
+func (o Optional[T]) IsSet() bool {
+	return o.state == optionalSet
+}
+
+// ExplicitValue implements schema.ExplicitValuer
+func (o Optional[T]) ExplicitValue() (interface{}, bool) {
+	switch o.state {
+	case optionalSet:
+		return o.V, true
+	case optionalDefault:
+		return Default{}, true
+	}
+	return nil, false
+}
+
+// Scan implements the Scanner interface, NULL isn't set
+func (o *Optional[T]) Scan(value interface{}) error {
+	var zero T
+	o.V, o.state = zero, optionalUnset
+	if value == nil {
+		return nil
+	}
+
+	if scanner, ok := interface{}(&o.V).(sql.Scanner); ok {
+		if err := scanner.Scan(value); err != nil {
+			return err
+		}
+	} else if err := assignValue(reflect.ValueOf(&o.V).Elem(), value); err != nil {
+		return err
+	}
+	o.state = optionalSet
+	return nil
+}
+
+// Value implements the driver Valuer interface, the value isn't set is NULL
+func (o Optional[T]) Value() (driver.Value, error) {
+	if o.state != optionalSet {
+		return nil, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(o.V)
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if o.state != optionalSet {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.V)
+}
+
+func (o *Optional[T]) UnmarshalJSON(b []byte) error {
+	var zero T
+	o.V, o.state = zero, optionalUnset
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+
+	if err := json.Unmarshal(b, &o.V); err != nil {
+		return err
+	}
+	o.state = optionalSet
+	return nil
+}
+
+// assignValue assigns the value scanned from the database to dest, converting the numbers, strings and bytes, and the
+// times returned as text, e.g: the aggregates of SQLite
+func assignValue(dest reflect.Value, value interface{}) error {
+	src := reflect.ValueOf(value)
+	if src.Type().AssignableTo(dest.Type()) {
+		dest.Set(src)
+		return nil
+	}
+
+	s, isText := "", false
+	switch v := value.(type) {
+	case []byte:
+		s, isText = string(v), true
+	case string:
+		s, isText = v, true
+	}
+
+	var err error
+	switch kind := dest.Kind(); {
+	case dest.Type() == schema.TimeReflectType && isText:
+		var t time.Time
+		if t, err = parseTimeText(s); err == nil {
+			dest.Set(reflect.ValueOf(t))
+		}
+	case kind == reflect.String:
+		if isText {
+			dest.SetString(s)
+			return nil
+		}
+		dest.SetString(fmt.Sprint(value))
+		return nil
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, 64); !isText && src.CanInt() {
+			i, err = src.Int(), nil
+		}
+		if err == nil {
+			dest.SetInt(i)
+		}
+	case kind >= reflect.Uint && kind <= reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, 64); !isText && src.CanInt() && src.Int() >= 0 {
+			u, err = uint64(src.Int()), nil
+		} else if !isText && src.CanUint() {
+			u, err = src.Uint(), nil
+		}
+		if err == nil {
+			dest.SetUint(u)
+		}
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, 64); !isText && src.CanFloat() {
+			f, err = src.Float(), nil
+		} else if !isText && src.CanInt() {
+			f, err = float64(src.Int()), nil
+		}
+		if err == nil {
+			dest.SetFloat(f)
+		}
+	case kind == reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); !isText && src.Kind() == reflect.Bool {
+			b, err = src.Bool(), nil
+		} else if !isText && src.CanInt() {
+			b, err = src.Int() != 0, nil
+		}
+		if err == nil {
+			dest.SetBool(b)
+		}
+	default:
+		if src.Type().ConvertibleTo(dest.Type()) {
+			dest.Set(src.Convert(dest.Type()))
+			return nil
+		}
+		err = fmt.Errorf("unsupported type %T", value)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to scan %#v into %v: %w", value, dest.Type(), err)
+	}
+	return nil
+}
+
+// timeTextLayouts the layouts of the times returned as text, e.g: by SQLite
+var timeTextLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+func parseTimeText(s string) (t time.Time, err error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeTextLayouts {
+		if t, err = time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return t, err
+}
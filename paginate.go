@@ -0,0 +1,73 @@
+package gorm
+
+import (
+	"fmt"
+)
+
+// PageInfo the page found by Paginate
+type PageInfo struct {
+	// TotalRows the rows of all the pages
+	TotalRows  int64
+	TotalPages int
+	// Page the number of the page starting from 1
+	Page int
+	// PerPage the rows per page, which is limited by Config.MaxPerPage
+	PerPage int
+	HasNext bool
+}
+
+// Paginate counts the rows of the chained conditions and finds the rows of page into dest, the page starts from 1, e.g:
+//
+//	var users []User
+//	page, err := db.Where("age > ?", 18).Order("name").Preload("Orders").Paginate(2, 20, &users)
+//
+// The rows are counted like Count without the orders and the preloads, the grouped or distinct rows are counted by
+// wrapping the query as a subquery. perPage is limited by Config.MaxPerPage, and defaults to it if it's less than 1
+func (db *DB) Paginate(page, perPage int, dest interface{}) (info PageInfo, err error) {
+	if page < 1 {
+		page = 1
+	}
+
+	if maxPerPage := db.Config.MaxPerPage; maxPerPage > 0 && (perPage < 1 || perPage > maxPerPage) {
+		perPage = maxPerPage
+	} else if perPage < 1 {
+		return info, fmt.Errorf("%w: %d rows per page", ErrInvalidData, perPage)
+	}
+	info.Page, info.PerPage = page, perPage
+
+	// the conditions are shared by the count and the find, which use the clones of the statement
+	tx := db.Session(&Session{})
+	if tx.Error != nil {
+		return info, tx.Error
+	}
+
+	if err = tx.countPage(dest, &info.TotalRows); err != nil {
+		return info, err
+	}
+
+	info.TotalPages = int((info.TotalRows + int64(perPage) - 1) / int64(perPage))
+	info.HasNext = page < info.TotalPages
+
+	if err = tx.Limit(perPage).Offset((page - 1) * perPage).Find(dest).Error; err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+// countPage counts the rows of the paginated query, the grouped or distinct rows are counted by a subquery
+func (db *DB) countPage(dest interface{}, count *int64) error {
+	countTx := db.getInstance()
+	if countTx.Statement.Model == nil {
+		countTx.Statement.Model = dest
+	}
+	countTx.Statement.Preloads = map[string][]interface{}{}
+	delete(countTx.Statement.Clauses, "LIMIT")
+
+	_, grouped := countTx.Statement.Clauses["GROUP BY"]
+	if !grouped && !countTx.Statement.Distinct {
+		return countTx.Count(count).Error
+	}
+
+	delete(countTx.Statement.Clauses, "ORDER BY")
+	return db.Session(&Session{NewDB: true}).Raw("SELECT count(*) FROM (?) AS paginated_rows", countTx).Scan(count).Error
+}
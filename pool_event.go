@@ -0,0 +1,143 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PoolEventType connection pool event type
+type PoolEventType uint8
+
+// pool event types
+const (
+	PoolWait         PoolEventType = iota + 1 // the pool waited for connections since the previous PoolWait, see PoolEvent.Waits
+	PoolStmtPrepared                          // a statement was prepared
+	PoolTxBegin                               // a transaction was begun
+	PoolTxCommit                              // a transaction was committed
+	PoolTxRollback                            // a transaction was rolled back
+)
+
+func (t PoolEventType) String() string {
+	switch t {
+	case PoolWait:
+		return "wait"
+	case PoolStmtPrepared:
+		return "stmt_prepared"
+	case PoolTxBegin:
+		return "tx_begin"
+	case PoolTxCommit:
+		return "tx_commit"
+	case PoolTxRollback:
+		return "tx_rollback"
+	}
+	return fmt.Sprintf("PoolEventType(%d)", uint8(t))
+}
+
+// PoolEvent connection pool event passed to Config.PoolEventHandler
+type PoolEvent struct {
+	Type PoolEventType
+	// Waits the number of the connections waited for since the previous PoolWait, only set for PoolWait, which are the
+	// waits of the whole pool, e.g: of the concurrent statements, not only of the statement of the context
+	Waits int64
+	// Wait the total time waited for the connections of Waits, only set for PoolWait
+	Wait time.Duration
+	// Stats the statistics of the pool when PoolWait is reported, e.g: InUse and OpenConnections for the saturation
+	Stats sql.DBStats
+	// Duration how long the operation took for the events other than PoolWait
+	Duration time.Duration
+	// SQL the prepared query of PoolStmtPrepared
+	SQL string
+	Err error
+}
+
+// poolEventConnPool reports the events of *sql.DB, the statements run on the pool as is, and the waits for connections
+// are reported from the growth of the statistics of the pool observed after the statements, each wait is reported once
+// by the statement observing it first, which isn't necessarily the one waited
+type poolEventConnPool struct {
+	*sql.DB
+	handler func(context.Context, PoolEvent)
+
+	mux          sync.Mutex
+	waitCount    int64
+	waitDuration time.Duration
+}
+
+func newPoolEventConnPool(sqlDB *sql.DB, handler func(context.Context, PoolEvent)) *poolEventConnPool {
+	stats := sqlDB.Stats()
+	return &poolEventConnPool{DB: sqlDB, handler: handler, waitCount: stats.WaitCount, waitDuration: stats.WaitDuration}
+}
+
+func (pool *poolEventConnPool) GetDBConn() (*sql.DB, error) {
+	return pool.DB, nil
+}
+
+// observeWaits reports the waits of the pool since the previous report
+func (pool *poolEventConnPool) observeWaits(ctx context.Context) {
+	stats := pool.DB.Stats()
+
+	pool.mux.Lock()
+	waits, wait := stats.WaitCount-pool.waitCount, stats.WaitDuration-pool.waitDuration
+	if waits > 0 {
+		pool.waitCount, pool.waitDuration = stats.WaitCount, stats.WaitDuration
+	}
+	pool.mux.Unlock()
+
+	if waits > 0 {
+		pool.handler(ctx, PoolEvent{Type: PoolWait, Waits: waits, Wait: wait, Stats: stats})
+	}
+}
+
+func (pool *poolEventConnPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	start := time.Now()
+	stmt, err := pool.DB.PrepareContext(ctx, query)
+	pool.observeWaits(ctx)
+	pool.handler(ctx, PoolEvent{Type: PoolStmtPrepared, Duration: time.Since(start), SQL: query, Err: err})
+	return stmt, err
+}
+
+func (pool *poolEventConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := pool.DB.ExecContext(ctx, query, args...)
+	pool.observeWaits(ctx)
+	return result, err
+}
+
+func (pool *poolEventConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := pool.DB.QueryContext(ctx, query, args...)
+	pool.observeWaits(ctx)
+	return rows, err
+}
+
+func (pool *poolEventConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	row := pool.DB.QueryRowContext(ctx, query, args...)
+	pool.observeWaits(ctx)
+	return row
+}
+
+func (pool *poolEventConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	tx, err := pool.DB.BeginTx(ctx, opts)
+	pool.observeWaits(ctx)
+	return tx, err
+}
+
+// notifyPoolEvent reports the event to Config.PoolEventHandler
+func (db *DB) notifyPoolEvent(typ PoolEventType, start time.Time, err error) {
+	if db.PoolEventHandler != nil {
+		db.PoolEventHandler(db.Statement.Context, PoolEvent{Type: typ, Duration: time.Since(start), Err: err})
+	}
+}
+
+// Stats returns the statistics of the underlying *sql.DB, returns *UnsupportedConnPoolError if the ConnPool isn't backed by one
+func (db *DB) Stats() (sql.DBStats, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		connPool := db.ConnPool
+		if db.Statement != nil && db.Statement.ConnPool != nil {
+			connPool = db.Statement.ConnPool
+		}
+		return sql.DBStats{}, &UnsupportedConnPoolError{ConnPool: connPool, Err: err}
+	}
+	return sqlDB.Stats(), nil
+}
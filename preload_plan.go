@@ -0,0 +1,201 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// PreloadNode a relationship or an embedded struct preloaded by the statement, see Statement.PreloadPlan
+type PreloadNode struct {
+	// Name the name of the relationship or the embedded struct
+	Name string
+	// Path the path of the node from the preloaded model, e.g: Orders.Items
+	Path string
+	// Relationship the preloaded relationship, nil for the embedded struct, whose relationships are its children
+	Relationship *schema.Relationship
+	// Conds the conditions of the relationship, the conditions of clause.Associations are appended
+	Conds []interface{}
+	// Children the nodes preloaded with the values of the relationship, sorted by their names
+	Children []*PreloadNode
+}
+
+// PreloadPlan returns the trees of the relationships preloaded by the statement, in the order they are preloaded,
+// the paths of Preload are merged by the rules:
+//
+//  1. the paths are split by ".", the same relationship of the paths is one node, the nodes of every level are
+//     sorted by their names, which is the order they are preloaded
+//  2. the conditions of a path are applied to its last relationship, e.g: the orders of "Orders.Items" are preloaded
+//     without conditions unless "Orders" is preloaded with them
+//  3. clause.Associations preloads all the relationships of its level, including the ones of the embedded structs,
+//     its conditions are appended to the conditions of every relationship once
+//  4. the embedded structs are the nodes without relationship, their relationships are preloaded with the values
+//  5. a relationship with different conditions of two paths returns ErrPreloadConflict, e.g: "Pets.Toy" and
+//     clause.Associations+".Toy"
+func (stmt *Statement) PreloadPlan() ([]*PreloadNode, error) {
+	if stmt.Schema == nil {
+		if err := stmt.Parse(stmt.Model); err != nil {
+			return nil, err
+		}
+	}
+
+	root := &preloadPlanNode{schema: stmt.Schema, relationships: &stmt.Schema.Relationships}
+	names := make([]string, 0, len(stmt.Preloads))
+	for name := range stmt.Preloads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := root.add(strings.Split(name, "."), stmt.Preloads[name]); err != nil {
+			return nil, err
+		}
+	}
+	return root.nodes(), nil
+}
+
+// preloadPlanNode a node of the preload plan being built
+type preloadPlanNode struct {
+	PreloadNode
+	schema          *schema.Schema
+	relationships   *schema.Relationships
+	children        map[string]*preloadPlanNode
+	conds           []interface{}
+	hasConds        bool
+	associations    []interface{}
+	hasAssociations bool
+}
+
+// add adds the path to the node, whose conditions are applied to its last relationship
+func (node *preloadPlanNode) add(path []string, conds []interface{}) error {
+	name, rest := path[0], path[1:]
+	if name == clause.Associations {
+		for _, name := range node.names() {
+			child, err := node.child(name)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case child.Relationship == nil:
+				// the relationships of the embedded structs are the relationships of the level
+				err = child.add(path, conds)
+			case len(rest) == 0:
+				if child.hasAssociations && !sameConds(child.associations, conds) {
+					err = fmt.Errorf("%w: %s", ErrPreloadConflict, child.Path)
+				}
+				child.associations, child.hasAssociations = conds, true
+			default:
+				err = child.add(rest, conds)
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	child, err := node.child(name)
+	if err != nil {
+		return err
+	}
+
+	if len(rest) > 0 {
+		return child.add(rest, conds)
+	}
+
+	if child.hasConds && !sameConds(child.conds, conds) {
+		return fmt.Errorf("%w: %s", ErrPreloadConflict, child.Path)
+	}
+	child.conds, child.hasConds = conds, true
+	return nil
+}
+
+// names returns the sorted names of the relationships and the embedded structs of the node
+func (node *preloadPlanNode) names() []string {
+	names := make([]string, 0, len(node.relationships.Relations)+len(node.relationships.EmbeddedRelations))
+	for name, rel := range node.relationships.Relations {
+		// the relationships of the embedded structs are also the relationships of the schema, which are preloaded with
+		// the embedded structs
+		embedded := node.relationships == &node.schema.Relationships && len(rel.Field.EmbeddedBindNames) > 1
+		if rel.Schema == node.schema && !embedded {
+			names = append(names, name)
+		}
+	}
+
+	for name := range node.relationships.EmbeddedRelations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// child returns the child node of the relationship or the embedded struct
+func (node *preloadPlanNode) child(name string) (*preloadPlanNode, error) {
+	if child, ok := node.children[name]; ok {
+		return child, nil
+	}
+
+	child := &preloadPlanNode{PreloadNode: PreloadNode{Name: name, Path: name}}
+	if node.Path != "" {
+		child.Path = node.Path + "." + name
+	}
+
+	if relationships := node.relationships.EmbeddedRelations[name]; relationships != nil {
+		child.schema, child.relationships = node.schema, relationships
+	} else if rel := node.relationships.Relations[name]; rel != nil {
+		child.Relationship = rel
+		child.schema, child.relationships = rel.FieldSchema, &rel.FieldSchema.Relationships
+	} else {
+		return nil, fmt.Errorf("%s: %w for schema %s", name, ErrUnsupportedRelation, node.schema.Name)
+	}
+
+	if node.children == nil {
+		node.children = map[string]*preloadPlanNode{}
+	}
+	node.children[name] = child
+	return child, nil
+}
+
+// nodes returns the sorted children with their conditions merged
+func (node *preloadPlanNode) nodes() []*PreloadNode {
+	names := make([]string, 0, len(node.children))
+	for name := range node.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	nodes := make([]*PreloadNode, 0, len(names))
+	for _, name := range names {
+		child := node.children[name]
+		result := child.PreloadNode
+		result.Conds = append(append([]interface{}{}, child.conds...), child.associations...)
+		result.Children = child.nodes()
+		nodes = append(nodes, &result)
+	}
+	return nodes
+}
+
+// sameConds returns true if the conditions are equal, the functions are equal if they are the same function
+func sameConds(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for idx := range a {
+		va, vb := reflect.ValueOf(a[idx]), reflect.ValueOf(b[idx])
+		if va.Kind() == reflect.Func && vb.Kind() == reflect.Func {
+			if va.Pointer() != vb.Pointer() {
+				return false
+			}
+		} else if !reflect.DeepEqual(a[idx], b[idx]) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,12 +1,15 @@
 package gorm
 
 import (
+	"container/list"
 	"context"
 	"database/sql"
 	"database/sql/driver"
 	"errors"
 	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Stmt struct {
@@ -14,19 +17,267 @@ type Stmt struct {
 	Transaction bool
 	prepared    chan struct{}
 	prepareErr  error
+
+	query    string
+	inflight *sync.WaitGroup
+	elem     *list.Element
+	usedAt   time.Time
+}
+
+// release marks the stmt is not used by the caller anymore
+func (stmt Stmt) release() {
+	if stmt.inflight != nil {
+		stmt.inflight.Done()
+	}
+}
+
+// close closes the underlying statement once it is prepared and all in-flight uses finished
+func (stmt *Stmt) close() {
+	go func() {
+		<-stmt.prepared
+		if stmt.inflight != nil {
+			stmt.inflight.Wait()
+		}
+		if stmt.Stmt != nil {
+			_ = stmt.Stmt.Close()
+		}
+	}()
+}
+
+// PreparedStmtStats statistics of the prepared statement cache
+type PreparedStmtStats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Size      int
+}
+
+// stmtCache tracks the usage of cached statements, shared by all sessions of a PreparedStmtDB, statements are
+// evicted when exceeding maxSize (least recently used first) or not used within ttl
+type stmtCache struct {
+	hits      int64
+	misses    int64
+	evictions int64
+	maxSize   int
+	ttl       time.Duration
+	mux       sync.Mutex
+	lru       *list.List
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+func (c *stmtCache) bounded() bool {
+	return c != nil && (c.maxSize > 0 || c.ttl > 0)
+}
+
+func (c *stmtCache) hit(stmt *Stmt) {
+	if c == nil {
+		return
+	}
+
+	atomic.AddInt64(&c.hits, 1)
+	if c.bounded() && stmt.elem != nil {
+		c.mux.Lock()
+		c.lru.MoveToFront(stmt.elem)
+		stmt.usedAt = time.Now()
+		c.mux.Unlock()
+	}
+}
+
+func (c *stmtCache) add(stmt *Stmt) {
+	if c == nil {
+		return
+	}
+
+	atomic.AddInt64(&c.misses, 1)
+	if c.bounded() {
+		c.mux.Lock()
+		stmt.usedAt = time.Now()
+		stmt.elem = c.lru.PushFront(stmt)
+		c.mux.Unlock()
+	}
+}
+
+func (c *stmtCache) remove(stmt *Stmt) {
+	if c.bounded() && stmt.elem != nil {
+		c.mux.Lock()
+		c.lru.Remove(stmt.elem)
+		stmt.elem = nil
+		c.mux.Unlock()
+	}
+}
+
+func (c *stmtCache) reset() {
+	if c.bounded() {
+		c.mux.Lock()
+		c.lru.Init()
+		c.mux.Unlock()
+	}
+}
+
+func (c *stmtCache) close() {
+	if c != nil {
+		c.stopOnce.Do(func() { close(c.stop) })
+	}
+}
+
+// isPreparedConnPool reports whether connPool executes the queries with prepared statements
+func isPreparedConnPool(connPool ConnPool) bool {
+	switch connPool.(type) {
+	case *PreparedStmtDB, *PreparedStmtTX:
+		return true
+	}
+	return false
+}
+
+// unpreparedConnPool returns the ConnPool of connPool executing the queries without prepared statements
+func unpreparedConnPool(connPool ConnPool) ConnPool {
+	switch pool := connPool.(type) {
+	case *PreparedStmtDB:
+		return pool.ConnPool
+	case *PreparedStmtTX:
+		return pool.Tx
+	}
+	return connPool
 }
 
 type PreparedStmtDB struct {
 	Stmts map[string]*Stmt
 	Mux   *sync.RWMutex
 	ConnPool
+
+	cache        *stmtCache
+	partitions   *stmtPartitions
+	partitionKey interface{}
+}
+
+// stmtPartitions the prepared statements caches of other ConnPools, e.g: read replicas, shared by all partitions
+type stmtPartitions struct {
+	mux sync.Mutex
+	dbs map[interface{}]*PreparedStmtDB
+}
+
+func preparedStmtPartitionOf(connPool ConnPool) interface{} {
+	if partitioner, ok := connPool.(PreparedStmtPartitioner); ok {
+		return partitioner.PreparedStmtPartition()
+	}
+	return connPool
 }
 
+// NewPreparedStmtDB creates a PreparedStmtDB caching the prepared statements unbounded
 func NewPreparedStmtDB(connPool ConnPool) *PreparedStmtDB {
-	return &PreparedStmtDB{
-		ConnPool: connPool,
-		Stmts:    make(map[string]*Stmt),
-		Mux:      &sync.RWMutex{},
+	return NewPreparedStmtDBWithCache(connPool, 0, 0)
+}
+
+// NewPreparedStmtDBWithCache creates a PreparedStmtDB, the cached statements are unbounded if maxSize and ttl are zero,
+// otherwise the least recently used statements are closed when exceeding maxSize, and the statements not used
+// within ttl are closed by a background sweeper until the PreparedStmtDB closed
+func NewPreparedStmtDBWithCache(connPool ConnPool, maxSize int, ttl time.Duration) *PreparedStmtDB {
+	db := &PreparedStmtDB{
+		ConnPool:   connPool,
+		Stmts:      make(map[string]*Stmt),
+		Mux:        &sync.RWMutex{},
+		cache:      &stmtCache{maxSize: maxSize, ttl: ttl, lru: list.New(), stop: make(chan struct{})},
+		partitions: &stmtPartitions{dbs: map[interface{}]*PreparedStmtDB{}},
+	}
+
+	if ttl > 0 {
+		go db.sweep()
+	}
+	return db
+}
+
+// Partition returns the PreparedStmtDB which prepares statements on connPool with its own cache, plugins that
+// switch ConnPool per statement (e.g: read replicas) should use it so statements are prepared on the pool they execute on
+func (db *PreparedStmtDB) Partition(connPool ConnPool) *PreparedStmtDB {
+	key := preparedStmtPartitionOf(connPool)
+	if db.partitions == nil || key == preparedStmtPartitionOf(db.ConnPool) {
+		return db
+	}
+
+	db.partitions.mux.Lock()
+	defer db.partitions.mux.Unlock()
+
+	partition, ok := db.partitions.dbs[key]
+	if !ok {
+		var (
+			maxSize int
+			ttl     time.Duration
+		)
+		if db.cache != nil {
+			maxSize, ttl = db.cache.maxSize, db.cache.ttl
+		}
+
+		partition = NewPreparedStmtDBWithCache(connPool, maxSize, ttl)
+		partition.partitions, partition.partitionKey = db.partitions, key
+		db.partitions.dbs[key] = partition
+	}
+	return partition
+}
+
+// Stats returns the statistics of the prepared statement cache
+func (db *PreparedStmtDB) Stats() (stats PreparedStmtStats) {
+	if db.cache != nil {
+		stats.Hits = atomic.LoadInt64(&db.cache.hits)
+		stats.Misses = atomic.LoadInt64(&db.cache.misses)
+		stats.Evictions = atomic.LoadInt64(&db.cache.evictions)
+	}
+
+	db.Mux.RLock()
+	stats.Size = len(db.Stmts)
+	db.Mux.RUnlock()
+	return
+}
+
+func (db *PreparedStmtDB) sweep() {
+	interval := db.cache.ttl / 2
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-db.cache.stop:
+			return
+		case <-ticker.C:
+			db.Mux.Lock()
+			db.evict(func(stmt *Stmt) bool { return time.Since(stmt.usedAt) > db.cache.ttl })
+			db.Mux.Unlock()
+		}
+	}
+}
+
+// evict closes the least recently used statements while shouldEvict returns true, must be called with db.Mux locked
+func (db *PreparedStmtDB) evict(shouldEvict func(*Stmt) bool) {
+	for {
+		db.cache.mux.Lock()
+		elem := db.cache.lru.Back()
+		if elem == nil || !shouldEvict(elem.Value.(*Stmt)) {
+			db.cache.mux.Unlock()
+			return
+		}
+
+		stmt := elem.Value.(*Stmt)
+		db.cache.lru.Remove(elem)
+		stmt.elem = nil
+		db.cache.mux.Unlock()
+
+		if db.Stmts[stmt.query] == stmt {
+			delete(db.Stmts, stmt.query)
+		}
+		atomic.AddInt64(&db.cache.evictions, 1)
+		stmt.close()
+	}
+}
+
+// removeStmt removes the cached statement of query, must be called with db.Mux locked
+func (db *PreparedStmtDB) removeStmt(query string) {
+	if stmt, ok := db.Stmts[query]; ok {
+		db.cache.remove(stmt)
+		delete(db.Stmts, query)
 	}
 }
 
@@ -47,16 +298,30 @@ func (db *PreparedStmtDB) Close() {
 	defer db.Mux.Unlock()
 
 	for _, stmt := range db.Stmts {
-		go func(s *Stmt) {
-			// make sure the stmt must finish preparation first
-			<-s.prepared
-			if s.Stmt != nil {
-				_ = s.Close()
-			}
-		}(stmt)
+		// make sure the stmt must finish preparation first
+		stmt.close()
 	}
 	// setting db.Stmts to nil to avoid further using
 	db.Stmts = nil
+	db.cache.reset()
+	db.cache.close()
+
+	if db.partitions != nil {
+		db.partitions.mux.Lock()
+		partitions := db.partitions.dbs
+		if db.partitionKey != nil {
+			// closing a partition only releases its own statements
+			delete(db.partitions.dbs, db.partitionKey)
+			partitions = nil
+		} else {
+			db.partitions.dbs = map[interface{}]*PreparedStmtDB{}
+		}
+		db.partitions.mux.Unlock()
+
+		for _, partition := range partitions {
+			partition.Close()
+		}
+	}
 }
 
 func (sdb *PreparedStmtDB) Reset() {
@@ -64,24 +329,24 @@ func (sdb *PreparedStmtDB) Reset() {
 	defer sdb.Mux.Unlock()
 
 	for _, stmt := range sdb.Stmts {
-		go func(s *Stmt) {
-			// make sure the stmt must finish preparation first
-			<-s.prepared
-			if s.Stmt != nil {
-				_ = s.Close()
-			}
-		}(stmt)
+		// make sure the stmt must finish preparation first
+		stmt.close()
 	}
 	sdb.Stmts = make(map[string]*Stmt)
+	sdb.cache.reset()
 }
 
 func (db *PreparedStmtDB) prepare(ctx context.Context, conn ConnPool, isTransaction bool, query string) (Stmt, error) {
+	key := preparedStmtKey(ctx, query)
 	db.Mux.RLock()
-	if stmt, ok := db.Stmts[query]; ok && (!stmt.Transaction || isTransaction) {
+	if stmt, ok := db.Stmts[key]; ok && (!stmt.Transaction || isTransaction) {
+		stmt.inflight.Add(1)
+		db.cache.hit(stmt)
 		db.Mux.RUnlock()
 		// wait for other goroutines prepared
 		<-stmt.prepared
 		if stmt.prepareErr != nil {
+			stmt.release()
 			return Stmt{}, stmt.prepareErr
 		}
 
@@ -91,11 +356,14 @@ func (db *PreparedStmtDB) prepare(ctx context.Context, conn ConnPool, isTransact
 
 	db.Mux.Lock()
 	// double check
-	if stmt, ok := db.Stmts[query]; ok && (!stmt.Transaction || isTransaction) {
+	if stmt, ok := db.Stmts[key]; ok && (!stmt.Transaction || isTransaction) {
+		stmt.inflight.Add(1)
+		db.cache.hit(stmt)
 		db.Mux.Unlock()
 		// wait for other goroutines prepared
 		<-stmt.prepared
 		if stmt.prepareErr != nil {
+			stmt.release()
 			return Stmt{}, stmt.prepareErr
 		}
 
@@ -108,8 +376,18 @@ func (db *PreparedStmtDB) prepare(ctx context.Context, conn ConnPool, isTransact
 		return Stmt{}, ErrInvalidDB
 	}
 	// cache preparing stmt first
-	cacheStmt := Stmt{Transaction: isTransaction, prepared: make(chan struct{})}
-	db.Stmts[query] = &cacheStmt
+	cacheStmt := Stmt{Transaction: isTransaction, prepared: make(chan struct{}), query: key, inflight: &sync.WaitGroup{}}
+	cacheStmt.inflight.Add(1)
+	if stmt, ok := db.Stmts[key]; ok {
+		// replace the stmt prepared in transaction
+		db.removeStmt(key)
+		stmt.close()
+	}
+	db.Stmts[key] = &cacheStmt
+	db.cache.add(&cacheStmt)
+	if db.cache.bounded() && db.cache.maxSize > 0 {
+		db.evict(func(*Stmt) bool { return len(db.Stmts) > db.cache.maxSize })
+	}
 	db.Mux.Unlock()
 
 	// prepare completed
@@ -124,8 +402,11 @@ func (db *PreparedStmtDB) prepare(ctx context.Context, conn ConnPool, isTransact
 	if err != nil {
 		cacheStmt.prepareErr = err
 		db.Mux.Lock()
-		delete(db.Stmts, query)
+		if db.Stmts[key] == &cacheStmt {
+			db.removeStmt(key)
+		}
 		db.Mux.Unlock()
+		cacheStmt.release()
 		return Stmt{}, err
 	}
 
@@ -160,12 +441,13 @@ func (db *PreparedStmtDB) BeginTx(ctx context.Context, opt *sql.TxOptions) (Conn
 func (db *PreparedStmtDB) ExecContext(ctx context.Context, query string, args ...interface{}) (result sql.Result, err error) {
 	stmt, err := db.prepare(ctx, db.ConnPool, false, query)
 	if err == nil {
+		defer stmt.release()
 		result, err = stmt.ExecContext(ctx, args...)
 		if errors.Is(err, driver.ErrBadConn) {
 			db.Mux.Lock()
 			defer db.Mux.Unlock()
 			go stmt.Close()
-			delete(db.Stmts, query)
+			db.removeStmt(stmt.query)
 		}
 	}
 	return result, err
@@ -174,13 +456,14 @@ func (db *PreparedStmtDB) ExecContext(ctx context.Context, query string, args ..
 func (db *PreparedStmtDB) QueryContext(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
 	stmt, err := db.prepare(ctx, db.ConnPool, false, query)
 	if err == nil {
+		defer stmt.release()
 		rows, err = stmt.QueryContext(ctx, args...)
 		if errors.Is(err, driver.ErrBadConn) {
 			db.Mux.Lock()
 			defer db.Mux.Unlock()
 
 			go stmt.Close()
-			delete(db.Stmts, query)
+			db.removeStmt(stmt.query)
 		}
 	}
 	return rows, err
@@ -189,6 +472,7 @@ func (db *PreparedStmtDB) QueryContext(ctx context.Context, query string, args .
 func (db *PreparedStmtDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
 	stmt, err := db.prepare(ctx, db.ConnPool, false, query)
 	if err == nil {
+		defer stmt.release()
 		return stmt.QueryRowContext(ctx, args...)
 	}
 	return &sql.Row{}
@@ -228,13 +512,14 @@ func (tx *PreparedStmtTX) Rollback() error {
 func (tx *PreparedStmtTX) ExecContext(ctx context.Context, query string, args ...interface{}) (result sql.Result, err error) {
 	stmt, err := tx.PreparedStmtDB.prepare(ctx, tx.Tx, true, query)
 	if err == nil {
+		defer stmt.release()
 		result, err = tx.Tx.StmtContext(ctx, stmt.Stmt).ExecContext(ctx, args...)
 		if errors.Is(err, driver.ErrBadConn) {
 			tx.PreparedStmtDB.Mux.Lock()
 			defer tx.PreparedStmtDB.Mux.Unlock()
 
 			go stmt.Close()
-			delete(tx.PreparedStmtDB.Stmts, query)
+			tx.PreparedStmtDB.removeStmt(stmt.query)
 		}
 	}
 	return result, err
@@ -243,13 +528,14 @@ func (tx *PreparedStmtTX) ExecContext(ctx context.Context, query string, args ..
 func (tx *PreparedStmtTX) QueryContext(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
 	stmt, err := tx.PreparedStmtDB.prepare(ctx, tx.Tx, true, query)
 	if err == nil {
+		defer stmt.release()
 		rows, err = tx.Tx.StmtContext(ctx, stmt.Stmt).QueryContext(ctx, args...)
 		if errors.Is(err, driver.ErrBadConn) {
 			tx.PreparedStmtDB.Mux.Lock()
 			defer tx.PreparedStmtDB.Mux.Unlock()
 
 			go stmt.Close()
-			delete(tx.PreparedStmtDB.Stmts, query)
+			tx.PreparedStmtDB.removeStmt(stmt.query)
 		}
 	}
 	return rows, err
@@ -258,6 +544,7 @@ func (tx *PreparedStmtTX) QueryContext(ctx context.Context, query string, args .
 func (tx *PreparedStmtTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
 	stmt, err := tx.PreparedStmtDB.prepare(ctx, tx.Tx, true, query)
 	if err == nil {
+		defer stmt.release()
 		return tx.Tx.StmtContext(ctx, stmt.Stmt).QueryRowContext(ctx, args...)
 	}
 	return &sql.Row{}
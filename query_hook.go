@@ -0,0 +1,149 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gorm.io/gorm/utils"
+)
+
+// QueryHook is called around every execution of the ConnPool, including the prepared statements and the
+// transactions, which could be used for tracing without the overhead of building the SQL, see Config.QueryHooks
+type QueryHook interface {
+	// Before is called before executing, the returned context is used to execute and passed to After
+	Before(ctx context.Context, stmt *Statement) context.Context
+	// After is called after executed with the error, the statement has the executed SQL and vars, whose queries could be
+	// grouped with Statement.Fingerprint
+	After(ctx context.Context, stmt *Statement, err error)
+}
+
+// the operations of the statements passed to the query hooks, see Statement.Operation
+const (
+	OperationQuery    = "query"
+	OperationExec     = "exec"
+	OperationRow      = "row"
+	OperationBegin    = "begin"
+	OperationCommit   = "commit"
+	OperationRollback = "rollback"
+)
+
+// Operation returns the operation of the ConnPool executing the statement, which is only set for the query hooks
+func (stmt *Statement) Operation() string {
+	return stmt.operation
+}
+
+// ExecContext executes the SQL of the statement with its ConnPool, calling the query hooks around it
+func (stmt *Statement) ExecContext() (sql.Result, error) {
+	query, vars, err := stmt.RewrittenSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stmt.DB.QueryHooks) == 0 {
+		return stmt.ConnPool.ExecContext(stmt.connContext(stmt.Context), query, vars...)
+	}
+
+	ctx := stmt.beforeQueryHooks(OperationExec)
+	result, err := stmt.ConnPool.ExecContext(stmt.connContext(ctx), query, vars...)
+	stmt.afterQueryHooks(ctx, err)
+	return result, err
+}
+
+// QueryContext queries the SQL of the statement with its ConnPool, calling the query hooks around it
+func (stmt *Statement) QueryContext() (*sql.Rows, error) {
+	query, vars, err := stmt.RewrittenSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stmt.DB.QueryHooks) == 0 {
+		return stmt.ConnPool.QueryContext(stmt.connContext(stmt.Context), query, vars...)
+	}
+
+	ctx := stmt.beforeQueryHooks(OperationQuery)
+	rows, err := stmt.ConnPool.QueryContext(stmt.connContext(ctx), query, vars...)
+	stmt.afterQueryHooks(ctx, err)
+	return rows, err
+}
+
+// QueryRowContext queries a row with the SQL of the statement with its ConnPool, calling the query hooks around it,
+// returns nil if the SQL isn't rewritten by Config.SQLRewriter, whose error is added to the statement
+func (stmt *Statement) QueryRowContext() *sql.Row {
+	query, vars, err := stmt.RewrittenSQL()
+	if err != nil {
+		stmt.AddError(err)
+		return nil
+	}
+
+	if len(stmt.DB.QueryHooks) == 0 {
+		return stmt.ConnPool.QueryRowContext(stmt.connContext(stmt.Context), query, vars...)
+	}
+
+	ctx := stmt.beforeQueryHooks(OperationRow)
+	row := stmt.ConnPool.QueryRowContext(stmt.connContext(ctx), query, vars...)
+	stmt.afterQueryHooks(ctx, row.Err())
+	return row
+}
+
+// RewrittenSQL returns the SQL and vars executed for the statement, which are ExecSQL and Vars rewritten by
+// Config.SQLRewriter, the SQL passed to the prepared statements is rewritten too, so they are cached by the rewritten
+// SQL. ErrInvalidData is returned if the rewritten vars aren't bound by the rewritten SQL
+func (stmt *Statement) RewrittenSQL() (string, []interface{}, error) {
+	query := stmt.ExecSQL()
+	if stmt.DB.SQLRewriter == nil {
+		return query, stmt.Vars, nil
+	}
+
+	ctx := stmt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	rewritten, vars, err := stmt.DB.SQLRewriter(ctx, query, stmt.Vars, stmt)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// the vars added or removed should be bound by the placeholders added or removed
+	if rewritten != query || len(vars) != len(stmt.Vars) {
+		if added, bound := len(vars)-len(stmt.Vars), utils.CountSQLBindVars(rewritten)-utils.CountSQLBindVars(query); added != bound {
+			return "", nil, fmt.Errorf("%w: the rewritten SQL binds %d more vars, but %d vars are added", ErrInvalidData, bound, added)
+		}
+	}
+	return rewritten, vars, nil
+}
+
+// beforeQueryHooks calls Before of the query hooks in order, returns the context for executing
+func (stmt *Statement) beforeQueryHooks(operation string) context.Context {
+	stmt.operation = operation
+	ctx := stmt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for _, hook := range stmt.DB.QueryHooks {
+		ctx = hook.Before(ctx, stmt)
+	}
+	return ctx
+}
+
+// afterQueryHooks calls After of the query hooks in reverse order
+func (stmt *Statement) afterQueryHooks(ctx context.Context, err error) {
+	for idx := len(stmt.DB.QueryHooks) - 1; idx >= 0; idx-- {
+		stmt.DB.QueryHooks[idx].After(ctx, stmt, err)
+	}
+	stmt.operation = ""
+}
+
+// runTxQueryHooks runs fc committing or rolling back the transaction, calling the query hooks around it
+func (stmt *Statement) runTxQueryHooks(operation string, fc func() error) error {
+	if len(stmt.DB.QueryHooks) == 0 {
+		return fc()
+	}
+
+	ctx := stmt.beforeQueryHooks(operation)
+	err := fc()
+	stmt.afterQueryHooks(ctx, err)
+	return err
+}
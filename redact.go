@@ -0,0 +1,317 @@
+package gorm
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gorm.io/gorm/schema"
+)
+
+// redactedKey the setting of the statements loading the models with the redacted fields
+const redactedKey = "gorm:redacted"
+
+var redactors sync.Map
+
+// RegisterRedactor registers the redactor of the fields tagged with `redact:name`, which redacts the scanned values
+// of the fields when the models are loaded with Session.Redact, the values scanned into the maps and the destinations
+// other than the model, e.g: Pluck and Scan, are redacted by the fields of the same columns, the rows of Row and Rows
+// are redacted once scanned by ScanRows. The NULL values aren't redacted, registering a name again replaces the
+// redactor. The models holding the redacted values are rejected by Save and Updates with ErrRedactedModel until
+// they're reloaded, see Redacted, e.g:
+//
+//	gorm.RegisterRedactor("phone", func(value interface{}) interface{} {
+//		if phone, _ := value.(string); len(phone) > 4 {
+//			return "***" + phone[len(phone)-4:]
+//		}
+//		return "***"
+//	})
+//
+//	type Customer struct {
+//		ID    uint
+//		Email string `gorm:"redact:email"`
+//		Phone string `gorm:"redact:phone"`
+//	}
+//
+//	db.Session(&gorm.Session{Redact: true}).Find(&customers) // Email: j***@example.com
+func RegisterRedactor(name string, redactor func(interface{}) interface{}) {
+	redactors.Store(strings.ToLower(name), redactor)
+}
+
+func init() {
+	RegisterRedactor("email", RedactEmail)
+	RegisterRedactor("card", RedactCard)
+}
+
+// RedactEmail keeps the first letter and the domain of the email, e.g: j***@example.com, which is registered as
+// `redact:email`
+func RedactEmail(value interface{}) interface{} {
+	email, ok := value.(string)
+	if !ok {
+		return nil
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return "***"
+	}
+
+	_, size := utf8.DecodeRuneInString(email)
+	return email[:size] + "***" + email[at:]
+}
+
+// RedactCard keeps the last 4 digits of the card number, e.g: ************4242, which is registered as `redact:card`
+func RedactCard(value interface{}) interface{} {
+	card, ok := value.(string)
+	if !ok {
+		return nil
+	}
+
+	runes := []rune(card)
+	for idx := range runes {
+		if idx < len(runes)-4 || len(runes) <= 4 {
+			runes[idx] = '*'
+		}
+	}
+	return string(runes)
+}
+
+// redactingField returns the field of the model redacting the scanned values of column, nil if they aren't redacted
+func (stmt *Statement) redactingField(column string) *schema.Field {
+	if stmt.Schema != nil {
+		if field := stmt.Schema.LookUpField(column); field != nil {
+			if _, ok := field.TagSettings["REDACT"]; ok {
+				return field
+			}
+		}
+	}
+	return nil
+}
+
+// redactedDestValue returns the value scanned into field of the destination redacted, the fields of the destinations
+// other than the model, e.g: the structs of Scan, are redacted by the fields of the model with the same columns
+func (stmt *Statement) redactedDestValue(field *schema.Field, value interface{}) interface{} {
+	if _, ok := field.TagSettings["REDACT"]; !ok && field.Schema != stmt.Schema && field.DBName != "" {
+		if modelField := stmt.redactingField(field.DBName); modelField != nil {
+			return stmt.redactedValue(modelField, value)
+		}
+	}
+	return stmt.redactedValue(field, value)
+}
+
+// redactedValue returns the scanned value of field redacted by the redactor of its `redact` tag, the unregistered
+// redactors redact the values to the zero values
+func (stmt *Statement) redactedValue(field *schema.Field, value interface{}) interface{} {
+	name, ok := field.TagSettings["REDACT"]
+	if !ok {
+		return value
+	}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return value
+		}
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() {
+		return value
+	}
+
+	scanned := rv.Interface()
+	if valuer, ok := scanned.(driver.Valuer); ok {
+		scanned, _ = valuer.Value()
+	}
+
+	if scanned == nil {
+		return value
+	}
+
+	if bytes, ok := scanned.([]byte); ok && field.DataType == schema.String {
+		scanned = string(bytes)
+	}
+
+	stmt.Settings.Store(redactedKey, true)
+	redactor, ok := redactors.Load(strings.ToLower(strings.TrimSpace(name)))
+	if !ok {
+		stmt.AddError(fmt.Errorf("%w: %s of field %s", ErrUnregisteredRedactor, name, field.Name))
+		return nil
+	}
+	return redactor.(func(interface{}) interface{})(scanned)
+}
+
+// Redacted records the redacted values of the model loaded with Session.Redact, the models still holding any of them
+// are rejected by Save and Updates of all the sessions with ErrRedactedModel, it's ignored by the migrations and the
+// writes with the `-` tag. The models without it are only rejected by the DB returned by their load, e.g:
+//
+//	type Customer struct {
+//		ID       uint
+//		Email    string        `gorm:"redact:email"`
+//		Redacted gorm.Redacted `gorm:"-"`
+//	}
+type Redacted struct {
+	values map[string]interface{}
+}
+
+// IsRedacted reports whether any field of the model still holds the redacted value of its load
+func (r Redacted) IsRedacted() bool {
+	return len(r.values) > 0
+}
+
+var redactedType = reflect.TypeOf(Redacted{})
+
+// redactedField returns the Redacted field of s, nil if s doesn't have it
+func redactedField(s *schema.Schema) *schema.Field {
+	for _, field := range s.Fields {
+		if field.FieldType == redactedType {
+			return field
+		}
+	}
+	return nil
+}
+
+// RecordRedacted records the redacted values of the loaded models into their Redacted fields, and resets the fields
+// of the models reloaded without redaction, it's called by the query callbacks after scanning
+func (stmt *Statement) RecordRedacted() {
+	if stmt.Schema == nil {
+		return
+	}
+
+	state := redactedField(stmt.Schema)
+	if state == nil {
+		return
+	}
+
+	_, redacted := stmt.Settings.Load(redactedKey)
+	eachModel(stmt.ReflectValue, func(rv reflect.Value) {
+		if rv.Type() != stmt.Schema.ModelType {
+			return
+		}
+
+		var record Redacted
+		if redacted {
+			for _, field := range stmt.Schema.Fields {
+				if _, ok := field.TagSettings["REDACT"]; ok {
+					if value, isZero := field.ValueOf(stmt.Context, rv); !isZero {
+						if record.values == nil {
+							record.values = map[string]interface{}{}
+						}
+						record.values[field.Name] = reflect.Indirect(reflect.ValueOf(value)).Interface()
+					}
+				}
+			}
+		}
+		stmt.AddError(state.Set(stmt.Context, rv, record))
+	})
+}
+
+// checkRedacted returns ErrRedactedModel if value holds the redacted values or was loaded by the statement of db
+// with the redacted fields, so the redacted values never overwrite the real data
+func (db *DB) checkRedacted(value interface{}) error {
+	if _, ok := db.Statement.Settings.Load(redactedKey); ok {
+		return ErrRedactedModel
+	}
+	return db.checkRedactedModels(value)
+}
+
+// checkRedactedModels returns ErrRedactedModel if any model of value still holds the redacted values recorded in its
+// Redacted field
+func (db *DB) checkRedactedModels(value interface{}) (err error) {
+	var (
+		modelType reflect.Type
+		s         *schema.Schema
+		state     *schema.Field
+	)
+
+	eachModel(reflect.ValueOf(value), func(rv reflect.Value) {
+		if err != nil {
+			return
+		}
+
+		if rv.Type() != modelType {
+			modelType, s, state = rv.Type(), nil, nil
+			if s, _ = schema.Parse(rv.Addr().Interface(), db.cacheStore, db.NamingStrategy); s != nil {
+				state = redactedField(s)
+			}
+		}
+
+		if state == nil {
+			return
+		}
+
+		record, _ := state.ValueOf(db.Statement.Context, rv)
+		for name, redacted := range record.(Redacted).values {
+			if field := s.FieldsByName[name]; field != nil {
+				current, _ := field.ValueOf(db.Statement.Context, rv)
+				if reflect.DeepEqual(reflect.Indirect(reflect.ValueOf(current)).Interface(), redacted) {
+					err = fmt.Errorf("%w: field %s of %s", ErrRedactedModel, field.Name, rv.Type())
+					return
+				}
+			}
+		}
+	})
+	return err
+}
+
+// redactMap redacts the scanned values of mapValue by the fields of the model with the same columns
+func (stmt *Statement) redactMap(mapValue map[string]interface{}) {
+	for column, value := range mapValue {
+		if field := stmt.redactingField(column); field != nil {
+			mapValue[column] = stmt.redactedValue(field, value)
+		}
+	}
+}
+
+// redactScanned redacts the value of the column of field scanned into rv, e.g: the values of Pluck
+func (stmt *Statement) redactScanned(field *schema.Field, rv reflect.Value) {
+	for rv.Kind() == reflect.Ptr && rv.Type().Elem().Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return
+	}
+
+	dst := rv.Elem()
+	redacted := reflect.ValueOf(stmt.redactedValue(field, dst.Interface()))
+	switch {
+	case !redacted.IsValid():
+		dst.Set(reflect.Zero(dst.Type()))
+	case redacted.Type().ConvertibleTo(dst.Type()):
+		dst.Set(redacted.Convert(dst.Type()))
+	case dst.Kind() == reflect.Ptr && redacted.Type().ConvertibleTo(dst.Type().Elem()):
+		value := reflect.New(dst.Type().Elem())
+		value.Elem().Set(redacted.Convert(dst.Type().Elem()))
+		dst.Set(value)
+	default:
+		dst.Set(reflect.Zero(dst.Type()))
+	}
+}
+
+// eachModel calls fc with the addressable struct values of rv, which is the model or the slice of them
+func eachModel(rv reflect.Value, fc func(rv reflect.Value)) {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			eachModel(rv.Index(i), fc)
+		}
+	case reflect.Struct:
+		if rv.CanAddr() {
+			fc(rv)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// ResolverMode forces the connection pool of a statement when Config.Replicas is set, e.g:
+//
+//	db.Clauses(gorm.Write).Find(&users) // read from the primary
+//	db.Clauses(gorm.Read).Raw("SHOW TABLES").Scan(&tables) // read from a replica
+type ResolverMode uint8
+
+// resolver modes
+const (
+	Read ResolverMode = iota + 1
+	Write
+)
+
+const (
+	resolverModeKey = "gorm:resolver_mode"
+	replicaKey      = "gorm:replica"
+)
+
+// ModifyStatement implements StatementModifier
+func (mode ResolverMode) ModifyStatement(stmt *Statement) {
+	stmt.Settings.Store(resolverModeKey, mode)
+}
+
+// Build implements clause.Expression, the mode isn't written into sql
+func (mode ResolverMode) Build(clause.Builder) {}
+
+// ReplicaPolicy chooses the replica serving a read, candidates are the indexes in Config.Replicas of the replicas
+// not quarantined, it returns one of them
+type ReplicaPolicy interface {
+	Resolve(ctx context.Context, candidates []int) int
+}
+
+type roundRobinPolicy struct {
+	next uint64
+}
+
+// RoundRobinPolicy returns a ReplicaPolicy reading from the replicas in turn, it's the default policy
+func RoundRobinPolicy() ReplicaPolicy {
+	return &roundRobinPolicy{}
+}
+
+func (p *roundRobinPolicy) Resolve(ctx context.Context, candidates []int) int {
+	return candidates[int(atomic.AddUint64(&p.next, 1)-1)%len(candidates)]
+}
+
+type randomPolicy struct{}
+
+// RandomPolicy returns a ReplicaPolicy reading from a random replica
+func RandomPolicy() ReplicaPolicy {
+	return randomPolicy{}
+}
+
+func (randomPolicy) Resolve(ctx context.Context, candidates []int) int {
+	return candidates[rand.Intn(len(candidates))]
+}
+
+type leastLagPolicy func(ctx context.Context, replica int) time.Duration
+
+// LeastLagPolicy returns a ReplicaPolicy reading from the replica with the least replication lag reported by lag
+func LeastLagPolicy(lag func(ctx context.Context, replica int) time.Duration) ReplicaPolicy {
+	return leastLagPolicy(lag)
+}
+
+func (lag leastLagPolicy) Resolve(ctx context.Context, candidates []int) int {
+	resolved, least := candidates[0], lag(ctx, candidates[0])
+	for _, candidate := range candidates[1:] {
+		if l := lag(ctx, candidate); l < least {
+			resolved, least = candidate, l
+		}
+	}
+	return resolved
+}
+
+// replicaResolver routes reads outside transactions to the replicas
+type replicaResolver struct {
+	primary    ConnPool
+	replicas   []*replica
+	policy     ReplicaPolicy
+	quarantine time.Duration
+}
+
+type replica struct {
+	connPool         ConnPool
+	mux              sync.Mutex
+	failures         int
+	quarantinedUntil time.Time
+}
+
+// replicaSwitch the replica serving a statement and the connection pool to restore after it
+type replicaSwitch struct {
+	replica  *replica
+	connPool ConnPool
+}
+
+// initializeReplicas opens Config.Replicas and registers the callbacks routing reads to them
+func (db *DB) initializeReplicas() error {
+	resolver := &replicaResolver{
+		primary:    db.ConnPool,
+		policy:     db.ReplicaPolicy,
+		quarantine: db.ReplicaQuarantine,
+	}
+	if resolver.policy == nil {
+		resolver.policy = RoundRobinPolicy()
+	}
+	if resolver.quarantine <= 0 {
+		resolver.quarantine = time.Second
+	}
+
+	for _, dialector := range db.Replicas {
+		replicaDB, err := Open(dialector, &Config{
+			Logger:               db.Logger,
+			NamingStrategy:       db.NamingStrategy,
+			NowFunc:              db.NowFunc,
+			DisableAutomaticPing: db.DisableAutomaticPing,
+			OnConnect:            db.OnConnect,
+			PoolEventHandler:     db.PoolEventHandler,
+		})
+		if err != nil {
+			return err
+		}
+		resolver.replicas = append(resolver.replicas, &replica{connPool: replicaDB.ConnPool})
+	}
+
+	queryCallback := db.Callback().Query()
+	if err := queryCallback.Before("gorm:query").Register("gorm:resolve_replica", resolver.switchReplica); err != nil {
+		return err
+	}
+	if err := queryCallback.After("gorm:query").Register("gorm:release_replica", resolver.releaseReplica); err != nil {
+		return err
+	}
+
+	rowCallback := db.Callback().Row()
+	if err := rowCallback.Before("gorm:row").Register("gorm:resolve_replica", resolver.switchReplica); err != nil {
+		return err
+	}
+	return rowCallback.After("gorm:row").Register("gorm:release_replica", resolver.releaseReplica)
+}
+
+// isPrimary reports whether the statement runs on the primary pool, rather than a transaction or a pinned connection
+func (r *replicaResolver) isPrimary(connPool ConnPool) bool {
+	if preparedStmt, ok := connPool.(*PreparedStmtDB); ok {
+		connPool = preparedStmt.ConnPool
+	}
+	return connPool == r.primary
+}
+
+func (r *replicaResolver) isRead(stmt *Statement) bool {
+	if !r.isPrimary(stmt.ConnPool) {
+		return false
+	}
+
+	if _, ok := stmt.Clauses["FOR"]; ok { // locking reads
+		return false
+	}
+
+	if v, ok := stmt.Settings.Load(resolverModeKey); ok {
+		return v.(ResolverMode) == Read
+	}
+
+	if stmt.SQL.Len() > 0 { // raw sql
+		sql := strings.ToUpper(strings.TrimSpace(stmt.SQL.String()))
+		return strings.HasPrefix(sql, "SELECT") && !strings.Contains(sql, " FOR UPDATE") && !strings.Contains(sql, " FOR SHARE")
+	}
+	return true
+}
+
+func (r *replicaResolver) switchReplica(db *DB) {
+	if db.Error != nil || !r.isRead(db.Statement) {
+		return
+	}
+
+	rep := r.resolve(db.Statement.Context)
+	if rep == nil {
+		return
+	}
+
+	connPool := rep.connPool
+	if preparedStmt, ok := db.Statement.ConnPool.(*PreparedStmtDB); ok {
+		// statements prepared on the primary can't be used on the replicas
+		connPool = preparedStmt.Partition(rep.connPool)
+	}
+
+	db.Statement.Settings.Store(replicaKey, &replicaSwitch{replica: rep, connPool: db.Statement.ConnPool})
+	db.Statement.ConnPool = connPool
+}
+
+func (r *replicaResolver) releaseReplica(db *DB) {
+	v, ok := db.Statement.Settings.LoadAndDelete(replicaKey)
+	if !ok {
+		return
+	}
+
+	s := v.(*replicaSwitch)
+	db.Statement.ConnPool = s.connPool
+	if isConnError(db.Error) {
+		s.replica.fail(r.quarantine)
+	} else {
+		s.replica.succeed()
+	}
+}
+
+// resolve returns the replica chosen by the policy, returns nil if all replicas are quarantined
+func (r *replicaResolver) resolve(ctx context.Context) *replica {
+	now := time.Now()
+	candidates := make([]int, 0, len(r.replicas))
+	for idx, rep := range r.replicas {
+		if rep.available(now) {
+			candidates = append(candidates, idx)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+	return r.replicas[r.policy.Resolve(ctx, candidates)]
+}
+
+func (rep *replica) available(now time.Time) bool {
+	rep.mux.Lock()
+	defer rep.mux.Unlock()
+	return !now.Before(rep.quarantinedUntil)
+}
+
+// fail quarantines the replica, the quarantine doubles with every consecutive failure up to a minute, or the configured
+// quarantine if it's longer
+func (rep *replica) fail(quarantine time.Duration) {
+	rep.mux.Lock()
+	defer rep.mux.Unlock()
+
+	if rep.failures < 16 {
+		rep.failures++
+	}
+	rep.quarantinedUntil = time.Now().Add(backoffQuarantine(quarantine, rep.failures))
+}
+
+// backoffQuarantine returns the quarantine doubled for the consecutive failures up to the limit, which stops doubling
+// before exceeding the limit, so it never overflows
+func backoffQuarantine(quarantine time.Duration, failures int) time.Duration {
+	limit := time.Minute
+	if quarantine > limit {
+		limit = quarantine
+	}
+
+	for i := 1; i < failures; i++ {
+		if quarantine > limit>>1 {
+			return limit
+		}
+		quarantine <<= 1
+	}
+	return quarantine
+}
+
+func (rep *replica) succeed() {
+	rep.mux.Lock()
+	rep.failures = 0
+	rep.mux.Unlock()
+}
+
+// isConnError reports whether err means the connection to the database is broken
+func isConnError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) ||
+		errors.As(err, &netErr)
+}
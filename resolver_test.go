@@ -0,0 +1,28 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffQuarantine(t *testing.T) {
+	tests := []struct {
+		quarantine time.Duration
+		failures   int
+		expected   time.Duration
+	}{
+		{time.Second, 1, time.Second},
+		{time.Second, 3, 4 * time.Second},
+		{time.Second, 16, time.Minute},
+		{40 * time.Second, 2, time.Minute},
+		{5 * time.Minute, 1, 5 * time.Minute},
+		{5 * time.Minute, 16, 5 * time.Minute},
+		{time.Duration(1<<62 + 1), 16, time.Duration(1<<62 + 1)},
+	}
+
+	for _, test := range tests {
+		if quarantine := backoffQuarantine(test.quarantine, test.failures); quarantine != test.expected {
+			t.Errorf("quarantine %v with %v failures should be %v, got %v", test.quarantine, test.failures, test.expected, quarantine)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// BeforeRestoreInterface is called before the soft deleted rows are restored, see Restore
+type BeforeRestoreInterface interface {
+	BeforeRestore(*DB) error
+}
+
+// AfterRestoreInterface is called after the soft deleted rows are restored, see Restore
+type AfterRestoreInterface interface {
+	AfterRestore(*DB) error
+}
+
+// Restore restores the soft deleted rows of value matching the conditions and the primary keys ids, the primary keys
+// of value are used if ids are not given, e.g:
+//
+//	db.Restore(&user)
+//	db.Restore(&User{}, 1, 2)
+//	db.Unscoped().Where("name = ?", "jinzhu").Restore(&User{})
+//	db.Select(clause.Associations).Restore(&user) // restores the soft deleted has one and has many associations too
+//
+// The soft delete field is reset to its zero value, the `softDeleteBy` field to NULL and the auto update time fields to
+// now, rows archived by ArchivedAt are moved back from the archive table. BeforeRestore and AfterRestore hooks are called
+// like the hooks of Update, RowsAffected is the number of restored rows and no ErrRecordNotFound is returned
+func (db *DB) Restore(value interface{}, ids ...interface{}) (tx *DB) {
+	tx = db.getInstance()
+	stmt := tx.Statement
+	if err := stmt.Parse(value); err != nil {
+		tx.AddError(err)
+		return
+	}
+
+	if stmt.Schema.SoftDelete == nil {
+		tx.AddError(fmt.Errorf("%w: %s", ErrNotSoftDeleted, stmt.Schema.Name))
+		return
+	}
+
+	stmt.Dest = value
+	stmt.ReflectValue = reflect.ValueOf(value)
+	for stmt.ReflectValue.Kind() == reflect.Ptr {
+		stmt.ReflectValue = stmt.ReflectValue.Elem()
+	}
+
+	var where clause.Where
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if w, ok := c.Expression.(clause.Where); ok {
+			where.Exprs = append(where.Exprs, w.Exprs...)
+		}
+	}
+
+	if len(ids) > 0 {
+		if len(stmt.Schema.PrimaryFields) != 1 {
+			tx.AddError(ErrPrimaryKeyRequired)
+			return
+		}
+		where.Exprs = append(where.Exprs, clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: stmt.Schema.PrimaryFields[0].DBName}, Values: ids})
+	} else {
+		_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+		column, values := schema.ToQueryValues(clause.CurrentTable, stmt.Schema.PrimaryFieldDBNames, queryValues)
+		if len(values) > 0 {
+			where.Exprs = append(where.Exprs, clause.IN{Column: column, Values: values})
+		}
+	}
+
+	if len(where.Exprs) == 0 {
+		if allowed, ok := tx.Get("gorm:allow_global_write"); !(ok && allowed.(bool)) && (!tx.AllowGlobalUpdate || tx.BlockGlobalWrite) {
+			tx.AddError(ErrMissingWhereClause)
+			return
+		}
+	}
+
+	// the rows of other tenants are never restored, even if their primary keys are given
+	if cond, ok := stmt.tenantCondition(); ok {
+		where.Exprs = append(where.Exprs, cond)
+	} else if tx.Error != nil {
+		return
+	}
+
+	skipHooks := stmt.SkipHooks || (tx.DryRun && !tx.DryRunWithHooks)
+	restore := func(restoreTx *DB) error {
+		if !skipHooks {
+			if err := callRestoreHooks(stmt.ReflectValue, func(v interface{}) error {
+				if i, ok := v.(BeforeRestoreInterface); ok {
+					return i.BeforeRestore(restoreTx)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		if err := restoreAssociations(restoreTx, stmt, where); err != nil {
+			return err
+		}
+
+		rowsAffected, err := restoreRows(restoreTx, stmt, where)
+		if err != nil {
+			return err
+		}
+		tx.RowsAffected = rowsAffected
+
+		if !skipHooks {
+			return callRestoreHooks(stmt.ReflectValue, func(v interface{}) error {
+				if i, ok := v.(AfterRestoreInterface); ok {
+					return i.AfterRestore(restoreTx)
+				}
+				return nil
+			})
+		}
+		return nil
+	}
+
+	if tx.SkipDefaultTransaction || tx.DryRun {
+		tx.AddError(restore(tx.Session(&Session{NewDB: true})))
+	} else {
+		tx.AddError(tx.Session(&Session{NewDB: true}).Transaction(restore))
+	}
+	return
+}
+
+// restoreRows restores the soft deleted rows of stmt matching where, and sets the restored values to the reflect value
+func restoreRows(tx *DB, stmt *Statement, where clause.Where) (int64, error) {
+	var (
+		s            = stmt.Schema
+		now          = tx.NowFunc()
+		table        = clause.Table{Name: stmt.Table}
+		restoreTx    = tx.Table(stmt.Table)
+		archiveTable = s.SoftDelete.ArchiveTable(stmt.Table)
+	)
+
+	if archiveTable != "" {
+		columns := archivedColumns(stmt, s, "")
+		selects := make([]string, 0, len(s.DBNames))
+		vars := []interface{}{table}
+		for _, dbName := range s.DBNames {
+			if field := s.FieldsByDBName[dbName]; field == s.SoftDeleteField {
+				continue
+			} else if field.AutoUpdateTime > 0 && !tx.SkipAutoTimeTracking {
+				selects = append(selects, "?")
+				vars = append(vars, autoUpdateTimeValue(field, now))
+			} else {
+				selects = append(selects, stmt.Quote(dbName))
+			}
+		}
+		vars = append(vars, clause.Table{Name: archiveTable}, clause.Table{Name: stmt.Table}, where)
+
+		result := restoreTx.Exec("INSERT INTO ? ("+columns+") SELECT "+strings.Join(selects, ",")+" FROM ? AS ? ?", vars...)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		// the target of the single-table DELETE isn't aliased, which is rejected by MySQL before 8.0.16, the conditions
+		// of the current table are built against the archive table
+		deleteTx := tx.Table(archiveTable)
+		return result.RowsAffected, deleteTx.Exec("DELETE FROM ? ?", clause.Table{Name: archiveTable}, where).Error
+	}
+
+	set := clause.Set{{Column: clause.Column{Name: s.SoftDeleteField.DBName}, Value: softDeleteZeroValue(s)}}
+	restored := map[string]interface{}{s.SoftDeleteField.Name: reflect.Zero(s.SoftDeleteField.FieldType).Interface()}
+	if s.SoftDeleteByField != nil {
+		set = append(set, clause.Assignment{Column: clause.Column{Name: s.SoftDeleteByField.DBName}, Value: nil})
+		restored[s.SoftDeleteByField.Name] = reflect.Zero(s.SoftDeleteByField.FieldType).Interface()
+	}
+	if !stmt.SkipHooks && !tx.SkipAutoTimeTracking {
+		for _, dbName := range s.DBNames {
+			if field := s.FieldsByDBName[dbName]; field.AutoUpdateTime > 0 && field.Updatable {
+				set = append(set, clause.Assignment{Column: clause.Column{Name: field.DBName}, Value: autoUpdateTimeValue(field, now)})
+				restored[field.Name] = autoUpdateTimeValue(field, now)
+			}
+		}
+	}
+
+	restoreWhere := clause.Where{Exprs: append(append([]clause.Expression{}, where.Exprs...), softDeletedCondition(s))}
+	result := restoreTx.Exec("UPDATE ? ? ?", table, set, restoreWhere)
+	if result.Error == nil && stmt.ReflectValue.CanAddr() {
+		for name, v := range restored {
+			stmt.SetColumn(name, v, true)
+		}
+	}
+	return result.RowsAffected, result.Error
+}
+
+// restoreAssociations restores the soft deleted has one and has many associations of the rows of stmt matching where,
+// which are selected with Select
+func restoreAssociations(tx *DB, stmt *Statement, where clause.Where) error {
+	selectColumns, restricted := stmt.SelectAndOmitColumns(true, false)
+	if !restricted {
+		return nil
+	}
+
+	for column, v := range selectColumns {
+		rel, ok := stmt.Schema.Relationships.Relations[column]
+		if !v || !ok || (rel.Type != schema.HasOne && rel.Type != schema.HasMany) || rel.FieldSchema.SoftDelete == nil {
+			continue
+		}
+
+		var (
+			foreignKeys = make([]interface{}, 0, len(rel.References))
+			primaryKeys = make([]string, 0, len(rel.References))
+			queryConds  = make([]clause.Expression, 0, len(rel.References)+1)
+		)
+		for _, ref := range rel.References {
+			if ref.OwnPrimaryKey {
+				foreignKeys = append(foreignKeys, clause.Column{Table: clause.CurrentTable, Name: ref.ForeignKey.DBName})
+				primaryKeys = append(primaryKeys, ref.PrimaryKey.DBName)
+			} else if ref.PrimaryValue != "" {
+				queryConds = append(queryConds, clause.Eq{
+					Column: clause.Column{Table: clause.CurrentTable, Name: ref.ForeignKey.DBName},
+					Value:  ref.PrimaryValue,
+				})
+			}
+		}
+
+		// the restoring rows are looked up in the archive table for ArchivedAt, which keeps the name of the table
+		parentWhere := where
+		subQuery := tx.Session(&Session{NewDB: true})
+		if archiveTable := stmt.Schema.SoftDelete.ArchiveTable(stmt.Table); archiveTable != "" {
+			subQuery = subQuery.Table("? AS ?", clause.Table{Name: archiveTable}, clause.Table{Name: stmt.Table})
+		} else {
+			subQuery = subQuery.Table(stmt.Table)
+			parentWhere = clause.Where{Exprs: append(append([]clause.Expression{}, where.Exprs...), softDeletedCondition(stmt.Schema))}
+		}
+		subQuery.Statement.Table = stmt.Table
+		subQuery = subQuery.Select(primaryKeys).Clauses(parentWhere)
+
+		var fkColumn interface{} = foreignKeys[0]
+		if len(foreignKeys) > 1 {
+			fkColumn = foreignKeys
+		}
+		queryConds = append(queryConds, clause.Expr{SQL: "? IN (?)", Vars: []interface{}{fkColumn, subQuery}})
+
+		modelValue := reflect.New(rel.FieldSchema.ModelType).Interface()
+		restoreTx := tx.Session(&Session{NewDB: true}).Clauses(clause.Where{Exprs: queryConds})
+		if len(stmt.Selects) > 0 {
+			selects := make([]string, 0, len(stmt.Selects))
+			for _, s := range stmt.Selects {
+				if s == clause.Associations {
+					selects = append(selects, s)
+				} else if columnPrefix := column + "."; strings.HasPrefix(s, columnPrefix) {
+					selects = append(selects, strings.TrimPrefix(s, columnPrefix))
+				}
+			}
+
+			if len(selects) > 0 {
+				restoreTx = restoreTx.Select(selects)
+			}
+		}
+
+		if err := restoreTx.Restore(modelValue).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// softDeleteZeroValue returns the value of the soft delete column of the rows not deleted
+func softDeleteZeroValue(s *schema.Schema) interface{} {
+	switch softDelete := s.SoftDelete.(type) {
+	case *DeletedAt:
+		if v := parseZeroValueTag(s.SoftDeleteField); v.Valid {
+			return v.String
+		}
+	case SoftDeleteMode:
+		return softDelete.zeroValue(s.SoftDeleteField)
+	}
+	return nil
+}
+
+// softDeletedCondition returns the condition matching the soft deleted rows of s
+func softDeletedCondition(s *schema.Schema) clause.Expression {
+	return clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: s.SoftDeleteField.DBName}, Value: softDeleteZeroValue(s)}
+}
+
+func autoUpdateTimeValue(field *schema.Field, now time.Time) interface{} {
+	switch field.AutoUpdateTime {
+	case schema.UnixNanosecond:
+		return now.UnixNano()
+	case schema.UnixMillisecond:
+		return now.UnixMilli()
+	case schema.UnixSecond:
+		return now.Unix()
+	}
+	return now
+}
+
+func callRestoreHooks(reflectValue reflect.Value, fc func(interface{}) error) error {
+	switch reflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < reflectValue.Len(); i++ {
+			if elem := reflect.Indirect(reflectValue.Index(i)); elem.CanAddr() {
+				if err := fc(elem.Addr().Interface()); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Struct:
+		if reflectValue.CanAddr() {
+			return fc(reflectValue.Addr().Interface())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package gorm
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// defaultPurgeBatchSize the batch size of PurgeExpired unless PurgeBatchSize
+const defaultPurgeBatchSize = 1000
+
+// PurgeOption the option of PurgeExpired passed with the models, see PurgeSoftDeleted, PurgeBatchSize, PurgeDryRun and
+// PurgeSkipHooks
+type PurgeOption func(*purgeConfig)
+
+type purgeConfig struct {
+	batchSize   int
+	softDeleted bool
+	dryRun      bool
+	skipHooks   bool
+}
+
+// PurgeSoftDeleted deletes the expired rows which are soft deleted permanently like Unscoped, instead of soft deleting
+// the expired rows which are not, the models without soft delete are deleted permanently either way
+func PurgeSoftDeleted() PurgeOption {
+	return func(config *purgeConfig) {
+		config.softDeleted = true
+	}
+}
+
+// PurgeBatchSize deletes the expired rows in batches of size, which is 1000 by default
+func PurgeBatchSize(size int) PurgeOption {
+	return func(config *purgeConfig) {
+		config.batchSize = size
+	}
+}
+
+// PurgeDryRun counts the expired rows with SELECT count(*) instead of deleting them
+func PurgeDryRun() PurgeOption {
+	return func(config *purgeConfig) {
+		config.dryRun = true
+	}
+}
+
+// PurgeSkipHooks deletes the expired rows without calling the hooks of Delete, e.g: the large tables
+func PurgeSkipHooks() PurgeOption {
+	return func(config *purgeConfig) {
+		config.skipHooks = true
+	}
+}
+
+// PurgeResult the expired rows of the model deleted by PurgeExpired, which are counted by PurgeDryRun
+type PurgeResult struct {
+	Model  string // the name of the schema
+	Table  string
+	Purged int64
+}
+
+// PurgeExpired deletes the rows of the models expired by the retention declared by the `retention` tag, which are older
+// than now minus the retention, see schema.Retention, the rows are deleted in batches like DeleteInBatches, the models
+// with soft delete are soft deleted unless PurgeSoftDeleted, returns the purged rows of the models in order and the
+// error of the first failed model, e.g:
+//
+//	type Event struct {
+//		ID        uint
+//		CreatedAt time.Time `gorm:"retention:2160h"`
+//	}
+//
+//	type Session struct {
+//		ID        uint
+//		DeletedAt gorm.DeletedAt `gorm:"retention:720h"` // deleted permanently 30 days after soft deleted
+//	}
+//
+//	results, err := db.PurgeExpired(&Event{}, &Session{}, gorm.PurgeSoftDeleted(), gorm.PurgeBatchSize(5000))
+func (db *DB) PurgeExpired(models ...interface{}) ([]PurgeResult, error) {
+	var (
+		config  = purgeConfig{batchSize: defaultPurgeBatchSize}
+		targets = make([]interface{}, 0, len(models))
+	)
+	for _, model := range models {
+		if opt, ok := model.(PurgeOption); ok {
+			opt(&config)
+		} else {
+			targets = append(targets, model)
+		}
+	}
+
+	results := make([]PurgeResult, 0, len(targets))
+	for _, model := range targets {
+		s, err := schema.Parse(model, db.cacheStore, db.NamingStrategy)
+		if err != nil {
+			return results, err
+		}
+
+		if s.Retention == nil {
+			return results, fmt.Errorf("%w: model %s has no retention", ErrInvalidData, s.Name)
+		}
+
+		field := s.Retention.Field
+		expiredAt := db.NowFunc().Add(-s.Retention.Duration)
+		tx := db.Session(&Session{SkipHooks: config.skipHooks}).Model(model).Where(
+			clause.Lt{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: retentionValue(field, expiredAt)},
+		)
+
+		if config.softDeleted && s.SoftDeleteField != nil {
+			tx = tx.Unscoped().Where(softDeletedCondition(s))
+		}
+
+		result := PurgeResult{Model: s.Name, Table: s.Table}
+		if config.dryRun {
+			err = tx.Count(&result.Purged).Error
+		} else {
+			deleteTx := tx.DeleteInBatches(model, config.batchSize, nil)
+			result.Purged, err = deleteTx.RowsAffected, deleteTx.Error
+		}
+
+		results = append(results, result)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// retentionValue returns the value of the retention field expired at expiredAt, the unix times are compared by the
+// numbers of their units
+func retentionValue(field *schema.Field, expiredAt time.Time) interface{} {
+	timeType := field.AutoCreateTime
+	if timeType == 0 {
+		timeType = field.AutoUpdateTime
+	}
+
+	switch timeType {
+	case schema.UnixNanosecond:
+		return expiredAt.UnixNano()
+	case schema.UnixMillisecond:
+		return expiredAt.UnixMilli()
+	case schema.UnixSecond:
+		return expiredAt.Unix()
+	}
+
+	if field.DataType == schema.Int || field.DataType == schema.Uint {
+		return expiredAt.Unix()
+	}
+	return expiredAt
+}
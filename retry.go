@@ -0,0 +1,50 @@
+package gorm
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+)
+
+// DefaultTransientErrors the errors retried by RetryTransient when Config.TransientErrors is not set
+var DefaultTransientErrors = []error{driver.ErrBadConn, io.EOF, io.ErrUnexpectedEOF}
+
+// IsTransientError reports whether err is one of Config.TransientErrors, or translated to ErrTransient by the dialector
+func (db *DB) IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	transientErrors := db.TransientErrors
+	if transientErrors == nil {
+		transientErrors = DefaultTransientErrors
+	}
+	for _, transientErr := range transientErrors {
+		if errors.Is(err, transientErr) {
+			return true
+		}
+	}
+
+	if errors.Is(err, ErrTransient) {
+		return true
+	}
+	if translator, ok := db.Dialector.(ErrorTranslator); ok {
+		return errors.Is(translator.Translate(err), ErrTransient)
+	}
+	return false
+}
+
+// ShouldRetryTransient reports whether a read failed with err should be executed again, attempt is the number of
+// retries already done, reads in transactions are never retried, see Config.RetryTransient
+func (db *DB) ShouldRetryTransient(attempt int, err error) bool {
+	if attempt >= db.RetryTransient || !db.IsTransientError(err) {
+		return false
+	}
+
+	if _, ok := db.Statement.ConnPool.(TxCommitter); ok {
+		return false
+	}
+
+	db.Logger.Warn(db.Statement.Context, "retrying query after transient error, attempt %d/%d: %v", attempt+1, db.RetryTransient, err)
+	return true
+}
@@ -0,0 +1,61 @@
+package gorm
+
+import (
+	"gorm.io/gorm/clause"
+)
+
+// ReturningBuilder the dialector building the columns returned by the creates, updates and deletes in its own syntax,
+// e.g: OUTPUT INSERTED.* of SQL Server, which is called instead of adding RETURNING to the statement, and replaces the
+// columns built before, the returned rows are scanned like RETURNING
+type ReturningBuilder interface {
+	BuildReturning(stmt *Statement, columns []clause.Column)
+}
+
+// ReturningClause the default ReturningBuilder, which adds RETURNING to the statement
+type ReturningClause struct{}
+
+// BuildReturning adds RETURNING of the columns to the statement
+func (ReturningClause) BuildReturning(stmt *Statement, columns []clause.Column) {
+	stmt.AddClause(clause.Returning{Columns: columns})
+}
+
+// returningKey the columns returned by the statement, which are built by the ReturningBuilder of the dialector
+const returningKey = "gorm:returning"
+
+// AddReturning adds the columns returned by the statement with the ReturningBuilder of the dialector, or the default
+// ReturningClause if the dialector isn't a ReturningBuilder, no columns returns all the columns
+func (stmt *Statement) AddReturning(columns ...clause.Column) {
+	builder, ok := stmt.DB.Dialector.(ReturningBuilder)
+	if !ok {
+		ReturningClause{}.BuildReturning(stmt, columns)
+		return
+	}
+
+	stmt.Settings.Store(returningKey, clause.Returning{Columns: columns})
+	builder.BuildReturning(stmt, columns)
+}
+
+// BuildReturning moves RETURNING added to the statement to the ReturningBuilder of the dialector, which is called
+// before building the creates, updates and deletes
+func (stmt *Statement) BuildReturning() {
+	if _, ok := stmt.DB.Dialector.(ReturningBuilder); ok {
+		if c, ok := stmt.Clauses["RETURNING"]; ok {
+			delete(stmt.Clauses, "RETURNING")
+			returning, _ := c.Expression.(clause.Returning)
+			stmt.AddReturning(returning.Columns...)
+		}
+	}
+}
+
+// Returning returns the columns returned by the statement, which are added by AddReturning or RETURNING
+func (stmt *Statement) Returning() (clause.Returning, bool) {
+	if v, ok := stmt.Settings.Load(returningKey); ok {
+		return v.(clause.Returning), true
+	}
+
+	if c, ok := stmt.Clauses["RETURNING"]; ok {
+		returning, _ := c.Expression.(clause.Returning)
+		return returning, true
+	}
+	return clause.Returning{}, false
+}
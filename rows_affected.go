@@ -0,0 +1,59 @@
+package gorm
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/clause"
+)
+
+// RowsAffectedMode whether RowsAffected of the updates counts the matched or the changed rows, the databases report
+// either of them, e.g: mysql reports 0 for the rows matched but not changed while postgres reports 1
+type RowsAffectedMode string
+
+const (
+	// RowsAffectedDriver RowsAffected is reported by the driver
+	RowsAffectedDriver RowsAffectedMode = ""
+	// RowsAffectedMatched RowsAffected counts the matched rows whether they are changed or not, the databases without
+	// CapabilityMatchedRowsAffected count the rows matched by the conditions of the update before executing it, e.g:
+	// the idempotent updates and Save, which costs a COUNT query per update
+	RowsAffectedMatched RowsAffectedMode = "matched"
+	// RowsAffectedChanged RowsAffected counts the changed rows, which is unsupported by the databases with
+	// CapabilityMatchedRowsAffected, the updates return ErrUnsupportedDriver instead of being executed
+	RowsAffectedChanged RowsAffectedMode = "changed"
+)
+
+// CheckRowsAffectedMode returns ErrUnsupportedDriver if Config.RowsAffectedMode can't be reported by the database,
+// which is checked by the update callbacks before executing the update
+func (stmt *Statement) CheckRowsAffectedMode() error {
+	if stmt.DB.RowsAffectedMode == RowsAffectedChanged && stmt.DB.Supports(CapabilityMatchedRowsAffected) {
+		return fmt.Errorf("%w: %s reports the matched rows of the updates, the changed rows can't be counted",
+			ErrUnsupportedDriver, stmt.DB.Dialector.Name())
+	}
+	return nil
+}
+
+// CountMatchedRows counts the rows matched by the conditions of the update to be executed if Config.RowsAffectedMode
+// is RowsAffectedMatched and the database reports the changed rows, which is called by the update callbacks before
+// executing the update, as the changed rows may not match the conditions anymore after it, ok is false if the rows
+// reported by the database should be used
+func (stmt *Statement) CountMatchedRows() (count int64, ok bool) {
+	db := stmt.DB
+	if db.RowsAffectedMode != RowsAffectedMatched || db.Error != nil || db.DryRun || db.Supports(CapabilityMatchedRowsAffected) {
+		return 0, false
+	}
+
+	tx := db.Session(&Session{NewDB: true, SkipHooks: true}).getInstance()
+	tx.Statement.Table, tx.Statement.TableExpr = stmt.Table, stmt.TableExpr
+	if where, ok := stmt.Clauses["WHERE"]; ok {
+		tx.Statement.Clauses["WHERE"] = where
+	}
+
+	if tx = tx.Count(&count); db.AddError(tx.Error) != nil {
+		return 0, false
+	}
+
+	if limit, ok := stmt.Clauses["LIMIT"].Expression.(clause.Limit); ok && limit.Limit != nil && int64(*limit.Limit) < count {
+		count = int64(*limit.Limit)
+	}
+	return count, true
+}
@@ -1,9 +1,11 @@
 package gorm
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"reflect"
+	"strings"
 	"time"
 
 	"gorm.io/gorm/schema"
@@ -50,9 +52,88 @@ func scanIntoMap(mapValue map[string]interface{}, values []interface{}, columns
 	}
 }
 
-func (db *DB) scanIntoStruct(rows Rows, reflectValue reflect.Value, values []interface{}, fields []*schema.Field, joinFields [][]*schema.Field) {
+// removeUnreadable removes the columns of the dynamic schema without the read permission from the scanned mapValue
+func (stmt *Statement) removeUnreadable(mapValue map[string]interface{}) {
+	if stmt.Schema != nil && stmt.Schema.Dynamic {
+		for _, field := range stmt.Schema.Fields {
+			if !field.Readable {
+				delete(mapValue, field.DBName)
+			}
+		}
+	}
+}
+
+var scannerWithContextType = reflect.TypeOf((*ScannerWithContext)(nil)).Elem()
+
+// isScannerWithContext reports whether the values of field are scanned by ScannerWithContext
+func isScannerWithContext(field *schema.Field) bool {
+	return reflect.PtrTo(field.IndirectFieldType).Implements(scannerWithContextType)
+}
+
+// scanContext scans src with ScanContext into the new value of the field, NULL of the pointer fields is kept nil,
+// returns false if the field isn't a ScannerWithContext or src is assignable to it
+func (stmt *Statement) scanContext(field *schema.Field, src interface{}) (value interface{}, ok bool, err error) {
+	if !isScannerWithContext(field) {
+		return nil, false, nil
+	}
+
+	if rv := reflect.ValueOf(src); rv.IsValid() {
+		if rv.Type().AssignableTo(field.FieldType) || rv.Type() == reflect.PtrTo(field.IndirectFieldType) {
+			return nil, false, nil
+		}
+
+		for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			rv = rv.Elem()
+		}
+
+		if rv.Kind() == reflect.Ptr {
+			src = nil
+		} else if valuer, isValuer := rv.Interface().(driver.Valuer); isValuer {
+			src, _ = valuer.Value()
+		} else {
+			src = rv.Interface()
+		}
+	}
+
+	if src == nil && field.FieldType.Kind() == reflect.Ptr {
+		return reflect.Zero(field.FieldType).Interface(), true, nil
+	}
+
+	fieldValue := reflect.New(field.IndirectFieldType)
+	err = fieldValue.Interface().(ScannerWithContext).ScanContext(stmt.Context, stmt.DB, src)
+	return fieldValue.Interface(), true, err
+}
+
+// setField sets value to the field of rv, which is scanned by ScanContext if the field is a ScannerWithContext
+func (stmt *Statement) setField(field *schema.Field, rv reflect.Value, value interface{}) error {
+	if v, ok, err := stmt.scanContext(field, value); err != nil {
+		return err
+	} else if ok {
+		value = v
+	}
+	return field.Set(stmt.Context, rv, value)
+}
+
+// defaultNestedColumnSeparator separates the prefixes of the nested columns, e.g: Manager__Company__name
+const defaultNestedColumnSeparator = "__"
+
+// isNullValue reports whether the scanned value is NULL
+func isNullValue(value interface{}) bool {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return true
+		}
+		rv = rv.Elem()
+	}
+	return !rv.IsValid()
+}
+
+func (db *DB) scanIntoStruct(rows Rows, reflectValue reflect.Value, values []interface{}, fields []*schema.Field, joinFields [][]*schema.Field, prefixed []bool, decoders []*schema.FieldDecoder, holders []interface{}) {
 	for idx, field := range fields {
-		if field != nil {
+		if holders != nil && holders[idx] != nil {
+			values[idx] = holders[idx]
+		} else if field != nil {
 			values[idx] = field.NewValuePool.Get()
 		} else if len(fields) == 1 {
 			if reflectValue.CanAddr() {
@@ -71,8 +152,37 @@ func (db *DB) scanIntoStruct(rows Rows, reflectValue reflect.Value, values []int
 			continue
 		}
 
-		if len(joinFields) == 0 || len(joinFields[idx]) == 0 {
-			db.AddError(field.Set(db.Statement.Context, reflectValue, values[idx]))
+		if holders != nil && holders[idx] != nil {
+			if decoders[idx] != nil {
+				db.AddError(decoders[idx].Decode(db.Statement.Context, reflectValue, holders[idx]))
+				continue
+			}
+
+			// scanned by ScannerWithContext
+			value, _, err := db.Statement.scanContext(field, *holders[idx].(*interface{}))
+			if err != nil {
+				db.AddError(err)
+				continue
+			}
+			values[idx] = value
+		}
+
+		value := values[idx]
+		if db.redact {
+			if len(joinFields) == 0 || len(joinFields[idx]) == 0 {
+				value = db.Statement.redactedDestValue(field, value)
+			} else {
+				value = db.Statement.redactedValue(field, value)
+			}
+		}
+
+		if len(prefixed) > 0 && prefixed[idx] && isNullValue(values[idx]) {
+			// the nil pointer of the nested embedded struct is only allocated for the values not NULL
+			if v, _ := field.ValueOf(db.Statement.Context, reflectValue); v != nil {
+				db.AddError(field.Set(db.Statement.Context, reflectValue, value))
+			}
+		} else if len(joinFields) == 0 || len(joinFields[idx]) == 0 {
+			db.AddError(field.Set(db.Statement.Context, reflectValue, value))
 		} else { // joinFields count is larger than 2 when using join
 			var isNilPtrValue bool
 			var relValue reflect.Value
@@ -102,13 +212,28 @@ func (db *DB) scanIntoStruct(rows Rows, reflectValue reflect.Value, values []int
 
 			if !isNilPtrValue { // ignore if value is nil
 				f := joinFields[idx][len(joinFields[idx])-1]
-				db.AddError(f.Set(db.Statement.Context, relValue, values[idx]))
+				db.AddError(f.Set(db.Statement.Context, relValue, value))
 			}
 		}
 
 		// release data to pool
-		field.NewValuePool.Put(values[idx])
+		if holders == nil || holders[idx] == nil {
+			field.NewValuePool.Put(values[idx])
+		}
+	}
+}
+
+// primaryKeyOf returns the key of the primary values of the scanned struct, false if any of them is zero
+func primaryKeyOf(ctx context.Context, sch *schema.Schema, reflectValue reflect.Value) (string, bool) {
+	values := make([]interface{}, len(sch.PrimaryFields))
+	for idx, field := range sch.PrimaryFields {
+		value, isZero := field.ValueOf(ctx, reflectValue)
+		if isZero {
+			return "", false
+		}
+		values[idx] = value
 	}
+	return utils.ToStringKey(values...), true
 }
 
 // ScanMode scan data mode
@@ -161,6 +286,10 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 				}
 			}
 			scanIntoMap(mapValue, values, columns)
+			db.Statement.removeUnreadable(mapValue)
+			if db.redact {
+				db.Statement.redactMap(mapValue)
+			}
 		}
 	case *[]map[string]interface{}:
 		columnTypes, _ := rows.ColumnTypes()
@@ -173,6 +302,10 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 
 			mapValue := map[string]interface{}{}
 			scanIntoMap(mapValue, values, columns)
+			db.Statement.removeUnreadable(mapValue)
+			if db.redact {
+				db.Statement.redactMap(mapValue)
+			}
 			*dest = append(*dest, mapValue)
 		}
 	case *int, *int8, *int16, *int32, *int64,
@@ -181,17 +314,31 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 		*bool, *string, *time.Time,
 		*sql.NullInt32, *sql.NullInt64, *sql.NullFloat64,
 		*sql.NullBool, *sql.NullString, *sql.NullTime:
+		var redactingField *schema.Field
+		if db.redact && len(columns) == 1 {
+			redactingField = db.Statement.redactingField(columns[0])
+		}
+
 		for initialized || rows.Next() {
 			initialized = false
 			db.RowsAffected++
 			db.AddError(rows.Scan(dest))
+			if redactingField != nil {
+				db.Statement.redactScanned(redactingField, reflect.ValueOf(dest))
+			}
 		}
 	default:
 		var (
-			fields       = make([]*schema.Field, len(columns))
-			joinFields   [][]*schema.Field
-			sch          = db.Statement.Schema
-			reflectValue = db.Statement.ReflectValue
+			fields     = make([]*schema.Field, len(columns))
+			joinFields [][]*schema.Field
+			prefixed   []bool
+			decoders   []*schema.FieldDecoder
+			holders    []interface{}
+			jsonRels   []*schema.Relationship
+			// the field of the model redacting the values of Pluck
+			redactingField *schema.Field
+			sch            = db.Statement.Schema
+			reflectValue   = db.Statement.ReflectValue
 		)
 
 		if reflectValue.Kind() == reflect.Interface {
@@ -219,58 +366,46 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 					reflectValueType.Kind() != reflect.Struct || // is not struct
 					sch.ModelType.ConvertibleTo(schema.TimeReflectType) { // is time
 					sch = nil
+					if db.redact {
+						redactingField = db.Statement.redactingField(columns[0])
+					}
 				}
 			}
 
 			// Not Pluck
 			if sch != nil {
-				matchedFieldCount := make(map[string]int, len(columns))
-				for idx, column := range columns {
-					if field := sch.LookUpField(column); field != nil && field.Readable {
-						fields[idx] = field
-						if count, ok := matchedFieldCount[column]; ok {
-							// handle duplicate fields
-							for _, selectField := range sch.Fields {
-								if selectField.DBName == column && selectField.Readable {
-									if count == 0 {
-										matchedFieldCount[column]++
-										fields[idx] = selectField
-										break
-									}
-									count--
-								}
-							}
-						} else {
-							matchedFieldCount[column] = 1
-						}
-					} else if names := utils.SplitNestedRelationName(column); len(names) > 1 { // has nested relation
-						if rel, ok := sch.Relationships.Relations[names[0]]; ok {
-							subNameCount := len(names)
-							// nested relation fields
-							relFields := make([]*schema.Field, 0, subNameCount-1)
-							relFields = append(relFields, rel.Field)
-							for _, name := range names[1 : subNameCount-1] {
-								rel = rel.FieldSchema.Relationships.Relations[name]
-								relFields = append(relFields, rel.Field)
-							}
-							// latest name is raw dbname
-							dbName := names[subNameCount-1]
-							if field := rel.FieldSchema.LookUpField(dbName); field != nil && field.Readable {
-								fields[idx] = field
-
-								if len(joinFields) == 0 {
-									joinFields = make([][]*schema.Field, len(columns))
-								}
-								relFields = append(relFields, field)
-								joinFields[idx] = relFields
-								continue
+				if sep := db.NestedColumnSeparator; sep != "" && sep != defaultNestedColumnSeparator {
+					for idx, column := range columns {
+						columns[idx] = strings.ReplaceAll(column, sep, defaultNestedColumnSeparator)
+					}
+				}
+
+				plan := sch.ScanPlan(columns)
+				db.AddError(plan.Err)
+				fields, joinFields, prefixed, decoders = plan.Fields, plan.JoinFields, plan.Prefixed, plan.Decoders
+				holders = plan.NewHolders()
+				jsonRels = db.Statement.associationJSONFields(sch, columns)
+				if db.redact && sch != db.Statement.Schema {
+					// the fields redacted by the fields of the model are scanned without the decoders of the shared plan
+					for idx, field := range fields {
+						if decoders[idx] != nil && db.Statement.redactingField(field.DBName) != nil {
+							if &decoders[0] == &plan.Decoders[0] {
+								decoders = append([]*schema.FieldDecoder(nil), decoders...)
 							}
+							decoders[idx], holders[idx] = nil, nil
 						}
+					}
+				}
+				for idx, field := range fields {
+					if field == nil {
 						var val interface{}
 						values[idx] = &val
-					} else {
-						var val interface{}
-						values[idx] = &val
+					} else if isScannerWithContext(field) {
+						// scan the raw values, which are scanned by ScanContext later
+						if holders == nil {
+							holders = make([]interface{}, len(fields))
+						}
+						holders[idx] = new(interface{})
 					}
 				}
 			}
@@ -280,9 +415,16 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 		case reflect.Slice, reflect.Array:
 			var (
 				elem        reflect.Value
+				reused      reflect.Value
 				isArrayKind = reflectValue.Kind() == reflect.Array
+				// the structs scanned by the primary keys, see Config.DeduplicateByPrimaryKey
+				identityMap map[string]reflect.Value
 			)
 
+			if db.DeduplicateByPrimaryKey && isPtr && !update && sch != nil && len(sch.PrimaryFields) > 0 {
+				identityMap = map[string]reflect.Value{}
+			}
+
 			if !update || reflectValue.Len() == 0 {
 				update = false
 				if isArrayKind {
@@ -298,9 +440,11 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 				}
 			}
 
+			reuseDest := db.ReuseDest && !update && !isArrayKind
 			for initialized || rows.Next() {
 			BEGIN:
 				initialized = false
+				reused = reflect.Value{}
 
 				if update {
 					if int(db.RowsAffected) >= reflectValue.Len() {
@@ -315,11 +459,35 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 							}
 						}
 					}
+				} else if reuseDest && reflectValue.CanSet() && reflectValue.Len() < reflectValue.Cap() {
+					// the element of the backing array is appended in place, see Config.ReuseDest
+					reflectValue.SetLen(reflectValue.Len() + 1)
+					reused = reflectValue.Index(reflectValue.Len() - 1)
+					if elem = reused; !isPtr {
+						elem = reused.Addr()
+					} else if elem.IsNil() {
+						elem = reflect.New(reflectValueType)
+					}
+					elem.Elem().Set(reflect.Zero(reflectValueType))
 				} else {
 					elem = reflect.New(reflectValueType)
 				}
 
-				db.scanIntoStruct(rows, elem, values, fields, joinFields)
+				db.scanIntoStruct(rows, elem, values, fields, joinFields, prefixed, decoders, holders)
+				db.scanAssociationJSON(elem, values, jsonRels)
+				if redactingField != nil {
+					db.Statement.redactScanned(redactingField, elem)
+				}
+
+				if identityMap != nil {
+					if key, ok := primaryKeyOf(db.Statement.Context, sch, elem.Elem()); ok {
+						if scanned, ok := identityMap[key]; ok {
+							elem = scanned
+						} else {
+							identityMap[key] = elem
+						}
+					}
+				}
 
 				if !update {
 					if !isPtr {
@@ -329,6 +497,10 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 						if reflectValue.Len() >= int(db.RowsAffected) {
 							reflectValue.Index(int(db.RowsAffected - 1)).Set(elem)
 						}
+					} else if reused.IsValid() {
+						if isPtr {
+							reused.Set(elem)
+						}
 					} else {
 						reflectValue = reflect.Append(reflectValue, elem)
 					}
@@ -343,7 +515,11 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 				if mode == ScanInitialized && reflectValue.Kind() == reflect.Struct {
 					db.Statement.ReflectValue.Set(reflect.Zero(reflectValue.Type()))
 				}
-				db.scanIntoStruct(rows, reflectValue, values, fields, joinFields)
+				db.scanIntoStruct(rows, reflectValue, values, fields, joinFields, prefixed, decoders, holders)
+				db.scanAssociationJSON(reflectValue, values, jsonRels)
+				if redactingField != nil && reflectValue.CanAddr() {
+					db.Statement.redactScanned(redactingField, reflectValue.Addr())
+				}
 			}
 		default:
 			db.AddError(rows.Scan(dest))
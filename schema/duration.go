@@ -0,0 +1,282 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DurationReflectType the reflect type of time.Duration
+var DurationReflectType = reflect.TypeOf(time.Duration(0))
+
+// durationUnits the units of the `durationUnit` tag
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "us": time.Microsecond, "ms": time.Millisecond,
+	"s": time.Second, "m": time.Minute, "h": time.Hour,
+}
+
+// DurationSerializer the serializer of the time.Duration fields tagged with `durationUnit`, which are stored as the
+// integers of the unit, truncated toward zero, e.g:
+//
+//	type Task struct {
+//	  Timeout time.Duration `gorm:"durationUnit:ms"` // 1500 of 1.5s
+//	}
+//
+// The durations without the tag are stored as the nanoseconds
+type DurationSerializer struct {
+	Unit time.Duration
+}
+
+// IntervalSerializer the serializer of the time.Duration fields typed with `type:interval` of postgres, which are
+// stored as the interval literals, the months of the intervals are 30 days and the years are 365.25 days
+type IntervalSerializer struct{}
+
+// Scan implements serializer interface
+func (s DurationSerializer) Scan(ctx context.Context, field *Field, dst reflect.Value, dbValue interface{}) (err error) {
+	var units int64
+	switch v := dbValue.(type) {
+	case nil:
+		return setDuration(ctx, field, dst, nil)
+	case int64:
+		units = v
+	case float64:
+		units = int64(math.Round(v))
+	case []byte:
+		units, err = strconv.ParseInt(strings.TrimSpace(string(v)), 10, 64)
+	case string:
+		units, err = strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	default:
+		if rv := reflect.ValueOf(dbValue); rv.CanInt() {
+			units = rv.Int()
+		} else {
+			err = fmt.Errorf("unsupported duration %#v", dbValue)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", field.Name, err)
+	}
+
+	d := time.Duration(units) * s.unit()
+	return setDuration(ctx, field, dst, &d)
+}
+
+// Value implements serializer interface
+func (s DurationSerializer) Value(ctx context.Context, field *Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	d, ok := durationOf(fieldValue)
+	if !ok {
+		return nil, nil
+	}
+	return int64(d / s.unit()), nil
+}
+
+func (s DurationSerializer) unit() time.Duration {
+	if s.Unit <= 0 {
+		return time.Nanosecond
+	}
+	return s.Unit
+}
+
+// Scan implements serializer interface
+func (IntervalSerializer) Scan(ctx context.Context, field *Field, dst reflect.Value, dbValue interface{}) error {
+	if dbValue == nil {
+		return setDuration(ctx, field, dst, nil)
+	}
+
+	text, err := literalText(dbValue)
+	if err != nil {
+		return err
+	}
+
+	d, err := parseInterval(text)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", field.Name, err)
+	}
+	return setDuration(ctx, field, dst, &d)
+}
+
+// Value implements serializer interface
+func (IntervalSerializer) Value(ctx context.Context, field *Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	d, ok := durationOf(fieldValue)
+	if !ok {
+		return nil, nil
+	}
+	return formatInterval(d), nil
+}
+
+// DurationValue returns the value stored by the duration field of the time.Duration value, e.g: the milliseconds of
+// the field tagged with `durationUnit:ms`, the values of the other fields and types are returned as is
+func (field *Field) DurationValue(ctx context.Context, value interface{}) interface{} {
+	if _, ok := durationOf(value); !ok {
+		return value
+	}
+
+	switch s := field.Serializer.(type) {
+	case DurationSerializer:
+		value, _ = s.Value(ctx, field, reflect.Value{}, value)
+	case IntervalSerializer:
+		value, _ = s.Value(ctx, field, reflect.Value{}, value)
+	}
+	return value
+}
+
+// parseDuration sets the serializers of the time.Duration fields tagged with `durationUnit` or typed with interval
+func (field *Field) parseDuration() error {
+	unitName, hasUnit := field.TagSettings["DURATIONUNIT"]
+	if field.IndirectFieldType != DurationReflectType {
+		if hasUnit {
+			return fmt.Errorf("invalid field %s: durationUnit of %v, which should be time.Duration", field.Name, field.FieldType)
+		}
+		return nil
+	}
+
+	if field.Serializer != nil {
+		return nil
+	}
+
+	if strings.EqualFold(strings.TrimSpace(string(field.DataType)), "interval") {
+		field.Serializer = IntervalSerializer{}
+		return nil
+	}
+
+	if hasUnit {
+		unit, ok := durationUnits[strings.ToLower(strings.TrimSpace(unitName))]
+		if !ok {
+			return fmt.Errorf("invalid field %s: unknown durationUnit %s", field.Name, unitName)
+		}
+
+		if unit != time.Nanosecond {
+			field.Serializer = DurationSerializer{Unit: unit}
+		}
+	}
+	return nil
+}
+
+// durationOf returns the duration of value, which is a time.Duration or a non-nil pointer of it
+func durationOf(value interface{}) (time.Duration, bool) {
+	switch v := value.(type) {
+	case time.Duration:
+		return v, true
+	case *time.Duration:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return 0, false
+}
+
+// setDuration sets the duration field to d, nil sets it to zero
+func setDuration(ctx context.Context, field *Field, dst reflect.Value, d *time.Duration) error {
+	fieldValue := field.ReflectValueOf(ctx, dst)
+	switch {
+	case d == nil:
+		fieldValue.Set(reflect.Zero(field.FieldType))
+	case fieldValue.Kind() == reflect.Ptr:
+		fieldValue.Set(reflect.New(DurationReflectType))
+		fieldValue.Elem().SetInt(int64(*d))
+	default:
+		fieldValue.SetInt(int64(*d))
+	}
+	return nil
+}
+
+// formatInterval formats d as the interval literal, e.g: -26:03:04.5
+func formatInterval(d time.Duration) string {
+	var builder strings.Builder
+	if d < 0 {
+		builder.WriteByte('-')
+	}
+
+	micros := d.Microseconds()
+	if micros < 0 {
+		micros = -micros
+	}
+
+	seconds := micros / 1e6
+	fmt.Fprintf(&builder, "%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	if micros%1e6 != 0 {
+		builder.WriteString(strings.TrimRight(fmt.Sprintf(".%06d", micros%1e6), "0"))
+	}
+	return builder.String()
+}
+
+// intervalUnits the units of the interval literals of the postgres and postgres_verbose styles
+var intervalUnits = map[string]time.Duration{
+	"microsecond": time.Microsecond, "millisecond": time.Millisecond,
+	"second": time.Second, "sec": time.Second, "minute": time.Minute, "min": time.Minute,
+	"hour": time.Hour, "day": 24 * time.Hour, "week": 7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour, "mon": 30 * 24 * time.Hour, "year": 8766 * time.Hour,
+}
+
+// parseInterval parses the interval literal of the postgres and postgres_verbose styles, e.g: 1 day -02:03:04.5 or
+// @ 1 day 2 hours ago
+func parseInterval(text string) (d time.Duration, err error) {
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(text), "@"))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("invalid interval %q", text)
+	}
+
+	ago := false
+	if fields[len(fields)-1] == "ago" {
+		ago, fields = true, fields[:len(fields)-1]
+	}
+
+	for idx := 0; idx < len(fields); idx++ {
+		if strings.Contains(fields[idx], ":") {
+			t, err := parseIntervalTime(fields[idx])
+			if err != nil {
+				return 0, fmt.Errorf("invalid interval %q: %w", text, err)
+			}
+			d += t
+			continue
+		}
+
+		if idx+1 >= len(fields) {
+			return 0, fmt.Errorf("invalid interval %q: missing unit of %s", text, fields[idx])
+		}
+
+		number, err := strconv.ParseFloat(fields[idx], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q: %w", text, err)
+		}
+
+		idx++
+		unit, ok := intervalUnits[strings.TrimSuffix(strings.ToLower(fields[idx]), "s")]
+		if !ok {
+			return 0, fmt.Errorf("invalid interval %q: unknown unit %s", text, fields[idx])
+		}
+		d += time.Duration(math.Round(number * float64(unit)))
+	}
+
+	if ago {
+		d = -d
+	}
+	return d, nil
+}
+
+// parseIntervalTime parses the time of the interval literals, e.g: -02:03:04.5
+func parseIntervalTime(text string) (time.Duration, error) {
+	negative := strings.HasPrefix(text, "-")
+	parts := strings.Split(strings.TrimLeft(text, "+-"), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid time %s", text)
+	}
+
+	var d time.Duration
+	for idx, unit := range []time.Duration{time.Hour, time.Minute, time.Second}[:len(parts)] {
+		number, err := strconv.ParseFloat(parts[idx], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time %s", text)
+		}
+		d += time.Duration(math.Round(number * float64(unit)))
+	}
+
+	if negative {
+		d = -d
+	}
+	return d, nil
+}
@@ -0,0 +1,147 @@
+package schema_test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils/tests"
+)
+
+type DurationModel struct {
+	ID       uint
+	Timeout  time.Duration  `gorm:"durationUnit:ms"`
+	Backoff  *time.Duration `gorm:"durationUnit:s"`
+	Elapsed  time.Duration
+	Interval time.Duration `gorm:"type:interval"`
+}
+
+func TestParseDurationFields(t *testing.T) {
+	s, err := schema.Parse(&DurationModel{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse, got error %v", err)
+	}
+
+	if field := s.LookUpField("Timeout"); field.DataType != schema.Int || field.Serializer != (schema.DurationSerializer{Unit: time.Millisecond}) {
+		t.Errorf("Timeout should be stored as milliseconds, got %v %#v", field.DataType, field.Serializer)
+	}
+
+	if field := s.LookUpField("Elapsed"); field.DataType != schema.Int || field.Serializer != nil {
+		t.Errorf("Elapsed should be stored as nanoseconds, got %v %#v", field.DataType, field.Serializer)
+	}
+
+	if field := s.LookUpField("Interval"); field.DataType != "interval" || field.Serializer != (schema.IntervalSerializer{}) || len(s.PostgresTypeFields) != 1 {
+		t.Errorf("Interval should be an interval field, got %v %#v", field.DataType, field.Serializer)
+	}
+
+	type InvalidUnit struct {
+		Timeout int64 `gorm:"durationUnit:ms"`
+	}
+
+	type UnknownUnit struct {
+		Timeout time.Duration `gorm:"durationUnit:days"`
+	}
+
+	for _, value := range []interface{}{&InvalidUnit{}, &UnknownUnit{}} {
+		if _, err := schema.Parse(value, &sync.Map{}, schema.NamingStrategy{}); err == nil {
+			t.Errorf("%T should be invalid", value)
+		}
+	}
+}
+
+func TestDurationSerializer(t *testing.T) {
+	s, err := schema.Parse(&DurationModel{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse, got error %v", err)
+	}
+
+	var (
+		ctx     = context.Background()
+		backoff = 90 * time.Second
+		model   = DurationModel{Timeout: 1500*time.Millisecond + time.Microsecond, Backoff: &backoff}
+		timeout = s.LookUpField("Timeout")
+		field   = s.LookUpField("Backoff")
+	)
+
+	if value, err := timeout.Serializer.Value(ctx, timeout, reflect.ValueOf(&model), model.Timeout); err != nil || value != int64(1500) {
+		t.Errorf("the timeout should be stored as milliseconds, got %v, error %v", value, err)
+	}
+
+	if value, err := field.Serializer.Value(ctx, field, reflect.ValueOf(&model), model.Backoff); err != nil || value != int64(90) {
+		t.Errorf("the backoff should be stored as seconds, got %v, error %v", value, err)
+	}
+
+	if value := timeout.DurationValue(ctx, 2*time.Second); value != int64(2000) {
+		t.Errorf("the duration value should be milliseconds, got %v", value)
+	}
+
+	var scanned DurationModel
+	for _, dbValue := range []interface{}{int64(2500), []byte("2500"), "2500", float64(2500), int32(2500)} {
+		if err := timeout.Serializer.Scan(ctx, timeout, reflect.ValueOf(&scanned), dbValue); err != nil || scanned.Timeout != 2500*time.Millisecond {
+			t.Errorf("failed to scan %#v, got %v, error %v", dbValue, scanned.Timeout, err)
+		}
+	}
+
+	if err := field.Serializer.Scan(ctx, field, reflect.ValueOf(&scanned), int64(3)); err != nil || scanned.Backoff == nil || *scanned.Backoff != 3*time.Second {
+		t.Errorf("failed to scan the backoff, got %v, error %v", scanned.Backoff, err)
+	}
+
+	if err := field.Serializer.Scan(ctx, field, reflect.ValueOf(&scanned), nil); err != nil || scanned.Backoff != nil {
+		t.Errorf("NULL should be scanned as nil, got %v, error %v", scanned.Backoff, err)
+	}
+}
+
+func TestIntervalSerializer(t *testing.T) {
+	s, err := schema.Parse(&DurationModel{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse, got error %v", err)
+	}
+
+	var (
+		ctx   = context.Background()
+		field = s.LookUpField("Interval")
+	)
+
+	for d, expected := range map[time.Duration]string{
+		0: "0:00:00",
+		26*time.Hour + 3*time.Minute + 4500*time.Millisecond: "26:03:04.5",
+		-90 * time.Second: "-0:01:30",
+		time.Microsecond:  "0:00:00.000001",
+	} {
+		value, err := field.Serializer.Value(ctx, field, reflect.Value{}, d)
+		if err != nil || value != expected {
+			t.Errorf("%v should be written as %v, got %v, error %v", d, expected, value, err)
+		}
+
+		var scanned DurationModel
+		if err := field.Serializer.Scan(ctx, field, reflect.ValueOf(&scanned), expected); err != nil || scanned.Interval != d {
+			t.Errorf("failed to scan %v, got %v, error %v", expected, scanned.Interval, err)
+		}
+	}
+
+	for literal, expected := range map[string]time.Duration{
+		"1 day 02:03:04.5":     26*time.Hour + 3*time.Minute + 4500*time.Millisecond,
+		"-1 days +02:00:00":    -22 * time.Hour,
+		"1 mon 2 days":         32 * 24 * time.Hour,
+		"@ 1 hour 30 mins ago": -90 * time.Minute,
+		"00:00:00":             0,
+		"3 days":               72 * time.Hour,
+	} {
+		var scanned DurationModel
+		if err := field.Serializer.Scan(ctx, field, reflect.ValueOf(&scanned), []byte(literal)); err != nil || scanned.Interval != expected {
+			t.Errorf("failed to scan %v, got %v, error %v", literal, scanned.Interval, err)
+		}
+	}
+
+	for _, literal := range []string{"", "1", "1 fortnight", "1:2:3:4", "x:00"} {
+		var scanned DurationModel
+		if err := field.Serializer.Scan(ctx, field, reflect.ValueOf(&scanned), literal); err == nil {
+			t.Errorf("%q should be invalid", literal)
+		}
+	}
+
+	tests.AssertEqual(t, field.DurationValue(ctx, time.Hour), "1:00:00")
+}
@@ -0,0 +1,185 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// DynamicFieldOption the option of the dynamic field, which is the setting of the `gorm` tag, e.g:
+// DynamicFieldOption("size:256")
+type DynamicFieldOption string
+
+// dynamic field options
+const (
+	PrimaryKey     DynamicFieldOption = "primaryKey"
+	NotNull        DynamicFieldOption = "not null"
+	Unique         DynamicFieldOption = "unique"
+	AutoCreateTime DynamicFieldOption = "autoCreateTime"
+	AutoUpdateTime DynamicFieldOption = "autoUpdateTime"
+	// SoftDelete sets the time, true or the unix milliseconds of the time, flag or integer fields instead of deleting the
+	// rows, see the `softDelete` tag
+	SoftDelete DynamicFieldOption = "softDelete"
+	// ReadOnly the field is not created or updated
+	ReadOnly DynamicFieldOption = "->"
+	// CreateOnly the field is created and read but not updated
+	CreateOnly DynamicFieldOption = "<-:create"
+	// WriteOnly the field is created and updated but not read
+	WriteOnly DynamicFieldOption = "<-;->:false"
+)
+
+var dynamicFieldTypes = map[DataType]reflect.Type{
+	Bool:   reflect.TypeOf(false),
+	Int:    reflect.TypeOf(int64(0)),
+	Uint:   reflect.TypeOf(uint64(0)),
+	Float:  reflect.TypeOf(float64(0)),
+	String: reflect.TypeOf(""),
+	Time:   reflect.TypeOf(time.Time{}),
+	Bytes:  reflect.TypeOf([]byte{}),
+}
+
+var dynamicSoftDeletes = map[DataType]string{Time: "time", Bool: "flag", Int: "milli", Uint: "milli"}
+
+// DynamicSchema the schema of the table defined at runtime without the Go struct, e.g: the tables of the user-defined
+// fields, the values of the table are the maps keyed by the columns
+//
+//	schema.NewDynamic("custom_objects_42").
+//		Field("id", schema.Uint, schema.PrimaryKey).
+//		Field("name", schema.String, schema.NotNull, "size:256").
+//		Field("created_at", schema.Time, schema.AutoCreateTime).
+//		Field("deleted_at", schema.Time, schema.SoftDelete)
+type DynamicSchema struct {
+	Table  string
+	Fields []DynamicField
+}
+
+// DynamicField the field of DynamicSchema
+type DynamicField struct {
+	Name     string
+	DataType DataType
+	Options  []DynamicFieldOption
+}
+
+// NewDynamic returns the dynamic schema of table
+func NewDynamic(table string) *DynamicSchema {
+	return &DynamicSchema{Table: table}
+}
+
+// Field adds the column name of dataType, which is one of Bool, Int, Uint, Float, String, Time and Bytes
+func (dynamic *DynamicSchema) Field(name string, dataType DataType, options ...DynamicFieldOption) *DynamicSchema {
+	dynamic.Fields = append(dynamic.Fields, DynamicField{Name: name, DataType: dataType, Options: options})
+	return dynamic
+}
+
+// Parse parses the dynamic schema into the schema of the struct built from its fields, the fields get and set the map
+// values as well, see Schema.Dynamic
+func (dynamic *DynamicSchema) Parse(namer Namer) (*Schema, error) {
+	if dynamic.Table == "" || len(dynamic.Fields) == 0 {
+		return nil, fmt.Errorf("%w: dynamic schema %q without fields", ErrUnsupportedDataType, dynamic.Table)
+	}
+
+	var (
+		structFields = make([]reflect.StructField, 0, len(dynamic.Fields))
+		names        = make(map[string]bool, len(dynamic.Fields))
+	)
+	for idx, f := range dynamic.Fields {
+		fieldType, ok := dynamicFieldTypes[f.DataType]
+		if !ok || f.Name == "" {
+			return nil, fmt.Errorf("%w: field %q of dynamic schema %s with type %q", ErrUnsupportedDataType, f.Name, dynamic.Table, f.DataType)
+		}
+
+		name := NamingStrategy{}.toSchemaName(f.Name)
+		if !isExportedIdentifier(name) || names[name] {
+			name = fmt.Sprintf("Field%d", idx)
+		}
+		names[name] = true
+
+		settings := []string{"column:" + f.Name}
+		for _, option := range f.Options {
+			if option == SoftDelete {
+				mode, ok := dynamicSoftDeletes[f.DataType]
+				if !ok {
+					return nil, fmt.Errorf("%w: soft delete field %q of dynamic schema %s with type %q", ErrUnsupportedDataType, f.Name, dynamic.Table, f.DataType)
+				}
+				option += DynamicFieldOption(":" + mode)
+			}
+			settings = append(settings, string(option))
+		}
+
+		structFields = append(structFields, reflect.StructField{
+			Name: name,
+			Type: fieldType,
+			Tag:  reflect.StructTag(fmt.Sprintf("gorm:%q", strings.Join(settings, ";"))),
+		})
+	}
+
+	schema, err := ParseWithSpecialTableName(reflect.New(reflect.StructOf(structFields)).Interface(), &sync.Map{}, namer, dynamic.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	schema.Name, schema.Dynamic = dynamic.Table, true
+	for _, field := range schema.Fields {
+		field.setupMapValuer()
+	}
+	return schema, nil
+}
+
+func isExportedIdentifier(name string) bool {
+	for idx, r := range name {
+		if (idx == 0 && !unicode.IsUpper(r)) || (!unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_') {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// setupMapValuer makes ValueOf and Set of the dynamic field get and set the value of its column if the value is the
+// map, the values set to the maps are converted like the struct fields, e.g: the unix times of autoCreateTime:milli
+func (field *Field) setupMapValuer() {
+	valueOf, set := field.ValueOf, field.Set
+
+	field.ValueOf = func(ctx context.Context, v reflect.Value) (interface{}, bool) {
+		if m, ok := mapValueOf(v); ok {
+			value, ok := m[field.DBName]
+			if !ok {
+				value = m[field.Name]
+			}
+			return value, value == nil || reflect.ValueOf(value).IsZero()
+		}
+		return valueOf(ctx, v)
+	}
+
+	field.Set = func(ctx context.Context, v reflect.Value, value interface{}) error {
+		if m, ok := mapValueOf(v); ok {
+			if value == nil {
+				m[field.DBName] = nil
+				return nil
+			}
+
+			rv := reflect.New(field.Schema.ModelType).Elem()
+			if err := set(ctx, rv, value); err != nil {
+				return err
+			}
+			m[field.DBName], _ = valueOf(ctx, rv)
+			return nil
+		}
+		return set(ctx, v, value)
+	}
+}
+
+func mapValueOf(v reflect.Value) (map[string]interface{}, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Map && !v.IsNil() {
+		m, ok := v.Interface().(map[string]interface{})
+		return m, ok
+	}
+	return nil, false
+}
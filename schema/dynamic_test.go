@@ -0,0 +1,49 @@
+package schema
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDynamicSchema(t *testing.T) {
+	s, err := NewDynamic("custom_objects").
+		Field("id", Uint, PrimaryKey).
+		Field("1st_name", String, ReadOnly).
+		Field("created_at", Int, "autoCreateTime:milli").
+		Parse(NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse dynamic schema, got error %v", err)
+	}
+
+	if s.Name != "custom_objects" || s.Table != "custom_objects" || !s.Dynamic || s.PrioritizedPrimaryField == nil || s.PrioritizedPrimaryField.DBName != "id" {
+		t.Fatalf("dynamic schema should be parsed, got %+v", s)
+	}
+
+	if field := s.LookUpField("1st_name"); field == nil || field.Name != "Field1" || field.Creatable || field.Updatable || !field.Readable {
+		t.Errorf("read only field should be parsed, got %+v", field)
+	}
+
+	var (
+		ctx      = context.Background()
+		field    = s.LookUpField("created_at")
+		mapValue = map[string]interface{}{}
+		now      = time.Now()
+	)
+	if _, isZero := field.ValueOf(ctx, reflect.ValueOf(mapValue)); !isZero {
+		t.Errorf("missing map value should be zero")
+	}
+
+	if err := field.Set(ctx, reflect.ValueOf(&mapValue), now); err != nil || mapValue["created_at"] != now.UnixMilli() {
+		t.Errorf("map value should be set as unix milliseconds, got %#v, error %v", mapValue, err)
+	}
+
+	if value, isZero := field.ValueOf(ctx, reflect.ValueOf(mapValue)); isZero || value != now.UnixMilli() {
+		t.Errorf("map value should be got, got %v", value)
+	}
+
+	if _, err := NewDynamic("custom_objects").Field("deleted", String, SoftDelete).Parse(NamingStrategy{}); err == nil {
+		t.Errorf("should return error for the soft delete string field")
+	}
+}
@@ -67,10 +67,18 @@ type Field struct {
 	Readable               bool
 	AutoCreateTime         TimeType
 	AutoUpdateTime         TimeType
+	AutoCreatedBy          bool
+	AutoUpdatedBy          bool
+	AutoDeletedBy          bool
+	SkipDiff               bool        // tagged with `skipDiff`, the field is considered changed without comparing, e.g: huge blobs
+	QueryCollate           string      // tagged with `queryCollate`, the collation of the conditions built from structs and maps
+	AutoUUID               bool        // tagged with `default:uuid`, the UUID is generated when creating with the zero value
+	UUIDStorage            UUIDStorage // tagged with `uuidStorage` or `default:uuid`, how the UUIDs are stored
 	HasDefaultValue        bool
 	DefaultValue           string
 	DefaultValueInterface  interface{}
 	NotNull                bool
+	Nullable               bool // the field is a nullable wrapper like sql.Null[T], whose value is NULL when it isn't valid
 	Unique                 bool
 	Comment                string
 	Size                   int
@@ -96,6 +104,9 @@ type Field struct {
 	// It causes field unnecessarily migration.
 	// Therefore, we need to record the UniqueIndex on this column (exclude Mul UniqueIndex) for MigrateColumnUnique.
 	UniqueIndex string
+
+	// the index of the valid field of the nullable wrapper
+	nullValidIndex int
 }
 
 func (field *Field) BindName() string {
@@ -140,6 +151,13 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 	// if field is valuer, used its value or first field as data type
 	valuer, isValuer := fieldValue.Interface().(driver.Valuer)
 	if isValuer {
+		var valueIndex int
+		_, isScanner := fieldValue.Interface().(sql.Scanner)
+		// the explicit valuers report their zero values, e.g: gorm.Optional[bool] isn't a nullable wrapper
+		if _, isExplicit := fieldValue.Interface().(ExplicitValuer); isScanner && !isExplicit {
+			field.nullValidIndex, valueIndex, field.Nullable = parseNullableWrapper(field.IndirectFieldType)
+		}
+
 		if _, ok := fieldValue.Interface().(GormDataTypeInterface); !ok {
 			if v, err := valuer.Value(); reflect.ValueOf(v).IsValid() && err == nil {
 				fieldValue = reflect.ValueOf(v)
@@ -180,6 +198,15 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 				}
 			}
 
+			if field.Nullable {
+				// use the type of the wrapped value as data type, e.g: use `time.Time` for sql.Null[time.Time]
+				valueType := field.IndirectFieldType.Field(valueIndex).Type
+				for valueType.Kind() == reflect.Ptr {
+					valueType = valueType.Elem()
+				}
+				fieldValue = reflect.New(valueType)
+			}
+
 			getRealFieldValue(fieldValue)
 		}
 	}
@@ -212,6 +239,13 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 		field.DefaultValue = v
 	}
 
+	if strings.EqualFold(strings.TrimSpace(field.DefaultValue), "uuid") {
+		// generated by Config.UUIDGenerator instead of the database
+		field.AutoUUID = true
+		field.HasDefaultValue = false
+		field.DefaultValue = ""
+	}
+
 	if num, ok := field.TagSettings["SIZE"]; ok {
 		if field.Size, err = strconv.Atoi(num); err != nil {
 			field.Size = -1
@@ -313,6 +347,32 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 		}
 	}
 
+	if v, ok := field.TagSettings["AUTOCREATEDBY"]; ok && utils.CheckTruth(v) {
+		field.AutoCreatedBy = true
+	}
+
+	if v, ok := field.TagSettings["AUTOUPDATEDBY"]; ok && utils.CheckTruth(v) {
+		field.AutoUpdatedBy = true
+	}
+
+	if v, ok := field.TagSettings["AUTODELETEDBY"]; ok && utils.CheckTruth(v) {
+		field.AutoDeletedBy = true
+	}
+
+	if v, ok := field.TagSettings["SKIPDIFF"]; ok && utils.CheckTruth(v) {
+		field.SkipDiff = true
+	}
+
+	if v, ok := field.TagSettings["QUERYCOLLATE"]; ok {
+		field.QueryCollate = strings.TrimSpace(v)
+	}
+
+	if storage := UUIDStorage(strings.ToLower(strings.TrimSpace(field.TagSettings["UUIDSTORAGE"]))); storage == UUIDString || storage == UUIDBinary {
+		field.setUUIDStorage(storage)
+	} else if field.AutoUUID {
+		field.setUUIDStorage(UUIDString)
+	}
+
 	if field.GORMDataType == "" {
 		field.GORMDataType = field.DataType
 	}
@@ -326,6 +386,12 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 		}
 	}
 
+	if err := field.parseDuration(); err != nil {
+		schema.err = err
+	} else if err := field.parsePostgresType(); err != nil {
+		schema.err = err
+	}
+
 	if field.Size == 0 {
 		switch reflect.Indirect(fieldValue).Kind() {
 		case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Float64:
@@ -446,6 +512,29 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 	return field
 }
 
+// parseNullableWrapper returns the indexes of the valid and value fields of the nullable wrappers like sql.Null[T] and
+// sql.NullString, which are structs of a value and a bool reporting whether the value is valid, the bool is the field
+// named Valid or the only bool field, e.g:
+//
+//	type Option[T any] struct {
+//	  ok    bool
+//	  value T
+//	}
+func parseNullableWrapper(t reflect.Type) (validIndex, valueIndex int, ok bool) {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 || t.ConvertibleTo(TimeReflectType) {
+		return 0, 0, false
+	}
+
+	first, second := t.Field(0), t.Field(1)
+	switch {
+	case second.Type.Kind() == reflect.Bool && (second.Name == "Valid" || first.Type.Kind() != reflect.Bool):
+		return 1, 0, true
+	case first.Type.Kind() == reflect.Bool && (first.Name == "Valid" || second.Type.Kind() != reflect.Bool):
+		return 0, 1, true
+	}
+	return 0, 0, false
+}
+
 // create valuer, setter when parse struct
 func (field *Field) setupValuerAndSetter() {
 	// Setup NewValuePool
@@ -481,6 +570,35 @@ func (field *Field) setupValuerAndSetter() {
 		}
 	}
 
+	if field.Nullable {
+		oldValueOf := field.ValueOf
+		field.ValueOf = func(ctx context.Context, v reflect.Value) (interface{}, bool) {
+			value, zero := oldValueOf(ctx, v)
+			if !zero {
+				if rv := reflect.Indirect(reflect.ValueOf(value)); rv.IsValid() && !rv.Field(field.nullValidIndex).Bool() {
+					zero = true
+				}
+			}
+			return value, zero
+		}
+	}
+
+	if _, ok := reflect.New(field.IndirectFieldType).Interface().(ExplicitValuer); ok {
+		oldValueOf := field.ValueOf
+		field.ValueOf = func(ctx context.Context, v reflect.Value) (interface{}, bool) {
+			value, zero := oldValueOf(ctx, v)
+			if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr && rv.IsNil() {
+				return value, zero
+			}
+
+			if valuer, ok := value.(ExplicitValuer); ok {
+				value, explicit := valuer.ExplicitValue()
+				return value, !explicit
+			}
+			return value, zero
+		}
+	}
+
 	if field.Serializer != nil {
 		oldValuerOf := field.ValueOf
 		field.ValueOf = func(ctx context.Context, v reflect.Value) (interface{}, bool) {
@@ -896,6 +1014,10 @@ func (field *Field) setupValuerAndSetter() {
 					if !reflectV.IsValid() {
 						field.ReflectValueOf(ctx, value).Set(reflect.New(field.FieldType).Elem())
 					} else if reflectV.Kind() == reflect.Ptr && reflectV.IsNil() {
+						// scanned NULL, which resets the valid nullable wrapper
+						if _, zero := field.ValueOf(ctx, value); field.Nullable && !zero {
+							err = field.ReflectValueOf(ctx, value).Interface().(sql.Scanner).Scan(nil)
+						}
 						return
 					} else if reflectV.Type().AssignableTo(field.FieldType) {
 						field.ReflectValueOf(ctx, value).Set(reflectV)
@@ -922,6 +1044,10 @@ func (field *Field) setupValuerAndSetter() {
 					if !reflectV.IsValid() {
 						field.ReflectValueOf(ctx, value).Set(reflect.New(field.FieldType).Elem())
 					} else if reflectV.Kind() == reflect.Ptr && reflectV.IsNil() {
+						// scanned NULL, which resets the valid nullable wrapper
+						if _, zero := field.ValueOf(ctx, value); field.Nullable && !zero {
+							err = field.ReflectValueOf(ctx, value).Addr().Interface().(sql.Scanner).Scan(nil)
+						}
 						return
 					} else if reflectV.Type().AssignableTo(field.FieldType) {
 						field.ReflectValueOf(ctx, value).Set(reflectV)
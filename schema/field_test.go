@@ -3,6 +3,7 @@ package schema_test
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"reflect"
 	"sync"
 	"testing"
@@ -332,3 +333,63 @@ func TestTypeAliasField(t *testing.T) {
 		checkSchemaField(t, alias, f, func(f *schema.Field) {})
 	}
 }
+
+type Option[T any] struct {
+	Some bool
+	Val  T
+}
+
+func (o *Option[T]) Scan(value interface{}) error {
+	if value == nil {
+		*o = Option[T]{}
+		return nil
+	}
+	o.Val, o.Some = value.(T)
+	return nil
+}
+
+func (o Option[T]) Value() (driver.Value, error) {
+	if !o.Some {
+		return nil, nil
+	}
+	return o.Val, nil
+}
+
+type NullableWrapper struct {
+	ID         uint
+	Name       Option[string] `gorm:"default:jinzhu"`
+	Age        *Option[int64]
+	Birthday   Option[time.Time]
+	NullString sql.NullString
+}
+
+func TestParseNullableWrapperField(t *testing.T) {
+	s, err := schema.Parse(&NullableWrapper{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse nullable wrapper, got error %v", err)
+	}
+
+	for name, dataType := range map[string]schema.DataType{"Name": schema.String, "Age": schema.Int, "Birthday": schema.Time, "NullString": schema.String} {
+		field := s.LookUpField(name)
+		if field.DataType != dataType || !field.Nullable {
+			t.Errorf("field %v should be nullable %v, got %v, nullable %v", name, dataType, field.DataType, field.Nullable)
+		}
+	}
+
+	if field := s.LookUpField("Name"); field.DefaultValueInterface != "jinzhu" {
+		t.Errorf("default value should be parsed as the wrapped type, got %#v", field.DefaultValueInterface)
+	}
+
+	ctx := context.Background()
+	value := reflect.ValueOf(&NullableWrapper{
+		Name:       Option[string]{Val: "invalid"},
+		Age:        &Option[int64]{Val: 18},
+		Birthday:   Option[time.Time]{Some: true},
+		NullString: sql.NullString{String: "invalid"},
+	})
+	for name, zero := range map[string]bool{"Name": true, "Age": true, "Birthday": false, "NullString": true} {
+		if _, isZero := s.LookUpField(name).ValueOf(ctx, value); isZero != zero {
+			t.Errorf("zero of field %v should be %v, got %v", name, zero, isZero)
+		}
+	}
+}
@@ -5,16 +5,19 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+
+	"gorm.io/gorm/clause"
 )
 
 type Index struct {
-	Name    string
-	Class   string // UNIQUE | FULLTEXT | SPATIAL
-	Type    string // btree, hash, gist, spgist, gin, and brin
-	Where   string
-	Comment string
-	Option  string        // WITH PARSER parser_name
-	Fields  []IndexOption // Note: IndexOption's Field maybe the same
+	Name       string
+	Class      string // UNIQUE | FULLTEXT | SPATIAL
+	Type       string // btree, hash, gist, spgist, gin, and brin
+	Where      string
+	Comment    string
+	Option     string        // WITH PARSER parser_name
+	OnConflict string        // update | nothing, see Schema.OnConflict
+	Fields     []IndexOption // Note: IndexOption's Field maybe the same
 }
 
 type IndexOption struct {
@@ -61,6 +64,9 @@ func (schema *Schema) ParseIndexes() []*Index {
 				if idx.Option == "" {
 					idx.Option = index.Option
 				}
+				if idx.OnConflict == "" {
+					idx.OnConflict = index.OnConflict
+				}
 
 				idx.Fields = append(idx.Fields, index.Fields...)
 				sort.Slice(idx.Fields, func(i, j int) bool {
@@ -77,6 +83,35 @@ func (schema *Schema) ParseIndexes() []*Index {
 	return indexes
 }
 
+// parseOnConflict returns the OnConflict clause declared by the `onConflict` setting of the unique index, e.g:
+// `gorm:"uniqueIndex:idx_email,onConflict:update"` updates the conflicted rows like clause.OnConflict{UpdateAll: true},
+// `onConflict:nothing` ignores them
+func (schema *Schema) parseOnConflict() *clause.OnConflict {
+	declared := false
+	for _, field := range schema.Fields {
+		if strings.Contains(strings.ToUpper(field.TagSettings["UNIQUEINDEX"]+field.TagSettings["INDEX"]), "ONCONFLICT") {
+			declared = true
+			break
+		}
+	}
+	if !declared {
+		return nil
+	}
+
+	for _, index := range schema.ParseIndexes() {
+		if index.Class != "UNIQUE" || index.OnConflict == "" {
+			continue
+		}
+
+		onConflict := &clause.OnConflict{DoNothing: index.OnConflict == "nothing", UpdateAll: index.OnConflict == "update"}
+		for _, option := range index.Fields {
+			onConflict.Columns = append(onConflict.Columns, clause.Column{Name: option.DBName})
+		}
+		return onConflict
+	}
+	return nil
+}
+
 func (schema *Schema) LookIndex(name string) *Index {
 	if schema != nil {
 		indexes := schema.ParseIndexes()
@@ -143,12 +178,13 @@ func parseFieldIndexes(field *Field) (indexes []Index, err error) {
 				}
 
 				indexes = append(indexes, Index{
-					Name:    name,
-					Class:   settings["CLASS"],
-					Type:    settings["TYPE"],
-					Where:   settings["WHERE"],
-					Comment: settings["COMMENT"],
-					Option:  settings["OPTION"],
+					Name:       name,
+					Class:      settings["CLASS"],
+					Type:       settings["TYPE"],
+					Where:      settings["WHERE"],
+					Comment:    settings["COMMENT"],
+					Option:     settings["OPTION"],
+					OnConflict: strings.ToLower(strings.TrimSpace(settings["ONCONFLICT"])),
 					Fields: []IndexOption{{
 						Field:      field,
 						Expression: settings["EXPRESSION"],
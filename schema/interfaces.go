@@ -1,6 +1,9 @@
 package schema
 
 import (
+	"strings"
+	"sync"
+
 	"gorm.io/gorm/clause"
 )
 
@@ -21,6 +24,12 @@ type FieldNewValuePool interface {
 	Put(interface{})
 }
 
+// ExplicitValuer the field values reporting whether they are set explicitly, e.g: gorm.Optional, the explicit values
+// are created and updated even if they are zero, ValueOf returns the value and treats the others as zero
+type ExplicitValuer interface {
+	ExplicitValue() (value interface{}, explicit bool)
+}
+
 // CreateClausesInterface create clauses interface
 type CreateClausesInterface interface {
 	CreateClauses(*Field) []clause.Interface
@@ -40,3 +49,44 @@ type UpdateClausesInterface interface {
 type DeleteClausesInterface interface {
 	DeleteClauses(*Field) []clause.Interface
 }
+
+// DefaultSelectInterface the models queried with the columns instead of *, e.g: the wide tables, the primary keys and
+// the foreign keys are selected as well, see Schema.DefaultSelectColumns
+type DefaultSelectInterface interface {
+	DefaultSelect() []string
+}
+
+// DefaultCreateBatchSizeInterface the models created in batches of the size by default, e.g: the log tables
+type DefaultCreateBatchSizeInterface interface {
+	DefaultCreateBatchSize() int
+}
+
+// DefaultClausesInterface the models queried with the clauses by default, e.g: the index hints
+type DefaultClausesInterface interface {
+	DefaultClauses() []clause.Expression
+}
+
+// OnConflictClauseInterface the models declaring the OnConflict clause used to create them, see Schema.OnConflict
+type OnConflictClauseInterface interface {
+	OnConflictClause() clause.OnConflict
+}
+
+// SoftDeleteStrategy soft delete strategy interface, implemented by the types of the fields enabling soft delete,
+// e.g. gorm.DeletedAt keeps the deleted rows in the table, gorm.ArchivedAt moves them to an archive table
+type SoftDeleteStrategy interface {
+	// ArchiveTable returns the table the deleted rows of table are moved to, empty if they are kept in table
+	ArchiveTable(table string) string
+}
+
+var softDeleteMap = sync.Map{}
+
+// RegisterSoftDelete registers the soft delete of the fields tagged with `softDelete:name`, it's used instead of the
+// field's value to get the clauses and the SoftDeleteStrategy of the field
+func RegisterSoftDelete(name string, softDelete interface{}) {
+	softDeleteMap.Store(strings.ToLower(name), softDelete)
+}
+
+// GetSoftDelete get the soft delete registered with name
+func GetSoftDelete(name string) (softDelete interface{}, ok bool) {
+	return softDeleteMap.Load(strings.ToLower(name))
+}
@@ -0,0 +1,61 @@
+package schema
+
+// parseModelDefaults caches the statement defaults declared by the model, which aren't evaluated per query
+func (schema *Schema) parseModelDefaults(model interface{}) {
+	if selecter, ok := model.(DefaultSelectInterface); ok {
+		schema.DefaultSelect = selecter.DefaultSelect()
+	}
+
+	if batchSizer, ok := model.(DefaultCreateBatchSizeInterface); ok {
+		schema.DefaultCreateBatchSize = batchSizer.DefaultCreateBatchSize()
+	}
+
+	if clauser, ok := model.(DefaultClausesInterface); ok {
+		schema.DefaultClauses = clauser.DefaultClauses()
+	}
+}
+
+// DefaultSelectColumns returns the columns of DefaultSelect with the primary keys, the foreign keys of the belongs to
+// relationships and keys, e.g: the foreign keys of the preloaded relationships, nil if the model doesn't declare
+// DefaultSelect
+func (schema *Schema) DefaultSelectColumns(keys ...string) []string {
+	if len(schema.DefaultSelect) == 0 {
+		return nil
+	}
+
+	var (
+		columns  = make([]string, 0, len(schema.DefaultSelect)+len(schema.PrimaryFieldDBNames)+len(keys))
+		selected = map[string]bool{}
+	)
+	appendColumn := func(column string) {
+		if field := schema.LookUpField(column); field != nil && field.DBName != "" {
+			column = field.DBName
+		}
+
+		if !selected[column] {
+			selected[column] = true
+			columns = append(columns, column)
+		}
+	}
+
+	for _, column := range schema.DefaultSelect {
+		appendColumn(column)
+	}
+
+	for _, column := range schema.PrimaryFieldDBNames {
+		appendColumn(column)
+	}
+
+	for _, rel := range schema.Relationships.BelongsTo {
+		for _, ref := range rel.References {
+			if ref.ForeignKey != nil && ref.ForeignKey.Schema == schema && ref.ForeignKey.DBName != "" {
+				appendColumn(ref.ForeignKey.DBName)
+			}
+		}
+	}
+
+	for _, key := range keys {
+		appendColumn(key)
+	}
+	return columns
+}
@@ -0,0 +1,635 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArraySerializer the serializer of the postgres array fields, which are the slices of strings, numbers and bools, or
+// the pointers of them, or the nested slices of the multidimensional arrays, typed with the array types, e.g:
+//
+//	type Post struct {
+//	  Tags   []string  `gorm:"type:text[]"`
+//	  Scores [][]int64 `gorm:"type:int[][]"`
+//	}
+//
+// The arrays are scanned from and written as the array literals, e.g: {go,"a b",NULL}, unless the driver binds the
+// slices as arrays, see WithDriverArrays. It's used by the fields of the array types without serializers automatically
+type ArraySerializer struct{}
+
+// CompositeSerializer the serializer of the postgres composite fields, which are the structs tagged with `composite`
+// and the name of the composite type, whose exported fields are the attributes of the type in order, e.g:
+//
+//	type Point struct {
+//	  Lat, Lng float64
+//	}
+//
+//	type Place struct {
+//	  Location Point `gorm:"composite:point_t"`
+//	}
+//
+// The composites are scanned from and written as the composite literals, e.g: (1.5,2.5)
+type CompositeSerializer struct{}
+
+// driverArraysKey marks the context whose arrays are bound as the slices
+type driverArraysKey struct{}
+
+// WithDriverArrays returns the context whose arrays are bound as the slices instead of the array literals, which are
+// converted by the driver, e.g: pgx
+func WithDriverArrays(ctx context.Context) context.Context {
+	return context.WithValue(ctx, driverArraysKey{}, true)
+}
+
+// DriverArrays reports whether the arrays of the context are bound as the slices, see WithDriverArrays
+func DriverArrays(ctx context.Context) bool {
+	return ctx != nil && ctx.Value(driverArraysKey{}) != nil
+}
+
+// IsPostgresType reports whether the field is an array, composite or interval field, which is only supported by postgres
+func (field *Field) IsPostgresType() bool {
+	switch field.Serializer.(type) {
+	case ArraySerializer, CompositeSerializer, IntervalSerializer:
+		return true
+	}
+	return false
+}
+
+// Scan implements serializer interface
+func (ArraySerializer) Scan(ctx context.Context, field *Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType).Elem()
+	if dbValue != nil {
+		if rv := reflect.ValueOf(dbValue); rv.Type().AssignableTo(field.FieldType) {
+			// scanned by the driver
+			fieldValue.Set(rv)
+		} else {
+			text, err := literalText(dbValue)
+			if err != nil {
+				return err
+			}
+
+			elems, err := parseArrayLiteral(text)
+			if err != nil {
+				return err
+			}
+
+			if err = setElem(fieldValue, elems); err != nil {
+				return fmt.Errorf("failed to scan array %s into %s: %w", text, field.Name, err)
+			}
+		}
+	}
+
+	field.ReflectValueOf(ctx, dst).Set(fieldValue)
+	return nil
+}
+
+// Value implements serializer interface
+func (ArraySerializer) Value(ctx context.Context, field *Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(fieldValue)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() || rv.Kind() == reflect.Ptr {
+		return nil, nil
+	} else if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("invalid array %s: %#v", field.Name, fieldValue)
+	} else if rv.IsNil() {
+		return nil, nil
+	}
+
+	if DriverArrays(ctx) {
+		return rv.Interface(), nil
+	}
+
+	var builder strings.Builder
+	if err := writeArray(&builder, rv); err != nil {
+		return nil, fmt.Errorf("failed to write array %s: %w", field.Name, err)
+	}
+	return builder.String(), nil
+}
+
+// Scan implements serializer interface
+func (CompositeSerializer) Scan(ctx context.Context, field *Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType).Elem()
+	if dbValue != nil {
+		text, err := literalText(dbValue)
+		if err != nil {
+			return err
+		}
+
+		attrs, err := parseCompositeLiteral(text)
+		if err != nil {
+			return err
+		}
+
+		if err = setElem(fieldValue, attrs); err != nil {
+			return fmt.Errorf("failed to scan composite %s into %s: %w", text, field.Name, err)
+		}
+	}
+
+	field.ReflectValueOf(ctx, dst).Set(fieldValue)
+	return nil
+}
+
+// Value implements serializer interface
+func (CompositeSerializer) Value(ctx context.Context, field *Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	rv := reflect.Indirect(reflect.ValueOf(fieldValue))
+	if !rv.IsValid() {
+		return nil, nil
+	}
+
+	var builder strings.Builder
+	if err := writeComposite(&builder, rv); err != nil {
+		return nil, fmt.Errorf("failed to write composite %s: %w", field.Name, err)
+	}
+	return builder.String(), nil
+}
+
+// parsePostgresType sets the serializers of the array and composite fields, which are checked by their types
+func (field *Field) parsePostgresType() error {
+	if typeName, ok := field.TagSettings["COMPOSITE"]; ok {
+		if field.IndirectFieldType.Kind() != reflect.Struct || field.IndirectFieldType.ConvertibleTo(TimeReflectType) {
+			return fmt.Errorf("invalid composite field %s: %v, which should be a struct", field.Name, field.FieldType)
+		}
+
+		for i := 0; i < field.IndirectFieldType.NumField(); i++ {
+			if attr := field.IndirectFieldType.Field(i); attr.IsExported() && !isTextType(attr.Type) {
+				return fmt.Errorf("invalid composite field %s: unsupported attribute %s %v", field.Name, attr.Name, attr.Type)
+			}
+		}
+
+		if field.DataType = DataType(strings.TrimSpace(typeName)); field.DataType == "" {
+			return fmt.Errorf("invalid composite field %s: the name of the composite type is required", field.Name)
+		}
+		field.Serializer = CompositeSerializer{}
+		return nil
+	}
+
+	// the arrays implementing sql.Scanner or driver.Valuer are converted by themselves, e.g: pq.StringArray
+	if field.Serializer != nil || !strings.HasSuffix(strings.TrimSpace(string(field.DataType)), "[]") ||
+		field.IndirectFieldType.Kind() != reflect.Slice || field.IndirectFieldType.Elem() == ByteReflectType ||
+		reflect.PointerTo(field.IndirectFieldType).Implements(scannerType) || field.IndirectFieldType.Implements(valuerType) {
+		return nil
+	}
+
+	elemType := field.IndirectFieldType.Elem()
+	for elemType.Kind() == reflect.Slice {
+		elemType = elemType.Elem()
+	}
+
+	if !isTextType(elemType) {
+		return fmt.Errorf("invalid array field %s: unsupported element type %v", field.Name, elemType)
+	}
+	field.Serializer = ArraySerializer{}
+	return nil
+}
+
+// isTextType reports whether the values of typ are scanned from and written as the text of the literals
+func isTextType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if reflect.PointerTo(typ).Implements(scannerType) {
+		return true
+	}
+
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return typ.ConvertibleTo(TimeReflectType)
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+func literalText(dbValue interface{}) (string, error) {
+	switch v := dbValue.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	return "", fmt.Errorf("unsupported literal %#v", dbValue)
+}
+
+// literalParser parses the array literals, e.g: {1,"a b",NULL,{2,3}}, and the composite literals, e.g: (1,"a b",)
+type literalParser struct {
+	text string
+	pos  int
+}
+
+// parseArrayLiteral parses the array literal, the elements are the strings, nil of NULL, or the nested elements
+func parseArrayLiteral(text string) ([]interface{}, error) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "[") {
+		// the decoration of the dimensions, e.g: [0:1]={1,2}
+		if idx := strings.Index(text, "="); idx > 0 {
+			text = text[idx+1:]
+		}
+	}
+
+	p := &literalParser{text: text}
+	elems, err := p.parseArray()
+	if err == nil && p.pos < len(p.text) {
+		err = p.errorf("unexpected %q", p.text[p.pos:])
+	}
+	return elems, err
+}
+
+// parseCompositeLiteral parses the composite literal, the attributes are the strings, or nil of the empty attributes
+func parseCompositeLiteral(text string) (attrs []interface{}, err error) {
+	p := &literalParser{text: strings.TrimSpace(text)}
+	if !p.consume('(') {
+		return nil, p.errorf("expected (")
+	}
+
+	for {
+		var attr interface{}
+		if attr, err = p.parseAttr(); err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, attr)
+
+		if p.consume(')') {
+			break
+		} else if !p.consume(',') {
+			return nil, p.errorf("expected , or )")
+		}
+	}
+
+	if p.pos < len(p.text) {
+		return nil, p.errorf("unexpected %q", p.text[p.pos:])
+	}
+	return attrs, nil
+}
+
+func (p *literalParser) parseArray() (elems []interface{}, err error) {
+	p.skipSpaces()
+	if !p.consume('{') {
+		return nil, p.errorf("expected {")
+	}
+
+	elems = []interface{}{}
+	if p.skipSpaces(); p.consume('}') {
+		return elems, nil
+	}
+
+	for {
+		var elem interface{}
+		if p.skipSpaces(); p.pos < len(p.text) && p.text[p.pos] == '{' {
+			elem, err = p.parseArray()
+		} else {
+			elem, err = p.parseArrayElem()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, elem)
+
+		if p.skipSpaces(); p.consume('}') {
+			return elems, nil
+		} else if !p.consume(',') {
+			return nil, p.errorf("expected , or }")
+		}
+	}
+}
+
+// parseArrayElem parses the element of arrays, the quoted elements are escaped by backslashes, the unquoted NULL is nil
+func (p *literalParser) parseArrayElem() (interface{}, error) {
+	if p.consume('"') {
+		var builder strings.Builder
+		for p.pos < len(p.text) {
+			switch c := p.text[p.pos]; c {
+			case '"':
+				p.pos++
+				return builder.String(), nil
+			case '\\':
+				if p.pos++; p.pos < len(p.text) {
+					builder.WriteByte(p.text[p.pos])
+				}
+			default:
+				builder.WriteByte(c)
+			}
+			p.pos++
+		}
+		return nil, p.errorf("unterminated quote")
+	}
+
+	start := p.pos
+	for p.pos < len(p.text) && strings.IndexByte(",}{\"", p.text[p.pos]) < 0 {
+		p.pos++
+	}
+
+	if text := strings.TrimSpace(p.text[start:p.pos]); text == "" {
+		return nil, p.errorf("empty element")
+	} else if strings.EqualFold(text, "NULL") {
+		return nil, nil
+	} else {
+		return text, nil
+	}
+}
+
+// parseAttr parses the attribute of composites, the quotes are escaped by doubling them or backslashes, the empty
+// unquoted attribute is nil
+func (p *literalParser) parseAttr() (interface{}, error) {
+	var (
+		builder strings.Builder
+		quoted  bool
+		null    = true
+	)
+
+	for ; p.pos < len(p.text); p.pos++ {
+		switch c := p.text[p.pos]; {
+		case c == '"':
+			if quoted && p.pos+1 < len(p.text) && p.text[p.pos+1] == '"' {
+				builder.WriteByte('"')
+				p.pos++
+			} else {
+				quoted = !quoted
+			}
+		case c == '\\':
+			if p.pos++; p.pos < len(p.text) {
+				builder.WriteByte(p.text[p.pos])
+			}
+		case !quoted && (c == ',' || c == ')'):
+			if null && builder.Len() == 0 {
+				return nil, nil
+			}
+			return builder.String(), nil
+		default:
+			builder.WriteByte(c)
+			continue
+		}
+		null = false
+	}
+
+	if quoted {
+		return nil, p.errorf("unterminated quote")
+	}
+	return nil, p.errorf("unexpected end")
+}
+
+func (p *literalParser) consume(c byte) bool {
+	if p.pos < len(p.text) && p.text[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *literalParser) skipSpaces() {
+	for p.pos < len(p.text) && strings.IndexByte(" \t\n\r", p.text[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *literalParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid literal %s at %d: %s", p.text, p.pos, fmt.Sprintf(format, args...))
+}
+
+// setArray sets the slice dst to the parsed elements
+func setArray(dst reflect.Value, elems []interface{}) error {
+	slice := reflect.MakeSlice(dst.Type(), len(elems), len(elems))
+	for idx, elem := range elems {
+		if err := setElem(slice.Index(idx), elem); err != nil {
+			return err
+		}
+	}
+	dst.Set(slice)
+	return nil
+}
+
+// setElem sets dst to the parsed element, nil sets dst to zero
+func setElem(dst reflect.Value, elem interface{}) error {
+	if elem == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	if dst.Kind() == reflect.Ptr {
+		value := reflect.New(dst.Type().Elem())
+		if err := setElem(value.Elem(), elem); err != nil {
+			return err
+		}
+		dst.Set(value)
+		return nil
+	}
+
+	switch v := elem.(type) {
+	case []interface{}:
+		switch {
+		case dst.Kind() == reflect.Slice:
+			return setArray(dst, v)
+		case dst.Kind() == reflect.Struct && !dst.Type().ConvertibleTo(TimeReflectType):
+			for i, attr := 0, 0; i < dst.NumField(); i++ {
+				if dst.Type().Field(i).IsExported() {
+					if attr >= len(v) {
+						return fmt.Errorf("%d attributes for %v", len(v), dst.Type())
+					} else if err := setElem(dst.Field(i), v[attr]); err != nil {
+						return err
+					}
+					attr++
+				}
+			}
+			return nil
+		}
+		return fmt.Errorf("unexpected array for %v", dst.Type())
+	case string:
+		if scanner, ok := dst.Addr().Interface().(sql.Scanner); ok {
+			return scanner.Scan(v)
+		}
+		return setText(dst, v)
+	}
+	return fmt.Errorf("unsupported element %#v", elem)
+}
+
+// setText parses text into dst by its kind
+func setText(dst reflect.Value, text string) (err error) {
+	switch kind := dst.Kind(); {
+	case dst.Type().ConvertibleTo(TimeReflectType):
+		var t time.Time
+		if t, err = parseTimeLiteral(text); err == nil {
+			dst.Set(reflect.ValueOf(t).Convert(dst.Type()))
+		}
+	case kind == reflect.String:
+		dst.SetString(text)
+	case kind == reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(text); err == nil {
+			dst.SetBool(b)
+		}
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(text, 10, 64); err == nil {
+			dst.SetInt(i)
+		}
+	case kind >= reflect.Uint && kind <= reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(text, 10, 64); err == nil {
+			dst.SetUint(u)
+		}
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(text, 64); err == nil {
+			dst.SetFloat(f)
+		}
+	case kind == reflect.Slice:
+		err = fmt.Errorf("unexpected element %q for %v", text, dst.Type())
+	default:
+		err = fmt.Errorf("unsupported type %v", dst.Type())
+	}
+	return err
+}
+
+// timeLiteralLayouts the layouts of the times and dates of the literals
+var timeLiteralLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02",
+}
+
+func parseTimeLiteral(text string) (t time.Time, err error) {
+	for _, layout := range timeLiteralLayouts {
+		if t, err = time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+	return t, err
+}
+
+// writeArray writes the array literal of the slice rv, the nested slices are written as the nested arrays
+func writeArray(builder *strings.Builder, rv reflect.Value) error {
+	builder.WriteByte('{')
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+
+		elem := rv.Index(i)
+		for elem.Kind() == reflect.Ptr && !elem.IsNil() {
+			elem = elem.Elem()
+		}
+
+		if elem.Kind() == reflect.Slice {
+			if err := writeArray(builder, elem); err != nil {
+				return err
+			}
+			continue
+		}
+
+		text, null, err := elemText(elem)
+		if err != nil {
+			return err
+		}
+
+		if null {
+			builder.WriteString("NULL")
+		} else if text == "" || strings.EqualFold(text, "NULL") || strings.ContainsAny(text, "{},\"\\ \t\n\r") {
+			writeQuoted(builder, text, `\"`)
+		} else {
+			builder.WriteString(text)
+		}
+	}
+	builder.WriteByte('}')
+	return nil
+}
+
+// writeComposite writes the composite literal of the struct rv, the NULL attributes are empty
+func writeComposite(builder *strings.Builder, rv reflect.Value) error {
+	builder.WriteByte('(')
+	for i, attr := 0, 0; i < rv.NumField(); i++ {
+		if !rv.Type().Field(i).IsExported() {
+			continue
+		}
+
+		if attr > 0 {
+			builder.WriteByte(',')
+		}
+		attr++
+
+		text, null, err := elemText(rv.Field(i))
+		if err != nil {
+			return err
+		} else if null {
+			continue
+		}
+
+		if text == "" || strings.ContainsAny(text, "(),\"\\ \t\n\r") {
+			writeQuoted(builder, text, `""`)
+		} else {
+			builder.WriteString(text)
+		}
+	}
+	builder.WriteByte(')')
+	return nil
+}
+
+// writeQuoted writes the quoted text, the quotes are escaped by quoteEscape, and the backslashes are escaped by
+// backslashes
+func writeQuoted(builder *strings.Builder, text string, quoteEscape string) {
+	builder.WriteByte('"')
+	for _, c := range text {
+		switch c {
+		case '"':
+			builder.WriteString(quoteEscape)
+		case '\\':
+			builder.WriteString(`\\`)
+		default:
+			builder.WriteRune(c)
+		}
+	}
+	builder.WriteByte('"')
+}
+
+// elemText returns the text of the element rv, null is true if it's nil
+func elemText(rv reflect.Value) (text string, null bool, err error) {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "", true, nil
+		}
+		rv = rv.Elem()
+	}
+
+	if valuer, ok := rv.Interface().(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil || value == nil {
+			return "", value == nil, err
+		}
+		rv = reflect.ValueOf(value)
+	}
+
+	switch kind := rv.Kind(); {
+	case rv.Type().ConvertibleTo(TimeReflectType):
+		text = rv.Convert(TimeReflectType).Interface().(time.Time).Format("2006-01-02 15:04:05.999999999Z07:00")
+	case kind == reflect.String:
+		text = rv.String()
+	case kind == reflect.Bool:
+		text = strconv.FormatBool(rv.Bool())
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		text = strconv.FormatInt(rv.Int(), 10)
+	case kind >= reflect.Uint && kind <= reflect.Uint64:
+		text = strconv.FormatUint(rv.Uint(), 10)
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		text = strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits())
+	case kind == reflect.Slice && rv.Type().Elem() == ByteReflectType:
+		text = string(rv.Bytes())
+	default:
+		err = fmt.Errorf("unsupported element type %v", rv.Type())
+	}
+	return text, false, err
+}
@@ -0,0 +1,180 @@
+package schema_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils/tests"
+)
+
+type PostgresPoint struct {
+	Lat  float64
+	Name string
+	Note *string
+}
+
+// PostgresLabels an array converted by itself like pq.StringArray
+type PostgresLabels []string
+
+func (l *PostgresLabels) Scan(value interface{}) error {
+	return nil
+}
+
+func (l PostgresLabels) Value() (driver.Value, error) {
+	return nil, nil
+}
+
+type PostgresTypes struct {
+	ID       uint
+	Tags     []string       `gorm:"type:text[]"`
+	Labels   PostgresLabels `gorm:"type:text[]"`
+	Scores   [][]int64      `gorm:"type:int[][]"`
+	Ranks    []*int         `gorm:"type:int[]"`
+	Data     []byte         `gorm:"type:bytea"`
+	Location PostgresPoint  `gorm:"composite:point_t"`
+	Origin   *PostgresPoint `gorm:"composite:point_t"`
+}
+
+func TestParsePostgresTypes(t *testing.T) {
+	s, err := schema.Parse(&PostgresTypes{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse, got error %v", err)
+	}
+
+	var names []string
+	for _, field := range s.PostgresTypeFields {
+		names = append(names, field.Name)
+	}
+	tests.AssertEqual(t, names, []string{"Tags", "Scores", "Ranks", "Location", "Origin"})
+
+	if field := s.LookUpField("Location"); field.DataType != "point_t" || field.Serializer != (schema.CompositeSerializer{}) {
+		t.Errorf("Location should be a composite field, got %v %T", field.DataType, field.Serializer)
+	}
+
+	if field := s.LookUpField("Tags"); field.DataType != "text[]" || field.Serializer != (schema.ArraySerializer{}) {
+		t.Errorf("Tags should be an array field, got %v %T", field.DataType, field.Serializer)
+	}
+
+	if field := s.LookUpField("Data"); field.Serializer != nil {
+		t.Errorf("Data shouldn't be an array field, got %T", field.Serializer)
+	}
+
+	if field := s.LookUpField("Labels"); field.Serializer != nil {
+		t.Errorf("Labels implementing sql.Scanner shouldn't be an array field, got %T", field.Serializer)
+	}
+
+	type InvalidComposite struct {
+		Location string `gorm:"composite:point_t"`
+	}
+
+	type InvalidArray struct {
+		Points []PostgresPoint `gorm:"type:point_t[]"`
+	}
+
+	for _, value := range []interface{}{&InvalidComposite{}, &InvalidArray{}} {
+		if _, err := schema.Parse(value, &sync.Map{}, schema.NamingStrategy{}); err == nil {
+			t.Errorf("%T should be invalid", value)
+		}
+	}
+}
+
+func TestPostgresArrayLiterals(t *testing.T) {
+	s, err := schema.Parse(&PostgresTypes{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse, got error %v", err)
+	}
+
+	ctx := context.Background()
+	two := 2
+	value := PostgresTypes{
+		Tags:   []string{"go", "", `a "b"`, `c\d`, "NULL", "x,y"},
+		Scores: [][]int64{{1, 2}, {3, 4}},
+		Ranks:  []*int{&two, nil},
+	}
+
+	for name, expected := range map[string]interface{}{
+		"Tags":   `{go,"","a \"b\"","c\\d","NULL","x,y"}`,
+		"Scores": "{{1,2},{3,4}}",
+		"Ranks":  "{2,NULL}",
+	} {
+		field := s.LookUpField(name)
+		fieldValue := field.ReflectValueOf(ctx, reflect.ValueOf(&value)).Interface()
+		if result, err := field.Serializer.Value(ctx, field, reflect.ValueOf(&value), fieldValue); err != nil || result != expected {
+			t.Errorf("%s should be written as %v, got %v, error %v", name, expected, result, err)
+		}
+
+		var scanned PostgresTypes
+		if err := field.Serializer.Scan(ctx, field, reflect.ValueOf(&scanned), expected); err != nil {
+			t.Errorf("failed to scan %s, got error %v", name, err)
+		}
+		tests.AssertEqual(t, field.ReflectValueOf(ctx, reflect.ValueOf(&scanned)).Interface(), fieldValue)
+	}
+
+	field := s.LookUpField("Scores")
+	if result, err := field.Serializer.Value(schema.WithDriverArrays(ctx), field, reflect.ValueOf(&value), value.Scores); err != nil || !reflect.DeepEqual(result, value.Scores) {
+		t.Errorf("the arrays should be bound as the slices, got %#v, error %v", result, err)
+	}
+
+	var scanned PostgresTypes
+	if err := field.Serializer.Scan(ctx, field, reflect.ValueOf(&scanned), []byte("[0:1][1:2]={ {5, 6} , {7,8} }")); err != nil {
+		t.Errorf("failed to scan the decorated array, got error %v", err)
+	}
+	tests.AssertEqual(t, scanned.Scores, [][]int64{{5, 6}, {7, 8}})
+
+	field = s.LookUpField("Ranks")
+	if err := field.Serializer.Scan(ctx, field, reflect.ValueOf(&scanned), "{1,null}"); err != nil || len(scanned.Ranks) != 2 || *scanned.Ranks[0] != 1 || scanned.Ranks[1] != nil {
+		t.Errorf("failed to scan the NULL elements, got %v, error %v", scanned.Ranks, err)
+	}
+
+	for _, literal := range []string{"{1,2", "{1,,2}", "1,2", `{"1}`, "{1,a}"} {
+		if err := field.Serializer.Scan(ctx, field, reflect.ValueOf(&scanned), literal); err == nil {
+			t.Errorf("%s should be invalid", literal)
+		}
+	}
+}
+
+func TestPostgresCompositeLiterals(t *testing.T) {
+	s, err := schema.Parse(&PostgresTypes{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse, got error %v", err)
+	}
+
+	var (
+		ctx   = context.Background()
+		note  = `say "hi"`
+		value = PostgresTypes{Location: PostgresPoint{Lat: 1.5, Name: "a b"}, Origin: &PostgresPoint{Name: "o", Note: &note}}
+	)
+
+	for name, expected := range map[string]interface{}{
+		"Location": `(1.5,"a b",)`,
+		"Origin":   `(0,o,"say ""hi""")`,
+	} {
+		field := s.LookUpField(name)
+		fieldValue := field.ReflectValueOf(ctx, reflect.ValueOf(&value)).Interface()
+		if result, err := field.Serializer.Value(ctx, field, reflect.ValueOf(&value), fieldValue); err != nil || result != expected {
+			t.Errorf("%s should be written as %v, got %v, error %v", name, expected, result, err)
+		}
+
+		var scanned PostgresTypes
+		if err := field.Serializer.Scan(ctx, field, reflect.ValueOf(&scanned), expected); err != nil {
+			t.Errorf("failed to scan %s, got error %v", name, err)
+		}
+		tests.AssertEqual(t, field.ReflectValueOf(ctx, reflect.ValueOf(&scanned)).Interface(), fieldValue)
+	}
+
+	field := s.LookUpField("Origin")
+	var scanned PostgresTypes
+	if err := field.Serializer.Scan(ctx, field, reflect.ValueOf(&scanned), nil); err != nil || scanned.Origin != nil {
+		t.Errorf("NULL should be scanned as nil, got %v, error %v", scanned.Origin, err)
+	}
+
+	for _, literal := range []string{"(1,a", "(1)", "1,a,b", "(x,a,b)"} {
+		if err := field.Serializer.Scan(ctx, field, reflect.ValueOf(&scanned), literal); err == nil {
+			t.Errorf("%s should be invalid", literal)
+		}
+	}
+}
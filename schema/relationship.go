@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 
@@ -62,6 +63,46 @@ type Reference struct {
 	OwnPrimaryKey bool
 }
 
+// ClosestNames returns the names of the relationships and the embedded structs closest to name by the edit distance,
+// at most 3 names sorted by the distance, e.g: Orders of Orderz, nil if none of them is similar
+func (rels *Relationships) ClosestNames(name string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	var (
+		candidates  []candidate
+		maxDistance = len(name)/3 + 1
+		names       = make([]string, 0, len(rels.Relations)+len(rels.EmbeddedRelations))
+	)
+	for relName := range rels.Relations {
+		names = append(names, relName)
+	}
+	for relName := range rels.EmbeddedRelations {
+		names = append(names, relName)
+	}
+
+	for _, relName := range names {
+		if distance := editDistance(strings.ToLower(name), strings.ToLower(relName)); distance <= maxDistance {
+			candidates = append(candidates, candidate{name: relName, distance: distance})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	var closest []string
+	for idx := 0; idx < len(candidates) && idx < 3; idx++ {
+		closest = append(closest, candidates[idx].name)
+	}
+	return closest
+}
+
 func (schema *Schema) parseRelation(field *Field) *Relationship {
 	var (
 		err        error
@@ -597,6 +638,11 @@ func (schema *Schema) guessRelation(relation *Relationship, field *Field, cgl gu
 		if foreignField.Size == 0 {
 			foreignField.Size = primaryFields[idx].Size
 		}
+		// the foreign keys of UUIDs are stored like them, so the binary UUIDs are comparable
+		if foreignField.UUIDStorage == "" && primaryFields[idx].UUIDStorage != "" {
+			foreignField.setUUIDStorage(primaryFields[idx].UUIDStorage)
+			foreignField.setupValuerAndSetter()
+		}
 
 		relation.References = append(relation.References, &Reference{
 			PrimaryKey:    primaryFields[idx],
@@ -6,6 +6,7 @@ import (
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils/tests"
 )
 
 func checkStructRelation(t *testing.T, data interface{}, relations ...Relation) {
@@ -996,3 +997,19 @@ func TestParseConstraintNameWithSchemaQualifiedLongTableName(t *testing.T) {
 		)
 	}
 }
+
+func TestRelationshipsClosestNames(t *testing.T) {
+	user, err := schema.Parse(&tests.User{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse user, got error %v", err)
+	}
+
+	for name, expected := range map[string][]string{
+		"Petz":    {"Pets"},
+		"tolls":   {"Tools", "Toys"},
+		"Compnay": {"Company"},
+		"Unknown": nil,
+	} {
+		tests.AssertEqual(t, user.Relationships.ClosestNames(name), expected)
+	}
+}
@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Retention the retention of the rows declared by the `retention` tag, the rows whose Field is older than Duration are
+// expired, which is the field of the tag or the column of `retentionColumn`, e.g:
+//
+//	type Event struct {
+//		ID        uint
+//		CreatedAt time.Time `gorm:"retention:2160h"` // kept for 90 days
+//	}
+type Retention struct {
+	Duration time.Duration
+	Field    *Field
+}
+
+// parseRetention returns the retention declared by the fields, the invalid columns are reported as the tag errors
+func (schema *Schema) parseRetention() *Retention {
+	for _, field := range schema.Fields {
+		value, ok := field.TagSettings["RETENTION"]
+		if !ok {
+			continue
+		}
+
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || duration <= 0 {
+			// the malformed durations are reported by validateTag
+			continue
+		}
+
+		retention := &Retention{Duration: duration, Field: field}
+		if column, ok := field.TagSettings["RETENTIONCOLUMN"]; ok {
+			if retention.Field = schema.LookUpField(strings.TrimSpace(column)); retention.Field == nil || retention.Field.DBName == "" {
+				schema.tagErrs = append(schema.tagErrs, fmt.Sprintf("field %s.%s: unknown retention column %q", schema.Name, field.Name, column))
+				continue
+			}
+		}
+		return retention
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"gorm.io/gorm/utils"
+)
+
+// maxScanPlans limits the number of scan plans cached per schema, queries selecting
+// dynamic column sets beyond it are still planned but not cached
+const maxScanPlans = 128
+
+type scanPlanCache struct {
+	mux   sync.RWMutex
+	plans map[string]*ScanPlan
+}
+
+// ScanPlan the compiled mapping from a result set's columns to schema fields
+type ScanPlan struct {
+	Schema     *Schema
+	Columns    []string
+	Fields     []*Field   // field of each column, nil if the column is not mapped
+	JoinFields [][]*Field // relation path of each column selected from joined tables, nil if none
+	Decoders   []*FieldDecoder
+	// Prefixed the columns resolved into the nested embedded structs by their prefixes, e.g: order__id, the NULL
+	// values don't allocate the nil pointers of the embedded structs, nil if none
+	Prefixed []bool
+	// Err ErrAmbiguousPrefix if the prefix of any column matches multiple fields
+	Err error
+}
+
+// ScanPlan returns the scan plan of columns, plans are cached on the schema
+func (schema *Schema) ScanPlan(columns []string) *ScanPlan {
+	cache := schema.scanPlans
+	if cache == nil {
+		return schema.compileScanPlan(columns)
+	}
+
+	key := strings.Join(columns, "\x00")
+	cache.mux.RLock()
+	plan, ok := cache.plans[key]
+	cache.mux.RUnlock()
+	if ok {
+		return plan
+	}
+
+	plan = schema.compileScanPlan(columns)
+	cache.mux.Lock()
+	if len(cache.plans) < maxScanPlans {
+		cache.plans[key] = plan
+	}
+	cache.mux.Unlock()
+	return plan
+}
+
+func (schema *Schema) compileScanPlan(columns []string) *ScanPlan {
+	plan := &ScanPlan{
+		Schema:   schema,
+		Columns:  append([]string(nil), columns...),
+		Fields:   make([]*Field, len(columns)),
+		Decoders: make([]*FieldDecoder, len(columns)),
+	}
+
+	matchedFieldCount := make(map[string]int, len(columns))
+	for idx, column := range columns {
+		if field := schema.LookUpField(column); field != nil && field.Readable {
+			plan.Fields[idx] = field
+			if count, ok := matchedFieldCount[column]; ok {
+				// handle duplicate fields
+				for _, selectField := range schema.Fields {
+					if selectField.DBName == column && selectField.Readable {
+						if count == 0 {
+							matchedFieldCount[column]++
+							plan.Fields[idx] = selectField
+							break
+						}
+						count--
+					}
+				}
+			} else {
+				matchedFieldCount[column] = 1
+			}
+			plan.Decoders[idx] = newFieldDecoder(schema, plan.Fields[idx])
+		} else if names := utils.SplitNestedRelationName(column); len(names) > 1 { // has nested relation
+			field, relFields, prefixed, err := schema.lookUpNestedField(names)
+			if err != nil {
+				if plan.Err == nil {
+					plan.Err = fmt.Errorf("%w: column %s, %v", ErrAmbiguousPrefix, column, err)
+				}
+				continue
+			}
+
+			if field == nil || !field.Readable {
+				continue
+			}
+
+			plan.Fields[idx] = field
+			if prefixed {
+				if len(plan.Prefixed) == 0 {
+					plan.Prefixed = make([]bool, len(columns))
+				}
+				plan.Prefixed[idx] = true
+			} else {
+				if len(plan.JoinFields) == 0 {
+					plan.JoinFields = make([][]*Field, len(columns))
+				}
+				plan.JoinFields[idx] = append(relFields, field)
+			}
+		}
+	}
+
+	return plan
+}
+
+// lookUpNestedField returns the field of the nested column split by its prefixes, e.g: []string{"Manager", "Company",
+// "name"}, the prefixes are the relationships, or the embedded struct of the first prefix, which are matched by the
+// names of the fields, their column names or the embedded prefixes, prefixed is true if the field is in the embedded
+// struct, returns an error listing the candidates if any prefix matches multiple fields
+func (schema *Schema) lookUpNestedField(names []string) (field *Field, relFields []*Field, prefixed bool, err error) {
+	var (
+		current = schema
+		dbName  = names[len(names)-1]
+	)
+
+	for idx, name := range names[:len(names)-1] {
+		rel, ok := current.Relationships.Relations[name]
+		if !ok {
+			var candidates []string
+			for relName, r := range current.Relationships.Relations {
+				if r.Schema == current && (strings.EqualFold(relName, name) || current.namer.ColumnName("", relName) == name) {
+					rel, candidates = r, append(candidates, relName)
+				}
+			}
+
+			// the embedded structs are only matched by the first prefix
+			var embedded map[string][]*Field
+			if idx == 0 && len(names) == 2 {
+				embedded = current.embeddedFieldsByPrefix(name)
+				for embeddedName := range embedded {
+					candidates = append(candidates, embeddedName)
+				}
+			}
+
+			if len(candidates) > 1 {
+				sort.Strings(candidates)
+				return nil, nil, false, fmt.Errorf("prefix %s matches %s", name, strings.Join(candidates, ", "))
+			}
+
+			for _, fields := range embedded {
+				for _, f := range fields {
+					if f.DBName == f.TagSettings["EMBEDDEDPREFIX"]+dbName || strings.EqualFold(f.Name, dbName) {
+						return f, nil, true, nil
+					}
+				}
+				return nil, nil, false, nil
+			}
+
+			if rel == nil {
+				return nil, nil, false, nil
+			}
+		}
+
+		relFields = append(relFields, rel.Field)
+		current = rel.FieldSchema
+	}
+
+	return current.LookUpField(dbName), relFields, false, nil
+}
+
+// embeddedFieldsByPrefix returns the fields of the embedded structs matched by the prefix, the key is the name of the
+// embedded struct
+func (schema *Schema) embeddedFieldsByPrefix(prefix string) map[string][]*Field {
+	results := map[string][]*Field{}
+	for _, field := range schema.Fields {
+		if len(field.EmbeddedBindNames) != 2 || field.DBName == "" {
+			continue
+		}
+
+		name := field.EmbeddedBindNames[0]
+		embeddedPrefix := strings.TrimRight(field.TagSettings["EMBEDDEDPREFIX"], "_")
+		if strings.EqualFold(name, prefix) || schema.namer.ColumnName("", name) == prefix ||
+			(embeddedPrefix != "" && strings.EqualFold(embeddedPrefix, prefix)) {
+			results[name] = append(results[name], field)
+		}
+	}
+	return results
+}
+
+// NewHolders returns the scan destinations of columns having a decoder, they can be reused between rows
+func (plan *ScanPlan) NewHolders() []interface{} {
+	var holders []interface{}
+	for idx, decoder := range plan.Decoders {
+		if decoder != nil {
+			if holders == nil {
+				holders = make([]interface{}, len(plan.Decoders))
+			}
+			holders[idx] = decoder.newHolder()
+		}
+	}
+	return holders
+}
+
+type decoderKind uint8
+
+const (
+	decodeInt64 decoderKind = iota + 1
+	decodeInt
+	decodeFloat64
+	decodeString
+	decodeBool
+	decodeTime
+)
+
+// FieldDecoder writes scanned values into a field at a fixed offset of the model struct,
+// avoiding reflection for fields of builtin types
+type FieldDecoder struct {
+	Field     *Field
+	modelType reflect.Type
+	offset    uintptr
+	kind      decoderKind
+}
+
+// newFieldDecoder returns nil if field's value can't be written through its offset, e.g. it has a serializer,
+// a custom type, or is inside a pointer embedded struct, or is tagged with `redact`, which is redacted before set
+func newFieldDecoder(schema *Schema, field *Field) *FieldDecoder {
+	if _, redact := field.TagSettings["REDACT"]; redact || field.Serializer != nil || field.Schema != schema || len(field.StructField.Index) == 0 {
+		return nil
+	}
+
+	var kind decoderKind
+	switch field.FieldType {
+	case reflect.TypeOf(int64(0)):
+		kind = decodeInt64
+	case reflect.TypeOf(0):
+		kind = decodeInt
+	case reflect.TypeOf(float64(0)):
+		kind = decodeFloat64
+	case reflect.TypeOf(""):
+		kind = decodeString
+	case reflect.TypeOf(false):
+		kind = decodeBool
+	case TimeReflectType:
+		kind = decodeTime
+	default:
+		return nil
+	}
+
+	var (
+		offset    uintptr
+		fieldType = schema.ModelType
+	)
+	for _, idx := range field.StructField.Index {
+		if idx < 0 || fieldType.Kind() != reflect.Struct {
+			return nil
+		}
+		structField := fieldType.Field(idx)
+		offset += structField.Offset
+		fieldType = structField.Type
+	}
+
+	if fieldType != field.FieldType {
+		return nil
+	}
+
+	return &FieldDecoder{Field: field, modelType: schema.ModelType, offset: offset, kind: kind}
+}
+
+func (decoder *FieldDecoder) newHolder() interface{} {
+	switch decoder.kind {
+	case decodeInt64, decodeInt:
+		return &sql.NullInt64{}
+	case decodeFloat64:
+		return &sql.NullFloat64{}
+	case decodeString:
+		return &sql.NullString{}
+	case decodeBool:
+		return &sql.NullBool{}
+	default:
+		return new(*time.Time)
+	}
+}
+
+// Decode sets the value scanned into holder to the field of value, NULL values leave the field unchanged
+func (decoder *FieldDecoder) Decode(ctx context.Context, value reflect.Value, holder interface{}) error {
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Type() != decoder.modelType || !value.CanAddr() {
+		return decoder.set(ctx, value, holder)
+	}
+
+	ptr := unsafe.Add(unsafe.Pointer(value.UnsafeAddr()), decoder.offset)
+	switch decoder.kind {
+	case decodeInt64:
+		if h := holder.(*sql.NullInt64); h.Valid {
+			*(*int64)(ptr) = h.Int64
+		}
+	case decodeInt:
+		if h := holder.(*sql.NullInt64); h.Valid {
+			*(*int)(ptr) = int(h.Int64)
+		}
+	case decodeFloat64:
+		if h := holder.(*sql.NullFloat64); h.Valid {
+			*(*float64)(ptr) = h.Float64
+		}
+	case decodeString:
+		if h := holder.(*sql.NullString); h.Valid {
+			*(*string)(ptr) = h.String
+		}
+	case decodeBool:
+		if h := holder.(*sql.NullBool); h.Valid {
+			*(*bool)(ptr) = h.Bool
+		}
+	case decodeTime:
+		if h := holder.(**time.Time); *h != nil {
+			*(*time.Time)(ptr) = **h
+		}
+	}
+	return nil
+}
+
+// set falls back to the field's reflection based setter
+func (decoder *FieldDecoder) set(ctx context.Context, value reflect.Value, holder interface{}) error {
+	var v interface{}
+	switch h := holder.(type) {
+	case *sql.NullInt64:
+		if h.Valid {
+			v = h.Int64
+		}
+	case *sql.NullFloat64:
+		if h.Valid {
+			v = h.Float64
+		}
+	case *sql.NullString:
+		if h.Valid {
+			v = h.String
+		}
+	case *sql.NullBool:
+		if h.Valid {
+			v = h.Bool
+		}
+	case **time.Time:
+		if *h != nil {
+			v = **h
+		}
+	}
+
+	if v == nil {
+		return nil
+	}
+	return decoder.Field.Set(ctx, value, v)
+}
@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
@@ -16,20 +17,28 @@ import (
 type callbackType string
 
 const (
-	callbackTypeBeforeCreate callbackType = "BeforeCreate"
-	callbackTypeBeforeUpdate callbackType = "BeforeUpdate"
-	callbackTypeAfterCreate  callbackType = "AfterCreate"
-	callbackTypeAfterUpdate  callbackType = "AfterUpdate"
-	callbackTypeBeforeSave   callbackType = "BeforeSave"
-	callbackTypeAfterSave    callbackType = "AfterSave"
-	callbackTypeBeforeDelete callbackType = "BeforeDelete"
-	callbackTypeAfterDelete  callbackType = "AfterDelete"
-	callbackTypeAfterFind    callbackType = "AfterFind"
+	callbackTypeBeforeCreate  callbackType = "BeforeCreate"
+	callbackTypeBeforeUpdate  callbackType = "BeforeUpdate"
+	callbackTypeAfterCreate   callbackType = "AfterCreate"
+	callbackTypeAfterUpdate   callbackType = "AfterUpdate"
+	callbackTypeBeforeSave    callbackType = "BeforeSave"
+	callbackTypeAfterSave     callbackType = "AfterSave"
+	callbackTypeBeforeDelete  callbackType = "BeforeDelete"
+	callbackTypeAfterDelete   callbackType = "AfterDelete"
+	callbackTypeAfterFind     callbackType = "AfterFind"
+	callbackTypeAfterCommit   callbackType = "AfterCommit"
+	callbackTypeAfterRollback callbackType = "AfterRollback"
 )
 
 // ErrUnsupportedDataType unsupported data type
 var ErrUnsupportedDataType = errors.New("unsupported data type")
 
+// ErrInvalidTag invalid gorm tag, e.g: unknown key, malformed value
+var ErrInvalidTag = errors.New("invalid tag")
+
+// ErrAmbiguousPrefix the prefix of the nested column matches multiple fields
+var ErrAmbiguousPrefix = errors.New("ambiguous prefix of nested column")
+
 type Schema struct {
 	Name                      string
 	ModelType                 reflect.Type
@@ -48,15 +57,31 @@ type Schema struct {
 	QueryClauses              []clause.Interface
 	UpdateClauses             []clause.Interface
 	DeleteClauses             []clause.Interface
+	SoftDelete                SoftDeleteStrategy
+	SoftDeleteField           *Field
+	SoftDeleteByField         *Field             // the field tagged with `softDeleteBy` or `autoDeletedBy`, see Config.SoftDeleteByContextKey
+	TenantField               *Field             // the field tagged with `tenant`, see Config.TenantResolver
+	OnConflict                *clause.OnConflict // declared by OnConflictClauseInterface or the unique index, see Config.UseModelConflictClause
+	OnConflictImplemented     bool               // OnConflict is declared by OnConflictClauseInterface, which is always used
+	PostgresTypeFields        []*Field           // the array, composite and interval fields, which are only supported by postgres
+	DefaultSelect             []string
+	DefaultCreateBatchSize    int
+	DefaultClauses            []clause.Expression
+	Retention                 *Retention
+	Dynamic                   bool // parsed from DynamicSchema, the values of its statements are the maps
 	BeforeCreate, AfterCreate bool
 	BeforeUpdate, AfterUpdate bool
 	BeforeDelete, AfterDelete bool
 	BeforeSave, AfterSave     bool
 	AfterFind                 bool
+	AfterCommit               bool
+	AfterRollback             bool
 	err                       error
+	tagErrs                   []string
 	initialized               chan struct{}
 	namer                     Namer
 	cacheStore                *sync.Map
+	scanPlans                 *scanPlanCache
 }
 
 func (schema Schema) String() string {
@@ -113,6 +138,14 @@ type TablerWithNamer interface {
 	TableName(Namer) string
 }
 
+// parsedCount the count of parsed schemas, excluding the ones loaded from cache
+var parsedCount int64
+
+// ParsedCount returns how many schemas have been parsed, excluding the ones loaded from cache
+func ParsedCount() int64 {
+	return atomic.LoadInt64(&parsedCount)
+}
+
 // Parse get data type from dialector
 func Parse(dest interface{}, cacheStore *sync.Map, namer Namer) (*Schema, error) {
 	return ParseWithSpecialTableName(dest, cacheStore, namer, "")
@@ -188,6 +221,7 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 		cacheStore:       cacheStore,
 		namer:            namer,
 		initialized:      make(chan struct{}),
+		scanPlans:        &scanPlanCache{plans: map[string]*ScanPlan{}},
 	}
 	// When the schema initialization is completed, the channel will be closed
 	defer close(schema.initialized)
@@ -199,10 +233,13 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 		<-s.initialized
 		return s, s.err
 	}
+	atomic.AddInt64(&parsedCount, 1)
 
 	for i := 0; i < modelType.NumField(); i++ {
 		if fieldStruct := modelType.Field(i); ast.IsExported(fieldStruct.Name) {
+			schema.tagErrs = append(schema.tagErrs, validateTag(schema, fieldStruct)...)
 			if field := schema.ParseField(fieldStruct); field.EmbeddedSchema != nil {
+				schema.tagErrs = append(schema.tagErrs, field.EmbeddedSchema.tagErrs...)
 				schema.Fields = append(schema.Fields, field.EmbeddedSchema.Fields...)
 			} else {
 				schema.Fields = append(schema.Fields, field)
@@ -309,6 +346,7 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 		callbackTypeBeforeSave, callbackTypeAfterSave,
 		callbackTypeBeforeDelete, callbackTypeAfterDelete,
 		callbackTypeAfterFind,
+		callbackTypeAfterCommit, callbackTypeAfterRollback,
 	}
 	for _, cbName := range callbackTypes {
 		if methodValue := callBackToMethodValue(modelValue, cbName); methodValue.IsValid() {
@@ -321,6 +359,19 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 		}
 	}
 
+	if _, embedded := cacheStore.Load(embeddedCacheKey); !embedded {
+		if clauser, ok := modelValue.Interface().(OnConflictClauseInterface); ok {
+			onConflict := clauser.OnConflictClause()
+			schema.OnConflict, schema.OnConflictImplemented = &onConflict, true
+		} else {
+			schema.OnConflict = schema.parseOnConflict()
+		}
+
+		schema.parseModelDefaults(modelValue.Interface())
+		schema.Retention = schema.parseRetention()
+		schema.warnTagErrors()
+	}
+
 	// Cache the schema
 	if v, loaded := cacheStore.LoadOrStore(schemaCacheKey, schema); loaded {
 		s := v.(*Schema)
@@ -332,7 +383,7 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 	defer func() {
 		if schema.err != nil {
 			logger.Default.Error(context.Background(), schema.err.Error())
-			cacheStore.Delete(modelType)
+			cacheStore.Delete(schemaCacheKey)
 		}
 	}()
 
@@ -349,6 +400,24 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 
 			fieldValue := reflect.New(field.IndirectFieldType)
 			fieldInterface := fieldValue.Interface()
+			if name, ok := field.TagSettings["SOFTDELETE"]; ok {
+				if softDelete, ok := GetSoftDelete(name); ok {
+					fieldInterface = softDelete
+				}
+			}
+
+			if _, ok := field.TagSettings["SOFTDELETEBY"]; (ok || field.AutoDeletedBy) && field.DBName != "" {
+				field.Schema.SoftDeleteByField = field
+			}
+
+			if _, ok := field.TagSettings["TENANT"]; ok && field.DBName != "" {
+				field.Schema.TenantField = field
+			}
+
+			if field.IsPostgresType() && field.DBName != "" {
+				field.Schema.PostgresTypeFields = append(field.Schema.PostgresTypeFields, field)
+			}
+
 			if fc, ok := fieldInterface.(CreateClausesInterface); ok {
 				field.Schema.CreateClauses = append(field.Schema.CreateClauses, fc.CreateClauses(field)...)
 			}
@@ -364,6 +433,10 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 			if fc, ok := fieldInterface.(DeleteClausesInterface); ok {
 				field.Schema.DeleteClauses = append(field.Schema.DeleteClauses, fc.DeleteClauses(field)...)
 			}
+
+			if strategy, ok := fieldInterface.(SoftDeleteStrategy); ok && field.DBName != "" {
+				field.Schema.SoftDelete, field.Schema.SoftDeleteField = strategy, field
+			}
 		}
 	}
 
@@ -398,6 +471,10 @@ func callBackToMethodValue(modelType reflect.Value, cbType callbackType) reflect
 		return modelType.MethodByName(string(callbackTypeAfterDelete))
 	case callbackTypeAfterFind:
 		return modelType.MethodByName(string(callbackTypeAfterFind))
+	case callbackTypeAfterCommit:
+		return modelType.MethodByName(string(callbackTypeAfterCommit))
+	case callbackTypeAfterRollback:
+		return modelType.MethodByName(string(callbackTypeAfterRollback))
 	default:
 		return reflect.ValueOf(nil)
 	}
@@ -0,0 +1,261 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+type tagValueValidator func(value string) bool
+
+var (
+	validateFlag tagValueValidator = func(value string) bool {
+		_, err := strconv.ParseBool(value)
+		return err == nil || strings.EqualFold(value, "yes") || strings.EqualFold(value, "no")
+	}
+	validateInt tagValueValidator = func(value string) bool {
+		_, err := strconv.Atoi(strings.TrimSpace(value))
+		return err == nil
+	}
+	validateAutoTime tagValueValidator = func(value string) bool {
+		return validateFlag(value) || strings.EqualFold(value, "nano") || strings.EqualFold(value, "milli")
+	}
+	validateDuration tagValueValidator = func(value string) bool {
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		return err == nil && duration > 0
+	}
+	validateOneOf = func(values ...string) tagValueValidator {
+		return func(value string) bool {
+			for _, v := range strings.Split(value, ",") {
+				if !utils.Contains(values, strings.ToLower(strings.TrimSpace(v))) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+)
+
+// tagKey the known key of tags, validate checks its value if it isn't a flag
+type tagKey struct {
+	Name     string
+	validate tagValueValidator
+}
+
+func tagKeys(keys ...tagKey) map[string]tagKey {
+	results := make(map[string]tagKey, len(keys))
+	for _, key := range keys {
+		results[strings.ToUpper(key.Name)] = key
+	}
+	return results
+}
+
+var (
+	// fieldTagKeys the known keys of field tags, including the settings of relationships
+	fieldTagKeys = tagKeys(
+		tagKey{Name: "column"}, tagKey{Name: "type"}, tagKey{Name: "serializer"}, tagKey{Name: "json"},
+		tagKey{Name: "size", validate: validateInt}, tagKey{Name: "precision", validate: validateInt},
+		tagKey{Name: "scale", validate: validateInt},
+		tagKey{Name: "primaryKey", validate: validateFlag}, tagKey{Name: "primary_key", validate: validateFlag},
+		tagKey{Name: "unique", validate: validateFlag}, tagKey{Name: "default"},
+		tagKey{Name: "not null", validate: validateFlag}, tagKey{Name: "notNull", validate: validateFlag},
+		tagKey{Name: "comment"}, tagKey{Name: "check"}, tagKey{Name: "index"}, tagKey{Name: "uniqueIndex"},
+		tagKey{Name: "autoIncrement", validate: validateFlag}, tagKey{Name: "autoIncrementIncrement", validate: validateInt},
+		tagKey{Name: "autoCreateTime", validate: validateAutoTime}, tagKey{Name: "autoUpdateTime", validate: validateAutoTime},
+		tagKey{Name: "autoCreatedBy", validate: validateFlag}, tagKey{Name: "autoUpdatedBy", validate: validateFlag},
+		tagKey{Name: "autoDeletedBy", validate: validateFlag}, tagKey{Name: "skipDiff", validate: validateFlag},
+		tagKey{Name: "queryCollate"}, tagKey{Name: "uuidStorage", validate: validateOneOf("string", "binary")},
+		tagKey{Name: "embedded"}, tagKey{Name: "embeddedPrefix"},
+		tagKey{Name: "softDelete"}, tagKey{Name: "softDeleteBy"}, tagKey{Name: "zeroValue"}, tagKey{Name: "tenant"},
+		tagKey{Name: "composite"}, tagKey{Name: "durationUnit", validate: validateOneOf("ns", "us", "ms", "s", "m", "h")},
+		tagKey{Name: "retention", validate: validateDuration}, tagKey{Name: "retentionColumn"}, tagKey{Name: "redact"},
+		tagKey{Name: "-", validate: validateOneOf("-", "all", "migration")},
+		tagKey{Name: "->", validate: validateOneOf("false", "true")},
+		tagKey{Name: "<-", validate: validateOneOf("create", "update", "false")},
+		tagKey{Name: "foreignKey"}, tagKey{Name: "references"}, tagKey{Name: "joinForeignKey"},
+		tagKey{Name: "joinReferences"}, tagKey{Name: "many2many"}, tagKey{Name: "belongsTo"},
+		tagKey{Name: "polymorphic"}, tagKey{Name: "polymorphicType"}, tagKey{Name: "polymorphicId"},
+		tagKey{Name: "polymorphicValue"}, tagKey{Name: "constraint"},
+		tagKey{Name: "emulateForeignKey", validate: validateFlag},
+	)
+
+	// indexTagKeys the known options of `index` and `uniqueIndex`
+	indexTagKeys = tagKeys(
+		tagKey{Name: "class"}, tagKey{Name: "type"}, tagKey{Name: "where"}, tagKey{Name: "comment"},
+		tagKey{Name: "option"}, tagKey{Name: "expression"}, tagKey{Name: "sort"}, tagKey{Name: "collate"},
+		tagKey{Name: "length", validate: validateInt}, tagKey{Name: "priority", validate: validateInt},
+		tagKey{Name: "unique"}, tagKey{Name: "composite"},
+		tagKey{Name: "onConflict", validate: validateOneOf("update", "nothing")},
+	)
+
+	// constraintTagKeys the known options of `constraint`
+	constraintTagKeys = tagKeys(tagKey{Name: "OnUpdate"}, tagKey{Name: "OnDelete"})
+)
+
+// splitTag splits the tag by sep like ParseTagSetting, returns the keys as written and the values, the value is empty
+// if it isn't set
+func splitTag(str string, sep string) (keys []string, values []string, hasValues []bool) {
+	names := strings.Split(str, sep)
+	for i := 0; i < len(names); i++ {
+		j := i
+		for len(names[j]) > 0 && names[j][len(names[j])-1] == '\\' && i+1 < len(names) {
+			i++
+			names[j] = names[j][0:len(names[j])-1] + sep + names[i]
+			names[i] = ""
+		}
+
+		kv := strings.SplitN(names[j], ":", 2)
+		if key := strings.TrimSpace(kv[0]); key != "" {
+			keys = append(keys, key)
+			if len(kv) == 2 {
+				values, hasValues = append(values, kv[1]), append(hasValues, true)
+			} else {
+				values, hasValues = append(values, ""), append(hasValues, false)
+			}
+		}
+	}
+	return
+}
+
+// validateTagSettings validates the keys and values of the tag settings, which are reported as `field User.Name: ...`
+func validateTagSettings(field string, str string, sep string, known map[string]tagKey) (errs []string) {
+	keys, values, hasValues := splitTag(str, sep)
+	for idx, key := range keys {
+		tagKey, ok := known[strings.ToUpper(key)]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("field %s: unknown key %q, did you mean %q?", field, key, closestTagKey(key, known)))
+			continue
+		}
+
+		if hasValues[idx] && tagKey.validate != nil && !tagKey.validate(values[idx]) {
+			errs = append(errs, fmt.Sprintf("field %s: malformed value %q of key %q", field, values[idx], key))
+		}
+	}
+	return errs
+}
+
+// validateTag validates the gorm tag of the struct field
+func validateTag(schema *Schema, fieldStruct reflect.StructField) (errs []string) {
+	tag, ok := fieldStruct.Tag.Lookup("gorm")
+	if !ok {
+		return nil
+	}
+
+	field := schema.Name + "." + fieldStruct.Name
+	errs = validateTagSettings(field, tag, ";", fieldTagKeys)
+
+	keys, values, _ := splitTag(tag, ";")
+	for idx, key := range keys {
+		switch strings.ToUpper(key) {
+		case "INDEX", "UNIQUEINDEX":
+			// the first option is the name of the index
+			if options := strings.SplitN(values[idx], ",", 2); len(options) == 2 {
+				errs = append(errs, validateTagSettings(field, options[1], ",", indexTagKeys)...)
+			}
+		case "CONSTRAINT":
+			options := values[idx]
+			if name := strings.SplitN(options, ",", 2); !strings.Contains(name[0], ":") {
+				// the first option is the name of the constraint if it isn't a setting
+				options = ""
+				if len(name) == 2 {
+					options = name[1]
+				}
+			}
+			errs = append(errs, validateTagSettings(field, options, ",", constraintTagKeys)...)
+		}
+	}
+	return errs
+}
+
+// closestTagKey returns the known key closest to the key by the edit distance
+func closestTagKey(key string, known map[string]tagKey) (closest string) {
+	minDistance := -1
+	for name, tagKey := range known {
+		if distance := editDistance(strings.ToUpper(key), name); minDistance == -1 || distance < minDistance ||
+			(distance == minDistance && tagKey.Name < closest) {
+			minDistance, closest = distance, tagKey.Name
+		}
+	}
+	return closest
+}
+
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// TagError returns the error of the invalid gorm tags of the schema, the unknown keys and the malformed values, which
+// are logged as warnings when parsed, and returned by the statements if gorm.Config.StrictTags is true
+func (schema *Schema) TagError() error {
+	if len(schema.tagErrs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidTag, strings.Join(schema.tagErrs, "; "))
+}
+
+// warnTagErrors logs the invalid gorm tags of the parsed schema
+func (schema *Schema) warnTagErrors() {
+	if err := schema.TagError(); err != nil {
+		logger.Default.Warn(context.Background(), "%v", err)
+	}
+}
+
+// ValidateModel validates the gorm tags of the model and its relationships without opening a database, returns
+// ErrInvalidTag if there are unknown keys or malformed values, e.g: checks all the models in CI
+//
+//	if err := schema.ValidateModel(&User{}); err != nil {
+//		t.Error(err)
+//	}
+func ValidateModel(model interface{}) error {
+	cacheStore := &sync.Map{}
+	if _, err := Parse(model, cacheStore, NamingStrategy{}); err != nil {
+		return err
+	}
+
+	// the schemas of the relationships are parsed into the same cache store
+	var errs []string
+	cacheStore.Range(func(key, value interface{}) bool {
+		if s, ok := value.(*Schema); ok {
+			errs = append(errs, s.tagErrs...)
+		}
+		return true
+	})
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	sort.Strings(errs)
+	return fmt.Errorf("%w: %s", ErrInvalidTag, strings.Join(errs, "; "))
+}
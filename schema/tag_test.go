@@ -0,0 +1,65 @@
+package schema_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils/tests"
+)
+
+type TagTypoAccount struct {
+	ID        uint
+	UserID    uint
+	Name      string `gorm:"collumn:name;size:64"`
+	Email     string `gorm:"index:idx_email,uniqe;size:abc"`
+	CreatedAt int64  `gorm:"autoCreateTime:nanoo"`
+}
+
+type TagTypoUser struct {
+	ID       uint
+	Name     string           `gorm:"column:name;type:varchar(100);not null;default:'a\\;b'"`
+	Accounts []TagTypoAccount `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelet:SET NULL"`
+}
+
+func TestValidateModel(t *testing.T) {
+	if err := schema.ValidateModel(&tests.User{}); err != nil {
+		t.Errorf("valid tags shouldn't return error, got %v", err)
+	}
+
+	if err := schema.ValidateModel(&UserIndex{}); err != nil {
+		t.Errorf("valid index tags shouldn't return error, got %v", err)
+	}
+
+	err := schema.ValidateModel(&TagTypoUser{})
+	if !errors.Is(err, schema.ErrInvalidTag) {
+		t.Fatalf("should return ErrInvalidTag, got %v", err)
+	}
+
+	for _, msg := range []string{
+		`field TagTypoAccount.Name: unknown key "collumn", did you mean "column"?`,
+		`field TagTypoAccount.Email: unknown key "uniqe", did you mean "unique"?`,
+		`field TagTypoAccount.Email: malformed value "abc" of key "size"`,
+		`field TagTypoAccount.CreatedAt: malformed value "nanoo" of key "autoCreateTime"`,
+		`field TagTypoUser.Accounts: unknown key "OnDelet", did you mean "OnDelete"?`,
+	} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Errorf("error should contain %v, got %v", msg, err)
+		}
+	}
+
+	if strings.Contains(err.Error(), "TagTypoUser.Name") {
+		t.Errorf("valid tags shouldn't be reported, got %v", err)
+	}
+
+	s, err := schema.Parse(&TagTypoAccount{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("invalid tags shouldn't fail to parse, got %v", err)
+	}
+
+	if err := s.TagError(); !errors.Is(err, schema.ErrInvalidTag) {
+		t.Errorf("should return ErrInvalidTag, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package schema
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// UUIDStorage how the UUID fields are stored, tagged with `uuidStorage`
+type UUIDStorage string
+
+const (
+	// UUIDString stores the UUIDs in the canonical string form, e.g: 0190f3b4-5a2e-7c1d-8f3a-4b5c6d7e8f90
+	UUIDString UUIDStorage = "string"
+	// UUIDBinary stores the UUIDs in 16 bytes with UUIDBinarySerializer, whose string forms are converted
+	UUIDBinary UUIDStorage = "binary"
+)
+
+// FormatUUID formats the UUID in the canonical string form
+func FormatUUID(id [16]byte) string {
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], id[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], id[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], id[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], id[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], id[10:])
+	return string(buf)
+}
+
+// ParseUUID parses the UUID in the canonical string form
+func ParseUUID(s string) (id [16]byte, err error) {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return id, fmt.Errorf("invalid UUID %q", s)
+	}
+
+	for i, j := 0, 0; i < 36; i += 2 {
+		if i == 8 || i == 13 || i == 18 || i == 23 {
+			i++
+		}
+		if _, err = hex.Decode(id[j:j+1], []byte(s[i:i+2])); err != nil {
+			return id, fmt.Errorf("invalid UUID %q", s)
+		}
+		j++
+	}
+	return id, nil
+}
+
+// IsUUID reports whether s is a UUID in the canonical string form
+func IsUUID(s string) bool {
+	_, err := ParseUUID(s)
+	return err == nil
+}
+
+// setUUIDStorage stores the field as UUIDs with storage, the binary UUIDs are serialized by UUIDBinarySerializer
+func (field *Field) setUUIDStorage(storage UUIDStorage) {
+	field.UUIDStorage = storage
+	if storage == UUIDBinary {
+		field.Serializer = UUIDBinarySerializer{}
+		field.DataType = Bytes
+		field.Size = 16
+	}
+}
+
+// UUIDBinarySerializer serializes the UUIDs of string, [16]byte or []byte fields to 16 bytes, used by the fields
+// tagged with `uuidStorage:binary`
+type UUIDBinarySerializer struct{}
+
+// Scan implements serializer interface
+func (UUIDBinarySerializer) Scan(ctx context.Context, field *Field, dst reflect.Value, dbValue interface{}) (err error) {
+	var id [16]byte
+	switch v := dbValue.(type) {
+	case nil:
+		field.ReflectValueOf(ctx, dst).Set(reflect.Zero(field.FieldType))
+		return nil
+	case []byte:
+		if len(v) == 16 {
+			copy(id[:], v)
+		} else {
+			id, err = ParseUUID(string(v))
+		}
+	case string:
+		id, err = ParseUUID(v)
+	default:
+		err = fmt.Errorf("invalid UUID %#v", dbValue)
+	}
+	if err != nil {
+		return err
+	}
+
+	var value interface{} = FormatUUID(id)
+	switch field.IndirectFieldType.Kind() {
+	case reflect.Array:
+		if reflect.TypeOf(id).ConvertibleTo(field.IndirectFieldType) {
+			value = reflect.ValueOf(id).Convert(field.IndirectFieldType).Interface()
+		}
+	case reflect.Slice:
+		value = id[:]
+	}
+	return field.Set(ctx, dst, value)
+}
+
+// Value implements serializer interface
+func (UUIDBinarySerializer) Value(ctx context.Context, field *Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	rv := reflect.Indirect(reflect.ValueOf(fieldValue))
+	switch {
+	case !rv.IsValid():
+		return nil, nil
+	case rv.Kind() == reflect.String:
+		if rv.Len() == 0 {
+			return nil, nil
+		}
+		id, err := ParseUUID(rv.String())
+		return id[:], err
+	case rv.Kind() == reflect.Array && rv.Len() == 16 && rv.Type().Elem().Kind() == reflect.Uint8:
+		var id [16]byte
+		reflect.Copy(reflect.ValueOf(id[:]), rv)
+		return id[:], nil
+	case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+		if rv.Len() == 0 {
+			return nil, nil
+		} else if rv.Len() == 16 {
+			return rv.Bytes(), nil
+		}
+	}
+	return nil, fmt.Errorf("invalid UUID %#v", fieldValue)
+}
@@ -0,0 +1,48 @@
+package schema_test
+
+import (
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+func TestParseUUID(t *testing.T) {
+	id, err := schema.ParseUUID("0190F3B4-5a2e-7c1d-8f3a-4b5c6d7e8f90")
+	if err != nil {
+		t.Fatalf("failed to parse UUID, got %v", err)
+	}
+
+	if s := schema.FormatUUID(id); s != "0190f3b4-5a2e-7c1d-8f3a-4b5c6d7e8f90" {
+		t.Errorf("failed to format UUID, got %v", s)
+	}
+
+	for _, s := range []string{"", "0190f3b45a2e7c1d8f3a4b5c6d7e8f90", "0190f3b4-5a2e-7c1d-8f3a-4b5c6d7e8f9z", "0190f3b4_5a2e-7c1d-8f3a-4b5c6d7e8f90"} {
+		if schema.IsUUID(s) {
+			t.Errorf("%q shouldn't be a UUID", s)
+		}
+	}
+}
+
+func TestParseUUIDFields(t *testing.T) {
+	type UUIDUser struct {
+		ID        string   `gorm:"primaryKey;default:uuid"`
+		BinaryID  [16]byte `gorm:"uuidStorage:binary"`
+		CompanyID string   `gorm:"uuidStorage:binary"`
+	}
+
+	user, err := schema.Parse(&UUIDUser{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("failed to parse user, got error %v", err)
+	}
+
+	if field := user.LookUpField("ID"); !field.AutoUUID || field.HasDefaultValue || field.UUIDStorage != schema.UUIDString || field.Serializer != nil {
+		t.Errorf("the ID should be generated UUID, got %+v", field)
+	}
+
+	for _, name := range []string{"BinaryID", "CompanyID"} {
+		if field := user.LookUpField(name); field.AutoUUID || field.UUIDStorage != schema.UUIDBinary || field.DataType != schema.Bytes || field.Size != 16 {
+			t.Errorf("the %v should be binary UUID, got %+v", name, field)
+		}
+	}
+}
@@ -0,0 +1,454 @@
+package gorm
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"time"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Sharder decides the shard tables of the sharded models, which is consulted once the conditions and the destination
+// of the statement are set, before building the SQL, so the shard could be derived from the primary conditions or the
+// fields of the destination, see Statement.ShardKey. The statements of preloads and associations consult it too, e.g:
+//
+//	func (userSharder) ShardName(stmt *gorm.Statement) (string, bool) {
+//		if stmt.Schema.Table != "users" {
+//			return "", true
+//		}
+//
+//		if userID, ok := stmt.ShardKey("UserID"); ok {
+//			return fmt.Sprintf("users_%02d", hash(userID)%32), true
+//		}
+//		return "", false
+//	}
+type Sharder interface {
+	// ShardName returns the table of the shard, or empty name if the model isn't sharded, returns false if the shard
+	// can't be determined by the statement
+	ShardName(stmt *Statement) (string, bool)
+}
+
+// ShardLister the Sharder listing all the shards of the sharded models, the queries whose shard can't be determined
+// are scattered to all the shards with Session.ShardScatter
+type ShardLister interface {
+	ShardNames(stmt *Statement) []string
+}
+
+// shardKeyExprRegexp matches the condition like `user_id = ?`
+var shardKeyExprRegexp = regexp.MustCompile("^\\s*(?:[`\"]?\\w+[`\"]?\\.)?[`\"]?(\\w+)[`\"]?\\s*=\\s*\\?\\s*$")
+
+// ShardKey returns the value of the field for Sharder, which is the value of the equal condition of the field, or the
+// value of the field of the destination or model struct if it isn't zero
+func (stmt *Statement) ShardKey(name string) (interface{}, bool) {
+	if stmt.Schema == nil {
+		return nil, false
+	}
+
+	field := stmt.Schema.LookUpField(name)
+	if field == nil {
+		return nil, false
+	}
+
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			if value, ok := shardKeyOf(field.DBName, where.Exprs); ok {
+				return value, true
+			}
+		}
+	}
+
+	reflectValue := stmt.ReflectValue
+	if reflectValue.Kind() != reflect.Struct && stmt.Model != nil {
+		reflectValue = reflect.Indirect(reflect.ValueOf(stmt.Model))
+	}
+
+	if reflectValue.Kind() == reflect.Struct && reflectValue.Type() == stmt.Schema.ModelType {
+		if value, isZero := field.ValueOf(stmt.Context, reflectValue); !isZero {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func shardKeyOf(column string, exprs []clause.Expression) (interface{}, bool) {
+	var isColumn func(c interface{}) bool
+	isColumn = func(c interface{}) bool {
+		switch c := c.(type) {
+		case string:
+			return c == column
+		case clause.Column:
+			return c.Name == column
+		case clause.Collate:
+			return isColumn(c.Column)
+		}
+		return false
+	}
+
+	for _, expr := range exprs {
+		switch v := expr.(type) {
+		case clause.Eq:
+			if isColumn(v.Column) {
+				return conditionValue(v.Value), true
+			}
+		case clause.IN:
+			if isColumn(v.Column) && len(v.Values) == 1 {
+				return conditionValue(v.Values[0]), true
+			}
+		case clause.Expr:
+			if matches := shardKeyExprRegexp.FindStringSubmatch(v.SQL); len(matches) == 2 && matches[1] == column && len(v.Vars) == 1 {
+				return v.Vars[0], true
+			}
+		case clause.AndConditions:
+			if value, ok := shardKeyOf(column, v.Exprs); ok {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// shard sets the table of the statement to the shard decided by Config.Sharder, returns true if the statement is
+// executed on the shards by itself, e.g: the created values of different shards, the scattered queries
+func (p *processor) shard(db *DB) bool {
+	stmt := db.Statement
+	if p == db.callbacks.Create() && stmt.ReflectValue.Kind() == reflect.Slice && stmt.ReflectValue.Len() > 0 {
+		return p.shardValues(db)
+	}
+
+	if name, ok := db.Sharder.ShardName(stmt); ok {
+		if name != "" {
+			p.useShard(stmt, name)
+		}
+		return false
+	}
+
+	lister, ok := db.Sharder.(ShardLister)
+	if !db.ShardScatter || !ok || p != db.callbacks.Query() {
+		db.AddError(fmt.Errorf("%w: %s", ErrShardNotFound, stmt.Schema.Table))
+		return true
+	}
+
+	p.scatter(db, lister.ShardNames(stmt))
+	return true
+}
+
+// shardValues creates the values of different shards on their shards
+func (p *processor) shardValues(db *DB) bool {
+	var (
+		stmt         = db.Statement
+		dest         = stmt.Dest
+		reflectValue = stmt.ReflectValue
+		shards       []string
+		groups       = map[string][]reflect.Value{}
+	)
+
+	for i := 0; i < reflectValue.Len(); i++ {
+		elem := reflectValue.Index(i)
+		if elem.Kind() != reflect.Ptr && elem.CanAddr() {
+			elem = elem.Addr()
+		}
+
+		stmt.Dest, stmt.ReflectValue = elem.Interface(), reflect.Indirect(elem)
+		name, ok := db.Sharder.ShardName(stmt)
+		stmt.Dest, stmt.ReflectValue = dest, reflectValue
+		if !ok {
+			db.AddError(fmt.Errorf("%w: %s of value #%d", ErrShardNotFound, stmt.Schema.Table, i))
+			return true
+		}
+
+		if _, ok := groups[name]; !ok {
+			shards = append(shards, name)
+		}
+		groups[name] = append(groups[name], elem)
+	}
+
+	if len(shards) == 1 {
+		if shards[0] != "" {
+			p.useShard(stmt, shards[0])
+		}
+		return false
+	}
+
+	createShards := func(tx *DB) error {
+		for _, shard := range shards {
+			values := reflect.MakeSlice(reflect.SliceOf(groups[shard][0].Type()), 0, len(groups[shard]))
+			values = reflect.Append(values, groups[shard]...)
+
+			result := p.executeShard(db, tx.Statement.ConnPool, shard, values.Interface())
+			db.RowsAffected += result.RowsAffected
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	}
+
+	if db.SkipDefaultTransaction {
+		db.AddError(createShards(db))
+	} else {
+		db.AddError(db.Transaction(createShards))
+	}
+	return true
+}
+
+// scatter executes the query on all the shards, the merged results are sorted by the ORDER BY columns, or the primary
+// keys if not ordered, before the limit and offset are applied to them
+func (p *processor) scatter(db *DB, shards []string) {
+	var (
+		stmt      = db.Statement
+		valueType = stmt.ReflectValue.Type()
+		limit     clause.Limit
+		results   reflect.Value
+	)
+
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice:
+		results = reflect.MakeSlice(valueType, 0, 0)
+	case reflect.Struct:
+		results = reflect.MakeSlice(reflect.SliceOf(valueType), 0, 0)
+	default:
+		db.AddError(fmt.Errorf("%w: %s, only the queries into structs and slices could be scattered", ErrShardNotFound, stmt.Schema.Table))
+		return
+	}
+
+	if c, ok := stmt.Clauses["LIMIT"]; ok {
+		limit, _ = c.Expression.(clause.Limit)
+	}
+
+	orders, err := scatterOrders(stmt)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+
+	for _, shard := range shards {
+		values := reflect.New(results.Type())
+		result := p.executeShard(db, stmt.ConnPool, shard, values.Interface(), func(tx *DB) {
+			tx.Statement.RaiseErrorOnNotFound = false
+			// the offset is applied to the merged results
+			if limit.Limit != nil {
+				shardLimit := *limit.Limit + limit.Offset
+				tx.Statement.Clauses["LIMIT"] = clause.Clause{Expression: clause.Limit{Limit: &shardLimit}}
+			} else {
+				delete(tx.Statement.Clauses, "LIMIT")
+			}
+		})
+		if result.Error != nil {
+			db.AddError(result.Error)
+			return
+		}
+		results = reflect.AppendSlice(results, values.Elem())
+	}
+
+	sortScattered(stmt.Context, results, orders)
+
+	if limit.Offset > 0 {
+		if limit.Offset >= results.Len() {
+			results = results.Slice(0, 0)
+		} else {
+			results = results.Slice(limit.Offset, results.Len())
+		}
+	}
+
+	if limit.Limit != nil && *limit.Limit >= 0 && *limit.Limit < results.Len() {
+		results = results.Slice(0, *limit.Limit)
+	}
+
+	db.RowsAffected = int64(results.Len())
+	if stmt.ReflectValue.Kind() == reflect.Slice {
+		stmt.ReflectValue.Set(results)
+	} else if results.Len() > 0 {
+		stmt.ReflectValue.Set(results.Index(0))
+	} else if stmt.RaiseErrorOnNotFound {
+		db.AddError(ErrRecordNotFound)
+	}
+}
+
+// scatterOrder the field the scattered results are sorted by
+type scatterOrder struct {
+	field *schema.Field
+	desc  bool
+}
+
+// scatterOrders returns the fields of the ORDER BY columns of the statement, or its primary fields if not ordered, the
+// orders by the expressions or the columns of other tables can't be applied to the merged results
+func scatterOrders(stmt *Statement) ([]scatterOrder, error) {
+	var orders []scatterOrder
+	c, ok := stmt.Clauses["ORDER BY"]
+	if !ok {
+		for _, field := range stmt.Schema.PrimaryFields {
+			orders = append(orders, scatterOrder{field: field})
+		}
+		return orders, nil
+	}
+
+	orderBy, _ := c.Expression.(clause.OrderBy)
+	if orderBy.Expression != nil {
+		return nil, fmt.Errorf("%w: %s, the scattered queries can't be ordered by the expressions", ErrShardNotFound, stmt.Schema.Table)
+	}
+
+	for _, column := range orderBy.Columns {
+		columns := []clause.OrderByColumn{column}
+		if column.Column.Raw {
+			if columns, ok = splitOrderColumns(column.Column.Name); !ok {
+				return nil, fmt.Errorf("%w: %s, the scattered queries can't be ordered by %s", ErrShardNotFound, stmt.Schema.Table, column.Column.Name)
+			}
+		}
+
+		for _, column := range columns {
+			col := column.Column
+			if col.Raw {
+				col, _ = clause.ParseIdentifier(col.Name)
+			}
+
+			field := stmt.Schema.LookUpField(col.Name)
+			if col.Name == clause.PrimaryKey {
+				field = stmt.Schema.PrioritizedPrimaryField
+			}
+			if field == nil || (col.Table != "" && col.Table != clause.CurrentTable && col.Table != stmt.Table && col.Table != stmt.Schema.Table) {
+				return nil, fmt.Errorf("%w: %s, the scattered queries can't be ordered by %s", ErrShardNotFound, stmt.Schema.Table, col.Name)
+			}
+			orders = append(orders, scatterOrder{field: field, desc: column.Desc})
+		}
+	}
+	return orders, nil
+}
+
+// sortScattered sorts the merged results of the shards by the orders
+func sortScattered(ctx context.Context, results reflect.Value, orders []scatterOrder) {
+	if len(orders) == 0 {
+		return
+	}
+
+	swap := reflect.Swapper(results.Interface())
+	sort.Stable(scatteredResults{len: results.Len(), swap: swap, less: func(i, j int) bool {
+		vi, vj := reflect.Indirect(results.Index(i)), reflect.Indirect(results.Index(j))
+		for _, order := range orders {
+			a, _ := order.field.ValueOf(ctx, vi)
+			b, _ := order.field.ValueOf(ctx, vj)
+			if cmp := compareScattered(a, b); cmp != 0 {
+				return (cmp < 0) != order.desc
+			}
+		}
+		return false
+	}})
+}
+
+type scatteredResults struct {
+	len  int
+	swap func(i, j int)
+	less func(i, j int) bool
+}
+
+func (r scatteredResults) Len() int           { return r.len }
+func (r scatteredResults) Swap(i, j int)      { r.swap(i, j) }
+func (r scatteredResults) Less(i, j int) bool { return r.less(i, j) }
+
+// compareScattered compares the values of the ORDER BY fields, the NULL values are sorted first
+func compareScattered(a, b interface{}) int {
+	if valuer, ok := a.(driver.Valuer); ok {
+		a, _ = valuer.Value()
+	}
+	if valuer, ok := b.(driver.Valuer); ok {
+		b, _ = valuer.Value()
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for va.Kind() == reflect.Ptr && !va.IsNil() {
+		va = va.Elem()
+	}
+	for vb.Kind() == reflect.Ptr && !vb.IsNil() {
+		vb = vb.Elem()
+	}
+
+	switch aNull, bNull := !va.IsValid() || va.Kind() == reflect.Ptr, !vb.IsValid() || vb.Kind() == reflect.Ptr; {
+	case aNull && bNull:
+		return 0
+	case aNull:
+		return -1
+	case bNull:
+		return 1
+	}
+
+	if ta, ok := va.Interface().(time.Time); ok {
+		if tb, ok := vb.Interface().(time.Time); ok {
+			if ta.Before(tb) {
+				return -1
+			} else if ta.After(tb) {
+				return 1
+			}
+			return 0
+		}
+	}
+
+	switch va.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if vb.CanInt() {
+			return cmpOrdered(va.Int(), vb.Int())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if vb.CanUint() {
+			return cmpOrdered(va.Uint(), vb.Uint())
+		}
+	case reflect.Float32, reflect.Float64:
+		if vb.CanFloat() {
+			return cmpOrdered(va.Float(), vb.Float())
+		}
+	case reflect.Bool:
+		if vb.Kind() == reflect.Bool {
+			return cmpOrdered(fmt.Sprint(va.Bool()), fmt.Sprint(vb.Bool()))
+		}
+	case reflect.Slice:
+		if ba, ok := va.Interface().([]byte); ok {
+			if bb, ok := vb.Interface().([]byte); ok {
+				return bytes.Compare(ba, bb)
+			}
+		}
+	}
+	return cmpOrdered(fmt.Sprint(va.Interface()), fmt.Sprint(vb.Interface()))
+}
+
+func cmpOrdered[T int64 | uint64 | float64 | string](a, b T) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// executeShard executes the statement on the shard table with the dest
+func (p *processor) executeShard(db *DB, connPool ConnPool, shard string, dest interface{}, modifiers ...func(*DB)) *DB {
+	tx := &DB{Config: db.Config}
+	tx.Statement = db.Statement.clone()
+	tx.Statement.DB = tx
+	tx.Statement.ConnPool = connPool
+	p.useShard(tx.Statement, shard)
+	if model, value := reflect.ValueOf(tx.Statement.Model), reflect.ValueOf(tx.Statement.Dest); p == db.callbacks.Create() ||
+		(model.Kind() == reflect.Ptr && value.Kind() == reflect.Ptr && model.Pointer() == value.Pointer()) {
+		tx.Statement.Model = dest
+	}
+	tx.Statement.Dest = dest
+
+	for _, modifier := range modifiers {
+		modifier(tx)
+	}
+	return p.Execute(tx)
+}
+
+// useShard uses the shard table for the statement, the shard of the queries is aliased as the table of the model, so
+// the conditions referring the table of the model, e.g: the conditions of associations, still work
+func (p *processor) useShard(stmt *Statement, shard string) {
+	if p == stmt.DB.callbacks.Query() || p == stmt.DB.callbacks.Row() {
+		stmt.TableExpr = &clause.Expr{SQL: "? AS ?", Vars: []interface{}{clause.Table{Name: shard}, clause.Table{Name: stmt.Table}}}
+	} else {
+		stmt.Table = shard
+		stmt.TableExpr = &clause.Expr{SQL: stmt.Quote(shard)}
+	}
+}
@@ -45,6 +45,11 @@ func (n *DeletedAt) UnmarshalJSON(b []byte) error {
 	return err
 }
 
+// ArchiveTable implements schema.SoftDeleteStrategy, the deleted rows are kept in the table
+func (DeletedAt) ArchiveTable(string) string {
+	return ""
+}
+
 func (DeletedAt) QueryClauses(f *schema.Field) []clause.Interface {
 	return []clause.Interface{SoftDeleteQueryClause{Field: f, ZeroValue: parseZeroValueTag(f)}}
 }
@@ -74,27 +79,53 @@ func (sd SoftDeleteQueryClause) MergeClause(*clause.Clause) {
 }
 
 func (sd SoftDeleteQueryClause) ModifyStatement(stmt *Statement) {
-	if _, ok := stmt.Clauses["soft_delete_enabled"]; !ok && !stmt.Statement.Unscoped {
-		if c, ok := stmt.Clauses["WHERE"]; ok {
-			if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) >= 1 {
-				for _, expr := range where.Exprs {
-					if orCond, ok := expr.(clause.OrConditions); ok && len(orCond.Exprs) == 1 {
-						where.Exprs = []clause.Expression{clause.And(where.Exprs...)}
-						c.Expression = where
-						stmt.Clauses["WHERE"] = c
-						break
-					}
-				}
-			}
+	if !isUnscoped(stmt, sd.Field) {
+		addSoftDeleteCondition(stmt, sd.Field, sd.ZeroValue)
+	}
+}
+
+// isUnscoped reports whether the soft delete conditions of field are skipped for the table of stmt, which is unscoped
+// by Unscoped, by Joins with an unscoped db, or listed in UnscopedTables
+func isUnscoped(stmt *Statement, field *schema.Field) bool {
+	if stmt.Unscoped || stmt.Statement.Unscoped {
+		return true
+	}
+
+	for _, table := range stmt.Statement.UnscopedTables {
+		if table == stmt.Table || table == field.Schema.Table {
+			return true
 		}
+	}
+	return false
+}
 
+// addSoftDeleteCondition adds the condition that the soft delete field equals to zeroValue
+func addSoftDeleteCondition(stmt *Statement, field *schema.Field, zeroValue interface{}) {
+	if _, ok := stmt.Clauses["soft_delete_enabled"]; !ok && !stmt.Statement.Unscoped {
+		groupOrConditions(stmt)
 		stmt.AddClause(clause.Where{Exprs: []clause.Expression{
-			clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: sd.Field.DBName}, Value: sd.ZeroValue},
+			clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: zeroValue},
 		}})
 		stmt.Clauses["soft_delete_enabled"] = clause.Clause{}
 	}
 }
 
+// groupOrConditions groups the where conditions having OR conditions, so conditions added later restrict all of them
+func groupOrConditions(stmt *Statement) {
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) >= 1 {
+			for _, expr := range where.Exprs {
+				if orCond, ok := expr.(clause.OrConditions); ok && len(orCond.Exprs) == 1 {
+					where.Exprs = []clause.Expression{clause.And(where.Exprs...)}
+					c.Expression = where
+					stmt.Clauses["WHERE"] = c
+					break
+				}
+			}
+		}
+	}
+}
+
 func (DeletedAt) UpdateClauses(f *schema.Field) []clause.Interface {
 	return []clause.Interface{SoftDeleteUpdateClause{Field: f, ZeroValue: parseZeroValueTag(f)}}
 }
@@ -141,30 +172,184 @@ func (sd SoftDeleteDeleteClause) MergeClause(*clause.Clause) {
 
 func (sd SoftDeleteDeleteClause) ModifyStatement(stmt *Statement) {
 	if stmt.SQL.Len() == 0 && !stmt.Statement.Unscoped {
-		curTime := stmt.DB.NowFunc()
-		stmt.AddClause(clause.Set{{Column: clause.Column{Name: sd.Field.DBName}, Value: curTime}})
-		stmt.SetColumn(sd.Field.DBName, curTime, true)
+		softDelete(stmt, sd.Field, stmt.DB.NowFunc(), sd.ZeroValue)
+	}
+}
+
+// softDeletedBy returns the value of Config.SoftDeleteByContextKey in the context, or the audit user
+func softDeletedBy(stmt *Statement) (interface{}, bool) {
+	if stmt.DB.SoftDeleteByContextKey != nil {
+		if deletedBy := stmt.Context.Value(stmt.DB.SoftDeleteByContextKey); deletedBy != nil {
+			return deletedBy, true
+		}
+	}
+	return stmt.AuditUser()
+}
+
+// softDelete builds the update setting the soft delete field to value and the `softDeleteBy` field to the value of
+// Config.SoftDeleteByContextKey in the context or the audit user, instead of deleting the rows
+func softDelete(stmt *Statement, field *schema.Field, value, zeroValue interface{}) {
+	set := clause.Set{{Column: clause.Column{Name: field.DBName}, Value: value}}
+	stmt.SetColumn(field.DBName, value, true)
+
+	if stmt.Schema != nil && stmt.Schema.SoftDeleteByField != nil {
+		if deletedBy, ok := softDeletedBy(stmt); ok {
+			set = append(set, clause.Assignment{Column: clause.Column{Name: stmt.Schema.SoftDeleteByField.DBName}, Value: deletedBy})
+			stmt.SetColumn(stmt.Schema.SoftDeleteByField.DBName, deletedBy, true)
+		}
+	}
+	stmt.AddClause(set)
 
-		if stmt.Schema != nil {
-			_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
-			column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+	if stmt.Schema != nil {
+		_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+		column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+
+		if len(values) > 0 {
+			stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+		}
+
+		if stmt.ReflectValue.CanAddr() && stmt.Dest != stmt.Model && stmt.Model != nil {
+			_, queryValues = schema.GetIdentityFieldValuesMap(stmt.Context, reflect.ValueOf(stmt.Model), stmt.Schema.PrimaryFields)
+			column, values = schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
 
 			if len(values) > 0 {
 				stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
 			}
+		}
+	}
+
+	addSoftDeleteCondition(stmt, field, zeroValue)
+	stmt.AddClauseIfNotExists(clause.Update{})
+	stmt.BuildReturning()
+	stmt.BuildLimited(stmt.DB.Callback().Update().Clauses...)
+}
 
-			if stmt.ReflectValue.CanAddr() && stmt.Dest != stmt.Model && stmt.Model != nil {
-				_, queryValues = schema.GetIdentityFieldValuesMap(stmt.Context, reflect.ValueOf(stmt.Model), stmt.Schema.PrimaryFields)
-				column, values = schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+// SoftDeleteMode the soft delete of the fields tagged with `softDelete`, which is set instead of deleting the rows and
+// is 0 or false for the rows not deleted, e.g:
+//
+//	type User struct {
+//	  ID        uint
+//	  Name      string `gorm:"uniqueIndex:idx_name"`
+//	  DeletedAt int64  `gorm:"softDelete:milli;uniqueIndex:idx_name"`
+//	  DeletedBy string `gorm:"softDeleteBy"`
+//	}
+type SoftDeleteMode string
+
+// soft delete modes
+const (
+	// SoftDeleteFlag sets the field to 1, or true for booleans
+	SoftDeleteFlag SoftDeleteMode = "flag"
+	// SoftDeleteMilli sets the field to the unix milliseconds
+	SoftDeleteMilli SoftDeleteMode = "milli"
+	// SoftDeleteNano sets the field to the unix nanoseconds
+	SoftDeleteNano SoftDeleteMode = "nano"
+)
 
-				if len(values) > 0 {
-					stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
-				}
-			}
-		}
+func init() {
+	for _, mode := range []SoftDeleteMode{SoftDeleteFlag, SoftDeleteMilli, SoftDeleteNano} {
+		schema.RegisterSoftDelete(string(mode), mode)
+	}
+	// the time fields tagged with `softDelete:time` are soft deleted like DeletedAt, e.g: the fields of schema.DynamicSchema
+	schema.RegisterSoftDelete("time", DeletedAt{})
+}
 
-		SoftDeleteQueryClause(sd).ModifyStatement(stmt)
-		stmt.AddClauseIfNotExists(clause.Update{})
-		stmt.Build(stmt.DB.Callback().Update().Clauses...)
+// ArchiveTable implements schema.SoftDeleteStrategy, the deleted rows are kept in the table
+func (SoftDeleteMode) ArchiveTable(string) string {
+	return ""
+}
+
+func (mode SoftDeleteMode) zeroValue(f *schema.Field) interface{} {
+	if f.DataType == schema.Bool {
+		return false
+	}
+	return 0
+}
+
+func (mode SoftDeleteMode) deletedValue(stmt *Statement, f *schema.Field) interface{} {
+	switch mode {
+	case SoftDeleteMilli:
+		return stmt.DB.NowFunc().UnixMilli()
+	case SoftDeleteNano:
+		return stmt.DB.NowFunc().UnixNano()
+	}
+
+	if f.DataType == schema.Bool {
+		return true
+	}
+	return 1
+}
+
+func (mode SoftDeleteMode) QueryClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{SoftDeleteModeQueryClause{Field: f, Mode: mode}}
+}
+
+func (mode SoftDeleteMode) UpdateClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{SoftDeleteModeUpdateClause{Field: f, Mode: mode}}
+}
+
+func (mode SoftDeleteMode) DeleteClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{SoftDeleteModeDeleteClause{Field: f, Mode: mode}}
+}
+
+type SoftDeleteModeQueryClause struct {
+	Field *schema.Field
+	Mode  SoftDeleteMode
+}
+
+func (sd SoftDeleteModeQueryClause) Name() string {
+	return ""
+}
+
+func (sd SoftDeleteModeQueryClause) Build(clause.Builder) {
+}
+
+func (sd SoftDeleteModeQueryClause) MergeClause(*clause.Clause) {
+}
+
+func (sd SoftDeleteModeQueryClause) ModifyStatement(stmt *Statement) {
+	if !isUnscoped(stmt, sd.Field) {
+		addSoftDeleteCondition(stmt, sd.Field, sd.Mode.zeroValue(sd.Field))
+	}
+}
+
+type SoftDeleteModeUpdateClause struct {
+	Field *schema.Field
+	Mode  SoftDeleteMode
+}
+
+func (sd SoftDeleteModeUpdateClause) Name() string {
+	return ""
+}
+
+func (sd SoftDeleteModeUpdateClause) Build(clause.Builder) {
+}
+
+func (sd SoftDeleteModeUpdateClause) MergeClause(*clause.Clause) {
+}
+
+func (sd SoftDeleteModeUpdateClause) ModifyStatement(stmt *Statement) {
+	if stmt.SQL.Len() == 0 && !stmt.Statement.Unscoped {
+		addSoftDeleteCondition(stmt, sd.Field, sd.Mode.zeroValue(sd.Field))
+	}
+}
+
+type SoftDeleteModeDeleteClause struct {
+	Field *schema.Field
+	Mode  SoftDeleteMode
+}
+
+func (sd SoftDeleteModeDeleteClause) Name() string {
+	return ""
+}
+
+func (sd SoftDeleteModeDeleteClause) Build(clause.Builder) {
+}
+
+func (sd SoftDeleteModeDeleteClause) MergeClause(*clause.Clause) {
+}
+
+func (sd SoftDeleteModeDeleteClause) ModifyStatement(stmt *Statement) {
+	if stmt.SQL.Len() == 0 && !stmt.Statement.Unscoped {
+		softDelete(stmt, sd.Field, sd.Mode.deletedValue(stmt, sd.Field), sd.Mode.zeroValue(sd.Field))
 	}
 }
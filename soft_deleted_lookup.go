@@ -0,0 +1,66 @@
+package gorm
+
+import (
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// SoftDeletedMode how FirstOrInit and FirstOrCreate treat the soft deleted rows matching the conditions, see
+// WithSoftDeleted
+type SoftDeletedMode int
+
+const (
+	// SoftDeletedExcluded the soft deleted rows aren't looked up, FirstOrCreate creates a new row for them
+	SoftDeletedExcluded SoftDeletedMode = iota
+	// SoftDeletedIncluded the soft deleted rows are looked up as the found rows, which are initialized or updated with
+	// Assign and kept soft deleted, e.g: the unique indexes including the soft deleted rows
+	SoftDeletedIncluded
+	// RestoreOnConflict the soft deleted rows are looked up, FirstOrCreate restores the found soft deleted row and
+	// updates it with Assign instead of creating a new row, e.g: the unique indexes WHERE deleted_at IS NULL
+	RestoreOnConflict
+)
+
+// WithSoftDeleted specifies how FirstOrInit and FirstOrCreate treat the soft deleted rows matching the conditions, the
+// rows are looked up without the soft delete conditions of the model only, the default scopes are still applied, e.g:
+//
+//	// restores the soft deleted user of the email instead of creating a new one
+//	db.Where(User{Email: "jinzhu@example.org"}).Assign(User{Name: "jinzhu"}).WithSoftDeleted(gorm.RestoreOnConflict).FirstOrCreate(&user)
+//
+// FirstOrCreate looks up and creates or restores the row in a transaction unless SkipDefaultTransaction, the created
+// row calls the hooks of Create, the restored row calls the hooks of Restore, and the hooks of Update if it's updated
+// with Assign, the rows archived by ArchivedAt aren't looked up, which are moved out of the table
+func (db *DB) WithSoftDeleted(mode SoftDeletedMode) (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.softDeleted = mode
+	return
+}
+
+// unscopeSoftDeleted disables the soft delete conditions of the model of FirstOrInit and FirstOrCreate, the errors of
+// parsing are returned by the statements
+func (db *DB) unscopeSoftDeleted(dest interface{}) *DB {
+	model := db.Statement.Model
+	if model == nil {
+		model = dest
+	}
+
+	if s, err := schema.Parse(model, db.cacheStore, db.NamingStrategy); err == nil && s.SoftDelete != nil {
+		return db.UnscopedTables(s.Table)
+	}
+	return db
+}
+
+// isSoftDeleted reports whether the row of dest found by FirstOrInit and FirstOrCreate is soft deleted
+func (stmt *Statement) isSoftDeleted(dest interface{}) bool {
+	if stmt.Schema == nil || stmt.Schema.SoftDeleteField == nil {
+		return false
+	}
+
+	reflectValue := reflect.Indirect(reflect.ValueOf(dest))
+	if reflectValue.Kind() != reflect.Struct || reflectValue.Type() != stmt.Schema.ModelType {
+		return false
+	}
+
+	_, zero := stmt.Schema.SoftDeleteField.ValueOf(stmt.Context, reflectValue)
+	return !zero
+}
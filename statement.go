@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
@@ -25,10 +26,12 @@ type Statement struct {
 	Table                string
 	Model                interface{}
 	Unscoped             bool
+	UnscopedTables       []string // tables which skip the soft delete conditions, see DB.UnscopedTables
 	Dest                 interface{}
 	ReflectValue         reflect.Value
 	Clauses              map[string]clause.Clause
 	BuildClauses         []string
+	ClauseBuilders       map[string]clause.ClauseBuilder // the clause builders overriding Config.ClauseBuilders, see DB.WithClauseBuilder
 	Distinct             bool
 	Selects              []string          // selected columns
 	Omits                []string          // omit columns
@@ -41,12 +44,117 @@ type Statement struct {
 	Context              context.Context
 	RaiseErrorOnNotFound bool
 	SkipHooks            bool
+	AppliedScopes        []string      // names of the applied scopes in order, for debugging
+	CacheKey             string        // the prefix of the keys of the cached results, which are derived from the SQL, see Session.CacheKey
+	Result               sql.Result    // the result of the statement executed by Exec, see DB.LastInsertID
+	Upsert               *UpsertResult // the rows inserted and updated by Create with OnConflict, see UpsertStats
+	Warnings             []Warning     // the warnings raised by the statement, see Config.CaptureWarnings
 	SQL                  strings.Builder
 	Vars                 []interface{}
 	CurDestIndex         int
 	attrs                []interface{}
 	assigns              []interface{}
-	scopes               []func(*DB) *DB
+	softDeleted          SoftDeletedMode
+	associationJSONs     []associationJSON
+	scopes               []scope
+	sqlStatements        *[]SQLStatement
+	varBindings          []*VarBinding
+	emptyINs             []string
+	buildingClause       string
+	operation            string
+	sqlComment           string // the comment appended to the SQL last executed, logged with it
+	cacheHit             bool
+	cacheKeyResolved     string
+	pooled               bool
+}
+
+// SQLStatement the sql and vars of a statement generated in DryRun mode
+type SQLStatement struct {
+	SQL  string
+	Vars []interface{}
+}
+
+var (
+	statementPool = sync.Pool{New: func() interface{} {
+		return &Statement{Clauses: map[string]clause.Clause{}, Preloads: map[string][]interface{}{}}
+	}}
+	// varsEstimate rolling estimate of the vars count of pooled statements, used to pre-size Vars
+	varsEstimate int64 = 8
+)
+
+// acquireStatement gets a statement from the pool, it should be released by the finisher created it
+func acquireStatement() *Statement {
+	stmt := statementPool.Get().(*Statement)
+	stmt.pooled = true
+	if estimate := int(atomic.LoadInt64(&varsEstimate)); cap(stmt.Vars) < estimate {
+		stmt.Vars = make([]interface{}, 0, estimate)
+	}
+	return stmt
+}
+
+// resetVars resets vars after executed, keeps the allocated vars of pooled statements
+func (stmt *Statement) resetVars() {
+	stmt.varBindings = nil
+	if !stmt.pooled {
+		stmt.Vars = nil
+		return
+	}
+
+	if size := int64(len(stmt.Vars)); size > 0 {
+		estimate := atomic.LoadInt64(&varsEstimate)
+		atomic.StoreInt64(&varsEstimate, (estimate*7+size+7)/8)
+	}
+
+	for idx := range stmt.Vars {
+		stmt.Vars[idx] = nil
+	}
+	stmt.Vars = stmt.Vars[:0]
+}
+
+// release puts the statement back to the pool, returns a detached statement keeping the values which could be
+// accessed after finished
+func (stmt *Statement) release() *Statement {
+	detached := &Statement{
+		DB:                   stmt.DB,
+		TableExpr:            stmt.TableExpr,
+		Table:                stmt.Table,
+		Model:                stmt.Model,
+		Unscoped:             stmt.Unscoped,
+		UnscopedTables:       stmt.UnscopedTables,
+		Dest:                 stmt.Dest,
+		ReflectValue:         stmt.ReflectValue,
+		Clauses:              map[string]clause.Clause{},
+		ConnPool:             stmt.ConnPool,
+		Schema:               stmt.Schema,
+		Context:              stmt.Context,
+		RaiseErrorOnNotFound: stmt.RaiseErrorOnNotFound,
+		SkipHooks:            stmt.SkipHooks,
+		AppliedScopes:        stmt.AppliedScopes,
+		CacheKey:             stmt.CacheKey,
+		Result:               stmt.Result,
+		Upsert:               stmt.Upsert,
+		Warnings:             stmt.Warnings,
+		cacheHit:             stmt.cacheHit,
+		sqlStatements:        stmt.sqlStatements,
+	}
+
+	// the redacted loads are rejected by Save of the returned DB, see RegisterRedactor
+	if redacted, ok := stmt.Settings.Load(redactedKey); ok {
+		detached.Settings.Store(redactedKey, redacted)
+	}
+
+	clauses, preloads := stmt.Clauses, stmt.Preloads
+	for k := range clauses {
+		delete(clauses, k)
+	}
+	for k := range preloads {
+		delete(preloads, k)
+	}
+
+	stmt.resetVars()
+	*stmt = Statement{Clauses: clauses, Preloads: preloads, Vars: stmt.Vars}
+	statementPool.Put(stmt)
+	return detached
 }
 
 type join struct {
@@ -56,6 +164,7 @@ type join struct {
 	Selects  []string
 	Omits    []string
 	JoinType clause.JoinType
+	Unscoped bool
 }
 
 // StatementModifier statement modifier interface
@@ -63,6 +172,28 @@ type StatementModifier interface {
 	ModifyStatement(*Statement)
 }
 
+// SQLStatements returns the statements generated in DryRun mode, in the order they would have been executed,
+// including the statements of preloads, associations and hooks run by the call
+func (stmt *Statement) SQLStatements() []SQLStatement {
+	if stmt.sqlStatements == nil {
+		return nil
+	}
+	return *stmt.sqlStatements
+}
+
+// Fingerprint returns the hash of the normalized SQL of the statement, which is the same for the statements of the same
+// shape and the SQL explained with their vars, see utils.NormalizeSQL
+func (stmt *Statement) Fingerprint() string {
+	return utils.SQLFingerprint(stmt.SQL.String())
+}
+
+// recordSQLStatement records the generated statement in DryRun mode
+func (stmt *Statement) recordSQLStatement() {
+	vars := make([]interface{}, len(stmt.Vars))
+	copy(vars, stmt.Vars)
+	*stmt.sqlStatements = append(*stmt.sqlStatements, SQLStatement{SQL: stmt.SQL.String(), Vars: vars})
+}
+
 // WriteString write string
 func (stmt *Statement) WriteString(str string) (int, error) {
 	return stmt.SQL.WriteString(str)
@@ -104,6 +235,14 @@ func (stmt *Statement) QuoteTo(writer clause.Writer, field interface{}) {
 			writer.WriteByte(' ')
 			write(v.Raw, v.Alias)
 		}
+	case *clause.Column:
+		if v != nil {
+			stmt.QuoteTo(writer, *v)
+		}
+	case *clause.Table:
+		if v != nil {
+			stmt.QuoteTo(writer, *v)
+		}
 	case clause.Column:
 		if v.Table != "" {
 			if v.Table == clause.CurrentTable {
@@ -143,6 +282,8 @@ func (stmt *Statement) QuoteTo(writer clause.Writer, field interface{}) {
 		writer.WriteByte(')')
 	case clause.Expr:
 		v.Build(stmt)
+	case clause.Collate:
+		v.Build(stmt)
 	case string:
 		stmt.DB.Dialector.QuoteTo(writer, v)
 	case []string:
@@ -166,6 +307,75 @@ func (stmt *Statement) Quote(field interface{}) string {
 	return builder.String()
 }
 
+// quoteIdentifierVars replaces the placeholders of sql with the quoted columns and tables of vars, which is used as
+// identifiers, e.g: GROUP BY, so other vars are unsupported, `??` is escaped like clause.Expr
+func (stmt *Statement) quoteIdentifierVars(sql string, vars []interface{}) string {
+	var (
+		builder strings.Builder
+		idx     int
+		escaped = strings.Contains(sql, "??") && strings.Count(sql, "?") > len(vars)
+	)
+
+	for i := 0; i < len(sql); i++ {
+		switch {
+		case escaped && sql[i] == '?' && i+1 < len(sql) && sql[i+1] == '?':
+			builder.WriteByte('?')
+			i++
+		case sql[i] == '?' && idx < len(vars):
+			switch v := vars[idx].(type) {
+			case clause.Column, clause.Table, *clause.Column, *clause.Table:
+				stmt.QuoteTo(&builder, v)
+			case clause.RawColumn:
+				builder.WriteString(string(v))
+			default:
+				stmt.AddError(fmt.Errorf("%w: %T should be clause.Column, clause.Table or clause.RawColumn in %v", ErrInvalidData, v, sql))
+			}
+			idx++
+		default:
+			builder.WriteByte(sql[i])
+		}
+	}
+
+	if idx < len(vars) {
+		stmt.AddError(fmt.Errorf("%w: %v has %d placeholders for %d vars", ErrInvalidData, sql, idx, len(vars)))
+	}
+	return builder.String()
+}
+
+// normalizePlaceholders rewrites the numbered placeholders of the SQL of Raw and Exec to `?` and binds the question marks
+// which aren't placeholders to themselves, e.g: the ones of the string literals and the Postgres operators `?|`, so the
+// SQL is built like clause.Expr, see Config.NormalizePlaceholders
+func (stmt *Statement) normalizePlaceholders(sql string, vars []interface{}) (string, []interface{}) {
+	normalized, indexes, err := utils.NormalizePlaceholders(sql)
+	if err != nil {
+		stmt.AddError(fmt.Errorf("%w: %v", ErrInvalidData, err))
+		return sql, vars
+	}
+
+	var (
+		used      = -1
+		boundVars = make([]interface{}, 0, len(indexes)+len(vars))
+	)
+	for _, index := range indexes {
+		if index < 0 {
+			boundVars = append(boundVars, clause.RawColumn("?"))
+			continue
+		}
+
+		if index >= len(vars) {
+			stmt.AddError(fmt.Errorf("%w: %v has placeholder %d for %d vars", ErrInvalidData, sql, index+1, len(vars)))
+			return sql, vars
+		}
+
+		if boundVars = append(boundVars, vars[index]); index > used {
+			used = index
+		}
+	}
+
+	// the vars after the placeholders are kept like clause.Expr, e.g: the named vars
+	return normalized, append(boundVars, vars[used+1:]...)
+}
+
 // AddVar add var
 func (stmt *Statement) AddVar(writer clause.Writer, vars ...interface{}) {
 	for idx, v := range vars {
@@ -178,6 +388,18 @@ func (stmt *Statement) AddVar(writer clause.Writer, vars ...interface{}) {
 			stmt.Vars = append(stmt.Vars, v.Value)
 		case clause.Column, clause.Table:
 			stmt.QuoteTo(writer, v)
+		case *clause.Column:
+			if v == nil {
+				stmt.AddVar(writer, nil)
+			} else {
+				stmt.QuoteTo(writer, *v)
+			}
+		case *clause.Table:
+			if v == nil {
+				stmt.AddVar(writer, nil)
+			} else {
+				stmt.QuoteTo(writer, *v)
+			}
 		case Valuer:
 			reflectValue := reflect.ValueOf(v)
 			if reflectValue.Kind() == reflect.Ptr && reflectValue.IsNil() {
@@ -203,9 +425,14 @@ func (stmt *Statement) AddVar(writer clause.Writer, vars ...interface{}) {
 				stmt.AddVar(writer, v...)
 				writer.WriteByte(')')
 			} else {
+				stmt.ReportEmptyIN(nil)
 				writer.WriteString("(NULL)")
 			}
 		case *DB:
+			if stmt.DB.TrackVarBindings {
+				stmt.syncVarBindings(nil)
+			}
+
 			subdb := v.Session(&Session{Logger: logger.Discard, DryRun: true}).getInstance()
 			if v.Statement.SQL.Len() > 0 {
 				var (
@@ -235,10 +462,17 @@ func (stmt *Statement) AddVar(writer clause.Writer, vars ...interface{}) {
 
 			writer.WriteString(subdb.Statement.SQL.String())
 			stmt.Vars = subdb.Statement.Vars
+			for _, condition := range subdb.Statement.emptyINs {
+				stmt.reportEmptyIN(condition)
+			}
+			if stmt.DB.TrackVarBindings && len(subdb.Statement.varBindings) > len(stmt.varBindings) {
+				stmt.varBindings = append(stmt.varBindings, subdb.Statement.varBindings[len(stmt.varBindings):]...)
+			}
 		default:
 			switch rv := reflect.ValueOf(v); rv.Kind() {
 			case reflect.Slice, reflect.Array:
 				if rv.Len() == 0 {
+					stmt.ReportEmptyIN(nil)
 					writer.WriteString("(NULL)")
 				} else if rv.Type().Elem() == reflect.TypeOf(uint8(0)) {
 					stmt.Vars = append(stmt.Vars, v)
@@ -281,11 +515,12 @@ func (stmt *Statement) AddClauseIfNotExists(v clause.Interface) {
 	}
 }
 
-// BuildCondition build condition
+// BuildCondition build condition, the conditions of maps are built in the order of their sorted keys, so the same
+// conditions are always built into the same SQL, e.g: for the SQL snapshots and the prepared statements
 func (stmt *Statement) BuildCondition(query interface{}, args ...interface{}) []clause.Expression {
 	if s, ok := query.(string); ok {
-		// if it is a number, then treats it as primary key
-		if _, err := strconv.Atoi(s); err != nil {
+		// if it is a number or a UUID, then treats it as primary key
+		if _, err := strconv.Atoi(s); err != nil && (len(args) > 0 || !schema.IsUUID(s)) {
 			if s == "" && len(args) == 0 {
 				return nil
 			}
@@ -297,6 +532,9 @@ func (stmt *Statement) BuildCondition(query interface{}, args ...interface{}) []
 
 			if len(args) > 0 && strings.Contains(s, "@") {
 				// looks like a named query
+				if stmt.DB.NilAsNull {
+					return []clause.Expression{clause.NilAsNull{SQL: s, Vars: args}}
+				}
 				return []clause.Expression{clause.NamedExpr{SQL: s, Vars: args}}
 			}
 
@@ -339,9 +577,17 @@ func (stmt *Statement) BuildCondition(query interface{}, args ...interface{}) []
 					conds = append(conds, cs.Expression)
 				}
 			}
+		case StructCondition:
+			conds = append(conds, stmt.buildStructCondition(v)...)
 		case map[interface{}]interface{}:
-			for i, j := range v {
-				conds = append(conds, clause.Eq{Column: i, Value: j})
+			keys := make([]interface{}, 0, len(v))
+			for i := range v {
+				keys = append(keys, i)
+			}
+			sortConditionKeys(keys)
+
+			for _, key := range keys {
+				conds = append(conds, clause.Eq{Column: key, Value: v[key]})
 			}
 		case map[string]string:
 			keys := make([]string, 0, len(v))
@@ -351,7 +597,7 @@ func (stmt *Statement) BuildCondition(query interface{}, args ...interface{}) []
 			sort.Strings(keys)
 
 			for _, key := range keys {
-				conds = append(conds, clause.Eq{Column: key, Value: v[key]})
+				conds = append(conds, clause.Eq{Column: stmt.collatedColumn(key), Value: v[key]})
 			}
 		case map[string]interface{}:
 			keys := make([]string, 0, len(v))
@@ -373,13 +619,13 @@ func (stmt *Statement) BuildCondition(query interface{}, args ...interface{}) []
 						valueLen := reflectValue.Len()
 						values := make([]interface{}, valueLen)
 						for i := 0; i < valueLen; i++ {
-							values[i] = reflectValue.Index(i).Interface()
+							values[i] = durationVarOf(key, reflectValue.Index(i).Interface())
 						}
 
 						conds = append(conds, clause.IN{Column: key, Values: values})
 					}
 				default:
-					conds = append(conds, clause.Eq{Column: key, Value: v[key]})
+					conds = append(conds, clause.Eq{Column: stmt.collatedColumn(key), Value: durationVarOf(key, v[key])})
 				}
 			}
 		default:
@@ -406,7 +652,7 @@ func (stmt *Statement) BuildCondition(query interface{}, args ...interface{}) []
 						if selected || (!restricted && field.Readable) {
 							if v, isZero := field.ValueOf(stmt.Context, reflectValue); !isZero || selected {
 								if field.DBName != "" {
-									conds = append(conds, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: v})
+									conds = append(conds, clause.Eq{Column: collateColumn(field, clause.Column{Table: clause.CurrentTable, Name: field.DBName}), Value: v})
 								} else if field.DataType != "" {
 									conds = append(conds, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.Name}, Value: v})
 								}
@@ -420,7 +666,7 @@ func (stmt *Statement) BuildCondition(query interface{}, args ...interface{}) []
 							if selected || (!restricted && field.Readable) {
 								if v, isZero := field.ValueOf(stmt.Context, reflectValue.Index(i)); !isZero || selected {
 									if field.DBName != "" {
-										conds = append(conds, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: v})
+										conds = append(conds, clause.Eq{Column: collateColumn(field, clause.Column{Table: clause.CurrentTable, Name: field.DBName}), Value: v})
 									} else if field.DataType != "" {
 										conds = append(conds, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.Name}, Value: v})
 									}
@@ -446,11 +692,9 @@ func (stmt *Statement) BuildCondition(query interface{}, args ...interface{}) []
 							values[i] = reflectValue.Index(i).Interface()
 						}
 
-						if len(values) > 0 {
-							conds = append(conds, clause.IN{Column: clause.PrimaryColumn, Values: values})
-							return []clause.Expression{clause.And(conds...)}
-						}
-						return nil
+						// the empty values match nothing, see Config.ErrorOnEmptyIN
+						conds = append(conds, clause.IN{Column: clause.PrimaryColumn, Values: values})
+						return []clause.Expression{clause.And(conds...)}
 					}
 				}
 
@@ -465,9 +709,20 @@ func (stmt *Statement) BuildCondition(query interface{}, args ...interface{}) []
 	return nil
 }
 
+// DialectName returns the name of the dialector, implements clause.DialectNamer
+func (stmt *Statement) DialectName() string {
+	if stmt.DB == nil || stmt.DB.Config == nil || stmt.DB.Dialector == nil {
+		return ""
+	}
+	return stmt.DB.Dialector.Name()
+}
+
 // Build build sql with clauses names
 func (stmt *Statement) Build(clauses ...string) {
-	var firstClauseWritten bool
+	var (
+		firstClauseWritten bool
+		buildingClause     = stmt.buildingClause
+	)
 
 	for _, name := range clauses {
 		if c, ok := stmt.Clauses[name]; ok {
@@ -476,13 +731,123 @@ func (stmt *Statement) Build(clauses ...string) {
 			}
 
 			firstClauseWritten = true
-			if b, ok := stmt.DB.ClauseBuilders[name]; ok {
+			stmt.buildingClause = name
+			if b, ok := stmt.ClauseBuilders[name]; ok {
+				b(c, stmt)
+			} else if b, ok := stmt.DB.ClauseBuilders[name]; ok {
 				b(c, stmt)
 			} else {
 				c.Build(stmt)
 			}
 		}
 	}
+	stmt.buildingClause = buildingClause
+}
+
+// Interrupted returns *InterruptedError if the context of the statement is done, which should be checked between the
+// statements of the operations executing many, e.g: the batches, completed is the steps of the operation completed
+func (stmt *Statement) Interrupted(operation string, completed int) error {
+	if stmt.Context == nil {
+		return nil
+	}
+
+	if err := stmt.Context.Err(); err != nil {
+		return &InterruptedError{Operation: operation, Completed: completed, Err: err}
+	}
+	return nil
+}
+
+// CreatingFromQuery reports whether the statement creates the rows selected by a query with DB.CreateFromQuery, whose
+// create hooks are called once with the model instead of the created rows
+func (stmt *Statement) CreatingFromQuery() bool {
+	_, ok := stmt.Settings.Load(createFromQueryKey)
+	return ok
+}
+
+// BuildLimited builds the clauses of an update or delete like Build, the ORDER BY and LIMIT of the statement which
+// can't be built as LIMIT isn't in clauses are rewritten into the condition of the primary keys selected by a
+// subquery, e.g:
+//
+//	DELETE FROM `events` WHERE `events`.`id` IN (SELECT `id` FROM (SELECT `events`.`id` FROM `events`
+//		WHERE created_at < ? ORDER BY created_at LIMIT ?) AS `gorm_limited`)
+func (stmt *Statement) BuildLimited(clauses ...string) {
+	if utils.Contains(clauses, "LIMIT") {
+		stmt.Build(clauses...)
+		return
+	}
+
+	cond, limited := stmt.limitedCondition()
+	if !limited {
+		stmt.Build(clauses...)
+		return
+	} else if cond == nil {
+		return
+	}
+
+	// the limited clauses are restored after built, as they might be checked after building, e.g: missing WHERE
+	limitedClauses := make(map[string]clause.Clause, 3)
+	for _, name := range []string{"WHERE", "ORDER BY", "LIMIT"} {
+		if c, ok := stmt.Clauses[name]; ok {
+			limitedClauses[name] = c
+			delete(stmt.Clauses, name)
+		}
+	}
+
+	stmt.AddClause(clause.Where{Exprs: []clause.Expression{cond}})
+	stmt.Build(clauses...)
+
+	delete(stmt.Clauses, "WHERE")
+	for name, c := range limitedClauses {
+		stmt.Clauses[name] = c
+	}
+}
+
+// limitedCondition returns the condition of the primary keys selected by the subquery of the WHERE, ORDER BY and
+// LIMIT of the statement, limited is false if the statement has no LIMIT, cond is nil if the model has no primary key
+func (stmt *Statement) limitedCondition() (cond clause.Expression, limited bool) {
+	limit, ok := stmt.Clauses["LIMIT"].Expression.(clause.Limit)
+	if !ok || ((limit.Limit == nil || *limit.Limit < 0) && limit.Offset <= 0) {
+		return nil, false
+	}
+
+	if stmt.Schema == nil || len(stmt.Schema.PrimaryFields) == 0 {
+		stmt.AddError(ErrPrimaryKeyRequired)
+		return nil, true
+	}
+
+	var (
+		primaryColumns = make([]interface{}, 0, len(stmt.Schema.PrimaryFields))
+		selectColumns  = make([]clause.Column, 0, len(stmt.Schema.PrimaryFields))
+		vars           = make([]interface{}, 0, len(stmt.Schema.PrimaryFields)+3)
+		subdb          = stmt.DB.Session(&Session{NewDB: true}).getInstance()
+	)
+
+	for _, field := range stmt.Schema.PrimaryFields {
+		primaryColumns = append(primaryColumns, clause.Column{Table: clause.CurrentTable, Name: field.DBName})
+		selectColumns = append(selectColumns, clause.Column{Table: clause.CurrentTable, Name: field.DBName})
+		vars = append(vars, clause.Column{Name: field.DBName})
+	}
+
+	subdb.Statement.Table = stmt.Table
+	subdb.Statement.TableExpr = stmt.TableExpr
+	subdb.Statement.AddClause(clause.Select{Columns: selectColumns})
+	for _, name := range []string{"WHERE", "ORDER BY", "LIMIT"} {
+		if c, ok := stmt.Clauses[name]; ok {
+			subdb.Statement.Clauses[name] = c
+		}
+	}
+
+	if len(primaryColumns) == 1 {
+		vars = append([]interface{}{primaryColumns[0]}, vars...)
+	} else {
+		vars = append([]interface{}{primaryColumns}, vars...)
+	}
+	vars = append(vars, subdb, clause.Table{Name: "gorm_limited"})
+
+	return clause.Expr{
+		SQL:  "? IN (SELECT " + strings.Repeat("?,", len(selectColumns)-1) + "? FROM (?) AS ?)",
+		Vars: vars,
+	}, true
 }
 
 func (stmt *Statement) Parse(value interface{}) (err error) {
@@ -490,7 +855,19 @@ func (stmt *Statement) Parse(value interface{}) (err error) {
 }
 
 func (stmt *Statement) ParseWithSpecialTableName(value interface{}, specialTableName string) (err error) {
-	if stmt.Schema, err = schema.ParseWithSpecialTableName(value, stmt.DB.cacheStore, stmt.DB.NamingStrategy, specialTableName); err == nil && stmt.Table == "" {
+	if ok, err := stmt.parseDynamicSchema(value, specialTableName); ok {
+		return err
+	}
+
+	if stmt.Schema, err = schema.ParseWithSpecialTableName(value, stmt.DB.cacheStore, stmt.DB.NamingStrategy, specialTableName); err == nil && stmt.DB.StrictTags {
+		err = stmt.Schema.TagError()
+	}
+
+	if err == nil && len(stmt.Schema.PostgresTypeFields) > 0 {
+		err = stmt.checkPostgresTypes()
+	}
+
+	if err == nil && stmt.Table == "" {
 		if tables := strings.Split(stmt.Schema.Table, "."); len(tables) == 2 {
 			stmt.TableExpr = &clause.Expr{SQL: stmt.Quote(stmt.Schema.Table)}
 			stmt.Table = tables[1]
@@ -502,31 +879,68 @@ func (stmt *Statement) ParseWithSpecialTableName(value interface{}, specialTable
 	return err
 }
 
-func (stmt *Statement) clone() *Statement {
-	newStmt := &Statement{
-		TableExpr:            stmt.TableExpr,
-		Table:                stmt.Table,
-		Model:                stmt.Model,
-		Unscoped:             stmt.Unscoped,
-		Dest:                 stmt.Dest,
-		ReflectValue:         stmt.ReflectValue,
-		Clauses:              map[string]clause.Clause{},
-		Distinct:             stmt.Distinct,
-		Selects:              stmt.Selects,
-		Omits:                stmt.Omits,
-		ColumnMapping:        stmt.ColumnMapping,
-		Preloads:             map[string][]interface{}{},
-		ConnPool:             stmt.ConnPool,
-		Schema:               stmt.Schema,
-		Context:              stmt.Context,
-		RaiseErrorOnNotFound: stmt.RaiseErrorOnNotFound,
-		SkipHooks:            stmt.SkipHooks,
+// checkPostgresTypes rejects the array, composite and interval fields used by the statement if the database doesn't
+// support CapabilityArrayTypes, the fields excluded by Select or Omit are not used, and binds the arrays as the slices
+// if the driver supports CapabilityDriverArrays
+func (stmt *Statement) checkPostgresTypes() error {
+	if !stmt.DB.Supports(CapabilityArrayTypes) {
+		selectColumns, restricted := stmt.SelectAndOmitColumns(false, false)
+		for _, field := range stmt.Schema.PostgresTypeFields {
+			if v, ok := selectColumns[field.DBName]; (ok && v) || (!ok && !restricted) {
+				return fmt.Errorf("%w: the %s type of %s.%s isn't supported by %s, which is only supported by postgres",
+					ErrUnsupportedDriver, field.DataType, stmt.Schema.Name, field.Name, stmt.DB.Dialector.Name())
+			}
+		}
+		return nil
 	}
 
+	if stmt.DB.Supports(CapabilityDriverArrays) {
+		ctx := stmt.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if !schema.DriverArrays(ctx) {
+			stmt.Context = schema.WithDriverArrays(ctx)
+		}
+	}
+	return nil
+}
+
+func (stmt *Statement) clone() *Statement {
+	return stmt.cloneTo(&Statement{Clauses: map[string]clause.Clause{}, Preloads: map[string][]interface{}{}})
+}
+
+// cloneTo clones the statement to newStmt, which should have empty Clauses and Preloads
+func (stmt *Statement) cloneTo(newStmt *Statement) *Statement {
+	newStmt.TableExpr = stmt.TableExpr
+	newStmt.Table = stmt.Table
+	newStmt.Model = stmt.Model
+	newStmt.Unscoped = stmt.Unscoped
+	newStmt.UnscopedTables = stmt.UnscopedTables
+	newStmt.Dest = stmt.Dest
+	newStmt.ReflectValue = stmt.ReflectValue
+	newStmt.Distinct = stmt.Distinct
+	newStmt.Selects = stmt.Selects
+	newStmt.Omits = stmt.Omits
+	newStmt.ColumnMapping = stmt.ColumnMapping
+	newStmt.ConnPool = stmt.ConnPool
+	newStmt.Schema = stmt.Schema
+	newStmt.Context = stmt.Context
+	newStmt.RaiseErrorOnNotFound = stmt.RaiseErrorOnNotFound
+	newStmt.SkipHooks = stmt.SkipHooks
+	newStmt.CacheKey = stmt.CacheKey
+	newStmt.ClauseBuilders = stmt.ClauseBuilders
+	newStmt.sqlStatements = stmt.sqlStatements
+
 	if stmt.SQL.Len() > 0 {
 		newStmt.SQL.WriteString(stmt.SQL.String())
-		newStmt.Vars = make([]interface{}, 0, len(stmt.Vars))
+		if newStmt.Vars == nil {
+			newStmt.Vars = make([]interface{}, 0, len(stmt.Vars))
+		}
 		newStmt.Vars = append(newStmt.Vars, stmt.Vars...)
+		if len(stmt.varBindings) > 0 {
+			newStmt.varBindings = append([]*VarBinding(nil), stmt.varBindings...)
+		}
 	}
 
 	for k, c := range stmt.Clauses {
@@ -542,11 +956,21 @@ func (stmt *Statement) clone() *Statement {
 		copy(newStmt.Joins, stmt.Joins)
 	}
 
+	if len(stmt.associationJSONs) > 0 {
+		newStmt.associationJSONs = make([]associationJSON, len(stmt.associationJSONs))
+		copy(newStmt.associationJSONs, stmt.associationJSONs)
+	}
+
 	if len(stmt.scopes) > 0 {
-		newStmt.scopes = make([]func(*DB) *DB, len(stmt.scopes))
+		newStmt.scopes = make([]scope, len(stmt.scopes))
 		copy(newStmt.scopes, stmt.scopes)
 	}
 
+	if len(stmt.AppliedScopes) > 0 {
+		newStmt.AppliedScopes = make([]string, len(stmt.AppliedScopes))
+		copy(newStmt.AppliedScopes, stmt.AppliedScopes)
+	}
+
 	stmt.Settings.Range(func(k, v interface{}) bool {
 		newStmt.Settings.Store(k, v)
 		return true
@@ -555,6 +979,21 @@ func (stmt *Statement) clone() *Statement {
 	return newStmt
 }
 
+// AuditUser returns the acting user extracted from the context by Config.AuditUserExtractor for the audit fields,
+// ErrMissingAuditUser is added to the statement if it isn't found when Config.StrictAudit is enabled
+func (stmt *Statement) AuditUser() (interface{}, bool) {
+	if stmt.DB.AuditUserExtractor != nil {
+		if user, ok := stmt.DB.AuditUserExtractor(stmt.Context); ok {
+			return user, true
+		}
+	}
+
+	if stmt.DB.StrictAudit {
+		stmt.AddError(ErrMissingAuditUser)
+	}
+	return nil, false
+}
+
 // SetColumn set column's value
 //
 //	stmt.SetColumn("Name", "jinzhu") // Hooks Method
@@ -583,7 +1022,7 @@ func (stmt *Statement) SetColumn(name string, value interface{}, fromCallbacks .
 
 				switch destValue.Kind() {
 				case reflect.Struct:
-					stmt.AddError(field.Set(stmt.Context, destValue, value))
+					stmt.AddError(stmt.setField(field, destValue, value))
 				default:
 					stmt.AddError(ErrInvalidData)
 				}
@@ -593,10 +1032,10 @@ func (stmt *Statement) SetColumn(name string, value interface{}, fromCallbacks .
 			case reflect.Slice, reflect.Array:
 				if len(fromCallbacks) > 0 {
 					for i := 0; i < stmt.ReflectValue.Len(); i++ {
-						stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue.Index(i), value))
+						stmt.AddError(stmt.setField(field, stmt.ReflectValue.Index(i), value))
 					}
 				} else {
-					stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue.Index(stmt.CurDestIndex), value))
+					stmt.AddError(stmt.setField(field, stmt.ReflectValue.Index(stmt.CurDestIndex), value))
 				}
 			case reflect.Struct:
 				if !stmt.ReflectValue.CanAddr() {
@@ -604,7 +1043,7 @@ func (stmt *Statement) SetColumn(name string, value interface{}, fromCallbacks .
 					return
 				}
 
-				stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue, value))
+				stmt.AddError(stmt.setField(field, stmt.ReflectValue, value))
 			}
 		} else {
 			stmt.AddError(ErrInvalidField)
@@ -614,6 +1053,75 @@ func (stmt *Statement) SetColumn(name string, value interface{}, fromCallbacks .
 	}
 }
 
+// UpdateColumns returns the assignments of the update statement, resolved from Dest with Select/Omit applied.
+// They are available from Before* hooks and only resolved when called, changes made by hooks to Dest after calling
+// it take effect when building the statement, the returned assignments will be re-resolved then.
+func (stmt *Statement) UpdateColumns() []clause.Assignment {
+	if c, ok := stmt.Clauses["SET"]; ok {
+		if set, ok := c.Expression.(clause.Set); ok {
+			return set
+		}
+	}
+
+	if v, ok := stmt.Settings.Load(fmt.Sprintf("%p", stmt) + "gorm:update_columns"); ok {
+		switch v := v.(type) {
+		case clause.Set:
+			return v
+		case func() clause.Set:
+			return v()
+		}
+	}
+	return nil
+}
+
+// ConditionsSummary returns the WHERE conditions of the statement, before the statement is built, the primary key
+// conditions of the model that will be added when building update or delete statements are included as well.
+func (stmt *Statement) ConditionsSummary() []clause.Expression {
+	var exprs []clause.Expression
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			exprs = append(exprs, where.Exprs...)
+		}
+	}
+
+	if _, built := stmt.Settings.Load(fmt.Sprintf("%p", stmt) + "gorm:conditions_built"); !built && stmt.Schema != nil && stmt.ReflectValue.IsValid() {
+		_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+		column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+		if len(values) > 0 {
+			exprs = append(exprs, clause.IN{Column: column, Values: values})
+		}
+	}
+	return exprs
+}
+
+// collatedColumn returns the column of the map conditions, which is compared with the collation of the field of the
+// model tagged with `queryCollate`, the model should be specified before the conditions, e.g:
+//
+//	db.Model(&User{}).Where(map[string]interface{}{"email": email}).First(&user)
+func (stmt *Statement) collatedColumn(column string) interface{} {
+	sch := stmt.Schema
+	if sch == nil && stmt.Model != nil {
+		if s, err := schema.Parse(stmt.Model, stmt.DB.cacheStore, stmt.DB.NamingStrategy); err == nil {
+			sch = s
+		}
+	}
+
+	if sch != nil && !strings.Contains(column, ".") {
+		if field := sch.LookUpField(column); field != nil {
+			return collateColumn(field, column)
+		}
+	}
+	return column
+}
+
+// collateColumn returns the column compared with the collation of field if it's tagged with `queryCollate`
+func collateColumn(field *schema.Field, column interface{}) interface{} {
+	if field.QueryCollate != "" {
+		return clause.Collate{Column: column, Collation: field.QueryCollate}
+	}
+	return column
+}
+
 // Changed check model changed or not when updating
 func (stmt *Statement) Changed(fields ...string) bool {
 	modelValue := stmt.ReflectValue
@@ -626,11 +1134,12 @@ func (stmt *Statement) Changed(fields ...string) bool {
 	changed := func(field *schema.Field) bool {
 		fieldValue, _ := field.ValueOf(stmt.Context, modelValue)
 		if v, ok := selectColumns[field.DBName]; (ok && v) || (!ok && !restricted) {
+			// the fields tagged with `skipDiff` are changed if they are updated, without comparing
 			if mv, mok := stmt.Dest.(map[string]interface{}); mok {
 				if fv, ok := mv[field.Name]; ok {
-					return !utils.AssertEqual(fv, fieldValue)
+					return field.SkipDiff || !utils.AssertEqual(fv, fieldValue)
 				} else if fv, ok := mv[field.DBName]; ok {
-					return !utils.AssertEqual(fv, fieldValue)
+					return field.SkipDiff || !utils.AssertEqual(fv, fieldValue)
 				}
 			} else {
 				destValue := reflect.ValueOf(stmt.Dest)
@@ -640,9 +1149,9 @@ func (stmt *Statement) Changed(fields ...string) bool {
 
 				changedValue, zero := field.ValueOf(stmt.Context, destValue)
 				if v {
-					return !utils.AssertEqual(changedValue, fieldValue)
+					return field.SkipDiff || !utils.AssertEqual(changedValue, fieldValue)
 				}
-				return !zero && !utils.AssertEqual(changedValue, fieldValue)
+				return !zero && (field.SkipDiff || !utils.AssertEqual(changedValue, fieldValue))
 			}
 		}
 		return false
@@ -742,3 +1251,70 @@ func (stmt *Statement) SelectAndOmitColumns(requireCreate, requireUpdate bool) (
 
 	return results, !notRestricted && len(stmt.Selects) > 0
 }
+
+// sortConditionKeys sorts the keys of map[interface{}]interface{} conditions by their column names, then by their types,
+// so the conditions are built in the same order
+func sortConditionKeys(keys []interface{}) {
+	names := make(map[interface{}]string, len(keys))
+	for _, key := range keys {
+		switch k := key.(type) {
+		case string:
+			names[key] = k
+		case clause.Column:
+			if k.Table != "" {
+				names[key] = k.Table + "." + k.Name
+			} else {
+				names[key] = k.Name
+			}
+		default:
+			names[key] = fmt.Sprint(key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if names[keys[i]] != names[keys[j]] {
+			return names[keys[i]] < names[keys[j]]
+		}
+		return fmt.Sprintf("%T", keys[i]) < fmt.Sprintf("%T", keys[j])
+	})
+}
+
+// lookUpColumn returns the column of name, which is a field or a column of the model, or a qualified column of the
+// joined tables, e.g: `companies.name`, ErrInvalidField is added if it isn't a column
+func (stmt *Statement) lookUpColumn(name string) (clause.Column, bool) {
+	if stmt.Schema != nil {
+		if field := stmt.Schema.LookUpField(name); field != nil && field.DBName != "" {
+			return clause.Column{Table: clause.CurrentTable, Name: field.DBName}, true
+		}
+	}
+
+	if column, ok := clause.ParseIdentifier(name); ok && (stmt.Schema == nil || column.Table != "") {
+		return column, true
+	}
+
+	if stmt.Schema != nil {
+		stmt.AddError(fmt.Errorf("%w: %s isn't a column of %s", ErrInvalidField, name, stmt.Schema.Name))
+	} else {
+		stmt.AddError(fmt.Errorf("%w: %s isn't a column", ErrInvalidField, name))
+	}
+	return clause.Column{}, false
+}
+
+// ReportEmptyIN implements clause.EmptyINReporter, the IN condition of column without values is logged at Info, or
+// ErrEmptyInCondition is added if Config.ErrorOnEmptyIN is enabled, column is nil for the empty slices of raw SQL
+func (stmt *Statement) ReportEmptyIN(column interface{}) {
+	condition := "IN"
+	if column != nil {
+		condition = stmt.Quote(column) + " IN"
+	}
+	stmt.reportEmptyIN(condition)
+}
+
+func (stmt *Statement) reportEmptyIN(condition string) {
+	stmt.emptyINs = append(stmt.emptyINs, condition)
+	if stmt.DB.ErrorOnEmptyIN {
+		stmt.AddError(fmt.Errorf("%w: %s", ErrEmptyInCondition, condition))
+	} else {
+		stmt.DB.Logger.Info(stmt.Context, "%s condition without values", condition)
+	}
+}
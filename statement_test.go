@@ -1,9 +1,15 @@
 package gorm
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"unsafe"
 
 	"gorm.io/gorm/clause"
 )
@@ -68,3 +74,66 @@ func TestNameMatcher(t *testing.T) {
 		}
 	}
 }
+
+func TestReleasedStatementIsClean(t *testing.T) {
+	stmt := acquireStatement()
+
+	// every field is set, so the fields added later fail the test until they are reset
+	values := []interface{}{1, "value", context.Background(), new(sql.DB), driver.RowsAffected(1)}
+	rv := reflect.ValueOf(stmt).Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		name, field := rv.Type().Field(i).Name, rv.Field(i)
+		field = reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+
+		switch field.Kind() {
+		case reflect.Bool:
+			field.SetBool(true)
+		case reflect.Int, reflect.Int64, reflect.Uint8:
+			field.Set(reflect.ValueOf(1).Convert(field.Type()))
+		case reflect.String:
+			field.SetString(name)
+		case reflect.Ptr:
+			field.Set(reflect.New(field.Type().Elem()))
+		case reflect.Map:
+			field.Set(reflect.MakeMap(field.Type()))
+			field.SetMapIndex(reflect.ValueOf(name), reflect.Zero(field.Type().Elem()))
+		case reflect.Slice:
+			field.Set(reflect.MakeSlice(field.Type(), 1, 1))
+		case reflect.Interface:
+			for _, value := range values {
+				if reflect.TypeOf(value).AssignableTo(field.Type()) {
+					field.Set(reflect.ValueOf(value))
+					break
+				}
+			}
+		case reflect.Struct:
+			switch v := field.Addr().Interface().(type) {
+			case *sync.Map:
+				v.Store(name, name)
+			case *strings.Builder:
+				v.WriteString(name)
+			case *reflect.Value:
+				*v = reflect.ValueOf(name)
+			}
+		}
+
+		if field.IsZero() {
+			t.Fatalf("field %s of Statement isn't set by the test", name)
+		}
+	}
+	stmt.release()
+
+	for i := 0; i < rv.NumField(); i++ {
+		name, field := rv.Type().Field(i).Name, rv.Field(i)
+		switch name {
+		case "Clauses", "Preloads", "Vars":
+			if field.Len() != 0 {
+				t.Errorf("field %s of the released statement should be empty, got %v", name, field.Len())
+			}
+		default:
+			if !field.IsZero() {
+				t.Errorf("field %s of the released statement should be reset", name)
+			}
+		}
+	}
+}
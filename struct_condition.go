@@ -0,0 +1,161 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// StructCondition the conditions of the fields of a struct, created by Cond
+type StructCondition struct {
+	Value   interface{}
+	Options []CondOption
+}
+
+// CondOption the option of Cond, see IncludeZero, ExcludeFields and CondOperator
+type CondOption func(*condConfig)
+
+type condConfig struct {
+	includeZero []string
+	exclude     []string
+	operators   map[string]string
+}
+
+// IncludeZero includes the zero values of the fields in the conditions, the fields are the names or the column names
+func IncludeZero(fields ...string) CondOption {
+	return func(config *condConfig) {
+		config.includeZero = append(config.includeZero, fields...)
+	}
+}
+
+// ExcludeFields excludes the fields from the conditions, the fields are the names or the column names
+func ExcludeFields(fields ...string) CondOption {
+	return func(config *condConfig) {
+		config.exclude = append(config.exclude, fields...)
+	}
+}
+
+// CondOperator compares the field with operator instead of =, which could be =, <>, !=, >, >=, <, <=, LIKE, NOT LIKE, IN
+// and NOT IN
+func CondOperator(field string, operator string) CondOption {
+	return func(config *condConfig) {
+		if config.operators == nil {
+			config.operators = map[string]string{}
+		}
+		config.operators[field] = operator
+	}
+}
+
+// Cond converts the non-zero fields of the struct to conditions like Where(&User{}), the fields are looked up like the
+// schema, so the embedded fields and the renamed columns work, the zero fields could be included with IncludeZero, e.g:
+// the filters of API requests
+//
+//	// WHERE `users`.`name` LIKE "%jin%" AND `users`.`age` = 0
+//	db.Where(gorm.Cond(&User{Name: "%jin%"}, gorm.IncludeZero("Age"), gorm.CondOperator("Name", "LIKE"))).Find(&users)
+func Cond(value interface{}, opts ...CondOption) StructCondition {
+	return StructCondition{Value: value, Options: opts}
+}
+
+// buildStructCondition builds the conditions of the fields of the struct
+func (stmt *Statement) buildStructCondition(cond StructCondition) (conds []clause.Expression) {
+	s, err := schema.Parse(cond.Value, stmt.DB.cacheStore, stmt.DB.NamingStrategy)
+	if err != nil {
+		stmt.AddError(err)
+		return nil
+	}
+
+	reflectValue := reflect.Indirect(reflect.ValueOf(cond.Value))
+	if reflectValue.Kind() != reflect.Struct {
+		stmt.AddError(fmt.Errorf("%w: conditions of %v, which should be a struct", ErrInvalidData, reflectValue.Type()))
+		return nil
+	}
+
+	var config condConfig
+	for _, opt := range cond.Options {
+		opt(&config)
+	}
+
+	lookUpFields := func(names []string) map[*schema.Field]bool {
+		fields := make(map[*schema.Field]bool, len(names))
+		for _, name := range names {
+			field := s.LookUpField(name)
+			if field == nil {
+				field = s.FieldsByBindName[name]
+			}
+
+			if field == nil {
+				stmt.AddError(fmt.Errorf("%w: %s of %s", ErrInvalidField, name, s.Name))
+				continue
+			}
+			fields[field] = true
+		}
+		return fields
+	}
+
+	var (
+		includeZero = lookUpFields(config.includeZero)
+		excluded    = lookUpFields(config.exclude)
+		operators   = make(map[*schema.Field]string, len(config.operators))
+	)
+	for name, operator := range config.operators {
+		for field := range lookUpFields([]string{name}) {
+			operators[field] = strings.ToUpper(strings.TrimSpace(operator))
+		}
+	}
+
+	for _, field := range s.Fields {
+		if excluded[field] || !(field.Readable || includeZero[field]) || (field.DBName == "" && field.DataType == "") {
+			continue
+		}
+
+		v, isZero := field.ValueOf(stmt.Context, reflectValue)
+		if isZero && !includeZero[field] {
+			continue
+		}
+
+		column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+		if field.DBName == "" {
+			column.Name = field.Name
+		}
+
+		switch operator := operators[field]; operator {
+		case "", "=":
+			conds = append(conds, clause.Eq{Column: collateColumn(field, column), Value: v})
+		case "<>", "!=":
+			conds = append(conds, clause.Neq{Column: collateColumn(field, column), Value: v})
+		case ">":
+			conds = append(conds, clause.Gt{Column: column, Value: v})
+		case ">=":
+			conds = append(conds, clause.Gte{Column: column, Value: v})
+		case "<":
+			conds = append(conds, clause.Lt{Column: column, Value: v})
+		case "<=":
+			conds = append(conds, clause.Lte{Column: column, Value: v})
+		case "LIKE":
+			conds = append(conds, clause.Like{Column: collateColumn(field, column), Value: v})
+		case "NOT LIKE":
+			conds = append(conds, clause.Not(clause.Like{Column: collateColumn(field, column), Value: v}))
+		case "IN", "NOT IN":
+			var values []interface{}
+			if rv := reflect.Indirect(reflect.ValueOf(v)); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+				for i := 0; i < rv.Len(); i++ {
+					values = append(values, rv.Index(i).Interface())
+				}
+			} else {
+				values = []interface{}{v}
+			}
+
+			if operator == "IN" {
+				conds = append(conds, clause.IN{Column: column, Values: values})
+			} else {
+				conds = append(conds, clause.Not(clause.IN{Column: column, Values: values}))
+			}
+		default:
+			stmt.AddError(fmt.Errorf("%w: operator %s of %s.%s", ErrInvalidData, operator, s.Name, field.Name))
+		}
+	}
+	return conds
+}
@@ -0,0 +1,71 @@
+package gorm
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/clause"
+)
+
+const withoutTenancyKey = "gorm:without_tenancy"
+
+// WithoutTenancy disables the tenant conditions and the tenant population of the models having a tenant field, it's
+// logged at Warn as it could access the data of all tenants, e.g:
+//
+//	db.WithoutTenancy().Where("expired_at < ?", time.Now()).Delete(&Session{})
+func (db *DB) WithoutTenancy() (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.Settings.Store(withoutTenancyKey, true)
+	tx.Logger.Warn(tx.Statement.Context, "tenancy disabled with WithoutTenancy")
+	return
+}
+
+// Tenant returns the tenant resolved by Config.TenantResolver for the model having a field tagged with `tenant`,
+// ok is false if the model doesn't have the field or the tenancy is disabled by WithoutTenancy, ErrMissingTenant is
+// added to the statement if the tenant isn't resolved
+func (stmt *Statement) Tenant() (tenant interface{}, ok bool) {
+	if stmt.Schema == nil || stmt.Schema.TenantField == nil {
+		return nil, false
+	}
+
+	if _, disabled := stmt.Settings.Load(withoutTenancyKey); disabled {
+		return nil, false
+	}
+
+	if stmt.DB.TenantResolver == nil {
+		stmt.AddError(fmt.Errorf("%w: TenantResolver isn't configured for %s", ErrMissingTenant, stmt.Schema.Name))
+		return nil, false
+	}
+
+	tenant, err := stmt.DB.TenantResolver(stmt.Context)
+	if err != nil {
+		stmt.AddError(err)
+		return nil, false
+	} else if tenant == nil {
+		stmt.AddError(fmt.Errorf("%w: %s", ErrMissingTenant, stmt.Schema.Name))
+		return nil, false
+	}
+	return tenant, true
+}
+
+// AddTenantCondition restricts the statement to the rows of the tenant, the query, update and delete callbacks call
+// it for the models having a tenant field
+func (stmt *Statement) AddTenantCondition() {
+	if _, ok := stmt.Clauses["tenant_enabled"]; ok {
+		return
+	}
+
+	if cond, ok := stmt.tenantCondition(); ok {
+		groupOrConditions(stmt)
+		stmt.AddClause(clause.Where{Exprs: []clause.Expression{cond}})
+		stmt.Clauses["tenant_enabled"] = clause.Clause{}
+	}
+}
+
+// tenantCondition returns the condition matching the rows of the tenant, ok is false if the statement isn't restricted
+func (stmt *Statement) tenantCondition() (cond clause.Expression, ok bool) {
+	tenant, ok := stmt.Tenant()
+	if !ok {
+		return nil, false
+	}
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: stmt.Schema.TenantField.DBName}, Value: tenant}, true
+}
@@ -0,0 +1,100 @@
+package tests_test
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestAggregate(t *testing.T) {
+	users := []*User{
+		GetUser("aggregate_1", Config{}),
+		GetUser("aggregate_2", Config{}),
+		GetUser("aggregate_3", Config{}),
+		GetUser("aggregate_4", Config{}),
+	}
+	for i, user := range users {
+		user.Age = uint(10 * (i + 1))
+		birthday := time.Date(2000+i, 1, 2, 3, 4, 5, 0, time.UTC)
+		user.Birthday = &birthday
+	}
+	DB.Create(&users)
+	DB.Delete(users[3])
+
+	ids := []uint{users[0].ID, users[1].ID, users[2].ID, users[3].ID}
+	tx := DB.Model(&User{}).Where("id IN ?", ids).Session(&gorm.Session{})
+
+	var maxAge, minAge uint
+	if err := tx.Max("age", &maxAge).Error; err != nil || maxAge != 30 {
+		t.Errorf("the max age should be 30 without the deleted user, got %v, error %v", maxAge, err)
+	}
+	if err := tx.Min("Age", &minAge).Error; err != nil || minAge != 10 {
+		t.Errorf("the min age should be 10, got %v, error %v", minAge, err)
+	}
+
+	var sum int64
+	if err := tx.SumOf("age", &sum).Error; err != nil || sum != 60 {
+		t.Errorf("the sum of ages should be 60, got %v, error %v", sum, err)
+	}
+
+	var avg float64
+	if err := tx.AvgOf("age", &avg).Error; err != nil || avg != 20 {
+		t.Errorf("the average age should be 20, got %v, error %v", avg, err)
+	}
+
+	var unscopedMax int
+	if err := tx.Unscoped().Max("age", &unscopedMax).Error; err != nil || unscopedMax != 40 {
+		t.Errorf("the unscoped max age should be 40, got %v, error %v", unscopedMax, err)
+	}
+
+	var lastBirthday time.Time
+	if err := tx.Order("id").Max("birthday", &lastBirthday).Error; err != nil || !lastBirthday.Equal(*users[2].Birthday) {
+		t.Errorf("the max birthday should be %v, got %v, error %v", users[2].Birthday, lastBirthday, err)
+	}
+
+	var firstBirthday *time.Time
+	if err := tx.Min("birthday", &firstBirthday).Error; err != nil || firstBirthday == nil || !firstBirthday.Equal(*users[0].Birthday) {
+		t.Errorf("the min birthday should be %v, got %v, error %v", users[0].Birthday, firstBirthday, err)
+	}
+
+	var nullAge sql.NullInt64
+	if err := DB.Model(&User{}).Where("name = ?", "aggregate_none").Max("age", &nullAge).Error; err != nil || nullAge.Valid {
+		t.Errorf("the max age of no rows should be NULL, got %v, error %v", nullAge, err)
+	}
+
+	maxAge, firstBirthday = 100, users[0].Birthday
+	result := DB.Model(&User{}).Where("name = ?", "aggregate_none").Max("age", &maxAge)
+	if result.Error != nil || result.RowsAffected != 0 || maxAge != 0 {
+		t.Errorf("the max age of no rows should be zero, got %v, rows affected %v, error %v", maxAge, result.RowsAffected, result.Error)
+	}
+	if err := DB.Model(&User{}).Where("name = ?", "aggregate_none").Max("birthday", &firstBirthday).Error; err != nil || firstBirthday != nil {
+		t.Errorf("the max birthday of no rows should be nil, got %v, error %v", firstBirthday, err)
+	}
+
+	if err := DB.Model(&User{}).Where("name = ?", "aggregate_none").SumOf("age", &sum, gorm.AggregateRequired()).Error; !errors.Is(err, gorm.ErrNullAggregate) {
+		t.Errorf("should return ErrNullAggregate, got %v", err)
+	}
+
+	if err := tx.Max("unknown", &maxAge).Error; !errors.Is(err, gorm.ErrInvalidField) {
+		t.Errorf("should return ErrInvalidField for the unknown column, got %v", err)
+	}
+
+	if err := tx.Group("name").Max("age", &maxAge).Error; !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("should return ErrInvalidData for the grouped rows, got %v", err)
+	}
+
+	var companyMax uint
+	if err := DB.Model(&User{}).Joins("Company").Where("users.id IN ?", ids).Max("users.age", &companyMax).Error; err != nil || companyMax != 30 {
+		t.Errorf("the max age with joins should be 30, got %v, error %v", companyMax, err)
+	}
+
+	result = DB.Session(&gorm.Session{DryRun: true}).Model(&User{}).Where("name = ?", "jinzhu").Max("age", &maxAge)
+	if sql := result.Statement.SQL.String(); !regexp.MustCompile(`SELECT MAX\(.users.\..age.\) FROM .users. WHERE name = .+ AND .users.\..deleted_at. IS NULL`).MatchString(sql) {
+		t.Errorf("invalid max sql, got %v", sql)
+	}
+}
@@ -0,0 +1,88 @@
+package tests_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestSelectAssociationJSON(t *testing.T) {
+	if name := DB.Dialector.Name(); name != "sqlite" && name != "postgres" && name != "mysql" {
+		t.Skip("json aggregation isn't supported by " + name)
+	}
+
+	users := []*User{
+		GetUser("association_json_1", Config{Account: true, Pets: 2, Toys: 2, Company: true, Manager: true, Team: 1, Languages: 2}),
+		GetUser("association_json_2", Config{}),
+	}
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("failed to create users, got error %v", err)
+	}
+
+	var results []User
+	if err := DB.Model(&User{}).SelectAssociationJSON("Account", "account_json").
+		SelectAssociationJSON("Pets", "pets_json").SelectAssociationJSON("Toys", "toys_json").
+		SelectAssociationJSON("Company", "company_json").SelectAssociationJSON("Manager", "manager_json").
+		SelectAssociationJSON("Team", "team_json").SelectAssociationJSON("Languages", "languages_json").
+		Where("id IN ?", []uint{users[0].ID, users[1].ID}).Order("id").Find(&results).Error; err != nil {
+		t.Fatalf("failed to find users, got error %v", err)
+	}
+
+	if len(results) != 2 || results[0].Name != "association_json_1" || results[1].Name != "association_json_2" {
+		t.Fatalf("users should be found, got %+v", results)
+	}
+
+	result, user := results[0], users[0]
+	AssertEqual(t, result.Account.Number, user.Account.Number)
+	AssertEqual(t, result.Account.ID, user.Account.ID)
+	if len(result.Pets) != 2 || len(result.Toys) != 2 || len(result.Team) != 1 || len(result.Languages) != 2 {
+		t.Fatalf("the has many and many to many associations should be unmarshalled, got %+v", result)
+	}
+
+	sort.Slice(result.Pets, func(i, j int) bool { return result.Pets[i].ID < result.Pets[j].ID })
+	for idx, pet := range result.Pets {
+		if pet.ID != user.Pets[idx].ID || pet.Name != user.Pets[idx].Name || pet.UserID == nil || *pet.UserID != user.ID {
+			t.Errorf("pet should be unmarshalled, expects %+v, got %+v", user.Pets[idx], pet)
+		}
+	}
+
+	for _, toy := range result.Toys {
+		if toy.OwnerType != "users" {
+			t.Errorf("toy should be selected by the polymorphic type, got %+v", toy)
+		}
+	}
+
+	AssertEqual(t, result.Company.Name, user.Company.Name)
+	if result.Manager == nil || result.Manager.ID != user.Manager.ID || result.Manager.Name != user.Manager.Name {
+		t.Errorf("manager should be unmarshalled, got %+v", result.Manager)
+	}
+	AssertEqual(t, result.Team[0].Name, user.Team[0].Name)
+
+	sort.Slice(result.Languages, func(i, j int) bool { return result.Languages[i].Code < result.Languages[j].Code })
+	AssertEqual(t, result.Languages, user.Languages)
+
+	empty := results[1]
+	if empty.Pets == nil || len(empty.Pets) != 0 || empty.Languages == nil || len(empty.Languages) != 0 ||
+		empty.Manager != nil || empty.Account.ID != 0 || empty.Company.ID != 0 {
+		t.Errorf("the parent without children should get the empty associations, got %+v", empty)
+	}
+
+	DB.Delete(&users[0].Pets[0])
+	var found User
+	if err := DB.Select("id", "name").SelectAssociationJSON("Pets", "pets_json").First(&found, users[0].ID).Error; err != nil {
+		t.Fatalf("failed to find user, got error %v", err)
+	}
+
+	if found.Name != user.Name || found.Age != 0 || len(found.Pets) != 2 {
+		t.Errorf("the association should be selected with the selected columns, got %+v", found)
+	} else if !found.Pets[0].DeletedAt.Valid && !found.Pets[1].DeletedAt.Valid {
+		t.Errorf("the soft deleted pet should be selected with its DeletedAt, got %+v", found.Pets)
+	}
+
+	if err := DB.SelectAssociationJSON("Petz", "pets_json").Find(&[]User{}).Error; !errors.Is(err, gorm.ErrUnsupportedRelation) {
+		t.Errorf("should return ErrUnsupportedRelation for the unknown association, got %v", err)
+	}
+}
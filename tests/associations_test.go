@@ -1,10 +1,14 @@
 package tests_test
 
 import (
+	"bytes"
+	"log"
+	"regexp"
 	"testing"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
 	. "gorm.io/gorm/utils/tests"
 )
@@ -394,3 +398,69 @@ func TestAssociationEmptyPrimaryKey(t *testing.T) {
 
 	AssertEqual(t, result, user)
 }
+
+func TestAssociationFindWithChain(t *testing.T) {
+	user := *GetUser("association_chain", Config{Pets: 3, Languages: 3, Company: true})
+	if err := DB.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	tx := DB.Session(&gorm.Session{Logger: logger.New(log.New(&buf, "", 0), logger.Config{LogLevel: logger.Info})})
+	assertSQL := func(pattern string) {
+		t.Helper()
+		if !regexp.MustCompile(pattern).MatchString(buf.String()) {
+			t.Errorf("sql should match %v, got %v", pattern, buf.String())
+		}
+		buf.Reset()
+	}
+
+	// has many
+	var pets []Pet
+	if err := tx.Model(&user).Order("name desc").Limit(2).Offset(1).Association("Pets").Find(&pets); err != nil {
+		t.Fatalf("failed to find pets, got %v", err)
+	}
+	AssertEqual(t, len(pets), 2)
+	AssertEqual(t, pets[0].Name, "association_chain_pet_2")
+	AssertEqual(t, pets[1].Name, "association_chain_pet_1")
+	assertSQL("FROM .pets. WHERE .pets.\\..user_id. = \\d+ AND .pets.\\..deleted_at. IS NULL ORDER BY name desc LIMIT 2 OFFSET 1")
+
+	if count := tx.Model(&user).Where("name <> ?", "association_chain_pet_1").Limit(2).Offset(1).Association("Pets").Count(); count != 2 {
+		t.Errorf("limit and offset shouldn't be counted, got %v", count)
+	}
+	assertSQL("SELECT count\\(\\*\\) FROM .pets. WHERE name <> .association_chain_pet_1. AND .pets.\\..user_id. = \\d+ AND .pets.\\..deleted_at. IS NULL\n")
+
+	association := tx.Model(&user).Association("Pets").Scopes(func(db *gorm.DB) *gorm.DB {
+		return db.Order("name").Limit(1)
+	})
+	if err := association.Find(&pets); err != nil || len(pets) != 1 || pets[0].Name != "association_chain_pet_1" {
+		t.Errorf("scopes should be applied to the associations, got %+v, %v", pets, err)
+	}
+	if count := association.Count(); count != 3 {
+		t.Errorf("limit of the scopes shouldn't be counted, got %v", count)
+	}
+	buf.Reset()
+
+	// many2many
+	var languages []Language
+	if err := tx.Model(&user).Select("Code", "name").Order("code desc").Limit(2).Association("Languages").Find(&languages); err != nil {
+		t.Fatalf("failed to find languages, got %v", err)
+	}
+	AssertEqual(t, languages, []Language{user.Languages[2], user.Languages[1]})
+	assertSQL("SELECT .languages.\\..code.,.languages.\\..name. FROM .languages. JOIN .user_speaks. ON .+ ORDER BY code desc LIMIT 2\n")
+
+	if count := tx.Model(&user).Where("languages.code <> ?", user.Languages[0].Code).Order("code").Limit(1).Association("Languages").Count(); count != 2 {
+		t.Errorf("limit shouldn't be counted, got %v", count)
+	}
+	assertSQL("SELECT count\\(\\*\\) FROM .languages. JOIN .user_speaks. ON .+ WHERE languages.code <> .association_chain_locale_1.\n")
+
+	// belongs to
+	var company Company
+	if err := tx.Model(&user).Select("name").Association("Company").Find(&company); err != nil {
+		t.Fatalf("failed to find company, got %v", err)
+	}
+	if company.ID != 0 || company.Name != user.Company.Name {
+		t.Errorf("only the selected columns should be queried, got %+v", company)
+	}
+	assertSQL("SELECT .name. FROM .companies. WHERE .companies.\\..id. = \\d+")
+}
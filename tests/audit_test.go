@@ -0,0 +1,104 @@
+package tests_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type AuditPost struct {
+	ID        uint
+	Title     string
+	CreatedBy uint `gorm:"autoCreatedBy"`
+	UpdatedBy uint `gorm:"autoUpdatedBy"`
+	DeletedBy uint `gorm:"autoDeletedBy"`
+	DeletedAt gorm.DeletedAt
+}
+
+type AuditNote struct {
+	ID        uint
+	Content   string
+	CreatedBy string `gorm:"autoCreatedBy"`
+	UpdatedBy string `gorm:"autoUpdatedBy"`
+}
+
+type auditUserKey struct{}
+
+func TestAuditFields(t *testing.T) {
+	DB.Migrator().DropTable(&AuditPost{}, &AuditNote{})
+	if err := DB.AutoMigrate(&AuditPost{}, &AuditNote{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	db := DB.Session(&gorm.Session{})
+	db.Config.AuditUserExtractor = func(ctx context.Context) (interface{}, bool) {
+		user := ctx.Value(auditUserKey{})
+		return user, user != nil
+	}
+	withUser := func(user interface{}) *gorm.DB {
+		return db.WithContext(context.WithValue(context.Background(), auditUserKey{}, user))
+	}
+
+	// batch insert
+	posts := []AuditPost{{Title: "post-1"}, {Title: "post-2", CreatedBy: 9}}
+	if err := withUser(uint(1)).Create(&posts).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+	AssertEqual(t, posts[0].CreatedBy, uint(1))
+	AssertEqual(t, posts[0].UpdatedBy, uint(1))
+	AssertEqual(t, posts[1].CreatedBy, uint(9))
+
+	var result AuditPost
+	db.First(&result, posts[0].ID)
+	AssertEqual(t, result.CreatedBy, uint(1))
+	AssertEqual(t, result.UpdatedBy, uint(1))
+
+	// map update adds the column to the SET clause
+	if err := withUser(uint(2)).Model(&posts[0]).Update("title", "post-1-updated").Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+	AssertEqual(t, posts[0].UpdatedBy, uint(2))
+	result = AuditPost{}
+	db.First(&result, posts[0].ID)
+	AssertEqual(t, result.UpdatedBy, uint(2))
+	AssertEqual(t, result.CreatedBy, uint(1))
+
+	// struct update
+	withUser(uint(3)).Model(&posts[1]).Updates(AuditPost{Title: "post-2-updated"})
+	result = AuditPost{}
+	db.First(&result, posts[1].ID)
+	AssertEqual(t, result.UpdatedBy, uint(3))
+
+	// missing user leaves the fields untouched
+	db.Model(&posts[1]).Update("title", "post-2-anonymous")
+	result = AuditPost{}
+	db.First(&result, posts[1].ID)
+	AssertEqual(t, result.UpdatedBy, uint(3))
+
+	if err := withUser(uint(4)).Delete(&posts[0]).Error; err != nil {
+		t.Fatalf("failed to delete, got error %v", err)
+	}
+	result = AuditPost{}
+	db.Unscoped().First(&result, posts[0].ID)
+	AssertEqual(t, result.DeletedBy, uint(4))
+
+	// string ids
+	note := AuditNote{Content: "note"}
+	withUser("jinzhu").Create(&note)
+	withUser("admin").Model(&note).Updates(map[string]interface{}{"content": "updated"})
+	var noteResult AuditNote
+	db.First(&noteResult, note.ID)
+	AssertEqual(t, noteResult.CreatedBy, "jinzhu")
+	AssertEqual(t, noteResult.UpdatedBy, "admin")
+
+	db.Config.StrictAudit = true
+	if err := db.Create(&AuditNote{Content: "strict"}).Error; !errors.Is(err, gorm.ErrMissingAuditUser) {
+		t.Errorf("expected ErrMissingAuditUser, got %v", err)
+	}
+	if err := withUser("jinzhu").Create(&AuditNote{Content: "strict"}).Error; err != nil {
+		t.Errorf("failed to create with the audit user, got error %v", err)
+	}
+}
@@ -3,16 +3,51 @@ package tests_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
+	"gorm.io/gorm"
 	. "gorm.io/gorm/utils/tests"
 )
 
 func BenchmarkCreate(b *testing.B) {
-	user := *GetUser("bench", Config{})
+	benchmarkStatementPool(b, func(b *testing.B, db *gorm.DB) {
+		user := *GetUser("bench", Config{})
+
+		for x := 0; x < b.N; x++ {
+			user.ID = 0
+			db.Create(&user)
+		}
+	})
+}
 
-	for x := 0; x < b.N; x++ {
-		user.ID = 0
-		DB.Create(&user)
+func BenchmarkSelectSimple(b *testing.B) {
+	user := *GetUser("select_simple", Config{})
+	DB.Create(&user)
+
+	benchmarkStatementPool(b, func(b *testing.B, db *gorm.DB) {
+		var result User
+		for x := 0; x < b.N; x++ {
+			db.First(&result, user.ID)
+		}
+	})
+}
+
+// benchmarkStatementPool runs fc with statement pool enabled and disabled to compare allocations
+func benchmarkStatementPool(b *testing.B, fc func(b *testing.B, db *gorm.DB)) {
+	for _, enabled := range []bool{true, false} {
+		name := "StatementPool"
+		if !enabled {
+			name = "NoStatementPool"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			db := DB.Session(&gorm.Session{NewDB: true})
+			db.Config.StatementPool = enabled
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			fc(b, db)
+		})
 	}
 }
 
@@ -82,3 +117,120 @@ func BenchmarkDelete(b *testing.B) {
 		DB.Delete(&user)
 	}
 }
+
+func BenchmarkCreateSlice(b *testing.B) {
+	benchmarkCreateSlice(b, DB)
+}
+
+func BenchmarkCreateSliceWithModelHook(b *testing.B) {
+	db := DB.Session(&gorm.Session{})
+	db.RegisterModelHook(gorm.ModelHook{
+		BeforeCreate: func(tx *gorm.DB, model interface{}) error { return nil },
+		AfterCreate:  func(tx *gorm.DB, model interface{}) error { return nil },
+	})
+	benchmarkCreateSlice(b, db)
+}
+
+func benchmarkCreateSlice(b *testing.B, db *gorm.DB) {
+	users := make([]User, 100)
+	b.ResetTimer()
+	for x := 0; x < b.N; x++ {
+		for i := range users {
+			users[i] = User{Name: "bench_slice"}
+		}
+		db.Create(&users)
+	}
+}
+
+func BenchmarkScanWideRows(b *testing.B) {
+	DB.Migrator().DropTable(&ScanWideRow{})
+	DB.AutoMigrate(&ScanWideRow{})
+
+	nick, weight, now := "nick", 62.5, time.Now()
+	rows := make([]ScanWideRow, 100_000)
+	for i := range rows {
+		rows[i] = ScanWideRow{
+			Name: fmt.Sprintf("scan-%d", i), Code: "code", Email: "bench@example.com", Age: i % 100, Score: 9.5, Rank: int64(i),
+			Active: true, Nick: &nick, Weight: &weight, Tags: []string{"a"}, Born: now, Login: &now, Note: "note",
+		}
+	}
+	DB.CreateInBatches(&rows, 1000)
+
+	var results []ScanWideRow
+	b.ReportAllocs()
+	b.ResetTimer()
+	for x := 0; x < b.N; x++ {
+		DB.Find(&results)
+	}
+}
+
+type BenchmarkImage struct {
+	gorm.ChangeTracker
+	ID   uint
+	Name string
+	Data []byte
+}
+
+type BenchmarkSkipDiffImage struct {
+	gorm.ChangeTracker
+	ID   uint
+	Name string
+	Data []byte `gorm:"skipDiff"`
+}
+
+func BenchmarkChangedFieldsLargeBytes(b *testing.B) {
+	data := make([]byte, 5<<20)
+
+	b.Run("Diff", func(b *testing.B) {
+		DB.Migrator().DropTable(&BenchmarkImage{})
+		DB.AutoMigrate(&BenchmarkImage{})
+		DB.Create(&BenchmarkImage{Name: "bench", Data: data})
+
+		var image BenchmarkImage
+		DB.First(&image)
+		image.Data[len(image.Data)-1] = 1
+
+		b.ResetTimer()
+		for x := 0; x < b.N; x++ {
+			gorm.ChangedFields(DB, &image)
+		}
+	})
+
+	b.Run("SkipDiff", func(b *testing.B) {
+		DB.Migrator().DropTable(&BenchmarkSkipDiffImage{})
+		DB.AutoMigrate(&BenchmarkSkipDiffImage{})
+		DB.Create(&BenchmarkSkipDiffImage{Name: "bench", Data: data})
+
+		var image BenchmarkSkipDiffImage
+		DB.First(&image)
+		image.Data[len(image.Data)-1] = 1
+
+		b.ResetTimer()
+		for x := 0; x < b.N; x++ {
+			gorm.ChangedFields(DB, &image)
+		}
+	})
+}
+
+func BenchmarkFindReuseDest(b *testing.B) {
+	DB.Exec("delete from users")
+	users := make([]User, 1_000)
+	for i := range users {
+		users[i] = *GetUser(fmt.Sprintf("reuse-%d", i), Config{})
+	}
+	DB.CreateInBatches(&users, 100)
+
+	for _, reuseDest := range []bool{false, true} {
+		b.Run(fmt.Sprintf("ReuseDest=%v", reuseDest), func(b *testing.B) {
+			var (
+				db     = DB.Session(&gorm.Session{ReuseDest: reuseDest})
+				result = make([]User, 0, len(users))
+			)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for x := 0; x < b.N; x++ {
+				db.Find(&result)
+			}
+		})
+	}
+}
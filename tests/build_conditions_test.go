@@ -0,0 +1,75 @@
+package tests_test
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type numberedDialector struct {
+	DummyDialector
+}
+
+func (numberedDialector) BindVarTo(writer clause.Writer, stmt *gorm.Statement, v interface{}) {
+	writer.WriteString("$" + strconv.Itoa(len(stmt.Vars)))
+}
+
+func TestBuildConditions(t *testing.T) {
+	DB.Unscoped().Where("name = ?", "build_conditions").Delete(&User{})
+	users := []User{*GetUser("build_conditions", Config{}), *GetUser("build_conditions", Config{}), *GetUser("build_conditions", Config{})}
+	users[0].Age, users[1].Age, users[2].Age = 18, 18, 20
+	DB.Create(&users)
+	DB.Delete(&users[1])
+
+	sql, vars, err := gorm.BuildConditions(DB.Model(&User{}), &User{Name: "build_conditions", Age: 18})
+	if err != nil {
+		t.Fatalf("failed to build conditions, got error %v", err)
+	}
+
+	if !regexp.MustCompile(`^.users.\..name. = \? AND .users.\..age. = \?$`).MatchString(sql) {
+		t.Errorf("conditions should be built with the columns of the model, got %v", sql)
+	}
+	AssertEqual(t, vars, []interface{}{"build_conditions", uint(18)})
+
+	var count int64
+	if err := DB.Raw("SELECT count(*) FROM users WHERE "+sql, vars...).Scan(&count).Error; err != nil || count != 2 {
+		t.Errorf("raw SQL should count the rows including the soft deleted one, got count %v, error %v", count, err)
+	}
+
+	sql, vars, err = gorm.BuildConditions(DB.Model(&User{}).Where("age > ?", 10), map[string]interface{}{"name": "build_conditions"}, gorm.IncludeQueryClauses())
+	if err != nil {
+		t.Fatalf("failed to build conditions, got error %v", err)
+	}
+
+	if !regexp.MustCompile(`^age > \? AND .name. = \? AND .users.\..deleted_at. IS NULL$`).MatchString(sql) {
+		t.Errorf("conditions should include the Where conditions and the soft delete condition, got %v", sql)
+	}
+	AssertEqual(t, vars, []interface{}{10, "build_conditions"})
+
+	if err := DB.Raw("SELECT count(*) FROM users WHERE "+sql, vars...).Scan(&count).Error; err != nil || count != 2 {
+		t.Errorf("raw SQL should count the rows excluding the soft deleted one, got count %v, error %v", count, err)
+	}
+
+	if sql, _, _ = gorm.BuildConditions(DB.Model(&User{}).Unscoped(), "age = ?", 18, gorm.IncludeQueryClauses()); sql != "age = ?" {
+		t.Errorf("Unscoped shouldn't include the soft delete condition, got %v", sql)
+	}
+
+	if _, _, err = gorm.BuildConditions(DB.Model(&User{}), gorm.Cond(&User{Name: "jinzhu"}, gorm.CondOperator("Name", "ILIKE"))); err == nil {
+		t.Errorf("should return the error of building conditions")
+	}
+
+	dryDB, _ := gorm.Open(numberedDialector{}, &gorm.Config{})
+	sql, vars, err = gorm.BuildConditions(dryDB.Model(&User{}), &User{Name: "jinzhu", Age: 18})
+	if err != nil || sql != "`users`.`name` = ? AND `users`.`age` = ?" || len(vars) != 2 {
+		t.Errorf("conditions should be built with ?, got %v, %v, error %v", sql, vars, err)
+	}
+
+	sql, vars, err = gorm.BuildConditions(dryDB.Model(&User{}), &User{Name: "jinzhu", Age: 18}, gorm.DialectPlaceholders(3))
+	if err != nil || sql != "`users`.`name` = $3 AND `users`.`age` = $4" || len(vars) != 2 {
+		t.Errorf("conditions should be built with the dialect placeholders, got %v, %v, error %v", sql, vars, err)
+	}
+}
@@ -0,0 +1,177 @@
+package tests_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type CacheUser struct {
+	ID    uint
+	Name  string
+	Found int `gorm:"-"`
+}
+
+func (u *CacheUser) AfterFind(*gorm.DB) error {
+	u.Found++
+	return nil
+}
+
+func TestCache(t *testing.T) {
+	DB.Migrator().DropTable(&CacheUser{})
+	if err := DB.AutoMigrate(&CacheUser{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	store := gorm.NewMemoryCacheStore()
+	db := DB.Session(&gorm.Session{})
+	db.Config.CacheStore = store
+	cached := db.Session(&gorm.Session{CacheTTL: time.Minute})
+
+	user := CacheUser{Name: "cache"}
+	db.Create(&user)
+
+	var result CacheUser
+	if err := cached.First(&result, user.ID).Error; err != nil || result.Name != "cache" || result.Found != 1 {
+		t.Fatalf("failed to find user, got %+v, error %v", result, err)
+	}
+	if store.Len() == 0 {
+		t.Fatalf("results should be cached")
+	}
+
+	// raw writes aren't invalidated automatically, the cached results are returned without querying
+	db.Exec("UPDATE cache_users SET name = ? WHERE id = ?", "raw", user.ID)
+	result = CacheUser{}
+	tx := cached.First(&result, user.ID)
+	if tx.Error != nil || result.Name != "cache" || tx.RowsAffected != 1 || !tx.Statement.CacheHit() {
+		t.Fatalf("cached user should be returned, got %+v, error %v", result, tx.Error)
+	}
+	if result.Found != 1 {
+		t.Errorf("AfterFind should be called for cached results, got %v", result.Found)
+	}
+
+	skipped := cached.Session(&gorm.Session{})
+	skipped.Config.CacheSkipAfterFind = true
+	result = CacheUser{}
+	if err := skipped.First(&result, user.ID).Error; err != nil || result.Name != "cache" || result.Found != 0 {
+		t.Errorf("AfterFind of cached results should be skipped, got %+v, error %v", result, err)
+	}
+
+	db.InvalidateCache("cache_users")
+	result = CacheUser{}
+	if err := cached.First(&result, user.ID).Error; err != nil || result.Name != "raw" {
+		t.Errorf("invalidated results should be queried, got %+v, error %v", result, err)
+	}
+
+	// updates invalidate the cached results of the table
+	db.Model(&user).Update("name", "updated")
+	result = CacheUser{}
+	if err := cached.First(&result, user.ID).Error; err != nil || result.Name != "updated" {
+		t.Errorf("updated user should be queried, got %+v, error %v", result, err)
+	}
+
+	var users []CacheUser
+	if err := cached.Find(&users).Error; err != nil || len(users) != 1 {
+		t.Fatalf("failed to find users, got %v, error %v", len(users), err)
+	}
+	db.Create(&CacheUser{Name: "cache2"})
+	if err := cached.Find(&users).Error; err != nil || len(users) != 2 {
+		t.Errorf("created user should be found, got %v, error %v", len(users), err)
+	}
+
+	var count int64
+	if err := cached.Model(&CacheUser{}).Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("failed to count users, got %v, error %v", count, err)
+	}
+	db.Delete(&CacheUser{}, user.ID)
+	if err := cached.Model(&CacheUser{}).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("deleted user should not be counted, got %v, error %v", count, err)
+	}
+
+	// empty results aren't cached
+	if err := cached.First(&result, user.ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("should return ErrRecordNotFound, got %v", err)
+	}
+	if err := cached.First(&result, user.ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("should return ErrRecordNotFound again, got %v", err)
+	}
+
+	// the keys of the queries with a CacheKey are still derived from their SQL and dest
+	keyed := cached.Session(&gorm.Session{CacheKey: "cache_user"})
+	var first, again CacheUser
+	keyed.First(&first)
+	if tx := keyed.First(&again); again.ID != first.ID || !tx.Statement.CacheHit() {
+		t.Errorf("results should be cached with the CacheKey, got %v, %v", first.ID, again.ID)
+	}
+	var others []CacheUser
+	if err := keyed.Where("id <> ?", first.ID).Find(&others).Error; err != nil || len(others) != 0 {
+		t.Errorf("the queries with a CacheKey shouldn't share the results, got %v, error %v", len(others), err)
+	}
+	var names []string
+	if err := keyed.Model(&CacheUser{}).Pluck("name", &names).Error; err != nil || len(names) != 1 {
+		t.Errorf("the dest types with a CacheKey shouldn't share the results, got %v, error %v", names, err)
+	}
+
+	// reads in transactions aren't cached
+	cached.First(&CacheUser{}, first.ID)
+	cached.Transaction(func(tx *gorm.DB) error {
+		tx.Model(&CacheUser{}).Where("id = ?", first.ID).UpdateColumn("name", "in_tx")
+		var inTx CacheUser
+		if err := tx.First(&inTx, first.ID).Error; err != nil || inTx.Name != "in_tx" || tx.Statement.CacheHit() {
+			t.Errorf("should read the uncommitted value in transaction, got %+v, error %v", inTx, err)
+		}
+
+		// the results read before the commit aren't invalidated until it
+		var outside CacheUser
+		if tx := cached.First(&outside, first.ID); outside.Name != first.Name || !tx.Statement.CacheHit() {
+			t.Errorf("the cached results should be kept before the commit, got %+v, error %v", outside, tx.Error)
+		}
+		return errors.New("rollback")
+	})
+
+	result = CacheUser{}
+	if tx := cached.First(&result, first.ID); result.Name != first.Name || !tx.Statement.CacheHit() {
+		t.Errorf("the rolled back updates shouldn't invalidate the cached results, got %+v, error %v", result, tx.Error)
+	}
+
+	cached.Transaction(func(tx *gorm.DB) error {
+		return tx.Model(&CacheUser{}).Where("id = ?", first.ID).UpdateColumn("name", "committed").Error
+	})
+	result = CacheUser{}
+	if tx := cached.First(&result, first.ID); result.Name != "committed" || tx.Statement.CacheHit() {
+		t.Errorf("the committed updates should invalidate the cached results, got %+v, error %v", result, tx.Error)
+	}
+
+	// the table versions are the only entries kept besides the cached results
+	entries := store.Len()
+	for i := 0; i < 5; i++ {
+		db.InvalidateCache("cache_users")
+	}
+	if store.Len() > entries {
+		t.Errorf("invalidations shouldn't add entries, got %v, expects %v", store.Len(), entries)
+	}
+}
+
+func TestCachePreload(t *testing.T) {
+	store := gorm.NewMemoryCacheStore()
+	db := DB.Session(&gorm.Session{})
+	db.Config.CacheStore = store
+	cached := db.Session(&gorm.Session{CacheTTL: time.Minute})
+
+	user := *GetUser("cache_preload", Config{Pets: 1})
+	db.Create(&user)
+
+	var result User
+	if err := cached.Preload("Pets").First(&result, user.ID).Error; err != nil || len(result.Pets) != 1 {
+		t.Fatalf("failed to preload pets, got %+v, error %v", result.Pets, err)
+	}
+
+	db.Model(user.Pets[0]).Update("name", "cache_preload_pet")
+	result = User{}
+	if err := cached.Preload("Pets").First(&result, user.ID).Error; err != nil || len(result.Pets) != 1 || result.Pets[0].Name != "cache_preload_pet" {
+		t.Errorf("updates of preloaded tables should invalidate the cached results, got %+v, error %v", result.Pets, err)
+	}
+}
@@ -1,13 +1,18 @@
 package tests_test
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"log"
 	"reflect"
 	"runtime"
 	"strings"
 	"testing"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	. "gorm.io/gorm/utils/tests"
 )
 
 func assertCallbacks(v interface{}, fnames []string) (result bool, msg string) {
@@ -252,3 +257,152 @@ func TestCallbacksRemove(t *testing.T) {
 		t.Errorf("callbacks tests failed, got %v", msg)
 	}
 }
+
+func TestCallbacksPattern(t *testing.T) {
+	db, _ := gorm.Open(nil, nil)
+	createCallback := db.Callback().Create()
+
+	createCallback.Register("c1", c1)
+	createCallback.Register("plugin:audit", c2)
+	createCallback.Register("c3", c3)
+	createCallback.Register("other:audit", c4)
+	createCallback.Before("*:audit").Register("c5", c5)
+	createCallback.After("*:audit").Register("c6", c6)
+
+	if ok, msg := assertCallbacks(createCallback, []string{"c1", "c5", "c2", "c3", "c4", "c6"}); !ok {
+		t.Errorf("callbacks tests failed, got %v", msg)
+	}
+
+	createCallback.Remove("plugin:audit")
+	if ok, msg := assertCallbacks(createCallback, []string{"c1", "c3", "c5", "c4", "c6"}); !ok {
+		t.Errorf("callbacks tests failed, got %v", msg)
+	}
+
+	createCallback.Remove("other:audit")
+	if ok, msg := assertCallbacks(createCallback, []string{"c1", "c3", "c5", "c6"}); !ok {
+		t.Errorf("callbacks tests failed, got %v", msg)
+	}
+}
+
+func TestCallbacksInspect(t *testing.T) {
+	db, _ := gorm.Open(nil, nil)
+	createCallback := db.Callback().Create()
+
+	createCallback.Register("c1", c1)
+	createCallback.After("c1").Register("c2", c2)
+	createCallback.Before("*:audit").Register("c3", c3)
+	createCallback.Register("plugin:audit", c4)
+	createCallback.Replace("c1", c5)
+
+	infos := createCallback.Inspect()
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+
+	if fmt.Sprint(names) != fmt.Sprint([]string{"c1", "c2", "c3", "plugin:audit"}) {
+		t.Fatalf("inspected callbacks order, got %v", names)
+	}
+
+	if infos[1].After != "c1" || infos[2].Before != "*:audit" {
+		t.Errorf("inspected callbacks constraints, got %+v", infos)
+	}
+
+	if len(infos[0].Replaced) != 1 || !strings.Contains(infos[0].Replaced[0], "callbacks_test.go") ||
+		!strings.Contains(infos[0].Location, "callbacks_test.go") || infos[0].Location == infos[0].Replaced[0] {
+		t.Errorf("inspected callback should record replacement, got %+v", infos[0])
+	}
+
+	if len(infos[3].Replaced) != 0 {
+		t.Errorf("callback not replaced, got %+v", infos[3])
+	}
+}
+
+func TestTraceCallbacks(t *testing.T) {
+	var buf bytes.Buffer
+	tx := DB.Session(&gorm.Session{
+		TraceCallbacks: true,
+		Logger:         logger.New(log.New(&buf, "", 0), logger.Config{LogLevel: logger.Info}),
+	})
+
+	user := *GetUser("trace_callbacks", Config{})
+	if err := tx.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got %v", err)
+	}
+
+	for _, name := range []string{"gorm:begin_transaction", "gorm:create", "gorm:commit_or_rollback_transaction"} {
+		if !strings.Contains(buf.String(), "callback `"+name+"` finished in") {
+			t.Errorf("callback %v should be traced, got %v", name, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := DB.Session(&gorm.Session{Logger: tx.Logger}).First(&User{}, user.ID).Error; err != nil {
+		t.Fatalf("failed to query user, got %v", err)
+	}
+
+	if strings.Contains(buf.String(), "finished in") {
+		t.Errorf("callbacks should not be traced without TraceCallbacks, got %v", buf.String())
+	}
+}
+
+func TestRawAndRowCallbacks(t *testing.T) {
+	db, err := gorm.Open(DB.Dialector, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open connection, got %v", err)
+	}
+
+	if ok, msg := assertCallbacks(db.Callback().Row(), []string{"BuildRowSQL", "RowQuery"}); !ok {
+		t.Errorf("row callbacks should build the sql before querying it, %v", msg)
+	}
+
+	var executed []string
+	db.Callback().Raw().Before("gorm:raw").Register("audit:raw", func(tx *gorm.DB) {
+		if strings.HasPrefix(tx.Statement.SQL.String(), "DELETE") {
+			tx.AddError(errors.New("deletes are vetoed"))
+			return
+		}
+
+		sql := tx.Statement.SQL.String()
+		tx.Statement.SQL.Reset()
+		tx.Statement.SQL.WriteString(sql + " AND name = ?")
+		tx.Statement.Vars = append(tx.Statement.Vars, "raw_callbacks")
+		executed = append(executed, tx.Statement.SQL.String())
+	})
+	db.Callback().Row().After("gorm:build_row_sql").Before("gorm:row").Register("audit:row", func(tx *gorm.DB) {
+		tx.Statement.SQL.WriteString(" /* audited */")
+		executed = append(executed, tx.Statement.SQL.String())
+	})
+
+	if ok, msg := assertCallbacks(db.Callback().Row(), []string{"BuildRowSQL", "func2", "RowQuery"}); !ok {
+		t.Errorf("callbacks should be registered between gorm:build_row_sql and gorm:row, %v", msg)
+	}
+
+	users := []User{*GetUser("raw_callbacks", Config{}), *GetUser("raw_callbacks_1", Config{})}
+	DB.Create(&users)
+
+	result := db.Exec("UPDATE users SET age = ? WHERE id IN ?", 66, []uint{users[0].ID, users[1].ID})
+	if result.Error != nil || result.RowsAffected != 1 {
+		t.Fatalf("modified sql should be executed, got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	if err := db.Exec("DELETE FROM users WHERE id = ?", users[0].ID).Error; err == nil || err.Error() != "deletes are vetoed" {
+		t.Errorf("execution should be stopped by the error of the callback, got %v", err)
+	}
+
+	var ages []int
+	if err := db.Model(&User{}).Where("id IN ?", []uint{users[0].ID, users[1].ID}).Order("id").Pluck("age", &ages).Error; err != nil {
+		t.Fatalf("failed to pluck ages, got %v", err)
+	}
+	AssertEqual(t, ages, []int{66, int(users[1].Age)})
+
+	var name string
+	if err := db.Model(&User{}).Select("name").Where("id = ?", users[0].ID).Row().Scan(&name); err != nil || name != "raw_callbacks" {
+		t.Errorf("failed to query row, got %v, %v", name, err)
+	}
+
+	if len(executed) != 2 || !strings.HasSuffix(executed[0], "AND name = ?") || !strings.HasSuffix(executed[1], "/* audited */") ||
+		!strings.Contains(executed[1], "SELECT") {
+		t.Errorf("callbacks should be called with the built sql, got %v", executed)
+	}
+}
@@ -0,0 +1,299 @@
+package tests_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/utils"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type capabilityDialector struct {
+	gorm.Dialector
+	capabilities gorm.Capabilities
+}
+
+func (dialector capabilityDialector) Capabilities() gorm.Capabilities {
+	return dialector.capabilities
+}
+
+func TestSupports(t *testing.T) {
+	_, savePoint := DB.Dialector.(gorm.SavePointerDialectorInterface)
+	if DB.Supports(gorm.CapabilitySavePoint) != savePoint {
+		t.Errorf("savepoint should be supported if the dialector implements SavePointerDialectorInterface")
+	}
+
+	if returning := utils.Contains(DB.Callback().Create().Clauses, "RETURNING"); DB.Supports(gorm.CapabilityReturning) != returning {
+		t.Errorf("returning should be supported if the create clauses have RETURNING")
+	}
+
+	if !DB.Supports(gorm.CapabilityOnConflict) {
+		t.Errorf("on conflict should be supported by the default create clauses")
+	}
+
+	if DB.Supports("unknown") {
+		t.Errorf("unknown capability should be unsupported")
+	}
+
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.Dialector = capabilityDialector{Dialector: DB.Dialector, capabilities: gorm.Capabilities{
+		gorm.CapabilitySavePoint: false,
+		"custom":                 true,
+	}}
+
+	if tx.Supports(gorm.CapabilitySavePoint) || !tx.Supports("custom") {
+		t.Errorf("declared capabilities should be preferred")
+	}
+
+	if tx.Supports(gorm.CapabilityOnConflict) != DB.Supports(gorm.CapabilityOnConflict) {
+		t.Errorf("undeclared capabilities should be inferred")
+	}
+
+	if DB.Supports(gorm.CapabilitySavePoint) != savePoint {
+		t.Errorf("declared capabilities of session shouldn't affect the db")
+	}
+}
+
+func TestSupportsProbe(t *testing.T) {
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.CapabilityProbes = map[gorm.Capability]string{
+		gorm.CapabilityCTE: gorm.DefaultCapabilityProbes[gorm.CapabilityCTE],
+		"invalid":          "SELECT * FROM gorm_probe_not_exists",
+	}
+
+	for i := 0; i < 2; i++ {
+		if !tx.Supports(gorm.CapabilityCTE) {
+			t.Errorf("CTE should be supported when probed")
+		}
+
+		if tx.Supports("invalid") {
+			t.Errorf("capability should be unsupported when the probe failed")
+		}
+	}
+
+	if DB.Supports(gorm.CapabilityCTE) {
+		t.Errorf("CTE shouldn't be probed without probes")
+	}
+
+	if err := tx.Transaction(func(tx *gorm.DB) error {
+		if tx.Supports("invalid") {
+			t.Errorf("capability should be unsupported when the probe failed")
+		}
+		return tx.Create(GetUser("probe_in_transaction", Config{})).Error
+	}); err != nil {
+		t.Errorf("probes shouldn't affect transactions, got %v", err)
+	}
+}
+
+func TestDeclaredReturning(t *testing.T) {
+	db, err := OpenTestConnection(&gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open connection, got %v", err)
+	}
+
+	for _, returning := range []bool{true, false} {
+		dialector := capabilityDialector{Dialector: db.Dialector, capabilities: gorm.Capabilities{gorm.CapabilityReturning: returning}}
+		tx, err := gorm.Open(dialector, &gorm.Config{})
+		if err != nil {
+			t.Fatalf("failed to open connection, got %v", err)
+		}
+
+		for _, clauses := range [][]string{tx.Callback().Create().Clauses, tx.Callback().Update().Clauses, tx.Callback().Delete().Clauses} {
+			if utils.Contains(clauses, "RETURNING") != returning {
+				t.Errorf("RETURNING of clauses %v should be %v", clauses, returning)
+			}
+		}
+
+		if tx.Supports(gorm.CapabilityReturning) != returning {
+			t.Errorf("returning should be %v as declared", returning)
+		}
+
+		if sqlDB, err := tx.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+func TestDeclaredUpdateFrom(t *testing.T) {
+	db, err := OpenTestConnection(&gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open connection, got %v", err)
+	}
+
+	for _, updateFrom := range []bool{true, false} {
+		dialector := capabilityDialector{Dialector: db.Dialector, capabilities: gorm.Capabilities{gorm.CapabilityUpdateFrom: updateFrom}}
+		tx, err := gorm.Open(dialector, &gorm.Config{DryRun: true})
+		if err != nil {
+			t.Fatalf("failed to open connection, got %v", err)
+		}
+
+		if tx.Supports(gorm.CapabilityUpdateFrom) != updateFrom {
+			t.Errorf("update from should be %v as declared", updateFrom)
+		}
+
+		stmt := tx.Model(&User{}).Clauses(clause.From{Tables: []clause.Table{{Name: "accounts"}}}).
+			Where("accounts.user_id = users.id").Update("name", gorm.Expr("accounts.number")).Statement
+		if strings.Contains(stmt.SQL.String(), "FROM") != updateFrom {
+			t.Errorf("FROM of the update should be built if declared %v, got %v", updateFrom, stmt.SQL.String())
+		}
+
+		if sqlDB, err := tx.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+func TestDeclaredLimitedWrite(t *testing.T) {
+	db, err := OpenTestConnection(&gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open connection, got %v", err)
+	}
+
+	for _, limited := range []bool{true, false} {
+		dialector := capabilityDialector{Dialector: db.Dialector, capabilities: gorm.Capabilities{gorm.CapabilityLimitedWrite: limited}}
+		tx, err := gorm.Open(dialector, &gorm.Config{DryRun: true})
+		if err != nil {
+			t.Fatalf("failed to open connection, got %v", err)
+		}
+
+		if tx.Supports(gorm.CapabilityLimitedWrite) != limited {
+			t.Errorf("limited write should be %v as declared", limited)
+		}
+
+		for _, stmt := range []*gorm.Statement{
+			tx.Where("name = ?", "limited").Order("id").Limit(10).Delete(&User{}).Statement,
+			tx.Unscoped().Where("name = ?", "limited").Order("id").Limit(10).Delete(&User{}).Statement,
+			tx.Model(&User{}).Where("name = ?", "limited").Order("id").Limit(10).Update("age", 10).Statement,
+		} {
+			if sql := stmt.SQL.String(); strings.Contains(sql, "gorm_limited") == limited || !strings.Contains(sql, "ORDER BY id LIMIT") {
+				t.Errorf("ORDER BY and LIMIT should be built natively if declared %v, got %v", limited, sql)
+			}
+		}
+
+		if sqlDB, err := tx.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+func TestDeclaredTableFunctions(t *testing.T) {
+	tx := DB.Session(&gorm.Session{Context: context.Background(), DryRun: true})
+	tx.Config.Dialector = capabilityDialector{Dialector: DB.Dialector, capabilities: gorm.Capabilities{
+		gorm.CapabilityTableFunctions: false,
+	}}
+
+	err := tx.Table(clause.FunctionTable{Name: "generate_series", Args: []interface{}{1, 10}, Alias: "s"}).Find(&[]User{}).Error
+	if !errors.Is(err, gorm.ErrUnsupportedDriver) || !strings.Contains(err.Error(), "generate_series") {
+		t.Errorf("function table should be unsupported if declared, got %v", err)
+	}
+}
+
+// outputClause builds the returned columns at the end like RETURNING, which is added by outputDialector
+type outputClause struct {
+	Columns []clause.Column
+}
+
+func (outputClause) Name() string {
+	return "OUTPUT"
+}
+
+func (output outputClause) Build(builder clause.Builder) {
+	builder.WriteString("RETURNING ")
+	clause.Returning{Columns: output.Columns}.Build(builder)
+}
+
+func (output outputClause) MergeClause(c *clause.Clause) {
+	c.Name = ""
+	c.Expression = output
+}
+
+type outputDialector struct {
+	gorm.Dialector
+	built *int
+}
+
+func (dialector outputDialector) BuildReturning(stmt *gorm.Statement, columns []clause.Column) {
+	*dialector.built++
+	stmt.AddClause(outputClause{Columns: columns})
+	if !utils.Contains(stmt.BuildClauses, "OUTPUT") {
+		stmt.BuildClauses = append(stmt.BuildClauses[:len(stmt.BuildClauses):len(stmt.BuildClauses)], "OUTPUT")
+	}
+}
+
+func TestReturningBuilder(t *testing.T) {
+	if DB.Dialector.Name() != "sqlite" {
+		t.Skip("outputDialector builds RETURNING of sqlite")
+	}
+
+	type OutputUser struct {
+		ID   uint
+		Name string
+		Code string `gorm:"default:(lower('OUTPUT'))"`
+	}
+
+	var built int
+	tx, err := gorm.Open(outputDialector{Dialector: DB.Dialector, built: &built}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open connection, got %v", err)
+	}
+
+	if !tx.Supports(gorm.CapabilityReturning) {
+		t.Errorf("returning should be supported by ReturningBuilder")
+	}
+
+	tx.Migrator().DropTable(&OutputUser{})
+	if err := tx.AutoMigrate(&OutputUser{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	stmt := tx.Session(&gorm.Session{DryRun: true}).Create(&OutputUser{Name: "output"}).Statement
+	if sql := stmt.SQL.String(); !regexp.MustCompile("^INSERT INTO .output_users. .* RETURNING .+code.*$").MatchString(sql) || strings.Count(sql, "RETURNING") != 1 || built != 1 {
+		t.Errorf("returned columns should be built by ReturningBuilder, got %v, built %v", sql, built)
+	}
+
+	user := OutputUser{Name: "output"}
+	if err := tx.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	if user.ID == 0 || user.Code != "output" {
+		t.Errorf("returned columns should be scanned, got %+v", user)
+	}
+
+	var updated []OutputUser
+	if err := tx.Model(&updated).Clauses(clause.Returning{Columns: []clause.Column{{Name: "name"}}}).Where("id = ?", user.ID).Update("name", "output_updated").Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+	AssertEqual(t, updated, []OutputUser{{Name: "output_updated"}})
+
+	var deleted []OutputUser
+	if err := tx.Clauses(clause.Returning{}).Where("id = ?", user.ID).Delete(&deleted).Error; err != nil {
+		t.Fatalf("failed to delete, got error %v", err)
+	}
+	AssertEqual(t, deleted, []OutputUser{{ID: user.ID, Name: "output_updated", Code: "output"}})
+
+	if built != 4 {
+		t.Errorf("ReturningBuilder should be called by creates, updates and deletes, got %v", built)
+	}
+
+	if sqlDB, err := tx.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
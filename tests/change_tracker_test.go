@@ -0,0 +1,187 @@
+package tests_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type TrackedProfile struct {
+	gorm.ChangeTracker
+	ID    uint
+	Name  string
+	Roles []string `gorm:"serializer:json"`
+}
+
+func TestChangedFields(t *testing.T) {
+	user := *GetUser("changed_fields", Config{})
+	user.Age = 18
+	DB.Create(&user)
+
+	tx := DB.Session(&gorm.Session{TrackChanges: true})
+
+	var result User
+	if err := tx.First(&result, user.ID).Error; err != nil {
+		t.Fatalf("failed to find user, got error %v", err)
+	}
+
+	if changed := gorm.ChangedFields(tx, &result); changed != nil {
+		t.Errorf("loaded user shouldn't be changed, got %v", changed)
+	}
+
+	result.Name = "changed_fields_updated"
+	result.Age = 0
+	if changed := gorm.ChangedFields(tx, &result); !reflect.DeepEqual(changed, []string{"Name", "Age"}) {
+		t.Errorf("name and age should be changed, got %v", changed)
+	}
+
+	if changed := gorm.ChangedFields(DB, &result); changed != nil {
+		t.Errorf("user isn't tracked by the session without TrackChanges, got %v", changed)
+	}
+
+	stmt := tx.Session(&gorm.Session{DryRun: true}).Save(&result).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, "name") || !strings.Contains(sql, "age") || strings.Contains(sql, "birthday") {
+		t.Errorf("should update the changed fields only, got %v", sql)
+	}
+
+	if err := tx.Save(&result).Error; err != nil {
+		t.Fatalf("failed to save changed user, got error %v", err)
+	}
+
+	var saved User
+	DB.First(&saved, user.ID)
+	if saved.Name != "changed_fields_updated" || saved.Age != 0 {
+		t.Errorf("user should be updated, got %+v", saved)
+	}
+
+	result.Age = 30
+	if changed := gorm.ChangedFields(tx, &result); changed != nil {
+		t.Errorf("the snapshot of the saved user should be released, got %v", changed)
+	}
+
+	var users []User
+	if err := tx.Where("name = ?", "changed_fields_updated").Find(&users).Error; err != nil || len(users) != 1 {
+		t.Fatalf("failed to find users, got %v, error %v", len(users), err)
+	}
+
+	users[0].Age = 20
+	if changed := gorm.ChangedFields(tx, &users[0]); !reflect.DeepEqual(changed, []string{"Age"}) {
+		t.Errorf("age should be changed, got %v", changed)
+	}
+
+	if err := tx.Updates(&users[0]).Error; err != nil {
+		t.Fatalf("failed to update changed user, got error %v", err)
+	}
+
+	if changed := gorm.ChangedFields(tx, &users[0]); changed != nil {
+		t.Errorf("the snapshot of the updated user should be released, got %v", changed)
+	}
+
+	tx.First(&users[0], user.ID)
+	users[0].Age = 0
+	if err := tx.Updates(&users[0]).Error; err != nil {
+		t.Fatalf("failed to update changed user, got error %v", err)
+	}
+
+	DB.First(&saved, user.ID)
+	if saved.Age != 0 {
+		t.Errorf("age should be updated to zero value, got %v", saved.Age)
+	}
+
+	tx.Find(&users, user.ID)
+	users[0].Name = "changed_fields_forgot"
+	gorm.ForgetChanges(tx, &users)
+	if changed := gorm.ChangedFields(tx, &users[0]); changed != nil {
+		t.Errorf("the forgot snapshot should be released, got %v", changed)
+	}
+
+	tx.Find(&users, user.ID)
+	users[0] = *GetUser("changed_fields_replaced", Config{})
+	if changed := gorm.ChangedFields(tx, &users[0]); changed != nil {
+		t.Errorf("the snapshot of the replaced user is stale, got %v", changed)
+	}
+}
+
+func TestChangeTracker(t *testing.T) {
+	DB.Migrator().DropTable(&TrackedProfile{})
+	if err := DB.AutoMigrate(&TrackedProfile{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	profile := TrackedProfile{Name: "change_tracker", Roles: []string{"admin"}}
+	DB.Create(&profile)
+
+	var result TrackedProfile
+	if err := DB.First(&result, profile.ID).Error; err != nil {
+		t.Fatalf("failed to find profile, got error %v", err)
+	}
+
+	if changed := gorm.ChangedFields(DB, &result); changed != nil {
+		t.Errorf("loaded profile shouldn't be changed, got %v", changed)
+	}
+
+	result.Roles[0] = "member"
+	if changed := gorm.ChangedFields(DB, &result); !reflect.DeepEqual(changed, []string{"Roles"}) {
+		t.Errorf("roles should be changed, got %v", changed)
+	}
+
+	if err := DB.Save(&result).Error; err != nil {
+		t.Fatalf("failed to save profile, got error %v", err)
+	}
+
+	var saved TrackedProfile
+	DB.First(&saved, profile.ID)
+	if len(saved.Roles) != 1 || saved.Roles[0] != "member" {
+		t.Errorf("roles should be saved, got %v", saved.Roles)
+	}
+
+	if changed := gorm.ChangedFields(DB, &result); changed != nil {
+		t.Errorf("saved profile shouldn't be changed, got %v", changed)
+	}
+}
+
+type TrackedImage struct {
+	gorm.ChangeTracker
+	ID       uint
+	Name     string
+	Data     []byte `gorm:"skipDiff"`
+	Checksum string `gorm:"<-:false"`
+}
+
+func TestChangedFieldsSkipDiff(t *testing.T) {
+	DB.Migrator().DropTable(&TrackedImage{})
+	if err := DB.AutoMigrate(&TrackedImage{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	image := TrackedImage{Name: "skip_diff", Data: []byte("blob")}
+	DB.Create(&image)
+
+	var result TrackedImage
+	if err := DB.First(&result, image.ID).Error; err != nil {
+		t.Fatalf("failed to find image, got error %v", err)
+	}
+
+	// the fields tagged with `skipDiff` are always changed, the fields never updated are never changed
+	result.Checksum = "checksum"
+	AssertEqual(t, gorm.ChangedFields(DB, &result), []string{"Data"})
+
+	result.Name = "skip_diff_updated"
+	AssertEqual(t, gorm.ChangedFields(DB, &result), []string{"Name", "Data"})
+
+	var changed, nameChanged bool
+	if err := DB.Callback().Update().Before("gorm:update").Register("skip_diff:changed", func(tx *gorm.DB) {
+		changed, nameChanged = tx.Statement.Changed("Data"), tx.Statement.Changed("Name")
+	}); err != nil {
+		t.Fatalf("failed to register callback, got error %v", err)
+	}
+	defer DB.Callback().Update().Remove("skip_diff:changed")
+
+	DB.Model(&image).Updates(map[string]interface{}{"name": image.Name, "data": image.Data})
+	if !changed || nameChanged {
+		t.Errorf("data should be changed without comparing, got %v, name shouldn't be changed, got %v", changed, nameChanged)
+	}
+}
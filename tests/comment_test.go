@@ -0,0 +1,143 @@
+package tests_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type commentRecorder struct {
+	gorm.ConnPool
+	sqls []string
+}
+
+func (r *commentRecorder) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	r.sqls = append(r.sqls, query)
+	return r.ConnPool.ExecContext(ctx, query, args...)
+}
+
+func (r *commentRecorder) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	r.sqls = append(r.sqls, query)
+	return r.ConnPool.QueryContext(ctx, query, args...)
+}
+
+func (r *commentRecorder) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	r.sqls = append(r.sqls, query)
+	return r.ConnPool.QueryRowContext(ctx, query, args...)
+}
+
+type traceKey struct{}
+
+func TestSQLComment(t *testing.T) {
+	user := *GetUser("sql-comment", Config{})
+	DB.Create(&user)
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	db := DB.Session(&gorm.Session{Context: ctx, SkipDefaultTransaction: true})
+	recorder := &commentRecorder{ConnPool: db.Statement.ConnPool}
+	db.Statement.ConnPool = recorder
+	db.Config.CommentProvider = func(ctx context.Context, stmt *gorm.Statement) map[string]string {
+		return map[string]string{"traceparent": ctx.Value(traceKey{}).(string), "route": "/users/{id}", "table": stmt.Table}
+	}
+	comment := "/*route='%2Fusers%2F%7Bid%7D',table='users',traceparent='00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01'*/"
+
+	var result User
+	db.First(&result, user.ID)
+	db.Model(&result).Update("age", 20)
+	db.Exec("UPDATE users SET age = ? WHERE id = ?", 21, user.ID)
+	db.Raw("SELECT * FROM users WHERE id = ?", user.ID).Scan(&result)
+
+	if len(recorder.sqls) != 4 {
+		t.Fatalf("expected 4 executed statements, got %v", recorder.sqls)
+	}
+	for _, sql := range recorder.sqls[:2] {
+		if !strings.HasSuffix(sql, " "+comment) {
+			t.Errorf("expected the sql comment appended, got %v", sql)
+		}
+	}
+	for _, sql := range recorder.sqls[2:] {
+		if !strings.HasSuffix(sql, "*/") || !strings.Contains(sql, "traceparent=") {
+			t.Errorf("expected the sql comment appended to raw sql, got %v", sql)
+		}
+	}
+
+	recorder.sqls = nil
+	db.Session(&gorm.Session{QueryComment: "nightly */ report"}).First(&result, user.ID)
+	if len(recorder.sqls) != 1 || !strings.Contains(recorder.sqls[0], " /*nightly * / report*/ /*route=") {
+		t.Errorf("expected the query comment appended, got %v", recorder.sqls)
+	}
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB { return tx.First(&User{}, user.ID) })
+	if strings.Contains(sql, "/*") {
+		t.Errorf("expected no sql comment in ToSQL, got %v", sql)
+	}
+
+	sql = db.Session(&gorm.Session{CommentDryRun: true}).ToSQL(func(tx *gorm.DB) *gorm.DB { return tx.First(&User{}, user.ID) })
+	if !strings.HasSuffix(sql, comment) {
+		t.Errorf("expected the sql comment in ToSQL with CommentDryRun, got %v", sql)
+	}
+
+	statements := db.Session(&gorm.Session{DryRun: true, CommentDryRun: true}).First(&User{}, user.ID).Statement.SQLStatements()
+	if len(statements) != 1 || strings.Contains(statements[0].SQL, "/*") {
+		t.Errorf("expected the recorded statements without sql comment, got %v", statements)
+	}
+
+	buf := &lockedBuffer{}
+	db.Session(&gorm.Session{Logger: logger.New(log.New(buf, "", 0), logger.Config{LogLevel: logger.Info})}).First(&result, user.ID)
+	if !strings.Contains(buf.String(), " LIMIT 1 "+comment) {
+		t.Errorf("expected the sql comment logged with the executed sql, got %v", buf.String())
+	}
+}
+
+func TestSQLCommentPreparedStmt(t *testing.T) {
+	user := *GetUser("sql-comment-prepared", Config{})
+	DB.Create(&user)
+
+	for _, cacheWithoutComment := range []bool{false, true} {
+		var executions int
+		tx := DB.Session(&gorm.Session{PrepareStmt: boolPtr(true), SkipDefaultTransaction: true})
+		tx.Config.PreparedStmtCacheWithoutComment = cacheWithoutComment
+		tx.Config.CommentProvider = func(ctx context.Context, stmt *gorm.Statement) map[string]string {
+			executions++
+			return map[string]string{"execution": strconv.Itoa(executions)}
+		}
+		recorder := &commentRecorder{ConnPool: tx.Statement.ConnPool}
+		preparedStmt := tx.Statement.ConnPool.(*gorm.PreparedStmtDB)
+		tx.Statement.ConnPool = recorder
+
+		query := fmt.Sprintf("SELECT id FROM users WHERE id = ? AND name <> '%v'", cacheWithoutComment)
+		for i := 0; i < 2; i++ {
+			var id uint
+			if err := tx.Raw(query, user.ID).Scan(&id).Error; err != nil || id != user.ID {
+				t.Fatalf("failed to query with prepared statement, got %v, error %v", id, err)
+			}
+		}
+
+		if len(recorder.sqls) != 2 || recorder.sqls[0] != query+" /*execution='1'*/" || recorder.sqls[1] != query+" /*execution='2'*/" {
+			t.Errorf("expected the sql comment sent with prepared statements, got %v", recorder.sqls)
+		}
+
+		var keys []string
+		preparedStmt.Mux.RLock()
+		for key := range preparedStmt.Stmts {
+			if strings.HasPrefix(key, query) {
+				keys = append(keys, key)
+			}
+		}
+		preparedStmt.Mux.RUnlock()
+
+		if cacheWithoutComment && (len(keys) != 1 || keys[0] != query) {
+			t.Errorf("expected the prepared statement cached without comment, got %v", keys)
+		} else if !cacheWithoutComment && len(keys) != 2 {
+			t.Errorf("expected the prepared statements cached with comments, got %v", keys)
+		}
+	}
+}
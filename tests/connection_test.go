@@ -1,11 +1,20 @@
 package tests_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	. "gorm.io/gorm/utils/tests"
 )
 
 func TestWithSingleConnection(t *testing.T) {
@@ -44,3 +53,89 @@ func getSetSQL(driverName string) (string, string) {
 		return "", ""
 	}
 }
+
+func TestAcquireConn(t *testing.T) {
+	db, err := OpenTestConnection(&gorm.Config{PrepareStmt: true})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	tx, release, err := db.AcquireConn(context.Background())
+	if err != nil {
+		t.Fatalf("failed to acquire connection, got error %v", err)
+	}
+	if _, ok := tx.Statement.ConnPool.(*gorm.PreparedStmtDB); !ok {
+		t.Errorf("pinned connection should prepare statements, got %T", tx.Statement.ConnPool)
+	}
+
+	// temporary tables are only visible on the connection creating them
+	if err := tx.Exec("CREATE TEMPORARY TABLE acquire_conn_temps (id int)").Error; err != nil {
+		t.Fatalf("failed to create temporary table, got error %v", err)
+	}
+	if err := tx.Exec("INSERT INTO acquire_conn_temps (id) VALUES (?)", 1).Error; err != nil {
+		t.Fatalf("failed to insert, got error %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		var count int64
+		if err := tx.Table("acquire_conn_temps").Count(&count).Error; err != nil || count != 1 {
+			t.Fatalf("failed to count on pinned connection, got %v, error %v", count, err)
+		}
+	}
+	AssertEqual(t, sqlDB.Stats().InUse, 1)
+
+	if err := release(); err != nil {
+		t.Fatalf("failed to release connection, got error %v", err)
+	}
+	AssertEqual(t, release(), nil)
+	AssertEqual(t, sqlDB.Stats().InUse, 0)
+
+	var count int64
+	if err := tx.Table("acquire_conn_temps").Count(&count).Error; !errors.Is(err, gorm.ErrConnReleased) {
+		t.Errorf("expected ErrConnReleased, got %v", err)
+	}
+	if err := tx.Exec("INSERT INTO acquire_conn_temps (id) VALUES (?)", 2).Error; !errors.Is(err, gorm.ErrConnReleased) {
+		t.Errorf("expected ErrConnReleased, got %v", err)
+	}
+	if err := tx.Transaction(func(tx *gorm.DB) error { return nil }); !errors.Is(err, gorm.ErrConnReleased) {
+		t.Errorf("expected ErrConnReleased, got %v", err)
+	}
+}
+
+func TestAcquireConnFinalizer(t *testing.T) {
+	for _, prepareStmt := range []bool{false, true} {
+		t.Run(fmt.Sprintf("PrepareStmt=%v", prepareStmt), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			db, err := OpenTestConnection(&gorm.Config{
+				PrepareStmt: prepareStmt,
+				Logger:      logger.New(log.New(buf, "", 0), logger.Config{LogLevel: logger.Error}),
+			})
+			if err != nil {
+				t.Fatalf("failed to connect database, got error %v", err)
+			}
+			sqlDB, _ := db.DB()
+			defer sqlDB.Close()
+
+			func() {
+				tx, _, err := db.AcquireConn(context.Background())
+				if err != nil {
+					t.Fatalf("failed to acquire connection, got error %v", err)
+				}
+				var count int64
+				tx.Model(&User{}).Where("name = ?", "acquire_conn_finalizer").Count(&count)
+			}()
+
+			for i := 0; i < 100 && sqlDB.Stats().InUse > 0; i++ {
+				runtime.GC()
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			AssertEqual(t, sqlDB.Stats().InUse, 0)
+			if !strings.Contains(buf.String(), "was never released") {
+				t.Errorf("expected error log for the connection never released, got %v", buf.String())
+			}
+		})
+	}
+}
@@ -3,9 +3,12 @@ package tests_test
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"os"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -171,3 +174,116 @@ func TestConnPoolWrapper(t *testing.T) {
 		t.Fatalf("Should be able to find committed record, but got %v", err)
 	}
 }
+
+type poolEventRecorder struct {
+	mux    sync.Mutex
+	events []gorm.PoolEvent
+}
+
+func (r *poolEventRecorder) handle(ctx context.Context, event gorm.PoolEvent) {
+	r.mux.Lock()
+	r.events = append(r.events, event)
+	r.mux.Unlock()
+}
+
+func (r *poolEventRecorder) count(typ gorm.PoolEventType) (n int) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	for _, event := range r.events {
+		if event.Type == typ {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *poolEventRecorder) reset() {
+	r.mux.Lock()
+	r.events = nil
+	r.mux.Unlock()
+}
+
+func TestPoolEventHandler(t *testing.T) {
+	recorder := &poolEventRecorder{}
+	db, err := OpenTestConnection(&gorm.Config{PoolEventHandler: recorder.handle})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+	defer func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	recorder.reset()
+	user := *GetUser("pool_event", Config{})
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+	AssertEqual(t, recorder.count(gorm.PoolWait), 0)
+	AssertEqual(t, recorder.count(gorm.PoolTxBegin), 1)
+	AssertEqual(t, recorder.count(gorm.PoolTxCommit), 1)
+
+	recorder.reset()
+	var result User
+	if err := db.First(&result, user.ID).Error; err != nil {
+		t.Fatalf("failed to find user, got error %v", err)
+	}
+	var count int64
+	if err := db.Model(&User{}).Where("id = ?", user.ID).Count(&count).Error; err != nil || count != 1 {
+		t.Fatalf("failed to count users, got %v, error %v", count, err)
+	}
+	AssertEqual(t, recorder.count(gorm.PoolTxBegin), 0)
+
+	recorder.reset()
+	db.Transaction(func(tx *gorm.DB) error {
+		tx.First(&User{}, user.ID)
+		return errors.New("rollback")
+	})
+	AssertEqual(t, recorder.count(gorm.PoolTxRollback), 1)
+
+	recorder.reset()
+	if err := db.Session(&gorm.Session{PrepareStmt: boolPtr(true)}).First(&User{}, user.ID).Error; err != nil {
+		t.Fatalf("failed to find user, got error %v", err)
+	}
+	AssertEqual(t, recorder.count(gorm.PoolStmtPrepared), 1)
+
+	// the waits of the pool are reported once, by the statement observing them first
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	recorder.reset()
+	tx := db.Begin()
+	done := make(chan error)
+	go func() {
+		done <- db.First(&User{}, user.ID).Error
+	}()
+	for stats, _ := db.Stats(); stats.WaitCount == 0; stats, _ = db.Stats() {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	tx.Rollback()
+	if err := <-done; err != nil {
+		t.Fatalf("failed to find user, got error %v", err)
+	}
+	db.First(&User{}, user.ID)
+	sqlDB.SetMaxOpenConns(0)
+
+	AssertEqual(t, recorder.count(gorm.PoolWait), 1)
+	for _, event := range recorder.events {
+		if event.Type == gorm.PoolWait && (event.Waits != 1 || event.Wait < 10*time.Millisecond || event.Stats.MaxOpenConnections != 1) {
+			t.Errorf("the wait of the pool should be reported, got %+v", event)
+		}
+	}
+
+	stats, err := db.Stats()
+	if err != nil {
+		t.Fatalf("failed to get stats, got error %v", err)
+	}
+	AssertEqual(t, stats.InUse, 0)
+
+	dummyDB, _ := gorm.Open(DummyDialector{}, &gorm.Config{})
+	var unsupported *gorm.UnsupportedConnPoolError
+	if _, err := dummyDB.Stats(); !errors.As(err, &unsupported) || !errors.Is(err, gorm.ErrInvalidDB) {
+		t.Errorf("expected UnsupportedConnPoolError, got %v", err)
+	}
+}
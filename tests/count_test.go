@@ -189,3 +189,66 @@ func TestCount(t *testing.T) {
 		t.Errorf("no error should raise when using count with preload, but got %v", err)
 	}
 }
+
+type namedDialector struct {
+	gorm.Dialector
+	name string
+}
+
+func (dialector namedDialector) Name() string {
+	return dialector.name
+}
+
+func TestEstimatedCount(t *testing.T) {
+	DB.Create(&[]Company{{Name: "estimated_count_1"}, {Name: "estimated_count_2"}})
+
+	var exact, estimated int64
+	DB.Model(&Company{}).Where("name LIKE ?", "estimated_count_%").Count(&exact)
+	strategy, err := DB.Model(&Company{}).Where("name LIKE ?", "estimated_count_%").EstimatedCount(&estimated)
+	if err != nil || strategy != gorm.CountExact || estimated != exact {
+		t.Errorf("queries with conditions should be counted exactly, got %v %v, error %v", strategy, estimated, err)
+	}
+
+	if DB.Dialector.Name() == "sqlite" {
+		DB.Model(&Company{}).Count(&exact)
+		if strategy, err = DB.Model(&Company{}).EstimatedCount(&estimated); err != nil || strategy != gorm.CountExact || estimated != exact {
+			t.Errorf("sqlite has no statistics, should be counted exactly, got %v %v, error %v", strategy, estimated, err)
+		}
+	}
+
+	tests := []struct {
+		dialect  string
+		planner  bool
+		query    func(tx *gorm.DB) *gorm.DB
+		strategy gorm.CountStrategy
+		sql      string
+	}{
+		{dialect: "postgres", query: func(tx *gorm.DB) *gorm.DB { return tx.Model(&Company{}) }, strategy: gorm.CountStatistics, sql: "FROM pg_class c WHERE c.oid = to_regclass("},
+		{dialect: "mysql", query: func(tx *gorm.DB) *gorm.DB { return tx.Model(&Company{}) }, strategy: gorm.CountStatistics, sql: "SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = \"companies\""},
+		{dialect: "mysql", query: func(tx *gorm.DB) *gorm.DB { return tx.Table("shop.companies") }, strategy: gorm.CountExact, sql: "SELECT count(*) FROM"},
+		{dialect: "postgres", query: func(tx *gorm.DB) *gorm.DB { return tx.Model(&User{}) }, strategy: gorm.CountExact, sql: "SELECT count(*) FROM"},
+		{dialect: "postgres", query: func(tx *gorm.DB) *gorm.DB { return tx.Model(&User{}).Unscoped() }, strategy: gorm.CountStatistics, sql: "FROM pg_class c"},
+		{dialect: "postgres", query: func(tx *gorm.DB) *gorm.DB { return tx.Model(&Company{}).Where("name = ?", "a") }, strategy: gorm.CountExact, sql: "SELECT count(*) FROM"},
+		{dialect: "postgres", planner: true, query: func(tx *gorm.DB) *gorm.DB { return tx.Model(&Company{}).Where("name = ?", "a") }, strategy: gorm.CountPlanner, sql: "EXPLAIN (FORMAT JSON) SELECT * FROM"},
+		{dialect: "mysql", planner: true, query: func(tx *gorm.DB) *gorm.DB { return tx.Model(&Company{}).Limit(10) }, strategy: gorm.CountPlanner, sql: "EXPLAIN FORMAT=JSON SELECT * FROM"},
+		{dialect: "sqlite", planner: true, query: func(tx *gorm.DB) *gorm.DB { return tx.Model(&Company{}) }, strategy: gorm.CountExact, sql: "SELECT count(*) FROM"},
+	}
+
+	for _, test := range tests {
+		tx := DB.Session(&gorm.Session{AllowPlannerEstimate: test.planner})
+		tx.Config.Dialector = namedDialector{Dialector: DB.Dialector, name: test.dialect}
+
+		var count int64
+		sqls := tx.ToSQLs(func(tx *gorm.DB) *gorm.DB {
+			strategy, err = test.query(tx).EstimatedCount(&count)
+			return tx
+		})
+
+		if err != nil || strategy != test.strategy {
+			t.Errorf("%v should be counted by %v, got %v, error %v", test.dialect, test.strategy, strategy, err)
+		}
+		if len(sqls) != 1 || !strings.Contains(strings.ReplaceAll(sqls[0], "`", "\""), test.sql) {
+			t.Errorf("%v should count by %q, got %v", test.dialect, test.sql, sqls)
+		}
+	}
+}
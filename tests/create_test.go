@@ -1,9 +1,11 @@
 package tests_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -791,3 +793,167 @@ func TestCreateFromMapWithTable(t *testing.T) {
 		t.Errorf("failed to create data from map with table, @id != id")
 	}
 }
+
+type ArchivedUser struct {
+	ID   uint
+	Name string
+	Age  uint
+
+	beforeCreateCalled, afterCreateCalled int
+}
+
+func (u *ArchivedUser) BeforeCreate(tx *gorm.DB) error {
+	if tx.Statement.CreatingFromQuery() {
+		u.beforeCreateCalled++
+	}
+	return nil
+}
+
+func (u *ArchivedUser) AfterCreate(tx *gorm.DB) error {
+	if tx.Statement.CreatingFromQuery() {
+		u.afterCreateCalled++
+	}
+	return nil
+}
+
+func TestCreateFromQuery(t *testing.T) {
+	DB.Migrator().DropTable(&ArchivedUser{})
+	if err := DB.AutoMigrate(&ArchivedUser{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	users := []User{*GetUser("create_from_query", Config{}), *GetUser("create_from_query", Config{}), *GetUser("create_from_query", Config{})}
+	DB.Create(&users)
+
+	source := func() *gorm.DB {
+		return DB.Model(&User{}).Select("id", "name", "age").Where("name = ?", "create_from_query")
+	}
+
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	stmt := dryDB.Model(&ArchivedUser{}).CreateFromQuery([]string{"ID", "name", "age"}, source()).Statement
+	if !regexp.MustCompile("INSERT INTO `archived_users` \\(`id`,`name`,`age`\\) SELECT `id`,`name`,`age` FROM `users` WHERE name = .+ AND `users`.`deleted_at` IS NULL").MatchString(stmt.SQL.String()) {
+		t.Errorf("unexpected insert from query, got %v", stmt.SQL.String())
+	}
+
+	var archived ArchivedUser
+	if result := DB.Model(&archived).CreateFromQuery([]string{"ID", "name", "age"}, source()); result.Error != nil || result.RowsAffected != 3 {
+		t.Fatalf("failed to create from query, rows affected %v, error %v", result.RowsAffected, result.Error)
+	}
+
+	if archived.beforeCreateCalled != 1 || archived.afterCreateCalled != 1 || archived.ID != 0 {
+		t.Errorf("create hooks should be called once with the model, got %+v", archived)
+	}
+
+	var results []ArchivedUser
+	DB.Order("id").Find(&results)
+	if len(results) != 3 {
+		t.Fatalf("should create 3 archived users, got %v", len(results))
+	}
+
+	for idx, result := range results {
+		if result.ID != users[idx].ID || result.Name != users[idx].Name || result.Age != users[idx].Age {
+			t.Errorf("archived user %+v should equal to user %+v", result, users[idx])
+		}
+	}
+
+	if err := DB.Model(&ArchivedUser{}).Clauses(clause.OnConflict{DoNothing: true}).CreateFromQuery([]string{"id", "name", "age"}, source()).Error; err != nil {
+		t.Errorf("failed to create from query on conflict, got error %v", err)
+	}
+
+	if err := DB.Model(&ArchivedUser{}).Clauses(clause.OnConflict{DoNothing: true}).CreateFromQuery([]string{"id", "name", "age"}, DB.Table("users").Select("id, name, age")).Error; err != nil {
+		t.Errorf("failed to create from query without conditions on conflict, got error %v", err)
+	}
+
+	if err := DB.Model(&ArchivedUser{}).CreateFromQuery([]string{"id", "name"}, source()).Error; !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("should return ErrInvalidData for mismatched columns, got %v", err)
+	}
+
+	if err := DB.Model(&ArchivedUser{}).CreateFromQuery([]string{"id", "name", "active"}, source()).Error; !errors.Is(err, gorm.ErrInvalidField) {
+		t.Errorf("should return ErrInvalidField for unknown columns, got %v", err)
+	}
+
+	if err := DB.CreateFromQuery([]string{"id"}, source()).Error; !errors.Is(err, gorm.ErrModelValueRequired) {
+		t.Errorf("should return ErrModelValueRequired without model, got %v", err)
+	}
+}
+
+type MapDefaultUser struct {
+	ID    uint
+	Name  string
+	Role  string `gorm:"not null;default:member"`
+	Score int    `gorm:"default:10"`
+	Code  string
+}
+
+func TestCreateFromHeterogeneousMaps(t *testing.T) {
+	DB.Migrator().DropTable(&MapDefaultUser{})
+	if err := DB.AutoMigrate(&MapDefaultUser{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	countInserts := func(sqls []string) (count int) {
+		for _, sql := range sqls {
+			if strings.HasPrefix(sql, "INSERT") {
+				count++
+			}
+		}
+		return
+	}
+
+	checkUsers := func(names ...string) {
+		t.Helper()
+		var users []MapDefaultUser
+		DB.Where("name IN ?", names).Order("name").Find(&users)
+		if len(users) != 3 {
+			t.Fatalf("should create 3 users, got %+v", users)
+		}
+
+		expects := []MapDefaultUser{{Role: "admin", Score: 10, Code: "A"}, {Role: "member", Score: 5}, {Role: "member", Score: 10, Code: "C"}}
+		for idx, user := range users {
+			if user.Name != names[idx] || user.Role != expects[idx].Role || user.Score != expects[idx].Score || user.Code != expects[idx].Code {
+				t.Errorf("user %+v should be %+v", user, expects[idx])
+			}
+		}
+	}
+
+	db := DB.Session(&gorm.Session{Context: context.Background(), SkipDefaultTransaction: true})
+	recorder := &commentRecorder{ConnPool: db.Statement.ConnPool}
+	db.Statement.ConnPool = recorder
+
+	if err := db.Model(&MapDefaultUser{}).Create(&[]map[string]interface{}{
+		{"name": "map_default_1", "role": "admin", "code": "A"},
+		{"Name": "map_default_2", "score": 5},
+		{"name": "map_default_3", "code": gorm.Expr("UPPER(?)", "c")},
+	}).Error; err != nil {
+		t.Fatalf("failed to create maps with different keys, got error %v", err)
+	}
+	checkUsers("map_default_1", "map_default_2", "map_default_3")
+
+	if countInserts(recorder.sqls) != 1 {
+		t.Errorf("should create the maps with one INSERT, got %v", recorder.sqls)
+	}
+
+	recorder.sqls = nil
+	groupDB := db.Session(&gorm.Session{GroupMapsByKeys: true})
+	if result := groupDB.Model(&MapDefaultUser{}).Create([]map[string]interface{}{
+		{"name": "map_group_1", "role": "admin", "code": "A"},
+		{"name": "map_group_2", "score": 5},
+		{"name": "map_group_3", "code": "C"},
+	}); result.Error != nil || result.RowsAffected != 3 {
+		t.Fatalf("failed to create maps grouped by keys, rows affected %v, error %v", result.RowsAffected, result.Error)
+	}
+	checkUsers("map_group_1", "map_group_2", "map_group_3")
+
+	if countInserts(recorder.sqls) != 3 {
+		t.Errorf("should create the maps with an INSERT per key set, got %v", recorder.sqls)
+	}
+
+	if err := DB.Model(&MapDefaultUser{}).Create(map[string]interface{}{"name": "map_expr", "code": gorm.Expr("UPPER(?)", "e")}).Error; err != nil {
+		t.Fatalf("failed to create map with expression, got error %v", err)
+	}
+
+	var user MapDefaultUser
+	if err := DB.Where("name = ?", "map_expr").First(&user).Error; err != nil || user.Code != "E" || user.Role != "member" {
+		t.Errorf("unexpected user created from map with expression %+v, error %v", user, err)
+	}
+}
@@ -1,10 +1,12 @@
 package tests_test
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
 	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
 )
 
 func TestDefaultValue(t *testing.T) {
@@ -57,3 +59,86 @@ func TestDefaultValue(t *testing.T) {
 		t.Fatalf("Failed to create data with default value, got: %+v", harumph2)
 	}
 }
+
+type OptionalProduct struct {
+	ID     uint
+	Name   string              `gorm:"default:unnamed"`
+	Stock  gorm.Optional[int]  `gorm:"default:10"`
+	Active gorm.Optional[bool] `gorm:"default:true"`
+	Note   gorm.Optional[string]
+}
+
+func TestOptionalValue(t *testing.T) {
+	DB.Migrator().DropTable(&OptionalProduct{})
+	if err := DB.AutoMigrate(&OptionalProduct{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	products := []OptionalProduct{
+		{Name: "explicit", Stock: gorm.Set(0), Active: gorm.Set(false), Note: gorm.Set("")},
+		{Name: "unset"},
+	}
+	if err := DB.Create(&products).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	var results []OptionalProduct
+	DB.Order("id").Find(&results)
+	if len(results) != 2 {
+		t.Fatalf("should find 2 products, got %v", len(results))
+	}
+	AssertEqual(t, results[0].Stock, gorm.Set(0))
+	AssertEqual(t, results[0].Active, gorm.Set(false))
+	AssertEqual(t, results[0].Note, gorm.Set(""))
+	AssertEqual(t, results[1].Stock, gorm.Set(10))
+	AssertEqual(t, results[1].Active, gorm.Set(true))
+	if results[1].Note.IsSet() {
+		t.Errorf("the NULL value shouldn't be set, got %+v", results[1].Note)
+	}
+
+	// the struct conditions include the set zero values
+	var product OptionalProduct
+	if err := DB.Where(&OptionalProduct{Stock: gorm.Set(0)}).First(&product).Error; err != nil || product.Name != "explicit" {
+		t.Errorf("should find by the set zero value, got %+v, error %v", product, err)
+	}
+
+	t.Run("UseDefault", func(t *testing.T) {
+		product := OptionalProduct{Name: "default", Stock: gorm.UseDefault[int]()}
+		if err := DB.Create(&product).Error; err != nil {
+			t.Fatalf("failed to create, got error %v", err)
+		}
+
+		var result OptionalProduct
+		DB.First(&result, product.ID)
+		AssertEqual(t, result.Stock, gorm.Set(10))
+
+		DB.Model(&result).Updates(OptionalProduct{Stock: gorm.Set(0)})
+		DB.First(&result, product.ID)
+		AssertEqual(t, result.Stock, gorm.Set(0))
+
+		DB.Model(&result).Updates(map[string]interface{}{"stock": gorm.Default{}, "name": "default updated"})
+		DB.First(&result, product.ID)
+		AssertEqual(t, result.Stock, gorm.Set(10))
+		AssertEqual(t, result.Name, "default updated")
+
+		if err := DB.Model(&OptionalProduct{}).Create(map[string]interface{}{"Name": gorm.Default{}, "Stock": 3}).Error; err != nil {
+			t.Fatalf("failed to create map, got error %v", err)
+		}
+		var created OptionalProduct
+		DB.Last(&created)
+		AssertEqual(t, created.Name, "unnamed")
+		AssertEqual(t, created.Stock, gorm.Set(3))
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		data, _ := json.Marshal(OptionalProduct{Stock: gorm.Set(0)})
+		var product OptionalProduct
+		if err := json.Unmarshal(data, &product); err != nil {
+			t.Fatalf("failed to unmarshal, got error %v", err)
+		}
+		AssertEqual(t, product.Stock, gorm.Set(0))
+		if product.Note.IsSet() {
+			t.Errorf("null shouldn't be set, got %+v", product.Note)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package tests_test
 
 import (
 	"errors"
+	"regexp"
 	"testing"
 
 	"gorm.io/gorm"
@@ -256,3 +257,166 @@ func TestDeleteReturning(t *testing.T) {
 		t.Errorf("failed to delete data, current count %v", count)
 	}
 }
+
+func TestBlockGlobalWrite(t *testing.T) {
+	type GlobalWriteItem struct {
+		ID        uint
+		Name      string
+		DeletedAt gorm.DeletedAt
+	}
+
+	DB.Migrator().DropTable(&GlobalWriteItem{})
+	DB.AutoMigrate(&GlobalWriteItem{})
+	DB.Create(&[]GlobalWriteItem{{Name: "a"}, {Name: "b"}, {Name: "c"}})
+
+	db := DB.Session(&gorm.Session{BlockGlobalWrite: true, AllowGlobalUpdate: true})
+
+	for _, sql := range []string{
+		"DELETE FROM global_write_items",
+		"  update global_write_items SET name = 'where'",
+		"UPDATE global_write_items SET name = (SELECT name FROM users WHERE id = 1)",
+	} {
+		if err := db.Exec(sql).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+			t.Errorf("%v: expected ErrMissingWhereClause, got %v", sql, err)
+		}
+	}
+
+	if err := db.Exec("UPDATE global_write_items SET name = ? WHERE name = ?", "c", "c").Error; err != nil {
+		t.Errorf("raw update with conditions should work, got %v", err)
+	}
+	if err := db.Exec("SELECT * FROM global_write_items").Error; err != nil {
+		t.Errorf("raw select should work, got %v", err)
+	}
+
+	if err := db.Model(&GlobalWriteItem{}).Updates(map[string]interface{}{"name": "x"}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("expected ErrMissingWhereClause for map updates, got %v", err)
+	}
+	if err := db.Model(&GlobalWriteItem{}).Where("name = ?", "a").Updates(map[string]interface{}{"name": "a"}).Error; err != nil {
+		t.Errorf("map updates with conditions should work, got %v", err)
+	}
+
+	// soft delete
+	if err := db.Delete(&GlobalWriteItem{}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("expected ErrMissingWhereClause for soft delete, got %v", err)
+	}
+	if err := db.Where("name = ?", "a").Delete(&GlobalWriteItem{}).Error; err != nil {
+		t.Errorf("soft delete with conditions should work, got %v", err)
+	}
+
+	var count int64
+	DB.Model(&GlobalWriteItem{}).Count(&count)
+	AssertEqual(t, count, 2)
+
+	if err := db.AllowGlobal().Delete(&GlobalWriteItem{}).Error; err != nil {
+		t.Errorf("AllowGlobal should allow deleting without conditions, got %v", err)
+	}
+	if err := db.Unscoped().AllowGlobal().Exec("DELETE FROM global_write_items").Error; err != nil {
+		t.Errorf("AllowGlobal should allow raw delete without conditions, got %v", err)
+	}
+	DB.Unscoped().Model(&GlobalWriteItem{}).Count(&count)
+	AssertEqual(t, count, 0)
+
+	// associations
+	user := *GetUser("block_global_write", Config{Pets: 2, Languages: 2})
+	DB.Create(&user)
+
+	if err := db.Model(&user).Association("Pets").Replace(&Pet{Name: "replaced"}); err != nil {
+		t.Errorf("replacing has many association should work, got %v", err)
+	}
+	if err := db.Model(&user).Association("Pets").Clear(); err != nil {
+		t.Errorf("clearing has many association should work, got %v", err)
+	}
+	if err := db.Model(&user).Association("Languages").Clear(); err != nil {
+		t.Errorf("clearing many2many association should work, got %v", err)
+	}
+	AssertEqual(t, DB.Model(&user).Association("Pets").Count(), int64(0))
+	AssertEqual(t, DB.Model(&user).Association("Languages").Count(), int64(0))
+}
+
+func TestDeleteWithLimit(t *testing.T) {
+	users := []User{
+		*GetUser("delete_with_limit", Config{}), *GetUser("delete_with_limit", Config{}), *GetUser("delete_with_limit", Config{}),
+	}
+	DB.Create(&users)
+
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	stmt := dryDB.Where("name = ?", "delete_with_limit").Order("id desc").Limit(2).Delete(&User{}).Statement
+	if !regexp.MustCompile("`users`.`id` IN \\(SELECT `id` FROM \\(SELECT `users`.`id` FROM `users` WHERE name = .+ AND `users`.`deleted_at` IS NULL ORDER BY id desc LIMIT .+\\) AS `gorm_limited`\\)").MatchString(stmt.SQL.String()) {
+		t.Errorf("unexpected limited delete, got %v", stmt.SQL.String())
+	}
+
+	if result := DB.Where("name = ?", "delete_with_limit").Order("id desc").Limit(2).Delete(&User{}); result.Error != nil || result.RowsAffected != 2 {
+		t.Fatalf("failed to delete with limit, rows affected %v, error %v", result.RowsAffected, result.Error)
+	}
+
+	var remains []User
+	if err := DB.Where("name = ?", "delete_with_limit").Find(&remains).Error; err != nil || len(remains) != 1 || remains[0].ID != users[0].ID {
+		t.Errorf("the first user should remain, got %+v, error %v", remains, err)
+	}
+
+	if result := DB.Unscoped().Where("name = ?", "delete_with_limit").Order("id").Limit(1).Delete(&User{}); result.Error != nil || result.RowsAffected != 1 {
+		t.Fatalf("failed to delete unscoped with limit, rows affected %v, error %v", result.RowsAffected, result.Error)
+	}
+
+	var count int64
+	DB.Unscoped().Model(&User{}).Where("name = ?", "delete_with_limit").Count(&count)
+	if count != 2 {
+		t.Errorf("should delete the first soft deleted user permanently, but got count %v", count)
+	}
+
+	if err := DB.Limit(1).Delete(&User{}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("should return ErrMissingWhereClause for limited delete without conditions, got %v", err)
+	}
+
+	if err := DB.Table("users").Where("name = ?", "delete_with_limit").Limit(1).Delete(map[string]interface{}{}).Error; !errors.Is(err, gorm.ErrPrimaryKeyRequired) {
+		t.Errorf("should return ErrPrimaryKeyRequired for limited delete without primary keys, got %v", err)
+	}
+}
+
+func TestDeleteInBatches(t *testing.T) {
+	users := make([]User, 0, 5)
+	for i := 0; i < 5; i++ {
+		users = append(users, *GetUser("delete_in_batches", Config{}))
+	}
+	DB.Create(&users)
+
+	var batches []int64
+	result := DB.Where("name = ?", "delete_in_batches").Order("id").DeleteInBatches(&User{}, 2, func(tx *gorm.DB, batch int) error {
+		if batch != len(batches)+1 {
+			t.Errorf("unexpected batch %v", batch)
+		}
+		batches = append(batches, tx.RowsAffected)
+		return nil
+	})
+
+	if result.Error != nil || result.RowsAffected != 5 {
+		t.Errorf("failed to delete in batches, rows affected %v, error %v", result.RowsAffected, result.Error)
+	}
+
+	if len(batches) != 3 || batches[0] != 2 || batches[1] != 2 || batches[2] != 1 {
+		t.Errorf("unexpected batches %v", batches)
+	}
+
+	var count int64
+	DB.Unscoped().Model(&User{}).Where("name = ? AND deleted_at IS NOT NULL", "delete_in_batches").Count(&count)
+	if count != 5 {
+		t.Errorf("all users should be soft deleted, but got %v", count)
+	}
+
+	if err := DB.Where("name = ?", "delete_in_batches").DeleteInBatches(&User{}, 0, nil).Error; !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("should return ErrInvalidData for invalid batch size, got %v", err)
+	}
+
+	batchErr := errors.New("batch error")
+	DB.Unscoped().Model(&User{}).Where("name = ?", "delete_in_batches").Update("deleted_at", nil)
+	if err := DB.Where("name = ?", "delete_in_batches").DeleteInBatches(&User{}, 2, func(tx *gorm.DB, batch int) error {
+		return batchErr
+	}).Error; !errors.Is(err, batchErr) {
+		t.Errorf("should return the error of the batch, got %v", err)
+	}
+
+	DB.Unscoped().Model(&User{}).Where("name = ? AND deleted_at IS NOT NULL", "delete_in_batches").Count(&count)
+	if count != 2 {
+		t.Errorf("should stop after the failed batch, but got %v deleted", count)
+	}
+}
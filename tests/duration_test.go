@@ -0,0 +1,111 @@
+package tests_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type DurationTask struct {
+	ID      uint
+	Name    string
+	Timeout time.Duration  `gorm:"durationUnit:ms"`
+	Backoff *time.Duration `gorm:"durationUnit:s"`
+	Elapsed time.Duration
+}
+
+func TestDurationFields(t *testing.T) {
+	DB.Migrator().DropTable(&DurationTask{})
+	if err := DB.AutoMigrate(&DurationTask{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	backoff := 30 * time.Second
+	task := DurationTask{Name: "duration", Timeout: 1500 * time.Millisecond, Backoff: &backoff, Elapsed: 2 * time.Second}
+	if err := DB.Create(&task).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	var stored struct {
+		Timeout int64
+		Backoff int64
+		Elapsed int64
+	}
+	DB.Table("duration_tasks").Where("id = ?", task.ID).Scan(&stored)
+	if stored.Timeout != 1500 || stored.Backoff != 30 || stored.Elapsed != int64(2*time.Second) {
+		t.Errorf("the durations should be stored as their units, got %+v", stored)
+	}
+
+	var result DurationTask
+	if err := DB.First(&result, task.ID).Error; err != nil {
+		t.Fatalf("failed to query, got error %v", err)
+	}
+	AssertEqual(t, result, task)
+
+	var count int64
+	DB.Model(&DurationTask{}).Where(&DurationTask{Timeout: 1500 * time.Millisecond}).Count(&count)
+	if count != 1 {
+		t.Errorf("the struct conditions should bind milliseconds, got %v", count)
+	}
+
+	DB.Model(&DurationTask{}).Where(map[string]interface{}{"timeout": 1500 * time.Millisecond, "backoff": []time.Duration{backoff, time.Minute}}).Count(&count)
+	if count != 1 {
+		t.Errorf("the map conditions should bind the units, got %v", count)
+	}
+
+	if err := DB.Model(&result).Updates(map[string]interface{}{"Timeout": 3 * time.Second, "backoff": time.Minute}).Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+	DB.Table("duration_tasks").Where("id = ?", task.ID).Scan(&stored)
+	if stored.Timeout != 3000 || stored.Backoff != 60 || result.Timeout != 3*time.Second {
+		t.Errorf("the updated durations should be stored as their units, got %+v, %v", stored, result.Timeout)
+	}
+
+	if err := DB.Model(&DurationTask{}).Create(map[string]interface{}{"Name": "duration map", "Timeout": 250 * time.Millisecond}).Error; err != nil {
+		t.Fatalf("failed to create map, got error %v", err)
+	}
+	DB.Table("duration_tasks").Where("name = ?", "duration map").Scan(&stored)
+	if stored.Timeout != 250 {
+		t.Errorf("the created duration should be stored as milliseconds, got %+v", stored)
+	}
+
+	tx := DB.Session(&gorm.Session{TrackChanges: true})
+	var tracked DurationTask
+	tx.First(&tracked, task.ID)
+	tracked.Timeout = 3*time.Second + time.Microsecond
+	if changed := gorm.ChangedFields(tx, &tracked); changed != nil {
+		t.Errorf("the durations of the same milliseconds shouldn't be changed, got %v", changed)
+	}
+
+	tracked.Timeout = 4 * time.Second
+	AssertEqual(t, gorm.ChangedFields(tx, &tracked), []string{"Timeout"})
+
+	type DurationInterval struct {
+		ID      uint
+		Timeout time.Duration `gorm:"type:interval"`
+	}
+
+	if DB.Dialector.Name() != "postgres" {
+		if err := DB.First(&DurationInterval{}).Error; !errors.Is(err, gorm.ErrUnsupportedDriver) {
+			t.Errorf("the interval fields should be rejected by %s, got %v", DB.Dialector.Name(), err)
+		}
+		return
+	}
+
+	DB.Migrator().DropTable(&DurationInterval{})
+	if err := DB.AutoMigrate(&DurationInterval{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	interval := DurationInterval{Timeout: 26*time.Hour + 1500*time.Millisecond}
+	if err := DB.Create(&interval).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	var intervalResult DurationInterval
+	DB.Where(map[string]interface{}{"timeout": interval.Timeout}).First(&intervalResult)
+	AssertEqual(t, intervalResult, interval)
+}
@@ -0,0 +1,108 @@
+package tests_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+func TestDynamicSchema(t *testing.T) {
+	dynamic := schema.NewDynamic("custom_objects_42").
+		Field("id", schema.Uint, schema.PrimaryKey).
+		Field("name", schema.String, schema.NotNull, "size:256").
+		Field("code", schema.String, schema.CreateOnly).
+		Field("secret", schema.String, schema.WriteOnly).
+		Field("version", schema.Int, schema.ReadOnly, "default:1").
+		Field("created_at", schema.Int, "autoCreateTime:milli").
+		Field("updated_at", schema.Time, schema.AutoUpdateTime).
+		Field("deleted_at", schema.Time, schema.SoftDelete)
+
+	if err := DB.RegisterDynamicSchema(dynamic); err != nil {
+		t.Fatalf("failed to register dynamic schema, got error %v", err)
+	}
+
+	DB.Migrator().DropTable("custom_objects_42")
+	if err := DB.Migrator().AutoMigrate(dynamic); err != nil {
+		t.Fatalf("failed to migrate dynamic schema, got error %v", err)
+	}
+
+	for _, column := range []string{"id", "name", "code", "secret", "version", "created_at", "updated_at", "deleted_at"} {
+		if !DB.Migrator().HasColumn("custom_objects_42", column) {
+			t.Errorf("column %s should be migrated", column)
+		}
+	}
+
+	values := map[string]interface{}{"name": "dynamic_1", "code": "c1", "secret": "s1", "version": 5}
+	if err := DB.Table("custom_objects_42").Create(values).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	if values["id"] == nil {
+		t.Errorf("primary key should be set, got %v", values)
+	}
+
+	if createdAt, ok := values["created_at"].(int64); !ok || time.Since(time.UnixMilli(createdAt)) > time.Minute {
+		t.Errorf("created_at should be set as unix milliseconds, got %#v", values["created_at"])
+	}
+
+	if updatedAt, ok := values["updated_at"].(time.Time); !ok || updatedAt.IsZero() {
+		t.Errorf("updated_at should be set, got %#v", values["updated_at"])
+	}
+
+	DB.Table("custom_objects_42").Create(&[]map[string]interface{}{{"name": "dynamic_2"}, {"name": "dynamic_3"}})
+
+	var results []map[string]interface{}
+	if err := DB.Table("custom_objects_42").Order("name").Find(&results).Error; err != nil {
+		t.Fatalf("failed to find, got error %v", err)
+	}
+
+	if len(results) != 3 || results[0]["name"] != "dynamic_1" {
+		t.Fatalf("rows should be found, got %v", results)
+	}
+
+	if _, ok := results[0]["secret"]; ok {
+		t.Errorf("write only column shouldn't be read, got %v", results[0])
+	}
+
+	if results[0]["code"] != "c1" || results[2]["created_at"] == nil {
+		t.Errorf("columns should be created, got %v", results)
+	}
+
+	var version int64
+	DB.Table("custom_objects_42").Where("name = ?", "dynamic_1").Select("version").Scan(&version)
+	if version != 1 {
+		t.Errorf("read only column shouldn't be created, got %v", version)
+	}
+
+	if err := DB.Model("custom_objects_42").Where("name = ?", "dynamic_1").Updates(map[string]interface{}{"code": "c2", "name": "dynamic_1_new"}).Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := DB.Model("custom_objects_42").Where("name = ?", "dynamic_1_new").Take(&result).Error; err != nil {
+		t.Fatalf("failed to find updated row, got error %v", err)
+	}
+
+	if result["code"] != "c1" {
+		t.Errorf("create only column shouldn't be updated, got %v", result)
+	}
+
+	if err := DB.Table("custom_objects_42").Where("name = ?", "dynamic_2").Delete(map[string]interface{}{}).Error; err != nil {
+		t.Fatalf("failed to delete, got error %v", err)
+	}
+
+	var count int64
+	if DB.Table("custom_objects_42").Find(&[]map[string]interface{}{}).Count(&count); count != 2 {
+		t.Errorf("soft deleted row shouldn't be found, got %v", count)
+	}
+
+	if DB.Unscoped().Table("custom_objects_42").Find(&[]map[string]interface{}{}).Count(&count); count != 3 {
+		t.Errorf("soft deleted row should be kept, got %v", count)
+	}
+
+	if err := DB.RegisterDynamicSchema(schema.NewDynamic("custom_objects_43").Field("id", "uuid")); !errors.Is(err, schema.ErrUnsupportedDataType) {
+		t.Errorf("should return ErrUnsupportedDataType for the unknown data type, got %v", err)
+	}
+}
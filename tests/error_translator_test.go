@@ -2,6 +2,8 @@ package tests_test
 
 import (
 	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 
 	"gorm.io/gorm"
@@ -109,3 +111,114 @@ func TestSupportedDialectorWithErrForeignKeyViolated(t *testing.T) {
 		t.Fatalf("expected err: %v got err: %v", gorm.ErrForeignKeyViolated, err)
 	}
 }
+
+func TestParseConstraintError(t *testing.T) {
+	cases := []struct {
+		msg      string
+		expected *gorm.ConstraintError
+	}{
+		// mysql
+		{"Error 1062 (23000): Duplicate entry 'jinzhu' for key 'users.idx_users_name'", &gorm.ConstraintError{Err: gorm.ErrDuplicatedKey, ConstraintName: "idx_users_name", Table: "users", Value: "jinzhu"}},
+		{"Error 1062: Duplicate entry 'jinzhu-18' for key 'idx_name_age'", &gorm.ConstraintError{Err: gorm.ErrDuplicatedKey, ConstraintName: "idx_name_age", Value: "jinzhu-18"}},
+		{"Error 3819 (HY000): Check constraint 'chk_users_age' is violated.", &gorm.ConstraintError{Err: gorm.ErrCheckConstraintViolated, ConstraintName: "chk_users_age"}},
+		{"Error 1048 (23000): Column 'name' cannot be null", &gorm.ConstraintError{Err: gorm.ErrNotNullViolated, Columns: []string{"name"}}},
+		{"Error 1452 (23000): Cannot add or update a child row: a foreign key constraint fails (`gorm`.`pets`, CONSTRAINT `fk_users_pets` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))", &gorm.ConstraintError{Err: gorm.ErrForeignKeyViolated, ConstraintName: "fk_users_pets", Table: "pets", Columns: []string{"user_id"}}},
+		// postgres
+		{`ERROR: duplicate key value violates unique constraint "idx_users_name" (SQLSTATE 23505)`, &gorm.ConstraintError{Err: gorm.ErrDuplicatedKey, ConstraintName: "idx_users_name"}},
+		{`ERROR: new row for relation "users" violates check constraint "chk_users_age" (SQLSTATE 23514)`, &gorm.ConstraintError{Err: gorm.ErrCheckConstraintViolated, ConstraintName: "chk_users_age", Table: "users"}},
+		{`ERROR: null value in column "name" of relation "users" violates not-null constraint (SQLSTATE 23502)`, &gorm.ConstraintError{Err: gorm.ErrNotNullViolated, Table: "users", Columns: []string{"name"}}},
+		{`ERROR: insert or update on table "pets" violates foreign key constraint "fk_users_pets" (SQLSTATE 23503)`, &gorm.ConstraintError{Err: gorm.ErrForeignKeyViolated, ConstraintName: "fk_users_pets", Table: "pets"}},
+		// sqlite
+		{"UNIQUE constraint failed: users.name, users.age", &gorm.ConstraintError{Err: gorm.ErrDuplicatedKey, Table: "users", Columns: []string{"name", "age"}}},
+		{"CHECK constraint failed: chk_users_age", &gorm.ConstraintError{Err: gorm.ErrCheckConstraintViolated, ConstraintName: "chk_users_age"}},
+		{"NOT NULL constraint failed: users.name", &gorm.ConstraintError{Err: gorm.ErrNotNullViolated, Table: "users", Columns: []string{"name"}}},
+		{"FOREIGN KEY constraint failed", &gorm.ConstraintError{Err: gorm.ErrForeignKeyViolated}},
+		// sqlserver
+		{"mssql: Violation of UNIQUE KEY constraint 'UQ_users_name'. Cannot insert duplicate key in object 'dbo.users'. The duplicate key value is (jinzhu).", &gorm.ConstraintError{Err: gorm.ErrDuplicatedKey, ConstraintName: "UQ_users_name", Table: "users", Value: "jinzhu"}},
+		{"mssql: Cannot insert duplicate key row in object 'dbo.users' with unique index 'idx_users_name'. The duplicate key value is (jinzhu).", &gorm.ConstraintError{Err: gorm.ErrDuplicatedKey, ConstraintName: "idx_users_name", Table: "users", Value: "jinzhu"}},
+		{`mssql: The INSERT statement conflicted with the CHECK constraint "chk_users_age". The conflict occurred in database "gorm", table "dbo.users", column 'age'.`, &gorm.ConstraintError{Err: gorm.ErrCheckConstraintViolated, ConstraintName: "chk_users_age", Table: "users", Columns: []string{"age"}}},
+		{"mssql: Cannot insert the value NULL into column 'name', table 'gorm.dbo.users'; column does not allow nulls. INSERT fails.", &gorm.ConstraintError{Err: gorm.ErrNotNullViolated, Table: "users", Columns: []string{"name"}}},
+		{`mssql: The INSERT statement conflicted with the FOREIGN KEY constraint "fk_users_pets". The conflict occurred in database "gorm", table "dbo.users", column 'id'.`, &gorm.ConstraintError{Err: gorm.ErrForeignKeyViolated, ConstraintName: "fk_users_pets", Table: "users", Columns: []string{"id"}}},
+		// unknown
+		{"near \"SELEC\": syntax error", nil},
+	}
+
+	for _, c := range cases {
+		cause := errors.New(c.msg)
+		got := gorm.ParseConstraintError(cause)
+		if c.expected == nil {
+			if got != nil {
+				t.Errorf("%v: expected nil, got %#v", c.msg, got)
+			}
+			continue
+		}
+
+		c.expected.Cause = cause
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("%v: expected %#v, got %#v", c.msg, c.expected, got)
+		}
+	}
+
+	// both the violation and the driver error are reachable
+	cause := &driverError{Code: 2067, Message: "UNIQUE constraint failed: users.name"}
+	err := error(gorm.ParseConstraintError(fmt.Errorf("failed to create: %w", cause)))
+	var constraintErr *gorm.ConstraintError
+	var driverErr *driverError
+	if !errors.Is(err, gorm.ErrDuplicatedKey) || !errors.Is(err, cause) || !errors.As(err, &constraintErr) || !errors.As(err, &driverErr) {
+		t.Errorf("the violation and the driver error should be unwrapped, got %#v", err)
+	} else if driverErr.Code != 2067 {
+		t.Errorf("the driver error should be unwrapped, got %#v", driverErr)
+	}
+
+	columns, value := gorm.ParsePostgresKeyDetail("Key (name, age)=(jinzhu, 18) already exists.")
+	tests.AssertEqual(t, columns, []string{"name", "age"})
+	tests.AssertEqual(t, value, "jinzhu, 18")
+}
+
+type driverError struct {
+	Code    int
+	Message string
+}
+
+func (e *driverError) Error() string {
+	return e.Message
+}
+
+func TestConstraintErrorDetails(t *testing.T) {
+	type Town struct {
+		ID   uint
+		Name string `gorm:"unique;not null"`
+	}
+
+	db, err := OpenTestConnection(&gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	if db.Dialector.Name() != "sqlite" {
+		t.Skip()
+	}
+
+	db.Migrator().DropTable(&Town{})
+	if err = db.AutoMigrate(&Town{}); err != nil {
+		t.Fatalf("failed to migrate towns table, got error: %v", err)
+	}
+
+	if err = db.Create(&Town{Name: "Kabul"}).Error; err != nil {
+		t.Fatalf("failed to create record: %v", err)
+	}
+
+	var constraintErr *gorm.ConstraintError
+	err = db.Create(&Town{Name: "Kabul"}).Error
+	if !errors.Is(err, gorm.ErrDuplicatedKey) || !errors.As(err, &constraintErr) {
+		t.Fatalf("expected ConstraintError of ErrDuplicatedKey, got %v", err)
+	}
+	tests.AssertEqual(t, constraintErr.Table, "towns")
+	tests.AssertEqual(t, constraintErr.Columns, []string{"name"})
+
+	err = db.Exec("INSERT INTO towns (name) VALUES (NULL)").Error
+	if !errors.Is(err, gorm.ErrNotNullViolated) || !errors.As(err, &constraintErr) {
+		t.Fatalf("expected ConstraintError of ErrNotNullViolated, got %v", err)
+	}
+	tests.AssertEqual(t, constraintErr.Columns, []string{"name"})
+}
@@ -0,0 +1,82 @@
+package tests_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestExecMulti(t *testing.T) {
+	results, err := DB.ExecMulti(`
+		-- seed users; skipped comments
+		INSERT INTO users (name, age) VALUES (?, ?);
+		INSERT INTO users (name, age) VALUES ('exec_multi;2', ?);
+		UPDATE users SET age = age + 1 WHERE name IN ?;
+	`, "exec_multi_1", 10, 20, []string{"exec_multi_1", "exec_multi;2"})
+	if err != nil {
+		t.Fatalf("failed to exec multi statements, got %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("should have 3 results, got %+v", results)
+	}
+
+	for idx, rows := range []int64{1, 1, 2} {
+		if results[idx].Error != nil || results[idx].RowsAffected != rows {
+			t.Errorf("result #%d should affect %d rows, got %+v", idx, rows, results[idx])
+		}
+	}
+
+	var users []User
+	DB.Where("name IN ?", []string{"exec_multi_1", "exec_multi;2"}).Order("age").Find(&users)
+	if len(users) != 2 || users[0].Age != 11 || users[1].Age != 21 {
+		t.Errorf("users should be created and updated, got %+v", users)
+	}
+
+	results, err = DB.ExecMulti("UPDATE users SET age = @age WHERE name = @name; DELETE FROM users WHERE name = @name", sql.Named("name", "exec_multi_1"), sql.Named("age", 30))
+	if err != nil || len(results) != 2 || results[0].RowsAffected != 1 || results[1].RowsAffected != 1 {
+		t.Errorf("named vars should be passed to every statement, got %+v, %v", results, err)
+	}
+
+	if _, err := DB.ExecMulti("SELECT ?; SELECT ?", 1); !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("vars not matching placeholders should return ErrInvalidData, got %v", err)
+	}
+}
+
+func TestExecMultiFailed(t *testing.T) {
+	script := "INSERT INTO users (name) VALUES (?); INSERT INTO exec_multi_not_exists (name) VALUES (?); INSERT INTO users (name) VALUES (?)"
+	countUsers := func(names ...string) (count int64) {
+		DB.Model(&User{}).Where("name IN ?", names).Count(&count)
+		return
+	}
+
+	results, err := DB.ExecMulti(script, "exec_multi_failed_1", "exec_multi_failed_2", "exec_multi_failed_3")
+	if err == nil || len(results) != 2 || results[0].Error != nil || results[1].Error == nil || !errors.Is(err, results[1].Error) {
+		t.Fatalf("should stop at the failed statement, got %+v, %v", results, err)
+	}
+
+	if count := countUsers("exec_multi_failed_1", "exec_multi_failed_3"); count != 0 {
+		t.Errorf("the transaction should be rolled back, got %d users", count)
+	}
+
+	results, err = DB.ContinueOnError().ExecMulti(script, "exec_multi_continue_1", "exec_multi_continue_2", "exec_multi_continue_3")
+	if err == nil || len(results) != 3 || results[1].Error == nil || results[2].Error != nil {
+		t.Fatalf("should continue after the failed statement, got %+v, %v", results, err)
+	}
+
+	if count := countUsers("exec_multi_continue_1", "exec_multi_continue_3"); count != 2 {
+		t.Errorf("the succeeded statements should be committed, got %d users", count)
+	}
+
+	results, err = DB.Session(&gorm.Session{SkipDefaultTransaction: true}).ExecMulti(script, "exec_multi_skip_1", "exec_multi_skip_2", "exec_multi_skip_3")
+	if err == nil || len(results) != 2 {
+		t.Fatalf("should stop at the failed statement, got %+v, %v", results, err)
+	}
+
+	if count := countUsers("exec_multi_skip_1", "exec_multi_skip_3"); count != 1 {
+		t.Errorf("the statements before the failed one should be executed without transaction, got %d users", count)
+	}
+}
@@ -0,0 +1,83 @@
+package tests_test
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestExecLastInsertID(t *testing.T) {
+	assertLastInsertID := func(t *testing.T, db *gorm.DB, tx *gorm.DB, name string) {
+		t.Helper()
+		id, err := tx.LastInsertID()
+		switch DB.Dialector.Name() {
+		case "postgres", "sqlserver":
+			if !errors.Is(err, gorm.ErrLastInsertIDUnsupported) {
+				t.Errorf("should return ErrLastInsertIDUnsupported, got %v, %v", id, err)
+			}
+			return
+		}
+
+		var user User
+		db.Where("name = ?", name).First(&user)
+		if err != nil || id != int64(user.ID) {
+			t.Errorf("the last insert id should be %v, got %v, error %v", user.ID, id, err)
+		}
+		AssertEqual(t, tx.RowsAffected, int64(1))
+	}
+
+	tx := DB.Exec("INSERT INTO users (name, age) VALUES (?, ?)", "last_insert_id", 10)
+	assertLastInsertID(t, DB, tx, "last_insert_id")
+
+	tx = DB.Session(&gorm.Session{PrepareStmt: boolPtr(true)}).Exec("INSERT INTO users (name, age) VALUES (?, ?)", "last_insert_id_prepared", 10)
+	assertLastInsertID(t, DB, tx, "last_insert_id_prepared")
+
+	DB.Transaction(func(tx *gorm.DB) error {
+		assertLastInsertID(t, tx, tx.Exec("INSERT INTO users (name, age) VALUES (?, ?)", "last_insert_id_tx", 10), "last_insert_id_tx")
+		return nil
+	})
+
+	results, err := DB.ExecMulti("INSERT INTO users (name, age) VALUES ('last_insert_id_multi', 10)")
+	if err != nil || len(results) != 1 {
+		t.Fatalf("failed to exec multi, got %v", err)
+	}
+	if name := DB.Dialector.Name(); name != "postgres" && name != "sqlserver" {
+		var user User
+		DB.Where("name = ?", "last_insert_id_multi").First(&user)
+		AssertEqual(t, results[0].LastInsertID, int64(user.ID))
+	}
+
+	t.Run("MultiRows", func(t *testing.T) {
+		tx := DB.Exec("INSERT INTO users (name, age) VALUES (?, ?), (?, ?)", "last_insert_id_1", 10, "last_insert_id_2", 10)
+		AssertEqual(t, tx.RowsAffected, int64(2))
+
+		var users []User
+		DB.Where("name IN ?", []string{"last_insert_id_1", "last_insert_id_2"}).Order("id").Find(&users)
+
+		id, err := tx.LastInsertID()
+		switch DB.Dialector.Name() {
+		case "mysql":
+			// the id of the first row
+			AssertEqual(t, id, int64(users[0].ID))
+		case "sqlite":
+			AssertEqual(t, id, int64(users[1].ID))
+		default:
+			if !errors.Is(err, gorm.ErrLastInsertIDUnsupported) {
+				t.Errorf("should return ErrLastInsertIDUnsupported, got %v, %v", id, err)
+			}
+		}
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		tx := DB.Exec("INSERT INTO last_insert_id_missing (name) VALUES (?)", "missing")
+		if _, err := tx.LastInsertID(); err == nil || err != tx.Error {
+			t.Errorf("should return the error of Exec, got %v", err)
+		}
+
+		if _, err := DB.Session(&gorm.Session{DryRun: true}).Exec("INSERT INTO users (name) VALUES (?)", "dry_run").LastInsertID(); err == nil {
+			t.Errorf("should return error without executed result")
+		}
+	})
+}
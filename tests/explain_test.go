@@ -0,0 +1,141 @@
+package tests_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *lockedBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
+
+func TestExplainQuery(t *testing.T) {
+	if DB.Dialector.Name() != "sqlite" {
+		t.Skip("query plans differ between dialects")
+	}
+
+	user := *GetUser("explain_query", Config{})
+	DB.Create(&user)
+
+	var plan string
+	if err := DB.Model(&User{}).Where("name = ?", user.Name).ExplainQuery(&plan, false).Error; err != nil {
+		t.Fatalf("failed to explain query, got error %v", err)
+	}
+	if !strings.Contains(plan, "SCAN") && !strings.Contains(plan, "SEARCH") {
+		t.Errorf("unexpected query plan %q", plan)
+	}
+
+	var jsonPlan json.RawMessage
+	if err := DB.Raw("SELECT * FROM users WHERE id = ?", user.ID).ExplainQuery(&jsonPlan, false).Error; err != nil {
+		t.Fatalf("failed to explain raw query, got error %v", err)
+	}
+	var records []map[string]interface{}
+	if err := json.Unmarshal(jsonPlan, &records); err != nil || len(records) == 0 || records[0]["detail"] == nil {
+		t.Errorf("unexpected json query plan %s, error %v", jsonPlan, err)
+	}
+
+	if err := DB.Raw("UPDATE users SET age = 1 WHERE id = ?", user.ID).ExplainQuery(&plan, true).Error; !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("ANALYZE of writes should be rejected, got error %v", err)
+	}
+
+	for _, sql := range []string{
+		"WITH updated AS (UPDATE users SET age = 1 WHERE id = ? RETURNING *) SELECT * FROM updated",
+		"SELECT * INTO users_copy FROM users WHERE id = ?",
+	} {
+		if err := DB.Raw(sql, user.ID).ExplainQuery(&plan, true).Error; !errors.Is(err, gorm.ErrInvalidData) {
+			t.Errorf("ANALYZE of %v should be rejected, got error %v", sql, err)
+		}
+	}
+
+	var result User
+	if err := DB.First(&result, user.ID).Error; err != nil || result.Age != user.Age {
+		t.Errorf("explained update should not be executed, got %v, error %v", result.Age, err)
+	}
+
+	var plans []string
+	if err := DB.Model(&User{}).ExplainQuery(&plans, false).Error; !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("unsupported destination should be rejected, got error %v", err)
+	}
+}
+
+func TestExplainSlowQueries(t *testing.T) {
+	if DB.Dialector.Name() != "sqlite" {
+		t.Skip("query plans differ between dialects")
+	}
+
+	buf := &lockedBuffer{}
+	db := DB.Session(&gorm.Session{Logger: logger.New(log.New(buf, "", 0), logger.Config{
+		SlowThreshold:      time.Nanosecond,
+		LogLevel:           logger.Warn,
+		ExplainSlowQueries: true,
+	})})
+
+	user := *GetUser("explain_slow_queries", Config{})
+	db.Create(&user)
+
+	for i := 0; i < 2; i++ {
+		var result User
+		db.Where("name = ?", user.Name).First(&result)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), "query plan of slow SQL SELECT") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	output := buf.String()
+	if count := strings.Count(output, "query plan of slow SQL SELECT"); count != 1 {
+		t.Fatalf("slow query should be explained once, got %v, output %v", count, output)
+	}
+	if !strings.Contains(output, "query plan of slow SQL INSERT") {
+		t.Errorf("slow insert should be explained, got %v", output)
+	}
+	if strings.Contains(output, "failed to explain") {
+		t.Errorf("failed to explain slow queries, got %v", output)
+	}
+
+	// tables only visible in the transaction are explained with it
+	buf.Reset()
+	tx := db.Begin()
+	defer tx.Rollback()
+	tx.Exec("CREATE TABLE explain_tx_items (id integer, name text)")
+	tx.Table("explain_tx_items").Where("name = ?", "explain_tx").Find(&[]map[string]interface{}{})
+
+	deadline = time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), "slow SQL SELECT * FROM `explain_tx_items`") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if output := buf.String(); !strings.Contains(output, "query plan of slow SQL SELECT * FROM `explain_tx_items`") {
+		t.Errorf("slow query in transaction should be explained in it, got %v", output)
+	}
+}
@@ -0,0 +1,130 @@
+package tests_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type ExportOrder struct {
+	ID     uint   `json:"id"`
+	Number string `json:"number"`
+	Note   *string
+	Tags   []string `gorm:"serializer:json" json:"tags"`
+	Secret string   `json:"-"`
+}
+
+func TestExport(t *testing.T) {
+	DB.Migrator().DropTable(&ExportOrder{})
+	if err := DB.AutoMigrate(&ExportOrder{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	note := `quoted "note", with comma`
+	orders := []ExportOrder{
+		{Number: "export_1", Note: &note, Tags: []string{"a", "b"}, Secret: "s1"},
+		{Number: "export_2", Tags: []string{"c"}, Secret: "s2"},
+		{Number: "export_3", Secret: "s3"},
+	}
+	DB.Create(&orders)
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		result, err := DB.Model(&ExportOrder{}).Order("id").Export(&buf, gorm.ExportCSV, gorm.ExportColumns("Number", "Note", "Tags"), gorm.ExportNull("NULL"))
+		if err != nil {
+			t.Fatalf("failed to export, got error %v", err)
+		}
+		AssertEqual(t, result, gorm.ExportResult{Rows: 3, Bytes: int64(buf.Len())})
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("failed to read the exported CSV, got error %v", err)
+		}
+		AssertEqual(t, records, [][]string{
+			{"number", "note", "tags"},
+			{"export_1", note, `["a","b"]`},
+			{"export_2", "NULL", `["c"]`},
+			{"export_3", "NULL", "NULL"},
+		})
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		if _, err := DB.Model(&ExportOrder{}).Where("number <> ?", "export_3").Order("id").Export(&buf, gorm.ExportNDJSON); err != nil {
+			t.Fatalf("failed to export, got error %v", err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 || strings.Contains(buf.String(), "s1") {
+			t.Fatalf("should export 2 lines without the ignored fields, got %v", buf.String())
+		}
+		if !strings.HasPrefix(lines[0], `{"id":`) {
+			t.Errorf("the keys should be ordered by the columns, got %v", lines[0])
+		}
+
+		var order ExportOrder
+		if err := json.Unmarshal([]byte(lines[0]), &order); err != nil {
+			t.Fatalf("failed to unmarshal the exported line, got error %v", err)
+		}
+		AssertEqual(t, order.ID, orders[0].ID)
+		AssertEqual(t, order.Note, &note)
+		AssertEqual(t, order.Tags, []string{"a", "b"})
+	})
+
+	t.Run("Columns", func(t *testing.T) {
+		var buf bytes.Buffer
+		DB.Table("export_orders").Select("number, id * 10 AS score").Order("id").Export(&buf, gorm.ExportCSV, gorm.ExportFlushEvery(1))
+		if !strings.HasPrefix(buf.String(), "number,score\nexport_1,") {
+			t.Errorf("should export the selected columns, got %v", buf.String())
+		}
+
+		if _, err := DB.Model(&ExportOrder{}).Select("number").Export(&buf, gorm.ExportCSV, gorm.ExportColumns("Note")); !errors.Is(err, gorm.ErrInvalidField) {
+			t.Errorf("should return ErrInvalidField for the columns not selected, got %v", err)
+		}
+
+		if _, err := DB.Model(&ExportOrder{}).Export(&buf, "xml"); !errors.Is(err, gorm.ErrInvalidData) {
+			t.Errorf("should return ErrInvalidData for the unknown format, got %v", err)
+		}
+	})
+
+	t.Run("Batches", func(t *testing.T) {
+		var buf bytes.Buffer
+		result, err := DB.Model(&ExportOrder{}).Export(&buf, gorm.ExportCSV, gorm.ExportBatchSize(2), gorm.ExportColumns("id", "number"))
+		if err != nil || result.Rows != 3 {
+			t.Fatalf("failed to export in batches, got %v, error %v", result, err)
+		}
+		AssertEqual(t, strings.Count(buf.String(), "\n"), 4)
+		AssertEqual(t, strings.Contains(buf.String(), "export_3\n"), true)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var buf bytes.Buffer
+		writer := &cancelWriter{Writer: &buf, cancel: cancel}
+		result, err := DB.WithContext(ctx).Model(&ExportOrder{}).Order("id").Export(writer, gorm.ExportNDJSON, gorm.ExportFlushEvery(1))
+
+		var interrupted *gorm.InterruptedError
+		if !errors.As(err, &interrupted) || !errors.Is(err, context.Canceled) {
+			t.Fatalf("should return InterruptedError, got %v", err)
+		}
+		AssertEqual(t, result, gorm.ExportResult{Rows: 1, Bytes: int64(buf.Len())})
+	})
+}
+
+// cancelWriter cancels the context after the first write
+type cancelWriter struct {
+	Writer *bytes.Buffer
+	cancel func()
+}
+
+func (w *cancelWriter) Write(p []byte) (int, error) {
+	defer w.cancel()
+	return w.Writer.Write(p)
+}
@@ -0,0 +1,136 @@
+package tests_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type EmulatedCategory struct {
+	ID       uint
+	Name     string
+	Products []EmulatedProduct `gorm:"foreignKey:CategoryID;emulateForeignKey"`
+	Tags     []EmulatedTag     `gorm:"foreignKey:CategoryID;emulateForeignKey;constraint:OnDelete:CASCADE"`
+}
+
+type EmulatedProduct struct {
+	ID         uint
+	Name       string
+	CategoryID *uint
+	Category   *EmulatedCategory `gorm:"emulateForeignKey"`
+}
+
+type EmulatedTag struct {
+	ID         uint
+	CategoryID uint
+}
+
+func TestEmulateForeignKeys(t *testing.T) {
+	tx := DB.Session(&gorm.Session{Context: context.Background()})
+	tx.Config.EmulateForeignKeys = true
+
+	company := Company{Name: "emulated_company"}
+	DB.Create(&company)
+
+	user := *GetUser("emulate_foreign_keys", Config{Pets: 2})
+	user.CompanyID = &company.ID
+	if err := tx.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user referencing existing company, got error %v", err)
+	}
+
+	missingCompanyID := company.ID + 1000
+	users := []User{*GetUser("emulate_foreign_keys_1", Config{}), *GetUser("emulate_foreign_keys_2", Config{})}
+	users[0].CompanyID = &company.ID
+	users[1].CompanyID = &missingCompanyID
+
+	var constraintErr *gorm.ConstraintError
+	if err := tx.Create(&users).Error; !errors.Is(err, gorm.ErrForeignKeyViolated) || !errors.As(err, &constraintErr) {
+		t.Fatalf("should return ErrForeignKeyViolated for missing company, got %v", err)
+	}
+	AssertEqual(t, constraintErr.Columns, []string{"company_id"})
+
+	var count int64
+	DB.Model(&User{}).Where("name LIKE ?", "emulate_foreign_keys_%").Count(&count)
+	AssertEqual(t, count, 0)
+
+	if err := tx.Model(&user).Update("company_id", missingCompanyID).Error; !errors.Is(err, gorm.ErrForeignKeyViolated) {
+		t.Errorf("should return ErrForeignKeyViolated when updating to missing company, got %v", err)
+	}
+
+	if err := tx.Model(&user).Updates(User{CompanyID: &missingCompanyID}).Error; !errors.Is(err, gorm.ErrForeignKeyViolated) {
+		t.Errorf("should return ErrForeignKeyViolated when updating to missing company, got %v", err)
+	}
+
+	if err := tx.Model(&user).Updates(User{Name: "emulate_foreign_keys_updated"}).Error; err != nil {
+		t.Errorf("updates without foreign keys shouldn't be checked, got %v", err)
+	}
+
+	if err := tx.Unscoped().Delete(&user).Error; !errors.Is(err, gorm.ErrForeignKeyViolated) || !errors.As(err, &constraintErr) {
+		t.Fatalf("should return ErrForeignKeyViolated when deleting user with pets, got %v", err)
+	}
+	AssertEqual(t, constraintErr.Table, "pets")
+
+	if err := tx.Delete(&user).Error; err != nil {
+		t.Errorf("soft deletes shouldn't be checked, got %v", err)
+	}
+
+	if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&Pet{}).Error; err != nil {
+		t.Fatalf("failed to delete pets, got %v", err)
+	}
+
+	if err := tx.Unscoped().Delete(&user).Error; err != nil {
+		t.Errorf("user without pets should be deleted, got %v", err)
+	}
+
+}
+
+func TestEmulateForeignKeyTag(t *testing.T) {
+	// the foreign keys are only emulated in application code
+	migrateDB := DB.Session(&gorm.Session{})
+	migrateDB.Config.DisableForeignKeyConstraintWhenMigrating = true
+	migrateDB.Migrator().DropTable(&EmulatedCategory{}, &EmulatedProduct{}, &EmulatedTag{})
+	if err := migrateDB.AutoMigrate(&EmulatedCategory{}, &EmulatedProduct{}, &EmulatedTag{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	category := EmulatedCategory{Name: "tag", Tags: []EmulatedTag{{}}}
+	if err := DB.Create(&category).Error; err != nil {
+		t.Fatalf("failed to create category, got error %v", err)
+	}
+
+	missingID := category.ID + 1000
+	if err := DB.Create(&EmulatedProduct{Name: "missing", CategoryID: &missingID}).Error; !errors.Is(err, gorm.ErrForeignKeyViolated) {
+		t.Errorf("should return ErrForeignKeyViolated for the tagged relationship, got %v", err)
+	}
+
+	if err := DB.WithoutForeignKeyChecks().Create(&EmulatedProduct{Name: "skipped", CategoryID: &missingID}).Error; err != nil {
+		t.Errorf("foreign keys shouldn't be checked with WithoutForeignKeyChecks, got %v", err)
+	}
+
+	if err := DB.Transaction(func(tx *gorm.DB) error {
+		uncommitted := EmulatedCategory{Name: "uncommitted"}
+		if err := tx.Create(&uncommitted).Error; err != nil {
+			return err
+		}
+		return tx.Create(&EmulatedProduct{Name: "uncommitted", CategoryID: &uncommitted.ID}).Error
+	}); err != nil {
+		t.Errorf("the parent created in the transaction should be found, got %v", err)
+	}
+
+	products := []EmulatedProduct{{Name: "p1", CategoryID: &category.ID}, {Name: "p2", CategoryID: &category.ID}, {Name: "p3"}}
+	if err := DB.Create(&products).Error; err != nil {
+		t.Fatalf("failed to create products, got error %v", err)
+	}
+
+	if err := DB.Where("name = ?", "tag").Delete(&EmulatedCategory{}).Error; !errors.Is(err, gorm.ErrForeignKeyViolated) {
+		t.Errorf("should return ErrForeignKeyViolated when deleting category with products, got %v", err)
+	}
+
+	DB.Where("category_id = ?", category.ID).Delete(&EmulatedProduct{})
+	if err := DB.Delete(&category).Error; err != nil {
+		t.Errorf("tags deleted with cascade shouldn't be checked, got %v", err)
+	}
+}
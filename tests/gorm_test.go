@@ -1,11 +1,21 @@
 package tests_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
 	"testing"
+	"time"
 
 	"gorm.io/driver/mysql"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+	. "gorm.io/gorm/utils/tests"
 )
 
 func TestOpen(t *testing.T) {
@@ -101,3 +111,159 @@ func TestReturningWithNullToZeroValues(t *testing.T) {
 
 	}
 }
+
+func TestPrewarmSchemas(t *testing.T) {
+	db, err := OpenTestConnection(&gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test connection due to %s", err)
+	}
+
+	if err := db.PrewarmSchemas(&User{}, gorm.TableModel{Model: &User{}, Table: "users_prewarm"}); err != nil {
+		t.Fatalf("failed to prewarm schemas, got %v", err)
+	}
+
+	var tables []string
+	for _, s := range db.CachedSchemas() {
+		tables = append(tables, s.Table)
+	}
+	for _, table := range []string{"users", "users_prewarm", "pets", "toys", "companies", "languages", "user_speaks"} {
+		if !strings.Contains(strings.Join(tables, ","), table) {
+			t.Errorf("schema of table %v should be cached, got %v", table, tables)
+		}
+	}
+
+	parsedCount := schema.ParsedCount()
+	var users []User
+	if err := db.Preload(clause.Associations).Where("name = ?", "prewarm").Find(&users).Error; err != nil {
+		t.Fatalf("failed to query, got %v", err)
+	}
+	db.Table("users_prewarm").Migrator().HasTable(&User{})
+
+	if count := schema.ParsedCount(); count != parsedCount {
+		t.Errorf("no schema should be parsed after prewarmed, but parsed %v", count-parsedCount)
+	}
+
+	err = db.PrewarmSchemas(1, &User{}, "users")
+	if !errors.Is(err, schema.ErrUnsupportedDataType) {
+		t.Fatalf("should return unsupported data type error, got %v", err)
+	}
+
+	if msg := err.Error(); !strings.Contains(msg, "of int") || !strings.Contains(msg, "of string") || strings.Contains(msg, "User") {
+		t.Errorf("should aggregate errors of failed models, got %v", msg)
+	}
+}
+
+type StrictTagsUser struct {
+	ID   uint
+	Name string `gorm:"collumn:name"`
+}
+
+func TestStrictTags(t *testing.T) {
+	DB.Migrator().DropTable(&StrictTagsUser{})
+	if err := DB.AutoMigrate(&StrictTagsUser{}); err != nil {
+		t.Fatalf("invalid tags should only be warned by default, got %v", err)
+	}
+
+	db, err := OpenTestConnection(&gorm.Config{StrictTags: true})
+	if err != nil {
+		t.Fatalf("failed to open test connection due to %s", err)
+	}
+
+	if err := db.Find(&[]StrictTagsUser{}).Error; !errors.Is(err, schema.ErrInvalidTag) || !strings.Contains(err.Error(), `did you mean "column"`) {
+		t.Errorf("should return ErrInvalidTag with StrictTags, got %v", err)
+	}
+
+	if err := db.PrewarmSchemas(&StrictTagsUser{}); !errors.Is(err, schema.ErrInvalidTag) {
+		t.Errorf("should return ErrInvalidTag with StrictTags, got %v", err)
+	}
+
+	if err := db.Find(&[]User{}).Error; err != nil {
+		t.Errorf("valid tags shouldn't return error with StrictTags, got %v", err)
+	}
+}
+
+func TestDebugIf(t *testing.T) {
+	buf := &bytes.Buffer{}
+	db := DB.Session(&gorm.Session{Logger: logger.New(log.New(buf, "", 0), logger.Config{LogLevel: logger.Warn})})
+
+	debugDB := db.DebugIf(func(stmt *gorm.Statement) bool { return stmt.Table == "pets" })
+	debugDB.Find(&[]User{})
+	if buf.Len() != 0 {
+		t.Errorf("statements of users should not be logged, got %v", buf.String())
+	}
+
+	debugDB.Find(&[]Pet{})
+	if !strings.Contains(buf.String(), "FROM `pets`") && !strings.Contains(buf.String(), `FROM "pets"`) {
+		t.Errorf("statements of pets should be logged, got %v", buf.String())
+	}
+
+	buf.Reset()
+	db.Find(&[]Pet{})
+	if buf.Len() != 0 {
+		t.Errorf("DebugIf should not change the log level of the DB, got %v", buf.String())
+	}
+}
+
+func TestLogLevelByTable(t *testing.T) {
+	buf := &bytes.Buffer{}
+	db := DB.Session(&gorm.Session{
+		Logger:          logger.New(log.New(buf, "", 0), logger.Config{LogLevel: logger.Info}),
+		LogLevelByTable: map[string]logger.LogLevel{"users": logger.Info, "*": logger.Silent},
+	})
+
+	db.Find(&[]Pet{})
+	db.Raw("SELECT * FROM pets").Scan(&[]Pet{})
+	if buf.Len() != 0 {
+		t.Errorf("statements of the other tables should not be logged, got %v", buf.String())
+	}
+
+	db.Find(&[]User{})
+	if !strings.Contains(buf.String(), "users") {
+		t.Errorf("statements of users should be logged, got %v", buf.String())
+	}
+
+	buf.Reset()
+	db.Raw("SELECT count(*) FROM users").Scan(new(int64))
+	if !strings.Contains(buf.String(), "FROM users") {
+		t.Errorf("raw statements of users should be logged, got %v", buf.String())
+	}
+}
+
+func TestStatementPoolReuse(t *testing.T) {
+	user := *GetUser("statement_pool", Config{Company: true, Pets: 2})
+	DB.Create(&user)
+
+	var sqls []string
+	db := DB.Session(&gorm.Session{NewDB: true, Logger: Tracer{
+		Logger: DB.Logger,
+		Test: func(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+			sql, _ := fc()
+			sqls = append(sqls, sql)
+		},
+	}})
+
+	plainSQL := func(db *gorm.DB) string {
+		sqls = nil
+		var users []User
+		if err := db.Where("name = ?", user.Name).Find(&users).Error; err != nil || len(users) != 1 {
+			t.Fatalf("failed to find users, got %v, error %v", len(users), err)
+		}
+		return strings.Join(sqls, "\n")
+	}
+	expected := plainSQL(db)
+
+	db.Config.StatementPool = true
+	for i := 0; i < 10; i++ {
+		var results []User
+		if err := db.Unscoped().Table("users").Select("users.*").Omit("age").Distinct().Joins("Company").Preload("Pets").
+			Scopes(func(tx *gorm.DB) *gorm.DB { return tx.Where("users.name = ?", user.Name) }).
+			Set("statement_pool", true).Clauses(clause.OrderBy{Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "users.id"}, Desc: true}}}).
+			Group("users.id").Having("count(*) > ?", 0).Limit(1).Offset(0).Find(&results).Error; err != nil {
+			t.Fatalf("failed to find users, got %v", err)
+		}
+
+		if got := plainSQL(db); got != expected {
+			t.Fatalf("the pooled statement should start clean, expects %v, got %v", expected, got)
+		}
+	}
+}
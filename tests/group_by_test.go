@@ -1,8 +1,13 @@
 package tests_test
 
 import (
+	"context"
+	"regexp"
+	"strings"
 	"testing"
 
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -107,3 +112,78 @@ func TestGroupBy(t *testing.T) {
 		}
 	}
 }
+
+func TestHavingConditions(t *testing.T) {
+	users := []User{
+		{Name: "having", Age: 10}, {Name: "having", Age: 20},
+		{Name: "having1", Age: 25},
+		{Name: "having2", Age: 40}, {Name: "having2", Age: 50}, {Name: "having2", Age: 60},
+	}
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("errors happened when create: %v", err)
+	}
+
+	type result struct {
+		Name  string
+		Total int64
+	}
+
+	var results []result
+	if err := DB.Model(&User{}).Select("name, sum(age) as total").Where("name LIKE ?", "having%").Group("name").
+		Having(clause.Gt{Column: clause.Expr{SQL: "count(*)"}, Value: 1}).
+		Having(clause.Lt{Column: clause.Expr{SQL: "sum(age)"}, Value: 100}).Order("name").Find(&results).Error; err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	}
+	AssertEqual(t, results, []result{{Name: "having", Total: 30}})
+
+	if err := DB.Model(&User{}).Select("name, sum(age) as total").Where("name LIKE ?", "having%").
+		Having(map[string]interface{}{"total": 150}).Group("name").Find(&results).Error; err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	}
+	AssertEqual(t, results, []result{{Name: "having2", Total: 150}})
+
+	var count int64
+	if err := DB.Model(&User{}).Select("name, sum(age) as total").Where("name LIKE ?", "having%").Group("name").
+		Having("total >= ?", 30).Count(&count).Error; err != nil {
+		t.Fatalf("no error should happen when counting, but got %v", err)
+	}
+	AssertEqual(t, count, 2)
+
+	tx := DB.Model(&User{}).Select("name, sum(age) as total").Where("name LIKE ?", "having%").Group("name").
+		Having(clause.Gte{Column: "total", Value: 30})
+	if err := tx.Count(&count).Error; err != nil {
+		t.Fatalf("no error should happen when counting, but got %v", err)
+	}
+	AssertEqual(t, count, 2)
+
+	if err := tx.Order("name").Find(&results).Error; err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	}
+	AssertEqual(t, results, []result{{Name: "having", Total: 30}, {Name: "having2", Total: 150}})
+
+	for _, c := range []struct {
+		name    string
+		aliased bool
+	}{{"postgres", false}, {"sqlite", true}, {"mysql", true}} {
+		t.Run(c.name, func(t *testing.T) {
+			session := DB.Session(&gorm.Session{DryRun: true, Context: context.Background()})
+			session.Config.Dialector = capabilityDialector{Dialector: DB.Dialector, capabilities: gorm.Capabilities{
+				gorm.CapabilityHavingAlias: c.aliased,
+			}}
+
+			stmt := session.Model(&User{}).Select("name, sum(age) + 1 AS total, count(*) AS cnt").Group("name").
+				Having("total > ? AND users.total > ? AND 'total' <> ?", 10, 20, "").
+				Having(clause.Gt{Column: "cnt", Value: 1}).Find(&[]result{}).Statement
+
+			sql := stmt.SQL.String()
+			if aliased := !strings.Contains(sql, "(sum(age) + 1) > ?"); aliased != c.aliased {
+				t.Errorf("aliases in HAVING should be kept: %v, got %v", c.aliased, sql)
+			}
+
+			if !c.aliased && (!strings.Contains(sql, "users.total > ?") || !strings.Contains(sql, "'total' <> ?") ||
+				!regexp.MustCompile(`AND count\(\*\) > \?$`).MatchString(sql)) {
+				t.Errorf("only the aliases of the selected columns should be replaced, got %v", sql)
+			}
+		})
+	}
+}
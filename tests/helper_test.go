@@ -292,3 +292,7 @@ func db(unscoped bool) *gorm.DB {
 		return DB
 	}
 }
+
+func boolPtr(b bool) *bool {
+	return &b
+}
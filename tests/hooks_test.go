@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -609,3 +610,386 @@ func TestPropagateUnscoped(t *testing.T) {
 		t.Fatalf("unscoped did not propagate")
 	}
 }
+
+type Product7 struct {
+	gorm.Model
+	Name  string
+	Code  string
+	Price float64
+
+	updateColumns []string
+	conditions    int
+}
+
+func (p *Product7) BeforeUpdate(tx *gorm.DB) error {
+	p.updateColumns = nil
+	for _, assignment := range tx.Statement.UpdateColumns() {
+		p.updateColumns = append(p.updateColumns, assignment.Column.Name)
+	}
+	p.conditions = len(tx.Statement.ConditionsSummary())
+
+	if dest, ok := tx.Statement.Dest.(map[string]interface{}); ok && dest["name"] == "hooked" {
+		tx.Statement.Dest = map[string]interface{}{"name": "changed_by_hook"}
+	}
+	return nil
+}
+
+func (p *Product7) BeforeDelete(tx *gorm.DB) error {
+	if p.Code == "protected" {
+		tx.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.Neq{Column: "code", Value: "protected"}}})
+	}
+	return nil
+}
+
+func TestHookStatementAccessors(t *testing.T) {
+	DB.Migrator().DropTable(&Product7{})
+	DB.AutoMigrate(&Product7{})
+
+	p := Product7{Name: "accessor", Code: "code", Price: 10}
+	DB.Create(&p)
+
+	if err := DB.Model(&p).Omit("price").Updates(map[string]interface{}{"name": "accessor_2", "price": 20, "code": "code_2"}).Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+
+	if !reflect.DeepEqual(p.updateColumns, []string{"code", "name", "updated_at"}) {
+		t.Errorf("update columns should respect omit, but got %v", p.updateColumns)
+	}
+
+	if p.conditions != 1 {
+		t.Errorf("conditions summary should contain primary key conditions, but got %v", p.conditions)
+	}
+
+	if err := DB.Model(&p).Where("code = ?", "code_2").Update("name", "hooked").Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+
+	if p.conditions != 2 {
+		t.Errorf("conditions summary should contain where and primary key conditions, but got %v", p.conditions)
+	}
+
+	var result Product7
+	DB.First(&result, p.ID)
+	if result.Name != "changed_by_hook" {
+		t.Errorf("dest changed by hook should take effect, but got %v", result.Name)
+	}
+
+	protected := Product7{Name: "protected", Code: "protected"}
+	DB.Create(&protected)
+	if err := DB.Delete(&protected).Error; err != nil {
+		t.Fatalf("failed to delete, got error %v", err)
+	}
+
+	if err := DB.First(&Product7{}, protected.ID).Error; err != nil {
+		t.Errorf("clause added by hook should take effect, but got error %v", err)
+	}
+}
+
+type Product8 struct {
+	gorm.Model
+	Name string
+
+	afterCommitCalled   int
+	afterRollbackCalled int
+}
+
+func (p *Product8) AfterCommit(tx *gorm.DB) error {
+	p.afterCommitCalled++
+	return nil
+}
+
+func (p *Product8) AfterRollback(tx *gorm.DB) error {
+	p.afterRollbackCalled++
+	return nil
+}
+
+func TestAfterCommitAndRollbackHooks(t *testing.T) {
+	DB.Migrator().DropTable(&Product8{})
+	DB.AutoMigrate(&Product8{})
+
+	p := Product8{Name: "after_commit"}
+	DB.Create(&p)
+	if p.afterCommitCalled != 1 || p.afterRollbackCalled != 0 {
+		t.Errorf("AfterCommit should be called after implicit transaction, got %v, %v", p.afterCommitCalled, p.afterRollbackCalled)
+	}
+
+	p2 := Product8{Name: "after_commit_2"}
+	DB.Transaction(func(tx *gorm.DB) error {
+		tx.Create(&p2)
+		if p2.afterCommitCalled != 0 {
+			t.Errorf("AfterCommit should not be called before transaction committed")
+		}
+		return nil
+	})
+	if p2.afterCommitCalled != 1 || p2.afterRollbackCalled != 0 {
+		t.Errorf("AfterCommit should be called after transaction committed, got %v, %v", p2.afterCommitCalled, p2.afterRollbackCalled)
+	}
+
+	p3 := Product8{Name: "after_rollback"}
+	DB.Transaction(func(tx *gorm.DB) error {
+		tx.Create(&p3)
+		return errors.New("rollback")
+	})
+	if p3.afterCommitCalled != 0 || p3.afterRollbackCalled != 1 {
+		t.Errorf("AfterRollback should be called after transaction rolled back, got %v, %v", p3.afterCommitCalled, p3.afterRollbackCalled)
+	}
+
+	var outerCommitted, innerCommitted, innerRolledBack bool
+	DB.Transaction(func(tx *gorm.DB) error {
+		tx.OnCommit(func() { outerCommitted = true })
+
+		tx.Transaction(func(tx2 *gorm.DB) error {
+			tx2.OnCommit(func() { innerCommitted = true })
+			tx2.OnRollback(func() { innerRolledBack = true })
+			return errors.New("rollback savepoint")
+		})
+
+		if outerCommitted || !innerRolledBack {
+			t.Errorf("hooks of nested transaction should be called when rolled back to savepoint")
+		}
+		return nil
+	})
+	if !outerCommitted || innerCommitted {
+		t.Errorf("commit hooks should be only called for the committed changes, got %v, %v", outerCommitted, innerCommitted)
+	}
+
+	var called bool
+	tx := DB.Begin()
+	tx.OnCommit(func() { panic("panic in hook") })
+	tx.OnCommit(func() { called = true })
+	if err := tx.Commit().Error; err != nil {
+		t.Fatalf("failed to commit, got error %v", err)
+	}
+	if !called {
+		t.Errorf("hooks should be called even if the previous one panicked")
+	}
+
+	if err := DB.First(&Product8{}, p.ID).Error; err != nil {
+		t.Errorf("connection should be usable after hook panicked, got error %v", err)
+	}
+
+	called = false
+	DB.OnCommit(func() { called = true })
+	if !called {
+		t.Errorf("OnCommit should be called immediately when not in transaction")
+	}
+}
+
+type auditable interface {
+	Audit(event string)
+}
+
+type Product9 struct {
+	gorm.Model
+	Name string
+
+	events []string
+}
+
+func (p *Product9) Audit(event string) {
+	p.events = append(p.events, event)
+}
+
+func (p *Product9) BeforeCreate(tx *gorm.DB) error {
+	p.Audit("model:before_create")
+	return nil
+}
+
+func (p *Product9) AfterCreate(tx *gorm.DB) error {
+	p.Audit("model:after_create")
+	return nil
+}
+
+func TestModelHooks(t *testing.T) {
+	DB.Migrator().DropTable(&Product9{})
+	DB.AutoMigrate(&Product9{})
+
+	audit := func(event string) func(tx *gorm.DB, model interface{}) error {
+		return func(tx *gorm.DB, model interface{}) error {
+			model.(auditable).Audit(event)
+			return nil
+		}
+	}
+
+	var userCalls int
+	db := DB.Session(&gorm.Session{})
+	db.RegisterModelHook(gorm.ModelHook{
+		BeforeCreate: audit("global:before_create"),
+		AfterCreate:  audit("global:after_create"),
+		BeforeUpdate: audit("global:before_update"),
+		AfterFind:    audit("global:after_find"),
+		BeforeDelete: audit("global:before_delete"),
+		Filter: func(model interface{}) bool {
+			_, ok := model.(auditable)
+			return ok
+		},
+	})
+	db.RegisterModelHook(gorm.ModelHook{
+		BeforeCreate: func(tx *gorm.DB, model interface{}) error {
+			userCalls++
+			return nil
+		},
+		Schemas: []string{"User"},
+	})
+
+	products := []Product9{{Name: "model_hook_1"}, {Name: "model_hook_2"}, {Name: "model_hook_3"}}
+	if err := db.Create(&products).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	for _, p := range products {
+		expects := []string{"global:before_create", "model:before_create", "model:after_create", "global:after_create"}
+		if !reflect.DeepEqual(p.events, expects) {
+			t.Errorf("model hooks should be called for every row in order, expects %v, got %v", expects, p.events)
+		}
+	}
+
+	if userCalls != 0 {
+		t.Errorf("model hook restricted to other schemas should not be called, got %v", userCalls)
+	}
+
+	db.Create(GetUser("model_hook", Config{}))
+	if userCalls != 1 {
+		t.Errorf("model hook should be called for its schema, got %v", userCalls)
+	}
+
+	var results []*Product9
+	db.Where("name LIKE ?", "model_hook_%").Find(&results)
+	if len(results) != 3 {
+		t.Fatalf("should find 3 products, got %v", len(results))
+	}
+	for _, p := range results {
+		if !reflect.DeepEqual(p.events, []string{"global:after_find"}) {
+			t.Errorf("AfterFind model hook should be called for every row, got %v", p.events)
+		}
+	}
+
+	db.Model(results[0]).Update("name", "model_hook_updated")
+	db.Delete(results[1])
+	if expects := []string{"global:after_find", "global:before_update"}; !reflect.DeepEqual(results[0].events, expects) {
+		t.Errorf("expects %v, got %v", expects, results[0].events)
+	}
+	if expects := []string{"global:after_find", "global:before_delete"}; !reflect.DeepEqual(results[1].events, expects) {
+		t.Errorf("expects %v, got %v", expects, results[1].events)
+	}
+
+	var product Product9
+	DB.First(&product, products[2].ID)
+	if len(product.events) != 0 {
+		t.Errorf("model hooks should not be called for other sessions, got %v", product.events)
+	}
+
+	if err := db.Session(&gorm.Session{}).Where("1 = 1").Delete(&Product9{}).Error; err != nil {
+		t.Fatalf("failed to delete, got error %v", err)
+	}
+}
+
+func TestModelHooksSessionsIsolated(t *testing.T) {
+	base := DB.Session(&gorm.Session{})
+	base.ModelHooks = make([]gorm.ModelHook, 0, 4)
+	base.RegisterModelHook(gorm.ModelHook{Schemas: []string{"base"}})
+
+	db1, db2 := base.Session(&gorm.Session{}), base.Session(&gorm.Session{})
+	db1.RegisterModelHook(gorm.ModelHook{Schemas: []string{"db1"}})
+	db2.RegisterModelHook(gorm.ModelHook{Schemas: []string{"db2"}})
+
+	if len(base.ModelHooks) != 1 {
+		t.Errorf("registering on sessions should not change the parent, got %v", base.ModelHooks)
+	}
+	if len(db1.ModelHooks) != 2 || db1.ModelHooks[1].Schemas[0] != "db1" {
+		t.Errorf("registering on another session should not change db1, got %v", db1.ModelHooks)
+	}
+	if len(db2.ModelHooks) != 2 || db2.ModelHooks[1].Schemas[0] != "db2" {
+		t.Errorf("expects db2 hook registered, got %v", db2.ModelHooks)
+	}
+}
+
+type Product10 struct {
+	gorm.Model
+	Name  string
+	Price float64
+
+	afterCreated bool
+}
+
+func (p *Product10) BeforeSave(tx *gorm.DB) error {
+	if p.Price < 0 {
+		return errors.New("invalid price")
+	}
+	return nil
+}
+
+func (p *Product10) AfterCreate(tx *gorm.DB) error {
+	p.afterCreated = true
+	return nil
+}
+
+func TestPartialBatchHooks(t *testing.T) {
+	DB.Migrator().DropTable(&Product10{})
+	DB.AutoMigrate(&Product10{})
+
+	products := []Product10{{Name: "partial_1", Price: 1}, {Name: "partial_2", Price: -1}, {Name: "partial_3", Price: 3}, {Name: "partial_4", Price: -4}}
+	if err := DB.Create(&products).Error; err == nil {
+		t.Fatalf("should fail to create batch without PartialBatch")
+	}
+
+	var count int64
+	DB.Model(&Product10{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("no rows should be created without PartialBatch, got %v", count)
+	}
+
+	tx := DB.Session(&gorm.Session{PartialBatch: true}).Create(&products)
+	var batchErr *gorm.BatchError
+	if !errors.As(tx.Error, &batchErr) {
+		t.Fatalf("should return BatchError, got %v", tx.Error)
+	}
+
+	if len(batchErr.Errors) != 2 || batchErr.Errors[1] == nil || batchErr.Errors[3] == nil || batchErr.RowsAffected != 2 || tx.RowsAffected != 2 {
+		t.Errorf("batch error should contain the failed rows, got %#v", batchErr)
+	}
+
+	for idx, product := range products {
+		if created := idx%2 == 0; (product.ID != 0) != created || product.afterCreated != created {
+			t.Errorf("product #%v created should be %v, got %#v", idx, created, product)
+		}
+	}
+
+	var names []string
+	DB.Model(&Product10{}).Order("id").Pluck("name", &names)
+	if !reflect.DeepEqual(names, []string{"partial_1", "partial_3"}) {
+		t.Errorf("only valid rows should be created, got %v", names)
+	}
+
+	for idx := range products {
+		products[idx].Price = -1
+	}
+	products[2].Price = 30
+	products[3].Price = 40
+
+	tx = DB.Session(&gorm.Session{PartialBatch: true}).Save(&products)
+	if !errors.As(tx.Error, &batchErr) || len(batchErr.Errors) != 2 || batchErr.Errors[0] == nil || batchErr.Errors[1] == nil {
+		t.Fatalf("should return BatchError when saving, got %v", tx.Error)
+	}
+
+	var prices []float64
+	DB.Model(&Product10{}).Order("id").Pluck("price", &prices)
+	if !reflect.DeepEqual(prices, []float64{1, 30, 40}) || products[3].ID == 0 {
+		t.Errorf("only valid rows should be saved, got %v", prices)
+	}
+
+	failed := []Product10{{Name: "partial_5", Price: -5}}
+	if err := DB.Session(&gorm.Session{PartialBatch: true}).Create(&failed).Error; !errors.As(err, &batchErr) || batchErr.RowsAffected != 0 {
+		t.Errorf("should return BatchError when all rows failed, got %v", err)
+	}
+
+	batches := []Product10{{Name: "partial_6", Price: 6}, {Name: "partial_7", Price: -7}, {Name: "partial_8", Price: -8}, {Name: "partial_9", Price: 9}}
+	tx = DB.Session(&gorm.Session{PartialBatch: true}).CreateInBatches(&batches, 2)
+	if !errors.As(tx.Error, &batchErr) || len(batchErr.Errors) != 2 || batchErr.Errors[1] == nil || batchErr.Errors[2] == nil || tx.RowsAffected != 2 {
+		t.Errorf("should return BatchError with indexes of the whole slice, got %v", tx.Error)
+	}
+
+	if batches[0].ID == 0 || batches[3].ID == 0 {
+		t.Errorf("valid rows should be created in batches, got %#v", batches)
+	}
+}
@@ -0,0 +1,132 @@
+package tests_test
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type ReservedWordOrder struct {
+	ID    uint
+	Order int    `gorm:"column:order"`
+	Group string `gorm:"column:group"`
+}
+
+func TestReservedWordAndDottedIdentifiers(t *testing.T) {
+	// the table with a dot in its name is quoted by the users, so it isn't split
+	quoted := DB.Statement.Quote("x")
+	table := quoted[:1] + "legacy.reserved_word_orders" + quoted[len(quoted)-1:]
+
+	DB.Table(table).Migrator().DropTable(&ReservedWordOrder{})
+	if err := DB.Table(table).AutoMigrate(&ReservedWordOrder{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	orders := []ReservedWordOrder{{ID: 1, Order: 3, Group: "b"}, {ID: 2, Order: 1, Group: "a"}, {ID: 3, Order: 2, Group: "a"}}
+	if err := DB.Table(table).Create(&orders).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	tx := DB.Table(table).Session(&gorm.Session{})
+
+	t.Run("Order", func(t *testing.T) {
+		var results []ReservedWordOrder
+		if err := tx.Order("order desc").Find(&results).Error; err != nil {
+			t.Fatalf("failed to order by reserved word, got %v", err)
+		}
+		AssertEqual(t, []uint{results[0].ID, results[1].ID, results[2].ID}, []uint{1, 3, 2})
+
+		results = nil
+		if err := tx.Order("group, " + table + ".order DESC").Find(&results).Error; err != nil {
+			t.Fatalf("failed to order by dotted table, got %v", err)
+		}
+		AssertEqual(t, []uint{results[0].ID, results[1].ID, results[2].ID}, []uint{3, 2, 1})
+	})
+
+	t.Run("Group", func(t *testing.T) {
+		var groups []string
+		if err := tx.Group("group").Order("group").Pluck("group", &groups).Error; err != nil {
+			t.Fatalf("failed to group by reserved word, got %v", err)
+		}
+		AssertEqual(t, groups, []string{"a", "b"})
+
+		groups = nil
+		if err := tx.Group(table+".group").Order("group").Pluck(table+".group", &groups).Error; err != nil {
+			t.Fatalf("failed to group by dotted table, got %v", err)
+		}
+		AssertEqual(t, groups, []string{"a", "b"})
+	})
+
+	t.Run("Select", func(t *testing.T) {
+		var results []map[string]interface{}
+		if err := tx.Select("id", "order", table+".group").Order("id").Find(&results).Error; err != nil {
+			t.Fatalf("failed to select reserved words, got %v", err)
+		}
+
+		if len(results) != 3 || len(results[0]) != 3 || results[0]["group"] != "b" {
+			t.Errorf("failed to select reserved words, got %v", results)
+		}
+	})
+
+	t.Run("Pluck", func(t *testing.T) {
+		var values []int
+		if err := tx.Order("order").Pluck("order", &values).Error; err != nil {
+			t.Fatalf("failed to pluck reserved word, got %v", err)
+		}
+		AssertEqual(t, values, []int{1, 2, 3})
+	})
+
+	t.Run("Distinct", func(t *testing.T) {
+		var groups []string
+		if err := tx.Distinct("group").Order("group").Find(&groups).Error; err != nil {
+			t.Fatalf("failed to select distinct reserved word, got %v", err)
+		}
+		AssertEqual(t, groups, []string{"a", "b"})
+	})
+
+	t.Run("OnConflict", func(t *testing.T) {
+		if !DB.Supports(gorm.CapabilityOnConflict) {
+			t.Skip("ON CONFLICT isn't supported")
+		}
+
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&ReservedWordOrder{ID: 1, Order: 10, Group: "c"}).Error; err != nil {
+			t.Fatalf("failed to upsert reserved words, got %v", err)
+		}
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "id"}}, DoUpdates: clause.AssignmentColumns([]string{"order"}),
+		}).Create(&ReservedWordOrder{ID: 2, Order: 20, Group: "c"}).Error; err != nil {
+			t.Fatalf("failed to upsert reserved words, got %v", err)
+		}
+
+		var results []ReservedWordOrder
+		tx.Order("id").Find(&results)
+		AssertEqual(t, results[0], ReservedWordOrder{ID: 1, Order: 10, Group: "c"})
+		AssertEqual(t, results[1], ReservedWordOrder{ID: 2, Order: 20, Group: "a"})
+	})
+
+	t.Run("Plain", func(t *testing.T) {
+		// the names not reserved are written as they are, so they're folded like the names of raw SQL
+		sql := DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Model(&User{}).Group("Name").Order("Name DESC, users.Age").Find(&[]User{})
+		})
+
+		if !strings.Contains(sql, "ORDER BY Name DESC, users.Age") {
+			t.Errorf("the plain names shouldn't be quoted, got %v", sql)
+		}
+	})
+
+	t.Run("RawColumn", func(t *testing.T) {
+		sql := DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Table("events").Select(clause.RawColumn("CURRENT_TIMESTAMP")).Group("?", clause.RawColumn("kind")).
+				Order(clause.RawColumn("rowid")).Find(&[]map[string]interface{}{})
+		})
+
+		if !strings.Contains(sql, "SELECT CURRENT_TIMESTAMP FROM") || !strings.Contains(sql, "GROUP BY kind ORDER BY rowid") {
+			t.Errorf("raw columns should be written as they are, got %v", sql)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package tests_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestInterruptedByContext(t *testing.T) {
+	var statements []string
+	db, _ := gorm.Open(DB.Dialector, &gorm.Config{Logger: Tracer{
+		Logger: DB.Logger,
+		Test: func(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+			sql, _ := fc()
+			statements = append(statements, sql)
+		},
+	}})
+
+	countStatements := func(prefix string) (count int) {
+		for _, sql := range statements {
+			if strings.HasPrefix(sql, prefix) {
+				count++
+			}
+		}
+		return count
+	}
+
+	assertInterrupted := func(t *testing.T, err error, operation string, completed int) {
+		t.Helper()
+		var interrupted *gorm.InterruptedError
+		if !errors.Is(err, context.Canceled) || !errors.As(err, &interrupted) {
+			t.Fatalf("should be interrupted by the context, got %v", err)
+		}
+		AssertEqual(t, interrupted.Operation, operation)
+		AssertEqual(t, interrupted.Completed, completed)
+	}
+
+	t.Run("CreateInBatches", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		if err := db.Callback().Create().After("gorm:create").Register("interrupt:cancel", func(tx *gorm.DB) {
+			cancel()
+		}); err != nil {
+			t.Fatalf("failed to register callback, got %v", err)
+		}
+		defer db.Callback().Create().Remove("interrupt:cancel")
+
+		users := []User{
+			*GetUser("interrupt_batch_1", Config{}), *GetUser("interrupt_batch_2", Config{}),
+			*GetUser("interrupt_batch_3", Config{}), *GetUser("interrupt_batch_4", Config{}),
+		}
+
+		statements = nil
+		err := db.WithContext(ctx).Session(&gorm.Session{SkipDefaultTransaction: true}).CreateInBatches(&users, 2).Error
+		assertInterrupted(t, err, "create in batches", 1)
+		AssertEqual(t, countStatements("INSERT INTO"), 1)
+	})
+
+	t.Run("FindInBatches", func(t *testing.T) {
+		users := []User{*GetUser("interrupt_find_1", Config{}), *GetUser("interrupt_find_2", Config{})}
+		db.Create(&users)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var results []User
+		statements = nil
+		err := db.WithContext(ctx).Where("name LIKE ?", "interrupt_find_%").FindInBatches(&results, 1, func(tx *gorm.DB, batch int) error {
+			cancel()
+			return nil
+		}).Error
+		assertInterrupted(t, err, "find in batches", 1)
+		AssertEqual(t, countStatements("SELECT"), 1)
+	})
+
+	t.Run("Preload", func(t *testing.T) {
+		user := *GetUser("interrupt_preload", Config{Company: true, Pets: 2})
+		db.Create(&user)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		if err := db.Callback().Query().After("gorm:query").Register("interrupt:cancel", func(tx *gorm.DB) {
+			if tx.Statement.Table == "companies" {
+				cancel()
+			}
+		}); err != nil {
+			t.Fatalf("failed to register callback, got %v", err)
+		}
+		defer db.Callback().Query().Remove("interrupt:cancel")
+
+		var result User
+		statements = nil
+		err := db.WithContext(ctx).Preload("Company").Preload("Pets").First(&result, user.ID).Error
+		assertInterrupted(t, err, "preload", 1)
+		for _, sql := range statements {
+			if strings.Contains(sql, "pets") {
+				t.Errorf("the pets shouldn't be preloaded, got %v", sql)
+			}
+		}
+	})
+
+	t.Run("SaveAssociations", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		if err := db.Callback().Create().After("gorm:create").Before("gorm:save_after_associations").Register("interrupt:cancel", func(tx *gorm.DB) {
+			if tx.Statement.Table == "users" {
+				cancel()
+			}
+		}); err != nil {
+			t.Fatalf("failed to register callback, got %v", err)
+		}
+		defer db.Callback().Create().Remove("interrupt:cancel")
+
+		user := *GetUser("interrupt_associations", Config{Company: true, Pets: 2, Languages: 1})
+		statements = nil
+		err := db.WithContext(ctx).Session(&gorm.Session{SkipDefaultTransaction: true}).Create(&user).Error
+		assertInterrupted(t, err, "save associations", 1)
+		AssertEqual(t, countStatements("INSERT INTO `companies`")+countStatements(`INSERT INTO "companies"`), 1)
+		for _, sql := range statements {
+			if strings.Contains(sql, "pets") || strings.Contains(sql, "languages") || strings.Contains(sql, "user_speaks") {
+				t.Errorf("the associations shouldn't be saved after cancelled, got %v", sql)
+			}
+		}
+	})
+
+	t.Run("Transaction", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		statements = nil
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(GetUser("interrupt_transaction_1", Config{})).Error; err != nil {
+				return err
+			}
+			cancel()
+			return tx.Create(GetUser("interrupt_transaction_2", Config{})).Error
+		})
+		assertInterrupted(t, err, "transaction", 1)
+		AssertEqual(t, countStatements("INSERT INTO"), 1)
+
+		var count int64
+		DB.Model(&User{}).Where("name LIKE ?", "interrupt_transaction_%").Count(&count)
+		AssertEqual(t, count, 0)
+	})
+}
@@ -1,9 +1,11 @@
 package tests_test
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -476,3 +478,109 @@ func TestJoinsPreload_Issue7013_NoEntries(t *testing.T) {
 
 	AssertEqual(t, len(entries), 0)
 }
+
+type DedupParent struct {
+	ID         uint
+	Name       string
+	Children   []DedupChild
+	FoundTimes int `gorm:"-"`
+}
+
+func (p *DedupParent) AfterFind(tx *gorm.DB) error {
+	p.FoundTimes++
+	return nil
+}
+
+type DedupChild struct {
+	ID            uint
+	DedupParentID uint
+	Name          string
+}
+
+func TestJoinsDeduplicateByPrimaryKey(t *testing.T) {
+	DB.Migrator().DropTable(&DedupParent{}, &DedupChild{})
+	if err := DB.AutoMigrate(&DedupParent{}, &DedupChild{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	parents := []DedupParent{
+		{Name: "parent-1", Children: []DedupChild{{Name: "child-1"}, {Name: "child-2"}}},
+		{Name: "parent-2", Children: []DedupChild{{Name: "child-3"}}},
+	}
+	if err := DB.Create(&parents).Error; err != nil {
+		t.Fatalf("failed to create parents, got %v", err)
+	}
+
+	query := func(db *gorm.DB, dest interface{}) error {
+		return db.Select("dedup_parents.*").Joins("JOIN dedup_children ON dedup_children.dedup_parent_id = dedup_parents.id").
+			Preload("Children").Order("dedup_children.id").Find(dest).Error
+	}
+
+	var results []*DedupParent
+	if err := query(DB, &results); err != nil || len(results) != 3 {
+		t.Fatalf("failed to query, got %v, %v", len(results), err)
+	}
+
+	if results[0] == results[1] {
+		t.Errorf("the rows should be scanned into different structs by default")
+	}
+
+	results = nil
+	if err := query(DB.Session(&gorm.Session{DeduplicateByPrimaryKey: true}), &results); err != nil || len(results) != 3 {
+		t.Fatalf("failed to query, got %v, %v", len(results), err)
+	}
+
+	if results[0] != results[1] || results[0] == results[2] {
+		t.Fatalf("the rows of the same primary key should share the struct, got %p, %p, %p", results[0], results[1], results[2])
+	}
+
+	for _, result := range results {
+		if result.FoundTimes != 1 {
+			t.Errorf("AfterFind should be called once for %v, got %v", result.Name, result.FoundTimes)
+		}
+	}
+
+	if len(results[0].Children) != 2 || len(results[2].Children) != 1 {
+		t.Errorf("the children should be preloaded once, got %v, %v", len(results[0].Children), len(results[2].Children))
+	}
+
+	// the structs of the slices of values can't be shared
+	var values []DedupParent
+	if err := query(DB.Session(&gorm.Session{DeduplicateByPrimaryKey: true}), &values); err != nil || len(values) != 3 {
+		t.Fatalf("failed to query, got %v, %v", len(values), err)
+	}
+
+	for _, value := range values {
+		if value.FoundTimes != 1 {
+			t.Errorf("AfterFind should be called for %v, got %v", value.Name, value.FoundTimes)
+		}
+	}
+}
+
+func TestJoinsCheckAssociations(t *testing.T) {
+	if err := DB.Model(&User{}).Joins("Compnay").Error; !errors.Is(err, gorm.ErrUnsupportedRelation) || !strings.Contains(err.Error(), "did you mean Company?") {
+		t.Errorf("unknown relationships should return ErrUnsupportedRelation with the closest names, got %v", err)
+	}
+
+	if err := DB.Model(&User{}).InnerJoins("Manager.Compnay").Error; !errors.Is(err, gorm.ErrUnsupportedRelation) || !strings.Contains(err.Error(), "Manager.Compnay") {
+		t.Errorf("unknown nested relationships should return ErrUnsupportedRelation, got %v", err)
+	}
+
+	if err := DB.Model(&User{}).Joins("Manager.Company").Joins("JOIN pets ON pets.user_id = users.id").Error; err != nil {
+		t.Errorf("known relationships and raw SQL shouldn't return error, got %v", err)
+	}
+
+	dryRun := DB.Session(&gorm.Session{DryRun: true})
+	if err := dryRun.Joins("Compnay").Find(&[]User{}).Error; err != nil {
+		t.Errorf("unknown relationships are treated as raw SQL without model, got %v", err)
+	}
+
+	strict := dryRun.Session(&gorm.Session{StrictAssociations: true})
+	if err := strict.Joins("Compnay").Find(&[]User{}).Error; !errors.Is(err, gorm.ErrUnsupportedRelation) {
+		t.Errorf("unknown relationships should return ErrUnsupportedRelation if strict, got %v", err)
+	}
+
+	if err := strict.Joins("Company").Joins("JOIN pets ON pets.user_id = users.id").Find(&[]User{}).Error; err != nil {
+		t.Errorf("known relationships and raw SQL shouldn't return error if strict, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package tests_test
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type CascadeCountry struct {
+	Code      string `gorm:"primaryKey"`
+	Name      string
+	Cities    []CascadeCity     `gorm:"foreignKey:CountryCode;references:Code"`
+	Languages []CascadeLanguage `gorm:"many2many:cascade_country_languages"`
+	Notes     []CascadeNote     `gorm:"polymorphic:Owner;polymorphicValue:country"`
+}
+
+type CascadeCity struct {
+	CountryCode string            `gorm:"primaryKey"`
+	Code        string            `gorm:"primaryKey"`
+	Districts   []CascadeDistrict `gorm:"foreignKey:CountryCode,CityCode;references:CountryCode,Code"`
+}
+
+type CascadeDistrict struct {
+	ID          uint
+	CountryCode string
+	CityCode    string
+	Name        string
+}
+
+type CascadeLanguage struct {
+	Code string `gorm:"primaryKey"`
+}
+
+type CascadeNote struct {
+	ID        uint
+	OwnerID   string
+	OwnerType string
+	Text      string
+}
+
+func TestUpdateKeyCascade(t *testing.T) {
+	models := []interface{}{&CascadeCountry{}, &CascadeCity{}, &CascadeDistrict{}, &CascadeLanguage{}, &CascadeNote{}, "cascade_country_languages"}
+	DB.Migrator().DropTable(models...)
+	migrateDB := DB.Session(&gorm.Session{})
+	migrateDB.Config.DisableForeignKeyConstraintWhenMigrating = true
+	if err := migrateDB.AutoMigrate(models[:5]...); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	country := CascadeCountry{
+		Code: "CS",
+		Name: "Czechoslovakia",
+		Cities: []CascadeCity{
+			{Code: "PRG", Districts: []CascadeDistrict{{Name: "Old Town"}, {Name: "New Town"}}},
+			{Code: "BRN", Districts: []CascadeDistrict{{Name: "Centre"}}},
+		},
+		Languages: []CascadeLanguage{{Code: "cs"}, {Code: "sk"}},
+		Notes:     []CascadeNote{{Text: "split in 1993"}},
+	}
+	if err := DB.Create(&country).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+	// the notes of other owners of the same id aren't cascaded
+	DB.Create(&CascadeNote{OwnerID: "CS", OwnerType: "city", Text: "other owner"})
+
+	result, err := DB.Model(&country).UpdateKeyCascade(map[string]interface{}{"Code": "CZ"})
+	if err != nil {
+		t.Fatalf("failed to update key cascade, got error %v", err)
+	}
+
+	expects := map[string]int64{
+		"cascade_countries":         1,
+		"cascade_cities":            2,
+		"cascade_districts":         3,
+		"cascade_country_languages": 2,
+		"cascade_notes":             1,
+	}
+	for table, rows := range expects {
+		if result.RowsAffected[table] != rows {
+			t.Errorf("%v rows of %v should be updated, got %v", rows, table, result.RowsAffected[table])
+		}
+	}
+
+	if country.Code != "CZ" {
+		t.Errorf("the key of the model should be updated, got %v", country.Code)
+	}
+
+	var loaded CascadeCountry
+	if err := DB.Preload("Cities.Districts").Preload("Languages").Preload("Notes").First(&loaded, "code = ?", "CZ").Error; err != nil {
+		t.Fatalf("failed to find the updated country, got error %v", err)
+	}
+	if len(loaded.Cities) != 2 || len(loaded.Cities[0].Districts)+len(loaded.Cities[1].Districts) != 3 || len(loaded.Languages) != 2 || len(loaded.Notes) != 1 {
+		t.Errorf("the children should be cascaded, got %+v", loaded)
+	}
+
+	var count int64
+	DB.Model(&CascadeNote{}).Where("owner_id = ? AND owner_type = ?", "CS", "city").Count(&count)
+	if count != 1 {
+		t.Errorf("the notes of other owners shouldn't be updated, got %v", count)
+	}
+
+	if _, err := DB.Model(&CascadeCountry{Code: "CS"}).UpdateKeyCascade(map[string]interface{}{"Code": "XX"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("should return ErrRecordNotFound for the missing model, got %v", err)
+	}
+
+	if _, err := DB.Model(&country).UpdateKeyCascade(map[string]interface{}{"Unknown": "XX"}); !errors.Is(err, gorm.ErrInvalidField) {
+		t.Errorf("should return ErrInvalidField for the unknown field, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package tests_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/metrics"
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestMetricsPlugin(t *testing.T) {
+	db, err := OpenTestConnection(&gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	collector := metrics.NewMemoryCollector()
+	if err := db.Use(metrics.New(collector)); err != nil {
+		t.Fatalf("failed to use the metrics plugin, got error %v", err)
+	}
+
+	users := []User{*GetUser("metrics-1", Config{}), *GetUser("metrics-2", Config{})}
+	db.Create(&users)
+	db.Model(&User{}).Where("name LIKE ?", "metrics-%").Update("age", 20)
+	var result User
+	db.Where("name = ?", "metrics-none").First(&result)
+	db.Find(&[]User{}, "name LIKE ?", "metrics-%")
+	db.Table("users").Select("name").Where("name = ?", "metrics-1").Row()
+	db.Exec("UPDATE users SET age = age + 1 WHERE name LIKE ?", "metrics-%")
+	db.Delete(&users[0])
+	db.Session(&gorm.Session{DryRun: true}).Delete(&users[1])
+
+	snapshot := collector.Snapshot()
+	AssertEqual(t, snapshot[metrics.Key{Table: "users", Operation: metrics.OperationCreate}].RowsAffected, int64(2))
+	AssertEqual(t, snapshot[metrics.Key{Table: "users", Operation: metrics.OperationUpdate}].RowsAffected, int64(2))
+
+	queries := snapshot[metrics.Key{Table: "users", Operation: metrics.OperationQuery}]
+	AssertEqual(t, queries.Count, int64(2))
+	AssertEqual(t, queries.Errors, int64(1))
+	if queries.TotalDuration <= 0 || queries.MaxDuration > queries.TotalDuration {
+		t.Errorf("expected the latency of queries observed, got %+v", queries)
+	}
+
+	AssertEqual(t, snapshot[metrics.Key{Table: "users", Operation: metrics.OperationRow}].Count, int64(1))
+	AssertEqual(t, snapshot[metrics.Key{Table: "", Operation: metrics.OperationRaw}].RowsAffected, int64(2))
+	// the statements of DryRun mode aren't observed
+	AssertEqual(t, snapshot[metrics.Key{Table: "users", Operation: metrics.OperationDelete}].Count, int64(1))
+
+	collector.Reset()
+	AssertEqual(t, len(collector.Snapshot()), 0)
+
+	var observedErr error
+	funcDB, _ := OpenTestConnection(&gorm.Config{})
+	funcDB.Use(metrics.New(metrics.CollectorFunc(func(table, operation string, _ time.Duration, _ int64, err error) {
+		observedErr = err
+	})))
+	funcDB.First(&result, "name = ?", "metrics-none")
+	if !errors.Is(observedErr, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound observed, got %v", observedErr)
+	}
+}
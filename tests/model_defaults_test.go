@@ -0,0 +1,163 @@
+package tests_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type DefaultsPost struct {
+	ID       uint
+	Title    string
+	Body     string
+	Comments []DefaultsComment
+}
+
+func (DefaultsPost) DefaultSelect() []string {
+	return []string{"Title"}
+}
+
+func (DefaultsPost) DefaultCreateBatchSize() int {
+	return 3
+}
+
+func (DefaultsPost) DefaultClauses() []clause.Expression {
+	return []clause.Expression{clause.OrderBy{Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "id"}, Desc: true}}}}
+}
+
+type DefaultsComment struct {
+	ID             uint
+	DefaultsPostID uint
+	Content        string
+	Note           string
+}
+
+func (DefaultsComment) DefaultSelect() []string {
+	return []string{"content"}
+}
+
+func TestModelDefaultsSelect(t *testing.T) {
+	DB.Migrator().DropTable(&DefaultsComment{}, &DefaultsPost{})
+	if err := DB.AutoMigrate(&DefaultsPost{}, &DefaultsComment{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	posts := []DefaultsPost{
+		{Title: "post 1", Body: "body 1", Comments: []DefaultsComment{{Content: "comment 1", Note: "note 1"}}},
+		{Title: "post 2", Body: "body 2", Comments: []DefaultsComment{{Content: "comment 2", Note: "note 2"}}},
+	}
+	if err := DB.Create(&posts).Error; err != nil {
+		t.Fatalf("failed to create posts, got error %v", err)
+	}
+
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	stmt := dryDB.Find(&[]DefaultsPost{}).Statement
+	if !regexp.MustCompile(`SELECT .title.,.id. FROM .defaults_posts. ORDER BY .id. DESC`).MatchString(stmt.SQL.String()) {
+		t.Errorf("DefaultSelect and DefaultClauses should be applied, got %v", stmt.SQL.String())
+	}
+
+	stmt = dryDB.Select("body").Order("title").Find(&[]DefaultsPost{}).Statement
+	if !regexp.MustCompile(`SELECT .body. FROM .defaults_posts. ORDER BY title$`).MatchString(stmt.SQL.String()) {
+		t.Errorf("Select and Order of the chain should override the model defaults, got %v", stmt.SQL.String())
+	}
+
+	stmt = dryDB.Session(&gorm.Session{IgnoreModelDefaults: true}).Find(&[]DefaultsPost{}).Statement
+	if !regexp.MustCompile(`SELECT \* FROM .defaults_posts.$`).MatchString(stmt.SQL.String()) {
+		t.Errorf("IgnoreModelDefaults should ignore the model defaults, got %v", stmt.SQL.String())
+	}
+
+	var count int64
+	if err := DB.Model(&DefaultsPost{}).Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("failed to count posts, got count %v, error %v", count, err)
+	}
+
+	var results []DefaultsPost
+	if err := DB.Preload("Comments").Find(&results).Error; err != nil {
+		t.Fatalf("failed to find posts, got error %v", err)
+	}
+
+	if len(results) != 2 || results[0].ID != posts[1].ID || results[0].Title != "post 2" || results[0].Body != "" {
+		t.Fatalf("posts should be found with DefaultSelect and DefaultClauses, got %+v", results)
+	}
+
+	for _, result := range results {
+		if len(result.Comments) != 1 || result.Comments[0].Content == "" || result.Comments[0].Note != "" ||
+			result.Comments[0].DefaultsPostID != result.ID {
+			t.Errorf("comments should be preloaded with DefaultSelect and the foreign keys, got %+v", result.Comments)
+		}
+	}
+
+	var ignored DefaultsPost
+	if err := DB.Session(&gorm.Session{IgnoreModelDefaults: true}).Preload("Comments").First(&ignored, posts[0].ID).Error; err != nil {
+		t.Fatalf("failed to find post, got error %v", err)
+	}
+
+	if ignored.Body != "body 1" || len(ignored.Comments) != 1 || ignored.Comments[0].Note != "note 1" {
+		t.Errorf("all columns should be found with IgnoreModelDefaults, got %+v", ignored)
+	}
+}
+
+func TestModelDefaultsCreateBatchSize(t *testing.T) {
+	db, err := OpenTestConnection(&gorm.Config{CreateBatchSize: 2})
+	if err != nil {
+		t.Fatalf("failed to open connection, got error %v", err)
+	}
+
+	if err := db.AutoMigrate(&DefaultsPost{}, &DefaultsComment{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	var inserts int
+	db.Callback().Create().After("gorm:create").Register("count_inserts", func(tx *gorm.DB) {
+		if strings.HasPrefix(tx.Statement.SQL.String(), "INSERT INTO") {
+			inserts++
+		}
+	})
+
+	newPosts := func() []DefaultsPost {
+		posts := make([]DefaultsPost, 6)
+		for i := range posts {
+			posts[i].Title = "batch post"
+		}
+		return posts
+	}
+
+	newUsers := func() []User {
+		users := make([]User, 6)
+		for i := range users {
+			users[i].Name = "batch user"
+		}
+		return users
+	}
+
+	tests := []struct {
+		name    string
+		create  func() error
+		inserts int
+	}{
+		{"config", func() error { return db.Create(newUsers()).Error }, 3},
+		{"model", func() error { return db.Create(newPosts()).Error }, 2},
+		{"session", func() error { return db.Session(&gorm.Session{CreateBatchSize: 6}).Create(newPosts()).Error }, 1},
+		{"chain", func() error {
+			return db.Session(&gorm.Session{CreateBatchSize: 6}).CreateInBatches(newPosts(), 1).Error
+		}, 6},
+		{"ignored", func() error { return db.Session(&gorm.Session{IgnoreModelDefaults: true}).Create(newPosts()).Error }, 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inserts = 0
+			if err := test.create(); err != nil {
+				t.Fatalf("failed to create, got error %v", err)
+			}
+
+			if inserts != test.inserts {
+				t.Errorf("should create in %v batches, got %v", test.inserts, inserts)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package tests_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestNamedQuery(t *testing.T) {
+	if err := gorm.RegisterQuery("users_by_age", `SELECT name, age FROM users WHERE name LIKE @prefix {{if .MinAge}}AND age >= @MinAge{{end}}`); err != nil {
+		t.Fatalf("failed to register query, got %v", err)
+	}
+
+	users := []User{*GetUser("named_query_1", Config{}), *GetUser("named_query_2", Config{}), *GetUser("named_query_3", Config{})}
+	for idx := range users {
+		users[idx].Age = uint(idx + 10)
+	}
+	DB.Create(&users)
+
+	type Params struct {
+		Prefix string
+		MinAge int
+	}
+
+	type Result struct {
+		Name string
+		Age  int
+	}
+
+	var results []Result
+	if err := DB.Named("users_by_age", map[string]interface{}{"prefix": "named_query_%", "MinAge": 11}).Order("age").Find(&results).Error; err != nil {
+		t.Fatalf("failed to query named query, got %v", err)
+	}
+
+	if len(results) != 2 || results[0].Name != "named_query_2" || results[1].Name != "named_query_3" {
+		t.Errorf("results of named query are incorrect, got %+v", results)
+	}
+
+	var count int64
+	if err := DB.Named("users_by_age", map[string]interface{}{"prefix": "named_query_%"}).Count(&count).Error; err != nil || count != 3 {
+		t.Errorf("count of named query should be 3, got %v, %v", count, err)
+	}
+
+	var names []string
+	if err := DB.Named("users_by_age", map[string]interface{}{"prefix": "named_query_%"}).Where("age < ?", 12).Order("age DESC").Limit(1).Offset(1).Pluck("name", &names).Error; err != nil {
+		t.Errorf("failed to paginate named query, got %v", err)
+	} else if len(names) != 1 || names[0] != "named_query_1" {
+		t.Errorf("names of named query are incorrect, got %v", names)
+	}
+
+	var found []User
+	if err := DB.Named("users_by_age", map[string]interface{}{"prefix": "named_query_1"}).Unscoped().Find(&found).Error; err != nil || len(found) != 1 || found[0].Age != 10 {
+		t.Errorf("named query should be scanned into models, got %+v, %v", found, err)
+	}
+
+	if err := DB.Named("users_by_age", map[string]interface{}{"prefix": "named_query_1"}).Find(&found).Error; err == nil {
+		t.Errorf("soft delete conditions should be applied to named query unless Unscoped")
+	}
+
+	sql := DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Named("users_by_age", map[string]interface{}{"prefix": "named_query_%", "MinAge": 11}).Find(&[]Result{})
+	})
+	if !regexp.MustCompile(`SELECT \* FROM \(SELECT name, age FROM users WHERE name LIKE .named_query_%. AND age >= 11\) AS .users_by_age.`).MatchString(sql) {
+		t.Errorf("SQL of named query is incorrect, got %v", sql)
+	}
+
+	if err := gorm.RegisterQuery("users_by_prefix", `SELECT name, age FROM users WHERE name LIKE @Prefix {{if .MinAge}}AND age >= @MinAge{{end}}`); err != nil {
+		t.Fatalf("failed to register query, got %v", err)
+	}
+
+	if err := DB.Named("users_by_prefix", Params{Prefix: "named_query_%", MinAge: 12}).Find(&results).Error; err != nil {
+		t.Errorf("failed to query named query with struct params, got %v", err)
+	} else if len(results) != 1 || results[0].Name != "named_query_3" {
+		t.Errorf("results of named query are incorrect, got %+v", results)
+	}
+
+	if err := gorm.RegisterQuery("users_by_name", `SELECT name, age FROM users WHERE name = {{.name}}{{if .ages}} AND age IN {{.ages}}{{end}}`); err != nil {
+		t.Fatalf("failed to register query, got %v", err)
+	}
+
+	results = nil
+	if err := DB.Named("users_by_name", map[string]interface{}{"name": "named_query_1' OR '1'='1"}).Find(&results).Error; err != nil || len(results) != 0 {
+		t.Errorf("printed values should be bound as vars, got %+v, %v", results, err)
+	}
+
+	if err := DB.Named("users_by_name", map[string]interface{}{"name": "named_query_2", "ages": []int{10, 11}}).Find(&results).Error; err != nil || len(results) != 1 || results[0].Age != 11 {
+		t.Errorf("printed values should be bound as vars, got %+v, %v", results, err)
+	}
+
+	result := DB.Session(&gorm.Session{DryRun: true}).Named("users_by_name", map[string]interface{}{"name": "named_query_2", "ages": []int{10, 11}}).Find(&[]Result{})
+	if !regexp.MustCompile(`WHERE name = \S+\s+AND age IN \(\S+,\S+\)\s*\) AS`).MatchString(result.Statement.SQL.String()) || len(result.Statement.Vars) != 3 {
+		t.Errorf("printed values should be rendered as placeholders, got %v, %v", result.Statement.SQL.String(), result.Statement.Vars)
+	}
+}
+
+func TestNamedQueryErrors(t *testing.T) {
+	if err := gorm.RegisterQuery("invalid_template", `SELECT * FROM users {{if .Name}}`); err == nil {
+		t.Errorf("template parse errors should be returned when registering")
+	}
+
+	if err := DB.Named("not_registered", nil).Find(&[]User{}).Error; !errors.Is(err, gorm.ErrUnregisteredQuery) {
+		t.Errorf("expected ErrUnregisteredQuery, got %v", err)
+	}
+
+	gorm.RegisterQuery("missing_field", `SELECT * FROM users {{if .Missing}}WHERE name = @Name{{end}}`)
+	if err := DB.Named("missing_field", struct{ Name string }{}).Find(&[]User{}).Error; err == nil {
+		t.Errorf("template execution errors should be returned")
+	}
+}
@@ -0,0 +1,88 @@
+//go:build go1.22
+
+package tests_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	. "gorm.io/gorm/utils/tests"
+)
+
+type Option[T any] struct {
+	ok    bool
+	value T
+}
+
+func Some[T any](value T) Option[T] {
+	return Option[T]{ok: true, value: value}
+}
+
+func (o *Option[T]) Scan(value interface{}) error {
+	var n sql.Null[T]
+	if err := n.Scan(value); err != nil {
+		return err
+	}
+	o.value, o.ok = n.V, n.Valid
+	return nil
+}
+
+func (o Option[T]) Value() (driver.Value, error) {
+	return sql.Null[T]{V: o.value, Valid: o.ok}.Value()
+}
+
+type NullableUser struct {
+	ID       uint
+	Name     sql.Null[string]
+	Birthday sql.Null[time.Time]
+	Nick     Option[string] `gorm:"default:anonymous"`
+}
+
+func TestNullableWrappers(t *testing.T) {
+	DB.Migrator().DropTable(&NullableUser{})
+	if err := DB.AutoMigrate(&NullableUser{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	birthday := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	user := NullableUser{Name: sql.Null[string]{V: "nullable", Valid: true}, Birthday: sql.Null[time.Time]{V: birthday, Valid: true}}
+	if err := DB.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+
+	var result NullableUser
+	if err := DB.First(&result, user.ID).Error; err != nil {
+		t.Fatalf("failed to find user, got error %v", err)
+	}
+	AssertEqual(t, result.Name, user.Name)
+	if !result.Birthday.Valid || !result.Birthday.V.Equal(birthday) {
+		t.Errorf("birthday should be %v, got %+v", birthday, result.Birthday)
+	}
+	if result.Nick != Some("anonymous") {
+		t.Errorf("invalid nick should use the default value, got %+v", result.Nick)
+	}
+
+	// invalid wrappers are zero values, which are skipped by Updates and conditions
+	if err := DB.Model(&result).Updates(NullableUser{Name: sql.Null[string]{V: "invalid"}, Nick: Some("nick")}).Error; err != nil {
+		t.Fatalf("failed to update user, got error %v", err)
+	}
+
+	result = NullableUser{}
+	if err := DB.Where(&NullableUser{Name: sql.Null[string]{V: "invalid"}, Nick: Some("nick")}).First(&result, user.ID).Error; err != nil {
+		t.Fatalf("failed to find user with nick, got error %v", err)
+	}
+	if result.Name.V != "nullable" || result.Nick != Some("nick") {
+		t.Errorf("invalid name should not be updated, got %+v", result)
+	}
+
+	if err := DB.Model(&result).Update("birthday", sql.Null[time.Time]{}).Error; err != nil {
+		t.Fatalf("failed to update birthday to NULL, got error %v", err)
+	}
+
+	result = NullableUser{Birthday: sql.Null[time.Time]{V: birthday, Valid: true}}
+	if err := DB.First(&result, user.ID).Error; err != nil || result.Birthday.Valid {
+		t.Errorf("birthday should be NULL, got %+v, error %v", result.Birthday, err)
+	}
+}
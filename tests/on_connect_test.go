@@ -0,0 +1,142 @@
+package tests_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+// dsnConnector opens the connections of the driver with the dsn, like the connectors of the drivers
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// connectorDialector exposes the connector of the pool opened by the sqlite dialector for Config.OnConnect
+type connectorDialector struct {
+	*sqlite.Dialector
+	driver driver.Driver
+}
+
+func (d connectorDialector) Connector() (driver.Connector, error) {
+	if d.Conn != nil {
+		return nil, nil
+	}
+	return dsnConnector{dsn: d.DSN, driver: d.driver}, nil
+}
+
+func TestOnConnect(t *testing.T) {
+	if DB.Dialector.Name() != "sqlite" {
+		t.Skip("the pragmas are only supported by sqlite")
+	}
+
+	rootDB, _ := DB.DB()
+	connector := dsnConnector{dsn: filepath.Join(os.TempDir(), "gorm.db"), driver: rootDB.Driver()}
+
+	assertForeignKeys := func(t *testing.T, tx *gorm.DB) {
+		t.Helper()
+		var enabled int
+		if err := tx.Raw("PRAGMA foreign_keys").Scan(&enabled).Error; err != nil || enabled != 1 {
+			t.Errorf("foreign keys should be enabled on connect, got %v, error %v", enabled, err)
+		}
+	}
+
+	for _, prepareStmt := range []bool{false, true} {
+		// the temp table is created once per connection, executing it per query would fail
+		sqlDB := sql.OpenDB(gorm.OnConnectConnector(connector, "PRAGMA foreign_keys = ON", "CREATE TEMP TABLE on_connect_marks (id INTEGER)"))
+		sqlDB.SetMaxIdleConns(2)
+
+		db, err := gorm.Open(sqlite.New(sqlite.Config{Conn: sqlDB}), &gorm.Config{PrepareStmt: prepareStmt})
+		if err != nil {
+			t.Fatalf("failed to open with OnConnectConnector, got %v", err)
+		}
+
+		for i := 0; i < 3; i++ {
+			assertForeignKeys(t, db)
+		}
+
+		if err := db.Connection(func(tx *gorm.DB) error {
+			assertForeignKeys(t, tx)
+			return tx.Exec("INSERT INTO on_connect_marks (id) VALUES (1)").Error
+		}); err != nil {
+			t.Errorf("the pinned connection should be initialized, got %v", err)
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			assertForeignKeys(t, tx)
+			return nil
+		}); err != nil {
+			t.Errorf("failed to run transaction, got %v", err)
+		}
+
+		sqlDB.Close()
+	}
+
+	t.Run("Config", func(t *testing.T) {
+		dialector := connectorDialector{Dialector: &sqlite.Dialector{DSN: connector.dsn}, driver: connector.driver}
+		db, err := gorm.Open(dialector, &gorm.Config{
+			// the temp table is created once per connection, executing it per query would fail
+			OnConnect: []string{"PRAGMA foreign_keys = ON", "CREATE TEMP TABLE on_connect_marks (id INTEGER)"},
+			Replicas:  []gorm.Dialector{dialector},
+		})
+		if err != nil {
+			t.Fatalf("failed to open with OnConnect, got %v", err)
+		}
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+
+		// the connections aren't pooled, every query runs on a new physical connection
+		sqlDB.SetMaxIdleConns(0)
+		for i := 0; i < 3; i++ {
+			assertForeignKeys(t, db)
+			assertForeignKeys(t, db.Clauses(gorm.Write))
+		}
+
+		sqlDB.SetMaxIdleConns(2)
+		for i := 0; i < 3; i++ {
+			if err := db.Clauses(gorm.Write).Exec("INSERT INTO on_connect_marks (id) VALUES (?)", i).Error; err != nil {
+				t.Errorf("the temp table should be created on connect, got %v", err)
+			}
+			assertForeignKeys(t, db)
+		}
+
+		if _, err := gorm.Open(sqlite.New(sqlite.Config{Conn: sqlDB}), &gorm.Config{OnConnect: []string{"PRAGMA foreign_keys = ON"}}); !errors.Is(err, gorm.ErrUnsupportedDriver) {
+			t.Errorf("OnConnect should require the connector of the dialector, got %v", err)
+		}
+		if err := sqlDB.Ping(); err != nil {
+			t.Errorf("the given pool shouldn't be closed, got %v", err)
+		}
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		sqlDB := sql.OpenDB(gorm.OnConnectConnector(connector, "PRAGMA foreign_keys = ON", "SELECT * FROM on_connect_missing"))
+		defer sqlDB.Close()
+
+		_, err := gorm.Open(sqlite.New(sqlite.Config{Conn: sqlDB}), &gorm.Config{})
+		var onConnectErr *gorm.OnConnectError
+		if !errors.As(err, &onConnectErr) {
+			t.Fatalf("should return OnConnectError, got %v", err)
+		}
+		AssertEqual(t, onConnectErr.SQL, "SELECT * FROM on_connect_missing")
+
+		if stats := sqlDB.Stats(); stats.OpenConnections != 0 {
+			t.Errorf("the failed connections shouldn't be pooled, got %v", stats.OpenConnections)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package tests_test
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestPaginate(t *testing.T) {
+	users := []*User{
+		GetUser("paginate_1", Config{Pets: 2}),
+		GetUser("paginate_2", Config{Pets: 1, Company: true}),
+		GetUser("paginate_3", Config{}),
+		GetUser("paginate_4", Config{}),
+		GetUser("paginate_5", Config{}),
+	}
+	for i, user := range users {
+		user.Age = uint(20 + i%2)
+	}
+	DB.Create(&users)
+	ids := make([]uint, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	var result []User
+	tx := DB.Where("id IN ?", ids).Order("name DESC").Preload("Pets")
+	info, err := tx.Paginate(2, 2, &result)
+	if err != nil {
+		t.Fatalf("failed to paginate, got error %v", err)
+	}
+	AssertEqual(t, info, gorm.PageInfo{TotalRows: 5, TotalPages: 3, Page: 2, PerPage: 2, HasNext: true})
+	if len(result) != 2 || result[0].Name != "paginate_3" || result[1].Name != "paginate_2" || len(result[1].Pets) != 1 {
+		t.Errorf("should find the second page with the pets, got %+v", result)
+	}
+
+	// the conditions aren't duplicated or lost by the count
+	info, err = tx.Paginate(3, 2, &result)
+	if err != nil || len(result) != 1 || result[0].Name != "paginate_1" || len(result[0].Pets) != 2 {
+		t.Errorf("should find the last page, got %+v, error %v", result, err)
+	}
+	AssertEqual(t, info, gorm.PageInfo{TotalRows: 5, TotalPages: 3, Page: 3, PerPage: 2, HasNext: false})
+
+	info, err = tx.Paginate(0, 10, &result)
+	if err != nil || len(result) != 5 || info.Page != 1 || info.HasNext {
+		t.Errorf("the page less than 1 should be the first page, got %+v, %v rows, error %v", info, len(result), err)
+	}
+
+	limited := DB.Session(&gorm.Session{})
+	limited.Config.MaxPerPage = 3
+	if info, err = limited.Where("id IN ?", ids).Paginate(1, 100, &result); err != nil || info.PerPage != 3 || len(result) != 3 || info.TotalPages != 2 {
+		t.Errorf("the rows per page should be limited, got %+v, %v rows, error %v", info, len(result), err)
+	}
+
+	if _, err = DB.Where("id IN ?", ids).Paginate(1, 0, &result); err == nil {
+		t.Errorf("should return error for the invalid rows per page")
+	}
+
+	var joined []User
+	if info, err = DB.Joins("Company").Where("users.id IN ?", ids).Order("users.id").Paginate(1, 2, &joined); err != nil || info.TotalRows != 5 || len(joined) != 2 || joined[1].Company.Name != users[1].Company.Name {
+		t.Errorf("should paginate with joins, got %+v, %+v, error %v", info, joined, err)
+	}
+
+	var groups []struct {
+		Age   uint
+		Total int
+	}
+	info, err = DB.Model(&User{}).Select("age, count(*) AS total").Where("id IN ?", ids).Group("age").Order("age").Paginate(1, 1, &groups)
+	if err != nil || info.TotalRows != 2 || info.TotalPages != 2 || len(groups) != 1 || groups[0].Age != 20 || groups[0].Total != 3 {
+		t.Errorf("should count the groups, got %+v, %+v, error %v", info, groups, err)
+	}
+
+	var ages []User
+	info, err = DB.Model(&User{}).Distinct("age").Where("id IN ?", ids).Paginate(1, 10, &ages)
+	if err != nil || info.TotalRows != 2 || len(ages) != 2 {
+		t.Errorf("should count the distinct rows, got %+v, %+v, error %v", info, ages, err)
+	}
+}
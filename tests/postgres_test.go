@@ -1,6 +1,7 @@
 package tests_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -254,3 +255,96 @@ func TestAlterColumnDataType(t *testing.T) {
 
 	DB.AutoMigrate(Company{})
 }
+
+type PostgresLocation struct {
+	Lat, Lng float64
+	Label    *string
+}
+
+type PostgresPlace struct {
+	ID       uint
+	Name     string
+	Tags     []string         `gorm:"type:text[]"`
+	Grid     [][]int64        `gorm:"type:bigint[][]"`
+	Location PostgresLocation `gorm:"composite:location_t"`
+}
+
+func TestPostgresArrayAndCompositeTypes(t *testing.T) {
+	if DB.Dialector.Name() != "postgres" {
+		if err := DB.First(&PostgresPlace{}).Error; !errors.Is(err, gorm.ErrUnsupportedDriver) {
+			t.Errorf("the array and composite fields should be rejected by %s, got %v", DB.Dialector.Name(), err)
+		}
+
+		if err := DB.Migrator().AutoMigrate(&PostgresPlace{}); !errors.Is(err, gorm.ErrUnsupportedDriver) {
+			t.Errorf("the array and composite fields shouldn't be migrated by %s, got %v", DB.Dialector.Name(), err)
+		}
+		return
+	}
+
+	DB.Migrator().DropTable(&PostgresPlace{})
+	DB.Exec("DROP TYPE IF EXISTS location_t")
+	if err := DB.Exec("CREATE TYPE location_t AS (lat float8, lng float8, label text)").Error; err != nil {
+		t.Fatalf("failed to create composite type, got error %v", err)
+	}
+
+	if err := DB.AutoMigrate(&PostgresPlace{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	label := `the "old" town, center`
+	place := PostgresPlace{
+		Name:     "prague",
+		Tags:     []string{"old town", "", "NULL", `back\slash`},
+		Grid:     [][]int64{{1, 2}, {3, 4}},
+		Location: PostgresLocation{Lat: 50.08, Lng: 14.42, Label: &label},
+	}
+	if err := DB.Create(&place).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	var result PostgresPlace
+	if err := DB.First(&result, place.ID).Error; err != nil {
+		t.Fatalf("failed to query, got error %v", err)
+	}
+	AssertEqual(t, result, place)
+
+	var count int64
+	DB.Model(&PostgresPlace{}).Where("? = ANY(tags) AND (location).lat > ?", "old town", 50).Count(&count)
+	if count != 1 {
+		t.Errorf("the array and composite should be queried by postgres, got %v", count)
+	}
+}
+
+type ScannerArrayPlace struct {
+	ID   uint
+	Name string
+	Tags pq.StringArray `gorm:"type:text[]"`
+}
+
+func TestScannerArrayTypes(t *testing.T) {
+	// the arrays implementing sql.Scanner and driver.Valuer are converted by themselves on any database
+	DB.Migrator().DropTable(&ScannerArrayPlace{})
+	if err := DB.AutoMigrate(&ScannerArrayPlace{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	place := ScannerArrayPlace{Name: "berlin", Tags: pq.StringArray{"old town", "river"}}
+	if err := DB.Create(&place).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	var result ScannerArrayPlace
+	if err := DB.First(&result, place.ID).Error; err != nil {
+		t.Fatalf("failed to find, got error %v", err)
+	}
+	AssertEqual(t, result.Tags, place.Tags)
+
+	if DB.Dialector.Name() != "postgres" {
+		// the array fields excluded by Select or Omit are not used
+		var found PostgresPlace
+		if err := DB.Table("scanner_array_places").Select("id", "name").First(&found, place.ID).Error; err != nil {
+			t.Errorf("the unused array fields shouldn't be rejected by %s, got %v", DB.Dialector.Name(), err)
+		}
+		AssertEqual(t, found.Name, "berlin")
+	}
+}
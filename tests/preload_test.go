@@ -1,17 +1,23 @@
 package tests_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"regexp"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -628,3 +634,274 @@ func TestEmbedPreload(t *testing.T) {
 		})
 	}
 }
+
+type LateLoadItem struct {
+	ID              uint
+	LateLoadChildID uint
+	Name            string
+}
+
+type LateLoadChild struct {
+	ID               uint
+	LateLoadParentID uint
+	Name             string
+	DeletedAt        gorm.DeletedAt
+	Items            []LateLoadItem
+	Found            bool `gorm:"-"`
+}
+
+func (child *LateLoadChild) AfterFind(tx *gorm.DB) error {
+	child.Found = true
+	return nil
+}
+
+type LateLoadParent struct {
+	ID       uint
+	Name     string
+	Children []*LateLoadChild
+}
+
+func TestLoadAssociations(t *testing.T) {
+	DB.Migrator().DropTable(&LateLoadParent{}, &LateLoadChild{}, &LateLoadItem{})
+	if err := DB.AutoMigrate(&LateLoadParent{}, &LateLoadChild{}, &LateLoadItem{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	parents := []LateLoadParent{
+		{Name: "parent_1", Children: []*LateLoadChild{
+			{Name: "child_1_1", Items: []LateLoadItem{{Name: "item_1_1_1"}, {Name: "item_1_1_2"}}},
+			{Name: "child_1_2"},
+			{Name: "child_1_3"},
+		}},
+		{Name: "parent_2", Children: []*LateLoadChild{{Name: "child_2_1", Items: []LateLoadItem{{Name: "item_2_1_1"}}}}},
+		{Name: "parent_3"},
+	}
+	if err := DB.Session(&gorm.Session{FullSaveAssociations: true}).Create(&parents).Error; err != nil {
+		t.Fatalf("failed to create parents, got %v", err)
+	}
+
+	if err := DB.Delete(parents[0].Children[2]).Error; err != nil {
+		t.Fatalf("failed to delete child, got %v", err)
+	}
+
+	var results []LateLoadParent
+	if err := DB.Order("id").Find(&results).Error; err != nil {
+		t.Fatalf("failed to find parents, got %v", err)
+	}
+
+	var queries int
+	tx := DB.Session(&gorm.Session{})
+	tx.Callback().Query().After("gorm:query").Register("test:count_late_load_queries", func(db *gorm.DB) {
+		queries++
+	})
+	defer tx.Callback().Query().Remove("test:count_late_load_queries")
+
+	if err := tx.Model(&results).LoadAssociations("Children.Items").Error; err != nil {
+		t.Fatalf("failed to load associations, got %v", err)
+	}
+
+	if queries != 2 {
+		t.Errorf("children and items should be loaded with one query each, got %v queries", queries)
+	}
+
+	AssertEqual(t, len(results[0].Children), 2)
+	AssertEqual(t, len(results[1].Children), 1)
+	AssertEqual(t, len(results[2].Children), 0)
+	for _, child := range append(results[0].Children, results[1].Children...) {
+		if !child.Found {
+			t.Errorf("AfterFind should be called for the loaded children, got %+v", child)
+		}
+	}
+	AssertEqual(t, results[0].Children[0].Items, parents[0].Children[0].Items)
+	AssertEqual(t, results[1].Children[0].Items, parents[1].Children[0].Items)
+
+	if err := DB.Model(&results).LoadAssociations("Children", "name <> ?", "child_1_1").Error; err != nil {
+		t.Fatalf("failed to load associations with conditions, got %v", err)
+	}
+	AssertEqual(t, len(results[0].Children), 1)
+	AssertEqual(t, results[0].Children[0].Name, "child_1_2")
+
+	if err := DB.Model(&results).Unscoped().LoadAssociations("Children", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id desc")
+	}).Error; err != nil {
+		t.Fatalf("failed to load associations, got %v", err)
+	}
+	AssertEqual(t, len(results[0].Children), 3)
+	AssertEqual(t, results[0].Children[0].Name, "child_1_3")
+
+	parent := LateLoadParent{ID: parents[1].ID}
+	if err := DB.Model(&parent).LoadAssociations(clause.Associations).Error; err != nil {
+		t.Fatalf("failed to load associations of the struct, got %v", err)
+	}
+	AssertEqual(t, len(parent.Children), 1)
+
+	if err := DB.Model(parent).LoadAssociations("Children").Error; !errors.Is(err, gorm.ErrInvalidValue) {
+		t.Errorf("should returns ErrInvalidValue if the model isn't a pointer, got %v", err)
+	}
+
+	if err := DB.Model(&results).LoadAssociations("Unknown").Error; err == nil {
+		t.Errorf("should returns error for unknown associations")
+	}
+}
+
+type PlanCountry struct {
+	ID   int
+	Name string
+}
+
+type PlanAddress struct {
+	Name          string
+	PlanCountryID *int
+	PlanCountry   *PlanCountry
+}
+
+type PlanOrg struct {
+	ID            int
+	PostalAddress PlanAddress `gorm:"embedded;embeddedPrefix:postal_"`
+	OwnerID       *int
+	Owner         *User
+}
+
+func TestPreloadPlan(t *testing.T) {
+	describe := func(nodes []*gorm.PreloadNode) (results []string) {
+		var walk func(nodes []*gorm.PreloadNode)
+		walk = func(nodes []*gorm.PreloadNode) {
+			for _, node := range nodes {
+				results = append(results, fmt.Sprintf("%v%v", node.Path, node.Conds))
+				walk(node.Children)
+			}
+		}
+		walk(nodes)
+		return results
+	}
+
+	plan := func(db *gorm.DB) ([]string, error) {
+		stmt := db.Statement
+		nodes, err := stmt.PreloadPlan()
+		return describe(nodes), err
+	}
+
+	results, err := plan(DB.Model(&User{}).Preload("Pets.Toy", "name = ?", "toy").Preload("Pets", "name <> ?", "pet").
+		Preload("Company").Preload("Manager.Pets.Toy"))
+	if err != nil {
+		t.Fatalf("failed to plan preloads, got %v", err)
+	}
+	AssertEqual(t, results, []string{
+		"Company[]", "Manager[]", "Manager.Pets[]", "Manager.Pets.Toy[]", "Pets[name <> ? pet]", "Pets.Toy[name = ? toy]",
+	})
+
+	results, err = plan(DB.Model(&User{}).Preload(clause.Associations, "id > ?", 0).Preload("Pets", "name <> ?", "pet").
+		Preload("Pets.Toy"))
+	if err != nil {
+		t.Fatalf("failed to plan preloads, got %v", err)
+	}
+	AssertEqual(t, results, []string{
+		"Account[id > ? 0]", "Company[id > ? 0]", "Friends[id > ? 0]", "Languages[id > ? 0]", "Manager[id > ? 0]",
+		"NamedPet[id > ? 0]", "Pets[name <> ? pet id > ? 0]", "Pets.Toy[]", "Team[id > ? 0]", "Tools[id > ? 0]", "Toys[id > ? 0]",
+	})
+
+	// the relationships of the embedded structs are preloaded with the conditions of clause.Associations once
+	results, err = plan(DB.Model(&PlanOrg{}).Preload(clause.Associations, "name <> ?", "").Preload("Owner.Pets"))
+	if err != nil {
+		t.Fatalf("failed to plan preloads, got %v", err)
+	}
+	AssertEqual(t, results, []string{"Owner[name <> ? ]", "Owner.Pets[]", "PostalAddress[]", "PostalAddress.PlanCountry[name <> ? ]"})
+
+	results, err = plan(DB.Model(&PlanOrg{}).Preload("PostalAddress.PlanCountry", "id > ?", 0))
+	if err != nil {
+		t.Fatalf("failed to plan preloads, got %v", err)
+	}
+	AssertEqual(t, results, []string{"PostalAddress[]", "PostalAddress.PlanCountry[id > ? 0]"})
+
+	if _, err := plan(DB.Model(&PlanOrg{}).Preload("PostalAddress.PlanCountry", "name = ?", "a").Preload(clause.Associations, "name = ?", "b").
+		Preload("PostalAddress."+clause.Associations, "name = ?", "c")); !errors.Is(err, gorm.ErrPreloadConflict) {
+		t.Errorf("conflicting conditions should return ErrPreloadConflict, got %v", err)
+	}
+
+	if _, err := plan(DB.Model(&User{}).Preload("Pets.Unknown")); !errors.Is(err, gorm.ErrUnsupportedRelation) {
+		t.Errorf("unknown relationships should return ErrUnsupportedRelation, got %v", err)
+	}
+
+	if err := DB.Model(&User{}).Preload("Pets", "name = ?", "a").Preload("Pets", "name = ?", "b").Find(&[]User{}).Error; !errors.Is(err, gorm.ErrPreloadConflict) {
+		t.Errorf("preloading a path again with different conditions should return ErrPreloadConflict, got %v", err)
+	}
+
+	if err := DB.Model(&User{}).Preload("Pets", "name = ?", "a").Preload("Pets", "name = ?", "a").Find(&[]User{}).Error; err != nil {
+		t.Errorf("preloading a path again with the same conditions should be merged, got %v", err)
+	}
+
+	DB.Migrator().DropTable(&PlanOrg{}, &PlanCountry{})
+	if err := DB.AutoMigrate(&PlanCountry{}, &PlanOrg{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	owner := GetUser("preload_plan", Config{Pets: 2})
+	orgs := []PlanOrg{
+		{PostalAddress: PlanAddress{Name: "a1", PlanCountry: &PlanCountry{Name: "c1"}}, Owner: owner},
+		{PostalAddress: PlanAddress{Name: "a2", PlanCountry: &PlanCountry{Name: "c2"}}},
+	}
+	if err := DB.Create(&orgs).Error; err != nil {
+		t.Fatalf("failed to create orgs, got %v", err)
+	}
+
+	var results2 []PlanOrg
+	if err := DB.Preload(clause.Associations, "name <> ?", "c2").Preload("Owner.Pets").Order("id").Find(&results2).Error; err != nil {
+		t.Fatalf("failed to preload, got %v", err)
+	}
+
+	if results2[0].PostalAddress.PlanCountry == nil || results2[0].PostalAddress.PlanCountry.Name != "c1" ||
+		results2[1].PostalAddress.PlanCountry != nil {
+		t.Errorf("embedded relationships should be preloaded with the conditions, got %+v", results2)
+	}
+
+	if results2[0].Owner == nil || len(results2[0].Owner.Pets) != 2 || results2[1].Owner != nil {
+		t.Errorf("nested relationships should be preloaded, got %+v", results2)
+	}
+}
+
+func TestPreloadCheckAssociations(t *testing.T) {
+	if err := DB.Model(&User{}).Preload("Petz").Error; !errors.Is(err, gorm.ErrUnsupportedRelation) || !strings.Contains(err.Error(), "did you mean Pets?") {
+		t.Errorf("unknown relationships should return ErrUnsupportedRelation with the closest names, got %v", err)
+	}
+
+	if err := DB.Model(&User{}).Preload("Pets.Toyz").Error; !errors.Is(err, gorm.ErrUnsupportedRelation) || !strings.Contains(err.Error(), "Pets.Toyz") {
+		t.Errorf("unknown nested relationships should return ErrUnsupportedRelation, got %v", err)
+	}
+
+	if err := DB.Model(&User{}).Preload("Pets.Toy").Preload("Pets." + clause.Associations).Error; err != nil {
+		t.Errorf("known relationships shouldn't return error, got %v", err)
+	}
+
+	// the model is unknown until the statement is executed
+	tx := DB.Preload("Petz")
+	if tx.Error != nil {
+		t.Errorf("the paths shouldn't be checked without model, got %v", tx.Error)
+	}
+
+	if err := tx.Find(&[]User{}).Error; !errors.Is(err, gorm.ErrUnsupportedRelation) {
+		t.Errorf("unknown relationships should return ErrUnsupportedRelation, got %v", err)
+	}
+
+	if err := DB.Preload(clause.Associations).Find(&[]Company{}).Error; err != nil {
+		t.Errorf("clause.Associations without relationships shouldn't return error, got %v", err)
+	}
+
+	strict := DB.Session(&gorm.Session{StrictAssociations: true})
+	if err := strict.Preload(clause.Associations).Find(&[]Company{}).Error; !errors.Is(err, gorm.ErrUnsupportedRelation) {
+		t.Errorf("clause.Associations without relationships should return ErrUnsupportedRelation if strict, got %v", err)
+	}
+
+	user := *GetUser("preload_check_associations", Config{Account: true, Pets: 2})
+	DB.Create(&user)
+
+	var buf bytes.Buffer
+	db := DB.Session(&gorm.Session{Logger: logger.New(log.New(&buf, "", 0), logger.Config{LogLevel: logger.Info})})
+	var result User
+	if err := db.Preload("Pets.Toy").Preload("Account").First(&result, user.ID).Error; err != nil {
+		t.Fatalf("failed to preload, got %v", err)
+	}
+
+	if strings.Count(buf.String(), "preloaded associations:") != 1 || !strings.Contains(buf.String(), "preloaded associations: Account, Pets, Pets.Toy") {
+		t.Errorf("the preloaded associations should be logged once, got %v", buf.String())
+	}
+}
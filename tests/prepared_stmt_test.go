@@ -2,7 +2,9 @@ package tests_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -13,7 +15,7 @@ import (
 )
 
 func TestPreparedStmt(t *testing.T) {
-	tx := DB.Session(&gorm.Session{PrepareStmt: true})
+	tx := DB.Session(&gorm.Session{PrepareStmt: boolPtr(true)})
 
 	if _, ok := tx.ConnPool.(*gorm.PreparedStmtDB); !ok {
 		t.Fatalf("should assign PreparedStatement Manager back to database when using PrepareStmt mode")
@@ -55,7 +57,7 @@ func TestPreparedStmt(t *testing.T) {
 }
 
 func TestPreparedStmtFromTransaction(t *testing.T) {
-	db := DB.Session(&gorm.Session{PrepareStmt: true, SkipDefaultTransaction: true})
+	db := DB.Session(&gorm.Session{PrepareStmt: boolPtr(true), SkipDefaultTransaction: true})
 
 	tx := db.Begin()
 	defer func() {
@@ -99,7 +101,7 @@ func TestPreparedStmtDeadlock(t *testing.T) {
 	sqlDB, _ := tx.DB()
 	sqlDB.SetMaxOpenConns(1)
 
-	tx = tx.Session(&gorm.Session{PrepareStmt: true})
+	tx = tx.Session(&gorm.Session{PrepareStmt: boolPtr(true)})
 
 	wg := sync.WaitGroup{}
 	for i := 0; i < 100; i++ {
@@ -131,7 +133,7 @@ func TestPreparedStmtInTransaction(t *testing.T) {
 	user := User{Name: "jinzhu"}
 
 	if err := DB.Transaction(func(tx *gorm.DB) error {
-		tx.Session(&gorm.Session{PrepareStmt: true}).Create(&user)
+		tx.Session(&gorm.Session{PrepareStmt: boolPtr(true)}).Create(&user)
 		return errors.New("test")
 	}); err == nil {
 		t.Error(err)
@@ -144,7 +146,7 @@ func TestPreparedStmtInTransaction(t *testing.T) {
 }
 
 func TestPreparedStmtReset(t *testing.T) {
-	tx := DB.Session(&gorm.Session{PrepareStmt: true})
+	tx := DB.Session(&gorm.Session{PrepareStmt: boolPtr(true)})
 
 	user := *GetUser("prepared_stmt_reset", Config{})
 	tx = tx.Create(&user)
@@ -314,3 +316,225 @@ func TestPreparedStmtConcurrentClose(t *testing.T) {
 		t.Fatalf("stmts must be nil")
 	}
 }
+
+func TestPreparedStmtMaxSize(t *testing.T) {
+	tx, err := OpenTestConnection(&gorm.Config{PrepareStmt: true, PreparedStmtMaxSize: 2})
+	if err != nil {
+		t.Fatalf("failed to open test connection due to %s", err)
+	}
+	pdb, ok := tx.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		t.Fatalf("should assign PreparedStatement Manager back to database when using PrepareStmt mode")
+	}
+	defer pdb.Close()
+	pdb.Reset()
+
+	var (
+		users  []User
+		before = pdb.Stats()
+	)
+	tx.Where("id IN ?", []int{1}).Find(&users)
+	tx.Where("id IN ?", []int{1, 2}).Find(&users)
+	tx.Where("id IN ?", []int{1}).Find(&users)
+	tx.Where("id IN ?", []int{1, 2, 3}).Find(&users)
+
+	stats := pdb.Stats()
+	AssertEqual(t, stats.Hits-before.Hits, int64(1))
+	AssertEqual(t, stats.Misses-before.Misses, int64(3))
+	AssertEqual(t, stats.Evictions-before.Evictions, int64(1))
+	AssertEqual(t, stats.Size, 2)
+
+	// the least recently used statement is evicted
+	tx.Session(&gorm.Session{}).Where("id IN ?", []int{1}).Find(&users)
+	AssertEqual(t, pdb.Stats().Hits-before.Hits, int64(2))
+	tx.Where("id IN ?", []int{1, 2}).Find(&users)
+	AssertEqual(t, pdb.Stats().Misses-before.Misses, int64(4))
+	AssertEqual(t, pdb.Stats().Size, 2)
+
+	// the cache is shared with PrepareStmt sessions
+	if err := tx.Session(&gorm.Session{PrepareStmt: boolPtr(true)}).Where("id IN ?", []int{1, 2, 3, 4}).Find(&users).Error; err != nil {
+		t.Fatalf("failed to query with prepared stmt session, got %v", err)
+	}
+	AssertEqual(t, pdb.Stats().Misses-before.Misses, int64(5))
+	AssertEqual(t, pdb.Stats().Size, 2)
+}
+
+func TestPreparedStmtSessionOverride(t *testing.T) {
+	tx, err := OpenTestConnection(&gorm.Config{PrepareStmt: true})
+	if err != nil {
+		t.Fatalf("failed to open test connection due to %s", err)
+	}
+	pdb, ok := tx.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		t.Fatalf("should assign PreparedStatement Manager back to database when using PrepareStmt mode")
+	}
+	defer pdb.Close()
+
+	var users []User
+	before := pdb.Stats()
+	unprepared := tx.Session(&gorm.Session{PrepareStmt: boolPtr(false)})
+	if _, ok := unprepared.ConnPool.(*gorm.PreparedStmtDB); ok || unprepared.PrepareStmt {
+		t.Fatalf("the session shouldn't prepare statements")
+	}
+	if err := unprepared.Where("id IN ?", []int{1, 2, 3, 4, 5}).Find(&users).Error; err != nil {
+		t.Fatalf("failed to query without prepared stmt, got %v", err)
+	}
+	AssertEqual(t, pdb.Stats(), before)
+
+	if err := unprepared.Transaction(func(tx *gorm.DB) error {
+		if _, ok := tx.Statement.ConnPool.(*gorm.PreparedStmtTX); ok {
+			t.Errorf("the transaction should inherit the choice of the session")
+		}
+		return tx.Where("id IN ?", []int{1, 2, 3, 4, 5, 6}).Find(&users).Error
+	}); err != nil {
+		t.Fatalf("failed to query in transaction, got %v", err)
+	}
+	AssertEqual(t, pdb.Stats(), before)
+
+	// nil inherits the setting of the DB
+	if _, ok := tx.Session(&gorm.Session{}).ConnPool.(*gorm.PreparedStmtDB); !ok {
+		t.Errorf("the session should inherit PrepareStmt of the DB")
+	}
+	if _, ok := unprepared.Session(&gorm.Session{}).ConnPool.(*gorm.PreparedStmtDB); ok {
+		t.Errorf("the session should inherit PrepareStmt of the parent session")
+	}
+
+	// enabled per session, the statements are shared with the cache of the DB
+	prepared := unprepared.Session(&gorm.Session{PrepareStmt: boolPtr(true)})
+	if err := prepared.Where("id IN ?", []int{1, 2, 3, 4, 5, 6, 7}).Find(&users).Error; err != nil {
+		t.Fatalf("failed to query with prepared stmt, got %v", err)
+	}
+	AssertEqual(t, pdb.Stats().Misses-before.Misses, int64(1))
+	if err := tx.Where("id IN ?", []int{1, 2, 3, 4, 5, 6, 7}).Find(&users).Error; err != nil {
+		t.Fatalf("failed to query with prepared stmt, got %v", err)
+	}
+	AssertEqual(t, pdb.Stats().Hits-before.Hits, int64(1))
+
+	if err := prepared.Transaction(func(tx *gorm.DB) error {
+		if _, ok := tx.Statement.ConnPool.(*gorm.PreparedStmtTX); !ok {
+			t.Errorf("the transaction should inherit the choice of the session")
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to run transaction, got %v", err)
+	}
+}
+
+func TestPreparedStmtTTL(t *testing.T) {
+	tx, err := OpenTestConnection(&gorm.Config{PrepareStmt: true, PreparedStmtTTL: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("failed to open test connection due to %s", err)
+	}
+	pdb, ok := tx.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		t.Fatalf("should assign PreparedStatement Manager back to database when using PrepareStmt mode")
+	}
+	defer pdb.Close()
+	pdb.Reset()
+
+	var users []User
+	if err := tx.Where("name = ?", "prepared_stmt_ttl").Find(&users).Error; err != nil {
+		t.Fatalf("failed to query, got %v", err)
+	}
+
+	if pdb.Stats().Size != 1 {
+		t.Fatalf("statement should be cached, got %+v", pdb.Stats())
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if stats := pdb.Stats(); stats.Size != 0 || stats.Evictions != 1 {
+		t.Fatalf("expired statement should be evicted, got %+v", stats)
+	}
+
+	if err := tx.Where("name = ?", "prepared_stmt_ttl").Find(&users).Error; err != nil {
+		t.Fatalf("failed to query after eviction, got %v", err)
+	}
+}
+
+type recordPreparePool struct {
+	*sql.DB
+	mux      sync.Mutex
+	prepared []string
+}
+
+func (pool *recordPreparePool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	pool.mux.Lock()
+	pool.prepared = append(pool.prepared, query)
+	pool.mux.Unlock()
+	return pool.DB.PrepareContext(ctx, query)
+}
+
+func (pool *recordPreparePool) preparedWith(prefix string) (count int) {
+	pool.mux.Lock()
+	defer pool.mux.Unlock()
+	for _, query := range pool.prepared {
+		if strings.HasPrefix(query, prefix) {
+			count++
+		}
+	}
+	return
+}
+
+func TestPreparedStmtPartitionedByConnPool(t *testing.T) {
+	db, err := OpenTestConnection(&gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open test connection due to %s", err)
+	}
+	replicaDB, err := OpenTestConnection(&gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test connection due to %s", err)
+	}
+
+	sqlDB, _ := db.DB()
+	replicaSQLDB, _ := replicaDB.DB()
+	var (
+		primary = &recordPreparePool{DB: sqlDB}
+		replica = &recordPreparePool{DB: replicaSQLDB}
+		pdb     = gorm.NewPreparedStmtDB(primary)
+	)
+	db.Config.ConnPool = pdb
+	db.Statement.ConnPool = pdb
+
+	// resolve queries to the replica like a read/write splitting plugin
+	db.Callback().Query().Before("gorm:query").Register("test:resolver", func(tx *gorm.DB) {
+		if connPool, ok := tx.Statement.ConnPool.(*gorm.PreparedStmtDB); ok {
+			tx.Statement.ConnPool = connPool.Partition(replica)
+		}
+	})
+
+	user := *GetUser("prepared_stmt_partition", Config{})
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		var result User
+		if err := db.First(&result, user.ID).Error; err != nil {
+			t.Fatalf("failed to query user, got %v", err)
+		}
+	}
+
+	if primary.preparedWith("SELECT") != 0 || primary.preparedWith("INSERT") != 1 {
+		t.Errorf("statements should be prepared on the primary pool, got %v", primary.prepared)
+	}
+
+	if replica.preparedWith("SELECT") != 1 || replica.preparedWith("INSERT") != 0 {
+		t.Errorf("statements should be prepared on the replica pool, got %v", replica.prepared)
+	}
+
+	partition := pdb.Partition(replica)
+	if partition == pdb || pdb.Partition(primary) != pdb || pdb.Partition(replica) != partition {
+		t.Fatalf("partition should be cached by ConnPool")
+	}
+	AssertEqual(t, pdb.Stats().Size, 1)
+	AssertEqual(t, partition.Stats().Size, 1)
+
+	partition.Reset()
+	AssertEqual(t, partition.Stats().Size, 0)
+	AssertEqual(t, pdb.Stats().Size, 1)
+
+	pdb.Close()
+	if pdb.Stmts != nil || partition.Stmts != nil {
+		t.Errorf("statements of all partitions should be released after close")
+	}
+}
@@ -0,0 +1,73 @@
+package tests_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type queryHookKey struct{}
+
+type recordingQueryHook struct {
+	name   string
+	events *[]string
+}
+
+func (h recordingQueryHook) Before(ctx context.Context, stmt *gorm.Statement) context.Context {
+	*h.events = append(*h.events, h.name+":before:"+stmt.Operation())
+	return context.WithValue(ctx, queryHookKey{}, h.name)
+}
+
+func (h recordingQueryHook) After(ctx context.Context, stmt *gorm.Statement, err error) {
+	*h.events = append(*h.events, fmt.Sprintf("%s:after:%s:%s:%d:%v:%v", h.name, stmt.Operation(), stmt.Table, len(stmt.Vars), ctx.Value(queryHookKey{}), err != nil))
+	if stmt.Operation() != gorm.OperationBegin && stmt.Operation() != gorm.OperationCommit && stmt.Operation() != gorm.OperationRollback && stmt.SQL.Len() == 0 {
+		*h.events = append(*h.events, "missing sql")
+	}
+}
+
+func TestQueryHooks(t *testing.T) {
+	var events []string
+	db := DB.Session(&gorm.Session{})
+	db.Config.QueryHooks = []gorm.QueryHook{recordingQueryHook{name: "h1", events: &events}, recordingQueryHook{name: "h2", events: &events}}
+
+	user := *GetUser("query-hooks", Config{})
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+	AssertEqual(t, events, []string{
+		"h1:before:begin", "h2:before:begin", "h2:after:begin:users:0:h2:false", "h1:after:begin:users:0:h2:false",
+		"h1:before:query", "h2:before:query", "h2:after:query:users:9:h2:false", "h1:after:query:users:9:h2:false",
+		"h1:before:commit", "h2:before:commit", "h2:after:commit:users:9:h2:false", "h1:after:commit:users:9:h2:false",
+	})
+
+	events = nil
+	var result User
+	db.First(&result, user.ID)
+	AssertEqual(t, events, []string{"h1:before:query", "h2:before:query", "h2:after:query:users:1:h2:false", "h1:after:query:users:1:h2:false"})
+
+	events = nil
+	db.Model(&User{}).Where("id = ?", user.ID).Select("name").Row()
+	db.Exec("UPDATE users SET age = ? WHERE id = ?", 18, user.ID)
+	AssertEqual(t, events, []string{
+		"h1:before:row", "h2:before:row", "h2:after:row:users:1:h2:false", "h1:after:row:users:1:h2:false",
+		"h1:before:exec", "h2:before:exec", "h2:after:exec::2:h2:false", "h1:after:exec::2:h2:false",
+	})
+
+	events = nil
+	db.Transaction(func(tx *gorm.DB) error {
+		tx.Exec("SELECT * FROM non_existing_table")
+		return errors.New("rollback")
+	})
+	if len(events) != 12 || !strings.HasPrefix(events[6], "h2:after:exec") || !strings.HasSuffix(events[6], ":true") || events[8] != "h1:before:rollback" {
+		t.Errorf("expected the failed exec and rollback recorded, got %v", events)
+	}
+
+	events = nil
+	DB.First(&result, user.ID)
+	AssertEqual(t, len(events), 0)
+}
@@ -1,9 +1,13 @@
 package tests_test
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"fmt"
+	"log"
 	"reflect"
 	"regexp"
 	"sort"
@@ -14,6 +18,7 @@ import (
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -1165,6 +1170,66 @@ func TestSearchWithStruct(t *testing.T) {
 	}
 }
 
+type CondReview struct {
+	Reviewer string
+}
+
+type CondOrder struct {
+	ID     uint
+	Status int
+	Title  string     `gorm:"column:order_title"`
+	Review CondReview `gorm:"embedded;embeddedPrefix:review_"`
+}
+
+func TestSearchWithStructCondition(t *testing.T) {
+	DB.Migrator().DropTable(&CondOrder{})
+	if err := DB.AutoMigrate(&CondOrder{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	orders := []CondOrder{
+		{Status: 0, Title: "draft order", Review: CondReview{Reviewer: "jinzhu"}},
+		{Status: 1, Title: "paid order", Review: CondReview{Reviewer: "jinzhu"}},
+		{Status: 2, Title: "shipped order"},
+	}
+	if err := DB.Create(&orders).Error; err != nil {
+		t.Fatalf("failed to create orders, got %v", err)
+	}
+
+	var results []CondOrder
+	if err := DB.Where(gorm.Cond(&CondOrder{Status: 0}, gorm.IncludeZero("Status"))).Find(&results).Error; err != nil {
+		t.Fatalf("failed to find orders, got %v", err)
+	}
+	AssertEqual(t, results, orders[:1])
+
+	filter := CondOrder{Title: "%order", Review: CondReview{Reviewer: "jinzhu"}, Status: 1}
+	if err := DB.Where(gorm.Cond(&filter, gorm.CondOperator("order_title", "LIKE"), gorm.CondOperator("Status", "<="))).
+		Order("id").Find(&results).Error; err != nil {
+		t.Fatalf("failed to find orders, got %v", err)
+	}
+	AssertEqual(t, results, orders[:2])
+
+	if err := DB.Where(gorm.Cond(&filter, gorm.ExcludeFields("Status", "Review.Reviewer"), gorm.CondOperator("Title", "LIKE"))).
+		Not(gorm.Cond(CondOrder{Status: 1})).Order("id").Find(&results).Error; err != nil {
+		t.Fatalf("failed to find orders, got %v", err)
+	}
+	AssertEqual(t, results, []CondOrder{orders[0], orders[2]})
+
+	dryRunDB := DB.Session(&gorm.Session{DryRun: true})
+	stmt := dryRunDB.Where(gorm.Cond(CondOrder{Title: "paid order"}, gorm.IncludeZero("review_reviewer"))).Find(&results).Statement
+	if !regexp.MustCompile(`WHERE .cond_orders.\..order_title. = .{1,3} AND .cond_orders.\..review_reviewer. = .{1,3}$`).MatchString(stmt.SQL.String()) {
+		t.Errorf("invalid query SQL, got %v", stmt.SQL.String())
+	}
+
+	if err := dryRunDB.Where(gorm.Cond(CondOrder{}, gorm.IncludeZero("Unknown"))).Find(&results).Error; !errors.Is(err, gorm.ErrInvalidField) {
+		t.Errorf("should returns ErrInvalidField for unknown fields, got %v", err)
+	}
+
+	if err := dryRunDB.Where(gorm.Cond(CondOrder{Status: 1}, gorm.CondOperator("Status", "BETWEEN"))).Find(&results).Error; !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("should returns ErrInvalidData for unsupported operators, got %v", err)
+	}
+}
+
 func TestSubQuery(t *testing.T) {
 	users := []User{
 		{Name: "subquery_1", Age: 10},
@@ -1453,3 +1518,438 @@ func TestQueryScanToArray(t *testing.T) {
 		t.Error("users[1] should be empty")
 	}
 }
+
+func TestTryFirst(t *testing.T) {
+	users := []User{*GetUser("try_first", Config{}), *GetUser("try_first", Config{})}
+	DB.Create(&users)
+
+	var user User
+	if found, err := DB.TryFirst(&user, "name = ?", "try_first"); !found || err != nil {
+		t.Fatalf("expected found, got %v, error %v", found, err)
+	}
+	AssertEqual(t, user.ID, users[0].ID)
+
+	user = User{}
+	if found, err := DB.Where("name = ?", "try_first").TryLast(&user); !found || err != nil {
+		t.Fatalf("expected found, got %v, error %v", found, err)
+	}
+	AssertEqual(t, user.ID, users[1].ID)
+
+	user = User{}
+	if found, err := DB.TryTake(&user, users[0].ID); !found || err != nil {
+		t.Fatalf("expected found, got %v, error %v", found, err)
+	}
+	AssertEqual(t, user.ID, users[0].ID)
+
+	buf := &bytes.Buffer{}
+	db := DB.Session(&gorm.Session{Logger: logger.New(log.New(buf, "", 0), logger.Config{LogLevel: logger.Error})})
+	tx := db.Where("name = ?", "try_first_not_exist")
+	for _, try := range []func(dest interface{}, conds ...interface{}) (bool, error){tx.TryFirst, tx.TryTake, tx.TryLast} {
+		if found, err := try(&User{}); found || err != nil {
+			t.Errorf("expected not found without error, got %v, error %v", found, err)
+		}
+	}
+	AssertEqual(t, tx.Error, nil)
+	AssertEqual(t, tx.RowsAffected, int64(0))
+	if strings.Contains(buf.String(), "record not found") {
+		t.Errorf("should not log record not found, got %v", buf.String())
+	}
+
+	// the chain isn't poisoned
+	var count int64
+	if err := tx.Model(&User{}).Count(&count).Error; err != nil || count != 0 {
+		t.Errorf("expected chained count without error, got %v, error %v", count, err)
+	}
+
+	tx = DB.Where("name = ?", "try_first")
+	if found, _ := tx.TryFirst(&User{}); !found {
+		t.Errorf("expected found")
+	}
+	AssertEqual(t, tx.RowsAffected, int64(1))
+
+	if _, err := DB.Table("not_exist_table").TryFirst(&User{}); err == nil {
+		t.Errorf("expected error for missing table")
+	}
+}
+
+func TestFirstBy(t *testing.T) {
+	users := []*User{GetUser("first_by_1", Config{}), GetUser("first_by_2", Config{}), GetUser("first_by_3", Config{})}
+	users[0].Age, users[1].Age, users[2].Age = 30, 10, 20
+	DB.Create(&users)
+	ids := []uint{users[0].ID, users[1].ID, users[2].ID}
+
+	var first, last User
+	if err := DB.Where("id IN ?", ids).FirstBy("Age", &first).Error; err != nil || first.Name != "first_by_2" {
+		t.Errorf("should find the user of the min age, got %v, error %v", first.Name, err)
+	}
+	if err := DB.Where("id IN ?", ids).LastBy("age", &last).Error; err != nil || last.Name != "first_by_1" {
+		t.Errorf("should find the user of the max age, got %v, error %v", last.Name, err)
+	}
+
+	var user User
+	if err := DB.LastBy("age", &user, "id IN ? AND age < ?", ids, 30).Error; err != nil || user.Name != "first_by_3" {
+		t.Errorf("should find the user by the conditions, got %v, error %v", user.Name, err)
+	}
+
+	if err := DB.FirstBy("age", &User{}, "id = ?", 0).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("should return ErrRecordNotFound, got %v", err)
+	}
+
+	if err := DB.FirstBy("age; DROP TABLE users", &User{}).Error; !errors.Is(err, gorm.ErrInvalidField) {
+		t.Errorf("should return ErrInvalidField for the invalid column, got %v", err)
+	}
+
+	result := DB.Session(&gorm.Session{DryRun: true}).LastBy("CreatedAt", &User{})
+	if sql := result.Statement.SQL.String(); !regexp.MustCompile(`ORDER BY .users.\..created_at. DESC,.users.\..id. DESC LIMIT`).MatchString(sql) {
+		t.Errorf("invalid last by sql, got %v", sql)
+	}
+}
+
+func TestSelectForType(t *testing.T) {
+	company := Company{Name: "select_for_type_company"}
+	DB.Create(&company)
+
+	user := *GetUser("select_for_type", Config{})
+	user.CompanyID = &company.ID
+	DB.Create(&user)
+
+	type UserSummary struct {
+		ID       uint
+		UserName string `gorm:"column:name"`
+		Age      uint
+	}
+
+	var summaries []UserSummary
+	if err := DB.Model(&User{}).SelectForType(&UserSummary{}).Where("name = ?", user.Name).Find(&summaries).Error; err != nil {
+		t.Fatalf("failed to select for type, got error %v", err)
+	}
+
+	if len(summaries) != 1 || summaries[0].ID != user.ID || summaries[0].UserName != user.Name || summaries[0].Age != user.Age {
+		t.Errorf("unexpected summaries %+v", summaries)
+	}
+
+	var count int64
+	if err := DB.Model(&User{}).SelectForType(&UserSummary{}).Where("name = ?", user.Name).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("failed to count, got count %v, error %v", count, err)
+	}
+
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	stmt := dryDB.Model(&User{}).SelectForType(&UserSummary{}).Find(&[]UserSummary{}).Statement
+	if !regexp.MustCompile("SELECT .users.\\..id.,.users.\\..name.,.users.\\..age. FROM .users.").MatchString(stmt.SQL.String()) {
+		t.Errorf("unexpected select, got %v", stmt.SQL.String())
+	}
+
+	type UserCompany struct {
+		Name        string
+		CompanyName string `gorm:"column:Company.name"`
+	}
+
+	var userCompany UserCompany
+	if err := DB.Model(&User{}).Joins("Company").SelectForType(&UserCompany{}).Where("users.id = ?", user.ID).Take(&userCompany).Error; err != nil {
+		t.Fatalf("failed to select joined columns, got error %v", err)
+	}
+
+	if userCompany.Name != user.Name || userCompany.CompanyName != company.Name {
+		t.Errorf("unexpected user company %+v", userCompany)
+	}
+
+	type UnknownField struct {
+		Name     string
+		Nickname string
+	}
+
+	if err := DB.Model(&User{}).SelectForType(&UnknownField{}).Find(&[]UnknownField{}).Error; !errors.Is(err, gorm.ErrInvalidField) {
+		t.Errorf("should return ErrInvalidField for unknown fields, got %v", err)
+	}
+
+	type UnknownJoinedField struct {
+		Name    string
+		Address string `gorm:"column:Company.address"`
+	}
+
+	if err := DB.Model(&User{}).Joins("Company").SelectForType(&UnknownJoinedField{}).Find(&[]UnknownJoinedField{}).Error; !errors.Is(err, gorm.ErrInvalidField) {
+		t.Errorf("should return ErrInvalidField for unknown joined fields, got %v", err)
+	}
+
+	if err := DB.SelectForType(&UserSummary{}).Find(&[]UserSummary{}).Error; !errors.Is(err, gorm.ErrModelValueRequired) {
+		t.Errorf("should return ErrModelValueRequired without model, got %v", err)
+	}
+}
+
+type CollatedAccount struct {
+	ID    uint
+	Email string `gorm:"queryCollate:NOCASE"`
+	Name  string
+}
+
+func TestQueryCollate(t *testing.T) {
+	if DB.Dialector.Name() != "sqlite" {
+		t.Skip("the collations differ between dialects")
+	}
+
+	DB.Migrator().DropTable(&CollatedAccount{})
+	if err := DB.AutoMigrate(&CollatedAccount{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	DB.Create(&[]CollatedAccount{{Email: "b@example.com", Name: "b"}, {Email: "A@example.com", Name: "a"}, {Email: "c@example.com", Name: "C"}})
+
+	var account CollatedAccount
+	if err := DB.Where(&CollatedAccount{Email: "a@EXAMPLE.com"}).First(&account).Error; err != nil || account.Name != "a" {
+		t.Errorf("struct conditions should be compared with the collation, got %+v, error %v", account, err)
+	}
+
+	account = CollatedAccount{}
+	if err := DB.Model(&CollatedAccount{}).Where(map[string]interface{}{"email": "B@example.com"}).First(&account).Error; err != nil || account.Name != "b" {
+		t.Errorf("map conditions should be compared with the collation, got %+v, error %v", account, err)
+	}
+
+	account = CollatedAccount{}
+	if err := DB.Where(gorm.Cond(&CollatedAccount{Email: "c@%"}, gorm.CondOperator("Email", "LIKE"))).First(&account).Error; err != nil || account.Name != "C" {
+		t.Errorf("like conditions should be compared with the collation, got %+v, error %v", account, err)
+	}
+
+	var accounts []CollatedAccount
+	DB.Where(&CollatedAccount{Name: "c"}).Find(&accounts)
+	AssertEqual(t, len(accounts), 0)
+
+	DB.Order("email DESC").Find(&accounts)
+	AssertEqual(t, []string{accounts[0].Name, accounts[1].Name, accounts[2].Name}, []string{"C", "b", "a"})
+
+	DB.Order(clause.Collate{Column: clause.Column{Name: "name"}, Collation: "NOCASE"}).Find(&accounts)
+	AssertEqual(t, []string{accounts[0].Name, accounts[1].Name, accounts[2].Name}, []string{"a", "b", "C"})
+
+	sql := DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where(&CollatedAccount{Email: "a@example.com"}).Order("email").Order("name").Find(&accounts)
+	})
+	if !regexp.MustCompile("WHERE .collated_accounts.\\..email. COLLATE .NOCASE. = \"a@example.com\" ORDER BY .email. COLLATE .NOCASE.,name$").MatchString(sql) {
+		t.Errorf("the collation should be applied to the conditions and orders, got %v", sql)
+	}
+}
+
+func TestQueryNullSafe(t *testing.T) {
+	users := []User{*GetUser("null_safe_1", Config{}), *GetUser("null_safe_2", Config{})}
+	users[1].Age = 0
+	DB.Create(&users)
+	DB.Model(&users[1]).Update("age", nil)
+
+	var results []User
+	if err := DB.Where(clause.EqNullSafe{Column: "age", Value: nil}).Where("name LIKE ?", "null_safe_%").Find(&results).Error; err != nil || len(results) != 1 || results[0].Name != "null_safe_2" {
+		t.Errorf("failed to query with EqNullSafe nil, got %v, error %v", len(results), err)
+	}
+
+	results = nil
+	DB.Where(clause.EqNullSafe{Column: "age", Value: users[0].Age}).Where("name LIKE ?", "null_safe_%").Find(&results)
+	if len(results) != 1 || results[0].Name != "null_safe_1" {
+		t.Errorf("failed to query with EqNullSafe, got %+v", results)
+	}
+
+	results = nil
+	DB.Where(clause.NeqNullSafe{Column: "age", Value: users[0].Age}).Where("name LIKE ?", "null_safe_%").Find(&results)
+	if len(results) != 1 || results[0].Name != "null_safe_2" {
+		t.Errorf("failed to query with NeqNullSafe, got %+v", results)
+	}
+
+	results = nil
+	DB.Session(&gorm.Session{NilAsNull: true}).Where("age = @age AND name LIKE @name", map[string]interface{}{"age": nil, "name": "null_safe_%"}).Find(&results)
+	if len(results) != 1 || results[0].Name != "null_safe_2" {
+		t.Errorf("failed to query the nil named arguments with NilAsNull, got %+v", results)
+	}
+
+	var count int64
+	DB.Model(&User{}).Where("age = @age AND name LIKE @name", map[string]interface{}{"age": nil, "name": "null_safe_%"}).Count(&count)
+	AssertEqual(t, count, 0)
+}
+
+func TestFindToChannel(t *testing.T) {
+	users := []User{*GetUser("find_to_channel_1", Config{}), *GetUser("find_to_channel_2", Config{}), *GetUser("find_to_channel_3", Config{})}
+	DB.Create(&users)
+
+	ch := make(chan User)
+	errs := make(chan error, 1)
+	go DB.Where("id IN ?", []uint{users[0].ID, users[1].ID, users[2].ID}).Order("id").FindToChannel(ch, gorm.ChannelErrors(errs))
+
+	var names []string
+	for user := range ch {
+		names = append(names, user.Name)
+	}
+	AssertEqual(t, names, []string{"find_to_channel_1", "find_to_channel_2", "find_to_channel_3"})
+	if len(errs) != 0 {
+		t.Errorf("should find without error, got %v", <-errs)
+	}
+
+	t.Run("AfterFind", func(t *testing.T) {
+		DB.Migrator().DropTable(&Product{})
+		DB.AutoMigrate(&Product{})
+		DB.Create(&[]Product{{Name: "find_to_channel_1"}, {Name: "find_to_channel_2"}})
+
+		ch := make(chan *Product, 2)
+		if tx := DB.FindToChannel(ch); tx.Error != nil || tx.RowsAffected != 2 {
+			t.Fatalf("failed to find to channel, got %v, error %v", tx.RowsAffected, tx.Error)
+		}
+		for product := range ch {
+			AssertEqual(t, product.AfterFindCallTimes, int64(1))
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := make(chan *User)
+		errs := make(chan error, 1)
+		go DB.WithContext(ctx).Where("id IN ?", []uint{users[0].ID, users[1].ID, users[2].ID}).FindToChannel(ch, gorm.ChannelErrors(errs))
+
+		<-ch
+		cancel()
+		for range ch {
+		}
+
+		var interrupted *gorm.InterruptedError
+		if err := <-errs; !errors.As(err, &interrupted) || !errors.Is(err, context.Canceled) {
+			t.Errorf("should return InterruptedError, got %v", err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		ch := make(chan int, 1)
+		if err := DB.Model(&User{}).FindToChannel(ch).Error; !errors.Is(err, gorm.ErrInvalidData) {
+			t.Errorf("should return ErrInvalidData for the channel of int, got %v", err)
+		}
+		if _, ok := <-ch; ok {
+			t.Errorf("the channel should be closed")
+		}
+
+		if err := DB.FindToChannel(make(<-chan User)).Error; !errors.Is(err, gorm.ErrInvalidData) {
+			t.Errorf("should return ErrInvalidData for the receive only channel, got %v", err)
+		}
+	})
+}
+
+func TestQueryEmptyIN(t *testing.T) {
+	users := []User{*GetUser("empty_in_1", Config{}), *GetUser("empty_in_2", Config{})}
+	DB.Create(&users)
+
+	var buf bytes.Buffer
+	tx := DB.Session(&gorm.Session{Logger: logger.New(log.New(&buf, "", 0), logger.Config{LogLevel: logger.Info})})
+
+	var empty []int
+	tests := []struct {
+		name  string
+		query func(tx *gorm.DB) *gorm.DB
+	}{
+		{"raw", func(tx *gorm.DB) *gorm.DB {
+			return tx.Where("name IN ?", []string{}).Find(&[]User{})
+		}},
+		{"raw with parentheses", func(tx *gorm.DB) *gorm.DB {
+			return tx.Where("id = ? AND users.name in (?)", users[0].ID, []string{}).Find(&[]User{})
+		}},
+		{"clause", func(tx *gorm.DB) *gorm.DB {
+			return tx.Where(clause.IN{Column: clause.Column{Name: "name"}}).Find(&[]User{})
+		}},
+		{"map", func(tx *gorm.DB) *gorm.DB {
+			return tx.Where(map[string]interface{}{"name": []string{}}).Find(&[]User{})
+		}},
+		{"primary keys", func(tx *gorm.DB) *gorm.DB {
+			return tx.Find(&[]User{}, empty)
+		}},
+		{"subquery", func(tx *gorm.DB) *gorm.DB {
+			return tx.Where("id IN (?)", tx.Model(&User{}).Select("id").Where("name IN ?", []string{})).Find(&[]User{})
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf.Reset()
+			result := test.query(tx)
+			if result.Error != nil || result.RowsAffected != 0 {
+				t.Errorf("empty IN should match nothing, got %v rows, error %v", result.RowsAffected, result.Error)
+			}
+			if !strings.Contains(buf.String(), "IN condition without values") {
+				t.Errorf("empty IN should be logged, got %v", buf.String())
+			}
+
+			strict := DB.Session(&gorm.Session{})
+			strict.Config.ErrorOnEmptyIN = true
+			if err := test.query(strict).Error; !errors.Is(err, gorm.ErrEmptyInCondition) {
+				t.Errorf("should return ErrEmptyInCondition, got %v", err)
+			}
+		})
+	}
+
+	result := DB.Session(&gorm.Session{DryRun: true}).Find(&[]User{}, []int{})
+	if sql := result.Statement.SQL.String(); !regexp.MustCompile(`WHERE 1 = 0 AND .users.\..deleted_at. IS NULL`).MatchString(sql) {
+		t.Errorf("empty primary keys should match nothing, got %v", sql)
+	}
+
+	names := []string{"empty_in_1", "empty_in_2"}
+	notINs := map[string]*gorm.DB{
+		"raw":    DB.Where("name NOT IN ?", []string{}),
+		"map":    DB.Not(map[string]interface{}{"name": []string{}}),
+		"clause": DB.Where(clause.Neq{Column: "name", Value: []string{}}),
+	}
+	for name, notIN := range notINs {
+		var count int64
+		notIN.Model(&User{}).Where("name IN ?", names).Count(&count)
+		if count != 2 {
+			t.Errorf("%v NOT IN without values should match all the rows, got %v", name, count)
+		}
+	}
+
+	dryRun := DB.Session(&gorm.Session{DryRun: true})
+	if sql := dryRun.Where("name IN ?", []string{}).Find(&[]User{}).Statement.SQL.String(); !strings.Contains(sql, "WHERE 1 = 0") {
+		t.Errorf("raw IN without values should be written as 1 = 0, got %v", sql)
+	}
+
+	if sql := dryRun.Where("name NOT IN ?", []string{}).Find(&[]User{}).Statement.SQL.String(); !strings.Contains(sql, "WHERE name IS NOT NULL") {
+		t.Errorf("raw NOT IN without values should be written as IS NOT NULL, got %v", sql)
+	}
+}
+
+func TestFindReuseDest(t *testing.T) {
+	users := []User{*GetUser("reuse_dest_1", Config{}), *GetUser("reuse_dest_2", Config{}), *GetUser("reuse_dest_3", Config{})}
+	DB.Create(&users)
+
+	db := DB.Session(&gorm.Session{ReuseDest: true}).Where("name LIKE ?", "reuse_dest_%").Order("id")
+
+	results := make([]User, 0, 10)
+	if err := db.Find(&results).Error; err != nil {
+		t.Fatalf("failed to find, got %v", err)
+	}
+	first := &results[0]
+	results[0].Age, results[0].Pets = 100, []*Pet{{Name: "stale"}}
+
+	if err := db.Find(&results).Error; err != nil || len(results) != 3 {
+		t.Fatalf("failed to find, got %v, %v", len(results), err)
+	}
+
+	if &results[0] != first {
+		t.Errorf("the backing array should be reused")
+	}
+
+	for idx, user := range results {
+		if user.ID != users[idx].ID || user.Age != users[idx].Age || user.Pets != nil {
+			t.Errorf("the elements should be zeroed and scanned again, got %+v", user)
+		}
+	}
+
+	pointers := make([]*User, 0, 10)
+	if err := db.Find(&pointers).Error; err != nil {
+		t.Fatalf("failed to find, got %v", err)
+	}
+	pointer := pointers[1]
+	pointer.Age = 100
+
+	if err := db.Find(&pointers).Error; err != nil || len(pointers) != 3 {
+		t.Fatalf("failed to find, got %v, %v", len(pointers), err)
+	}
+
+	if pointers[1] != pointer || pointer.Age != users[1].Age {
+		t.Errorf("the pointed structs should be reused and scanned again, got %+v", pointers[1])
+	}
+
+	if err := DB.Where("name LIKE ?", "reuse_dest_%").Order("id").Find(&pointers).Error; err != nil {
+		t.Fatalf("failed to find, got %v", err)
+	}
+
+	if pointers[1] == pointer {
+		t.Errorf("the pointed structs shouldn't be reused by default")
+	}
+}
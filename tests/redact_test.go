@@ -0,0 +1,192 @@
+package tests_test
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type RedactCustomer struct {
+	ID           uint
+	Name         string
+	Email        string        `gorm:"redact:email"`
+	Card         *string       `gorm:"redact:card"`
+	Phone        string        `gorm:"redact:phone"`
+	EmailInHook  string        `gorm:"-"`
+	UnknownField string        `gorm:"redact:unknown"`
+	Redacted     gorm.Redacted `gorm:"-"`
+}
+
+func (c *RedactCustomer) AfterFind(tx *gorm.DB) error {
+	c.EmailInHook = c.Email
+	return nil
+}
+
+func redactPhone(value interface{}) interface{} {
+	if phone, _ := value.(string); len(phone) > 4 {
+		return "***" + phone[len(phone)-4:]
+	}
+	return "***"
+}
+
+func TestRedact(t *testing.T) {
+	gorm.RegisterRedactor("phone", redactPhone)
+
+	DB.Migrator().DropTable(&RedactCustomer{})
+	if err := DB.AutoMigrate(&RedactCustomer{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	card := "4242424242424242"
+	customers := []RedactCustomer{
+		{Name: "redact_1", Email: "jinzhu@example.com", Card: &card, Phone: "+1 555 0100"},
+		{Name: "redact_2", Email: "invalid", Phone: "+1 555 0101"},
+	}
+	DB.Omit("UnknownField").Create(&customers)
+
+	var found RedactCustomer
+	if err := DB.First(&found, customers[0].ID).Error; err != nil || found.Email != "jinzhu@example.com" {
+		t.Fatalf("the fields shouldn't be redacted without Redact, got %+v, error %v", found, err)
+	}
+
+	tx := DB.Session(&gorm.Session{Redact: true})
+
+	var results []RedactCustomer
+	if err := tx.Order("id").Find(&results).Error; err != nil {
+		t.Fatalf("failed to find, got error %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("customers should be found, got %+v", results)
+	}
+
+	if results[0].Email != "j***@example.com" || results[0].Card == nil || *results[0].Card != "************4242" || results[0].Phone != "***0100" {
+		t.Errorf("the fields should be redacted, got %+v", results[0])
+	}
+
+	if results[0].EmailInHook != "j***@example.com" {
+		t.Errorf("AfterFind should see the redacted value, got %v", results[0].EmailInHook)
+	}
+
+	if results[1].Email != "***" || results[1].Card != nil || results[1].Name != "redact_2" {
+		t.Errorf("NULL shouldn't be redacted, got %+v", results[1])
+	}
+
+	results[0].Name = "redact_1_new"
+	if err := tx.Save(&results[0]).Error; !errors.Is(err, gorm.ErrRedactedModel) {
+		t.Errorf("should return ErrRedactedModel when saving the redacted model, got %v", err)
+	}
+
+	if err := tx.Save(&results).Error; !errors.Is(err, gorm.ErrRedactedModel) {
+		t.Errorf("should return ErrRedactedModel when saving the redacted models, got %v", err)
+	}
+
+	if err := DB.Save(&results[0]).Error; !errors.Is(err, gorm.ErrRedactedModel) {
+		t.Errorf("should return ErrRedactedModel when saving the redacted model without Redact, got %v", err)
+	}
+
+	if err := DB.Model(&results[0]).Updates(&results[0]).Error; !errors.Is(err, gorm.ErrRedactedModel) {
+		t.Errorf("should return ErrRedactedModel when updating with the redacted model, got %v", err)
+	}
+
+	results[1].Email, results[1].Phone = "redact_2@example.com", "+1 555 0102"
+	if err := DB.Save(&results[1]).Error; err != nil {
+		t.Errorf("the model no longer holding the redacted values should be saved, got %v", err)
+	}
+
+	if err := DB.First(&results[0], customers[0].ID).Error; err != nil || results[0].Email != "jinzhu@example.com" {
+		t.Fatalf("the fields shouldn't be redacted when reloaded without Redact, got %+v, error %v", results[0], err)
+	}
+
+	if err := DB.Save(&results[0]).Error; err != nil {
+		t.Errorf("the reloaded model should be saved, got %v", err)
+	}
+
+	var redacted RedactCustomer
+	loadTx := tx.First(&redacted, customers[0].ID)
+	if v, ok := loadTx.Get("gorm:redacted"); !ok || v != true {
+		t.Errorf("the redacted load should be recorded in the statement, got %v", v)
+	}
+
+	if err := loadTx.Save(&RedactCustomer{ID: customers[0].ID, Name: "overwritten"}).Error; !errors.Is(err, gorm.ErrRedactedModel) {
+		t.Errorf("should return ErrRedactedModel when saving with the statement loading the redacted model, got %v", err)
+	}
+
+	if err := tx.Model(&RedactCustomer{}).Where("id = ?", customers[0].ID).Update("name", "redact_1_updated").Error; err != nil {
+		t.Errorf("the writes shouldn't be affected, got %v", err)
+	}
+
+	DB.First(&found, customers[0].ID)
+	if found.Email != "jinzhu@example.com" || *found.Card != card || found.Name != "redact_1_updated" {
+		t.Errorf("the real data shouldn't be overwritten, got %+v", found)
+	}
+
+	DB.Model(&RedactCustomer{}).Where("id = ?", customers[0].ID).Update("unknown_field", "unknown")
+	if err := tx.First(&RedactCustomer{}, customers[0].ID).Error; !errors.Is(err, gorm.ErrUnregisteredRedactor) {
+		t.Errorf("should return ErrUnregisteredRedactor for the unknown redactor, got %v", err)
+	}
+}
+
+func TestRedactScanDestinations(t *testing.T) {
+	gorm.RegisterRedactor("phone", redactPhone)
+
+	DB.Migrator().DropTable(&RedactCustomer{})
+	if err := DB.AutoMigrate(&RedactCustomer{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	customer := RedactCustomer{Name: "redact_scan", Email: "jinzhu@example.com", Phone: "+1 555 0100"}
+	DB.Omit("UnknownField").Create(&customer)
+	tx := DB.Session(&gorm.Session{Redact: true})
+
+	var loaded RedactCustomer
+	tx.First(&loaded, customer.ID)
+	if !loaded.Redacted.IsRedacted() {
+		t.Errorf("the redacted load should be recorded in the model")
+	}
+
+	var result map[string]interface{}
+	if err := tx.Model(&RedactCustomer{}).Select("email").Where("id = ?", customer.ID).Find(&result).Error; err != nil || result["email"] != "j***@example.com" {
+		t.Errorf("the values scanned into the map should be redacted, got %v, error %v", result, err)
+	}
+
+	var results []map[string]interface{}
+	tx.Model(&RedactCustomer{}).Select("email").Where("id = ?", customer.ID).Find(&results)
+	if len(results) != 1 || results[0]["email"] != "j***@example.com" {
+		t.Errorf("the values scanned into the maps should be redacted, got %v", results)
+	}
+
+	type CustomerDTO struct {
+		Name  string
+		Email string
+	}
+	var dto CustomerDTO
+	tx.Model(&RedactCustomer{}).Where("id = ?", customer.ID).Scan(&dto)
+	if dto.Name != "redact_scan" || dto.Email != "j***@example.com" {
+		t.Errorf("the values scanned into the struct should be redacted, got %+v", dto)
+	}
+
+	var emails []string
+	tx.Model(&RedactCustomer{}).Where("id = ?", customer.ID).Pluck("email", &emails)
+	if len(emails) != 1 || emails[0] != "j***@example.com" {
+		t.Errorf("the plucked values should be redacted, got %v", emails)
+	}
+
+	var email string
+	tx.Model(&RedactCustomer{}).Select("email").Where("id = ?", customer.ID).Scan(&email)
+	AssertEqual(t, email, "j***@example.com")
+
+	rows, err := tx.Model(&RedactCustomer{}).Where("id = ?", customer.ID).Rows()
+	if err != nil {
+		t.Fatalf("failed to query rows, got error %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var scanned CustomerDTO
+		if err := tx.Model(&RedactCustomer{}).ScanRows(rows, &scanned); err != nil || scanned.Email != "j***@example.com" {
+			t.Errorf("the rows scanned by ScanRows should be redacted, got %+v, error %v", scanned, err)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package tests_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type ResolverItem struct {
+	ID   uint
+	Name string
+}
+
+// countingPool counts the queries run on the replica, and fails them with driver.ErrBadConn when broken
+type countingPool struct {
+	*sql.DB
+	queries int64
+	broken  int32
+}
+
+func (p *countingPool) count() error {
+	atomic.AddInt64(&p.queries, 1)
+	if atomic.LoadInt32(&p.broken) == 1 {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (p *countingPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	if err := p.count(); err != nil {
+		return nil, err
+	}
+	return p.DB.PrepareContext(ctx, query)
+}
+
+func (p *countingPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if err := p.count(); err != nil {
+		return nil, err
+	}
+	return p.DB.QueryContext(ctx, query, args...)
+}
+
+func (p *countingPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	p.count()
+	return p.DB.QueryRowContext(ctx, query, args...)
+}
+
+func TestReplicas(t *testing.T) {
+	if DB.Dialector.Name() != "sqlite" {
+		t.Skip()
+	}
+
+	dir := t.TempDir()
+	openWithItem := func(path, name string) *gorm.DB {
+		db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+		if err != nil {
+			t.Fatalf("failed to open %v, got error %v", path, err)
+		}
+		db.Migrator().DropTable(&ResolverItem{})
+		db.AutoMigrate(&ResolverItem{})
+		db.Create(&ResolverItem{Name: name})
+		return db
+	}
+
+	openWithItem(filepath.Join(dir, "primary.db"), "primary")
+	replicaDB := openWithItem(filepath.Join(dir, "replica.db"), "replica")
+	sqlDB, _ := replicaDB.DB()
+	replica := &countingPool{DB: sqlDB}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "primary.db")), &gorm.Config{
+		Replicas:          []gorm.Dialector{sqlite.New(sqlite.Config{Conn: replica})},
+		ReplicaQuarantine: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("failed to open with replicas, got error %v", err)
+	}
+
+	readFrom := func(db *gorm.DB) string {
+		t.Helper()
+		var item ResolverItem
+		if err := db.First(&item).Error; err != nil {
+			t.Fatalf("failed to query, got error %v", err)
+		}
+		return item.Name
+	}
+
+	AssertEqual(t, readFrom(db), "replica")
+	AssertEqual(t, readFrom(db.Clauses(gorm.Write)), "primary")
+	AssertEqual(t, readFrom(db.Session(&gorm.Session{PrepareStmt: boolPtr(true)})), "replica")
+
+	var name string
+	db.Raw("SELECT name FROM resolver_items").Scan(&name)
+	AssertEqual(t, name, "replica")
+	db.Clauses(gorm.Write).Raw("SELECT name FROM resolver_items").Scan(&name)
+	AssertEqual(t, name, "primary")
+
+	var count int64
+	db.Model(&ResolverItem{}).Where("name = ?", "replica").Count(&count)
+	AssertEqual(t, count, 1)
+
+	db.Transaction(func(tx *gorm.DB) error {
+		AssertEqual(t, readFrom(tx), "primary")
+		return nil
+	})
+
+	// writes and locking reads stay on the primary
+	queries := atomic.LoadInt64(&replica.queries)
+	if err := db.Create(&ResolverItem{Name: "primary 2"}).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+	db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ResolverItem{}) // sqlite doesn't support FOR UPDATE
+	db.Exec("UPDATE resolver_items SET name = name")
+	AssertEqual(t, atomic.LoadInt64(&replica.queries), queries)
+
+	// quarantine the replica returning connection errors
+	atomic.StoreInt32(&replica.broken, 1)
+	if err := db.First(&ResolverItem{}).Error; !errors.Is(err, driver.ErrBadConn) {
+		t.Errorf("expected ErrBadConn, got %v", err)
+	}
+	queries = atomic.LoadInt64(&replica.queries)
+	AssertEqual(t, readFrom(db), "primary")
+	AssertEqual(t, atomic.LoadInt64(&replica.queries), queries)
+
+	atomic.StoreInt32(&replica.broken, 0)
+	time.Sleep(60 * time.Millisecond)
+	AssertEqual(t, readFrom(db), "replica")
+}
+
+func TestReplicaPolicies(t *testing.T) {
+	ctx := context.Background()
+	candidates := []int{0, 2, 3}
+
+	roundRobin := gorm.RoundRobinPolicy()
+	var got []int
+	for i := 0; i < 4; i++ {
+		got = append(got, roundRobin.Resolve(ctx, candidates))
+	}
+	AssertEqual(t, got, []int{0, 2, 3, 0})
+
+	random := gorm.RandomPolicy()
+	for i := 0; i < 10; i++ {
+		if r := random.Resolve(ctx, candidates); r != 0 && r != 2 && r != 3 {
+			t.Errorf("random policy should return a candidate, got %v", r)
+		}
+	}
+
+	lags := map[int]time.Duration{0: time.Second, 2: time.Millisecond, 3: time.Minute}
+	leastLag := gorm.LeastLagPolicy(func(ctx context.Context, replica int) time.Duration { return lags[replica] })
+	AssertEqual(t, leastLag.Resolve(ctx, candidates), 2)
+}
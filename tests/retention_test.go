@@ -0,0 +1,122 @@
+package tests_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type RetentionEvent struct {
+	ID        uint `gorm:"retention:2160h;retentionColumn:created_at"`
+	Name      string
+	CreatedAt time.Time
+}
+
+var retentionEventDeletes int
+
+func (RetentionEvent) BeforeDelete(tx *gorm.DB) error {
+	retentionEventDeletes++
+	return nil
+}
+
+type RetentionToken struct {
+	ID        uint
+	Name      string
+	CreatedAt int64 `gorm:"autoCreateTime:milli;retention:24h"`
+	DeletedAt gorm.DeletedAt
+}
+
+func TestPurgeExpired(t *testing.T) {
+	DB.Migrator().DropTable(&RetentionEvent{}, &RetentionToken{})
+	if err := DB.AutoMigrate(&RetentionEvent{}, &RetentionToken{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	now := time.Now()
+	events := []RetentionEvent{
+		{Name: "expired_1", CreatedAt: now.Add(-91 * 24 * time.Hour)},
+		{Name: "expired_2", CreatedAt: now.Add(-100 * 24 * time.Hour)},
+		{Name: "expired_3", CreatedAt: now.Add(-365 * 24 * time.Hour)},
+		{Name: "kept", CreatedAt: now.Add(-89 * 24 * time.Hour)},
+	}
+	tokens := []RetentionToken{
+		{Name: "expired_1", CreatedAt: now.Add(-25 * time.Hour).UnixMilli()},
+		{Name: "expired_2", CreatedAt: now.Add(-48 * time.Hour).UnixMilli()},
+		{Name: "kept", CreatedAt: now.Add(-time.Hour).UnixMilli()},
+	}
+	DB.Create(&events)
+	DB.Create(&tokens)
+
+	results, err := DB.PurgeExpired(&RetentionEvent{}, &RetentionToken{}, gorm.PurgeDryRun())
+	if err != nil {
+		t.Fatalf("failed to count expired rows, got error %v", err)
+	}
+
+	expects := []gorm.PurgeResult{{Model: "RetentionEvent", Table: "retention_events", Purged: 3}, {Model: "RetentionToken", Table: "retention_tokens", Purged: 2}}
+	if len(results) != 2 || results[0] != expects[0] || results[1] != expects[1] {
+		t.Fatalf("expired rows should be counted, expects %+v, got %+v", expects, results)
+	}
+
+	var count int64
+	if DB.Model(&RetentionEvent{}).Count(&count); count != 4 {
+		t.Fatalf("dry run shouldn't delete rows, got %v", count)
+	}
+
+	retentionEventDeletes = 0
+	results, err = DB.PurgeExpired(&RetentionEvent{}, &RetentionToken{}, gorm.PurgeBatchSize(2))
+	if err != nil || len(results) != 2 || results[0].Purged != 3 || results[1].Purged != 2 {
+		t.Fatalf("expired rows should be purged, got %+v, error %v", results, err)
+	}
+
+	if retentionEventDeletes != 2 {
+		t.Errorf("hooks should be called by every batch, got %v", retentionEventDeletes)
+	}
+
+	var names []string
+	DB.Model(&RetentionEvent{}).Pluck("name", &names)
+	if len(names) != 1 || names[0] != "kept" {
+		t.Errorf("expired events should be deleted, got %v", names)
+	}
+
+	DB.Model(&RetentionToken{}).Pluck("name", &names)
+	if len(names) != 1 || names[0] != "kept" {
+		t.Errorf("expired tokens should be soft deleted, got %v", names)
+	}
+
+	if DB.Unscoped().Model(&RetentionToken{}).Count(&count); count != 3 {
+		t.Errorf("expired tokens should be soft deleted, got %v", count)
+	}
+
+	DB.Create(&RetentionEvent{Name: "expired_4", CreatedAt: now.Add(-91 * 24 * time.Hour)})
+	retentionEventDeletes = 0
+	results, err = DB.PurgeExpired(&RetentionEvent{}, &RetentionToken{}, gorm.PurgeSoftDeleted(), gorm.PurgeSkipHooks())
+	if err != nil || len(results) != 2 || results[0].Purged != 1 || results[1].Purged != 2 {
+		t.Fatalf("expired rows should be purged, got %+v, error %v", results, err)
+	}
+
+	if retentionEventDeletes != 0 {
+		t.Errorf("hooks should be skipped, got %v", retentionEventDeletes)
+	}
+
+	if DB.Unscoped().Model(&RetentionToken{}).Count(&count); count != 1 {
+		t.Errorf("soft deleted tokens should be deleted permanently, got %v", count)
+	}
+
+	if _, err := DB.PurgeExpired(&Company{}); !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("should return ErrInvalidData for the model without retention, got %v", err)
+	}
+
+	type InvalidRetention struct {
+		ID        uint `gorm:"retention:90d"`
+		CreatedAt time.Time
+		UpdatedAt time.Time `gorm:"retention:24h;retentionColumn:expired_at"`
+	}
+
+	if err := schema.ValidateModel(&InvalidRetention{}); !errors.Is(err, schema.ErrInvalidTag) {
+		t.Errorf("should return ErrInvalidTag for the invalid retention, got %v", err)
+	}
+}
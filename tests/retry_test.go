@@ -0,0 +1,124 @@
+package tests_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	. "gorm.io/gorm/utils/tests"
+)
+
+// flakyPool fails the next queries like a dropped connection
+type flakyPool struct {
+	*sql.DB
+	failures int32
+}
+
+func (p *flakyPool) fail() bool {
+	return atomic.AddInt32(&p.failures, -1) >= 0
+}
+
+func (p *flakyPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if p.fail() {
+		return nil, driver.ErrBadConn
+	}
+	return p.DB.QueryContext(ctx, query, args...)
+}
+
+func (p *flakyPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if p.fail() {
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		return p.DB.QueryRowContext(canceledCtx, query, args...)
+	}
+	return p.DB.QueryRowContext(ctx, query, args...)
+}
+
+func TestRetryTransient(t *testing.T) {
+	if DB.Dialector.Name() != "sqlite" {
+		t.Skip()
+	}
+
+	sqlDB, _ := DB.DB()
+	pool := &flakyPool{DB: sqlDB}
+	buf := &bytes.Buffer{}
+	db, err := gorm.Open(sqlite.New(sqlite.Config{Conn: pool}), &gorm.Config{
+		Logger:          logger.New(log.New(buf, "", 0), logger.Config{LogLevel: logger.Warn}),
+		TransientErrors: []error{driver.ErrBadConn, context.Canceled},
+	})
+	if err != nil {
+		t.Fatalf("failed to open, got error %v", err)
+	}
+
+	user := *GetUser("retry_transient", Config{})
+	DB.Create(&user)
+
+	retryDB := db.Session(&gorm.Session{RetryTransient: 2})
+
+	atomic.StoreInt32(&pool.failures, 2)
+	var result User
+	if err := retryDB.First(&result, user.ID).Error; err != nil {
+		t.Fatalf("failed to find after retries, got error %v", err)
+	}
+	AssertEqual(t, result.Name, user.Name)
+	AssertEqual(t, strings.Count(buf.String(), "retrying query after transient error"), 2)
+	if !strings.Contains(buf.String(), "attempt 2/2") {
+		t.Errorf("expected retry attempt in log, got %v", buf.String())
+	}
+
+	atomic.StoreInt32(&pool.failures, 3)
+	if err := retryDB.First(&result, user.ID).Error; !errors.Is(err, driver.ErrBadConn) {
+		t.Errorf("expected ErrBadConn after exhausting retries, got %v", err)
+	}
+
+	atomic.StoreInt32(&pool.failures, 1)
+	if err := db.First(&result, user.ID).Error; !errors.Is(err, driver.ErrBadConn) {
+		t.Errorf("expected ErrBadConn without RetryTransient, got %v", err)
+	}
+
+	atomic.StoreInt32(&pool.failures, 1)
+	var users []User
+	if err := retryDB.Find(&users, "name = ?", user.Name).Error; err != nil || len(users) != 1 {
+		t.Errorf("failed to find after retry, got %v, error %v", len(users), err)
+	}
+
+	atomic.StoreInt32(&pool.failures, 1)
+	var count int64
+	if err := retryDB.Model(&User{}).Where("name = ?", user.Name).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("failed to count after retry, got %v, error %v", count, err)
+	}
+
+	atomic.StoreInt32(&pool.failures, 1)
+	var name string
+	if err := retryDB.Model(&User{}).Select("name").Where("id = ?", user.ID).Row().Scan(&name); err != nil || name != user.Name {
+		t.Errorf("failed to query row after retry, got %v, error %v", name, err)
+	}
+
+	atomic.StoreInt32(&pool.failures, 1)
+	rows, err := retryDB.Model(&User{}).Where("id = ?", user.ID).Rows()
+	if err != nil {
+		t.Fatalf("failed to query rows after retry, got error %v", err)
+	}
+	rows.Close()
+
+	atomic.StoreInt32(&pool.failures, 1)
+	if err := retryDB.Raw("SELECT name FROM users WHERE id = ?", user.ID).Scan(&name).Error; err != nil || name != user.Name {
+		t.Errorf("failed to scan after retry, got %v, error %v", name, err)
+	}
+
+	// reads in transactions are never retried
+	AssertEqual(t, retryDB.ShouldRetryTransient(0, driver.ErrBadConn), true)
+	tx := retryDB.Begin()
+	defer tx.Rollback()
+	AssertEqual(t, tx.ShouldRetryTransient(0, driver.ErrBadConn), false)
+	AssertEqual(t, retryDB.ShouldRetryTransient(0, errors.New("syntax error")), false)
+}
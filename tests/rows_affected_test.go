@@ -0,0 +1,95 @@
+package tests_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+// unchangedConnPool reports the changed rows of the updates, which are the updates not changing some of the matched
+// rows of mysql
+type unchangedConnPool struct {
+	gorm.ConnPool
+	changed int64
+}
+
+type unchangedResult struct {
+	sql.Result
+	changed int64
+}
+
+func (r unchangedResult) RowsAffected() (int64, error) {
+	return r.changed, nil
+}
+
+func (p unchangedConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := p.ConnPool.ExecContext(ctx, query, args...)
+	if err == nil && strings.HasPrefix(query, "UPDATE") {
+		result = unchangedResult{Result: result, changed: p.changed}
+	}
+	return result, err
+}
+
+func TestRowsAffectedMode(t *testing.T) {
+	user := *GetUser("rows_affected", Config{})
+	DB.Create(&user)
+
+	// postgres, sqlite and sqlserver report the matched rows, mysql reports the changed rows unless clientFoundRows=true
+	matched := DB.Dialector.Name() != "mysql"
+	if DB.Supports(gorm.CapabilityMatchedRowsAffected) != matched {
+		t.Fatalf("CapabilityMatchedRowsAffected of %s should be %v", DB.Dialector.Name(), matched)
+	}
+
+	if result := DB.Model(&user).UpdateColumn("name", user.Name); result.Error != nil || (result.RowsAffected == 1) != matched {
+		t.Errorf("the unchanged row should be reported by the driver, got %v, error %v", result.RowsAffected, result.Error)
+	}
+
+	result := DB.Session(&gorm.Session{RowsAffectedMode: gorm.RowsAffectedChanged}).Model(&user).UpdateColumn("age", 30)
+	if matched && !errors.Is(result.Error, gorm.ErrUnsupportedDriver) {
+		t.Errorf("should return ErrUnsupportedDriver for the changed rows of %s, got %v", DB.Dialector.Name(), result.Error)
+	} else if !matched && (result.Error != nil || result.RowsAffected != 1) {
+		t.Errorf("the changed row should be reported, got %v, error %v", result.RowsAffected, result.Error)
+	}
+
+	var age uint
+	if DB.Model(&User{}).Select("age").Where("id = ?", user.ID).Scan(&age); matched && age == 30 {
+		t.Errorf("the update shouldn't be executed for the unsupported mode")
+	}
+
+	tx := DB.Session(&gorm.Session{Context: context.Background(), RowsAffectedMode: gorm.RowsAffectedMatched})
+	tx.Config.Dialector = capabilityDialector{Dialector: DB.Dialector, capabilities: gorm.Capabilities{gorm.CapabilityMatchedRowsAffected: false}}
+	tx.Statement.ConnPool = unchangedConnPool{ConnPool: tx.Statement.ConnPool}
+
+	if result := tx.Model(&user).UpdateColumn("name", user.Name); result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("the unchanged row should be counted as matched, got %v, error %v", result.RowsAffected, result.Error)
+	}
+
+	if result := tx.Model(&User{}).Where("name = ?", "rows_affected_none").UpdateColumn("age", 1); result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("no rows should be matched, got %v, error %v", result.RowsAffected, result.Error)
+	}
+
+	if result := tx.Save(&user); result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("the unchanged row should be saved, got %v, error %v", result.RowsAffected, result.Error)
+	} else if path, _ := result.Get("gorm:save_path"); path != "update" {
+		t.Errorf("the matched row should be updated by Save, got %v", path)
+	}
+
+	users := []User{*GetUser("rows_affected_partial", Config{}), *GetUser("rows_affected_partial", Config{})}
+	DB.Create(&users)
+	DB.Model(&users[0]).UpdateColumn("age", 40)
+
+	tx.Statement.ConnPool = unchangedConnPool{ConnPool: DB.Statement.ConnPool, changed: 1}
+	if result := tx.Model(&User{}).Where("name = ?", "rows_affected_partial").UpdateColumn("age", 40); result.Error != nil || result.RowsAffected != 2 {
+		t.Errorf("the partially changed rows should be counted as matched, got %v, error %v", result.RowsAffected, result.Error)
+	}
+
+	// the changed rows don't match the conditions after the update
+	if result := tx.Model(&User{}).Where("name = ?", "rows_affected_partial").UpdateColumn("name", "rows_affected_renamed"); result.Error != nil || result.RowsAffected != 2 {
+		t.Errorf("the renamed rows should be counted as matched, got %v, error %v", result.RowsAffected, result.Error)
+	}
+}
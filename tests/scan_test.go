@@ -1,6 +1,7 @@
 package tests_test
 
 import (
+	"errors"
 	"reflect"
 	"sort"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -339,3 +341,168 @@ func TestScanToEmbedded(t *testing.T) {
 	err := DB.Raw("SELECT * FROM users INNER JOIN users Manager ON users.manager_id = Manager.id WHERE users.id = ?", user.ID).Scan(&user2).Error
 	AssertEqual(t, err, nil)
 }
+
+type ScanWideRow struct {
+	ID       int64
+	Name     string
+	Code     string
+	Email    string
+	Age      int
+	Score    float64
+	Rank     int64
+	Active   bool
+	Verified bool
+	Nick     *string
+	Weight   *float64
+	Tags     []string `gorm:"serializer:json"`
+	Born     time.Time
+	Login    *time.Time
+	Note     string
+}
+
+func TestScanPlan(t *testing.T) {
+	DB.Migrator().DropTable(&ScanWideRow{})
+	if err := DB.AutoMigrate(&ScanWideRow{}); err != nil {
+		t.Fatalf("failed to migrate, got error: %v", err)
+	}
+
+	nick, weight, login := "nick", 62.5, time.Now().Round(time.Second)
+	rows := []ScanWideRow{
+		{Name: "scan_plan_1", Age: 18, Score: 9.5, Rank: 3, Active: true, Nick: &nick, Weight: &weight, Tags: []string{"a", "b"}, Born: time.Now().Round(time.Second), Login: &login, Note: "note"},
+		{Name: "scan_plan_2", Age: 20, Tags: []string{}, Born: time.Now().Round(time.Second)},
+	}
+	if err := DB.Create(&rows).Error; err != nil {
+		t.Fatalf("failed to create, got error: %v", err)
+	}
+
+	var results []ScanWideRow
+	if err := DB.Order("id").Find(&results).Error; err != nil {
+		t.Fatalf("failed to find, got error: %v", err)
+	}
+	AssertEqual(t, len(results), 2)
+	for idx := range rows {
+		AssertObjEqual(t, results[idx], rows[idx], "ID", "Name", "Code", "Email", "Age", "Score", "Rank", "Active", "Verified", "Nick", "Weight", "Tags", "Born", "Login", "Note")
+	}
+
+	var pointers []*ScanWideRow
+	if err := DB.Order("id").Find(&pointers).Error; err != nil {
+		t.Fatalf("failed to find, got error: %v", err)
+	}
+	AssertEqual(t, len(pointers), 2)
+	AssertObjEqual(t, *pointers[0], rows[0], "ID", "Name", "Age", "Score", "Nick", "Tags", "Born", "Login")
+
+	// NULL leaves the field unchanged
+	result := ScanWideRow{Name: "unchanged", Note: "unchanged"}
+	if err := DB.Raw("SELECT id, NULL AS name, note FROM scan_wide_rows WHERE id = ?", rows[1].ID).Find(&result).Error; err != nil {
+		t.Fatalf("failed to scan, got error: %v", err)
+	}
+	AssertEqual(t, result.ID, rows[1].ID)
+	AssertEqual(t, result.Name, "unchanged")
+	AssertEqual(t, result.Note, "")
+
+	stmt := &gorm.Statement{DB: DB}
+	if err := stmt.Parse(&ScanWideRow{}); err != nil {
+		t.Fatalf("failed to parse, got error: %v", err)
+	}
+
+	columns := []string{"id", "name", "nick", "tags", "born", "unknown"}
+	plan := stmt.Schema.ScanPlan(columns)
+	if plan != stmt.Schema.ScanPlan(append([]string(nil), columns...)) {
+		t.Errorf("scan plan should be cached")
+	}
+
+	for idx, hasDecoder := range []bool{true, true, false, false, true, false} {
+		if (plan.Decoders[idx] != nil) != hasDecoder {
+			t.Errorf("column %v should have decoder %v", columns[idx], hasDecoder)
+		}
+	}
+	if plan.Fields[5] != nil {
+		t.Errorf("unknown column should not be mapped")
+	}
+}
+
+type NestedScanOrder struct {
+	ID     uint
+	UserID uint
+	Total  int
+}
+
+type NestedScanAddress struct {
+	City string
+	Zip  string
+}
+
+type NestedScanUser struct {
+	ID      uint
+	Name    string
+	Order   *NestedScanOrder   `gorm:"foreignKey:UserID"`
+	Address *NestedScanAddress `gorm:"embedded;embeddedPrefix:addr_"`
+	Billing NestedScanAddress  `gorm:"embedded;embeddedPrefix:billing_"`
+}
+
+type AmbiguousNestedScanUser struct {
+	ID        uint
+	Order     *NestedScanOrder  `gorm:"foreignKey:UserID"`
+	OrderInfo NestedScanAddress `gorm:"embedded;embeddedPrefix:order_"`
+}
+
+func TestScanNestedColumns(t *testing.T) {
+	DB.Migrator().DropTable(&NestedScanUser{}, &NestedScanOrder{})
+	if err := DB.AutoMigrate(&NestedScanUser{}, &NestedScanOrder{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	users := []NestedScanUser{
+		{Name: "nested_scan_1", Order: &NestedScanOrder{Total: 10}, Address: &NestedScanAddress{City: "a", Zip: "1"}},
+		{Name: "nested_scan_2"},
+	}
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("failed to create users, got error %v", err)
+	}
+
+	var results []NestedScanUser
+	if err := DB.Raw("SELECT u.id, u.name, o.id AS order__id, o.user_id AS order__user_id, o.total AS order__total, " +
+		"u.addr_city AS addr__city, u.addr_zip AS addr__zip, u.name AS billing__city " +
+		"FROM nested_scan_users u LEFT JOIN nested_scan_orders o ON o.user_id = u.id ORDER BY u.id").Scan(&results).Error; err != nil {
+		t.Fatalf("failed to scan nested columns, got error %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("should scan 2 users, got %v", len(results))
+	}
+	AssertEqual(t, results[0].Order, users[0].Order)
+	AssertEqual(t, results[0].Address, users[0].Address)
+	AssertEqual(t, results[0].Billing.City, "nested_scan_1")
+
+	if results[1].Order != nil || results[1].Address != nil {
+		t.Errorf("nested pointers should be nil if all their columns are NULL, got %+v, %+v", results[1].Order, results[1].Address)
+	}
+	AssertEqual(t, results[1].Billing.City, "nested_scan_2")
+
+	results = nil
+	if err := DB.Model(&NestedScanUser{}).Select("nested_scan_users.id, nested_scan_users.name, o.id AS order__id, o.total AS order__total").
+		Joins("LEFT JOIN nested_scan_orders o ON o.user_id = nested_scan_users.id").Order("nested_scan_users.id").Find(&results).Error; err != nil {
+		t.Fatalf("failed to find with aliased joined columns, got error %v", err)
+	}
+
+	if len(results) != 2 || results[0].Order == nil || results[0].Order.Total != 10 || results[1].Order != nil {
+		t.Errorf("aliased joined columns should be scanned into the relationship, got %+v", results)
+	}
+
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.NestedColumnSeparator = "$"
+	var result NestedScanUser
+	if err := tx.Raw("SELECT u.id, o.total AS order$total FROM nested_scan_users u JOIN nested_scan_orders o ON o.user_id = u.id").Scan(&result).Error; err != nil {
+		t.Fatalf("failed to scan nested columns with separator, got error %v", err)
+	}
+
+	if result.Order == nil || result.Order.Total != 10 {
+		t.Errorf("nested columns should be split by the separator, got %+v", result.Order)
+	}
+
+	var ambiguous AmbiguousNestedScanUser
+	err := DB.Raw("SELECT u.id, o.total AS order__total FROM nested_scan_users u JOIN nested_scan_orders o ON o.user_id = u.id").Scan(&ambiguous).Error
+	if !errors.Is(err, schema.ErrAmbiguousPrefix) || !strings.Contains(err.Error(), "Order, OrderInfo") {
+		t.Errorf("should return ErrAmbiguousPrefix with the candidates, got %v", err)
+	}
+}
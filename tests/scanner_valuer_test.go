@@ -391,3 +391,108 @@ func TestGORMValuer(t *testing.T) {
 		t.Errorf("generated vars is not equal, got %v", stmt.Vars)
 	}
 }
+
+type localeContextKey struct{}
+
+// LocaleMoney the money formatted with the decimal separator of the locale in the context
+type LocaleMoney struct {
+	Cents int64
+}
+
+func localeSeparator(ctx context.Context) string {
+	if ctx.Value(localeContextKey{}) == "de" {
+		return ","
+	}
+	return "."
+}
+
+func (LocaleMoney) GormDataType() string {
+	return "string"
+}
+
+func (money LocaleMoney) GormValue(ctx context.Context, db *gorm.DB) clause.Expr {
+	return clause.Expr{SQL: "?", Vars: []interface{}{fmt.Sprintf("%d%s%02d", money.Cents/100, localeSeparator(ctx), money.Cents%100)}}
+}
+
+func (money *LocaleMoney) ScanContext(ctx context.Context, db *gorm.DB, src interface{}) error {
+	if db == nil {
+		return errors.New("db is required")
+	}
+
+	var value string
+	switch v := src.(type) {
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	case nil:
+		*money = LocaleMoney{}
+		return nil
+	default:
+		return fmt.Errorf("failed to scan money %#v", src)
+	}
+
+	var units, cents int64
+	if _, err := fmt.Sscanf(value, "%d"+localeSeparator(ctx)+"%d", &units, &cents); err != nil {
+		return fmt.Errorf("failed to scan money %v: %w", value, err)
+	}
+	money.Cents = units*100 + cents
+	return nil
+}
+
+func TestScannerWithContext(t *testing.T) {
+	type LocaleProduct struct {
+		ID       uint
+		Name     string
+		Price    LocaleMoney
+		Discount *LocaleMoney
+	}
+
+	DB.Migrator().DropTable(&LocaleProduct{})
+	if err := DB.AutoMigrate(&LocaleProduct{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	tx := DB.Session(&gorm.Session{Context: context.WithValue(context.Background(), localeContextKey{}, "de")})
+	product := LocaleProduct{Name: "scanner_with_context", Price: LocaleMoney{Cents: 1234}}
+	if err := tx.Create(&product).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	var price string
+	DB.Table("locale_products").Select("price").Where("id = ?", product.ID).Scan(&price)
+	AssertEqual(t, price, "12,34")
+
+	var result LocaleProduct
+	if err := tx.First(&result, product.ID).Error; err != nil {
+		t.Fatalf("failed to query, got error %v", err)
+	}
+	AssertEqual(t, result.Price, LocaleMoney{Cents: 1234})
+
+	if result.Discount != nil {
+		t.Errorf("NULL should be scanned as nil, got %v", result.Discount)
+	}
+
+	if err := DB.First(&LocaleProduct{}, product.ID).Error; err == nil {
+		t.Errorf("money should be scanned with the locale of the context")
+	}
+
+	discount := LocaleMoney{Cents: 50}
+	tx.Model(&result).Update("discount", discount)
+
+	var results []LocaleProduct
+	if err := tx.Find(&results, product.ID).Error; err != nil || len(results) != 1 {
+		t.Fatalf("failed to find, got %v, error %v", len(results), err)
+	}
+	AssertEqual(t, results[0].Price, LocaleMoney{Cents: 1234})
+	AssertEqual(t, results[0].Discount, &discount)
+
+	var created LocaleProduct
+	if err := tx.Where("name = ?", "scanner_with_context_attrs").Attrs(map[string]interface{}{"price": "5,60"}).FirstOrCreate(&created).Error; err != nil {
+		t.Fatalf("failed to first or create, got error %v", err)
+	}
+	AssertEqual(t, created.Price, LocaleMoney{Cents: 560})
+
+	DB.Table("locale_products").Select("price").Where("id = ?", created.ID).Scan(&price)
+	AssertEqual(t, price, "5,60")
+}
@@ -2,6 +2,8 @@ package tests_test
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"gorm.io/gorm"
@@ -127,3 +129,160 @@ func TestComplexScopes(t *testing.T) {
 		})
 	}
 }
+
+func TestNamedScopes(t *testing.T) {
+	users := []*User{
+		GetUser("NamedScopeUser1", Config{}),
+		GetUser("NamedScopeUser2", Config{}),
+		GetUser("NamedScopeUser3", Config{}),
+	}
+	users[2].Active = true
+	DB.Create(&users)
+
+	DB.RegisterScope("named_scope_users", func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name LIKE ?", "NamedScopeUser%")
+	}, gorm.ScopeOption{Unique: true})
+	DB.RegisterScope("named_scope_inactive", func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("active = ?", false)
+	})
+
+	var result []User
+	if err := DB.NamedScopes("named_scope_users", "named_scope_inactive").Order("name").Find(&result).Error; err != nil {
+		t.Fatalf("failed to find with named scopes, got error %v", err)
+	}
+	AssertEqual(t, len(result), 2)
+
+	dryRunDB := DB.Session(&gorm.Session{DryRun: true})
+	stmt := dryRunDB.NamedScopes("named_scope_users").Scopes(NameIn1And2).
+		NamedScopes("named_scope_users", "named_scope_inactive", "named_scope_inactive").Find(&User{}).Statement
+	AssertEqual(t, strings.Count(stmt.SQL.String(), "LIKE"), 1)
+	AssertEqual(t, strings.Count(stmt.SQL.String(), "active"), 2)
+	if len(stmt.AppliedScopes) != 4 || stmt.AppliedScopes[0] != "named_scope_users" ||
+		!strings.HasSuffix(stmt.AppliedScopes[1], "NameIn1And2") || stmt.AppliedScopes[3] != "named_scope_inactive" {
+		t.Errorf("unexpected applied scopes %v", stmt.AppliedScopes)
+	}
+
+	// unique scopes applied by other scopes
+	stmt = dryRunDB.Scopes(func(tx *gorm.DB) *gorm.DB {
+		return tx.NamedScopes("named_scope_users")
+	}).NamedScopes("named_scope_users").Find(&User{}).Statement
+	AssertEqual(t, strings.Count(stmt.SQL.String(), "LIKE"), 1)
+
+	if err := DB.NamedScopes("named_scope_unknown").Find(&result).Error; !errors.Is(err, gorm.ErrUnregisteredScope) {
+		t.Errorf("expected ErrUnregisteredScope, got %v", err)
+	}
+}
+
+type ScopedArticle struct {
+	ID       uint
+	Title    string
+	Visible  bool
+	Position int
+	Comments []ScopedComment
+}
+
+func (ScopedArticle) DefaultScope(db *gorm.DB) *gorm.DB {
+	return db.Where("visible = ?", true).Order("position")
+}
+
+type ScopedComment struct {
+	ID              uint
+	ScopedArticleID uint
+	Body            string
+	Approved        bool
+}
+
+func (ScopedComment) DefaultScope(db *gorm.DB) *gorm.DB {
+	return db.Where("approved = ?", true)
+}
+
+func (ScopedComment) DefaultWriteScope(db *gorm.DB) *gorm.DB {
+	return db.Where("approved = ?", true)
+}
+
+// ScopedDraft the scope queries the same model with a subquery
+type ScopedDraft struct {
+	ID       uint
+	Revision int
+}
+
+func (ScopedDraft) DefaultScope(db *gorm.DB) *gorm.DB {
+	return db.Where("revision = (?)", db.Session(&gorm.Session{NewDB: true}).Model(&ScopedDraft{}).Select("MAX(revision)"))
+}
+
+func TestDefaultScope(t *testing.T) {
+	DB.Migrator().DropTable(&ScopedArticle{}, &ScopedComment{}, &ScopedDraft{})
+	if err := DB.AutoMigrate(&ScopedArticle{}, &ScopedComment{}, &ScopedDraft{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	articles := []ScopedArticle{
+		{Title: "second", Visible: true, Position: 2, Comments: []ScopedComment{{Body: "approved", Approved: true}, {Body: "pending"}}},
+		{Title: "hidden", Position: 0},
+		{Title: "first", Visible: true, Position: 1},
+	}
+	DB.Create(&articles)
+
+	var results []ScopedArticle
+	if err := DB.Preload("Comments").Find(&results).Error; err != nil {
+		t.Fatalf("failed to find, got error %v", err)
+	}
+	if len(results) != 2 || results[0].Title != "first" || results[1].Title != "second" {
+		t.Fatalf("should find the visible articles ordered by position, got %+v", results)
+	}
+	if len(results[1].Comments) != 1 || results[1].Comments[0].Body != "approved" {
+		t.Errorf("should preload the approved comments, got %+v", results[1].Comments)
+	}
+
+	var article ScopedArticle
+	if err := DB.First(&article, articles[1].ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("the hidden article shouldn't be found, got %v", err)
+	}
+
+	// the conditions are grouped with the OR conditions
+	var count int64
+	if err := DB.Model(&ScopedArticle{}).Where("title = ?", "hidden").Or("title = ?", "first").Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("should count the visible articles, got %v, error %v", count, err)
+	}
+
+	var titles []string
+	DB.Model(&ScopedArticle{}).Pluck("title", &titles)
+	AssertEqual(t, titles, []string{"first", "second"})
+
+	t.Run("Disabled", func(t *testing.T) {
+		var results []ScopedArticle
+		DB.Unscoped().Preload("Comments").Order("id").Find(&results)
+		if len(results) != 3 || len(results[0].Comments) != 2 {
+			t.Errorf("Unscoped should disable the default scopes, got %+v", results)
+		}
+
+		results = nil
+		DB.WithoutDefaultScope().Preload("Comments").Order("id").Find(&results)
+		if len(results) != 3 || len(results[0].Comments) != 2 {
+			t.Errorf("WithoutDefaultScope should disable the default scopes, got %+v", results)
+		}
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		// the articles have no DefaultWriteScope
+		if tx := DB.Model(&ScopedArticle{}).Where("1 = 1").Update("position", gorm.Expr("position + ?", 10)); tx.RowsAffected != 3 {
+			t.Errorf("the updates shouldn't be scoped without DefaultWriteScope, got %v", tx.RowsAffected)
+		}
+
+		if tx := DB.Where("scoped_article_id = ?", articles[0].ID).Delete(&ScopedComment{}); tx.RowsAffected != 1 {
+			t.Errorf("the deletes should be scoped with DefaultWriteScope, got %v", tx.RowsAffected)
+		}
+		var count int64
+		DB.Unscoped().Model(&ScopedComment{}).Count(&count)
+		AssertEqual(t, count, 1)
+	})
+
+	t.Run("Recursion", func(t *testing.T) {
+		DB.Create(&[]ScopedDraft{{Revision: 1}, {Revision: 3}, {Revision: 2}})
+
+		var drafts []ScopedDraft
+		if err := DB.Find(&drafts).Error; err != nil || len(drafts) != 1 || drafts[0].Revision != 3 {
+			t.Errorf("should find the last revision, got %+v, error %v", drafts, err)
+		}
+	})
+}
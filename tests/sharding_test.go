@@ -0,0 +1,177 @@
+package tests_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type ShardedCustomer struct {
+	ID     uint
+	Name   string
+	Orders []ShardedOrder `gorm:"foreignKey:CustomerID"`
+}
+
+type ShardedOrder struct {
+	ID         uint
+	CustomerID uint
+	Amount     int
+}
+
+var orderShards = []string{"sharded_orders_0", "sharded_orders_1"}
+
+type orderSharder struct{}
+
+func (orderSharder) ShardName(stmt *gorm.Statement) (string, bool) {
+	if stmt.Schema.Table != "sharded_orders" {
+		return "", true
+	}
+
+	if value, ok := stmt.ShardKey("CustomerID"); ok {
+		customerID, _ := strconv.Atoi(fmt.Sprint(value))
+		return orderShards[customerID%len(orderShards)], true
+	}
+	return "", false
+}
+
+func (orderSharder) ShardNames(stmt *gorm.Statement) []string {
+	return orderShards
+}
+
+func TestSharder(t *testing.T) {
+	DB.Migrator().DropTable(&ShardedCustomer{}, orderShards[0], orderShards[1])
+	if err := DB.AutoMigrate(&ShardedCustomer{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	for _, shard := range orderShards {
+		if err := DB.Table(shard).AutoMigrate(&ShardedOrder{}); err != nil {
+			t.Fatalf("failed to migrate shard %v, got error %v", shard, err)
+		}
+	}
+
+	tx := DB.Session(&gorm.Session{Context: context.Background()})
+	tx.Config.Sharder = orderSharder{}
+
+	orders := []ShardedOrder{{CustomerID: 101, Amount: 10}, {CustomerID: 102, Amount: 20}, {CustomerID: 103, Amount: 30}}
+	if err := tx.Create(&orders).Error; err != nil {
+		t.Fatalf("failed to create orders, got error %v", err)
+	}
+
+	for _, order := range orders {
+		if order.ID == 0 {
+			t.Errorf("primary key of created order should be set, got %+v", order)
+		}
+	}
+
+	var count int64
+	DB.Table(orderShards[0]).Count(&count)
+	AssertEqual(t, count, 1)
+	DB.Table(orderShards[1]).Count(&count)
+	AssertEqual(t, count, 2)
+
+	var results []ShardedOrder
+	if err := tx.Where("customer_id = ?", 103).Find(&results).Error; err != nil {
+		t.Fatalf("failed to query the shard, got error %v", err)
+	}
+	AssertEqual(t, results, []ShardedOrder{orders[2]})
+
+	if err := tx.Model(&orders[1]).Update("amount", 21).Error; err != nil {
+		t.Fatalf("failed to update the shard, got error %v", err)
+	}
+
+	var order ShardedOrder
+	DB.Table(orderShards[0]).First(&order, orders[1].ID)
+	AssertEqual(t, order.Amount, 21)
+
+	if err := tx.Find(&results).Error; !errors.Is(err, gorm.ErrShardNotFound) {
+		t.Errorf("should returns ErrShardNotFound if the shard can't be determined, got %v", err)
+	}
+
+	if err := tx.Delete(&ShardedOrder{}, orders[0].ID).Error; !errors.Is(err, gorm.ErrShardNotFound) {
+		t.Errorf("writes shouldn't be scattered, got %v", err)
+	}
+
+	scatterTx := tx.Session(&gorm.Session{ShardScatter: true})
+	if err := scatterTx.Order("id").Find(&results).Error; err != nil {
+		t.Fatalf("failed to scatter the query, got error %v", err)
+	}
+	// the ids are generated by the shards, the equal ones are kept in the order of the shards
+	orders[1].Amount = 21
+	AssertEqual(t, results, []ShardedOrder{orders[1], orders[0], orders[2]})
+
+	if err := scatterTx.Order("id").Limit(1).Offset(1).Find(&results).Error; err != nil {
+		t.Fatalf("failed to scatter the query, got error %v", err)
+	}
+	AssertEqual(t, results, []ShardedOrder{orders[0]})
+
+	// the keys are interleaved across the shards, so the merged results are sorted before limited
+	if err := scatterTx.Order("amount DESC").Limit(2).Find(&results).Error; err != nil {
+		t.Fatalf("failed to scatter the query, got error %v", err)
+	}
+	AssertEqual(t, results, []ShardedOrder{orders[2], orders[1]})
+
+	if err := scatterTx.Order("customer_id").Order(clause.OrderByColumn{Column: clause.Column{Name: "amount"}, Desc: true}).Offset(1).Find(&results).Error; err != nil {
+		t.Fatalf("failed to scatter the query, got error %v", err)
+	}
+	AssertEqual(t, results, []ShardedOrder{orders[1], orders[2]})
+
+	order = ShardedOrder{}
+	if err := scatterTx.First(&order).Error; err != nil {
+		t.Fatalf("failed to scatter the query, got error %v", err)
+	}
+	AssertEqual(t, order, orders[1])
+
+	order = ShardedOrder{}
+	if err := scatterTx.Last(&order).Error; err != nil {
+		t.Fatalf("failed to scatter the query, got error %v", err)
+	}
+	AssertEqual(t, order, orders[2])
+
+	if err := scatterTx.Order("amount + 1").Find(&results).Error; !errors.Is(err, gorm.ErrShardNotFound) {
+		t.Errorf("the scattered queries can't be ordered by the expressions, got %v", err)
+	}
+
+	order = ShardedOrder{}
+	if err := scatterTx.Where("amount = ?", 30).First(&order).Error; err != nil {
+		t.Fatalf("failed to scatter the query, got error %v", err)
+	}
+	AssertEqual(t, order, orders[2])
+
+	order = ShardedOrder{}
+	if err := scatterTx.Where("amount = ?", 40).First(&order).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("should returns ErrRecordNotFound if not found on any shard, got %v", err)
+	}
+
+	customer := ShardedCustomer{Name: "sharder", Orders: []ShardedOrder{{Amount: 1}, {Amount: 2}}}
+	if err := tx.Create(&customer).Error; err != nil {
+		t.Fatalf("failed to create customer with orders, got error %v", err)
+	}
+
+	shard := orderShards[int(customer.ID)%len(orderShards)]
+	DB.Table(shard).Where("customer_id = ?", customer.ID).Count(&count)
+	AssertEqual(t, count, 2)
+
+	var result ShardedCustomer
+	if err := tx.Preload("Orders").First(&result, customer.ID).Error; err != nil {
+		t.Fatalf("failed to preload orders, got error %v", err)
+	}
+	AssertEqual(t, result, customer)
+
+	var associated []ShardedOrder
+	if err := tx.Model(&customer).Association("Orders").Find(&associated); err != nil {
+		t.Fatalf("failed to find associations, got error %v", err)
+	}
+	AssertEqual(t, associated, customer.Orders)
+
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Find(&results).Statement
+	if stmt.Table != "sharded_orders" || stmt.Schema.Table != "sharded_orders" {
+		t.Errorf("schema shouldn't be changed by the shards, got %v, %v", stmt.Table, stmt.Schema.Table)
+	}
+}
@@ -1,14 +1,19 @@
 package tests_test
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jinzhu/now"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -167,3 +172,551 @@ func TestSoftDeleteZeroValue(t *testing.T) {
 		t.Errorf("Can't find permanently deleted record")
 	}
 }
+
+type ArchivedOrder struct {
+	ID         uint
+	Name       string `gorm:"uniqueIndex"`
+	Amount     int
+	ArchivedAt gorm.ArchivedAt
+}
+
+func TestArchive(t *testing.T) {
+	DB.Migrator().DropTable(&ArchivedOrder{}, "archived_orders_archive")
+	if err := DB.AutoMigrate(&ArchivedOrder{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if !DB.Migrator().HasTable("archived_orders_archive") || !DB.Migrator().HasColumn("archived_orders_archive", "archived_at") {
+		t.Fatalf("failed to create the archive table")
+	}
+	if err := DB.AutoMigrate(&ArchivedOrder{}); err != nil {
+		t.Fatalf("failed to migrate again, got error %v", err)
+	}
+
+	orders := []ArchivedOrder{{Name: "order1", Amount: 1}, {Name: "order2", Amount: 2}, {Name: "order3", Amount: 3}, {Name: "order4", Amount: 4}}
+	DB.Create(&orders)
+
+	counts := func() (count, archived int64) {
+		t.Helper()
+		DB.Model(&ArchivedOrder{}).Count(&count)
+		DB.Table("archived_orders_archive").Count(&archived)
+		return
+	}
+
+	if err := DB.Where("amount = ?", 1).Delete(&ArchivedOrder{}).Error; err != nil {
+		t.Fatalf("failed to delete, got error %v", err)
+	}
+	count, archived := counts()
+	AssertEqual(t, count, 3)
+	AssertEqual(t, archived, 1)
+
+	// batch deletes
+	if err := DB.Delete(orders[1:3]).Error; err != nil {
+		t.Fatalf("failed to delete, got error %v", err)
+	}
+	count, archived = counts()
+	AssertEqual(t, count, 1)
+	AssertEqual(t, archived, 3)
+
+	if err := DB.Delete(&ArchivedOrder{}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("expected ErrMissingWhereClause, got %v", err)
+	}
+	count, archived = counts()
+	AssertEqual(t, archived, 3)
+
+	var result []ArchivedOrder
+	DB.Unscoped().Order("id").Find(&result)
+	AssertEqual(t, len(result), 1)
+
+	DB.Unscoped().Clauses(gorm.WithArchive).Order("id").Find(&result)
+	if len(result) != 4 {
+		t.Fatalf("expected archived rows with WithArchive, got %v", len(result))
+	}
+	for idx, order := range result {
+		AssertEqual(t, order.Name, orders[idx].Name)
+		AssertEqual(t, order.ArchivedAt.Valid, idx < 3)
+	}
+
+	var found ArchivedOrder
+	DB.Unscoped().Clauses(gorm.WithArchive).Where("amount = ?", 2).First(&found)
+	AssertEqual(t, found.Name, "order2")
+
+	// restore by ids and by model
+	if result := DB.Restore(&ArchivedOrder{}, orders[0].ID); result.Error != nil || result.RowsAffected != 1 {
+		t.Fatalf("failed to restore, got %v, error %v", result.RowsAffected, result.Error)
+	}
+	if err := DB.Restore(&orders[1]).Error; err != nil {
+		t.Fatalf("failed to restore, got error %v", err)
+	}
+	count, archived = counts()
+	AssertEqual(t, count, 3)
+	AssertEqual(t, archived, 1)
+
+	restoreStatements := DB.ToSQLStatements(func(tx *gorm.DB) *gorm.DB {
+		return tx.Restore(&ArchivedOrder{}, orders[2].ID)
+	})
+	if len(restoreStatements) != 2 || !strings.HasPrefix(restoreStatements[1].SQL, "DELETE FROM") ||
+		strings.Contains(restoreStatements[1].SQL, " AS ") || !strings.Contains(restoreStatements[1].SQL, "archived_orders_archive") {
+		t.Errorf("the archived rows should be deleted from the archive table without alias, got %v", restoreStatements)
+	}
+
+	found = ArchivedOrder{}
+	DB.First(&found, orders[0].ID)
+	AssertEqual(t, found.Name, "order1")
+	AssertEqual(t, found.ArchivedAt.Valid, false)
+
+	// rows could be archived again
+	DB.Delete(&orders[0])
+	count, archived = counts()
+	AssertEqual(t, count, 2)
+	AssertEqual(t, archived, 2)
+
+	// hard delete
+	DB.Unscoped().Delete(&orders[3])
+	count, archived = counts()
+	AssertEqual(t, count, 1)
+	AssertEqual(t, archived, 2)
+
+	statements := DB.Session(&gorm.Session{DryRun: true}).Delete(&orders[1]).Statement.SQLStatements()
+	if len(statements) != 2 || !strings.HasPrefix(statements[0].SQL, "INSERT INTO") || !strings.HasPrefix(statements[1].SQL, "DELETE FROM") {
+		t.Errorf("expected archiving before deleting, got %v", statements)
+	}
+
+	// the rows archived without the default transaction are rolled back if failed to delete them
+	tx := DB.Session(&gorm.Session{Context: context.Background(), SkipDefaultTransaction: true})
+	tx.Statement.ConnPool = failingDeleteConnPool{ConnPool: tx.Statement.ConnPool}
+	if err := tx.Delete(&orders[1]).Error; err == nil || !strings.Contains(err.Error(), "failed to delete") {
+		t.Errorf("expected the error of the delete, got %v", err)
+	}
+	count, archived = counts()
+	AssertEqual(t, count, 1)
+	AssertEqual(t, archived, 2)
+
+	if err := DB.Restore(&Company{}, 1).Error; !errors.Is(err, gorm.ErrNotSoftDeleted) {
+		t.Errorf("expected ErrNotSoftDeleted restoring models not soft deleted, got %v", err)
+	}
+}
+
+func TestArchiveDeleteInBatches(t *testing.T) {
+	DB.Migrator().DropTable(&ArchivedOrder{}, "archived_orders_archive")
+	if err := DB.AutoMigrate(&ArchivedOrder{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	orders := make([]ArchivedOrder, 5)
+	for i := range orders {
+		orders[i] = ArchivedOrder{Name: fmt.Sprintf("batch_order%d", i), Amount: 10}
+	}
+	DB.Create(&orders)
+
+	result := DB.Where("amount = ?", 10).Order("id").DeleteInBatches(&ArchivedOrder{}, 2, nil)
+	if result.Error != nil || result.RowsAffected != 5 {
+		t.Fatalf("failed to delete in batches, got %v, error %v", result.RowsAffected, result.Error)
+	}
+
+	var count, archived int64
+	DB.Model(&ArchivedOrder{}).Count(&count)
+	DB.Table("archived_orders_archive").Count(&archived)
+	AssertEqual(t, count, 0)
+	AssertEqual(t, archived, 5)
+
+	var names []string
+	DB.Table("archived_orders_archive").Order("id").Pluck("name", &names)
+	for i, name := range names {
+		AssertEqual(t, name, orders[i].Name)
+	}
+}
+
+// failingDeleteConnPool fails the DELETE statements, in the transactions begun by it as well
+type failingDeleteConnPool struct {
+	gorm.ConnPool
+}
+
+func (p failingDeleteConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if strings.HasPrefix(query, "DELETE") {
+		return nil, errors.New("failed to delete")
+	}
+	return p.ConnPool.ExecContext(ctx, query, args...)
+}
+
+func (p failingDeleteConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	tx, err := p.ConnPool.(gorm.TxBeginner).BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &failingDeleteTx{Tx: tx}, nil
+}
+
+type failingDeleteTx struct {
+	*sql.Tx
+}
+
+func (tx *failingDeleteTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if strings.HasPrefix(query, "DELETE") {
+		return nil, errors.New("failed to delete")
+	}
+	return tx.Tx.ExecContext(ctx, query, args...)
+}
+
+type SoftDeleteFlagItem struct {
+	ID        uint
+	Name      string
+	Deleted   bool   `gorm:"softDelete:flag"`
+	DeletedBy string `gorm:"softDeleteBy"`
+}
+
+type SoftDeleteMilliItem struct {
+	ID        uint
+	Name      string `gorm:"uniqueIndex:idx_soft_delete_milli_items_name"`
+	DeletedAt int64  `gorm:"softDelete:milli;uniqueIndex:idx_soft_delete_milli_items_name"`
+}
+
+type SoftDeleteNanoItem struct {
+	ID      uint
+	Name    string
+	Deleted int64 `gorm:"softDelete:nano"`
+}
+
+type softDeleteByKey struct{}
+
+func TestSoftDeleteModes(t *testing.T) {
+	DB.Migrator().DropTable(&SoftDeleteFlagItem{}, &SoftDeleteMilliItem{}, &SoftDeleteNanoItem{})
+	if err := DB.AutoMigrate(&SoftDeleteFlagItem{}, &SoftDeleteMilliItem{}, &SoftDeleteNanoItem{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	nowTime := time.Now()
+	db := DB.Session(&gorm.Session{NowFunc: func() time.Time {
+		nowTime = nowTime.Add(time.Millisecond)
+		return nowTime
+	}})
+	db.Config.SoftDeleteByContextKey = softDeleteByKey{}
+
+	// flag with deleted by
+	items := []SoftDeleteFlagItem{{Name: "flag1"}, {Name: "flag2"}}
+	db.Create(&items)
+
+	ctx := context.WithValue(context.Background(), softDeleteByKey{}, "jinzhu")
+	if err := db.WithContext(ctx).Delete(&items[0]).Error; err != nil {
+		t.Fatalf("failed to soft delete, got error %v", err)
+	}
+	AssertEqual(t, items[0].Deleted, true)
+	AssertEqual(t, items[0].DeletedBy, "jinzhu")
+
+	var count int64
+	db.Model(&SoftDeleteFlagItem{}).Where("name LIKE ?", "flag%").Count(&count)
+	AssertEqual(t, count, 1)
+	AssertEqual(t, db.Model(&SoftDeleteFlagItem{ID: items[0].ID}).Update("name", "flag0").RowsAffected, int64(0))
+
+	var deleted SoftDeleteFlagItem
+	db.Unscoped().First(&deleted, items[0].ID)
+	AssertEqual(t, deleted, items[0])
+
+	if result := db.Restore(&items[0]); result.Error != nil || result.RowsAffected != 1 {
+		t.Fatalf("failed to restore, got %v, error %v", result.RowsAffected, result.Error)
+	}
+	var restored SoftDeleteFlagItem
+	db.First(&restored, items[0].ID)
+	AssertEqual(t, restored.Deleted, false)
+	AssertEqual(t, restored.DeletedBy, "")
+
+	if err := db.Delete(&SoftDeleteFlagItem{}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("expected ErrMissingWhereClause, got %v", err)
+	}
+
+	// timestamps usable in unique indexes
+	for i := 0; i < 2; i++ {
+		item := SoftDeleteMilliItem{Name: "milli"}
+		if err := db.Create(&item).Error; err != nil {
+			t.Fatalf("failed to create with the name of deleted rows, got error %v", err)
+		}
+		db.Delete(&item)
+		AssertEqual(t, item.DeletedAt, nowTime.UnixMilli())
+	}
+	db.Model(&SoftDeleteMilliItem{}).Count(&count)
+	AssertEqual(t, count, 0)
+	db.Unscoped().Model(&SoftDeleteMilliItem{}).Count(&count)
+	AssertEqual(t, count, 2)
+
+	nanoItem := SoftDeleteNanoItem{Name: "nano"}
+	db.Create(&nanoItem)
+	db.Where("name = ?", "nano").Delete(&SoftDeleteNanoItem{})
+	var nanoItems []SoftDeleteNanoItem
+	db.Unscoped().Find(&nanoItems)
+	if len(nanoItems) != 1 || nanoItems[0].Deleted != nowTime.UnixNano() {
+		t.Errorf("expected deleted in unix nanoseconds, got %+v", nanoItems)
+	}
+
+	db.Restore(&SoftDeleteNanoItem{}, nanoItem.ID)
+	db.Find(&nanoItems)
+	AssertEqual(t, len(nanoItems), 1)
+}
+
+type RestoreAuthor struct {
+	ID            uint
+	Name          string
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt
+	Posts         []RestorePost
+	beforeRestore int
+	afterRestore  int
+}
+
+func (a *RestoreAuthor) BeforeRestore(tx *gorm.DB) error {
+	if a.Name == "locked" {
+		return errors.New("can't restore locked authors")
+	}
+	a.beforeRestore++
+	return nil
+}
+
+func (a *RestoreAuthor) AfterRestore(tx *gorm.DB) error {
+	a.afterRestore++
+	return nil
+}
+
+type RestorePost struct {
+	ID              uint
+	RestoreAuthorID uint
+	Title           string
+	DeletedAt       gorm.DeletedAt
+}
+
+func TestRestore(t *testing.T) {
+	DB.Migrator().DropTable(&RestoreAuthor{}, &RestorePost{})
+	if err := DB.AutoMigrate(&RestoreAuthor{}, &RestorePost{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	authors := []RestoreAuthor{
+		{Name: "restore-1", Posts: []RestorePost{{Title: "post-1"}, {Title: "post-2"}}},
+		{Name: "restore-2", Posts: []RestorePost{{Title: "post-3"}}},
+		{Name: "locked"},
+	}
+	DB.Create(&authors)
+	DB.Select(clause.Associations).Delete(&authors)
+
+	var count int64
+	DB.Model(&RestorePost{}).Count(&count)
+	AssertEqual(t, count, 0)
+
+	updatedAt := authors[0].UpdatedAt
+	result := DB.Restore(&authors[0])
+	if result.Error != nil || result.RowsAffected != 1 {
+		t.Fatalf("failed to restore, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+	AssertEqual(t, authors[0].beforeRestore, 1)
+	AssertEqual(t, authors[0].afterRestore, 1)
+	if authors[0].DeletedAt.Valid || !authors[0].UpdatedAt.After(updatedAt) {
+		t.Errorf("expected restored values set, got %+v", authors[0])
+	}
+
+	var author RestoreAuthor
+	if err := DB.First(&author, authors[0].ID).Error; err != nil {
+		t.Errorf("failed to find the restored author, got error %v", err)
+	}
+	DB.Model(&RestorePost{}).Count(&count)
+	AssertEqual(t, count, 0)
+
+	// restoring again restores nothing without ErrRecordNotFound
+	if result := DB.Restore(&authors[0]); result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("expected nothing restored, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+
+	if err := DB.Restore(&authors[2]).Error; err == nil {
+		t.Errorf("expected error returned by BeforeRestore")
+	}
+	author = RestoreAuthor{}
+	DB.Unscoped().First(&author, authors[2].ID)
+	AssertEqual(t, author.DeletedAt.Valid, true)
+
+	result = DB.Unscoped().Select(clause.Associations).Where("name LIKE ?", "restore-%").Restore(&RestoreAuthor{})
+	if result.Error != nil || result.RowsAffected != 1 {
+		t.Fatalf("failed to restore with conditions, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+	var posts []RestorePost
+	DB.Order("id").Find(&posts)
+	if len(posts) != 1 || posts[0].Title != "post-3" {
+		t.Errorf("expected the posts of the restored authors restored, got %+v", posts)
+	}
+
+	if err := DB.Restore(&RestoreAuthor{}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("expected ErrMissingWhereClause, got %v", err)
+	}
+
+	if err := DB.Restore(&User{Model: gorm.Model{ID: 1}}).Error; err != nil {
+		t.Errorf("failed to restore models soft deleted by gorm.Model, got error %v", err)
+	}
+}
+
+func TestUnscopedTables(t *testing.T) {
+	user := *GetUser("unscoped-tables", Config{Account: true, Pets: 2})
+	deletedUser := *GetUser("unscoped-tables-deleted", Config{Account: true})
+	DB.Create(&user)
+	DB.Create(&deletedUser)
+	DB.Delete(&user.Account)
+	DB.Delete(user.Pets[0])
+	DB.Delete(&deletedUser)
+
+	var result User
+	DB.Joins("Account").First(&result, user.ID)
+	AssertEqual(t, result.Account.ID, uint(0))
+
+	result = User{}
+	DB.Joins("Account", DB.Unscoped()).First(&result, user.ID)
+	AssertEqual(t, result.Account.ID, user.Account.ID)
+
+	var users []User
+	DB.UnscopedTables("accounts").Joins("Account").Where("users.name LIKE ?", "unscoped-tables%").Find(&users)
+	if len(users) != 1 || users[0].Account.ID != user.Account.ID {
+		t.Errorf("expected the deleted accounts of the users not deleted, got %+v", users)
+	}
+
+	users = nil
+	DB.UnscopedTables("users").Joins("Account").Where("users.name LIKE ?", "unscoped-tables%").Order("users.id").Find(&users)
+	if len(users) != 2 || users[0].Account.ID != 0 || users[1].Account.ID != deletedUser.Account.ID {
+		t.Errorf("expected the deleted users with the accounts not deleted, got %+v", users)
+	}
+
+	result = User{}
+	DB.Preload("Pets").First(&result, user.ID)
+	AssertEqual(t, len(result.Pets), 1)
+
+	result = User{}
+	DB.UnscopedTables("pets").Preload("Pets").First(&result, user.ID)
+	AssertEqual(t, len(result.Pets), 2)
+
+	result = User{}
+	DB.Preload("Pets", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).First(&result, user.ID)
+	AssertEqual(t, len(result.Pets), 2)
+
+	AssertEqual(t, DB.Model(&user).Association("Pets").Count(), int64(1))
+	AssertEqual(t, DB.UnscopedTables("pets").Model(&user).Association("Pets").Count(), int64(2))
+}
+
+type SoftDeletedSubscriber struct {
+	ID            uint
+	Email         string `gorm:"size:100;uniqueIndex:idx_soft_deleted_subscribers_email,where:deleted_at IS NULL"`
+	Name          string
+	DeletedAt     gorm.DeletedAt
+	beforeCreate  int
+	beforeUpdate  int
+	beforeRestore int
+}
+
+func (s *SoftDeletedSubscriber) BeforeCreate(*gorm.DB) error {
+	s.beforeCreate++
+	return nil
+}
+
+func (s *SoftDeletedSubscriber) BeforeUpdate(tx *gorm.DB) error {
+	s.beforeUpdate++
+	if assigns, ok := tx.Statement.Dest.(map[string]interface{}); ok && assigns["name"] == "fail" {
+		return errors.New("failed to update")
+	}
+	return nil
+}
+
+func (s *SoftDeletedSubscriber) BeforeRestore(*gorm.DB) error {
+	s.beforeRestore++
+	return nil
+}
+
+func TestFirstOrCreateWithSoftDeleted(t *testing.T) {
+	DB.Migrator().DropTable(&SoftDeletedSubscriber{})
+	if err := DB.AutoMigrate(&SoftDeletedSubscriber{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	deleted := SoftDeletedSubscriber{Email: "restore@example.org", Name: "old"}
+	DB.Create(&deleted)
+	DB.Delete(&deleted)
+
+	// the soft deleted row isn't found by default, the partial unique index allows the duplicate
+	var created SoftDeletedSubscriber
+	if err := DB.Where(SoftDeletedSubscriber{Email: "restore@example.org"}).FirstOrCreate(&created).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+	if created.ID == deleted.ID || created.beforeCreate != 1 {
+		t.Errorf("expected a new row created, got %+v", created)
+	}
+	DB.Delete(&created)
+
+	// the soft deleted row is found and kept soft deleted
+	var included SoftDeletedSubscriber
+	result := DB.Where(SoftDeletedSubscriber{Email: "restore@example.org"}).Assign(SoftDeletedSubscriber{Name: "included"}).
+		WithSoftDeleted(gorm.SoftDeletedIncluded).FirstOrCreate(&included)
+	if result.Error != nil || result.RowsAffected != 1 {
+		t.Fatalf("failed to update the soft deleted row, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+	if included.ID != deleted.ID || !included.DeletedAt.Valid || included.beforeCreate != 0 || included.beforeUpdate != 1 {
+		t.Errorf("expected the soft deleted row found and updated, got %+v", included)
+	}
+
+	var found SoftDeletedSubscriber
+	DB.Unscoped().First(&found, deleted.ID)
+	if found.Name != "included" || !found.DeletedAt.Valid {
+		t.Errorf("expected the soft deleted row updated and kept soft deleted, got %+v", found)
+	}
+
+	// the soft deleted row is restored with Assign
+	var restored SoftDeletedSubscriber
+	result = DB.Where(SoftDeletedSubscriber{Email: "restore@example.org"}).Assign(SoftDeletedSubscriber{Name: "restored"}).
+		WithSoftDeleted(gorm.RestoreOnConflict).FirstOrCreate(&restored)
+	if result.Error != nil || result.RowsAffected != 1 {
+		t.Fatalf("failed to restore, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+	if restored.ID != deleted.ID || restored.DeletedAt.Valid || restored.Name != "restored" ||
+		restored.beforeCreate != 0 || restored.beforeRestore != 1 || restored.beforeUpdate != 1 {
+		t.Errorf("expected the soft deleted row restored and updated, got %+v", restored)
+	}
+
+	found = SoftDeletedSubscriber{}
+	if err := DB.First(&found, deleted.ID).Error; err != nil || found.Name != "restored" {
+		t.Errorf("expected the restored row found, got %+v, error %v", found, err)
+	}
+
+	var count int64
+	DB.Model(&SoftDeletedSubscriber{}).Where("email = ?", "restore@example.org").Count(&count)
+	AssertEqual(t, count, 1)
+
+	// the row not deleted is found as is
+	var existing SoftDeletedSubscriber
+	result = DB.Where(SoftDeletedSubscriber{Email: "restore@example.org"}).WithSoftDeleted(gorm.RestoreOnConflict).FirstOrCreate(&existing)
+	if result.Error != nil || result.RowsAffected != 0 || existing.ID != deleted.ID || existing.beforeRestore != 0 {
+		t.Errorf("expected the row found as is, got %+v, error %v", existing, result.Error)
+	}
+
+	// the restoring is rolled back if the updating with Assign failed
+	DB.Delete(&existing)
+	var failed SoftDeletedSubscriber
+	if err := DB.Where(SoftDeletedSubscriber{Email: "restore@example.org"}).Assign(map[string]interface{}{"name": "fail"}).
+		WithSoftDeleted(gorm.RestoreOnConflict).FirstOrCreate(&failed).Error; err == nil {
+		t.Errorf("expected error returned by BeforeUpdate")
+	}
+
+	found = SoftDeletedSubscriber{}
+	if err := DB.Unscoped().First(&found, deleted.ID).Error; err != nil || !found.DeletedAt.Valid {
+		t.Errorf("expected the restoring rolled back, got %+v, error %v", found, err)
+	}
+
+	// the soft deleted row is restored without Assign
+	var restoredOnly SoftDeletedSubscriber
+	result = DB.Where(SoftDeletedSubscriber{Email: "restore@example.org"}).WithSoftDeleted(gorm.RestoreOnConflict).FirstOrCreate(&restoredOnly)
+	if result.Error != nil || result.RowsAffected != 1 || restoredOnly.DeletedAt.Valid || restoredOnly.beforeUpdate != 0 {
+		t.Errorf("expected the soft deleted row restored, got %+v, error %v", restoredOnly, result.Error)
+	}
+
+	var newOne SoftDeletedSubscriber
+	result = DB.Where(SoftDeletedSubscriber{Email: "new@example.org"}).WithSoftDeleted(gorm.RestoreOnConflict).FirstOrCreate(&newOne)
+	if result.Error != nil || result.RowsAffected != 1 || newOne.ID == 0 || newOne.beforeCreate != 1 {
+		t.Errorf("expected a new row created, got %+v, error %v", newOne, result.Error)
+	}
+
+	// FirstOrInit finds the soft deleted rows without writing
+	DB.Delete(&newOne)
+	var initialized SoftDeletedSubscriber
+	if err := DB.Where(SoftDeletedSubscriber{Email: "new@example.org"}).WithSoftDeleted(gorm.RestoreOnConflict).FirstOrInit(&initialized).Error; err != nil ||
+		initialized.ID != newOne.ID || !initialized.DeletedAt.Valid {
+		t.Errorf("expected the soft deleted row found, got %+v, error %v", initialized, err)
+	}
+}
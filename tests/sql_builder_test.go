@@ -1,13 +1,16 @@
 package tests_test
 
 import (
+	"errors"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/utils"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -168,6 +171,133 @@ func TestDryRun(t *testing.T) {
 	}
 }
 
+type DryRunProduct struct {
+	ID    uint
+	Name  string
+	Hooks int `gorm:"-"`
+}
+
+func (p *DryRunProduct) BeforeCreate(tx *gorm.DB) error {
+	p.Hooks++
+	return nil
+}
+
+func (p *DryRunProduct) AfterCreate(tx *gorm.DB) error {
+	p.Hooks++
+	return nil
+}
+
+func TestDryRunStatements(t *testing.T) {
+	dryRunDB := DB.Session(&gorm.Session{DryRun: true})
+
+	user := *GetUser("dry-run-statements", Config{Company: true, Pets: 2})
+	result := dryRunDB.Create(&user)
+	if result.Error != nil || result.RowsAffected != 0 {
+		t.Fatalf("dry run create should succeed without affecting rows, got %v, %v", result.RowsAffected, result.Error)
+	}
+
+	var tables []string
+	for _, s := range result.Statement.SQLStatements() {
+		if !strings.HasPrefix(s.SQL, "INSERT INTO ") {
+			t.Errorf("expected insert statement, got %v", s.SQL)
+		}
+		tables = append(tables, strings.Trim(strings.Fields(s.SQL)[2], "`\"[]"))
+	}
+	AssertEqual(t, tables, []string{"companies", "users", "pets"})
+	AssertEqual(t, result.Statement.SQLStatements()[1].SQL, result.Statement.SQL.String())
+
+	var count int64
+	DB.Model(&User{}).Where("name = ?", user.Name).Count(&count)
+	AssertEqual(t, count, 0)
+
+	// hooks are skipped unless DryRunWithHooks
+	product := DryRunProduct{Name: "dry-run"}
+	dryRunDB.Create(&product)
+	AssertEqual(t, product.Hooks, 0)
+	DB.Session(&gorm.Session{DryRun: true, DryRunWithHooks: true}).Create(&product)
+	AssertEqual(t, product.Hooks, 2)
+
+	// preload
+	users := []User{{Model: gorm.Model{ID: 1}}, {Model: gorm.Model{ID: 2}}}
+	result = dryRunDB.Preload("Pets").Find(&users)
+	if statements := result.Statement.SQLStatements(); len(statements) != 2 || !strings.Contains(statements[1].SQL, "pets") || len(statements[1].Vars) != 2 {
+		t.Errorf("expected the statements of query and preload, got %v", statements)
+	}
+
+	// raw sql
+	var name string
+	result = dryRunDB.Raw("SELECT name FROM users WHERE id = ?", 1).Scan(&name)
+	if result.Error != nil || len(result.Statement.SQLStatements()) != 1 {
+		t.Errorf("dry run scan should succeed, got %v, %v", result.Statement.SQLStatements(), result.Error)
+	}
+
+	rows, err := dryRunDB.Table("users").Where("id = ?", 1).Rows()
+	var dryRunErr *gorm.DryRunError
+	if rows != nil || !errors.Is(err, gorm.ErrDryRunModeUnsupported) || !errors.As(err, &dryRunErr) {
+		t.Fatalf("expected DryRunError, got %v", err)
+	}
+	if !strings.Contains(dryRunErr.SQL, "users") || len(dryRunErr.Vars) != 1 {
+		t.Errorf("expected DryRunError with the generated statement, got %#v", dryRunErr)
+	}
+}
+
+func TestToSQLs(t *testing.T) {
+	user := *GetUser("to-sqls", Config{Company: true, Pets: 2, Languages: 1})
+	user.ID = 1
+	// the SQL is deterministic with the same time
+	now := time.Now()
+	db := DB.Session(&gorm.Session{NowFunc: func() time.Time { return now }})
+
+	tables := func(sqls []string) (results []string) {
+		for _, sql := range sqls {
+			fields := strings.Fields(sql)
+			if len(fields) > 2 && fields[0] == "INSERT" {
+				results = append(results, fields[0]+" "+strings.Trim(fields[2], "`\"[]"))
+			} else if len(fields) > 1 {
+				results = append(results, fields[0]+" "+strings.Trim(fields[1], "`\"[]"))
+			}
+		}
+		return results
+	}
+
+	saveFn := func(tx *gorm.DB) *gorm.DB {
+		return tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(&user)
+	}
+
+	sqls := db.ToSQLs(saveFn)
+	AssertEqual(t, tables(sqls), []string{
+		"INSERT companies", "UPDATE users", "INSERT pets", "INSERT languages", "INSERT user_speaks",
+	})
+
+	if !strings.Contains(sqls[1], user.Name) {
+		t.Errorf("the SQL should be explained with the vars, got %v", sqls[1])
+	}
+
+	for i := 0; i < 3; i++ {
+		AssertEqual(t, db.ToSQLs(saveFn), sqls)
+	}
+
+	statements := db.ToSQLStatements(saveFn)
+	if len(statements) != len(sqls) || len(statements[1].Vars) == 0 {
+		t.Fatalf("expected the statements with vars, got %v", statements)
+	}
+
+	for idx, statement := range statements {
+		AssertEqual(t, DB.Dialector.Explain(statement.SQL, statement.Vars...), sqls[idx])
+	}
+
+	// the statements of all the operations and the preloads
+	sqls = db.ToSQLs(func(tx *gorm.DB) *gorm.DB {
+		tx.Model(&User{}).Where("id = ?", user.ID).Update("name", "to-sqls-updated")
+		return tx.Preload("Pets").Find(&[]User{user})
+	})
+	AssertEqual(t, tables(sqls), []string{"UPDATE users", "SELECT *", "SELECT *"})
+
+	var count int64
+	DB.Model(&User{}).Where("name = ?", user.Name).Or("name = ?", "to-sqls-updated").Count(&count)
+	AssertEqual(t, count, 0)
+}
+
 type ageInt int8
 
 func (ageInt) String() string {
@@ -497,3 +627,309 @@ func replaceQuoteInSQL(sql string) string {
 
 	return sql
 }
+
+func TestStatementFingerprint(t *testing.T) {
+	dryRunDB := DB.Session(&gorm.Session{DryRun: true})
+
+	stmt := dryRunDB.Where("id IN ?", []int{1, 2}).Where("name = ?", "jinzhu").Find(&User{}).Statement
+	stmt2 := dryRunDB.Where("id IN ?", []int{1, 2, 3, 4}).Where("name = ?", "fingerprint").Find(&User{}).Statement
+	if stmt.Fingerprint() != stmt2.Fingerprint() {
+		t.Errorf("fingerprints of different IN lists should be the same, got %v, %v", stmt.SQL.String(), stmt2.SQL.String())
+	}
+
+	explained := DB.Dialector.Explain(stmt.SQL.String(), stmt.Vars...)
+	if fingerprint := utils.SQLFingerprint(explained); fingerprint != stmt.Fingerprint() {
+		t.Errorf("fingerprint of explained SQL %v should be the same as the statement", explained)
+	}
+
+	stmt3 := dryRunDB.Where("id IN ?", []int{1, 2}).Where("age = ?", 18).Find(&User{}).Statement
+	if stmt3.Fingerprint() == stmt.Fingerprint() {
+		t.Errorf("fingerprints of different queries should be different")
+	}
+}
+
+func TestVarBindings(t *testing.T) {
+	dryRunDB := DB.Session(&gorm.Session{DryRun: true})
+	if stmt := dryRunDB.Where(&User{Name: "jinzhu"}).Find(&User{}).Statement; stmt.VarBindings() != nil {
+		t.Fatalf("var bindings should be nil when not tracked, got %v", stmt.VarBindings())
+	}
+
+	dryRunDB.Config.TrackVarBindings = true
+
+	stmt := dryRunDB.Where(&User{Name: "jinzhu"}).Where("age > ?", 18).Clauses(clause.IN{Column: "id", Values: []interface{}{1, 2}}).Find(&User{}).Statement
+	bindings := stmt.VarBindings()
+	if len(bindings) != len(stmt.Vars) || len(bindings) != 4 {
+		t.Fatalf("var bindings should match vars %v, got %v", stmt.Vars, bindings)
+	}
+
+	if b := bindings[0]; b == nil || b.Column.Name != "name" || b.ClauseName != "WHERE" || b.Index != 0 {
+		t.Errorf("var binding of name is incorrect, got %+v", b)
+	}
+
+	if bindings[1] != nil {
+		t.Errorf("var binding of raw SQL should be nil, got %+v", bindings[1])
+	}
+
+	for idx := 2; idx < 4; idx++ {
+		if b := bindings[idx]; b == nil || b.Column.Name != "id" || b.Index != idx {
+			t.Errorf("var binding of IN is incorrect, got %+v", b)
+		}
+	}
+
+	stmt = dryRunDB.Model(&User{}).Where("id = ?", 1).Updates(map[string]interface{}{"name": "jinzhu", "age": gorm.Expr("age + ?", 1)}).Statement
+	bindings = stmt.VarBindings()
+	if len(bindings) != len(stmt.Vars) {
+		t.Fatalf("var bindings should match vars %v, got %v", stmt.Vars, bindings)
+	}
+
+	columns := map[string]bool{}
+	for idx, b := range bindings {
+		if b == nil {
+			continue
+		}
+
+		if b.ClauseName != "SET" || b.Index != idx {
+			t.Errorf("var binding of SET is incorrect, got %+v", b)
+		}
+		columns[b.Column.Name] = true
+	}
+
+	if !columns["name"] || !columns["updated_at"] || columns["age"] || len(columns) != 2 {
+		t.Errorf("var bindings of SET should be name and updated_at, got %v", columns)
+	}
+
+	user := *GetUser("var_bindings", Config{})
+	stmt = dryRunDB.Create(&user).Statement
+	bindings = stmt.VarBindings()
+	if len(bindings) != len(stmt.Vars) || len(bindings) == 0 {
+		t.Fatalf("var bindings should match vars %v, got %v", stmt.Vars, bindings)
+	}
+
+	for idx, b := range bindings {
+		if b == nil || b.ClauseName != "VALUES" || b.Index != idx {
+			t.Errorf("var binding of VALUES is incorrect, got %+v", b)
+		} else if b.Column.Name == "name" && stmt.Vars[idx] != "var_bindings" {
+			t.Errorf("var of name should be var_bindings, got %v", stmt.Vars[idx])
+		}
+	}
+}
+
+func TestExprColumnVars(t *testing.T) {
+	var (
+		dryRunDB = DB.Session(&gorm.Session{DryRun: true})
+		name     = clause.Col("name")
+		quoted   = func(column string) string { return dryRunDB.Statement.Quote(clause.Col(column)) }
+	)
+
+	results := []struct {
+		Name string
+		Stmt *gorm.Statement
+		SQL  string
+		Vars int
+	}{{
+		Name: "select",
+		Stmt: dryRunDB.Model(&User{}).Select("lower(?) AS name, ?", name, &clause.Column{Name: "age"}).Find(&[]User{}).Statement,
+		SQL:  "SELECT lower(" + quoted("name") + ") AS name, " + quoted("age") + " FROM",
+	}, {
+		Name: "select expression",
+		Stmt: dryRunDB.Model(&User{}).Select(gorm.Expr("max(?)", name)).Find(&[]User{}).Statement,
+		SQL:  "SELECT max(" + quoted("name") + ") FROM",
+	}, {
+		Name: "order",
+		Stmt: dryRunDB.Order(gorm.Expr("lower(?) DESC", name)).Find(&[]User{}).Statement,
+		SQL:  "ORDER BY lower(" + quoted("name") + ") DESC",
+	}, {
+		Name: "group",
+		Stmt: dryRunDB.Model(&User{}).Select("lower(name)").Group("lower(?)", name).Find(&[]map[string]interface{}{}).Statement,
+		SQL:  "GROUP BY lower(" + quoted("name") + ")",
+	}, {
+		Name: "having",
+		Stmt: dryRunDB.Model(&User{}).Select("name").Group("name").Having("count(?) > ?", name, 1).Find(&[]map[string]interface{}{}).Statement,
+		SQL:  "HAVING count(" + quoted("name") + ") > ",
+		Vars: 1,
+	}, {
+		Name: "joins on",
+		Stmt: dryRunDB.Joins("JOIN pets ON ? = ? AND ? <> ?", clause.ColTable("pets", "user_id"), clause.ColTable("users", "id"), clause.ColTable("pets", "name"), "").Find(&[]User{}).Statement,
+		SQL:  "JOIN pets ON " + dryRunDB.Statement.Quote(clause.ColTable("pets", "user_id")) + " = " + dryRunDB.Statement.Quote(clause.ColTable("users", "id")) + " AND " + dryRunDB.Statement.Quote(clause.ColTable("pets", "name")) + " <> ",
+		Vars: 1,
+	}, {
+		Name: "update set",
+		Stmt: dryRunDB.Model(&User{}).Where("id = ?", 1).UpdateColumn("name", gorm.Expr("lower(?)", name)).Statement,
+		SQL:  "SET " + quoted("name") + "=lower(" + quoted("name") + ") WHERE",
+		Vars: 1,
+	}, {
+		Name: "where",
+		Stmt: dryRunDB.Where("lower(?) = ?", &clause.Column{Name: "name"}, "jinzhu").Find(&[]User{}).Statement,
+		SQL:  "WHERE lower(" + quoted("name") + ") = ",
+		Vars: 1,
+	}}
+
+	for _, result := range results {
+		t.Run(result.Name, func(t *testing.T) {
+			if result.Stmt.Error != nil {
+				t.Fatalf("failed to build SQL, got error %v", result.Stmt.Error)
+			}
+
+			if sql := result.Stmt.SQL.String(); !strings.Contains(sql, result.SQL) {
+				t.Errorf("SQL %v should contain %v", sql, result.SQL)
+			}
+
+			if len(result.Stmt.Vars) != result.Vars {
+				t.Errorf("columns shouldn't be vars, got %v", result.Stmt.Vars)
+			}
+		})
+	}
+
+	if err := dryRunDB.Model(&User{}).Group("?", 1).Find(&[]User{}).Error; !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("group by non column vars should return ErrInvalidData, got %v", err)
+	}
+
+	stmt := dryRunDB.Where("data ?? ? AND ? = ?", "email", name, "jinzhu").Find(&[]User{}).Statement
+	if sql := stmt.SQL.String(); strings.Contains(sql, "??") || !strings.Contains(sql, "data ? ") || len(stmt.Vars) != 2 {
+		t.Errorf("escaped question mark should be written as is, got %v %v", sql, stmt.Vars)
+	}
+}
+
+func TestClauseBuilderOverrides(t *testing.T) {
+	limitBuilder := func(comment string) clause.ClauseBuilder {
+		return func(c clause.Clause, builder clause.Builder) {
+			if limit, ok := c.Expression.(clause.Limit); ok && limit.Limit != nil {
+				builder.WriteString("LIMIT " + strconv.Itoa(*limit.Limit) + " /* " + comment + " */")
+			}
+		}
+	}
+
+	db, _ := gorm.Open(DB.Dialector, &gorm.Config{})
+	db.ClauseBuilders["LIMIT"] = limitBuilder("config")
+
+	toSQL := func(tx *gorm.DB) string {
+		return tx.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Model(&User{}).Limit(10).Find(&[]User{})
+		})
+	}
+
+	if sql := toSQL(db); !strings.HasSuffix(sql, "LIMIT 10 /* config */") {
+		t.Errorf("LIMIT should be built by the clause builder of config, got %v", sql)
+	}
+
+	sessionDB := db.Session(&gorm.Session{ClauseBuilders: map[string]clause.ClauseBuilder{"LIMIT": limitBuilder("session")}})
+	if sql := toSQL(sessionDB); !strings.HasSuffix(sql, "LIMIT 10 /* session */") {
+		t.Errorf("LIMIT should be built by the clause builder of session, got %v", sql)
+	}
+
+	if sql := toSQL(sessionDB.WithClauseBuilder("LIMIT", limitBuilder("statement"))); !strings.HasSuffix(sql, "LIMIT 10 /* statement */") {
+		t.Errorf("LIMIT should be built by the clause builder of statement, got %v", sql)
+	}
+
+	// the overrides don't affect the db
+	if sql := toSQL(db); !strings.HasSuffix(sql, "LIMIT 10 /* config */") {
+		t.Errorf("LIMIT should be built by the clause builder of config, got %v", sql)
+	}
+
+	if sql := toSQL(DB.WithClauseBuilder("LIMIT", limitBuilder("statement"))); !strings.HasSuffix(sql, "LIMIT 10 /* statement */") {
+		t.Errorf("LIMIT should be built by the clause builder of statement, got %v", sql)
+	}
+
+	if sql := toSQL(DB); strings.Contains(sql, "/*") {
+		t.Errorf("LIMIT should be built by default, got %v", sql)
+	}
+
+	users := []User{*GetUser("clause_builder_1", Config{}), *GetUser("clause_builder_2", Config{})}
+	DB.Create(&users)
+
+	var results []User
+	if err := DB.WithClauseBuilder("LIMIT", limitBuilder("legacy")).Where("name LIKE ?", "clause_builder_%").
+		Limit(1).Find(&results).Error; err != nil || len(results) != 1 {
+		t.Errorf("failed to query with the clause builder, got %v, %v", len(results), err)
+	}
+}
+
+func TestDeterministicMapSQL(t *testing.T) {
+	dryRun := DB.Session(&gorm.Session{DryRun: true, SkipDefaultTransaction: true})
+	values := map[string]interface{}{"name": "deterministic", "age": 18, "active": true, "birthday": nil, "company_id": 1, "manager_id": 2}
+
+	builds := map[string]func() *gorm.DB{
+		"where map": func() *gorm.DB {
+			return dryRun.Where(values).Find(&[]User{})
+		},
+		"where interface map": func() *gorm.DB {
+			return dryRun.Where(map[interface{}]interface{}{"name": "deterministic", "age": 18, clause.Column{Table: "users", Name: "active"}: true, "company_id": 1}).Find(&[]User{})
+		},
+		"updates map": func() *gorm.DB {
+			return dryRun.Model(&User{}).Where("id = ?", 1).Updates(values)
+		},
+		"create map": func() *gorm.DB {
+			return dryRun.Model(&User{}).Create(values)
+		},
+		"create maps": func() *gorm.DB {
+			return dryRun.Model(&User{}).Create([]map[string]interface{}{values, values})
+		},
+		"on conflict map": func() *gorm.DB {
+			return dryRun.Clauses(clause.OnConflict{UpdateAll: false, DoUpdates: clause.Assignments(values)}).Create(&User{Name: "deterministic"})
+		},
+		"attrs and assign maps": func() *gorm.DB {
+			return dryRun.Where(values).Attrs(values).Assign(values).FirstOrInit(&User{})
+		},
+	}
+
+	for name, build := range builds {
+		tx := build()
+		if tx.Error != nil {
+			t.Fatalf("%s: failed to build, got error %v", name, tx.Error)
+		}
+		expected, expectedVars := tx.Statement.SQL.String(), tx.Statement.Vars
+
+		for i := 0; i < 50; i++ {
+			tx := build()
+			if sql := tx.Statement.SQL.String(); sql != expected {
+				t.Fatalf("%s: the SQL should be identical, got %v, expects %v", name, sql, expected)
+			}
+			AssertEqual(t, tx.Statement.Vars, expectedVars)
+		}
+	}
+
+	sql := dryRun.Where(map[interface{}]interface{}{"name": "jinzhu", "age": 18, "active": true}).Find(&[]User{}).Statement.SQL.String()
+	if !regexp.MustCompile(`active.* = .*AND .*age.* = .*AND .*name.* = `).MatchString(sql) {
+		t.Errorf("the conditions should be sorted by the columns, got %v", sql)
+	}
+}
+
+func TestNormalizePlaceholders(t *testing.T) {
+	users := []User{*GetUser("normalize_placeholders_1", Config{}), *GetUser("normalize_placeholders_2", Config{})}
+	DB.Create(&users)
+
+	db := DB.Session(&gorm.Session{NormalizePlaceholders: true})
+
+	var names []string
+	if err := db.Raw("SELECT name FROM users WHERE id IN ($2, $1) AND name <> '?' /* $3 ? */ ORDER BY id", users[0].ID, users[1].ID).Scan(&names).Error; err != nil {
+		t.Fatalf("failed to query with the numbered placeholders, got error %v", err)
+	}
+	AssertEqual(t, names, []string{users[0].Name, users[1].Name})
+
+	if err := db.Exec("UPDATE users SET age = $1 WHERE id = $2 OR name = $3", 30, users[0].ID, users[1].Name).Error; err != nil {
+		t.Fatalf("failed to exec with the numbered placeholders, got error %v", err)
+	}
+
+	var count int64
+	db.Raw("SELECT count(*) FROM users WHERE age = ? AND id IN (?)", 30, []uint{users[0].ID, users[1].ID}).Scan(&count)
+	if count != 2 {
+		t.Errorf("should update the users, got %v", count)
+	}
+
+	numberedDB, _ := gorm.Open(numberedDialector{}, &gorm.Config{NormalizePlaceholders: true, DryRun: true})
+	stmt := numberedDB.Raw("SELECT * FROM users WHERE name = ? AND data ?| ? AND tags ?? ? AND note = '?' -- ?", "jinzhu", "tags", "key").Statement
+	AssertEqual(t, stmt.SQL.String(), "SELECT * FROM users WHERE name = $1 AND data ?| $2 AND tags ? $3 AND note = '?' -- ?")
+	AssertEqual(t, stmt.Vars, []interface{}{"jinzhu", "tags", "key"})
+
+	stmt = numberedDB.Raw("SELECT * FROM users WHERE name = @name AND age = $1", 18, map[string]interface{}{"name": "jinzhu"}).Statement
+	AssertEqual(t, stmt.SQL.String(), "SELECT * FROM users WHERE name = $1 AND age = $2")
+	AssertEqual(t, stmt.Vars, []interface{}{"jinzhu", 18})
+
+	if err := db.Raw("SELECT * FROM users WHERE id = $1 AND name = ?", 1, "jinzhu").Scan(&names).Error; !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("should return ErrInvalidData for the mixed placeholders, got %v", err)
+	}
+
+	if err := db.Exec("UPDATE users SET age = $2 WHERE id = $1", 1).Error; !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("should return ErrInvalidData for the missing vars, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package tests_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestSQLRewriter(t *testing.T) {
+	user := *GetUser("sql-rewriter", Config{Pets: 2})
+	if err := DB.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	var rewritten []string
+	db := DB.Session(&gorm.Session{})
+	db.Config.SQLRewriter = func(ctx context.Context, sql string, vars []interface{}, stmt *gorm.Statement) (string, []interface{}, error) {
+		sql = "/* route:primary */ " + sql
+		rewritten = append(rewritten, sql)
+		return sql, vars, nil
+	}
+
+	var result User
+	if err := db.Preload("Pets").First(&result, user.ID).Error; err != nil {
+		t.Fatalf("failed to query, got error %v", err)
+	}
+
+	if len(result.Pets) != 2 || len(rewritten) != 2 || !strings.Contains(rewritten[1], "pets") {
+		t.Fatalf("the query and the preload should be rewritten, got %v pets, %v", len(result.Pets), rewritten)
+	}
+
+	t.Run("AddVars", func(t *testing.T) {
+		db := DB.Session(&gorm.Session{})
+		db.Config.SQLRewriter = func(ctx context.Context, sql string, vars []interface{}, stmt *gorm.Statement) (string, []interface{}, error) {
+			if stmt.Table == "users" && strings.HasPrefix(sql, "SELECT") {
+				return strings.Replace(sql, "WHERE ", "WHERE name = ? AND ", 1), append([]interface{}{"not-" + user.Name}, vars...), nil
+			}
+			return sql, vars, nil
+		}
+
+		var count int64
+		if err := db.Model(&User{}).Where("id = ?", user.ID).Count(&count).Error; err != nil || count != 0 {
+			t.Errorf("the added condition should be applied, got count %v, error %v", count, err)
+		}
+
+		db.Config.SQLRewriter = func(ctx context.Context, sql string, vars []interface{}, stmt *gorm.Statement) (string, []interface{}, error) {
+			return sql, append(vars, "unbound"), nil
+		}
+
+		if err := db.Where("id = ?", user.ID).Find(&[]User{}).Error; !errors.Is(err, gorm.ErrInvalidData) {
+			t.Errorf("the unbound vars should be rejected, got %v", err)
+		}
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		errDenied := errors.New("denied")
+		db := DB.Session(&gorm.Session{})
+		db.Config.SQLRewriter = func(ctx context.Context, sql string, vars []interface{}, stmt *gorm.Statement) (string, []interface{}, error) {
+			// the soft delete is an update
+			if strings.HasPrefix(sql, "DELETE") || strings.HasPrefix(sql, "UPDATE") {
+				return "", nil, errDenied
+			}
+			return sql, vars, nil
+		}
+
+		if err := db.Delete(&User{}, user.ID).Error; !errors.Is(err, errDenied) {
+			t.Errorf("the delete should be aborted, got %v", err)
+		}
+
+		var name string
+		if err := db.Model(&User{}).Where("id = ?", user.ID).Select("name").Row().Scan(&name); err != nil || name != user.Name {
+			t.Errorf("the row should be queried, got %v, error %v", name, err)
+		}
+
+		if err := DB.First(&User{}, user.ID).Error; err != nil {
+			t.Errorf("the user shouldn't be deleted, got error %v", err)
+		}
+	})
+
+	t.Run("PreparedStmt", func(t *testing.T) {
+		db := DB.Session(&gorm.Session{PrepareStmt: boolPtr(true)})
+		db.Config.SQLRewriter = func(ctx context.Context, sql string, vars []interface{}, stmt *gorm.Statement) (string, []interface{}, error) {
+			return "/* prepared */ " + sql, vars, nil
+		}
+
+		if err := db.First(&User{}, user.ID).Error; err != nil {
+			t.Fatalf("failed to query, got error %v", err)
+		}
+
+		preparedStmt, ok := db.ConnPool.(*gorm.PreparedStmtDB)
+		if !ok {
+			t.Fatalf("the conn pool should be PreparedStmtDB, got %T", db.ConnPool)
+		}
+
+		var cached bool
+		preparedStmt.Mux.RLock()
+		for key := range preparedStmt.Stmts {
+			if strings.HasPrefix(key, "/* prepared */ ") {
+				cached = true
+			}
+		}
+		preparedStmt.Mux.RUnlock()
+
+		if !cached {
+			t.Errorf("the rewritten SQL should be prepared")
+		}
+	})
+}
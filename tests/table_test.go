@@ -1,12 +1,14 @@
 package tests_test
 
 import (
+	"errors"
 	"regexp"
 	"sync"
 	"testing"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
 	"gorm.io/gorm/utils/tests"
 	. "gorm.io/gorm/utils/tests"
@@ -93,6 +95,56 @@ func TestTable(t *testing.T) {
 	AssertEqual(t, r.Statement.Vars, []interface{}{2, 4, 1, 3})
 }
 
+func TestFunctionTable(t *testing.T) {
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	if !DB.Supports(gorm.CapabilityTableFunctions) {
+		r := dryDB.Table(clause.FunctionTable{Name: "json_each", Args: []interface{}{"[]"}, Alias: "j"}).Find(&[]User{})
+		if !errors.Is(r.Error, gorm.ErrUnsupportedDriver) {
+			t.Errorf("function table should be unsupported, got %v", r.Error)
+		}
+		return
+	}
+
+	r := dryDB.Table(clause.FunctionTable{Name: "search_users", Args: []interface{}{"jinzhu", 10}, Alias: "u"}).
+		Select("u.name").Where("u.age > ?", 18).Find(&User{}).Statement
+	if !regexp.MustCompile(`SELECT u.name FROM search_users\(.+,.+\) AS .u. WHERE u.age > .+ AND .u.\..deleted_at. IS NULL`).MatchString(r.Statement.SQL.String()) {
+		t.Errorf("Table with function table, got %v", r.Statement.SQL.String())
+	}
+	AssertEqual(t, r.Statement.Vars, []interface{}{"jinzhu", 10, 18})
+
+	if DB.Dialector.Name() != "sqlite" {
+		t.Skip("json_each is only available in sqlite")
+	}
+
+	users := []User{*GetUser("function_table_1", Config{}), *GetUser("function_table_2", Config{})}
+	users[0].Age, users[1].Age = 20, 30
+	DB.Create(&users)
+
+	type Element struct {
+		Key   int
+		Value string
+	}
+
+	var elements []Element
+	if err := DB.Table(clause.FunctionTable{Name: "json_each", Args: []interface{}{`["a","b","c"]`}, Alias: "j"}).
+		Select("j.key, j.value").Where("j.value <> ?", "b").Order("j.key").Find(&elements).Error; err != nil {
+		t.Fatalf("failed to query function table, got %v", err)
+	}
+	AssertEqual(t, elements, []Element{{Key: 0, Value: "a"}, {Key: 2, Value: "c"}})
+
+	type Result struct {
+		Name string
+		Age  uint
+	}
+
+	var results []Result
+	if err := DB.Table(clause.FunctionTable{Name: "json_each", Args: []interface{}{`["function_table_2","function_table_1"]`}, Alias: "j"}).
+		Select("users.name, users.age").Joins("JOIN users ON users.name = j.value").Order("j.key").Scan(&results).Error; err != nil {
+		t.Fatalf("failed to join function table, got %v", err)
+	}
+	AssertEqual(t, results, []Result{{Name: "function_table_2", Age: 30}, {Name: "function_table_1", Age: 20}})
+}
+
 func TestTableWithAllFields(t *testing.T) {
 	dryDB := DB.Session(&gorm.Session{DryRun: true, QueryFields: true})
 	userQuery := "SELECT .*user.*id.*user.*created_at.*user.*updated_at.*user.*deleted_at.*user.*name.*user.*age" +
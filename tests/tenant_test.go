@@ -0,0 +1,156 @@
+package tests_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type TenantProject struct {
+	ID       uint
+	TenantID uint `gorm:"tenant"`
+	Name     string
+	Tasks    []TenantTask
+}
+
+type TenantTask struct {
+	ID              uint
+	TenantID        uint `gorm:"tenant"`
+	TenantProjectID uint
+	Name            string
+}
+
+type tenantKey struct{}
+
+func TestTenancy(t *testing.T) {
+	DB.Migrator().DropTable(&TenantProject{}, &TenantTask{})
+	if err := DB.AutoMigrate(&TenantProject{}, &TenantTask{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	db := DB.Session(&gorm.Session{})
+	db.Config.TenantResolver = func(ctx context.Context) (interface{}, error) {
+		return ctx.Value(tenantKey{}), nil
+	}
+	tenant := func(id uint) *gorm.DB {
+		return db.WithContext(context.WithValue(context.Background(), tenantKey{}, id))
+	}
+
+	projects := []TenantProject{
+		{Name: "project-1", Tasks: []TenantTask{{Name: "task-1"}, {Name: "task-2"}}},
+		{Name: "project-2", Tasks: []TenantTask{{Name: "task-3"}}},
+	}
+	if err := tenant(1).Create(&projects).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+	AssertEqual(t, projects[0].TenantID, uint(1))
+	AssertEqual(t, projects[0].Tasks[0].TenantID, uint(1))
+
+	otherProject := TenantProject{Name: "project-1", Tasks: []TenantTask{{Name: "task-4"}}}
+	tenant(2).Create(&otherProject)
+	AssertEqual(t, otherProject.TenantID, uint(2))
+	// a task of tenant 2 referencing the project of tenant 1
+	tenant(2).Create(&TenantTask{Name: "task-5", TenantProjectID: projects[0].ID})
+
+	var results []TenantProject
+	tenant(1).Where("name = ?", "project-1").Or("name = ?", "project-2").Order("id").Find(&results)
+	if len(results) != 2 || results[0].ID != projects[0].ID || results[1].ID != projects[1].ID {
+		t.Errorf("expected the projects of the tenant, got %+v", results)
+	}
+
+	var result TenantProject
+	if err := tenant(1).First(&result, otherProject.ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound loading the project of other tenant, got %v", err)
+	}
+
+	result = TenantProject{}
+	tenant(1).Preload("Tasks").First(&result, projects[0].ID)
+	AssertEqual(t, len(result.Tasks), 2)
+	AssertEqual(t, tenant(1).Model(&projects[0]).Association("Tasks").Count(), int64(2))
+	AssertEqual(t, tenant(2).Model(&projects[0]).Association("Tasks").Count(), int64(1))
+
+	if result := tenant(1).Model(&otherProject).Update("name", "hijacked"); result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("expected the project of other tenant not updated, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+	if result := tenant(1).Delete(&otherProject); result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("expected the project of other tenant not deleted, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+	if err := tenant(1).Delete(&TenantProject{}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("expected ErrMissingWhereClause, got %v", err)
+	}
+
+	if err := db.Find(&results).Error; !errors.Is(err, gorm.ErrMissingTenant) {
+		t.Errorf("expected ErrMissingTenant, got %v", err)
+	}
+
+	var count int64
+	db.WithoutTenancy().Model(&TenantProject{}).Count(&count)
+	AssertEqual(t, count, 3)
+
+	// raw sql is exempt
+	results = nil
+	tenant(1).Raw("SELECT * FROM tenant_projects").Scan(&results)
+	AssertEqual(t, len(results), 3)
+}
+
+type TenantNote struct {
+	ID        uint
+	TenantID  uint `gorm:"tenant"`
+	Name      string
+	DeletedAt gorm.DeletedAt
+}
+
+func TestTenancyCrossTenantWrites(t *testing.T) {
+	DB.Migrator().DropTable(&TenantNote{})
+	if err := DB.AutoMigrate(&TenantNote{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	db := DB.Session(&gorm.Session{})
+	db.Config.TenantResolver = func(ctx context.Context) (interface{}, error) {
+		return ctx.Value(tenantKey{}), nil
+	}
+	tenant := func(id uint) *gorm.DB {
+		return db.WithContext(context.WithValue(context.Background(), tenantKey{}, id))
+	}
+
+	// an explicit tenant of other tenant is overwritten with the current tenant
+	note := TenantNote{Name: "note-1", TenantID: 1}
+	if err := tenant(2).Create(&note).Error; err != nil {
+		t.Fatalf("failed to create note, got error %v", err)
+	}
+	AssertEqual(t, note.TenantID, uint(2))
+
+	notes := []TenantNote{{Name: "note-2", TenantID: 1}, {Name: "note-3"}}
+	if err := tenant(2).Create(&notes).Error; err != nil {
+		t.Fatalf("failed to create notes, got error %v", err)
+	}
+	var count int64
+	db.WithoutTenancy().Model(&TenantNote{}).Where("tenant_id = ?", 1).Count(&count)
+	AssertEqual(t, count, 0)
+
+	other := TenantNote{Name: "note-4"}
+	if err := tenant(1).Create(&other).Error; err != nil {
+		t.Fatalf("failed to create note, got error %v", err)
+	}
+	if err := tenant(1).Delete(&other).Error; err != nil {
+		t.Fatalf("failed to delete note, got error %v", err)
+	}
+
+	if result := tenant(2).Restore(&TenantNote{}, other.ID); result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("expected the note of other tenant not restored, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+	if err := tenant(1).First(&TenantNote{}, other.ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected the note of other tenant still deleted, got %v", err)
+	}
+
+	if result := tenant(1).Restore(&TenantNote{}, other.ID); result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("expected the note restored, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+	if err := tenant(1).First(&TenantNote{}, other.ID).Error; err != nil {
+		t.Errorf("expected the note restored, got %v", err)
+	}
+}
@@ -1,11 +1,15 @@
 package tests_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"log"
+	"strings"
 	"testing"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -60,7 +64,7 @@ func TestTransaction(t *testing.T) {
 
 	t.Run("this is test nested transaction and prepareStmt coexist case", func(t *testing.T) {
 		// enable prepare statement
-		tx3 := DB.Session(&gorm.Session{PrepareStmt: true})
+		tx3 := DB.Session(&gorm.Session{PrepareStmt: boolPtr(true)})
 		if err := tx3.Transaction(func(tx4 *gorm.DB) error {
 			// nested transaction
 			return tx4.Transaction(func(tx5 *gorm.DB) error {
@@ -372,7 +376,7 @@ func TestDisabledNestedTransaction(t *testing.T) {
 		user2 = *GetUser("transaction-nested-2", Config{})
 	)
 
-	if err := DB.Session(&gorm.Session{DisableNestedTransaction: true}).Transaction(func(tx *gorm.DB) error {
+	if err := DB.Session(&gorm.Session{DisableNestedTransaction: boolPtr(true)}).Transaction(func(tx *gorm.DB) error {
 		tx.Create(&user)
 
 		if err := tx.First(&User{}, "name = ?", user.Name).Error; err != nil {
@@ -428,6 +432,123 @@ func TestDisabledNestedTransaction(t *testing.T) {
 	}
 }
 
+func TestNestedTransactionSessionOverride(t *testing.T) {
+	var (
+		user  = *GetUser("transaction-override", Config{})
+		user1 = *GetUser("transaction-override-1", Config{})
+	)
+
+	db := DB.Session(&gorm.Session{DisableNestedTransaction: boolPtr(true)})
+	if err := db.Session(&gorm.Session{DisableNestedTransaction: boolPtr(false)}).Transaction(func(tx *gorm.DB) error {
+		tx.Create(&user)
+
+		if err := tx.Transaction(func(tx1 *gorm.DB) error {
+			tx1.Create(&user1)
+			return errors.New("rollback")
+		}); err == nil {
+			t.Fatalf("nested transaction should returns error")
+		}
+
+		if err := tx.First(&User{}, "name = ?", user1.Name).Error; err == nil {
+			t.Fatalf("Should rollback to savepoint if enabled nested transaction by session")
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("no error should return, but got %v", err)
+	}
+
+	if err := DB.First(&User{}, "name = ?", user.Name).Error; err != nil {
+		t.Fatalf("Should find saved record")
+	}
+
+	if err := DB.First(&User{}, "name = ?", user1.Name).Error; err == nil {
+		t.Fatalf("Should not find rollbacked nested record")
+	}
+}
+
+func TestNestedTransactionWithTxJoin(t *testing.T) {
+	var (
+		buf   bytes.Buffer
+		user  = *GetUser("transaction-join", Config{})
+		user1 = *GetUser("transaction-join-1", Config{})
+		user2 = *GetUser("transaction-join-2", Config{})
+		user3 = *GetUser("transaction-join-3", Config{})
+	)
+
+	db := DB.Session(&gorm.Session{Logger: logger.New(log.New(&buf, "", 0), logger.Config{LogLevel: logger.Info})})
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		tx.Create(&user)
+
+		// joined transaction, the error doesn't rollback its changes
+		if err := tx.Transaction(func(tx1 *gorm.DB) error {
+			tx1.Create(&user1)
+
+			// the nested transactions of the joined transaction join the outer transaction too
+			if err := tx1.Transaction(func(tx2 *gorm.DB) error {
+				tx2.Create(&user2)
+				return errors.New("rollback")
+			}); err == nil {
+				t.Fatalf("nested transaction should returns error")
+			}
+			return errors.New("rollback")
+		}, gorm.TxJoin); err == nil {
+			t.Fatalf("nested transaction should returns error")
+		}
+
+		for _, name := range []string{user1.Name, user2.Name} {
+			if err := tx.First(&User{}, "name = ?", name).Error; err != nil {
+				t.Fatalf("Should not rollback record %v if joined the outer transaction", name)
+			}
+		}
+
+		// savepoint transaction, the error rollbacks to the savepoint
+		if err := tx.Transaction(func(tx3 *gorm.DB) error {
+			tx3.Create(&user3)
+			return errors.New("rollback")
+		}); err == nil {
+			t.Fatalf("nested transaction should returns error")
+		}
+
+		if err := tx.First(&User{}, "name = ?", user3.Name).Error; err == nil {
+			t.Fatalf("Should rollback to savepoint if not joined the outer transaction")
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("no error should return, but got %v", err)
+	}
+
+	if strings.Count(buf.String(), "nested transaction joins the outer transaction without savepoint") != 2 {
+		t.Errorf("the joined transactions should be logged, got %v", buf.String())
+	}
+
+	for _, name := range []string{user.Name, user1.Name, user2.Name} {
+		if err := DB.First(&User{}, "name = ?", name).Error; err != nil {
+			t.Fatalf("Should find saved record %v", name)
+		}
+	}
+
+	if err := DB.First(&User{}, "name = ?", user3.Name).Error; err == nil {
+		t.Fatalf("Should not find rollbacked nested record")
+	}
+
+	// the error of the outer transaction rollbacks the joined transactions
+	user4 := *GetUser("transaction-join-4", Config{})
+	if err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Transaction(func(tx1 *gorm.DB) error {
+			return tx1.Create(&user4).Error
+		}); err != nil {
+			t.Fatalf("nested transaction returns error: %v", err)
+		}
+		return errors.New("rollback")
+	}, gorm.TxJoin); err == nil {
+		t.Fatalf("transaction should returns error")
+	}
+
+	if err := DB.First(&User{}, "name = ?", user4.Name).Error; err == nil {
+		t.Fatalf("Should not find rollbacked record")
+	}
+}
+
 func TestTransactionOnClosedConn(t *testing.T) {
 	DB, err := OpenTestConnection(&gorm.Config{})
 	if err != nil {
@@ -442,7 +563,7 @@ func TestTransactionOnClosedConn(t *testing.T) {
 		t.Errorf("should returns error when commit with closed conn, got error %v", err)
 	}
 
-	if err := DB.Session(&gorm.Session{PrepareStmt: true}).Transaction(func(tx *gorm.DB) error {
+	if err := DB.Session(&gorm.Session{PrepareStmt: boolPtr(true)}).Transaction(func(tx *gorm.DB) error {
 		return nil
 	}); err == nil {
 		t.Errorf("should returns error when commit with closed conn, got error %v", err)
@@ -478,3 +599,92 @@ func TestTransactionWithHooks(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+type twoPhaseDialector struct {
+	gorm.Dialector
+	prepared  []string
+	committed []string
+	rollbacks []string
+}
+
+func (d *twoPhaseDialector) PrepareTransaction(tx *gorm.DB, id string) error {
+	d.prepared = append(d.prepared, id)
+	return nil
+}
+
+func (d *twoPhaseDialector) CommitPrepared(db *gorm.DB, id string) error {
+	d.committed = append(d.committed, id)
+	return nil
+}
+
+func (d *twoPhaseDialector) RollbackPrepared(db *gorm.DB, id string) error {
+	d.rollbacks = append(d.rollbacks, id)
+	return nil
+}
+
+func (d *twoPhaseDialector) PreparedTransactions(db *gorm.DB) (txs []gorm.PreparedTransaction, err error) {
+	for _, id := range d.prepared {
+		txs = append(txs, gorm.PreparedTransaction{ID: id})
+	}
+	return
+}
+
+func TestTwoPhaseTransaction(t *testing.T) {
+	if _, ok := DB.Dialector.(gorm.TxPreparer); !ok {
+		tx := DB.Begin()
+		if err := tx.PrepareTransaction("unsupported").Error; !errors.Is(err, gorm.ErrUnsupportedDriver) {
+			t.Errorf("should return ErrUnsupportedDriver, but got %v", err)
+		}
+		tx.Rollback()
+
+		if err := DB.CommitPrepared("unsupported").Error; !errors.Is(err, gorm.ErrUnsupportedDriver) {
+			t.Errorf("should return ErrUnsupportedDriver, but got %v", err)
+		}
+
+		if _, err := DB.PreparedTransactions(); !errors.Is(err, gorm.ErrUnsupportedDriver) {
+			t.Errorf("should return ErrUnsupportedDriver, but got %v", err)
+		}
+	}
+
+	dialector := &twoPhaseDialector{Dialector: DB.Dialector}
+	db := DB.Session(&gorm.Session{})
+	db.Dialector = dialector
+
+	if err := db.Session(&gorm.Session{}).PrepareTransaction("not_in_tx").Error; !errors.Is(err, gorm.ErrInvalidTransaction) {
+		t.Errorf("should return ErrInvalidTransaction when not in transaction, but got %v", err)
+	}
+
+	tx := db.Begin()
+	user := *GetUser("two-phase-transaction", Config{})
+	if err := tx.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+
+	if err := tx.PrepareTransaction("gorm_2pc").Error; err != nil {
+		t.Fatalf("failed to prepare transaction, got error %v", err)
+	}
+
+	if _, ok := tx.Statement.ConnPool.(gorm.TxCommitter); ok {
+		t.Errorf("transaction connection should be released after prepared")
+	}
+
+	if err := tx.Commit().Error; !errors.Is(err, gorm.ErrInvalidTransaction) {
+		t.Errorf("commit prepared transaction should return ErrInvalidTransaction, but got %v", err)
+	}
+
+	if txs, err := db.PreparedTransactions(); err != nil || len(txs) != 1 || txs[0].ID != "gorm_2pc" {
+		t.Errorf("failed to list prepared transactions, got %v, %v", txs, err)
+	}
+
+	if err := db.CommitPrepared("gorm_2pc").Error; err != nil {
+		t.Errorf("failed to commit prepared transaction, got error %v", err)
+	}
+
+	if err := db.RollbackPrepared("gorm_2pc_rollback").Error; err != nil {
+		t.Errorf("failed to rollback prepared transaction, got error %v", err)
+	}
+
+	if len(dialector.prepared) != 1 || len(dialector.committed) != 1 || len(dialector.rollbacks) != 1 {
+		t.Errorf("unexpected dialector calls, prepared: %v, committed: %v, rollbacks: %v", dialector.prepared, dialector.committed, dialector.rollbacks)
+	}
+}
@@ -931,3 +931,231 @@ func TestUpdateFrom(t *testing.T) {
 		}
 	}
 }
+
+func TestUpdateWithSubquery(t *testing.T) {
+	user := *GetUser("update_with_subquery", Config{Pets: 3})
+	DB.Create(&user)
+
+	petsCount := func() *gorm.DB {
+		return DB.Model(&Pet{}).Select("count(*)").Where("pets.user_id = ?", clause.ColTable("users", "id")).Where("name <> ?", "none")
+	}
+
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	stmt := dryDB.Model(&User{}).Where("name = ?", user.Name).Update("age", petsCount()).Statement
+	if !regexp.MustCompile(`SET .age.=\(SELECT count\(\*\) FROM .pets. WHERE pets.user_id = .users.\..id. AND name <> .+\),`).MatchString(stmt.SQL.String()) {
+		t.Errorf("unexpected update with subquery, got %v", stmt.SQL.String())
+	}
+
+	if len(stmt.Vars) != 3 || stmt.Vars[0] != "none" || stmt.Vars[2] != user.Name {
+		t.Errorf("unexpected vars of update with subquery, got %v", stmt.Vars)
+	}
+
+	checkAge := func(age uint) {
+		t.Helper()
+		var result User
+		DB.First(&result, user.ID)
+		if result.Age != age {
+			t.Errorf("age should be %v, but got %v", age, result.Age)
+		}
+	}
+
+	if err := DB.Model(&User{}).Where("name = ?", user.Name).Update("age", petsCount()).Error; err != nil {
+		t.Fatalf("failed to update with subquery, got error %v", err)
+	}
+	checkAge(3)
+
+	DB.Delete(user.Pets[0])
+	if err := DB.Model(&User{}).Where("name = ?", user.Name).Updates(map[string]interface{}{"age": petsCount(), "active": true}).Error; err != nil {
+		t.Fatalf("failed to update map with subquery, got error %v", err)
+	}
+	checkAge(2)
+
+	DB.Delete(user.Pets[1])
+	if err := DB.Model(&User{}).Where("name = ?", user.Name).Clauses(clause.Set{{Column: clause.Column{Name: "age"}, Value: petsCount()}}).Updates(map[string]interface{}{}).Error; err != nil {
+		t.Fatalf("failed to update assignments with subquery, got error %v", err)
+	}
+	checkAge(1)
+}
+
+func TestUpdateWithLimit(t *testing.T) {
+	users := []User{*GetUser("update_with_limit", Config{}), *GetUser("update_with_limit", Config{}), *GetUser("update_with_limit", Config{})}
+	DB.Create(&users)
+
+	if result := DB.Model(&User{}).Where("name = ?", "update_with_limit").Order("id desc").Limit(2).Update("age", 99); result.Error != nil || result.RowsAffected != 2 {
+		t.Fatalf("failed to update with limit, rows affected %v, error %v", result.RowsAffected, result.Error)
+	}
+
+	var results []User
+	DB.Where("name = ?", "update_with_limit").Order("id").Find(&results)
+	if len(results) != 3 || results[0].Age == 99 || results[1].Age != 99 || results[2].Age != 99 {
+		t.Errorf("the last two users should be updated, got %+v", results)
+	}
+}
+
+type SaveModeUser struct {
+	ID   uint
+	Name string
+
+	beforeCreateCalled, beforeUpdateCalled int
+}
+
+func (u *SaveModeUser) BeforeCreate(*gorm.DB) error {
+	u.beforeCreateCalled++
+	return nil
+}
+
+func (u *SaveModeUser) BeforeUpdate(*gorm.DB) error {
+	u.beforeUpdateCalled++
+	return nil
+}
+
+func TestSaveMode(t *testing.T) {
+	DB.Migrator().DropTable(&SaveModeUser{})
+	if err := DB.AutoMigrate(&SaveModeUser{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	savePath := func(tx *gorm.DB) interface{} {
+		path, _ := tx.Get("gorm:save_path")
+		return path
+	}
+
+	checkSaved := func(user SaveModeUser) {
+		t.Helper()
+		var result SaveModeUser
+		if err := DB.First(&result, user.ID).Error; err != nil || result.Name != user.Name {
+			t.Errorf("user %+v should be saved, got %+v, error %v", user, result, err)
+		}
+	}
+
+	user := SaveModeUser{Name: "save_mode"}
+	if result := DB.Save(&user); result.Error != nil || savePath(result) != "create" || user.beforeCreateCalled != 1 {
+		t.Errorf("should create the user without primary key, got path %v, error %v", savePath(result), result.Error)
+	}
+
+	if result := DB.Save(&user); result.Error != nil || savePath(result) != "update" || user.beforeUpdateCalled != 1 {
+		t.Errorf("should update the user with primary key, got path %v, error %v", savePath(result), result.Error)
+	}
+
+	queryDB := DB.Session(&gorm.Session{SaveMode: gorm.SaveByQuery})
+	queryUser := SaveModeUser{ID: user.ID + 100, Name: "save_by_query"}
+	if result := queryDB.Save(&queryUser); result.Error != nil || savePath(result) != "create" || queryUser.beforeCreateCalled != 1 || queryUser.beforeUpdateCalled != 0 {
+		t.Errorf("should create the user not found, got path %v, user %+v, error %v", savePath(result), queryUser, result.Error)
+	}
+	checkSaved(queryUser)
+
+	queryUser.beforeCreateCalled = 0
+	if result := queryDB.Save(&queryUser); result.Error != nil || savePath(result) != "update" || queryUser.beforeCreateCalled != 0 || queryUser.beforeUpdateCalled != 1 {
+		t.Errorf("should update the user found even not changed, got path %v, user %+v, error %v", savePath(result), queryUser, result.Error)
+	}
+
+	if err := queryDB.Transaction(func(tx *gorm.DB) error {
+		txUser := SaveModeUser{ID: user.ID + 200, Name: "save_by_query_in_transaction"}
+		if result := tx.Save(&txUser); result.Error != nil || savePath(result) != "create" {
+			t.Errorf("should create the user in transaction, got path %v, error %v", savePath(result), result.Error)
+		}
+
+		txUser.Name = "save_by_query_in_transaction_updated"
+		if result := tx.Save(&txUser); result.Error != nil || savePath(result) != "update" {
+			t.Errorf("should update the user created in transaction, got path %v, error %v", savePath(result), result.Error)
+		}
+		return nil
+	}); err != nil {
+		t.Errorf("failed to save in transaction, got error %v", err)
+	}
+
+	// the soft deleted record isn't found by the scoped query, so it's never updated silently
+	softDeleted := User{Name: "save_by_query_soft_deleted"}
+	DB.Create(&softDeleted)
+	DB.Delete(&softDeleted)
+	softDeleted.Name = "save_by_query_soft_deleted_updated"
+	if result := queryDB.Save(&softDeleted); result.Error == nil {
+		t.Errorf("should fail to save the soft deleted user, got path %v", savePath(result))
+	}
+	var deleted User
+	DB.Unscoped().First(&deleted, softDeleted.ID)
+	if deleted.Name != "save_by_query_soft_deleted" || !deleted.DeletedAt.Valid {
+		t.Errorf("the soft deleted user shouldn't be changed, got %+v", deleted)
+	}
+
+	upsertDB := DB.Session(&gorm.Session{SaveMode: gorm.SaveByUpsert})
+	upsertUser := SaveModeUser{ID: user.ID + 300, Name: "save_by_upsert"}
+	expectedPath := "upsert"
+	if !DB.Supports(gorm.CapabilityOnConflict) {
+		expectedPath = "create"
+	}
+
+	if result := upsertDB.Save(&upsertUser); result.Error != nil || savePath(result) != expectedPath || upsertUser.beforeUpdateCalled != 0 {
+		t.Errorf("should upsert the user, got path %v, user %+v, error %v", savePath(result), upsertUser, result.Error)
+	}
+	checkSaved(upsertUser)
+
+	upsertUser.Name = "save_by_upsert_updated"
+	if result := upsertDB.Save(&upsertUser); result.Error != nil {
+		t.Errorf("failed to upsert the existing user, got error %v", result.Error)
+	}
+	checkSaved(upsertUser)
+}
+
+func TestSkipAutoTimeTracking(t *testing.T) {
+	user := *GetUser("skip_auto_time_tracking", Config{Pets: 1})
+	if err := DB.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+
+	lastUpdatedAt := time.Now().Add(-time.Hour).Round(time.Second)
+	DB.Model(&user).UpdateColumn("updated_at", lastUpdatedAt)
+	DB.Model(&user.Pets[0]).UpdateColumn("updated_at", lastUpdatedAt)
+
+	updatedAt := func(value interface{}) time.Time {
+		t.Helper()
+		var result time.Time
+		if err := DB.Model(value).Select("updated_at").Scan(&result).Error; err != nil {
+			t.Fatalf("failed to query updated_at, got error %v", err)
+		}
+		return result
+	}
+
+	skipDB := DB.Session(&gorm.Session{SkipAutoTimeTracking: true})
+	if err := skipDB.Model(&user).Updates(User{Name: "skip_auto_time_tracking_updated"}).Error; err != nil {
+		t.Fatalf("failed to update user, got error %v", err)
+	}
+	AssertEqual(t, updatedAt(&user), lastUpdatedAt)
+
+	if err := skipDB.Model(&user).Update("age", 20).Error; err != nil {
+		t.Fatalf("failed to update user, got error %v", err)
+	}
+	AssertEqual(t, updatedAt(&user), lastUpdatedAt)
+
+	if err := DB.Model(&user).Select("*").Omit("UpdatedAt", "CreatedAt").Updates(user).Error; err != nil {
+		t.Fatalf("failed to update user, got error %v", err)
+	}
+	AssertEqual(t, updatedAt(&user), lastUpdatedAt)
+
+	user.Pets[0].Name = "skip_auto_time_tracking_pet"
+	if err := skipDB.Session(&gorm.Session{FullSaveAssociations: true}).Save(&user).Error; err != nil {
+		t.Fatalf("failed to save user, got error %v", err)
+	}
+	AssertEqual(t, updatedAt(&user), lastUpdatedAt)
+	AssertEqual(t, updatedAt(user.Pets[0]), lastUpdatedAt)
+
+	if err := DB.Session(&gorm.Session{FullSaveAssociations: true}).Save(&user).Error; err != nil {
+		t.Fatalf("failed to save user, got error %v", err)
+	}
+	if !updatedAt(&user).After(lastUpdatedAt) || !updatedAt(user.Pets[0]).After(lastUpdatedAt) {
+		t.Errorf("updated_at should be updated without SkipAutoTimeTracking")
+	}
+
+	product := Product{Code: "skip_auto_time_tracking", Price: 10}
+	if err := skipDB.Create(&product).Error; err != nil {
+		t.Fatalf("failed to create product, got error %v", err)
+	}
+
+	if !product.CreatedAt.IsZero() || product.BeforeCreateCallTimes != 1 {
+		t.Errorf("created_at shouldn't be set but hooks should be called, got %+v", product)
+	}
+
+	if err := skipDB.Model(&product).Update("price", 20).Error; err != nil || product.BeforeUpdateCallTimes != 1 || !product.UpdatedAt.IsZero() {
+		t.Errorf("updated_at shouldn't be set but hooks should be called, got %+v, error %v", product, err)
+	}
+}
@@ -326,3 +326,155 @@ func TestUpdateWithMissWhere(t *testing.T) {
 		t.Fatalf("invalid updating SQL, got %v", tx.Statement.SQL.String())
 	}
 }
+
+type ConflictSubscriber struct {
+	ID     uint
+	Email  string `gorm:"size:100;uniqueIndex:ux_conflict_subscribers_email,onConflict:update"`
+	Name   string
+	Source string `gorm:"<-:create"`
+}
+
+type ConflictVisitor struct {
+	ID     uint
+	Email  string `gorm:"size:100;uniqueIndex:ux_conflict_visitors_email,onConflict:nothing"`
+	Name   string
+	Visits int
+}
+
+type ConflictMember struct {
+	ID     uint
+	Email  string `gorm:"size:100;uniqueIndex"`
+	Name   string
+	Visits int
+}
+
+func (ConflictMember) OnConflictClause() clause.OnConflict {
+	return clause.OnConflict{Columns: []clause.Column{{Name: "email"}}, DoUpdates: clause.AssignmentColumns([]string{"visits"})}
+}
+
+func TestUpsertWithModelConflictClause(t *testing.T) {
+	DB.Migrator().DropTable(&ConflictSubscriber{}, &ConflictVisitor{}, &ConflictMember{})
+	if err := DB.AutoMigrate(&ConflictSubscriber{}, &ConflictVisitor{}, &ConflictMember{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	if err := DB.Create(&ConflictSubscriber{Email: "jinzhu@example.com", Name: "jinzhu", Source: "signup"}).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	if err := DB.Create(&ConflictSubscriber{Email: "jinzhu@example.com", Name: "jinzhu 2"}).Error; err == nil {
+		t.Errorf("the declared OnConflict clause shouldn't be used without UseModelConflictClause")
+	}
+
+	tx := DB.Session(&gorm.Session{UseModelConflictClause: true})
+	if err := tx.Create(&ConflictSubscriber{Email: "jinzhu@example.com", Name: "jinzhu 3", Source: "import"}).Error; err != nil {
+		t.Fatalf("the existing natural key should be updated, got %v", err)
+	}
+
+	var subscribers []ConflictSubscriber
+	DB.Find(&subscribers)
+	if len(subscribers) != 1 || subscribers[0].Name != "jinzhu 3" || subscribers[0].Source != "signup" {
+		t.Errorf("the non key fields except the create only ones should be updated, got %+v", subscribers)
+	}
+
+	// the specified OnConflict clause is preferred
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&ConflictSubscriber{Email: "jinzhu@example.com", Name: "jinzhu 4"}).Error; err != nil {
+		t.Fatalf("failed to create with OnConflict clause, got %v", err)
+	}
+	DB.Find(&subscribers)
+	AssertEqual(t, subscribers[0].Name, "jinzhu 3")
+
+	visitors := []ConflictVisitor{{Email: "visitor@example.com", Name: "visitor", Visits: 1}, {Email: "visitor@example.com", Name: "visitor 2", Visits: 2}}
+	if err := tx.Create(&visitors[0]).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+	if err := tx.Create(&visitors[1]).Error; err != nil {
+		t.Fatalf("the conflicted rows should be ignored, got %v", err)
+	}
+	var visitor ConflictVisitor
+	DB.First(&visitor, "email = ?", "visitor@example.com")
+	AssertEqual(t, visitor.Name, "visitor")
+	AssertEqual(t, visitor.Visits, 1)
+
+	// the models implementing OnConflictClause always use it, only the listed columns are updated
+	if err := DB.Create(&ConflictMember{Email: "member@example.com", Name: "member", Visits: 1}).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+	if err := DB.Create(&ConflictMember{Email: "member@example.com", Name: "member 2", Visits: 2}).Error; err != nil {
+		t.Fatalf("the existing natural key should be updated, got %v", err)
+	}
+	var member ConflictMember
+	DB.First(&member, "email = ?", "member@example.com")
+	AssertEqual(t, member.Name, "member")
+	AssertEqual(t, member.Visits, 2)
+}
+
+func TestUpsertStats(t *testing.T) {
+	DB.Create(&[]Language{{Code: "upsert_stats_1", Name: "1"}, {Code: "upsert_stats_2", Name: "2"}})
+
+	langs := []Language{{Code: "upsert_stats_1", Name: "1 updated"}, {Code: "upsert_stats_2", Name: "2"}, {Code: "upsert_stats_3", Name: "3"}}
+	tx := DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&langs)
+	if tx.Error != nil {
+		t.Fatalf("failed to upsert, got %v", tx.Error)
+	}
+
+	if stats := gorm.UpsertStats(tx); DB.Dialector.Name() == "postgres" {
+		// the conflicting rows are always updated by Postgres
+		AssertEqual(t, stats, gorm.UpsertResult{Known: true, Inserted: 1, Updated: 2})
+	} else if stats.Known {
+		// the affected rows of MySQL for 1 inserted, 1 updated and 1 unchanged row are 3, which are also 3 inserted rows
+		t.Errorf("the updated rows shouldn't be known, got %+v", stats)
+	}
+
+	langs = []Language{{Code: "upsert_stats_1", Name: "1 updated again"}, {Code: "upsert_stats_4", Name: "4"}}
+	tx = DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&langs)
+	stats := gorm.UpsertStats(tx)
+	if name := DB.Dialector.Name(); name == "mysql" || name == "postgres" {
+		AssertEqual(t, stats, gorm.UpsertResult{Known: true, Inserted: 1, Updated: 1})
+	} else if stats.Known {
+		t.Errorf("the updated rows can't be determined by %v, got %+v", name, stats)
+	}
+
+	langs = []Language{{Code: "upsert_stats_1"}, {Code: "upsert_stats_5", Name: "5"}}
+	tx = DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&langs)
+	AssertEqual(t, gorm.UpsertStats(tx), gorm.UpsertResult{Known: true, Inserted: 1, Unchanged: 1})
+
+	t.Run("Returning", func(t *testing.T) {
+		users := []User{*GetUser("upsert_stats_user_1", Config{}), *GetUser("upsert_stats_user_2", Config{})}
+		DB.Create(&users[0])
+
+		users[0].Age = 30
+		tx := DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&users)
+		if tx.Error != nil {
+			t.Fatalf("failed to upsert, got %v", tx.Error)
+		}
+
+		stats := gorm.UpsertStats(tx)
+		switch DB.Dialector.Name() {
+		case "postgres", "mysql":
+			AssertEqual(t, stats, gorm.UpsertResult{Known: true, Inserted: 1, Updated: 1})
+		default:
+			if stats.Known {
+				t.Errorf("the updated rows can't be determined by %v, got %+v", DB.Dialector.Name(), stats)
+			}
+		}
+
+		var user User
+		DB.First(&user, users[1].ID)
+		AssertEqual(t, user.Name, "upsert_stats_user_2")
+
+		users = append(users, *GetUser("upsert_stats_user_3", Config{}))
+		tx = DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&users)
+		AssertEqual(t, gorm.UpsertStats(tx), gorm.UpsertResult{Known: true, Inserted: 1, Unchanged: 2})
+	})
+
+	t.Run("Batches", func(t *testing.T) {
+		langs := []Language{{Code: "upsert_stats_1"}, {Code: "upsert_stats_6"}, {Code: "upsert_stats_2"}, {Code: "upsert_stats_7"}, {Code: "upsert_stats_8"}}
+		tx := DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&langs, 2)
+		AssertEqual(t, gorm.UpsertStats(tx), gorm.UpsertResult{Known: true, Inserted: 3, Unchanged: 2})
+
+		if stats := gorm.UpsertStats(DB.Create(&Language{Code: "upsert_stats_9"})); stats.Known {
+			t.Errorf("the create without OnConflict isn't an upsert, got %+v", stats)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package tests_test
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	. "gorm.io/gorm/utils/tests"
+)
+
+type UUIDAccount struct {
+	ID      string `gorm:"primaryKey;default:uuid"`
+	Name    string
+	Devices []UUIDDevice
+}
+
+type UUIDDevice struct {
+	ID            string `gorm:"primaryKey;default:uuid"`
+	UUIDAccountID string
+	Name          string
+}
+
+type BinaryUUIDAccount struct {
+	ID      string `gorm:"primaryKey;default:uuid;uuidStorage:binary"`
+	Name    string
+	Devices []BinaryUUIDDevice `gorm:"foreignKey:AccountID"`
+}
+
+type BinaryUUIDDevice struct {
+	ID        [16]byte `gorm:"primaryKey;default:uuid;uuidStorage:binary"`
+	AccountID string
+	Name      string
+}
+
+func TestUUIDPrimaryKeys(t *testing.T) {
+	DB.Migrator().DropTable(&UUIDDevice{}, &UUIDAccount{}, &BinaryUUIDDevice{}, &BinaryUUIDAccount{})
+	if err := DB.AutoMigrate(&UUIDAccount{}, &UUIDDevice{}, &BinaryUUIDAccount{}, &BinaryUUIDDevice{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	t.Run("String", func(t *testing.T) {
+		accounts := []UUIDAccount{
+			{Name: "uuid_1", Devices: []UUIDDevice{{Name: "uuid_1_phone"}, {Name: "uuid_1_laptop"}}},
+			{Name: "uuid_2", Devices: []UUIDDevice{{Name: "uuid_2_phone"}}},
+		}
+		if err := DB.Create(&accounts).Error; err != nil {
+			t.Fatalf("failed to create, got error %v", err)
+		}
+
+		for _, account := range accounts {
+			if !schema.IsUUID(account.ID) || account.Devices[0].UUIDAccountID != account.ID || !schema.IsUUID(account.Devices[0].ID) {
+				t.Fatalf("the UUIDs should be generated, got %+v", account)
+			}
+		}
+
+		var account UUIDAccount
+		if err := DB.Preload("Devices").First(&account, accounts[0].ID).Error; err != nil {
+			t.Fatalf("failed to find by UUID, got error %v", err)
+		}
+		AssertEqual(t, account.Name, "uuid_1")
+		AssertEqual(t, len(account.Devices), 2)
+
+		// the UUIDs v7 are ordered by the creation
+		var results []UUIDAccount
+		DB.Preload("Devices").Order("id").Find(&results, []string{accounts[1].ID, accounts[0].ID})
+		if len(results) != 2 || results[0].Name != "uuid_1" || len(results[1].Devices) != 1 {
+			t.Errorf("failed to find by UUIDs, got %+v", results)
+		}
+	})
+
+	t.Run("Binary", func(t *testing.T) {
+		accounts := []BinaryUUIDAccount{
+			{Name: "binary_1", Devices: []BinaryUUIDDevice{{Name: "binary_1_phone"}, {Name: "binary_1_laptop"}}},
+			{Name: "binary_2", Devices: []BinaryUUIDDevice{{Name: "binary_2_phone"}}},
+		}
+		if err := DB.Create(&accounts).Error; err != nil {
+			t.Fatalf("failed to create, got error %v", err)
+		}
+
+		if !schema.IsUUID(accounts[0].ID) || accounts[0].Devices[0].AccountID != accounts[0].ID || accounts[0].Devices[0].ID == [16]byte{} {
+			t.Fatalf("the UUIDs should be generated, got %+v", accounts[0])
+		}
+
+		var length int
+		DB.Model(&BinaryUUIDAccount{}).Select("length(id)").Where(&BinaryUUIDAccount{ID: accounts[0].ID}).Scan(&length)
+		AssertEqual(t, length, 16)
+
+		var account BinaryUUIDAccount
+		if err := DB.Preload("Devices").First(&account, accounts[0].ID).Error; err != nil {
+			t.Fatalf("failed to find by UUID, got error %v", err)
+		}
+		AssertEqual(t, account.ID, accounts[0].ID)
+		AssertEqual(t, len(account.Devices), 2)
+
+		var device BinaryUUIDDevice
+		if err := DB.Where(&BinaryUUIDDevice{ID: accounts[1].Devices[0].ID}).First(&device).Error; err != nil {
+			t.Fatalf("failed to find by binary UUID, got error %v", err)
+		}
+		AssertEqual(t, device.AccountID, accounts[1].ID)
+
+		var results []BinaryUUIDAccount
+		DB.Preload("Devices").Order("id").Find(&results, []string{accounts[1].ID, accounts[0].ID})
+		if len(results) != 2 || results[0].Name != "binary_1" || len(results[0].Devices) != 2 || len(results[1].Devices) != 1 {
+			t.Errorf("failed to find by UUIDs, got %+v", results)
+		}
+
+		if err := DB.Where(&BinaryUUIDDevice{AccountID: accounts[1].ID}).Delete(&BinaryUUIDDevice{}).Error; err != nil {
+			t.Fatalf("failed to delete by UUID foreign key, got error %v", err)
+		}
+		if err := DB.Delete(&BinaryUUIDAccount{}, accounts[1].ID).Error; err != nil {
+			t.Fatalf("failed to delete by UUID, got error %v", err)
+		}
+		var count int64
+		DB.Model(&BinaryUUIDAccount{}).Count(&count)
+		AssertEqual(t, count, 1)
+	})
+
+	t.Run("Generator", func(t *testing.T) {
+		db, _ := gorm.Open(DB.Dialector, &gorm.Config{UUIDGenerator: gorm.NewUUIDv4})
+		account := UUIDAccount{Name: "uuid_v4"}
+		if err := db.Create(&account).Error; err != nil {
+			t.Fatalf("failed to create, got error %v", err)
+		}
+
+		if id, err := schema.ParseUUID(account.ID); err != nil || id[6]>>4 != 4 {
+			t.Errorf("the UUID should be generated by the generator, got %v", account.ID)
+		}
+
+		account = UUIDAccount{ID: "0190f3b4-5a2e-7c1d-8f3a-4b5c6d7e8f90", Name: "uuid_given"}
+		db.Create(&account)
+		AssertEqual(t, account.ID, "0190f3b4-5a2e-7c1d-8f3a-4b5c6d7e8f90")
+	})
+}
@@ -0,0 +1,81 @@
+package tests_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	. "gorm.io/gorm/utils/tests"
+)
+
+// changesDialector reports the rows changed by the last statement on the connection as the warning, which is 0 if it
+// isn't fetched from the connection executing the statement
+type changesDialector struct {
+	gorm.Dialector
+	fetched *int
+}
+
+func (d changesDialector) FetchWarnings(ctx context.Context, conn gorm.ConnPool) []gorm.Warning {
+	*d.fetched++
+	var changes string
+	if err := conn.QueryRowContext(ctx, "SELECT changes()").Scan(&changes); err != nil || changes == "0" {
+		return nil
+	}
+	return []gorm.Warning{{Level: "Note", Code: "1", Message: changes + " rows changed"}}
+}
+
+func TestCaptureWarnings(t *testing.T) {
+	if DB.Dialector.Name() != "sqlite" {
+		t.Skip("changes() is only supported by sqlite")
+	}
+
+	users := []User{*GetUser("warnings_1", Config{}), *GetUser("warnings_2", Config{})}
+	DB.Create(&users)
+
+	var (
+		buf     bytes.Buffer
+		fetched int
+	)
+	tx := DB.Session(&gorm.Session{
+		CaptureWarnings: true,
+		Logger:          logger.New(log.New(&buf, "", 0), logger.Config{LogLevel: logger.Warn}),
+	})
+	tx.Config.Dialector = changesDialector{Dialector: DB.Dialector, fetched: &fetched}
+
+	result := tx.Model(&User{}).Where("id IN ?", []uint{users[0].ID, users[1].ID}).Update("age", 30)
+	if result.Error != nil {
+		t.Fatalf("failed to update, got error %v", result.Error)
+	}
+
+	if len(result.Statement.Warnings) != 1 || result.Statement.Warnings[0].Message != "2 rows changed" {
+		t.Errorf("should capture the warnings of the connection executing the statement, got %+v", result.Statement.Warnings)
+	}
+
+	if !strings.Contains(buf.String(), "Note 1: 2 rows changed") {
+		t.Errorf("the warnings should be logged, got %v", buf.String())
+	}
+
+	var user User
+	if err := tx.First(&user, users[0].ID).Error; err != nil || user.Age != 30 {
+		t.Errorf("failed to find the updated user, got %v, error %v", user.Age, err)
+	}
+
+	tx.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&User{}).Where("id = ?", users[0].ID).Update("age", 20)
+		if len(result.Statement.Warnings) != 1 || result.Statement.Warnings[0].Message != "1 rows changed" {
+			t.Errorf("should capture the warnings in the transaction, got %+v", result.Statement.Warnings)
+		}
+		return nil
+	})
+
+	fetched = 0
+	tx.Model(&User{}).Where("id = ?", users[0].ID).Rows()
+	DB.Session(&gorm.Session{}).Model(&User{}).Where("id = ?", users[0].ID).Update("age", 10)
+	if fetched != 0 {
+		t.Errorf("the warnings of Rows and the sessions not capturing them shouldn't be fetched, got %v", fetched)
+	}
+}
@@ -0,0 +1,40 @@
+package gorm
+
+// UpsertResult the rows inserted and updated by a create with clause.OnConflict, see UpsertStats
+type UpsertResult struct {
+	// Known reports whether the rows could be determined, the databases don't report them in all cases, e.g: the
+	// rows updated by SQLite, or the rows of MySQL which are ambiguous by the affected rows
+	Known    bool
+	Inserted int64
+	Updated  int64
+	// Unchanged the conflicting rows neither inserted nor updated, e.g: DoNothing, or the rows whose values are the
+	// same on MySQL
+	Unchanged int64
+}
+
+// add adds the rows of the batches, which is known only if all the batches are known
+func (result UpsertResult) add(other UpsertResult) UpsertResult {
+	return UpsertResult{
+		Known:     result.Known && other.Known,
+		Inserted:  result.Inserted + other.Inserted,
+		Updated:   result.Updated + other.Updated,
+		Unchanged: result.Unchanged + other.Unchanged,
+	}
+}
+
+// UpsertStats returns the rows inserted and updated by the last Create or CreateInBatches of db with clause.OnConflict,
+// which are determined by RETURNING (xmax = 0) on Postgres, and by the affected rows on MySQL, which counts the
+// updated rows as 2 by default, e.g:
+//
+//	tx := db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&products)
+//	if stats := gorm.UpsertStats(tx); stats.Known {
+//		log.Printf("imported %d new, updated %d", stats.Inserted, stats.Updated)
+//	}
+//
+// Known is false if the rows can't be determined or db isn't an upsert
+func UpsertStats(db *DB) UpsertResult {
+	if db == nil || db.Statement == nil || db.Statement.Upsert == nil {
+		return UpsertResult{}
+	}
+	return *db.Statement.Upsert
+}
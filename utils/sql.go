@@ -0,0 +1,532 @@
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+type sqlTokenKind int
+
+const (
+	sqlWord sqlTokenKind = iota
+	sqlIdentifier
+	sqlLiteral
+	sqlOperator
+	sqlPunct
+)
+
+type sqlToken struct {
+	kind  sqlTokenKind
+	text  string
+	space bool // preceded by whitespace or comments
+}
+
+// the keywords after which a minus sign starts a negative number
+var sqlSignKeywords = map[string]bool{
+	"and": true, "or": true, "not": true, "between": true, "case": true, "when": true, "then": true, "else": true,
+	"select": true, "where": true, "having": true, "on": true, "by": true, "limit": true, "offset": true,
+	"values": true, "in": true, "is": true, "like": true, "set": true, "return": true,
+}
+
+// NormalizeSQL normalizes sql for grouping the queries of the same shape, the words are lowercased, whitespaces and
+// comments collapsed, the string, numeric, boolean and NULL literals and the placeholders replaced by `?`, and the
+// lists of IN collapsed to `(?+)`, e.g:
+//
+//	NormalizeSQL("SELECT * FROM `users` WHERE id IN (1, 2, 3) AND name = 'jinzhu'")
+//	// select * from `users` where id in (?+) and name = ?
+//
+// The quoted identifiers are kept as they are, so the SQL with placeholders and the SQL explained with its vars are
+// normalized to the same result, the double quoted values are strings like MySQL if the identifiers are quoted with
+// backticks, otherwise identifiers like Postgres
+func NormalizeSQL(sql string) string {
+	tokens := collapseSQLLists(tokenizeSQL(sql))
+
+	var builder strings.Builder
+	builder.Grow(len(sql))
+	for idx, token := range tokens {
+		if idx > 0 {
+			prev := tokens[idx-1].text
+			switch {
+			case token.text == "," || token.text == ")" || token.text == "." || token.text == ";":
+			case prev == "(" || prev == ".":
+			case strings.HasPrefix(token.text, "("):
+				if token.space || prev == "," {
+					builder.WriteByte(' ')
+				}
+			default:
+				builder.WriteByte(' ')
+			}
+		}
+		builder.WriteString(token.text)
+	}
+	return builder.String()
+}
+
+// SQLFingerprint returns the hash of the normalized sql, see NormalizeSQL
+func SQLFingerprint(sql string) string {
+	hash := fnv.New64a()
+	hash.Write([]byte(NormalizeSQL(sql)))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// SQLTables returns the tables following FROM, JOIN, INTO, UPDATE and TABLE of sql, without the quotes and schemas,
+// the subqueries and the tables after the first one of comma separated lists are skipped
+func SQLTables(sql string) []string {
+	var (
+		tables []string
+		tokens = tokenizeSQL(sql)
+	)
+
+	for i := 0; i+1 < len(tokens); i++ {
+		switch tokens[i].text {
+		case "from", "join", "into", "update", "table":
+		default:
+			continue
+		}
+
+		var table string
+		for i++; i < len(tokens) && (tokens[i].kind == sqlWord || tokens[i].kind == sqlIdentifier); i++ {
+			table = strings.Trim(tokens[i].text, "`\"")
+			if i+1 >= len(tokens) || tokens[i+1].text != "." {
+				break
+			}
+			i++
+		}
+
+		if table != "" && !Contains(tables, table) {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+func tokenizeSQL(sql string) []sqlToken {
+	var (
+		tokens              []sqlToken
+		space               bool
+		doubleQuotedLiteral = strings.IndexByte(sql, '`') >= 0
+	)
+
+	add := func(kind sqlTokenKind, text string) {
+		tokens = append(tokens, sqlToken{kind: kind, text: text, space: space})
+		space = false
+	}
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			space = true
+			i++
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			space = true
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(sql)
+			}
+			space = true
+		case c == '\'' || (c == '"' && doubleQuotedLiteral):
+			i = skipQuoted(sql, i, c)
+			add(sqlLiteral, "?")
+		case c == '"' || c == '`':
+			end := skipQuoted(sql, i, c)
+			add(sqlIdentifier, sql[i:end])
+			i = end
+		case c == '?':
+			add(sqlLiteral, "?")
+			i++
+		case c == '$' && i+1 < len(sql) && isDigit(sql[i+1]):
+			for i++; i < len(sql) && isDigit(sql[i]); i++ {
+			}
+			add(sqlLiteral, "?")
+		case isDigit(c) || (c == '.' && i+1 < len(sql) && isDigit(sql[i+1])) ||
+			(c == '-' && i+1 < len(sql) && isDigit(sql[i+1]) && signedNumber(tokens)):
+			i = skipNumber(sql, i+1)
+			add(sqlLiteral, "?")
+		case isWordChar(c):
+			start := i
+			for i < len(sql) && (isWordChar(sql[i]) || isDigit(sql[i])) {
+				i++
+			}
+
+			switch word := strings.ToLower(sql[start:i]); word {
+			case "null", "true", "false":
+				add(sqlLiteral, "?")
+			default:
+				add(sqlWord, word)
+			}
+		case c == '(' || c == ')' || c == ',' || c == '.' || c == ';' || c == '[' || c == ']' || c == '{' || c == '}':
+			add(sqlPunct, sql[i:i+1])
+			i++
+		default:
+			start := i
+			for i++; i < len(sql) && isOperatorChar(sql[i]); i++ {
+				if next := i + 1; next < len(sql) &&
+					((sql[i] == '-' && (sql[next] == '-' || isDigit(sql[next]))) || (sql[i] == '/' && sql[next] == '*')) {
+					break
+				}
+			}
+			add(sqlOperator, sql[start:i])
+		}
+	}
+	return tokens
+}
+
+// collapseSQLLists collapses the placeholder lists of IN to `(?+)`, and the lists of placeholder tuples to `((?, ?)+)`
+func collapseSQLLists(tokens []sqlToken) []sqlToken {
+	result := tokens[:0]
+	for i := 0; i < len(tokens); i++ {
+		result = append(result, tokens[i])
+		if tokens[i].kind != sqlWord || tokens[i].text != "in" || i+1 >= len(tokens) || tokens[i+1].text != "(" {
+			continue
+		}
+
+		if end, ok := matchPlaceholderList(tokens, i+1); ok {
+			result = append(result, sqlToken{kind: sqlPunct, text: "(?+)", space: tokens[i+1].space})
+			i = end
+			continue
+		}
+
+		// tuples, e.g: ((?,?),(?,?))
+		var (
+			tuple string
+			arity int
+			pos   = i + 2
+		)
+		for pos < len(tokens) && tokens[pos].text == "(" {
+			end, ok := matchPlaceholderList(tokens, pos)
+			if !ok || (arity != 0 && (end-pos)/2 != arity) {
+				break
+			}
+			arity = (end - pos) / 2
+			tuple = "(" + strings.Repeat("?, ", arity-1) + "?)"
+			pos = end + 1
+			if pos < len(tokens) && tokens[pos].text == "," {
+				pos++
+			} else {
+				break
+			}
+		}
+
+		if tuple != "" && pos < len(tokens) && tokens[pos].text == ")" && tokens[pos-1].text != "," {
+			result = append(result, sqlToken{kind: sqlPunct, text: "(" + tuple + "+)", space: tokens[i+1].space})
+			i = pos
+		}
+	}
+	return result
+}
+
+// matchPlaceholderList matches `(?,?,...)` starting at the tokens[start], returns the index of the closing parenthesis
+func matchPlaceholderList(tokens []sqlToken, start int) (int, bool) {
+	for pos := start + 1; pos+1 < len(tokens); pos += 2 {
+		if tokens[pos].kind != sqlLiteral {
+			return 0, false
+		}
+
+		switch tokens[pos+1].text {
+		case ")":
+			return pos + 1, true
+		case ",":
+		default:
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// skipQuoted returns the index after the quoted string starting at sql[start], the doubled quotes are escaped
+func skipQuoted(sql string, start int, quote byte) int {
+	for i := start + 1; i < len(sql); i++ {
+		if sql[i] == quote {
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(sql)
+}
+
+// skipNumber returns the index after the number, including the decimals, exponents and hexadecimal digits
+func skipNumber(sql string, i int) int {
+	for i < len(sql) {
+		switch c := sql[i]; {
+		case isDigit(c) || c == '.' || c == 'x' || c == 'X' || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F'):
+			i++
+		case (c == '+' || c == '-') && (sql[i-1] == 'e' || sql[i-1] == 'E'):
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// signedNumber reports whether a minus sign after the tokens starts a negative number instead of a subtraction
+func signedNumber(tokens []sqlToken) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+
+	switch last := tokens[len(tokens)-1]; last.kind {
+	case sqlOperator:
+		return true
+	case sqlPunct:
+		return last.text == "(" || last.text == "," || last.text == "["
+	case sqlWord:
+		return sqlSignKeywords[last.text]
+	}
+	return false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c == '$' || c == '@' || c == '#' || c >= 0x80
+}
+
+func isOperatorChar(c byte) bool {
+	switch c {
+	case '=', '<', '>', '!', '+', '-', '*', '/', '%', '|', '&', '^', '~', ':':
+		return true
+	}
+	return false
+}
+
+// SplitSQL splits sql into the statements separated by semicolons, the semicolons of the string literals, quoted
+// identifiers, dollar-quoted strings and comments are kept, the statements are trimmed and the empty ones or the ones
+// only having comments are skipped, e.g:
+//
+//	SplitSQL("INSERT INTO t VALUES ('a;b'); -- done\nUPDATE t SET n = 1;")
+//	// ["INSERT INTO t VALUES ('a;b')", "-- done\nUPDATE t SET n = 1"]
+//
+// The compound statements with semicolons in their bodies, e.g: the triggers of MySQL, aren't supported
+func SplitSQL(sql string) []string {
+	var (
+		statements []string
+		start      int
+		hasContent bool
+	)
+
+	scanSQL(sql, func(from, to int, comment bool) {
+		switch {
+		case comment:
+		case sql[from] == ';' && to-from == 1:
+			if hasContent {
+				statements = append(statements, strings.TrimSpace(sql[start:from]))
+			}
+			start, hasContent = to, false
+		case !isSpace(sql[from]):
+			hasContent = true
+		}
+	})
+
+	if hasContent {
+		statements = append(statements, strings.TrimSpace(sql[start:]))
+	}
+	return statements
+}
+
+// CountSQLPlaceholders returns the count of the `?` placeholders of sql, excluding the ones of the string literals,
+// quoted identifiers, dollar-quoted strings and comments
+func CountSQLPlaceholders(sql string) (count int) {
+	scanSQL(sql, func(from, to int, comment bool) {
+		if !comment && sql[from] == '?' && to-from == 1 {
+			count++
+		}
+	})
+	return count
+}
+
+// CountSQLBindVars returns the count of the vars bound by sql, which is the count of the `?` placeholders, or the max
+// index of the numbered placeholders like `$1` of Postgres and `@p1` of SQL Server, excluding the ones of the string
+// literals, quoted identifiers, dollar-quoted strings and comments
+func CountSQLBindVars(sql string) (count int) {
+	var numbered int
+	scanSQL(sql, func(from, to int, comment bool) {
+		if comment || to-from != 1 {
+			return
+		}
+
+		start := from + 1
+		switch c := sql[from]; {
+		case c == '?':
+			count++
+			return
+		case c == '@' && start < len(sql) && (sql[start] == 'p' || sql[start] == 'P'):
+			start++
+		case c != '$':
+			return
+		}
+
+		index, end := 0, start
+		for ; end < len(sql) && isDigit(sql[end]); end++ {
+			index = index*10 + int(sql[end]-'0')
+		}
+		if end > start && index > numbered {
+			numbered = index
+		}
+	})
+	return count + numbered
+}
+
+// NormalizePlaceholders rewrites the numbered placeholders like `$1` of sql to `?`, and returns the indexes of the vars
+// bound by the question marks of the rewritten sql in order, which are -1 for the question marks that aren't
+// placeholders, e.g: the ones of the string literals, quoted identifiers, dollar-quoted strings and comments, the
+// Postgres operators `?|` and `?&`, and the escaped `??` which is rewritten to `?`, e.g:
+//
+//	NormalizePlaceholders("SELECT * FROM t WHERE b = $2 AND a = $1 AND tags ?| $3")
+//	// "SELECT * FROM t WHERE b = ? AND a = ? AND tags ?| ?", [1 0 -1 2]
+//
+// The sql mixing `?` and the numbered placeholders or having `$0` returns an error
+func NormalizePlaceholders(sql string) (string, []int, error) {
+	var (
+		builder             strings.Builder
+		indexes             []int
+		next                int
+		question, numbered  bool
+		invalid             bool
+		questionPlaceholder int
+	)
+	builder.Grow(len(sql))
+
+	scanSQL(sql, func(from, to int, comment bool) {
+		if from < next {
+			return
+		}
+
+		if comment || to-from > 1 {
+			for i := from; i < to; i++ {
+				if sql[i] == '?' {
+					indexes = append(indexes, -1)
+				}
+			}
+			builder.WriteString(sql[from:to])
+			return
+		}
+
+		switch c := sql[from]; {
+		case c == '?' && to < len(sql) && sql[to] == '?':
+			next = to + 1
+			indexes = append(indexes, -1)
+		case c == '?' && to < len(sql) && (sql[to] == '|' || sql[to] == '&'):
+			indexes = append(indexes, -1)
+		case c == '?':
+			question = true
+			indexes = append(indexes, questionPlaceholder)
+			questionPlaceholder++
+		case c == '$' && to < len(sql) && isDigit(sql[to]):
+			index, end := 0, to
+			for ; end < len(sql) && isDigit(sql[end]); end++ {
+				index = index*10 + int(sql[end]-'0')
+			}
+
+			if index == 0 {
+				invalid = true
+			}
+
+			numbered, next = true, end
+			indexes = append(indexes, index-1)
+			builder.WriteByte('?')
+			return
+		}
+		builder.WriteByte(sql[from])
+	})
+
+	if question && numbered {
+		return sql, nil, fmt.Errorf("mixed ? and numbered placeholders in %v", sql)
+	} else if invalid {
+		return sql, nil, fmt.Errorf("numbered placeholder $0 in %v", sql)
+	}
+	return builder.String(), indexes, nil
+}
+
+// scanSQL calls fn with the ranges of sql in order, which are the single bytes of the code, or the whole string
+// literals, quoted identifiers, dollar-quoted strings and comments
+func scanSQL(sql string, fn func(from, to int, comment bool)) {
+	for i := 0; i < len(sql); {
+		var (
+			end     = i + 1
+			comment bool
+		)
+
+		switch c := sql[i]; {
+		case c == '\'' || c == '"':
+			end = skipEscapedQuoted(sql, i, c)
+		case c == '`':
+			end = skipQuoted(sql, i, c)
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			if idx := strings.IndexByte(sql[i:], '\n'); idx >= 0 {
+				end = i + idx
+			} else {
+				end = len(sql)
+			}
+			comment = true
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			if idx := strings.Index(sql[i+2:], "*/"); idx >= 0 {
+				end = i + idx + 4
+			} else {
+				end = len(sql)
+			}
+			comment = true
+		case c == '$':
+			end = skipDollarQuoted(sql, i)
+		}
+
+		fn(i, end, comment)
+		i = end
+	}
+}
+
+// skipEscapedQuoted returns the index after the quoted string starting at sql[start], the doubled quotes and the
+// characters after backslashes are escaped
+func skipEscapedQuoted(sql string, start int, quote byte) int {
+	for i := start + 1; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			i++
+		case quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(sql)
+}
+
+// skipDollarQuoted returns the index after the dollar-quoted string starting at sql[start], e.g: $$a;b$$ or
+// $body$a;b$body$, returns start+1 if it isn't a dollar-quoted string, e.g: the placeholder $1
+func skipDollarQuoted(sql string, start int) int {
+	end := start + 1
+	for ; end < len(sql); end++ {
+		if c := sql[end]; !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c >= 0x80 || (end > start+1 && isDigit(c))) {
+			break
+		}
+	}
+
+	if end >= len(sql) || sql[end] != '$' {
+		return start + 1
+	}
+
+	tag := sql[start : end+1]
+	if idx := strings.Index(sql[end+1:], tag); idx >= 0 {
+		return end + 1 + idx + len(tag)
+	}
+	return len(sql)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
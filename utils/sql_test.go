@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeSQL(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{
+			"SELECT * FROM `users` WHERE id IN (1, 2, 3) AND name = 'jinzhu'",
+			"select * from `users` where id in (?+) and name = ?",
+		},
+		{
+			"SELECT *  FROM `users`\n\tWHERE id IN (?,?) AND name = ?",
+			"select * from `users` where id in (?+) and name = ?",
+		},
+		{
+			`SELECT * FROM "users" WHERE "users"."id" IN ($1,$2,$3) AND "users"."deleted_at" IS NULL LIMIT $4`,
+			`select * from "users" where "users"."id" in (?+) and "users"."deleted_at" is ? limit ?`,
+		},
+		{
+			"SELECT `Select`, `a `` b` FROM `Order` WHERE `Where` = 'from' AND `From` = \"where\"",
+			"select `Select`, `a `` b` from `Order` where `Where` = ? and `From` = ?",
+		},
+		{
+			`SELECT "Select", "a "" b" FROM "Order" WHERE "Where" = 'from'`,
+			`select "Select", "a "" b" from "Order" where "Where" = ?`,
+		},
+		{
+			"SELECT count(*) FROM t WHERE name = 'a (b'' c) IN (' AND x IN ('(', ')') AND y NOT IN (?)",
+			"select count(*) from t where name = ? and x in (?+) and y not in (?+)",
+		},
+		{
+			"UPDATE t SET a = -1, b = a-1, c = 1.5e+06, d = 0x1F WHERE e > -2.5 AND f = TRUE",
+			"update t set a = ?, b = a - ?, c = ?, d = ? where e > ? and f = ?",
+		},
+		{
+			"SELECT /* route='/users' */ 1 -- trailing comment\nFROM t /* unterminated",
+			"select ? from t",
+		},
+		{
+			"SELECT * FROM t WHERE (a, b) IN ((1, 2), (3, 4)) AND (c) IN ((?), (?))",
+			"select * from t where (a, b) in ((?, ?)+) and (c) in ((?)+)",
+		},
+		{
+			"SELECT * FROM t WHERE id IN (SELECT id FROM s WHERE x IN (1,2)) AND y IN ((1, 2), (3))",
+			"select * from t where id in (select id from s where x in (?+)) and y in ((?, ?), (?))",
+		},
+		{
+			"INSERT INTO `users` (`name`,`age`) VALUES ('a',1),('b',2)",
+			"insert into `users` (`name`, `age`) values (?, ?), (?, ?)",
+		},
+	}
+
+	for _, test := range tests {
+		if normalized := NormalizeSQL(test.sql); normalized != test.expected {
+			t.Errorf("failed to normalize %q\nexpected: %s\n     got: %s", test.sql, test.expected, normalized)
+		}
+	}
+}
+
+func TestSQLFingerprint(t *testing.T) {
+	fingerprint := SQLFingerprint("SELECT * FROM users WHERE id IN (?,?) AND name = ?")
+	if len(fingerprint) != 16 {
+		t.Errorf("unexpected fingerprint %v", fingerprint)
+	}
+
+	for _, sql := range []string{
+		"SELECT * FROM users WHERE id IN (1,2,3,4) AND name = 'jinzhu'",
+		"select *\nfrom users where id in (?) and name = NULL",
+	} {
+		if f := SQLFingerprint(sql); f != fingerprint {
+			t.Errorf("fingerprint of %q should be %v, got %v", sql, fingerprint, f)
+		}
+	}
+
+	if f := SQLFingerprint("SELECT * FROM users WHERE id IN (?,?) AND age = ?"); f == fingerprint {
+		t.Errorf("fingerprints of different queries should be different")
+	}
+}
+
+func TestSQLTables(t *testing.T) {
+	tests := []struct {
+		sql    string
+		tables []string
+	}{
+		{"SELECT * FROM `users` WHERE name = 'from orders'", []string{"users"}},
+		{`SELECT * FROM "public"."users" LEFT JOIN "pets" ON "pets"."user_id" = "users"."id"`, []string{"users", "pets"}},
+		{"INSERT INTO users (name) SELECT name FROM users_archive", []string{"users", "users_archive"}},
+		{"UPDATE `users` SET age = 1", []string{"users"}},
+		{"DELETE FROM users WHERE id IN (SELECT user_id FROM pets)", []string{"users", "pets"}},
+		{"SELECT * FROM (SELECT 1) AS t", nil},
+		{"SELECT 1", nil},
+	}
+
+	for _, test := range tests {
+		if tables := SQLTables(test.sql); !AssertEqual(tables, test.tables) {
+			t.Errorf("failed to parse tables of %q, expected %v, got %v", test.sql, test.tables, tables)
+		}
+	}
+}
+
+func TestSplitSQL(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected []string
+	}{
+		{"SELECT 1", []string{"SELECT 1"}},
+		{"SELECT 1; SELECT 2;\n", []string{"SELECT 1", "SELECT 2"}},
+		{";; SELECT 1 ;\n;", []string{"SELECT 1"}},
+		{"INSERT INTO t VALUES ('a;b', 'it''s;', 'c\\';d'); SELECT \"x;y\", `z;`", []string{"INSERT INTO t VALUES ('a;b', 'it''s;', 'c\\';d')", "SELECT \"x;y\", `z;`"}},
+		{"-- seed; users\nINSERT INTO t VALUES (1); /* done; */", []string{"-- seed; users\nINSERT INTO t VALUES (1)"}},
+		{
+			"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql; DO $body$ BEGIN PERFORM 1; END $body$; SELECT $1",
+			[]string{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql", "DO $body$ BEGIN PERFORM 1; END $body$", "SELECT $1"},
+		},
+		{"SELECT 'unterminated; SELECT 2", []string{"SELECT 'unterminated; SELECT 2"}},
+		{"  -- only comments\n/* ; */", nil},
+	}
+
+	for _, test := range tests {
+		if statements := SplitSQL(test.sql); !reflect.DeepEqual(statements, test.expected) {
+			t.Errorf("SplitSQL(%q) = %q, expected %q", test.sql, statements, test.expected)
+		}
+	}
+}
+
+func TestCountSQLPlaceholders(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected int
+	}{
+		{"INSERT INTO t VALUES (?, ?)", 2},
+		{"SELECT '?', \"?\", `?` FROM t WHERE a = ? -- ?\n AND b = ? /* ? */", 2},
+		{"SELECT $$?$$, $1", 0},
+	}
+
+	for _, test := range tests {
+		if count := CountSQLPlaceholders(test.sql); count != test.expected {
+			t.Errorf("CountSQLPlaceholders(%q) = %v, expected %v", test.sql, count, test.expected)
+		}
+	}
+}
+
+func TestCountSQLBindVars(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected int
+	}{
+		{"INSERT INTO t VALUES (?, ?)", 2},
+		{"SELECT * FROM t WHERE a = $1 AND b = $2 OR c = $1", 2},
+		{"SELECT '$3', $$ $4 $$ FROM t WHERE a = $1 -- $5", 1},
+		{"SELECT * FROM t WHERE a = @p1 AND b = @p12 AND c = @name", 12},
+		{"SELECT 1", 0},
+	}
+
+	for _, test := range tests {
+		if count := CountSQLBindVars(test.sql); count != test.expected {
+			t.Errorf("CountSQLBindVars(%q) = %v, expected %v", test.sql, count, test.expected)
+		}
+	}
+}
+
+func TestNormalizePlaceholders(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+		indexes  []int
+		err      bool
+	}{
+		{"SELECT * FROM t WHERE a = ? AND b IN (?)", "SELECT * FROM t WHERE a = ? AND b IN (?)", []int{0, 1}, false},
+		{"SELECT * FROM t WHERE b = $2 AND a = $1 OR c = $2", "SELECT * FROM t WHERE b = ? AND a = ? OR c = ?", []int{1, 0, 1}, false},
+		{"SELECT '?', \"$1\", `?` FROM t WHERE a = ? -- ?\n AND b = ? /* $1 */", "SELECT '?', \"$1\", `?` FROM t WHERE a = ? -- ?\n AND b = ? /* $1 */", []int{-1, -1, 0, -1, 1}, false},
+		{"SELECT $$?$$ FROM t WHERE tags ?| $1 AND tags ?& $2 AND data ?? $3", "SELECT $$?$$ FROM t WHERE tags ?| ? AND tags ?& ? AND data ? ?", []int{-1, -1, 0, -1, 1, -1, 2}, false},
+		{"SELECT $10", "SELECT ?", []int{9}, false},
+		{"SELECT $1, ?", "", nil, true},
+		{"SELECT $0", "", nil, true},
+	}
+
+	for _, test := range tests {
+		sql, indexes, err := NormalizePlaceholders(test.sql)
+		if test.err {
+			if err == nil {
+				t.Errorf("NormalizePlaceholders(%q) should return error", test.sql)
+			}
+			continue
+		}
+
+		if err != nil || sql != test.expected || !reflect.DeepEqual(indexes, test.indexes) {
+			t.Errorf("NormalizePlaceholders(%q) = %q, %v, %v, expected %q, %v", test.sql, sql, indexes, err, test.expected, test.indexes)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"bytes"
 	"database/sql/driver"
 	"fmt"
 	"path/filepath"
@@ -99,12 +100,15 @@ func Contains(elems []string, elem string) bool {
 }
 
 func AssertEqual(x, y interface{}) bool {
+	if x == nil || y == nil {
+		return x == nil && y == nil
+	}
+	if equal, ok := fastEqual(x, y); ok {
+		return equal
+	}
 	if reflect.DeepEqual(x, y) {
 		return true
 	}
-	if x == nil || y == nil {
-		return false
-	}
 
 	xval := reflect.ValueOf(x)
 	yval := reflect.ValueOf(y)
@@ -122,6 +126,45 @@ func AssertEqual(x, y interface{}) bool {
 	return reflect.DeepEqual(x, y)
 }
 
+// fastEqual compares x and y of the same type without reflect.DeepEqual, e.g: the huge []byte, ok is false if it
+// can't be decided, the values of different types or the valuers may be equal by their driver values
+func fastEqual(x, y interface{}) (equal bool, ok bool) {
+	if xb, isBytes := x.([]byte); isBytes {
+		if yb, isBytes := y.([]byte); isBytes {
+			return (xb == nil) == (yb == nil) && bytes.Equal(xb, yb), true
+		}
+		return false, false
+	}
+
+	xval, yval := reflect.ValueOf(x), reflect.ValueOf(y)
+	if xval.Type() != yval.Type() {
+		return false, false
+	}
+
+	_, isValuer := x.(driver.Valuer)
+	switch xval.Kind() {
+	case reflect.Bool, reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		if x == y {
+			return true, true
+		}
+		return false, !isValuer
+	case reflect.Ptr:
+		if xval.Pointer() == yval.Pointer() {
+			return true, true
+		}
+	case reflect.Slice, reflect.Map:
+		if xval.IsNil() != yval.IsNil() || xval.Len() != yval.Len() {
+			return false, !isValuer
+		}
+		if xval.Pointer() == yval.Pointer() {
+			return true, true
+		}
+	}
+	return false, false
+}
+
 func ToString(value interface{}) string {
 	switch v := value.(type) {
 	case string:
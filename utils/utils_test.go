@@ -5,6 +5,7 @@ import (
 	"database/sql/driver"
 	"errors"
 	"math"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -101,6 +102,16 @@ func TestAssertEqual(t *testing.T) {
 		{"driver.Valuer equal", ModifyAt{Time: now, Valid: true}, ModifyAt{Time: now, Valid: true}, true},
 		{"driver.Valuer not equal", ModifyAt{Time: now, Valid: true}, ModifyAt{Time: now.Add(time.Second), Valid: true}, false},
 		{"driver.Valuer equal (ptr to nil ptr)", (*ModifyAt)(nil), &ModifyAt{}, false},
+		{"bytes equal", []byte("gorm"), []byte("gorm"), true},
+		{"bytes not equal", []byte("gorm"), []byte("orm"), false},
+		{"nil bytes not equal to empty bytes", []byte(nil), []byte{}, false},
+		{"int equal", 1, 1, true},
+		{"int not equal", 1, 2, false},
+		{"different types not equal", 1, int64(1), false},
+		{"same pointer equal", &now, &now, true},
+		{"slice of different length not equal", []string{"a"}, []string{"a", "b"}, false},
+		{"nil equal", nil, nil, true},
+		{"nil not equal", nil, 1, false},
 	}
 	for _, test := range assertEqualTests {
 		t.Run(test.name, func(t *testing.T) {
@@ -111,6 +122,31 @@ func TestAssertEqual(t *testing.T) {
 	}
 }
 
+func BenchmarkAssertEqualLargeBytes(b *testing.B) {
+	x, y := make([]byte, 5<<20), make([]byte, 5<<20)
+	y[len(y)-1] = 1
+
+	// the unequal values were compared by reflect.DeepEqual twice, before and after converting the valuers
+	b.Run("DeepEqual", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if reflect.DeepEqual(x, y) {
+				b.Fatal("should not be equal")
+			}
+			if reflect.DeepEqual(x, y) {
+				b.Fatal("should not be equal")
+			}
+		}
+	})
+
+	b.Run("AssertEqual", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if AssertEqual(x, y) {
+				b.Fatal("should not be equal")
+			}
+		}
+	})
+}
+
 func TestToString(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,55 @@
+package gorm
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// NewUUIDv4 generates a random UUID of version 4
+func NewUUIDv4() (id [16]byte, err error) {
+	if _, err = rand.Read(id[:]); err != nil {
+		return id, err
+	}
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return id, nil
+}
+
+var uuidV7 struct {
+	sync.Mutex
+	millis   int64
+	sequence uint16
+}
+
+// NewUUIDv7 generates a UUID of version 7, whose first 48 bits are the unix milliseconds, the UUIDs generated by the
+// process are increasing, so they are ordered by the creation like the auto increment primary keys
+func NewUUIDv7() (id [16]byte, err error) {
+	if _, err = rand.Read(id[:]); err != nil {
+		return id, err
+	}
+
+	uuidV7.Lock()
+	millis := time.Now().UnixMilli()
+	if millis <= uuidV7.millis {
+		// the 12 bits after the version count the UUIDs of the same millisecond
+		if uuidV7.sequence++; uuidV7.sequence > 0x0fff {
+			uuidV7.millis++
+			uuidV7.sequence = 0
+		}
+		millis = uuidV7.millis
+	} else {
+		uuidV7.millis = millis
+		uuidV7.sequence = 0
+	}
+	sequence := uuidV7.sequence
+	uuidV7.Unlock()
+
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(millis))
+	copy(id[0:6], buf[2:])
+	binary.BigEndian.PutUint16(id[6:8], 0x7000|sequence)
+	id[8] = id[8]&0x3f | 0x80
+	return id, nil
+}
@@ -0,0 +1,86 @@
+package gorm
+
+import (
+	"gorm.io/gorm/clause"
+)
+
+// VarBinding the origin of a var of the statement, added by the typed clauses like clause.Eq, clause.IN, clause.Set
+// and clause.Values, see Config.TrackVarBindings
+type VarBinding struct {
+	Column     clause.Column
+	ClauseName string // the name of the clause built the var, e.g: WHERE, SET, VALUES
+	Index      int    // the index of the var in Statement.Vars
+}
+
+// VarBindings returns the bindings of the built vars when Config.TrackVarBindings is enabled, whose length equals
+// Statement.Vars, the vars added by raw SQL and expressions have nil bindings, the returned bindings shouldn't be
+// modified, e.g:
+//
+//	func (h firewall) After(ctx context.Context, stmt *gorm.Statement, err error) {
+//		for idx, binding := range stmt.VarBindings() {
+//			if binding != nil && binding.Column.Name == "email" {
+//				report(stmt.Vars[idx])
+//			}
+//		}
+//	}
+func (stmt *Statement) VarBindings() []*VarBinding {
+	if !stmt.DB.TrackVarBindings || len(stmt.Vars) == 0 {
+		return nil
+	}
+
+	stmt.syncVarBindings(nil)
+	return stmt.varBindings
+}
+
+// AddColumnVar adds vars of the column like AddVar, which are tracked by VarBindings when Config.TrackVarBindings is
+// enabled, the vars of expressions and subqueries aren't bound to the column
+func (stmt *Statement) AddColumnVar(writer clause.Writer, column interface{}, vars ...interface{}) {
+	if !stmt.DB.TrackVarBindings {
+		stmt.AddVar(writer, vars...)
+		return
+	}
+
+	if collate, ok := column.(clause.Collate); ok {
+		column = collate.Column
+	}
+
+	var binding *VarBinding
+	switch column := column.(type) {
+	case string:
+		binding = &VarBinding{Column: clause.Column{Name: column}, ClauseName: stmt.buildingClause}
+	case clause.Column:
+		binding = &VarBinding{Column: column, ClauseName: stmt.buildingClause}
+	}
+
+	for idx, v := range vars {
+		if idx > 0 {
+			writer.WriteByte(',')
+		}
+
+		stmt.syncVarBindings(nil)
+		stmt.AddVar(writer, v)
+		switch v.(type) {
+		case clause.Expression, *DB:
+			stmt.syncVarBindings(nil)
+		default:
+			stmt.syncVarBindings(binding)
+		}
+	}
+}
+
+// syncVarBindings truncates the bindings to the vars, and binds the vars added since last synced to binding
+func (stmt *Statement) syncVarBindings(binding *VarBinding) {
+	if len(stmt.varBindings) > len(stmt.Vars) {
+		stmt.varBindings = stmt.varBindings[:len(stmt.Vars)]
+	}
+
+	for idx := len(stmt.varBindings); idx < len(stmt.Vars); idx++ {
+		if binding == nil {
+			stmt.varBindings = append(stmt.varBindings, nil)
+		} else {
+			b := *binding
+			b.Index = idx
+			stmt.varBindings = append(stmt.varBindings, &b)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Warning the warning raised by the database for a statement, e.g: the values truncated by MySQL, see
+// Config.CaptureWarnings
+type Warning struct {
+	// Level the level of the warning, e.g: Warning and Note of MySQL, WARNING and NOTICE of Postgres
+	Level string
+	// Code the code of the warning, e.g: the error code of MySQL, or the SQLSTATE of Postgres
+	Code    string
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.Code == "" {
+		return fmt.Sprintf("%s: %s", w.Level, w.Message)
+	}
+	return fmt.Sprintf("%s %s: %s", w.Level, w.Code, w.Message)
+}
+
+// captureWarnings pins the statement to a connection of the pool, so the warnings are fetched from the connection
+// executing it, e.g: the reads aren't sent to the replicas then, returns the function fetching the warnings after the
+// statement is executed, or nil if the warnings can't be captured, e.g: the rows of Row and Rows are read later
+func (stmt *Statement) captureWarnings(fetcher WarningFetcher) (fetch func()) {
+	if _, ok := stmt.Settings.Load("rows"); ok {
+		return nil
+	}
+
+	ctx := stmt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var (
+		connPool = stmt.ConnPool
+		conn     *sql.Conn
+	)
+	if sqlDB := pooledDB(connPool); sqlDB != nil {
+		var err error
+		if conn, err = sqlDB.Conn(ctx); err != nil {
+			return nil
+		}
+		stmt.ConnPool = conn
+	}
+
+	return func() {
+		fetchConn := stmt.ConnPool
+		if conn != nil {
+			// the default transaction resets the pool of the statement when it's committed
+			fetchConn = conn
+			defer func() {
+				stmt.ConnPool = connPool
+				conn.Close()
+			}()
+		}
+
+		stmt.Warnings = fetcher.FetchWarnings(ctx, fetchConn)
+		for _, warning := range stmt.Warnings {
+			stmt.DB.Logger.Warn(ctx, "%v, sql: %s", warning, stmt.SQL.String())
+		}
+	}
+}
+
+// pooledDB returns the *sql.DB of connPool if its statements may run on different connections
+func pooledDB(connPool ConnPool) *sql.DB {
+	if preparedStmt, ok := connPool.(*PreparedStmtDB); ok {
+		connPool = preparedStmt.ConnPool
+	}
+
+	switch pool := connPool.(type) {
+	case *sql.DB:
+		return pool
+	case *poolEventConnPool:
+		return pool.DB
+	}
+	return nil
+}